@@ -6,6 +6,36 @@
       let serverHealthy = true;
       let healthCheckInterval = null;
 
+      // ===========================================
+      // Session & CSRF Protection
+      // ===========================================
+
+      // Send the session's CSRF token with every state-changing request
+      const nativeFetch = window.fetch.bind(window);
+      const sessionReady = nativeFetch('/api/session')
+        .then((response) => (response.ok ? response.json() : {}))
+        .catch(() => ({}));
+
+      window.fetch = async (resource, options = {}) => {
+        const method = (options.method || 'GET').toUpperCase();
+        if (!['GET', 'HEAD', 'OPTIONS'].includes(method)) {
+          const session = await sessionReady;
+          if (session.csrfToken) {
+            const headers = new Headers(options.headers || {});
+            headers.set('X-CSRF-Token', session.csrfToken);
+            options = { ...options, headers };
+          }
+        }
+        return nativeFetch(resource, options);
+      };
+
+      async function showSessionInfo() {
+        const session = await sessionReady;
+        if (session.authEnabled && session.role === 'readonly') {
+          showToast('Read-only access', `Signed in as ${session.user}: analyses work, changes are blocked`, 'info', 6000);
+        }
+      }
+
       // ===========================================
       // Helper Functions
       // ===========================================
@@ -54,8 +84,8 @@
         toast.className = `toast ${type}`;
         toast.innerHTML = `
           <div class="toast-content">
-            <div class="toast-title">${title}</div>
-            <div class="toast-message">${message}</div>
+            <div class="toast-title">${escapeHtml(title)}</div>
+            <div class="toast-message">${escapeHtml(message)}</div>
           </div>
           <button class="toast-close" onclick="this.parentElement.remove()">×</button>
         `;
@@ -166,6 +196,7 @@
 
       // Initialize on page load
       document.addEventListener('DOMContentLoaded', () => {
+        showSessionInfo();
         startHealthCheck();
       });
 
@@ -176,6 +207,7 @@
       let currentStats = {
         totalRepos: 0,
         repoDetails: [],
+        depsByPath: {},
         springVersions: {},
         topDependencies: {},
         totalTodos: 0,
@@ -252,12 +284,166 @@
         div.className = "replacement-row";
         div.innerHTML = `
             <button class="btn-remove" onclick="removeRow(this)" title="Remove Row">-</button>
+            <select class="replacement-type" onchange="updateReplacementType(this)" aria-label="Replacement type" style="width: auto;">
+              <option value="">Text</option>
+              <option value="regex">Regex</option>
+              <option value="property">Maven property</option>
+            </select>
             <textarea placeholder="Search Text" class="replacement-search" oninput="autoResize(this)"></textarea>
             <textarea placeholder="Replacement" class="replacement-replace" oninput="autoResize(this)"></textarea>
+            <input type="text" class="replacement-include" placeholder="Only files, e.g. **/*.yaml" title="Comma-separated path globs the rule is limited to" />
+            <input type="text" class="replacement-exclude" placeholder="Skip files, e.g. src/test" title="Comma-separated path globs the rule never touches" />
         `;
         container.appendChild(div);
       }
 
+      // Property rules name a <properties> entry and its new value ("latest" = newest release on Maven Central),
+      // regex rules use Go syntax and may reference capture groups as $1 or ${name}
+      const replacementPlaceholders = {
+        "": ["Search Text", "Replacement"],
+        regex: ["Regex, e.g. <version>2\\.(\\d+)</version>", "Replacement, e.g. <version>3.$1</version>"],
+        property: ["Property name, e.g. jackson.version", "New value or 'latest'"],
+      };
+
+      function updateReplacementType(select) {
+        const row = select.closest(".replacement-row");
+        const [search, replace] = replacementPlaceholders[select.value] || replacementPlaceholders[""];
+        const searchInput = row.querySelector(".replacement-search");
+        searchInput.placeholder = search;
+        searchInput.classList.remove("input-error");
+        searchInput.title = "";
+        row.querySelector(".replacement-replace").placeholder = replace;
+      }
+
+      // Returns the filled-in replacement rules and their rows (for marking invalid ones)
+      function collectReplacements() {
+        const replacements = [];
+        const rows = [];
+        document
+          .querySelectorAll("#replacements-list .replacement-row")
+          .forEach((row) => {
+            const search = row.querySelector(".replacement-search").value;
+            const replace = row.querySelector(".replacement-replace").value;
+            const type = row.querySelector(".replacement-type")?.value || "";
+            const globs = (cls) => (row.querySelector(cls)?.value || "").split(",").map(p => p.trim()).filter(Boolean);
+            if (search) {
+              replacements.push({ Search: search, Replace: replace, Type: type, Include: globs(".replacement-include"), Exclude: globs(".replacement-exclude") });
+              rows.push(row);
+            }
+          });
+        return { replacements, rows };
+      }
+
+      // Shows the diffs the replacements would produce in the current working trees, without running anything
+      async function previewReplacements() {
+        const rootPath = document.getElementById("rootPath")?.value;
+        if (!rootPath) {
+          showToast('Error', 'Please configure a root path in Project Setup first.', 'error');
+          return;
+        }
+        const { replacements, rows } = collectReplacements();
+        const container = document.getElementById("replacement-preview");
+        if (replacements.length === 0) {
+          container.innerHTML = '<div class="hint">No replacements defined.</div>';
+          return;
+        }
+        const btn = document.getElementById("preview-replacements-btn");
+        btn.disabled = true;
+
+        const previews = [];
+        try {
+          if (!(await validateReplacements(replacements, rows))) return;
+          container.innerHTML = '<div class="hint">Computing preview...</div>';
+
+          const res = await fetch("/api/preview-replacements", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({
+              rootPath,
+              excluded: getExcludedProjects(),
+              group: getSelectedGroup(),
+              replacements,
+              replacementScope: document.querySelector('input[name="replacementScope"]:checked')?.value || "all",
+            }),
+          });
+          if (!res.ok) throw new Error(await res.text());
+
+          const reader = res.body.getReader();
+          const decoder = new TextDecoder();
+          let buffer = '';
+          while (true) {
+            const { done, value } = await reader.read();
+            if (done) break;
+            buffer += decoder.decode(value, { stream: true });
+            const lines = buffer.split("\n");
+            buffer = lines.pop();
+            for (const line of lines) {
+              if (line.startsWith("REPO_START:")) {
+                container.innerHTML = `<div class="hint">Previewing ${escapeHtml(line.substring(11))}...</div>`;
+              } else if (line.startsWith("REPO_RESULT:")) {
+                const preview = JSON.parse(line.substring(12));
+                if (preview.error || preview.files.length > 0) previews.push(preview);
+              }
+            }
+          }
+
+          if (previews.length === 0) {
+            container.innerHTML = '<div class="hint">The replacements would not change any file.</div>';
+            return;
+          }
+          const fileCount = previews.reduce((sum, p) => sum + p.files.length, 0);
+          container.innerHTML = `<div class="hint">${fileCount} files in ${previews.length} repositories would change.</div>` +
+            previews.map(p => `
+              <details style="padding: 4px 0; border-bottom: 1px solid var(--border-color);">
+                <summary style="cursor: pointer;"><strong>${escapeHtml(p.repoName)}</strong>
+                  <span style="color: #9ca0b0; font-size: 0.85em;">${p.files.length} files</span>
+                  ${p.error ? `<span class="log-error">${escapeHtml(p.error)}</span>` : ''}</summary>
+                ${p.files.map(f => `<pre class="diff-preview">${renderDiff(f.diff)}</pre>`).join('')}
+              </details>`).join('');
+        } catch (e) {
+          container.innerHTML = `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
+        } finally {
+          btn.disabled = false;
+        }
+      }
+
+      function renderDiff(diff) {
+        return diff.split("\n").map(line => {
+          const cls = line.startsWith("+++") || line.startsWith("---") ? "diff-file"
+            : line.startsWith("@@") ? "diff-hunk"
+            : line.startsWith("+") ? "diff-add"
+            : line.startsWith("-") ? "diff-del" : "";
+          return cls ? `<span class="${cls}">${escapeHtml(line)}</span>` : escapeHtml(line);
+        }).join("\n");
+      }
+
+      // Compiles the rules on the server; marks invalid rows and returns false if any rule is broken
+      async function validateReplacements(replacements, rows) {
+        const response = await fetch("/api/validate-replacements", {
+          method: "POST",
+          headers: { "Content-Type": "application/json" },
+          body: JSON.stringify(replacements),
+        });
+        const result = await response.json();
+        rows.forEach((row) => {
+          const search = row.querySelector(".replacement-search");
+          search.classList.remove("input-error");
+          search.title = "";
+        });
+        (result.issues || []).forEach((issue) => {
+          const search = rows[issue.index]?.querySelector(".replacement-search");
+          if (search) {
+            search.classList.add("input-error");
+            search.title = issue.error;
+          }
+        });
+        if (!result.valid) {
+          const first = result.issues[0];
+          showToast("Invalid replacement", `${first.search}: ${first.error}`, "error", 6000);
+        }
+        return result.valid;
+      }
+
       function removeRow(btn) {
         const row = btn.parentElement;
         // Optional: Prevent removing the last row if desired, but user asked to remove rows.
@@ -315,9 +501,41 @@
         // Reset all form fields
         document.getElementById("rootPath").value = "";
         document.getElementById("parentVersion").value = "";
+        document.getElementById("parent-suggestions").innerHTML = "";
+        document.getElementById("nodeVersion").value = "";
         document.getElementById("versionBumpStrategy").value = "patch";
         document.getElementById("runCleanInstall").checked = false;
+        document.getElementById("verificationLevel").value = "";
+        document.getElementById("verificationGoal").value = "";
+        document.getElementById("verificationGoal").style.display = "none";
         document.getElementById("customBranchName").value = "";
+        document.getElementById("ignorePaths").value = "";
+        document.getElementById("tagPattern").value = "";
+        document.getElementById("tagPrefix").value = "";
+        document.getElementById("mavenHome").value = "";
+        document.getElementById("mavenSettingsFile").value = "";
+        document.getElementById("mavenProfiles").value = "";
+        document.getElementById("mavenExtraArgs").value = "";
+        document.getElementById("mavenUseWrapper").checked = true;
+        document.getElementById("goUpdateMode").value = "";
+        document.getElementById("goModules").value = "";
+        document.getElementById("goVersion").value = "";
+        document.getElementById("goToolchain").value = "";
+        document.getElementById("lfsPatterns").value = "";
+        document.getElementById("formatEnabled").checked = false;
+        document.querySelectorAll(".format-formatter-cb").forEach((cb) => (cb.checked = true));
+        document.getElementById("updateChangelog").checked = false;
+        ["python", "php"].forEach((eco) => {
+          document.getElementById(`${eco}Update`).checked = false;
+          document.getElementById(`${eco}Packages`).value = "";
+          document.getElementById(`${eco}Verify`).value = "";
+        });
+
+        document.getElementById("commitStrategy").value = "per-file";
+        document.getElementById("dirtyTree").value = "stash-restore";
+        document.getElementById("checkGitAccess").checked = false;
+        applySigningSettings({});
+        applyCreateTagSettings({});
 
         // Reset branch strategy to default (No Branch)
         document.getElementById("branch_none").checked = true;
@@ -333,8 +551,15 @@
           replacementsList.innerHTML = `
             <div class="replacement-row">
               <button class="btn-remove" onclick="removeRow(this)" title="Remove Row">-</button>
+              <select class="replacement-type" onchange="updateReplacementType(this)" aria-label="Replacement type" style="width: auto;">
+                <option value="">Text</option>
+                <option value="regex">Regex</option>
+                <option value="property">Maven property</option>
+              </select>
               <textarea placeholder="Search Text" class="replacement-search" oninput="autoResize(this)"></textarea>
               <textarea placeholder="Replacement" class="replacement-replace" oninput="autoResize(this)"></textarea>
+              <input type="text" class="replacement-include" placeholder="Only files, e.g. **/*.yaml" title="Comma-separated path globs the rule is limited to" />
+              <input type="text" class="replacement-exclude" placeholder="Skip files, e.g. src/test" title="Comma-separated path globs the rule never touches" />
             </div>
           `;
         }
@@ -350,17 +575,8 @@
           '<div class="log-info">Waiting for results...</div>';
       }
 
-      async function runHousekeeper() {
-        showTab("report");
-        const log = document.getElementById("report-log");
-        const deprecationLog = document.getElementById("deprecation-log");
-        const loading = document.getElementById("loading");
-
-        log.innerHTML = "";
-        deprecationLog.innerHTML = "";
-        loading.classList.remove("hidden");
-        isProcessRunning = true; // Mark process as running;
-
+      // Collects the run configuration from the form; returns null (after telling the user) if it is incomplete
+      function buildRunRequest() {
         // Determine Target Branch
         let targetBranch = "";
         if (document.getElementById("branch_housekeeping").checked) {
@@ -371,43 +587,81 @@
             .value.trim();
           if (!targetBranch) {
             alert("Please enter a branch name.");
-            loading.classList.add("hidden");
-            return;
+            return null;
           }
         } else {
           // None selected -> Master
           targetBranch = "";
         }
 
-        // Collect Data
-        // Calculate Excluded Folders from Checkboxes
-        const excluded = ["node_modules", "target", "dist", ".idea", ".vscode"]; // Standard defaults
-        document
-          .querySelectorAll('#folder-list-container input[type="checkbox"]')
-          .forEach((cb) => {
-            if (!cb.checked) {
-              excluded.push(cb.value);
-            }
-          });
-
         const data = {
           rootPath: document.getElementById("rootPath").value,
-          excluded: excluded,
+          excluded: getExcludedProjects(),
+          group: getSelectedGroup(),
           parentVersion: document.getElementById("parentVersion").value,
+          repoParentVersions: getRepoParentVersions(),
+          nodeVersion: document.getElementById("nodeVersion").value.trim(),
           versionBumpStrategy: document.getElementById("versionBumpStrategy")
             .value,
           runCleanInstall: document.getElementById("runCleanInstall").checked,
+          verificationLevel: document.getElementById("verificationLevel").value,
+          verificationGoal: document.getElementById("verificationGoal").value.trim(),
           targetBranch: targetBranch,
-          replacements: [],
+          commitStrategy: document.getElementById("commitStrategy").value,
+          dirtyTree: document.getElementById("dirtyTree").value,
+          checkGitAccess: document.getElementById("checkGitAccess").checked,
+          signing: getSigningSettings(),
+          createTag: getCreateTagSettings(),
+          replacements: collectReplacements().replacements,
           replacementScope: document.querySelector('input[name="replacementScope"]:checked')?.value || "all",
+          tags: {
+            pattern: document.getElementById("tagPattern").value.trim(),
+            prefix: document.getElementById("tagPrefix").value.trim(),
+          },
+          maven: getMavenSettings(),
+          go: getGoSettings(),
+          python: getEcosystemSettings("python"),
+          php: getEcosystemSettings("php"),
+          lfs: { patterns: document.getElementById("lfsPatterns").value.split(/\s+/).filter((p) => p) },
+          format: getFormatSettings(),
+          changelog: document.getElementById("updateChangelog").checked,
+          label: document.getElementById("runLabel").value.trim(),
+          description: document.getElementById("runDescription").value.trim(),
+          maxDurationMinutes: parseInt(document.getElementById("runMaxDuration").value, 10) || 0,
+          timeouts: {
+            gitMinutes: parseInt(document.getElementById("timeoutGit").value, 10) || 0,
+            mavenMinutes: parseInt(document.getElementById("timeoutMaven").value, 10) || 0,
+            replacementsMinutes: parseInt(document.getElementById("timeoutReplacements").value, 10) || 0,
+            repoMinutes: parseInt(document.getElementById("timeoutRepo").value, 10) || 0,
+          },
+          includeRepos: getRepoSelection("includeRepos"),
+          excludeRepos: getRepoSelection("excludeRepos"),
+          emailReport: document.getElementById("runEmailReport").checked,
+          emailTo: getRepoSelection("runEmailTo"),
         };
 
         if (!data.rootPath) {
           alert("Please specify a project path.");
-          loading.classList.add("hidden");
           showTab("settings");
-          return;
+          return null;
         }
+        return data;
+      }
+
+      async function runHousekeeper() {
+        const data = buildRunRequest();
+        if (!data) return;
+
+        showTab("report");
+        const log = document.getElementById("report-log");
+        const deprecationLog = document.getElementById("deprecation-log");
+        const loading = document.getElementById("loading");
+
+        log.innerHTML = "";
+        deprecationLog.innerHTML = "";
+        document.getElementById("deprecation-summary").innerHTML = "";
+        loading.classList.remove("hidden");
+        isProcessRunning = true; // Mark process as running;
 
         // Save settings to localStorage
         localStorage.setItem(
@@ -416,26 +670,41 @@
             rootPath: data.rootPath,
             // excluded: document.getElementById('excluded').value, // No longer saving excluded list as text
             parentVersion: data.parentVersion,
+            nodeVersion: data.nodeVersion,
             versionBumpStrategy: data.versionBumpStrategy,
             runCleanInstall: data.runCleanInstall,
+            verificationLevel: data.verificationLevel,
+            verificationGoal: data.verificationGoal,
             branchStrategy: document.querySelector(
               'input[name="branchStrategy"]:checked'
             ).value,
             customBranchName: document.getElementById("customBranchName").value,
+            commitStrategy: data.commitStrategy,
+            group: data.group,
+            dirtyTree: data.dirtyTree,
+            checkGitAccess: data.checkGitAccess,
+            signing: data.signing,
+            createTag: data.createTag,
             replacementScope: data.replacementScope,
+            ignorePaths: document.getElementById("ignorePaths").value,
+            tags: data.tags,
+            maven: data.maven,
+            go: data.go,
+            python: data.python,
+            php: data.php,
           })
         );
 
-        // Collect Replacements
-        document
-          .querySelectorAll("#replacements-list .replacement-row")
-          .forEach((row) => {
-            const search = row.querySelector(".replacement-search").value;
-            const replace = row.querySelector(".replacement-replace").value;
-            if (search) {
-              data.replacements.push({ Search: search, Replace: replace });
-            }
-          });
+        try {
+          if (data.replacements.length > 0 && !(await validateReplacements(data.replacements, collectReplacements().rows))) {
+            loading.classList.add("hidden");
+            isProcessRunning = false;
+            showTab("settings");
+            return;
+          }
+        } catch (e) {
+          showToast('Error', `Could not validate replacements: ${e.message}`, 'error');
+        }
 
         try {
           const response = await fetch("/api/run", {
@@ -444,1132 +713,4387 @@
             body: JSON.stringify(data),
           });
 
-          const reader = response.body.getReader();
-          const decoder = new TextDecoder("utf-8");
-          let isDeprecation = false;
-
-          while (true) {
-            const { done, value } = await reader.read();
-            if (done) break;
-
-            const chunk = decoder.decode(value, { stream: true });
-            const lines = chunk.split("\n");
-
-            for (let line of lines) {
-              if (!line.trim()) continue;
-
-              if (line.startsWith("DEPRECATION_START:")) {
-                isDeprecation = true;
-                const repoName = line.split(":")[1];
-                const header = document.createElement("div");
-                header.className = "log-repo";
-                header.textContent = repoName;
-                deprecationLog.appendChild(header);
-                continue;
-              }
-              if (line.startsWith("DEPRECATION_END")) {
-                isDeprecation = false;
-                continue;
-              }
-
-              if (isDeprecation) {
-                const div = document.createElement("div");
-                div.className = "log-warning";
-                div.textContent = line;
-                deprecationLog.appendChild(div);
-                deprecationLog.scrollTop = deprecationLog.scrollHeight;
-                continue;
-              }
-
-              const div = document.createElement("div");
-              if (line.startsWith("REPO:")) {
-                div.className = "log-repo";
-                div.textContent = line.substring(5);
-              } else if (line.includes("[ERROR]") || line.includes("✗")) {
-                div.className = "log-error";
-                div.textContent = line;
-              } else if (
-                line.includes("[WARNING]") ||
-                line.toLowerCase().includes("warning") ||
-                line.toLowerCase().includes("deprecated")
-              ) {
-                div.className = "log-warning";
-                div.textContent = line;
-              } else if (line.includes("✓") || line.includes("success")) {
-                div.className = "log-success";
-                div.textContent = line;
-              } else {
-                div.className = "log-info";
-                div.textContent = line;
-              }
-              log.appendChild(div);
-              log.scrollTop = log.scrollHeight;
-            }
-          }
+          await readRunStream(response, log, deprecationLog);
         } catch (e) {
-          log.innerHTML += `<div class="log-error">Error: ${e.message}</div>`;
+          log.innerHTML += `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
           showToast('Error', `An error occurred: ${e.message}`, 'error');
         } finally {
           loading.classList.add("hidden");
           isProcessRunning = false; // Mark process as complete
           log.innerHTML +=
             '<div class="log-info" style="margin-top:20px; border-top:1px solid #333; padding-top:10px;">--- Done ---</div>';
+          appendRunActions(log);
           showToast('Complete', 'The housekeeping process has finished.', 'success', 4000);
         }
       }
 
-      // Load settings on startup
-      window.addEventListener("DOMContentLoaded", () => {
-        const saved = localStorage.getItem("gitHousekeeper_settings");
-        if (saved) {
-          try {
-            const settings = JSON.parse(saved);
-            if (settings.rootPath) {
-              document.getElementById("rootPath").value = settings.rootPath;
-              loadFolders(); // Trigger load
-            }
-            // if (settings.excluded) document.getElementById('excluded').value = settings.excluded;
-            if (settings.parentVersion)
-              document.getElementById("parentVersion").value =
-                settings.parentVersion;
-            if (settings.versionBumpStrategy)
-              document.getElementById("versionBumpStrategy").value =
-                settings.versionBumpStrategy;
-            if (settings.runCleanInstall !== undefined)
-              document.getElementById("runCleanInstall").checked =
-                settings.runCleanInstall;
+      // ==================== RUN PROFILES ====================
 
-            if (settings.branchStrategy) {
-              const rb = document.querySelector(
-                `input[name="branchStrategy"][value="${settings.branchStrategy}"]`
-              );
-              if (rb) rb.checked = true;
-            }
-            if (settings.customBranchName)
-              document.getElementById("customBranchName").value =
-                settings.customBranchName;
-            toggleBranchInput();
-          } catch (e) {
-            console.error("Failed to load settings", e);
-          }
+      let runProfiles = [];
+      let repoGroups = [];
+
+      async function loadGroups() {
+        const select = document.getElementById("groupSelect");
+        if (!select) return;
+        try {
+          const res = await fetch("/api/groups");
+          if (!res.ok) throw new Error(await res.text());
+          repoGroups = await res.json();
+        } catch (e) {
+          repoGroups = [];
+          console.error("Failed to load groups", e);
         }
+        const current = select.value || JSON.parse(localStorage.getItem("gitHousekeeper_settings") || "{}").group || "";
+        select.innerHTML = '<option value="">-- All repositories under the root path --</option>' +
+          repoGroups.map(g => `<option value="${escapeHtml(g.id)}" title="${escapeHtml(g.repos.join('\n'))}">${escapeHtml(g.name)} (${g.repos.length})</option>`).join('');
+        select.value = repoGroups.some(g => g.id === current) ? current : "";
+      }
 
-        // Auto-load folders when typing/pasting path
-        const rootPathInput = document.getElementById("rootPath");
-        let debounceTimer;
-        rootPathInput.addEventListener("input", () => {
-          clearTimeout(debounceTimer);
-          debounceTimer = setTimeout(() => {
-            loadFolders();
-          }, 500);
-        });
+      function getSelectedGroup() {
+        return document.getElementById("groupSelect")?.value || "";
+      }
 
-        // Dashboard Logic
-        const savedSettings = localStorage.getItem("gitHousekeeper_settings");
-        if (savedSettings) {
-          try {
-            const settings = JSON.parse(savedSettings);
-            if (settings.rootPath) {
-              document.getElementById("rootPath").value = settings.rootPath;
-              loadDashboardStats(settings.rootPath);
-              // Also trigger folder load for settings tab
-              loadFolders();
-            } else {
-               // No path, show empty state
-               document.getElementById("dashboard-empty").classList.remove("hidden");
-               document.getElementById("dashboard-path-header").classList.add("hidden");
-            }
-          } catch (e) {
-            console.error("Error loading settings for dashboard", e);
-          }
-        } else {
-           document.getElementById("dashboard-empty").classList.remove("hidden");
-           document.getElementById("dashboard-path-header").classList.add("hidden");
+      async function saveGroup() {
+        const rootPath = document.getElementById("rootPath").value;
+        const folders = Array.from(document.querySelectorAll('#folder-list-container input[type="checkbox"]:checked')).map(cb => cb.value);
+        // A root path that is a repository itself has no folder list
+        const repos = folders.length ? folders : (rootPath ? [rootPath] : []);
+        if (repos.length === 0) {
+          showToast('Error', 'Please select a root path and include at least one project.', 'error');
+          return;
         }
-      });
-
-      async function loadDashboardStats(rootPath) {
-        lastLoadedPath = rootPath;
-        const content = document.getElementById("dashboard-content");
-        const empty = document.getElementById("dashboard-empty");
-        const header = document.getElementById("dashboard-path-header");
-        const tbody = document.getElementById("repo-table-body");
+        const current = repoGroups.find(g => g.id === getSelectedGroup());
+        const name = prompt(`Group name for ${repos.length} repositories (an existing group with the same name is replaced):`, current?.name || "");
+        if (!name || !name.trim()) return;
 
-        empty.classList.add("hidden");
-        header.classList.remove("hidden");
-        content.classList.remove("hidden");
+        try {
+          const res = await fetch("/api/groups", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({ name: name.trim(), rootPath, repos }),
+          });
+          if (!res.ok) throw new Error(await res.text());
+          const group = await res.json();
+          await loadGroups();
+          document.getElementById("groupSelect").value = group.id;
+          showToast('Saved', `Group "${group.name}" saved with ${group.repos.length} entries.`, 'success');
+        } catch (e) {
+          showToast('Error', `Could not save group: ${e.message}`, 'error');
+        }
+      }
 
-        // Reset UI
-        tbody.innerHTML = "";
-        document.getElementById("metric-health").innerText = "--";
-        document.getElementById("metric-repos").innerText = "0";
-        document.getElementById("metric-todos").innerText = "0";
-        document.getElementById("metric-outdated").innerText = "0";
-        document.getElementById("chart-deps").innerHTML = '<div class="hint">Loading...</div>';
-        document.getElementById("chart-frameworks").innerHTML = '<div class="hint">Loading...</div>';
+      async function deleteSelectedGroup() {
+        const group = repoGroups.find(g => g.id === getSelectedGroup());
+        if (!group) {
+          showToast('No group', 'Please select a group first.', 'warning');
+          return;
+        }
+        if (!confirm(`Delete group "${group.name}"? The repositories are not touched.`)) return;
+        try {
+          const res = await fetch(`/api/groups/${encodeURIComponent(group.id)}`, { method: "DELETE" });
+          if (!res.ok) throw new Error(await res.text());
+          document.getElementById("groupSelect").value = "";
+          await loadGroups();
+          showToast('Deleted', `Group "${group.name}" deleted.`, 'success');
+        } catch (e) {
+          showToast('Error', `Could not delete group: ${e.message}`, 'error');
+        }
+      }
 
-        // Reset Stats
-        currentStats = {
-            totalRepos: 0,
-            repoDetails: [],
-            frameworks: {},      // Framework distribution
-            topDependencies: {}, // Map for easy counting
-            totalTodos: 0,
-            totalHealth: 0,
-            totalOutdated: 0
-        };
+      async function loadProfiles() {
+        const select = document.getElementById("profileSelect");
+        if (!select) return;
+        try {
+          const res = await fetch("/api/profiles");
+          if (!res.ok) throw new Error(await res.text());
+          runProfiles = await res.json();
+        } catch (e) {
+          runProfiles = [];
+          console.error("Failed to load profiles", e);
+        }
+        const current = select.value;
+        select.innerHTML = '<option value="">-- Saved profiles --</option>' +
+          runProfiles.map(p => `<option value="${escapeHtml(p.id)}">${escapeHtml(p.name)}</option>`).join('');
+        select.value = runProfiles.some(p => p.id === current) ? current : "";
+      }
+
+      function selectedProfile() {
+        const id = document.getElementById("profileSelect").value;
+        const profile = runProfiles.find(p => p.id === id);
+        if (!profile) showToast('No profile', 'Please select a saved profile first.', 'warning');
+        return profile;
+      }
+
+      async function saveProfile() {
+        const data = buildRunRequest();
+        if (!data) return;
+        const current = runProfiles.find(p => p.id === document.getElementById("profileSelect").value);
+        const name = prompt("Profile name (an existing profile with the same name is replaced):", current?.name || document.getElementById("runLabel").value.trim());
+        if (!name || !name.trim()) return;
 
         try {
-          const response = await fetch("/api/dashboard-stats", {
+          const res = await fetch("/api/profiles", {
             method: "POST",
             headers: { "Content-Type": "application/json" },
-            body: JSON.stringify({ RootPath: rootPath, Excluded: [] })
+            body: JSON.stringify({ name: name.trim(), request: data }),
           });
+          if (!res.ok) throw new Error(await res.text());
+          const profile = await res.json();
+          await loadProfiles();
+          document.getElementById("profileSelect").value = profile.id;
+          showToast('Saved', `Profile "${profile.name}" saved.`, 'success');
+        } catch (e) {
+          showToast('Error', `Could not save profile: ${e.message}`, 'error');
+        }
+      }
 
-          if (!response.ok) throw new Error("Failed to load stats");
+      // Fills the form with the stored run request of the selected profile
+      async function applySelectedProfile() {
+        const profile = selectedProfile();
+        if (!profile) return;
+        const req = profile.request || {};
+        const set = (id, value) => { const el = document.getElementById(id); if (el) el.value = value ?? ""; };
+
+        set("rootPath", req.RootPath);
+        set("groupSelect", repoGroups.some(g => g.id === req.Group) ? req.Group : "");
+        set("parentVersion", req.ParentVersion);
+        set("nodeVersion", req.NodeVersion);
+        set("versionBumpStrategy", req.VersionBumpStrategy);
+        document.getElementById("runCleanInstall").checked = !!req.RunCleanInstall;
+        set("verificationLevel", req.VerificationLevel);
+        set("verificationGoal", req.VerificationGoal);
+        document.getElementById("verificationLevel").dispatchEvent(new Event("change"));
+        set("runLabel", req.Label);
+        set("includeRepos", (req.IncludeRepos || []).join(", "));
+        set("excludeRepos", (req.ExcludeRepos || []).join(", "));
+        document.getElementById("runEmailReport").checked = !!req.EmailReport;
+        set("runEmailTo", (req.EmailTo || []).join(", "));
+        set("runDescription", req.Description);
+        set("runMaxDuration", req.MaxDurationMinutes || "");
+        const timeouts = req.Timeouts || {};
+        set("timeoutGit", timeouts.gitMinutes || "");
+        set("timeoutMaven", timeouts.mavenMinutes || "");
+        set("timeoutReplacements", timeouts.replacementsMinutes || "");
+        set("timeoutRepo", timeouts.repoMinutes || "");
+
+        const branch = req.TargetBranch || "";
+        document.getElementById(branch === "" ? "branch_none" : branch === "housekeeping" ? "branch_housekeeping" : "branch_custom").checked = true;
+        set("customBranchName", branch === "housekeeping" ? "" : branch);
+        toggleBranchInput();
+        set("commitStrategy", req.CommitStrategy || "per-file");
+        set("dirtyTree", req.DirtyTree || "stash-restore");
+        document.getElementById("checkGitAccess").checked = !!req.CheckGitAccess;
+        applySigningSettings(req.Signing || {});
+        applyCreateTagSettings(req.CreateTag || {});
+
+        const scope = document.querySelector(`input[name="replacementScope"][value="${req.ReplacementScope || "all"}"]`);
+        if (scope) scope.checked = true;
+        const list = document.getElementById("replacements-list");
+        list.innerHTML = "";
+        (req.Replacements || []).forEach((r) => {
+          addRow("replacements-list");
+          const row = list.lastElementChild;
+          row.querySelector(".replacement-type").value = r.Type || "";
+          updateReplacementType(row.querySelector(".replacement-type"));
+          row.querySelector(".replacement-search").value = r.Search || "";
+          row.querySelector(".replacement-replace").value = r.Replace || "";
+          row.querySelector(".replacement-include").value = (r.Include || []).join(", ");
+          row.querySelector(".replacement-exclude").value = (r.Exclude || []).join(", ");
+        });
+        if (list.children.length === 0) addRow("replacements-list");
+
+        set("tagPattern", req.Tags?.pattern);
+        set("tagPrefix", req.Tags?.prefix);
+        const maven = req.Maven || {};
+        set("mavenHome", maven.mavenHome);
+        set("mavenSettingsFile", maven.settingsFile);
+        set("mavenProfiles", maven.profiles);
+        set("mavenExtraArgs", (maven.extraArgs || []).join(" "));
+        document.getElementById("mavenUseWrapper").checked = maven.useWrapper ?? true;
+        const go = req.Go || {};
+        set("goUpdateMode", go.updateMode);
+        set("goModules", (go.modules || []).join(" "));
+        set("goVersion", go.goVersion);
+        set("goToolchain", go.toolchain);
+        set("lfsPatterns", (req.LFS?.patterns || []).join(" "));
+        document.getElementById("formatEnabled").checked = !!req.Format?.enabled;
+        document.querySelectorAll(".format-formatter-cb").forEach((cb) => {
+          cb.checked = !req.Format?.formatters?.length || req.Format.formatters.includes(cb.value);
+        });
+        document.getElementById("updateChangelog").checked = !!req.Changelog;
+        [["python", req.Python], ["php", req.Php]].forEach(([eco, settings]) => {
+          document.getElementById(`${eco}Update`).checked = !!settings?.update;
+          set(`${eco}Packages`, (settings?.packages || []).join(" "));
+          set(`${eco}Verify`, settings?.verifyCommand);
+        });
 
-          const reader = response.body.getReader();
-          const decoder = new TextDecoder();
-          let buffer = "";
+        // Restore the project exclusions once the folder list of the root path is loaded
+        await loadFolders();
+        const excluded = new Set(req.Excluded || []);
+        document.querySelectorAll('#folder-list-container input[type="checkbox"]').forEach((cb) => {
+          cb.checked = !excluded.has(cb.value);
+        });
+        showToast('Loaded', `Profile "${profile.name}" loaded.`, 'success');
+      }
 
-          while (true) {
-            const { done, value } = await reader.read();
-            if (done) break;
+      async function runSelectedProfile() {
+        const profile = selectedProfile();
+        if (!profile) return;
+        if (isProcessRunning) {
+          showToast('Busy', 'Another process is still running.', 'error');
+          return;
+        }
+        showTab("report");
+        const log = document.getElementById("report-log");
+        const deprecationLog = document.getElementById("deprecation-log");
+        const loading = document.getElementById("loading");
+        log.innerHTML = "";
+        deprecationLog.innerHTML = "";
+        loading.classList.remove("hidden");
+        isProcessRunning = true;
 
-            buffer += decoder.decode(value, { stream: true });
-            const lines = buffer.split("\n");
-            buffer = lines.pop(); // Keep incomplete line
+        try {
+          const response = await fetch(`/api/profiles/${encodeURIComponent(profile.id)}/run`, { method: "POST" });
+          if (!response.ok) throw new Error(await response.text());
+          await readRunStream(response, log, deprecationLog);
+          appendRunActions(log);
+          showToast('Complete', `Profile "${profile.name}" has finished.`, 'success', 4000);
+        } catch (e) {
+          log.innerHTML += `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
+          showToast('Error', `Profile run failed: ${e.message}`, 'error');
+        } finally {
+          loading.classList.add("hidden");
+          isProcessRunning = false;
+        }
+      }
 
-            for (const line of lines) {
-                if (!line.trim()) continue;
-                try {
-                    const msg = JSON.parse(line);
-                    handleDashboardMessage(msg);
-                } catch (e) {
-                    console.error("Error parsing JSON", e);
-                }
-            }
-          }
+      async function deleteSelectedProfile() {
+        const profile = selectedProfile();
+        if (!profile || !confirm(`Delete profile "${profile.name}"?`)) return;
+        try {
+          const res = await fetch(`/api/profiles/${encodeURIComponent(profile.id)}`, { method: "DELETE" });
+          if (!res.ok) throw new Error(await res.text());
+          await loadProfiles();
+          showToast('Deleted', `Profile "${profile.name}" deleted.`, 'success');
         } catch (e) {
-          console.error(e);
-          // content.classList.add("hidden");
+          showToast('Error', `Could not delete profile: ${e.message}`, 'error');
         }
       }
 
-      function handleDashboardMessage(msg) {
-        if (msg.type === "init") {
-            currentStats.totalRepos = msg.totalRepos;
-            document.getElementById("metric-repos").innerText = msg.totalRepos;
-            // Clear charts
-            document.getElementById("chart-deps").innerHTML = "";
-            document.getElementById("chart-frameworks").innerHTML = "";
-        } else if (msg.type === "repo") {
-            const repo = msg.data;
-            const deps = msg.deps || [];
+      let lastRunId = null;
+      let lastRunRemaining = 0;
+      let lastRunFailed = 0;
 
-            // Update Stats
-            currentStats.repoDetails.push(repo);
-            currentStats.totalTodos += repo.todoCount;
-            currentStats.totalHealth += repo.healthScore;
-            currentStats.totalOutdated += repo.outdatedDeps || 0;
+      // Renders the streamed log of /api/run (or a resumed run) into the report panels
+      async function readRunStream(response, log, deprecationLog) {
+        const reader = response.body.getReader();
+        const decoder = new TextDecoder("utf-8");
+        let isDeprecation = false;
+        lastRunId = null;
+        lastRunRemaining = 0;
+        lastRunFailed = 0;
 
-            // Track frameworks (including Spring Boot versions as separate entries)
-            if (repo.framework) {
-                let frameworkLabel = repo.framework;
-                // Add version info for Spring Boot
-                if (repo.framework === "Spring Boot" && repo.springBootVer) {
-                    frameworkLabel = `Spring Boot ${repo.springBootVer}`;
-                }
-                currentStats.frameworks[frameworkLabel] = (currentStats.frameworks[frameworkLabel] || 0) + 1;
-            } else if (repo.projectType === "maven" && repo.springBootVer) {
-                const frameworkLabel = `Spring Boot ${repo.springBootVer}`;
-                currentStats.frameworks[frameworkLabel] = (currentStats.frameworks[frameworkLabel] || 0) + 1;
-            } else if (repo.projectType && repo.projectType !== "unknown") {
-                // For projects without detected framework, use project type
-                const typeLabels = {
-                    'npm': 'Node.js',
-                    'yarn': 'Node.js',
-                    'pnpm': 'Node.js',
-                    'go': 'Go',
-                    'python': 'Python',
-                    'maven': 'Maven'
-                };
-                const frameworkLabel = typeLabels[repo.projectType] || repo.projectType;
-                currentStats.frameworks[frameworkLabel] = (currentStats.frameworks[frameworkLabel] || 0) + 1;
+        while (true) {
+          const { done, value } = await reader.read();
+          if (done) break;
+
+          const chunk = decoder.decode(value, { stream: true });
+          const lines = chunk.split("\n");
+
+          for (let line of lines) {
+            if (!line.trim()) continue;
+
+            if (line.startsWith("RUN_ID:")) {
+              lastRunId = line.substring(7).trim();
+              continue;
+            }
+            if (line.startsWith("RUN_REPORT:")) {
+              continue;
+            }
+            if (line.startsWith("TIME_BUDGET_EXHAUSTED:")) {
+              lastRunRemaining = parseInt(line.split(":")[1], 10) || 0;
+              continue;
+            }
+            if (line.startsWith("DIRTY_ABORT:")) {
+              showToast('Run Aborted', `${line.split(":")[1]} repositories have uncommitted changes.`, 'error', 8000);
+              continue;
+            }
+            if (line.startsWith("AUTH_ABORT:")) {
+              showToast('Run Aborted', `${line.split(":")[1]} repositories cannot reach their remote.`, 'error', 8000);
+              continue;
+            }
+            if (line.startsWith("TOOLS_ABORT:")) {
+              showToast('Run Aborted', `${line.split(":")[1]} required tools are not installed, see the log.`, 'error', 8000);
+              continue;
             }
 
-            deps.forEach(d => {
-                currentStats.topDependencies[d] = (currentStats.topDependencies[d] || 0) + 1;
-            });
+            if (line.startsWith("DEPRECATION_START:")) {
+              isDeprecation = true;
+              const repoName = line.split(":")[1];
+              const header = document.createElement("div");
+              header.className = "log-repo";
+              header.textContent = repoName;
+              deprecationLog.appendChild(header);
+              continue;
+            }
+            if (line.startsWith("DEPRECATION_END")) {
+              isDeprecation = false;
+              continue;
+            }
 
-            // Update Metrics
-            const count = currentStats.repoDetails.length;
-            const avgHealth = Math.round(currentStats.totalHealth / count);
-            document.getElementById("metric-health").innerText = avgHealth + "/100";
-            document.getElementById("metric-todos").innerText = currentStats.totalTodos;
-            document.getElementById("metric-outdated").innerText = currentStats.totalOutdated;
+            if (isDeprecation) {
+              const div = document.createElement("div");
+              div.className = "log-warning";
+              div.textContent = line;
+              deprecationLog.appendChild(div);
+              deprecationLog.scrollTop = deprecationLog.scrollHeight;
+              continue;
+            }
 
-            // Add Row
-            addRepoRow(repo);
+            if (/^[✗–] .* (failed|skipped)( \(timeout: \w+\))?\.$/.test(line)) {
+              lastRunFailed++;
+            }
 
-            // Update Charts (Debounce could be good, but live is cool)
-            updateCharts();
-        } else if (msg.type === "done") {
-            // Final polish if needed
+            const div = document.createElement("div");
+            if (line.startsWith("REPO:")) {
+              div.className = "log-repo";
+              div.textContent = line.substring(5);
+            } else if (line.includes("[ERROR]") || line.includes("✗")) {
+              div.className = "log-error";
+              div.textContent = line;
+            } else if (
+              line.includes("[WARNING]") ||
+              line.toLowerCase().includes("warning") ||
+              line.toLowerCase().includes("deprecated")
+            ) {
+              div.className = "log-warning";
+              div.textContent = line;
+            } else if (line.includes("✓") || line.includes("success")) {
+              div.className = "log-success";
+              div.textContent = line;
+            } else {
+              div.className = "log-info";
+              div.textContent = line;
+            }
+            log.appendChild(div);
+            log.scrollTop = log.scrollHeight;
+          }
         }
       }
 
-      // Get framework icon/badge
-      function getFrameworkBadge(framework) {
-        const frameworkIcons = {
-            'Next.js': { icon: '▲', color: '#000' },
-            'Nuxt.js': { icon: '💚', color: '#00DC82' },
-            'Angular': { icon: '🅰️', color: '#DD0031' },
-            'Vue.js': { icon: '💚', color: '#4FC08D' },
-            'React': { icon: '⚛️', color: '#61DAFB' },
-            'Svelte': { icon: '🔥', color: '#FF3E00' },
-            'Express': { icon: '🚀', color: '#000' },
-            'Fastify': { icon: '⚡', color: '#000' },
-            'NestJS': { icon: '🐱', color: '#E0234E' },
-            'Gatsby': { icon: '💜', color: '#663399' },
-            'Remix': { icon: '💿', color: '#000' },
-            'Koa': { icon: '🥝', color: '#000' },
-            'Electron': { icon: '⚡', color: '#47848F' },
-            'Spring Boot': { icon: '🍃', color: '#6DB33F' },
-            // Go frameworks
-            'Go': { icon: '🐹', color: '#00ADD8' },
-            'Gin': { icon: '🐹', color: '#00ADD8' },
-            'Fiber': { icon: '🐹', color: '#00ADD8' },
-            'Echo': { icon: '🐹', color: '#00ADD8' },
-            'Chi': { icon: '🐹', color: '#00ADD8' },
-            'Gorilla Mux': { icon: '🐹', color: '#00ADD8' },
-            'gRPC': { icon: '🐹', color: '#00ADD8' },
-            // Python frameworks
-            'Python': { icon: '🐍', color: '#3776AB' },
-            'Django': { icon: '🐍', color: '#092E20' },
-            'Flask': { icon: '🐍', color: '#000' },
-            'FastAPI': { icon: '🐍', color: '#009688' },
-            'Streamlit': { icon: '🐍', color: '#FF4B4B' },
-            'PyTorch': { icon: '🔥', color: '#EE4C2C' },
-            'TensorFlow': { icon: '🧠', color: '#FF6F00' },
-            'Data Science': { icon: '📊', color: '#3776AB' },
-            // PHP frameworks
-            'PHP': { icon: '🐘', color: '#777BB4' },
-            'Laravel': { icon: '🐘', color: '#FF2D20' },
-            'Symfony': { icon: '🐘', color: '#000' },
-            'CodeIgniter': { icon: '🐘', color: '#EE4623' },
-            'CakePHP': { icon: '🐘', color: '#D33C44' },
-            'Yii': { icon: '🐘', color: '#40B3D8' },
-            'Slim': { icon: '🐘', color: '#74A045' }
-        };
-
-        const info = frameworkIcons[framework] || { icon: '📦', color: '#888' };
-        return `<span style="color: ${info.color};" title="${framework}">${info.icon}</span>`;
+      // Shows the deprecations of a run aggregated across repos and the change since the previous run
+      async function showDeprecationReport(runId) {
+        const summary = document.getElementById("deprecation-summary");
+        summary.innerHTML = '<div class="hint">Loading deprecation report...</div>';
+        try {
+          const res = await fetch(`/api/deprecations-report?run=${encodeURIComponent(runId)}`);
+          if (!res.ok) throw new Error(await res.text());
+          const report = await res.json();
+
+          let trend = '<span class="hint" style="margin: 0;">No previous run of this root path to compare with.</span>';
+          if (report.diff) {
+            const shrinking = report.diff.fixed >= report.diff.new;
+            trend = `<span class="${shrinking ? 'status-good' : 'status-bad'}">${report.diff.fixed} fixed, ${report.diff.new} new</span>
+              <span class="hint" style="margin: 0;">since ${new Date(report.diff.previousAt).toLocaleDateString()} (${report.diff.previousTotal} in the same repositories then)</span>`;
+          }
+          const delta = d => d === undefined || d === null ? '' : d < 0 ? ` <span class="status-good">(${d})</span>` : d > 0 ? ` <span class="status-bad">(+${d})</span>` : ' <span class="hint" style="margin: 0;">(±0)</span>';
+          const repos = report.repos.filter(r => r.count > 0 || (r.delta || 0) !== 0).map(r => `
+            <div style="display: flex; gap: 10px; padding: 2px 0; font-size: 0.9em;">
+              <strong style="flex: 1;">${escapeHtml(r.repoName)}</strong>
+              <span>${r.count}${delta(r.delta)}</span>
+            </div>`).join('');
+          const symbols = report.symbols.slice(0, 10).map(s => `
+            <div style="display: flex; gap: 10px; padding: 2px 0; font-size: 0.9em;">
+              <span style="flex: 1; font-family: 'Consolas', monospace; word-break: break-all;">${escapeHtml(s.symbol)}</span>
+              <span title="${escapeHtml(s.repos.join(', '))}">${s.count}× in ${s.repos.length} repo${s.repos.length === 1 ? '' : 's'}</span>
+            </div>`).join('');
+
+          summary.innerHTML = `
+            <div style="margin-bottom: 10px; padding-bottom: 10px; border-bottom: 1px solid var(--border-color);">
+              <div style="display: flex; gap: 10px; align-items: baseline; flex-wrap: wrap;">
+                <strong>${report.total} deprecation${report.total === 1 ? '' : 's'}</strong> ${trend}
+                <a class="btn btn-secondary" style="margin-left: auto;" href="/api/deprecations-report?run=${encodeURIComponent(runId)}" target="_blank" aria-label="Open the JSON deprecation report">{ } JSON</a>
+              </div>
+              ${repos ? `<div style="margin-top: 8px;">${repos}</div>` : ''}
+              ${symbols ? `<div style="margin-top: 8px;"><div class="hint" style="margin: 0 0 4px;">Most used deprecated APIs</div>${symbols}</div>` : ''}
+            </div>`;
+        } catch (e) {
+          summary.innerHTML = "";
+          showToast('Error', `Deprecation report failed: ${e.message}`, 'error');
+        }
       }
 
-      function addRepoRow(repo) {
-        const tbody = document.getElementById("repo-table-body");
-        let statusClass = "status-good";
-        let statusText = "Healthy";
-        if (repo.healthScore < 50) {
-            statusClass = "status-bad";
-            statusText = "Critical";
-        } else if (repo.healthScore < 80) {
-            statusClass = "status-warn";
-            statusText = "Warning";
+      function appendRunActions(log) {
+        if (!lastRunId) return;
+        let buttons = `<button class="btn btn-secondary" onclick="rollbackRun('${lastRunId}')" aria-label="Undo this housekeeping run">↩️ Undo this run</button>`;
+        buttons += ` <a class="btn btn-secondary" href="/api/run-report/${encodeURIComponent(lastRunId)}?format=html" aria-label="Download the HTML report of this run">📄 HTML report</a>`;
+        buttons += ` <button class="btn btn-secondary" onclick="downloadRunReportPdf('${lastRunId}')" aria-label="Download the PDF report of this run">📄 PDF</button>`;
+        buttons += ` <a class="btn btn-secondary" href="/api/run-report/${encodeURIComponent(lastRunId)}" target="_blank" aria-label="Open the JSON report of this run">{ } JSON</a>`;
+        buttons += ` <button class="btn btn-secondary" onclick="showDeprecationReport('${lastRunId}')" aria-label="Show the deprecations of this run compared with the previous run">📉 Deprecations</button>`;
+        if (lastRunRemaining > 0) {
+          buttons += ` <button class="btn btn-primary" onclick="resumeRun('${lastRunId}')" aria-label="Resume remaining repositories">▶️ Resume ${lastRunRemaining} remaining</button>`;
+        }
+        if (lastRunFailed > 0) {
+          buttons += ` <button class="btn btn-primary" onclick="rerunRun('${lastRunId}', true)" aria-label="Re-run failed and skipped repositories">🔁 Re-run ${lastRunFailed} failed</button>`;
         }
+        log.innerHTML += `<div style="margin-top:10px;">${buttons}</div>`;
+      }
 
-        // Build Framework column
-        let frameworkDisplay = '-';
-        if (repo.framework) {
-            frameworkDisplay = `${getFrameworkBadge(repo.framework)} ${repo.framework}`;
-            if (repo.framework === "Spring Boot" && repo.springBootVer) {
-                frameworkDisplay += ` ${repo.springBootVer}`;
-            }
-        } else if (repo.springBootVer) {
-            frameworkDisplay = `${getFrameworkBadge('Spring Boot')} Spring Boot ${repo.springBootVer}`;
+      async function resumeRun(runId) {
+        if (isProcessRunning) {
+          showToast('Busy', 'Another process is still running.', 'error');
+          return;
         }
+        showTab("report");
+        const log = document.getElementById("report-log");
+        const deprecationLog = document.getElementById("deprecation-log");
+        log.innerHTML += '<div class="log-repo">Resume</div>';
+        isProcessRunning = true;
 
-        // Build Runtime column (Java, Node.js, Go, or Python version)
-        let runtimeDisplay = '-';
-        if (repo.javaVersion) {
-            runtimeDisplay = `☕ Java ${repo.javaVersion}`;
-        } else if (repo.nodeVersion) {
-            runtimeDisplay = `📗 Node ${repo.nodeVersion}`;
-        } else if (repo.goVersion) {
-            runtimeDisplay = `🐹 Go ${repo.goVersion}`;
-        } else if (repo.pythonVersion) {
-            runtimeDisplay = `🐍 Python ${repo.pythonVersion}`;
+        try {
+          const response = await fetch(`/api/runs/${encodeURIComponent(runId)}/resume`, { method: "POST" });
+          if (!response.ok) throw new Error(await response.text());
+          await readRunStream(response, log, deprecationLog);
+          appendRunActions(log);
+          showToast('Complete', 'The resumed run has finished.', 'success', 4000);
+        } catch (e) {
+          log.innerHTML += `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
+          showToast('Error', `Resume failed: ${e.message}`, 'error');
+        } finally {
+          isProcessRunning = false;
         }
+      }
 
-        // Outdated deps display
-        let outdatedDisplay = repo.outdatedDeps || 0;
-        let outdatedClass = outdatedDisplay === 0 ? 'status-good' : (outdatedDisplay > 10 ? 'status-bad' : 'status-warn');
-        let outdatedBadge = outdatedDisplay === 0 ? '✅' : (outdatedDisplay > 10 ? '⚠️' : '📦');
+      // PDF export depends on a converter on the server, so surface its error instead of a broken download
+      async function downloadRunReportPdf(runId) {
+        try {
+          const res = await fetch(`/api/run-report/${encodeURIComponent(runId)}?format=pdf`);
+          if (!res.ok) throw new Error(await res.text());
+          const url = URL.createObjectURL(await res.blob());
+          const a = document.createElement("a");
+          a.href = url;
+          a.download = `housekeeping-report-${runId}.pdf`;
+          a.click();
+          URL.revokeObjectURL(url);
+        } catch (e) {
+          showToast('Error', `PDF export failed: ${e.message}`, 'error', 8000);
+        }
+      }
 
-        const tr = document.createElement("tr");
-        tr.innerHTML = `
-            <td>${repo.name}</td>
-            <td>
-                <div style="display:flex; align-items:center; gap:10px;">
-                    <div style="flex:1; height:6px; background:#45475a; border-radius:3px; width:50px;">
-                        <div style="width:${repo.healthScore}%; height:100%; background:${repo.healthScore < 50 ? '#f38ba8' : repo.healthScore < 80 ? '#fab387' : '#a6e3a1'}; border-radius:3px;"></div>
-                    </div>
-                    <span>${repo.healthScore}</span>
-                </div>
-            </td>
-            <td>${frameworkDisplay}</td>
-            <td>${runtimeDisplay}</td>
-            <td>${repo.lastCommit || '-'}</td>
-            <td>${repo.todoCount}</td>
-            <td><span title="${outdatedDisplay} outdated packages">${outdatedBadge} ${outdatedDisplay}</span></td>
-            <td><span class="status-badge ${statusClass}">${statusText}</span></td>
-        `;
-        tbody.appendChild(tr);
+      async function loadSmtpSettings() {
+        try {
+          const res = await fetch("/api/smtp-settings");
+          if (!res.ok) throw new Error(await res.text());
+          const smtp = await res.json();
+          document.getElementById("smtp-host").value = smtp.host || "";
+          document.getElementById("smtp-port").value = smtp.port || "";
+          document.getElementById("smtp-security").value = smtp.security || "starttls";
+          document.getElementById("smtp-username").value = smtp.username || "";
+          document.getElementById("smtp-password").placeholder = smtp.hasPassword ? "Password (stored)" : "Password";
+          document.getElementById("smtp-from").value = smtp.from || "";
+          document.getElementById("smtp-recipients").value = (smtp.recipients || []).join(", ");
+        } catch (e) {
+          console.error("Failed to load SMTP settings", e);
+        }
       }
 
-      function updateCharts() {
-        // Top Dependencies
-        const sortedDeps = Object.entries(currentStats.topDependencies)
-            .sort((a, b) => b[1] - a[1])
-            .slice(0, 5);
+      async function saveSmtpSettings() {
+        try {
+          const res = await fetch("/api/smtp-settings", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({
+              host: document.getElementById("smtp-host").value.trim(),
+              port: parseInt(document.getElementById("smtp-port").value, 10) || 0,
+              security: document.getElementById("smtp-security").value,
+              username: document.getElementById("smtp-username").value.trim(),
+              password: document.getElementById("smtp-password").value,
+              from: document.getElementById("smtp-from").value.trim(),
+              recipients: getRepoSelection("smtp-recipients"),
+            }),
+          });
+          if (!res.ok) throw new Error(await res.text());
+          document.getElementById("smtp-password").value = "";
+          await loadSmtpSettings();
+          showToast('Saved', 'SMTP settings saved.', 'success');
+        } catch (e) {
+          showToast('Error', `Could not save SMTP settings: ${e.message}`, 'error');
+        }
+      }
 
-        const depsContainer = document.getElementById("chart-deps");
-        depsContainer.innerHTML = "";
+      async function sendTestEmail() {
+        try {
+          const res = await fetch("/api/smtp-test", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({ to: getRepoSelection("smtp-recipients") }),
+          });
+          if (!res.ok) throw new Error(await res.text());
+          const result = await res.json();
+          showToast('Sent', `Test email sent to ${result.recipients.join(", ")}.`, 'success');
+        } catch (e) {
+          showToast('Error', `Test email failed: ${e.message}`, 'error', 8000);
+        }
+      }
 
-        if (sortedDeps.length === 0) {
-            depsContainer.innerHTML = '<div class="hint" style="color: #a6adc8; font-size: 0.9em;">Dependencies are collected during scan. Maven, Node.js, Go and Python projects are supported.</div>';
-        } else {
-            // Find max for scaling
-            const maxDep = sortedDeps[0][1];
+      async function loadNetworkSettings() {
+        try {
+          const res = await fetch("/api/network-settings");
+          if (!res.ok) throw new Error(await res.text());
+          const network = await res.json();
+          document.getElementById("network-http-proxy").value = network.httpProxy || "";
+          document.getElementById("network-https-proxy").value = network.httpsProxy || "";
+          document.getElementById("network-no-proxy").value = network.noProxy || "";
+          document.getElementById("network-ca-bundle").value = network.caBundle || "";
+          document.getElementById("network-timeout").value = network.timeoutSeconds || 15;
+          document.getElementById("network-offline").checked = !!network.offline;
+          document.getElementById("network-maven-repository").value = network.mavenRepository || "";
+          document.getElementById("network-maven-username").value = network.mavenUsername || "";
+          document.getElementById("network-maven-password").placeholder = network.hasMavenPassword ? "Password (stored)" : "Password or token";
+        } catch (e) {
+          console.error("Failed to load network settings", e);
+        }
+      }
 
-            sortedDeps.forEach(([name, count]) => {
-                const pct = (count / maxDep) * 100;
-                const tooltip = `${name} is used in ${count} project${count > 1 ? 's' : ''}`;
-                const row = document.createElement("div");
-                row.className = "bar-row";
-                row.style.cursor = "help";
-                row.title = tooltip;
-                row.innerHTML = `
-                    <div class="bar-label" title="${tooltip}">${name}</div>
-                    <div class="bar-track" title="${tooltip}">
-                        <div class="bar-fill" style="width: ${pct}%; background-color: #89b4fa;"></div>
-                    </div>
-                    <div class="bar-value">${count}</div>
-                `;
-                depsContainer.appendChild(row);
-            });
+      async function saveNetworkSettings() {
+        try {
+          const res = await fetch("/api/network-settings", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({
+              httpProxy: document.getElementById("network-http-proxy").value.trim(),
+              httpsProxy: document.getElementById("network-https-proxy").value.trim(),
+              noProxy: document.getElementById("network-no-proxy").value.trim(),
+              caBundle: document.getElementById("network-ca-bundle").value.trim(),
+              timeoutSeconds: parseInt(document.getElementById("network-timeout").value, 10) || 0,
+              offline: document.getElementById("network-offline").checked,
+              mavenRepository: document.getElementById("network-maven-repository").value.trim(),
+              mavenUsername: document.getElementById("network-maven-username").value.trim(),
+              mavenPassword: document.getElementById("network-maven-password").value,
+            }),
+          });
+          if (!res.ok) throw new Error(await res.text());
+          document.getElementById("network-maven-password").value = "";
+          await loadNetworkSettings();
+          showToast('Saved', 'Network settings saved and applied.', 'success');
+        } catch (e) {
+          showToast('Error', `Could not save network settings: ${e.message}`, 'error');
         }
+      }
 
-        // Frameworks Chart
-        const sortedFrameworks = Object.entries(currentStats.frameworks)
-            .sort((a, b) => b[1] - a[1]); // Sort by count desc
+      async function loadOwaspSettings() {
+        try {
+          const res = await fetch("/api/owasp-settings");
+          if (!res.ok) throw new Error(await res.text());
+          const owasp = await res.json();
+          document.getElementById("owasp-data-directory").value = owasp.dataDirectory || "";
+          document.getElementById("owasp-nvd-api-key").placeholder = owasp.hasNvdApiKey ? "NVD API key (stored)" : "NVD API key";
+        } catch (e) {
+          console.error("Failed to load OWASP settings", e);
+        }
+        await loadOwaspDatabase();
+      }
 
-        const frameworksContainer = document.getElementById("chart-frameworks");
-        frameworksContainer.innerHTML = "";
+      async function saveOwaspSettings() {
+        try {
+          const res = await fetch("/api/owasp-settings", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({
+              nvdApiKey: document.getElementById("owasp-nvd-api-key").value,
+              dataDirectory: document.getElementById("owasp-data-directory").value.trim(),
+            }),
+          });
+          if (!res.ok) throw new Error(await res.text());
+          document.getElementById("owasp-nvd-api-key").value = "";
+          await loadOwaspSettings();
+          showToast('Saved', 'OWASP settings saved.', 'success');
+        } catch (e) {
+          showToast('Error', `Could not save OWASP settings: ${e.message}`, 'error');
+        }
+      }
 
-        if (sortedFrameworks.length === 0) {
-            frameworksContainer.innerHTML = '<div class="hint" style="color: #a6adc8; font-size: 0.9em;">No frameworks detected. Supported: Spring Boot, React, Vue, Angular, Next.js, Go, Python, Django, Flask, etc.</div>';
-        } else {
-            const maxFramework = sortedFrameworks[0][1];
+      // formatOwaspAge describes the age of the NVD database, e.g. "3 h" or "2 days"
+      function formatOwaspAge(hours) {
+        if (hours < 1) return `${Math.round(hours * 60)} min`;
+        if (hours < 48) return `${Math.round(hours)} h`;
+        return `${Math.round(hours / 24)} days`;
+      }
 
-            sortedFrameworks.forEach(([name, count]) => {
-                const pct = (count / maxFramework) * 100;
-                const tooltip = `${count} project${count > 1 ? 's' : ''} use${count > 1 ? '' : 's'} ${name}`;
+      // loadOwaspDatabase shows the state of the NVD database in the settings and the OWASP scanner info
+      async function loadOwaspDatabase() {
+        const status = document.getElementById("owasp-db-status");
+        const age = document.getElementById("owasp-db-age");
+        try {
+          const res = await fetch("/api/owasp-db");
+          if (!res.ok) throw new Error(await res.text());
+          const db = await res.json();
+          let text = db.exists
+            ? `Updated ${formatOwaspAge(db.ageHours || 0)} ago (${new Date(db.updatedAt).toLocaleString()}), ${(db.sizeBytes / 1048576).toFixed(0)} MB in ${db.dataDirectory}`
+            : `Not downloaded yet, will be stored in ${db.dataDirectory}`;
+          if (db.updating) text += " — update running";
+          if (!db.hasApiKey) text += " — no NVD API key";
+          status.textContent = text;
+          status.style.color = !db.exists || db.ageHours > 24 * 7 ? "#f9e2af" : "#9ca0b0";
+          age.textContent = db.exists ? `updated ${formatOwaspAge(db.ageHours || 0)} ago, refreshed before each scan` : "not downloaded yet, the first scan downloads it (10+ minutes)";
+        } catch (e) {
+          status.textContent = `Database status not available: ${e.message}`;
+          age.textContent = "status not available";
+        }
+      }
 
-                const row = document.createElement("div");
-                row.className = "bar-row";
-                row.style.cursor = "help";
-                row.title = tooltip;
-                row.innerHTML = `
-                    <div class="bar-label" title="${tooltip}">${name}</div>
-                    <div class="bar-track" title="${tooltip}">
-                        <div class="bar-fill" style="width: ${pct}%; background-color: #a6e3a1;"></div>
-                    </div>
-                    <div class="bar-value">${count}</div>
-                `;
-                frameworksContainer.appendChild(row);
-            });
+      async function updateOwaspDatabase() {
+        const btn = document.getElementById("owasp-db-update-btn");
+        const log = document.getElementById("owasp-db-log");
+        btn.disabled = true;
+        log.style.display = "block";
+        log.textContent = "";
+        try {
+          const res = await fetch("/api/owasp-db-update", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({ maven: getMavenSettings() }),
+          });
+          if (!res.ok) throw new Error(await res.text());
+          const reader = res.body.getReader();
+          const decoder = new TextDecoder();
+          let buffer = '';
+          let outcome = '';
+          while (true) {
+            const { done, value } = await reader.read();
+            if (done) break;
+            buffer += decoder.decode(value, { stream: true });
+            const lines = buffer.split('\n');
+            buffer = lines.pop() || '';
+            for (const line of lines) {
+              if (line.startsWith("OWASP_DB_DONE:")) {
+                outcome = line.substring(14);
+                continue;
+              }
+              log.textContent += line + "\n";
+              log.scrollTop = log.scrollHeight;
+            }
+          }
+          if (outcome === 'ok') {
+            showToast('NVD database', 'The NVD database is up to date.', 'success');
+          } else {
+            showToast('NVD database', `Update failed: ${outcome.replace(/^failed:/, '') || 'connection lost'}`, 'error', 8000);
+          }
+        } catch (e) {
+          showToast('Error', `NVD database update failed: ${e.message}`, 'error', 8000);
+        } finally {
+          btn.disabled = false;
+          await loadOwaspDatabase();
         }
       }
 
-      // Track if Spring versions have been loaded to avoid redundant fetches
-      let springVersionsLoaded = false;
+      let workspaces = [];
 
-      async function loadSpringVersions() {
-        const container = document.getElementById("spring-versions-list");
+      async function loadWorkspaces() {
+        const select = document.getElementById("workspaceSelect");
+        if (!select) return;
+        try {
+          const res = await fetch("/api/workspaces");
+          if (!res.ok) throw new Error(await res.text());
+          workspaces = await res.json();
+        } catch (e) {
+          workspaces = [];
+          console.error("Failed to load workspaces", e);
+        }
+        const current = select.value;
+        select.innerHTML = '<option value="">-- New workspace --</option>' +
+          workspaces.map(ws => `<option value="${escapeHtml(ws.id)}" title="${escapeHtml(ws.rootPath)}">${escapeHtml(ws.name)}</option>`).join('');
+        select.value = workspaces.some(ws => ws.id === current) ? current : "";
+        showSelectedWorkspace();
+      }
 
-        // Skip if already loaded (use cache)
-        if (springVersionsLoaded && window.springVersionsCache) {
+      function showSelectedWorkspace() {
+        const ws = workspaces.find(w => w.id === document.getElementById("workspaceSelect").value);
+        document.getElementById("workspace-name").value = ws?.name || "";
+        document.getElementById("workspace-gitlab-url").value = ws?.credentials?.gitlabUrl || "";
+        document.getElementById("workspace-github-token").value = "";
+        document.getElementById("workspace-gitlab-token").value = "";
+      }
+
+      function useSelectedWorkspace() {
+        const ws = workspaces.find(w => w.id === document.getElementById("workspaceSelect").value);
+        if (!ws) {
+          showToast('No workspace', 'Please select a workspace first.', 'warning');
           return;
         }
+        document.getElementById("rootPath").value = ws.rootPath;
+        loadFolders();
+        loadDashboardStats(ws.rootPath);
+        showToast('Workspace', `Using "${ws.name}" (${ws.rootPath}).`, 'success');
+      }
 
-        // Show loading indicator
-        container.innerHTML = '<div class="hint">Loading Spring Boot versions from Maven Central...</div>';
+      async function saveWorkspace() {
+        const rootPath = document.getElementById("rootPath").value;
+        const name = document.getElementById("workspace-name").value.trim();
+        if (!rootPath || !name) {
+          showToast('Error', 'Please select a root path in the Dashboard and enter a workspace name.', 'error');
+          return;
+        }
+        const current = workspaces.find(w => w.id === document.getElementById("workspaceSelect").value);
+        try {
+          const res = await fetch("/api/workspaces", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({
+              name,
+              rootPath,
+              excluded: getExcludedProjects(),
+              credentials: {
+                githubToken: document.getElementById("workspace-github-token").value.trim(),
+                gitlabUrl: document.getElementById("workspace-gitlab-url").value.trim(),
+                gitlabToken: document.getElementById("workspace-gitlab-token").value.trim(),
+              },
+              defaults: current?.defaults,
+            }),
+          });
+          if (!res.ok) throw new Error(await res.text());
+          const ws = await res.json();
+          document.getElementById("workspaceSelect").value = ws.id;
+          await loadWorkspaces();
+          showToast('Saved', `Workspace "${ws.name}" saved.`, 'success');
+        } catch (e) {
+          showToast('Error', `Could not save workspace: ${e.message}`, 'error');
+        }
+      }
 
+      async function deleteSelectedWorkspace() {
+        const ws = workspaces.find(w => w.id === document.getElementById("workspaceSelect").value);
+        if (!ws) {
+          showToast('No workspace', 'Please select a workspace first.', 'warning');
+          return;
+        }
+        if (!confirm(`Delete workspace "${ws.name}"? The repositories are not touched.`)) return;
         try {
-          const res = await fetch("/api/spring-versions");
-          if (!res.ok) throw new Error("Failed to fetch versions");
-          const versions = await res.json();
+          const res = await fetch(`/api/workspaces/${encodeURIComponent(ws.id)}`, { method: "DELETE" });
+          if (!res.ok) throw new Error(await res.text());
+          document.getElementById("workspaceSelect").value = "";
+          await loadWorkspaces();
+          showToast('Deleted', `Workspace "${ws.name}" deleted.`, 'success');
+        } catch (e) {
+          showToast('Error', `Could not delete workspace: ${e.message}`, 'error');
+        }
+      }
 
-          // Cache for Migration Assistant
-          window.springVersionsCache = versions;
-          springVersionsLoaded = true;
+      async function loadRetrySettings() {
+        try {
+          const res = await fetch("/api/retry-settings");
+          if (!res.ok) throw new Error(await res.text());
+          const retry = await res.json();
+          for (const cls of ["git", "maven", "scanners"]) {
+            document.getElementById(`retry-${cls}-attempts`).value = retry[cls].attempts;
+            document.getElementById(`retry-${cls}-delay`).value = retry[cls].delayMs;
+          }
+        } catch (e) {
+          console.error("Failed to load retry settings", e);
+        }
+      }
 
-          container.innerHTML = "";
+      async function saveRetrySettings() {
+        const policy = (cls) => ({
+          attempts: parseInt(document.getElementById(`retry-${cls}-attempts`).value, 10) || 1,
+          delayMs: parseInt(document.getElementById(`retry-${cls}-delay`).value, 10) || 0,
+        });
+        try {
+          const res = await fetch("/api/retry-settings", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({ git: policy("git"), maven: policy("maven"), scanners: policy("scanners") }),
+          });
+          if (!res.ok) throw new Error(await res.text());
+          await loadRetrySettings();
+          showToast('Saved', 'Retry settings saved and applied.', 'success');
+        } catch (e) {
+          showToast('Error', `Could not save retry settings: ${e.message}`, 'error');
+        }
+      }
 
-          // Show only first 5 branches initially
-          const initialShowCount = 5;
-          let hiddenContainer = null;
-          let showMoreBtn = null;
+      async function loadServerLog() {
+        const list = document.getElementById("server-log-list");
+        const level = document.getElementById("server-log-level").value;
+        try {
+          const res = await fetch(`/api/logs/tail?lines=200&level=${encodeURIComponent(level)}`);
+          if (!res.ok) throw new Error(await res.text());
+          const entries = await res.json();
+          if (entries.length === 0) {
+            list.innerHTML = '<div class="hint">No log entries.</div>';
+            return;
+          }
+          list.innerHTML = entries.map(e => {
+            const cls = e.level === "ERROR" ? "log-error" : e.level === "WARN" ? "log-warning" : "log-info";
+            const attrs = Object.entries(e.attrs || {}).map(([k, v]) => `${k}=${v}`).join(" ");
+            return `<div class="${cls}">${new Date(e.time).toLocaleTimeString()} ${e.level} ${escapeHtml(e.message)} ${escapeHtml(attrs)}</div>`;
+          }).join("");
+          list.scrollTop = list.scrollHeight;
+        } catch (e) {
+          list.innerHTML = `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
+        }
+        loadCommandLimits();
+      }
 
-          versions.forEach((group, index) => {
-            const groupDiv = document.createElement("div");
-            groupDiv.style.marginBottom = "20px";
-            groupDiv.style.padding = "15px";
-            groupDiv.style.backgroundColor = "var(--input-bg)";
-            groupDiv.style.borderRadius = "6px";
+      // Shows how many external processes (Maven, git, npm, scanners) run and wait right now
+      async function loadCommandLimits() {
+        const target = document.getElementById("command-limits");
+        try {
+          const res = await fetch("/api/command-limits");
+          if (!res.ok) throw new Error(await res.text());
+          const limits = await res.json();
+          target.textContent = "Processes: " + limits
+            .map(l => `${l.class} ${l.running}/${l.limit}` + (l.waiting > 0 ? ` (${l.waiting} waiting)` : ""))
+            .join(" · ");
+        } catch (e) {
+          target.textContent = "";
+        }
+      }
 
-            const title = document.createElement("h4");
-            title.style.margin = "0 0 10px 0";
-            title.style.color = "var(--accent-color)";
-            title.textContent = `Spring Boot ${group.Branch}`;
+      // Executes the repos of a past run again with its original parameters (onlyFailed: failed and skipped repos)
+      async function rerunRun(runId, onlyFailed) {
+        if (isProcessRunning) {
+          showToast('Busy', 'Another process is still running.', 'error');
+          return;
+        }
+        showTab("report");
+        const log = document.getElementById("report-log");
+        const deprecationLog = document.getElementById("deprecation-log");
+        log.innerHTML += '<div class="log-repo">Re-run</div>';
+        isProcessRunning = true;
 
-            if (group.MigrationGuide) {
-              const link = document.createElement("a");
-              link.href = group.MigrationGuide;
-              link.target = "_blank";
-              link.style.fontSize = "0.8em";
-              link.style.marginLeft = "10px";
-              link.style.color = "#89b4fa";
-              link.textContent = group.Branch.endsWith(".0")
-                ? "📖 Migration Guide"
-                : "📝 Release Notes";
-              title.appendChild(link);
-            }
+        try {
+          const response = await fetch(`/api/rerun/${encodeURIComponent(runId)}${onlyFailed ? "?only=failed" : ""}`, { method: "POST" });
+          if (!response.ok) throw new Error(await response.text());
+          await readRunStream(response, log, deprecationLog);
+          appendRunActions(log);
+          showToast('Complete', 'The re-run has finished.', 'success', 4000);
+        } catch (e) {
+          log.innerHTML += `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
+          showToast('Error', `Re-run failed: ${e.message}`, 'error');
+        } finally {
+          isProcessRunning = false;
+        }
+      }
 
-            groupDiv.appendChild(title);
+      async function rollbackRun(runId) {
+        if (!confirm(`Roll back run ${runId}? Branches created by the run are deleted, existing branches are reset to their previous state.`)) {
+          return;
+        }
 
-            const versionsDiv = document.createElement("div");
-            versionsDiv.style.fontSize = "0.9em";
-            versionsDiv.style.color = "#a6adc8";
-            // Show top 5 versions, then "..."
-            const showCount = 5;
-            const visible = group.Versions.slice(0, showCount);
-            versionsDiv.textContent = visible.join(", ");
+        const log = document.getElementById("report-log");
+        log.innerHTML += '<div class="log-repo">Rollback</div>';
 
-            if (group.Versions.length > showCount) {
-              const more = document.createElement("span");
-              more.textContent = ` (+${
-                group.Versions.length - showCount
-              } weitere)`;
-              more.style.cursor = "pointer";
-              more.style.textDecoration = "underline";
-              more.style.marginLeft = "5px";
-              more.onclick = () => {
-                versionsDiv.textContent = group.Versions.join(", ");
-              };
-              versionsDiv.appendChild(more);
-            }
+        try {
+          const response = await fetch(`/api/rollback/${encodeURIComponent(runId)}`, { method: "POST" });
+          if (!response.ok) throw new Error(await response.text());
 
-            groupDiv.appendChild(versionsDiv);
+          const reader = response.body.getReader();
+          const decoder = new TextDecoder("utf-8");
+          while (true) {
+            const { done, value } = await reader.read();
+            if (done) break;
 
-            // Add to visible container or hidden container based on index
-            if (index < initialShowCount) {
-              container.appendChild(groupDiv);
+            for (const line of decoder.decode(value, { stream: true }).split("\n")) {
+              if (!line.trim() || line.startsWith("ROLLBACK_COMPLETE")) continue;
+              const div = document.createElement("div");
+              if (line.startsWith("REPO:")) {
+                div.className = "log-repo";
+                div.textContent = line.substring(5);
+              } else if (line.includes("[ERROR]")) {
+                div.className = "log-error";
+                div.textContent = line;
+              } else if (line.includes("[WARNING]")) {
+                div.className = "log-warning";
+                div.textContent = line;
+              } else if (line.includes("✓")) {
+                div.className = "log-success";
+                div.textContent = line;
+              } else {
+                div.className = "log-info";
+                div.textContent = line;
+              }
+              log.appendChild(div);
+              log.scrollTop = log.scrollHeight;
+            }
+          }
+          showToast('Rollback', 'The housekeeping run has been rolled back.', 'success', 4000);
+        } catch (e) {
+          log.innerHTML += `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
+          showToast('Error', `Rollback failed: ${e.message}`, 'error');
+        }
+      }
+
+      function escapeHtml(text) {
+        const div = document.createElement("div");
+        div.textContent = text || "";
+        return div.innerHTML;
+      }
+
+      async function loadRunHistory() {
+        const list = document.getElementById("run-history-list");
+        const q = document.getElementById("run-history-filter").value.trim();
+        list.innerHTML = '<div class="hint">Loading...</div>';
+        try {
+          const res = await fetch(`/api/runs?q=${encodeURIComponent(q)}`);
+          if (!res.ok) throw new Error(await res.text());
+          const runs = (await res.json()) || [];
+
+          if (runs.length === 0) {
+            list.innerHTML = '<div class="hint">No runs found.</div>';
+            return;
+          }
+
+          list.innerHTML = runs.map(run => `
+            <div style="display: flex; align-items: center; gap: 10px; padding: 6px 0; border-bottom: 1px solid var(--border-color);">
+              <div style="flex: 1;">
+                <strong>${escapeHtml(run.label) || '<span style="color: #9ca0b0;">(no label)</span>'}</strong>
+                <span style="color: #9ca0b0; font-size: 0.85em;">${new Date(run.startedAt).toLocaleString()} · ${(run.repos || []).length} repos · ${run.id}</span>
+                ${run.description ? `<div style="color: #9ca0b0; font-size: 0.85em;">${escapeHtml(run.description)}</div>` : ''}
+              </div>
+              ${(run.remaining || []).length && !run.resumedBy ? `<button class="btn btn-primary" style="padding: 4px 8px; font-size: 0.85em;" onclick="resumeRun('${run.id}')" aria-label="Resume remaining repositories">▶️ ${run.remaining.length}</button>` : ''}
+              ${(run.results || []).some(r => r.status !== 'success') ? `<button class="btn btn-primary" style="padding: 4px 8px; font-size: 0.85em;" onclick="rerunRun('${run.id}', true)" aria-label="Re-run failed and skipped repositories">🔁 ${run.results.filter(r => r.status !== 'success').length}</button>` : ''}
+              <a class="btn btn-secondary" style="padding: 4px 8px; font-size: 0.85em;" href="/api/run-report/${run.id}?format=html" aria-label="Download the HTML report">📄</a>
+              <button class="btn btn-secondary" style="padding: 4px 8px; font-size: 0.85em;" onclick="annotateRun('${run.id}')" aria-label="Edit run label">✏️</button>
+              <button class="btn btn-secondary" style="padding: 4px 8px; font-size: 0.85em;" onclick="rollbackRun('${run.id}')" aria-label="Undo this housekeeping run">↩️</button>
+            </div>
+          `).join('');
+        } catch (e) {
+          list.innerHTML = `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
+        }
+      }
+
+      async function annotateRun(runId) {
+        const current = await fetch(`/api/runs/${encodeURIComponent(runId)}`).then(r => r.json()).catch(() => ({}));
+        const label = prompt("Run label:", current.label || "");
+        if (label === null) return;
+        const description = prompt("Description:", current.description || "");
+        if (description === null) return;
+
+        try {
+          const res = await fetch(`/api/runs/${encodeURIComponent(runId)}`, {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({ label, description }),
+          });
+          if (!res.ok) throw new Error(await res.text());
+          loadRunHistory();
+        } catch (e) {
+          showToast('Error', `Could not save annotation: ${e.message}`, 'error');
+        }
+      }
+
+      // Load settings on startup
+      // Suggest the supported Node.js LTS lines for the Node.js version field (endoflife.date)
+      async function loadNodeLTSVersions() {
+        try {
+          const res = await fetch("/api/node-lts");
+          if (!res.ok) return;
+          const versions = await res.json();
+          document.getElementById("nodeLtsVersions").innerHTML = versions
+            .map((v) => `<option value="${escapeHtml(v.cycle)}">LTS, latest ${escapeHtml(v.latest || "-")}${v.eol ? `, supported until ${escapeHtml(v.eol)}` : ""}</option>`)
+            .join("");
+        } catch (e) {
+          // Offline: the field still accepts any version
+        }
+      }
+      window.addEventListener("DOMContentLoaded", loadNodeLTSVersions);
+
+      window.addEventListener("DOMContentLoaded", () => {
+        const saved = localStorage.getItem("gitHousekeeper_settings");
+        if (saved) {
+          try {
+            const settings = JSON.parse(saved);
+            if (settings.rootPath) {
+              document.getElementById("rootPath").value = settings.rootPath;
+              loadFolders(); // Trigger load
+            }
+            // if (settings.excluded) document.getElementById('excluded').value = settings.excluded;
+            if (settings.parentVersion)
+              document.getElementById("parentVersion").value =
+                settings.parentVersion;
+            if (settings.nodeVersion)
+              document.getElementById("nodeVersion").value =
+                settings.nodeVersion;
+            if (settings.versionBumpStrategy)
+              document.getElementById("versionBumpStrategy").value =
+                settings.versionBumpStrategy;
+            if (settings.runCleanInstall !== undefined)
+              document.getElementById("runCleanInstall").checked =
+                settings.runCleanInstall;
+            if (settings.verificationLevel) {
+              document.getElementById("verificationLevel").value = settings.verificationLevel;
+              document.getElementById("verificationGoal").value = settings.verificationGoal || "";
+              document.getElementById("verificationLevel").dispatchEvent(new Event("change"));
+            }
+
+            if (settings.branchStrategy) {
+              const rb = document.querySelector(
+                `input[name="branchStrategy"][value="${settings.branchStrategy}"]`
+              );
+              if (rb) rb.checked = true;
+            }
+            if (settings.customBranchName)
+              document.getElementById("customBranchName").value =
+                settings.customBranchName;
+            if (settings.commitStrategy)
+              document.getElementById("commitStrategy").value = settings.commitStrategy;
+            if (settings.dirtyTree)
+              document.getElementById("dirtyTree").value = settings.dirtyTree;
+            document.getElementById("checkGitAccess").checked = !!settings.checkGitAccess;
+            if (settings.signing) applySigningSettings(settings.signing);
+            if (settings.createTag) applyCreateTagSettings(settings.createTag);
+            if (settings.ignorePaths)
+              document.getElementById("ignorePaths").value =
+                settings.ignorePaths;
+            if (settings.tags) {
+              document.getElementById("tagPattern").value = settings.tags.pattern || "";
+              document.getElementById("tagPrefix").value = settings.tags.prefix || "";
+            }
+            if (settings.maven) {
+              document.getElementById("mavenHome").value = settings.maven.mavenHome || "";
+              document.getElementById("mavenSettingsFile").value = settings.maven.settingsFile || "";
+              document.getElementById("mavenProfiles").value = settings.maven.profiles || "";
+              document.getElementById("mavenExtraArgs").value = (settings.maven.extraArgs || []).join(" ");
+              document.getElementById("mavenUseWrapper").checked = settings.maven.useWrapper;
+            }
+            if (settings.go) {
+              document.getElementById("goUpdateMode").value = settings.go.updateMode || "";
+              document.getElementById("goModules").value = (settings.go.modules || []).join(" ");
+              document.getElementById("goVersion").value = settings.go.goVersion || "";
+              document.getElementById("goToolchain").value = settings.go.toolchain || "";
+            }
+            ["python", "php"].forEach((eco) => {
+              if (!settings[eco]) return;
+              document.getElementById(`${eco}Update`).checked = !!settings[eco].update;
+              document.getElementById(`${eco}Packages`).value = (settings[eco].packages || []).join(" ");
+              document.getElementById(`${eco}Verify`).value = settings[eco].verifyCommand || "";
+            });
+            toggleBranchInput();
+          } catch (e) {
+            console.error("Failed to load settings", e);
+          }
+        }
+
+        loadProfiles();
+        loadGroups();
+        loadSmtpSettings();
+        loadNetworkSettings();
+        loadOwaspSettings();
+        loadGitignoreStandard();
+        loadRetrySettings();
+        loadWorkspaces();
+
+        // Auto-load folders when typing/pasting path
+        const rootPathInput = document.getElementById("rootPath");
+        let debounceTimer;
+        rootPathInput.addEventListener("input", () => {
+          clearTimeout(debounceTimer);
+          debounceTimer = setTimeout(() => {
+            loadFolders();
+          }, 500);
+        });
+
+        // Dashboard Logic
+        const savedSettings = localStorage.getItem("gitHousekeeper_settings");
+        if (savedSettings) {
+          try {
+            const settings = JSON.parse(savedSettings);
+            if (settings.rootPath) {
+              document.getElementById("rootPath").value = settings.rootPath;
+              loadDashboardStats(settings.rootPath);
+              // Also trigger folder load for settings tab
+              loadFolders();
             } else {
-              // Create hidden container if not exists
-              if (!hiddenContainer) {
-                hiddenContainer = document.createElement("div");
-                hiddenContainer.id = "hidden-versions";
-                hiddenContainer.style.display = "none";
-                container.appendChild(hiddenContainer);
+               // No path, show empty state
+               document.getElementById("dashboard-empty").classList.remove("hidden");
+               document.getElementById("dashboard-path-header").classList.add("hidden");
+            }
+          } catch (e) {
+            console.error("Error loading settings for dashboard", e);
+          }
+        } else {
+           document.getElementById("dashboard-empty").classList.remove("hidden");
+           document.getElementById("dashboard-path-header").classList.add("hidden");
+        }
+      });
+
+      function getRepoSelection(id) {
+        return document.getElementById(id).value
+          .split(",")
+          .map((r) => r.trim())
+          .filter((r) => r);
+      }
+
+      function getIgnorePaths() {
+        const input = document.getElementById("ignorePaths");
+        if (!input) return [];
+        return input.value
+          .split(",")
+          .map((p) => p.trim())
+          .filter((p) => p);
+      }
+
+      function getMavenSettings() {
+        const value = (id) => document.getElementById(id)?.value.trim() || "";
+        return {
+          useWrapper: document.getElementById("mavenUseWrapper")?.checked ?? true,
+          mavenHome: value("mavenHome"),
+          settingsFile: value("mavenSettingsFile"),
+          profiles: value("mavenProfiles"),
+          extraArgs: value("mavenExtraArgs").split(/\s+/).filter((a) => a),
+        };
+      }
+
+      function getSigningSettings() {
+        return {
+          enabled: document.getElementById("signingEnabled").checked,
+          format: document.getElementById("signingFormat").value,
+          key: document.getElementById("signingKey").value.trim(),
+        };
+      }
+
+      function applySigningSettings(signing) {
+        document.getElementById("signingEnabled").checked = !!signing.enabled;
+        document.getElementById("signingFormat").value = signing.format || "gpg";
+        document.getElementById("signingKey").value = signing.key || "";
+      }
+
+      function getCreateTagSettings() {
+        return {
+          enabled: document.getElementById("createTagEnabled").checked,
+          template: document.getElementById("createTagTemplate").value.trim(),
+          message: document.getElementById("createTagMessage").value.trim(),
+          push: document.getElementById("createTagPush").checked,
+        };
+      }
+
+      function applyCreateTagSettings(createTag) {
+        document.getElementById("createTagEnabled").checked = !!createTag.enabled;
+        document.getElementById("createTagTemplate").value = createTag.template || "";
+        document.getElementById("createTagMessage").value = createTag.message || "";
+        document.getElementById("createTagPush").checked = !!createTag.push;
+      }
+
+      async function checkSigning() {
+        try {
+          const res = await fetch("/api/check-signing", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify(getSigningSettings()),
+          });
+          if (!res.ok) throw new Error(await res.text());
+          const result = await res.json();
+          if (result.ok) {
+            showToast('Signing works', 'A signed test commit was created successfully.', 'success');
+          } else {
+            showToast('Signing failed', result.error, 'error', 8000);
+          }
+        } catch (e) {
+          showToast('Error', `Could not test signing: ${e.message}`, 'error');
+        }
+      }
+
+      function getGoSettings() {
+        const value = (id) => document.getElementById(id)?.value.trim() || "";
+        return {
+          updateMode: value("goUpdateMode"),
+          modules: value("goModules").split(/[\s,]+/).filter((m) => m),
+          goVersion: value("goVersion"),
+          toolchain: value("goToolchain"),
+        };
+      }
+
+      // Unset when disabled, so that the format defaults of a workspace apply
+      function getFormatSettings() {
+        if (!document.getElementById("formatEnabled").checked) return undefined;
+        const boxes = Array.from(document.querySelectorAll(".format-formatter-cb"));
+        const formatters = boxes.filter((cb) => cb.checked).map((cb) => cb.value);
+        if (formatters.length === 0) return undefined;
+        return { enabled: true, formatters: formatters.length === boxes.length ? [] : formatters };
+      }
+
+      // Python and PHP share the same form layout (ids pythonUpdate, phpPackages, ...)
+      function getEcosystemSettings(eco) {
+        return {
+          update: document.getElementById(`${eco}Update`)?.checked || false,
+          packages: (document.getElementById(`${eco}Packages`)?.value || "").split(/[\s,]+/).filter((p) => p),
+          verifyCommand: document.getElementById(`${eco}Verify`)?.value.trim() || "",
+        };
+      }
+
+      async function loadDashboardStats(rootPath) {
+        stopDashboardWatch();
+        lastLoadedPath = rootPath;
+        const content = document.getElementById("dashboard-content");
+        const empty = document.getElementById("dashboard-empty");
+        const header = document.getElementById("dashboard-path-header");
+        const tbody = document.getElementById("repo-table-body");
+
+        empty.classList.add("hidden");
+        header.classList.remove("hidden");
+        content.classList.remove("hidden");
+
+        // Reset UI
+        tbody.innerHTML = "";
+        document.getElementById("metric-health").innerText = "--";
+        document.getElementById("metric-repos").innerText = "0";
+        document.getElementById("metric-todos").innerText = "0";
+        document.getElementById("metric-outdated").innerText = "0";
+        document.getElementById("chart-deps").innerHTML = '<div class="hint">Loading...</div>';
+        document.getElementById("chart-frameworks").innerHTML = '<div class="hint">Loading...</div>';
+
+        // Reset Stats
+        currentStats = {
+            totalRepos: 0,
+            repoDetails: [],
+            depsByPath: {},      // Top dependencies of each repo, for live updates
+            frameworks: {},      // Framework distribution
+            topDependencies: {}, // Map for easy counting
+            totalTodos: 0,
+            totalHealth: 0,
+            totalOutdated: 0
+        };
+
+        try {
+          const response = await fetch("/api/dashboard-stats", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({ RootPath: rootPath, Excluded: [], Group: getSelectedGroup(), IgnorePaths: getIgnorePaths(), Maven: getMavenSettings() })
+          });
+
+          if (!response.ok) throw new Error("Failed to load stats");
+
+          const reader = response.body.getReader();
+          const decoder = new TextDecoder();
+          let buffer = "";
+
+          while (true) {
+            const { done, value } = await reader.read();
+            if (done) break;
+
+            buffer += decoder.decode(value, { stream: true });
+            const lines = buffer.split("\n");
+            buffer = lines.pop(); // Keep incomplete line
+
+            for (const line of lines) {
+                if (!line.trim()) continue;
+                try {
+                    const msg = JSON.parse(line);
+                    handleDashboardMessage(msg);
+                } catch (e) {
+                    console.error("Error parsing JSON", e);
+                }
+            }
+          }
+        } catch (e) {
+          console.error(e);
+          // content.classList.add("hidden");
+        }
+      }
+
+      function handleDashboardMessage(msg) {
+        if (msg.type === "init") {
+            currentStats.totalRepos = msg.totalRepos;
+            document.getElementById("metric-repos").innerText = msg.totalRepos;
+            // Clear charts
+            document.getElementById("chart-deps").innerHTML = "";
+            document.getElementById("chart-frameworks").innerHTML = "";
+        } else if (msg.type === "repo") {
+            const repo = msg.data;
+            const deps = msg.deps || [];
+
+            // Update Stats
+            currentStats.repoDetails.push(repo);
+            currentStats.totalTodos += repo.todoCount;
+            currentStats.totalHealth += repo.healthScore;
+            currentStats.totalOutdated += repo.outdatedDeps || 0;
+
+            currentStats.depsByPath[repo.path] = deps;
+            countFramework(repo);
+
+            deps.forEach(d => {
+                currentStats.topDependencies[d] = (currentStats.topDependencies[d] || 0) + 1;
+            });
+
+            // Update Metrics
+            const count = currentStats.repoDetails.length;
+            const avgHealth = Math.round(currentStats.totalHealth / count);
+            document.getElementById("metric-health").innerText = avgHealth + "/100";
+            document.getElementById("metric-todos").innerText = currentStats.totalTodos;
+            document.getElementById("metric-outdated").innerText = currentStats.totalOutdated;
+
+            // Add Row
+            addRepoRow(repo);
+
+            // Update Charts (Debounce could be good, but live is cool)
+            updateCharts();
+        } else if (msg.type === "done") {
+            loadDashboardTrends();
+            renderBaseImages();
+            renderCIActions();
+            renderDependencyBots();
+            renderHygiene();
+            startDashboardWatch();
+        }
+      }
+
+      // Counts a repo in the framework distribution (Spring Boot versions as separate entries)
+      function countFramework(repo) {
+        let frameworkLabel = null;
+        if (repo.framework) {
+            frameworkLabel = repo.framework;
+            if (repo.framework === "Spring Boot" && repo.springBootVer) {
+                frameworkLabel = `Spring Boot ${repo.springBootVer}`;
+            }
+        } else if (repo.projectType === "maven" && repo.springBootVer) {
+            frameworkLabel = `Spring Boot ${repo.springBootVer}`;
+        } else if (repo.projectType && repo.projectType !== "unknown") {
+            // For projects without detected framework, use project type
+            const typeLabels = {
+                'npm': 'Node.js',
+                'yarn': 'Node.js',
+                'pnpm': 'Node.js',
+                'go': 'Go',
+                'python': 'Python',
+                'maven': 'Maven'
+            };
+            frameworkLabel = typeLabels[repo.projectType] || repo.projectType;
+        }
+        if (frameworkLabel) {
+            currentStats.frameworks[frameworkLabel] = (currentStats.frameworks[frameworkLabel] || 0) + 1;
+        }
+      }
+
+      let dashboardWatch = null;
+
+      function stopDashboardWatch() {
+        if (dashboardWatch) {
+          dashboardWatch.abort();
+          dashboardWatch = null;
+        }
+      }
+
+      // Streams changes below the dashboard's root path (new and deleted repos, changed pom.xml/package.json)
+      // and updates the affected rows without a full rescan. Groups are not watched.
+      async function startDashboardWatch() {
+        stopDashboardWatch();
+        if (!lastLoadedPath || getSelectedGroup()) return;
+        const controller = new AbortController();
+        dashboardWatch = controller;
+        try {
+          const response = await fetch("/api/dashboard-watch", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({ RootPath: lastLoadedPath, Excluded: [], IgnorePaths: getIgnorePaths(), Maven: getMavenSettings() }),
+            signal: controller.signal,
+          });
+          if (!response.ok) throw new Error(await response.text());
+
+          const reader = response.body.getReader();
+          const decoder = new TextDecoder();
+          let buffer = "";
+          while (true) {
+            const { done, value } = await reader.read();
+            if (done) break;
+            buffer += decoder.decode(value, { stream: true });
+            const lines = buffer.split("\n");
+            buffer = lines.pop();
+            for (const line of lines) {
+              if (line.trim()) applyDashboardUpdate(JSON.parse(line));
+            }
+          }
+        } catch (e) {
+          if (e.name !== "AbortError") console.error("Dashboard watch stopped", e);
+        }
+        if (dashboardWatch === controller) dashboardWatch = null;
+      }
+
+      function applyDashboardUpdate(msg) {
+        if (msg.type === "repo") {
+            const index = currentStats.repoDetails.findIndex(r => r.path === msg.data.path);
+            if (index >= 0) {
+                currentStats.repoDetails[index] = msg.data;
+            } else {
+                currentStats.repoDetails.push(msg.data);
+            }
+            currentStats.depsByPath[msg.data.path] = msg.deps || [];
+            const what = msg.event === "repo-added" ? "New repository" : `${msg.file || "Build file"} changed in`;
+            showToast('Dashboard updated', `${what} ${msg.data.name}`, 'info');
+        } else if (msg.type === "removed") {
+            currentStats.repoDetails = currentStats.repoDetails.filter(r => r.path !== msg.path);
+            delete currentStats.depsByPath[msg.path];
+        } else {
+            return;
+        }
+        rebuildDashboard();
+      }
+
+      // Recomputes metrics, rows and charts from currentStats.repoDetails after a live update
+      function rebuildDashboard() {
+        currentStats.totalRepos = currentStats.repoDetails.length;
+        currentStats.frameworks = {};
+        currentStats.topDependencies = {};
+        currentStats.totalTodos = 0;
+        currentStats.totalHealth = 0;
+        currentStats.totalOutdated = 0;
+        const tbody = document.getElementById("repo-table-body");
+        tbody.innerHTML = "";
+        for (const repo of currentStats.repoDetails) {
+            currentStats.totalTodos += repo.todoCount;
+            currentStats.totalHealth += repo.healthScore;
+            currentStats.totalOutdated += repo.outdatedDeps || 0;
+            countFramework(repo);
+            (currentStats.depsByPath[repo.path] || []).forEach(d => {
+                currentStats.topDependencies[d] = (currentStats.topDependencies[d] || 0) + 1;
+            });
+            addRepoRow(repo);
+        }
+        const count = currentStats.repoDetails.length;
+        document.getElementById("metric-repos").innerText = count;
+        document.getElementById("metric-health").innerText = count ? Math.round(currentStats.totalHealth / count) + "/100" : "--";
+        document.getElementById("metric-todos").innerText = currentStats.totalTodos;
+        document.getElementById("metric-outdated").innerText = currentStats.totalOutdated;
+        updateCharts();
+        renderBaseImages();
+        renderCIActions();
+        renderDependencyBots();
+        renderHygiene();
+      }
+
+      // Loads the stored dashboard snapshots of the current folder or group and draws one line chart per metric
+      async function loadDashboardTrends() {
+        const summary = document.getElementById("trend-summary");
+        const charts = document.getElementById("trend-charts");
+        const group = getSelectedGroup();
+        if (!group && !lastLoadedPath) return;
+
+        const days = document.getElementById("trend-days").value;
+        const scope = group ? `group=${encodeURIComponent(group)}` : `rootPath=${encodeURIComponent(lastLoadedPath)}`;
+        try {
+          const res = await fetch(`/api/dashboard-trends?${scope}&days=${days}`);
+          if (!res.ok) throw new Error(await res.text());
+          const trend = await res.json();
+          if (trend.points.length < 2) {
+            summary.innerHTML = '<div class="hint">Trends appear after the second dashboard scan of this scope.</div>';
+            charts.innerHTML = "";
+            return;
+          }
+
+          const change = (value, goodWhenNegative) => {
+            const good = goodWhenNegative ? value < 0 : value > 0;
+            const cls = value === 0 ? "log-info" : good ? "log-success" : "log-error";
+            return `<span class="${cls}">${value > 0 ? "+" : ""}${value}</span>`;
+          };
+          summary.innerHTML = `Since ${new Date(trend.points[0].time).toLocaleDateString()}: ` +
+            `health ${change(trend.healthChange, false)} · TODOs ${change(trend.todosChange, true)} · ` +
+            `outdated dependencies ${change(trend.outdatedChange, true)}`;
+
+          charts.innerHTML = [
+            ["Avg Health Score", "avgHealthScore", "#a6e3a1"],
+            ["TODOs", "totalTodos", "#fab387"],
+            ["Outdated Dependencies", "outdatedDeps", "#f38ba8"],
+            ["Build Errors", "buildErrors", "#f9e2af"],
+          ].map(([title, key, color]) => renderTrendChart(title, trend.points, key, color)).join("");
+        } catch (e) {
+          summary.innerHTML = `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
+        }
+      }
+
+      // Lists the flagged base images of the last dashboard scan, grouped by image
+      function renderBaseImages() {
+        const list = document.getElementById("base-image-list");
+        const byImage = {};
+        for (const repo of currentStats.repoDetails) {
+          for (const image of (repo.baseImages || []).filter(i => i.issue)) {
+            const entry = byImage[image.image] || (byImage[image.image] = { ...image, repos: new Set() });
+            entry.repos.add(repo.name);
+          }
+        }
+        const entries = Object.values(byImage).sort((a, b) => b.repos.size - a.repos.size);
+        if (entries.length === 0) {
+          list.innerHTML = '<div class="hint">No base images pinned to latest or end-of-life versions.</div>';
+          return;
+        }
+        list.innerHTML = entries.map(e => `
+          <div style="display: flex; align-items: center; gap: 10px; padding: 6px 0; border-bottom: 1px solid var(--border-color);">
+            <div style="flex: 1;">
+              <strong>${escapeHtml(e.image)}</strong> <span class="${e.issue === 'eol' ? 'log-error' : 'log-warning'}">${escapeHtml(e.detail)}</span>
+              <div style="color: #9ca0b0; font-size: 0.85em;">${[...e.repos].map(escapeHtml).join(', ')}</div>
+            </div>
+            <button class="btn btn-secondary" data-from="${escapeHtml(e.image)}" data-to="${escapeHtml(e.suggestion || '')}"
+              onclick="selectBaseImage(this.dataset.from, this.dataset.to)" aria-label="Select ${escapeHtml(e.image)} for replacement">
+              ${e.suggestion ? `→ ${escapeHtml(e.suggestion)}` : 'Select'}
+            </button>
+          </div>`).join('');
+      }
+
+      function selectBaseImage(from, to) {
+        document.getElementById("base-image-from").value = from;
+        document.getElementById("base-image-to").value = to;
+        if (!to) document.getElementById("base-image-to").focus();
+      }
+
+      // Replaces a base image in all repos of the last dashboard scan that use it
+      async function updateBaseImage() {
+        const from = document.getElementById("base-image-from").value.trim();
+        const to = document.getElementById("base-image-to").value.trim();
+        if (!from || !to) {
+          showToast('Error', 'Please enter the current and the new base image.', 'error');
+          return;
+        }
+        const repos = currentStats.repoDetails
+          .filter(repo => (repo.baseImages || []).some(i => i.image === from))
+          .map(repo => repo.path);
+        if (repos.length === 0) {
+          showToast('Error', `No repository of the last dashboard scan uses ${from}.`, 'error');
+          return;
+        }
+
+        const log = document.getElementById("base-image-log");
+        log.classList.remove("hidden");
+        log.innerHTML = "";
+        try {
+          const response = await fetch("/api/base-images/update", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({
+              rootPath: lastLoadedPath,
+              group: getSelectedGroup(),
+              repos,
+              from,
+              to,
+              branch: document.getElementById("base-image-branch").value.trim(),
+              signing: getSigningSettings(),
+            }),
+          });
+          if (!response.ok) throw new Error(await response.text());
+
+          const reader = response.body.getReader();
+          const decoder = new TextDecoder();
+          while (true) {
+            const { done, value } = await reader.read();
+            if (done) break;
+            for (const line of decoder.decode(value, { stream: true }).split("\n")) {
+              if (!line.trim() || line.startsWith("REPO_DONE:")) continue;
+              if (line.startsWith("BASE_IMAGE_COMPLETE:")) {
+                showToast('Base Image Updated', `${line.split(":")[1]} repositories updated. Reload the dashboard to re-audit.`, 'success');
+                continue;
+              }
+              const div = document.createElement("div");
+              if (line.startsWith("REPO_START:")) {
+                div.style.fontWeight = "bold";
+                div.textContent = line.substring("REPO_START:".length);
+              } else {
+                div.style.color = line.includes("[ERROR]") ? "#f38ba8" : "#9ca0b0";
+                div.textContent = line;
+              }
+              log.appendChild(div);
+              log.scrollTop = log.scrollHeight;
+            }
+          }
+        } catch (e) {
+          showToast('Error', `Base image update failed: ${e.message}`, 'error');
+        }
+      }
+
+      // Lists the actions and includes of the last dashboard scan by reference, flagged ones first
+      function renderCIActions() {
+        const list = document.getElementById("ci-action-list");
+        const byRef = {};
+        for (const repo of currentStats.repoDetails) {
+          for (const action of repo.ciActions || []) {
+            const key = action.uses || `${action.name}${action.ref ? '@' + action.ref : ''}`;
+            const entry = byRef[key] || (byRef[key] = { ...action, key, repos: new Set() });
+            entry.repos.add(repo.name);
+          }
+        }
+        const entries = Object.values(byRef).sort((a, b) => (!!b.issue - !!a.issue) || b.repos.size - a.repos.size || a.key.localeCompare(b.key));
+        if (entries.length === 0) {
+          list.innerHTML = '<div class="hint">No GitHub actions or GitLab includes found.</div>';
+          return;
+        }
+        const issueText = { unpinned: 'Not pinned to a tag or commit, changes without notice', deprecated: 'Runs on a retired Node.js version' };
+        list.innerHTML = entries.map(e => `
+          <div style="display: flex; align-items: center; gap: 10px; padding: 6px 0; border-bottom: 1px solid var(--border-color);">
+            <div style="flex: 1;">
+              <strong>${escapeHtml(e.key)}</strong> <span style="color: #9ca0b0; font-size: 0.85em;">${escapeHtml(e.system)}, ${escapeHtml(e.pinning)}</span>
+              ${e.issue ? `<span class="${e.issue === 'deprecated' ? 'log-error' : 'log-warning'}">${escapeHtml(issueText[e.issue] || e.issue)}</span>` : ''}
+              <div style="color: #9ca0b0; font-size: 0.85em;">${[...e.repos].map(escapeHtml).join(', ')}</div>
+            </div>
+            ${e.uses ? `<button class="btn btn-secondary" data-from="${escapeHtml(e.uses)}" data-to="${escapeHtml(e.suggestion || '')}"
+              onclick="selectCIAction(this.dataset.from, this.dataset.to)" aria-label="Select ${escapeHtml(e.uses)} for replacement">
+              ${e.suggestion ? `→ ${escapeHtml(e.suggestion)}` : 'Select'}
+            </button>` : ''}
+          </div>`).join('');
+      }
+
+      function selectCIAction(from, to) {
+        document.getElementById("ci-action-from").value = from;
+        document.getElementById("ci-action-to").value = to || from.split('@')[0] + '@';
+        if (!to) document.getElementById("ci-action-to").focus();
+      }
+
+      // Replaces an action reference in all repos of the last dashboard scan that use it
+      async function updateCIAction() {
+        const from = document.getElementById("ci-action-from").value.trim();
+        const to = document.getElementById("ci-action-to").value.trim();
+        if (!from.includes('@') || !to.includes('@')) {
+          showToast('Error', 'Please enter the current and the new reference, e.g. actions/checkout@v4.', 'error');
+          return;
+        }
+        const repos = currentStats.repoDetails
+          .filter(repo => (repo.ciActions || []).some(a => a.uses === from))
+          .map(repo => repo.path);
+        if (repos.length === 0) {
+          showToast('Error', `No repository of the last dashboard scan uses ${from}.`, 'error');
+          return;
+        }
+
+        const log = document.getElementById("ci-action-log");
+        log.classList.remove("hidden");
+        log.innerHTML = "";
+        try {
+          const response = await fetch("/api/ci-actions/update", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({
+              rootPath: lastLoadedPath,
+              group: getSelectedGroup(),
+              repos,
+              from,
+              to,
+              branch: document.getElementById("ci-action-branch").value.trim(),
+              signing: getSigningSettings(),
+            }),
+          });
+          if (!response.ok) throw new Error(await response.text());
+
+          const reader = response.body.getReader();
+          const decoder = new TextDecoder();
+          while (true) {
+            const { done, value } = await reader.read();
+            if (done) break;
+            for (const line of decoder.decode(value, { stream: true }).split("\n")) {
+              if (!line.trim() || line.startsWith("REPO_DONE:")) continue;
+              if (line.startsWith("CI_ACTION_COMPLETE:")) {
+                showToast('CI Action Updated', `${line.split(":")[1]} repositories updated. Reload the dashboard to re-audit.`, 'success');
+                continue;
+              }
+              const div = document.createElement("div");
+              if (line.startsWith("REPO_START:")) {
+                div.style.fontWeight = "bold";
+                div.textContent = line.substring("REPO_START:".length);
+              } else {
+                div.style.color = line.includes("[ERROR]") ? "#f38ba8" : "#9ca0b0";
+                div.textContent = line;
+              }
+              log.appendChild(div);
+              log.scrollTop = log.scrollHeight;
+            }
+          }
+        } catch (e) {
+          showToast('Error', `CI action update failed: ${e.message}`, 'error');
+        }
+      }
+
+      function renderDependencyBots() {
+        const list = document.getElementById("dependency-bot-list");
+        const missing = currentStats.repoDetails.filter(repo => (repo.dependencyBots || []).length === 0);
+        if (missing.length === 0) {
+          list.innerHTML = '<div class="hint">All repositories have a dependency bot configured.</div>';
+          return;
+        }
+        list.innerHTML = missing.map(repo => `
+          <label style="display: flex; align-items: center; gap: 8px; padding: 6px 0; border-bottom: 1px solid var(--border-color); font-weight: normal;">
+            <input type="checkbox" class="dependency-bot-repo-cb" value="${escapeHtml(repo.path)}" checked style="width: auto;" />
+            <strong>${escapeHtml(repo.name)}</strong> <span style="color: #9ca0b0; font-size: 0.85em;">${escapeHtml(repo.projectType || '')}</span>
+          </label>`).join('');
+        if (!document.getElementById("dependency-bot-template").value) loadDependencyBotTemplate();
+      }
+
+      async function loadDependencyBotTemplate() {
+        try {
+          const res = await fetch("/api/dependency-bot-settings");
+          if (!res.ok) throw new Error(await res.text());
+          const settings = await res.json();
+          document.getElementById("dependency-bot-template").value = settings[document.getElementById("dependency-bot-tool").value] || "";
+        } catch (e) {
+          console.error("Failed to load dependency bot templates", e);
+        }
+      }
+
+      async function saveDependencyBotTemplate() {
+        try {
+          const res = await fetch("/api/dependency-bot-settings");
+          if (!res.ok) throw new Error(await res.text());
+          const settings = await res.json();
+          settings[document.getElementById("dependency-bot-tool").value] = document.getElementById("dependency-bot-template").value;
+          const saved = await fetch("/api/dependency-bot-settings", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify(settings),
+          });
+          if (!saved.ok) throw new Error(await saved.text());
+          showToast('Saved', 'Dependency bot template saved.', 'success');
+        } catch (e) {
+          showToast('Error', `Could not save the template: ${e.message}`, 'error');
+        }
+      }
+
+      // Commits the templated bot config to the selected repos of the last dashboard scan
+      async function seedDependencyBots() {
+        const repos = Array.from(document.querySelectorAll(".dependency-bot-repo-cb:checked")).map(cb => cb.value);
+        if (repos.length === 0) {
+          showToast('Error', 'Please load the dashboard and select at least one repository.', 'error');
+          return;
+        }
+
+        const log = document.getElementById("dependency-bot-log");
+        log.classList.remove("hidden");
+        log.innerHTML = "";
+        try {
+          const response = await fetch("/api/dependency-bots/seed", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({
+              rootPath: lastLoadedPath,
+              group: getSelectedGroup(),
+              repos,
+              tool: document.getElementById("dependency-bot-tool").value,
+              branch: document.getElementById("dependency-bot-branch").value.trim(),
+              signing: getSigningSettings(),
+            }),
+          });
+          if (!response.ok) throw new Error(await response.text());
+
+          const reader = response.body.getReader();
+          const decoder = new TextDecoder();
+          while (true) {
+            const { done, value } = await reader.read();
+            if (done) break;
+            for (const line of decoder.decode(value, { stream: true }).split("\n")) {
+              if (!line.trim() || line.startsWith("REPO_DONE:")) continue;
+              if (line.startsWith("DEPENDENCY_BOT_COMPLETE:")) {
+                showToast('Dependency Bots', `${line.split(":")[1]} repositories configured. Reload the dashboard to re-check.`, 'success');
+                continue;
+              }
+              const div = document.createElement("div");
+              if (line.startsWith("REPO_START:")) {
+                div.style.fontWeight = "bold";
+                div.textContent = line.substring("REPO_START:".length);
+              } else {
+                div.style.color = line.includes("[ERROR]") ? "#f38ba8" : "#9ca0b0";
+                div.textContent = line;
+              }
+              log.appendChild(div);
+              log.scrollTop = log.scrollHeight;
+            }
+          }
+        } catch (e) {
+          showToast('Error', `Seeding dependency bots failed: ${e.message}`, 'error');
+        }
+      }
+
+      function renderHygiene() {
+        const list = document.getElementById("hygiene-list");
+        const incomplete = currentStats.repoDetails.filter(repo => (repo.hygiene?.missing || []).length > 0);
+        if (incomplete.length === 0) {
+          list.innerHTML = '<div class="hint">All repositories have the standard files.</div>';
+          return;
+        }
+        list.innerHTML = incomplete.map(repo => `
+          <label style="display: flex; align-items: center; gap: 8px; padding: 6px 0; border-bottom: 1px solid var(--border-color); font-weight: normal;">
+            <input type="checkbox" class="hygiene-repo-cb" value="${escapeHtml(repo.path)}" checked style="width: auto;" />
+            <strong>${escapeHtml(repo.name)}</strong> <span style="color: #9ca0b0; font-size: 0.85em;">missing ${repo.hygiene.missing.map(escapeHtml).join(', ')}</span>
+          </label>`).join('');
+        if (!document.getElementById("hygiene-template").value) loadHygieneTemplate();
+      }
+
+      async function loadHygieneTemplate() {
+        try {
+          const res = await fetch("/api/hygiene-templates");
+          if (!res.ok) throw new Error(await res.text());
+          const templates = await res.json();
+          document.getElementById("hygiene-template").value = templates[document.getElementById("hygiene-template-file").value] || "";
+        } catch (e) {
+          console.error("Failed to load file templates", e);
+        }
+      }
+
+      async function saveHygieneTemplate() {
+        try {
+          const res = await fetch("/api/hygiene-templates");
+          if (!res.ok) throw new Error(await res.text());
+          const templates = await res.json();
+          templates[document.getElementById("hygiene-template-file").value] = document.getElementById("hygiene-template").value;
+          const saved = await fetch("/api/hygiene-templates", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify(templates),
+          });
+          if (!saved.ok) throw new Error(await saved.text());
+          showToast('Saved', 'File template saved.', 'success');
+        } catch (e) {
+          showToast('Error', `Could not save the template: ${e.message}`, 'error');
+        }
+      }
+
+      // Adds the selected missing files to the selected repos of the last dashboard scan
+      async function seedHygieneFiles() {
+        const repos = Array.from(document.querySelectorAll(".hygiene-repo-cb:checked")).map(cb => cb.value);
+        const files = Array.from(document.querySelectorAll(".hygiene-file-cb:checked")).map(cb => cb.value);
+        if (repos.length === 0 || files.length === 0) {
+          showToast('Error', 'Please load the dashboard and select at least one repository and file.', 'error');
+          return;
+        }
+
+        const log = document.getElementById("hygiene-log");
+        log.classList.remove("hidden");
+        log.innerHTML = "";
+        try {
+          const response = await fetch("/api/hygiene/seed", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({
+              rootPath: lastLoadedPath,
+              group: getSelectedGroup(),
+              repos,
+              files,
+              branch: document.getElementById("hygiene-branch").value.trim(),
+              signing: getSigningSettings(),
+            }),
+          });
+          if (!response.ok) throw new Error(await response.text());
+
+          const reader = response.body.getReader();
+          const decoder = new TextDecoder();
+          while (true) {
+            const { done, value } = await reader.read();
+            if (done) break;
+            for (const line of decoder.decode(value, { stream: true }).split("\n")) {
+              if (!line.trim() || line.startsWith("REPO_DONE:")) continue;
+              if (line.startsWith("HYGIENE_COMPLETE:")) {
+                showToast('Repository Files', `${line.split(":")[1]} repositories updated. Reload the dashboard to re-check.`, 'success');
+                continue;
+              }
+              const div = document.createElement("div");
+              if (line.startsWith("REPO_START:")) {
+                div.style.fontWeight = "bold";
+                div.textContent = line.substring("REPO_START:".length);
+              } else {
+                div.style.color = line.includes("[ERROR]") ? "#f38ba8" : line.includes("[WARN]") ? "#fab387" : "#9ca0b0";
+                div.textContent = line;
+              }
+              log.appendChild(div);
+              log.scrollTop = log.scrollHeight;
+            }
+          }
+        } catch (e) {
+          showToast('Error', `Seeding files failed: ${e.message}`, 'error');
+        }
+      }
+
+      function renderTrendChart(title, points, key, color) {
+        const width = 300, height = 110, pad = 6;
+        const values = points.map(p => p[key] || 0);
+        const times = points.map(p => new Date(p.time).getTime());
+        const minV = Math.min(...values), maxV = Math.max(...values);
+        const minT = times[0], spanT = Math.max(1, times[times.length - 1] - minT);
+        const x = t => pad + ((t - minT) / spanT) * (width - 2 * pad);
+        const y = v => height - pad - (maxV === minV ? 0.5 : (v - minV) / (maxV - minV)) * (height - 2 * pad);
+        const path = values.map((v, i) => `${i === 0 ? "M" : "L"}${x(times[i]).toFixed(1)},${y(v).toFixed(1)}`).join(" ");
+        const dots = values.map((v, i) =>
+          `<circle cx="${x(times[i]).toFixed(1)}" cy="${y(v).toFixed(1)}" r="2.5" fill="${color}"><title>${new Date(times[i]).toLocaleString()}: ${v}</title></circle>`
+        ).join("");
+        return `<div class="chart-card" style="margin: 0;">
+            <div class="metric-label">${escapeHtml(title)}: ${values[values.length - 1]}</div>
+            <svg viewBox="0 0 ${width} ${height}" style="width: 100%; height: ${height}px;" role="img" aria-label="${escapeHtml(title)} trend">
+              <path d="${path}" fill="none" stroke="${color}" stroke-width="2" />${dots}
+            </svg>
+            <div class="hint" style="margin: 0;">min ${minV} · max ${maxV}</div>
+          </div>`;
+      }
+
+      // Get framework icon/badge
+      function getFrameworkBadge(framework) {
+        const frameworkIcons = {
+            'Next.js': { icon: '▲', color: '#000' },
+            'Nuxt.js': { icon: '💚', color: '#00DC82' },
+            'Angular': { icon: '🅰️', color: '#DD0031' },
+            'Vue.js': { icon: '💚', color: '#4FC08D' },
+            'React': { icon: '⚛️', color: '#61DAFB' },
+            'Svelte': { icon: '🔥', color: '#FF3E00' },
+            'Express': { icon: '🚀', color: '#000' },
+            'Fastify': { icon: '⚡', color: '#000' },
+            'NestJS': { icon: '🐱', color: '#E0234E' },
+            'Gatsby': { icon: '💜', color: '#663399' },
+            'Remix': { icon: '💿', color: '#000' },
+            'Koa': { icon: '🥝', color: '#000' },
+            'Electron': { icon: '⚡', color: '#47848F' },
+            'Spring Boot': { icon: '🍃', color: '#6DB33F' },
+            // Go frameworks
+            'Go': { icon: '🐹', color: '#00ADD8' },
+            'Gin': { icon: '🐹', color: '#00ADD8' },
+            'Fiber': { icon: '🐹', color: '#00ADD8' },
+            'Echo': { icon: '🐹', color: '#00ADD8' },
+            'Chi': { icon: '🐹', color: '#00ADD8' },
+            'Gorilla Mux': { icon: '🐹', color: '#00ADD8' },
+            'gRPC': { icon: '🐹', color: '#00ADD8' },
+            // Python frameworks
+            'Python': { icon: '🐍', color: '#3776AB' },
+            'Django': { icon: '🐍', color: '#092E20' },
+            'Flask': { icon: '🐍', color: '#000' },
+            'FastAPI': { icon: '🐍', color: '#009688' },
+            'Streamlit': { icon: '🐍', color: '#FF4B4B' },
+            'PyTorch': { icon: '🔥', color: '#EE4C2C' },
+            'TensorFlow': { icon: '🧠', color: '#FF6F00' },
+            'Data Science': { icon: '📊', color: '#3776AB' },
+            // PHP frameworks
+            'PHP': { icon: '🐘', color: '#777BB4' },
+            'Laravel': { icon: '🐘', color: '#FF2D20' },
+            'Symfony': { icon: '🐘', color: '#000' },
+            'CodeIgniter': { icon: '🐘', color: '#EE4623' },
+            'CakePHP': { icon: '🐘', color: '#D33C44' },
+            'Yii': { icon: '🐘', color: '#40B3D8' },
+            'Slim': { icon: '🐘', color: '#74A045' },
+            // Rust frameworks
+            'Rust': { icon: '🦀', color: '#DEA584' },
+            'Actix Web': { icon: '🦀', color: '#DEA584' },
+            'Axum': { icon: '🦀', color: '#DEA584' },
+            'Rocket': { icon: '🚀', color: '#D33847' },
+            'Warp': { icon: '🦀', color: '#DEA584' },
+            'Tonic': { icon: '🦀', color: '#DEA584' },
+            'Tauri': { icon: '🦀', color: '#FFC131' },
+            'Bevy': { icon: '🎮', color: '#DEA584' },
+            // .NET frameworks
+            '.NET': { icon: '🟣', color: '#512BD4' },
+            'ASP.NET Core': { icon: '🟣', color: '#512BD4' },
+            'Blazor': { icon: '🟣', color: '#512BD4' },
+            'MAUI': { icon: '📱', color: '#512BD4' },
+            'Azure Functions': { icon: '⚡', color: '#0062AD' },
+            'WPF': { icon: '🪟', color: '#512BD4' },
+            'WinForms': { icon: '🪟', color: '#512BD4' },
+            'Worker Service': { icon: '⚙️', color: '#512BD4' },
+            // Ruby frameworks
+            'Ruby': { icon: '💎', color: '#CC342D' },
+            'Rails': { icon: '🛤️', color: '#CC0000' },
+            'Sinatra': { icon: '💎', color: '#000' },
+            'Hanami': { icon: '🌸', color: '#CC342D' },
+            'Jekyll': { icon: '🧪', color: '#CC0000' }
+        };
+
+        const info = frameworkIcons[framework] || { icon: '📦', color: '#888' };
+        return `<span style="color: ${info.color};" title="${framework}">${info.icon}</span>`;
+      }
+
+      function addRepoRow(repo) {
+        const tbody = document.getElementById("repo-table-body");
+        let statusClass = "status-good";
+        let statusText = "Healthy";
+        if (repo.healthScore < 50) {
+            statusClass = "status-bad";
+            statusText = "Critical";
+        } else if (repo.healthScore < 80) {
+            statusClass = "status-warn";
+            statusText = "Warning";
+        }
+
+        // Build Framework column
+        let frameworkDisplay = '-';
+        if (repo.framework) {
+            frameworkDisplay = `${getFrameworkBadge(repo.framework)} ${repo.framework}`;
+            if (repo.framework === "Spring Boot" && repo.springBootVer) {
+                frameworkDisplay += ` ${repo.springBootVer}`;
+            }
+        } else if (repo.springBootVer) {
+            frameworkDisplay = `${getFrameworkBadge('Spring Boot')} Spring Boot ${repo.springBootVer}`;
+        }
+
+        // Build Runtime column (Java, Node.js, Go, or Python version)
+        let runtimeDisplay = '-';
+        if (repo.javaVersion) {
+            runtimeDisplay = `☕ Java ${repo.javaVersion}`;
+        } else if (repo.nodeVersion) {
+            runtimeDisplay = `📗 Node ${repo.nodeVersion}`;
+        } else if (repo.goVersion) {
+            runtimeDisplay = `🐹 Go ${repo.goVersion}`;
+        } else if (repo.pythonVersion) {
+            runtimeDisplay = `🐍 Python ${repo.pythonVersion}`;
+        } else if (repo.rustVersion) {
+            runtimeDisplay = `🦀 Rust ${repo.rustVersion}`;
+        } else if (repo.dotnetVersion) {
+            runtimeDisplay = `🟣 .NET ${repo.dotnetVersion}`;
+        } else if (repo.rubyVersion) {
+            runtimeDisplay = `💎 Ruby ${repo.rubyVersion}`;
+        }
+
+        // Support windows (endoflife.date): badge for runtimes out of support or ending soon
+        const windows = repo.supportWindows || [];
+        let supportBadge = '';
+        let supportTitle = 'No support window data';
+        if (windows.length > 0) {
+            const eol = windows.filter(w => w.status === 'eol');
+            const soon = windows.filter(w => w.status === 'ending-soon');
+            if (eol.length > 0) {
+                supportBadge = ` <span class="status-badge status-bad">⛔ EOL</span>`;
+            } else if (soon.length > 0) {
+                supportBadge = ` <span class="status-badge status-warn">⏳ EOL soon</span>`;
+            }
+            supportTitle = windows.map(w => {
+                let line = `${w.name} ${w.version}: cycle ${w.cycle}`;
+                if (w.eol) {
+                    line += w.daysLeft < 0 ? `, out of support since ${w.eol}` : `, supported until ${w.eol} (${w.daysLeft} days left)`;
+                } else if (w.status === 'eol') {
+                    line += ', out of support';
+                }
+                return line + (w.latest ? `, latest ${w.latest}` : '');
+            }).join('\n');
+        }
+
+        // Java toolchain: versions of Gradle, tool configs, Dockerfiles and CI, warning for older ones than the build target
+        const javaSources = repo.javaSources || [];
+        const javaConflicts = repo.javaConflicts || [];
+        if (javaSources.length > 0) {
+            supportTitle += '\n\nJava versions:' + javaSources.map(s => `\n${s.file} (${s.source}): ${s.version}`).join('') +
+                javaConflicts.map(c => `\n⚠️ ${c}`).join('');
+        }
+        if (javaConflicts.length > 0) {
+            supportBadge += ` <span class="status-badge status-warn">⚠️ Java conflict</span>`;
+        }
+
+        // Outdated deps display
+        let outdatedDisplay = repo.outdatedDeps || 0;
+        let outdatedClass = outdatedDisplay === 0 ? 'status-good' : (outdatedDisplay > 10 ? 'status-bad' : 'status-warn');
+        let outdatedBadge = outdatedDisplay === 0 ? '✅' : (outdatedDisplay > 10 ? '⚠️' : '📦');
+
+        // Dependency freshness display
+        let ageDisplay = '-';
+        let ageTitle = 'No direct dependencies with a resolvable version';
+        const ages = repo.dependencyAges || [];
+        if (ages.length > 0) {
+            const days = repo.avgDependencyAgeDays || 0;
+            const behind = ages.filter(a => a.versionsBehind > 0)
+                .sort((a, b) => b.daysBehind - a.daysBehind);
+            ageDisplay = `${days < 180 ? '🟢' : days < 365 ? '🟡' : '🔴'} ${days}d`;
+            ageTitle = `Average ${days} days behind latest (${ages.length} checked, ${behind.length} behind)` +
+                behind.slice(0, 5).map(a => `\n${a.name}: ${a.version} → ${a.latest} (${a.versionsBehind} versions, ${a.daysBehind} days)`).join('');
+        }
+
+        // Tests display: file count plus coverage of the last build's report
+        const testFiles = repo.testFileCount || 0;
+        let testsDisplay = testFiles === 0 ? '❌ none' : `🧪 ${testFiles}`;
+        let testsTitle = testFiles === 0 ? 'No test files found' : `${testFiles} test files`;
+        if (repo.coverage !== undefined && repo.coverage !== null) {
+            testsDisplay += ` · ${repo.coverage}%`;
+            testsTitle += `, ${repo.coverage}% line coverage (${repo.coverageSource})`;
+        }
+
+        // CI display: detected systems, warning sign for deprecated runners and actions
+        const ciSystems = repo.ciSystems || [];
+        const ciWarnings = repo.ciWarnings || [];
+        let ciDisplay = '❌ none';
+        let ciTitle = 'No CI configuration found';
+        if (ciSystems.length > 0) {
+            // Deprecated actions are among the warnings already, unpinned ones are added
+            const unpinnedActions = (repo.ciActions || []).filter(a => a.issue === 'unpinned');
+            ciDisplay = `${ciWarnings.length > 0 || unpinnedActions.length > 0 ? '⚠️' : '✅'} ${ciSystems.join(', ')}`;
+            ciTitle = (repo.ciFiles || []).join('\n') + ciWarnings.map(w => `\n⚠️ ${w}`).join('') +
+                unpinnedActions.map(a => `\n⚠️ ${a.file}: ${a.name}${a.ref ? '@' + a.ref : ''} is not pinned to a tag or commit`).join('');
+        }
+
+        // Base image display: number of images of Dockerfiles and Kubernetes manifests, warning sign
+        // for latest or end-of-life images
+        const k8sImages = repo.kubernetes?.images || [];
+        const baseImages = (repo.baseImages || []).concat(k8sImages);
+        const flaggedImages = baseImages.filter(i => i.issue);
+        let imagesDisplay = '-';
+        let imagesTitle = repo.kubernetes ? 'No images referenced' : 'No Dockerfiles';
+        if (baseImages.length > 0) {
+            imagesDisplay = `${flaggedImages.length > 0 ? '⚠️' : (repo.baseImages || []).length > 0 ? '🐳' : '☸️'} ${baseImages.length}`;
+            imagesTitle = baseImages.map(i => `${k8sImages.includes(i) ? '☸️ ' : ''}${i.file}: ${i.image}${i.issue ? ` ⚠️ ${i.detail}` : ''}`).join('\n');
+        }
+
+        // Terraform display: providers and modules, warning sign for unpinned versions or missing lock files
+        const terraform = repo.terraform;
+        let terraformDisplay = '-';
+        let terraformTitle = 'No Terraform files';
+        if (terraform) {
+            const pins = (terraform.providers || []).concat(terraform.modules || []);
+            const unpinned = pins.filter(p => p.pinning === 'unpinned');
+            const unlocked = terraform.unlocked || [];
+            terraformDisplay = `${unpinned.length > 0 || unlocked.length > 0 ? '⚠️' : '🏗️'} ${pins.length}`;
+            terraformTitle = (terraform.requiredVersions || []).map(v => `terraform ${v}\n`).join('') +
+                pins.map(p => `${p.kind} ${p.name}${p.version ? ' ' + p.version : ''}: ${p.pinning === 'unpinned' ? '⚠️ unpinned' : p.pinning} (${p.file})`).join('\n') +
+                unlocked.map(d => `\n⚠️ ${d}: no .terraform.lock.hcl`).join('');
+            if (pins.length === 0) terraformTitle = `${terraform.dirs.length} Terraform directories without providers or modules`;
+        }
+
+        // Dependency bot display: Renovate or Dependabot, cross for repos updated by hand
+        const bots = repo.dependencyBots || [];
+        const botDisplay = bots.length === 0 ? '❌' : `🤖 ${bots.map(b => b.tool === 'renovate' ? 'Renovate' : 'Dependabot').join(', ')}`;
+        const botTitle = bots.length === 0 ? 'No Renovate or Dependabot configuration' : bots.map(b => b.file).join('\n');
+
+        // Standard files display: present of the five, warning sign when one is missing
+        const missingFiles = repo.hygiene?.missing || [];
+        const foundFiles = Object.values(repo.hygiene?.found || {});
+        const filesDisplay = `${missingFiles.length > 0 ? '⚠️' : '✅'} ${foundFiles.length}/${foundFiles.length + missingFiles.length}`;
+        const filesTitle = foundFiles.join('\n') + missingFiles.map(f => `\n❌ ${f} missing`).join('');
+
+        // Activity display: abandoned repos are archiving candidates
+        const activity = repo.activity;
+        let activityBadge = '';
+        let activityTitle = 'No git activity data';
+        if (activity) {
+            if (activity.abandoned) activityBadge = ' <span class="status-badge status-warn">💤 abandoned</span>';
+            activityTitle = `${activity.commits90d} commits in 90 days, ${activity.commits365d} in the last year\n` +
+                `${activity.contributors365d} active contributors (${activity.contributors} all time)\n` +
+                (activity.lastRelease ? `Last release ${activity.lastRelease} on ${activity.lastReleaseDate} (${activity.lastReleaseDays} days ago)` : 'No release tags');
+        }
+
+        const deductions = repo.scoreDeductions || [];
+        const scoreTitle = deductions.length === 0 ? 'No deductions'
+            : deductions.map(d => `-${d.points} ${d.rule}`).join('\n');
+
+        const tr = document.createElement("tr");
+        tr.innerHTML = `
+            <td>${repo.name}</td>
+            <td>
+                <div style="display:flex; align-items:center; gap:10px;" title="${escapeHtml(scoreTitle)}">
+                    <div style="flex:1; height:6px; background:#45475a; border-radius:3px; width:50px;">
+                        <div style="width:${repo.healthScore}%; height:100%; background:${repo.healthScore < 50 ? '#f38ba8' : repo.healthScore < 80 ? '#fab387' : '#a6e3a1'}; border-radius:3px;"></div>
+                    </div>
+                    <span>${repo.healthScore}</span>
+                </div>
+            </td>
+            <td>${frameworkDisplay}</td>
+            <td><span title="${escapeHtml(supportTitle)}">${runtimeDisplay}</span>${supportBadge}</td>
+            <td><span title="${escapeHtml(activityTitle)}">${repo.lastCommit || '-'}</span>${activityBadge}</td>
+            <td>${repo.todoCount}</td>
+            <td><span title="${escapeHtml(testsTitle)}">${testsDisplay}</span></td>
+            <td><span title="${escapeHtml(ciTitle)}">${escapeHtml(ciDisplay)}</span></td>
+            <td><span title="${escapeHtml(imagesTitle)}">${imagesDisplay}</span></td>
+            <td><span title="${escapeHtml(terraformTitle)}">${terraformDisplay}</span></td>
+            <td><span title="${escapeHtml(botTitle)}">${botDisplay}</span></td>
+            <td><span title="${escapeHtml(filesTitle.trim())}">${filesDisplay}</span></td>
+            <td><span title="${outdatedDisplay} outdated packages">${outdatedBadge} ${outdatedDisplay}</span></td>
+            <td><span title="${escapeHtml(ageTitle)}">${ageDisplay}</span></td>
+            <td><span class="status-badge ${statusClass}">${statusText}</span></td>
+        `;
+        tbody.appendChild(tr);
+      }
+
+      function updateCharts() {
+        // Top Dependencies
+        const sortedDeps = Object.entries(currentStats.topDependencies)
+            .sort((a, b) => b[1] - a[1])
+            .slice(0, 5);
+
+        const depsContainer = document.getElementById("chart-deps");
+        depsContainer.innerHTML = "";
+
+        if (sortedDeps.length === 0) {
+            depsContainer.innerHTML = '<div class="hint" style="color: #a6adc8; font-size: 0.9em;">Dependencies are collected during scan. Maven, Node.js, Go and Python projects are supported.</div>';
+        } else {
+            // Find max for scaling
+            const maxDep = sortedDeps[0][1];
+
+            sortedDeps.forEach(([name, count]) => {
+                const pct = (count / maxDep) * 100;
+                const tooltip = `${name} is used in ${count} project${count > 1 ? 's' : ''}`;
+                const row = document.createElement("div");
+                row.className = "bar-row";
+                row.style.cursor = "help";
+                row.title = tooltip;
+                row.innerHTML = `
+                    <div class="bar-label" title="${tooltip}">${name}</div>
+                    <div class="bar-track" title="${tooltip}">
+                        <div class="bar-fill" style="width: ${pct}%; background-color: #89b4fa;"></div>
+                    </div>
+                    <div class="bar-value">${count}</div>
+                `;
+                depsContainer.appendChild(row);
+            });
+        }
+
+        // Frameworks Chart
+        const sortedFrameworks = Object.entries(currentStats.frameworks)
+            .sort((a, b) => b[1] - a[1]); // Sort by count desc
+
+        const frameworksContainer = document.getElementById("chart-frameworks");
+        frameworksContainer.innerHTML = "";
+
+        if (sortedFrameworks.length === 0) {
+            frameworksContainer.innerHTML = '<div class="hint" style="color: #a6adc8; font-size: 0.9em;">No frameworks detected. Supported: Spring Boot, React, Vue, Angular, Next.js, Go, Python, Django, Flask, etc.</div>';
+        } else {
+            const maxFramework = sortedFrameworks[0][1];
+
+            sortedFrameworks.forEach(([name, count]) => {
+                const pct = (count / maxFramework) * 100;
+                const tooltip = `${count} project${count > 1 ? 's' : ''} use${count > 1 ? '' : 's'} ${name}`;
+
+                const row = document.createElement("div");
+                row.className = "bar-row";
+                row.style.cursor = "help";
+                row.title = tooltip;
+                row.innerHTML = `
+                    <div class="bar-label" title="${tooltip}">${name}</div>
+                    <div class="bar-track" title="${tooltip}">
+                        <div class="bar-fill" style="width: ${pct}%; background-color: #a6e3a1;"></div>
+                    </div>
+                    <div class="bar-value">${count}</div>
+                `;
+                frameworksContainer.appendChild(row);
+            });
+        }
+      }
+
+      // Track if Spring versions have been loaded to avoid redundant fetches
+      let springVersionsLoaded = false;
+
+      async function loadSpringVersions() {
+        const container = document.getElementById("spring-versions-list");
+
+        // Skip if already loaded (use cache)
+        if (springVersionsLoaded && window.springVersionsCache) {
+          return;
+        }
+
+        // Show loading indicator
+        container.innerHTML = '<div class="hint">Loading Spring Boot versions from Maven Central...</div>';
+
+        try {
+          const res = await fetch("/api/spring-versions");
+          if (!res.ok) throw new Error("Failed to fetch versions");
+          const versions = await res.json();
+
+          // Cache for Migration Assistant
+          window.springVersionsCache = versions;
+          springVersionsLoaded = true;
+
+          container.innerHTML = "";
+
+          // Show only first 5 branches initially
+          const initialShowCount = 5;
+          let hiddenContainer = null;
+          let showMoreBtn = null;
+
+          versions.forEach((group, index) => {
+            const groupDiv = document.createElement("div");
+            groupDiv.style.marginBottom = "20px";
+            groupDiv.style.padding = "15px";
+            groupDiv.style.backgroundColor = "var(--input-bg)";
+            groupDiv.style.borderRadius = "6px";
+
+            const title = document.createElement("h4");
+            title.style.margin = "0 0 10px 0";
+            title.style.color = "var(--accent-color)";
+            title.textContent = `Spring Boot ${group.Branch}`;
+
+            if (group.MigrationGuide) {
+              const link = document.createElement("a");
+              link.href = group.MigrationGuide;
+              link.target = "_blank";
+              link.style.fontSize = "0.8em";
+              link.style.marginLeft = "10px";
+              link.style.color = "#89b4fa";
+              link.textContent = group.Branch.endsWith(".0")
+                ? "📖 Migration Guide"
+                : "📝 Release Notes";
+              title.appendChild(link);
+            }
+
+            groupDiv.appendChild(title);
+
+            const versionsDiv = document.createElement("div");
+            versionsDiv.style.fontSize = "0.9em";
+            versionsDiv.style.color = "#a6adc8";
+            // Show top 5 versions, then "..."
+            const showCount = 5;
+            const visible = group.Versions.slice(0, showCount);
+            versionsDiv.textContent = visible.join(", ");
+
+            if (group.Versions.length > showCount) {
+              const more = document.createElement("span");
+              more.textContent = ` (+${
+                group.Versions.length - showCount
+              } weitere)`;
+              more.style.cursor = "pointer";
+              more.style.textDecoration = "underline";
+              more.style.marginLeft = "5px";
+              more.onclick = () => {
+                versionsDiv.textContent = group.Versions.join(", ");
+              };
+              versionsDiv.appendChild(more);
+            }
+
+            groupDiv.appendChild(versionsDiv);
+
+            // Add to visible container or hidden container based on index
+            if (index < initialShowCount) {
+              container.appendChild(groupDiv);
+            } else {
+              // Create hidden container if not exists
+              if (!hiddenContainer) {
+                hiddenContainer = document.createElement("div");
+                hiddenContainer.id = "hidden-versions";
+                hiddenContainer.style.display = "none";
+                container.appendChild(hiddenContainer);
+              }
+              hiddenContainer.appendChild(groupDiv);
+            }
+          });
+
+          // Add "Show More" button if there are hidden items
+          if (versions.length > initialShowCount) {
+            showMoreBtn = document.createElement("button");
+            showMoreBtn.className = "btn btn-secondary";
+            showMoreBtn.style.width = "100%";
+            showMoreBtn.style.marginTop = "10px";
+            showMoreBtn.innerHTML = `📋 Show older versions (+${
+              versions.length - initialShowCount
+            })`;
+            showMoreBtn.onclick = () => {
+              const hidden = document.getElementById("hidden-versions");
+              if (hidden.style.display === "none") {
+                hidden.style.display = "block";
+                showMoreBtn.innerHTML = "📋 Hide older versions";
+              } else {
+                hidden.style.display = "none";
+                showMoreBtn.innerHTML = `📋 Show older versions (+${
+                  versions.length - initialShowCount
+                })`;
+              }
+            };
+            container.appendChild(showMoreBtn);
+          }
+
+          // Also populate the dropdown if we are in Spring Boot mode
+          const typeInput = document.querySelector('input[name="migrationType"]:checked');
+          if (typeInput && typeInput.value === 'spring-boot') {
+              populateSpringVersions(versions);
+          }
+
+        } catch (e) {
+          container.innerHTML = `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
+        }
+      }
+
+      function populateSpringVersions(versions) {
+        const select = document.getElementById("targetBootVersion");
+        if (!select) return;
+        select.innerHTML = '<option value="">Select Version...</option>';
+
+        versions.forEach((v) => {
+            // Add the branch (Major.Minor) as value - OpenRewrite only has recipes for minor versions
+            if (v.Versions && v.Versions.length > 0) {
+                const opt = document.createElement("option");
+                opt.value = v.Branch; // Use Major.Minor (e.g., "3.5") instead of full version
+                opt.innerText = `Spring Boot ${v.Branch} (latest: ${v.Versions[0]})`;
+                select.appendChild(opt);
+            }
+        });
+      }
+
+      function updateMigrationOptions() {
+        const typeInput = document.querySelector('input[name="migrationType"]:checked');
+        if (!typeInput) return;
+        const type = typeInput.value;
+
+        const title = document.getElementById('migration-options-title');
+        const select = document.getElementById('targetBootVersion');
+        const hint = document.getElementById('migration-hint');
+        const card = document.getElementById('migration-options-card');
+        const container = select ? select.parentElement : null;
+
+        if (!select) return;
+
+        // Reset
+        select.innerHTML = '';
+        select.style.display = 'block';
+        if (container) container.style.justifyContent = '';
+        document.getElementById('jakarta-readiness-btn')?.classList.toggle('hidden', type !== 'jakarta-ee');
+        const readinessList = document.getElementById('jakarta-readiness-list');
+        if (readinessList && type !== 'jakarta-ee') readinessList.innerHTML = '';
+
+        if (type === 'spring-boot') {
+            title.innerText = 'Target Spring Boot Version';
+            hint.innerText = 'Runs OpenRewrite to upgrade Spring Boot version.';
+            if (window.springVersionsCache) {
+                 populateSpringVersions(window.springVersionsCache);
+            } else {
+                 loadSpringVersions();
+            }
+        } else if (type === 'java-version') {
+            title.innerText = 'Target Java Version';
+            hint.innerText = 'Runs OpenRewrite to upgrade Java version.';
+            // LTS Versions: 25, 21, 17, 11, 8
+            const versions = ['25', '21', '17', '11', '8'];
+            versions.forEach(v => {
+                const opt = document.createElement("option");
+                opt.value = v;
+                opt.innerText = 'Java ' + v + (v === '25' ? ' (Latest LTS)' : '');
+                select.appendChild(opt);
+            });
+        } else if (type === 'jakarta-ee') {
+            title.innerText = 'Jakarta EE Migration';
+            hint.innerText = 'Runs OpenRewrite to migrate from javax to jakarta namespace. Check Readiness lists the dependencies without a Jakarta-compatible release first.';
+            select.style.display = 'none';
+            if (container) container.style.justifyContent = 'flex-end';
+        } else if (type === 'quarkus') {
+            title.innerText = 'Target Quarkus Stream';
+            hint.innerText = 'Runs the Quarkus update (quarkus-maven-plugin:update) as dry run for projects importing quarkus-bom.';
+            const latest = document.createElement("option");
+            latest.value = '';
+            latest.innerText = 'Latest stream';
+            select.appendChild(latest);
+            loadQuarkusStreams(select);
+        }
+      }
+
+      async function loadQuarkusStreams(select) {
+        try {
+          const res = await fetch("/api/quarkus-streams");
+          if (!res.ok) return;
+          const streams = await res.json();
+          // The type may have changed while loading
+          if (document.querySelector('input[name="migrationType"]:checked')?.value !== 'quarkus') return;
+          streams.slice(0, 10).forEach(stream => {
+            const opt = document.createElement("option");
+            opt.value = stream;
+            opt.innerText = 'Quarkus ' + stream;
+            select.appendChild(opt);
+          });
+        } catch (e) {
+          // Offline: the latest stream is resolved by the plugin
+        }
+      }
+
+      // Track if OpenRewrite versions have been loaded
+      let openRewriteVersionsLoaded = false;
+
+      async function checkOpenRewriteVersions() {
+        // Skip if already loaded
+        if (openRewriteVersionsLoaded) {
+          return;
+        }
+
+        const container = document.getElementById("openrewrite-versions");
+
+        try {
+          const res = await fetch("/api/openrewrite-versions");
+          if (!res.ok) throw new Error(res.statusText);
+          const data = await res.json();
+
+          openRewriteVersionsLoaded = true;
+          container.innerHTML = "";
+
+          data.forEach((item) => {
+            const card = document.createElement("div");
+            card.style.cssText =
+              "flex: 1; min-width: 200px; padding: 12px; background: var(--bg-color); border-radius: 6px; border: 1px solid var(--border-color);";
+
+            const hasUpdate = item.updateAvailable;
+            const statusColor = hasUpdate ? "#f9e2af" : "#a6e3a1";
+            const statusIcon = hasUpdate ? "⚠️" : "✅";
+            const statusText = hasUpdate ? "Update available" : "Up to date";
+
+            card.innerHTML = `
+              <div style="font-weight: 500; margin-bottom: 8px; color: var(--text-color);">
+                ${item.component}
+              </div>
+              <div style="display: flex; justify-content: space-between; font-size: 0.9em; margin-bottom: 4px;">
+                <span style="color: #6c7086;">Using:</span>
+                <span style="color: var(--text-color); font-family: monospace;">${
+                  item.currentVersion
+                }</span>
+              </div>
+              <div style="display: flex; justify-content: space-between; font-size: 0.9em; margin-bottom: 8px;">
+                <span style="color: #6c7086;">Latest:</span>
+                <span style="color: ${
+                  hasUpdate ? "#a6e3a1" : "var(--text-color)"
+                }; font-family: monospace;">${item.latestVersion}</span>
+              </div>
+              <div style="display: flex; justify-content: space-between; align-items: center;">
+                <span style="color: ${statusColor}; font-size: 0.85em;">${statusIcon} ${statusText}</span>
+                <a href="${
+                  item.mavenCentralUrl
+                }" target="_blank" style="color: #89b4fa; font-size: 0.85em; text-decoration: none;">
+                  Maven Central ↗
+                </a>
+              </div>
+            `;
+
+            container.appendChild(card);
+          });
+        } catch (e) {
+          container.innerHTML = `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
+        }
+      }
+
+      async function scanSpringProjects() {
+        const container = document.getElementById("spring-projects-list");
+        const rootPath = document.getElementById("rootPath").value;
+
+        // Calculate Excluded Folders from Checkboxes
+        const excluded = ["node_modules", "target", "dist", ".idea", ".vscode"]; // Standard defaults
+        document
+          .querySelectorAll('#folder-list-container input[type="checkbox"]')
+          .forEach((cb) => {
+            if (!cb.checked) {
+              excluded.push(cb.value);
+            }
+          });
+
+        if (!rootPath) {
+          alert("Please configure the project path in Project Setup first.");
+          return;
+        }
+
+        container.innerHTML = '<div class="log-info">Scanning...</div>';
+
+        try {
+          const res = await fetch("/api/scan-spring", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({ RootPath: rootPath, Excluded: excluded, Group: getSelectedGroup(), Maven: getMavenSettings() }),
+          });
+          if (!res.ok) throw new Error(res.statusText);
+          const results = await res.json();
+
+          container.innerHTML = "";
+
+          // Handle new SpringScanResult structure
+          if (!results || !results.Projects || results.Projects.length === 0) {
+            let html = '<div class="hint">No Spring Boot projects found.</div>';
+
+            // Show debug log if available
+            if (results && results.DebugLog && results.DebugLog.length > 0) {
+              html +=
+                '<div style="margin-top:20px; border-top:1px solid var(--border-color); padding-top:10px;">';
+              html += "<h4>Debug Log</h4>";
+              html +=
+                '<div style="font-family:monospace; font-size:0.8em; color:#a6adc8; max-height:200px; overflow-y:auto;">';
+              results.DebugLog.forEach((line) => {
+                html += `<div>${line}</div>`;
+              });
+              html += "</div></div>";
+            }
+
+            container.innerHTML = html;
+            return;
+          }
+
+          // Display found projects
+          results.Projects.forEach((proj) => {
+            const div = document.createElement("div");
+            div.style.padding = "10px";
+            div.style.borderBottom = "1px solid var(--border-color)";
+
+            div.innerHTML = `
+                    <div style="font-weight:bold;">${proj.RepoName}</div>
+                    <div style="font-size:0.9em; color: #a6adc8;">Aktuell: <span style="color: var(--accent-color);">${proj.CurrentVersion}</span></div>
+                `;
+            container.appendChild(div);
+          });
+        } catch (e) {
+          container.innerHTML = `<div class="log-error">Error scanning: ${escapeHtml(e.message)}</div>`;
+        }
+      }
+
+      // Folder Picker: Call backend API to open native OS dialog
+      async function pickFolder(targetId) {
+        try {
+          const res = await fetch("/api/pick-folder");
+          if (!res.ok) {
+            const text = await res.text();
+            throw new Error(text);
+          }
+          const data = await res.json();
+          if (data.path) {
+            document.getElementById(targetId).value = data.path;
+            if (targetId === "rootPath") {
+              loadFolders();
+              loadDashboardStats(data.path);
+              // Reset security repos when path changes
+              securityReposLoaded = false;
+            }
+          }
+        } catch (e) {
+          console.error(e);
+          alert("Error opening dialog: " + e.message);
+        }
+      }
+
+      async function loadFolders() {
+        const rootPath = document.getElementById("rootPath").value;
+        const container = document.getElementById("folder-list-container");
+
+        if (!rootPath) {
+          container.innerHTML = "";
+          return;
+        }
+
+        // Save path immediately to localStorage for better UX
+        const saved = localStorage.getItem("gitHousekeeper_settings");
+        let settings = {};
+        if (saved) {
+            try { settings = JSON.parse(saved); } catch(e) {}
+        }
+        settings.rootPath = rootPath;
+        localStorage.setItem("gitHousekeeper_settings", JSON.stringify(settings));
+
+        container.innerHTML = '<div class="log-info">Loading folders...</div>';
+
+        try {
+          const res = await fetch("/api/list-folders", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({ Path: rootPath }),
+          });
+
+          if (!res.ok) throw new Error(res.statusText);
+          const data = await res.json();
+
+          container.innerHTML = "";
+
+          if (data.Error) {
+            container.innerHTML = `<div class="log-error">${escapeHtml(data.Error)}</div>`;
+            return;
+          }
+
+          if (data.IsRepo) {
+            container.innerHTML =
+              '<div class="hint">None (Current folder is a Git Repository)</div>';
+            return;
+          }
+
+          if (!data.Folders || data.Folders.length === 0) {
+            container.innerHTML =
+              '<div class="hint">No subfolders found.</div>';
+            return;
+          }
+
+          const ignoreDisplay = [
+            ".git",
+            "node_modules",
+            "target",
+            "dist",
+            ".idea",
+            ".vscode",
+          ];
+
+          data.Folders.forEach((folder) => {
+            if (ignoreDisplay.includes(folder)) return;
+
+            const div = document.createElement("div");
+            div.style.marginBottom = "5px";
+
+            const checkbox = document.createElement("input");
+            checkbox.type = "checkbox";
+            checkbox.id = "folder_" + folder;
+            checkbox.value = folder;
+            checkbox.checked = true; // Default checked
+            checkbox.style.width = "auto";
+            checkbox.style.marginRight = "10px";
+
+            const label = document.createElement("label");
+            label.htmlFor = "folder_" + folder;
+            label.textContent = folder;
+            label.style.display = "inline";
+            label.style.fontWeight = "normal";
+
+            div.appendChild(checkbox);
+            div.appendChild(label);
+            container.appendChild(div);
+          });
+
+          if (container.children.length === 0) {
+            container.innerHTML =
+              '<div class="hint">No relevant subfolders found.</div>';
+          }
+        } catch (e) {
+          container.innerHTML = `<div class="log-error">Error loading folders: ${escapeHtml(e.message)}</div>`;
+        }
+      }
+
+
+      async function runOpenRewriteAnalysis() {
+        const rootPath = document.getElementById("rootPath").value;
+        const targetVersionSelect = document.getElementById("targetBootVersion");
+        const targetVersion = targetVersionSelect ? targetVersionSelect.value : "";
+
+        const typeInput = document.querySelector('input[name="migrationType"]:checked');
+        const migrationType = typeInput ? typeInput.value : "spring-boot";
+
+        const container = document.getElementById("migration-report-container");
+        const log = document.getElementById("migration-log");
+        const progressContainer = document.getElementById("migration-progress");
+        const progressBar = document.getElementById("progress-bar");
+        const progressText = document.getElementById("progress-text");
+        const progressEta = document.getElementById("progress-eta");
+        const progressPercent = document.getElementById("progress-percent");
+
+        // Get excluded folders
+        const excluded = ["node_modules", "target", "dist", ".idea", ".vscode"];
+        document
+          .querySelectorAll('#folder-list-container input[type="checkbox"]')
+          .forEach((cb) => {
+            if (!cb.checked) {
+              excluded.push(cb.value);
+            }
+          });
+
+        if (!rootPath) {
+          alert("Please configure the project path first.");
+          return;
+        }
+
+        // Validate target version only if needed
+        if ((migrationType === 'spring-boot' || migrationType === 'java-version') && !targetVersion) {
+          alert("Please select a target version.");
+          return;
+        }
+
+        container.classList.remove("hidden");
+        progressContainer.classList.add("hidden");
+        log.innerHTML =
+          '<div class="log-info">Starting OpenRewrite analysis... This may take a while.</div>';
+        isProcessRunning = true; // Mark process as running
+
+        let totalProjects = 0;
+
+        try {
+          const res = await fetch("/api/analyze-spring", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({
+              RootPath: rootPath,
+              Excluded: excluded,
+              Group: getSelectedGroup(),
+              TargetVersion: targetVersion,
+              MigrationType: migrationType,
+              Maven: getMavenSettings()
+            }),
+          });
+
+          const reader = res.body.getReader();
+          const decoder = new TextDecoder("utf-8");
+          let pending = ""; // Incomplete last line of a chunk, PATCH_SUMMARY lines can be large
+
+          while (true) {
+            const { done, value } = await reader.read();
+            if (done) break;
+
+            pending += decoder.decode(value, { stream: true });
+            const lines = pending.split("\n");
+            pending = lines.pop();
+
+            for (let line of lines) {
+              if (!line.trim()) continue;
+
+              // Handle progress markers
+              if (line.startsWith("PROGRESS_INIT:")) {
+                totalProjects = parseInt(line.split(":")[1]);
+                progressContainer.classList.remove("hidden");
+                progressBar.style.width = "0%";
+                progressText.textContent = `Analyzing... 0/${totalProjects}`;
+                progressEta.textContent = "Estimated: calculating...";
+                progressPercent.textContent = "0%";
+                // Clear repo status list
+                const repoStatusItems = document.getElementById("repo-status-items");
+                if (repoStatusItems) {
+                  repoStatusItems.innerHTML = "";
+                }
+                continue;
               }
-              hiddenContainer.appendChild(groupDiv);
+
+              // Handle individual repo queued (show as "running" with animated progress)
+              if (line.startsWith("REPO_QUEUED:")) {
+                const repoName = line.split(":")[1];
+                const repoStatusItems = document.getElementById("repo-status-items");
+                if (repoStatusItems) {
+                  const repoItem = document.createElement("div");
+                  repoItem.id = `repo-status-${repoName}`;
+                  repoItem.className = "repo-card running";
+                  repoItem.innerHTML = `
+                    <div class="repo-card-header">
+                      <span class="icon">🔄</span>
+                      <span class="name">${repoName}</span>
+                      <span class="status running">analyzing...</span>
+                    </div>
+                    <div class="repo-progress-bar">
+                      <div class="fill running"></div>
+                    </div>
+                  `;
+                  repoStatusItems.appendChild(repoItem);
+                }
+                continue;
+              }
+
+              // Handle individual repo done
+              if (line.startsWith("REPO_DONE:")) {
+                const parts = line.split(":");
+                const repoName = parts[1];
+                const status = parts[2]; // SUCCESS or FAILED
+                const duration = parseFloat(parts[3]);
+
+                const repoItem = document.getElementById(`repo-status-${repoName}`);
+                if (repoItem) {
+                  const isSuccess = status === "SUCCESS";
+                  const icon = isSuccess ? "✅" : "❌";
+                  const statusClass = isSuccess ? "success" : "failed";
+
+                  repoItem.className = `repo-card ${statusClass}`;
+                  repoItem.innerHTML = `
+                    <div class="repo-card-header">
+                      <span class="icon">${icon}</span>
+                      <span class="name">${repoName}</span>
+                      <span class="status ${statusClass}">${duration.toFixed(1)}s</span>
+                    </div>
+                    <div class="repo-progress-bar">
+                      <div class="fill ${statusClass}"></div>
+                    </div>
+                  `;
+                }
+                continue;
+              }
+
+              if (line.startsWith("PROGRESS_UPDATE:")) {
+                const parts = line.split(":");
+                const completed = parseInt(parts[1]);
+                const total = parseInt(parts[2]);
+                const remainingSecs = parseFloat(parts[3]);
+
+                const percent = Math.round((completed / total) * 100);
+                progressBar.style.width = percent + "%";
+                progressText.textContent = `Analyzing... ${completed}/${total}`;
+                progressPercent.textContent = percent + "%";
+
+                if (remainingSecs > 0) {
+                  const mins = Math.floor(remainingSecs / 60);
+                  const secs = Math.round(remainingSecs % 60);
+                  if (mins > 0) {
+                    progressEta.textContent = `Estimated: ~${mins}m ${secs}s remaining`;
+                  } else {
+                    progressEta.textContent = `Estimated: ~${secs}s remaining`;
+                  }
+                } else {
+                  progressEta.textContent = "Finishing...";
+                }
+                continue;
+              }
+
+              if (line.startsWith("PROGRESS_DONE:")) {
+                const totalSecs = parseFloat(line.split(":")[1]);
+                const mins = Math.floor(totalSecs / 60);
+                const secs = Math.round(totalSecs % 60);
+                progressBar.style.width = "100%";
+                progressPercent.textContent = "100%";
+                progressText.textContent = `Completed ${totalProjects}/${totalProjects}`;
+                if (mins > 0) {
+                  progressEta.textContent = `Total time: ${mins}m ${secs}s`;
+                } else {
+                  progressEta.textContent = `Total time: ${secs}s`;
+                }
+                continue;
+              }
+
+              // Typed patch model of a repo with changes
+              if (line.startsWith("PATCH_SUMMARY:")) {
+                try {
+                  log.insertAdjacentHTML("beforeend", renderPatchSummary(JSON.parse(line.slice("PATCH_SUMMARY:".length))));
+                } catch (e) {
+                  log.innerHTML += `<div class="log-error">Could not read the patch summary: ${escapeHtml(e.message)}</div>`;
+                }
+                log.scrollTop = log.scrollHeight;
+                continue;
+              }
+
+              // Regular log output - escape HTML
+              const formattedLine = line
+                .replace(/</g, "&lt;")
+                .replace(/>/g, "&gt;");
+
+              // Color coding
+              let cssClass = "log-info";
+              if (line.includes("✗") || line.toLowerCase().includes("error")) {
+                cssClass = "log-error";
+              } else if (line.includes("✓") || line.includes(">>>")) {
+                cssClass = "log-success";
+              } else if (line.startsWith("📈")) {
+                cssClass = "log-success";
+              } else if (line.includes("Changes detected")) {
+                cssClass = "log-warning";
+              }
+
+              log.innerHTML += `<div class="${cssClass}">${formattedLine}</div>`;
+              log.scrollTop = log.scrollHeight;
             }
+          }
+
+          log.innerHTML +=
+            '<div class="log-success" style="margin-top: 20px; border-top: 1px solid #444; padding-top: 10px;">--- Analysis Complete ---</div>';
+          isProcessRunning = false; // Mark process as complete
+          showToast('Analysis complete', 'Migration analysis has finished successfully.', 'success', 4000);
+        } catch (e) {
+          log.innerHTML += `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
+          progressContainer.classList.add("hidden");
+          isProcessRunning = false; // Mark process as complete
+          showToast('Error', `Analysis failed: ${e.message}`, 'error');
+        }
+      }
+
+      const patchCategoryTitles = {
+        annotations: "🔄 Annotation Updates",
+        imports: "📦 Import Changes",
+        modernization: "🛠️ Code Modernization",
+        configuration: "⚙️ Configuration Changes",
+        removal: "🗑️ Deprecated Code Removal",
+      };
+
+      // Renders the patch model of one repo: recognized changes by category, the diff per file and the raw patch download
+      function renderPatchSummary(event) {
+        const summary = event.summary;
+        const changes = summary.changes || [];
+        const byCategory = {};
+        changes.forEach((c) => (byCategory[c.category] = byCategory[c.category] || []).push(c));
+
+        const categories = Object.keys(patchCategoryTitles).filter((c) => byCategory[c]);
+        const filter = categories.length > 1
+          ? `<select onchange="filterPatchCategories(this)" aria-label="Filter changes by category" style="margin-left:10px;">
+              <option value="">All categories</option>
+              ${categories.map((c) => `<option value="${c}">${patchCategoryTitles[c]}</option>`).join("")}
+            </select>`
+          : "";
+
+        const sections = categories.map((category) => `
+          <div class="summary-section" data-category="${category}" style="margin-top:20px;">
+            <h3 style="color:#a6e3a1; margin:0 0 10px 0;">${patchCategoryTitles[category]} <span style="background:#45475a; padding:2px 8px; border-radius:10px; font-size:0.8em;">${byCategory[category].length}</span></h3>
+            <table style="width:100%; border-collapse:collapse; font-size:0.9em;">
+              ${byCategory[category].map((c) => `
+                <tr style="border-bottom:1px solid #313244;" title="${escapeHtml([c.before, c.after].filter((x) => x).join("\n→ "))}">
+                  <td style="padding:6px 10px; color:#f9e2af; white-space:nowrap; width:1%;" title="${escapeHtml(c.file)}">${escapeHtml(c.file.split("/").pop())}</td>
+                  <td style="padding:6px 10px; color:#cdd6f4;">${escapeHtml(c.description)}</td>
+                </tr>`).join("")}
+            </table>
+          </div>`).join("");
+
+        const uncategorized = changes.length === 0 ? `
+          <div class="summary-section" style="margin-top:20px; padding:15px; background:#313244; border-radius:8px;">
+            <p style="margin:0; color:#f9e2af;">ℹ️ Changes detected but could not be automatically categorized.</p>
+            <p style="margin:5px 0 0 0; color:#a6adc8;">Review the diff of each file below.</p>
+          </div>` : "";
+
+        const files = summary.files.map((f) => `
+          <details style="padding: 4px 0; border-bottom: 1px solid #313244;">
+            <summary style="cursor: pointer;">${escapeHtml(f.path)}
+              <span style="font-size:0.85em;"><span class="log-success">+${f.additions}</span> <span class="log-error">-${f.deletions}</span>${f.status !== "modified" ? ` · ${escapeHtml(f.status)}` : ""}</span></summary>
+            <pre class="diff-preview">${renderDiff(patchFileDiff(f))}</pre>
+          </details>`).join("");
+
+        return `<div class="migration-summary">
+          <h2 style="margin:0 0 15px 0; color:#cdd6f4; border-bottom:2px solid #89b4fa; padding-bottom:10px;">📋 Migration Summary: ${escapeHtml(event.repoName)}
+            <a class="btn btn-secondary" style="float:right; padding:4px 10px; font-size:0.6em;" href="/api/analysis-patch?repoPath=${encodeURIComponent(event.repoPath)}" download>⬇️ Patch</a></h2>
+          ${event.delta ? `<p style="margin:0 0 10px 0; color:#a6e3a1;">📈 Since the analysis of ${escapeHtml(event.delta.since.slice(0, 10))}: ${event.delta.filesFixed} files fixed, ${event.delta.filesAdded} newly affected, ${event.delta.findingsFixed} findings resolved, ${event.delta.findingsNew} new</p>` : ""}
+          <div class="summary-section"><h3 style="color:#89b4fa; margin:15px 0 10px 0;">📁 Files affected: ${summary.files.length}
+            <span style="font-size:0.8em; color:#a6adc8;">(+${summary.additions} -${summary.deletions})</span>${filter}</h3>
+            ${files}
+          </div>
+          ${sections}${uncategorized}
+          <div style="margin-top:20px; padding:12px; background:#1e1e2e; border-left:3px solid #89b4fa; border-radius:4px;">
+            <p style="margin:0; color:#89b4fa;">💡 <strong>Tip:</strong> These are recommended changes for your upgrade. Review each change before applying.</p>
+          </div>
+        </div>`;
+      }
+
+      // Rebuilds the unified diff of a file of the patch model for renderDiff
+      function patchFileDiff(file) {
+        const marker = { added: "+", removed: "-", context: " " };
+        return file.hunks.map((h) =>
+          `@@ -${h.oldStart},${h.oldLines} +${h.newStart},${h.newLines} @@ ${h.header}\n` +
+          h.lines.map((l) => marker[l.kind] + l.text).join("\n")).join("\n");
+      }
+
+      function filterPatchCategories(select) {
+        select.closest(".migration-summary").querySelectorAll("[data-category]").forEach((section) => {
+          section.classList.toggle("hidden", select.value !== "" && section.dataset.category !== select.value);
+        });
+      }
+
+      // ==================== MAINTENANCE TAB ====================
+
+      function getExcludedProjects() {
+        const excluded = ["node_modules", "target", "dist", ".idea", ".vscode"];
+        document.querySelectorAll('#folder-list-container input[type="checkbox"]').forEach((cb) => {
+          if (!cb.checked) {
+            excluded.push(cb.value);
+          }
+        });
+        return excluded;
+      }
+
+      function updateRemoteProvider() {
+        const isGitLab = document.getElementById("remote-provider").value === "gitlab";
+        document.getElementById("remote-gitlab-url").classList.toggle("hidden", !isGitLab);
+        document.getElementById("remote-org").placeholder = isGitLab ? "GitLab group (e.g. acme/backend)" : "GitHub organization";
+        document.getElementById("remote-repos-list").innerHTML = "";
+      }
+
+      function getRemoteRequest() {
+        const org = document.getElementById("remote-org").value.trim();
+        return {
+          provider: document.getElementById("remote-provider").value,
+          org: org,
+          group: org,
+          baseUrl: document.getElementById("remote-gitlab-url").value.trim(),
+          token: document.getElementById("remote-token").value.trim(),
+          update: document.getElementById("remote-update").checked,
+          rootPath: document.getElementById("rootPath")?.value || "",
+          filter: {
+            topic: document.getElementById("remote-topic").value.trim(),
+            language: document.getElementById("remote-language").value.trim(),
+            includeArchived: document.getElementById("remote-archived").checked,
+          },
+        };
+      }
+
+      async function loadRemoteRepos() {
+        const req = getRemoteRequest();
+        const list = document.getElementById("remote-repos-list");
+        if (!req.org) {
+          showToast('Error', 'Please enter an organization or group.', 'error');
+          return;
+        }
+
+        list.innerHTML = '<div class="hint">Loading...</div>';
+        try {
+          const res = await fetch(`/api/${req.provider}/repos`, {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify(req),
           });
+          if (!res.ok) throw new Error(await res.text());
+          const repos = (await res.json()) || [];
 
-          // Add "Show More" button if there are hidden items
-          if (versions.length > initialShowCount) {
-            showMoreBtn = document.createElement("button");
-            showMoreBtn.className = "btn btn-secondary";
-            showMoreBtn.style.width = "100%";
-            showMoreBtn.style.marginTop = "10px";
-            showMoreBtn.innerHTML = `📋 Show older versions (+${
-              versions.length - initialShowCount
-            })`;
-            showMoreBtn.onclick = () => {
-              const hidden = document.getElementById("hidden-versions");
-              if (hidden.style.display === "none") {
-                hidden.style.display = "block";
-                showMoreBtn.innerHTML = "📋 Hide older versions";
-              } else {
-                hidden.style.display = "none";
-                showMoreBtn.innerHTML = `📋 Show older versions (+${
-                  versions.length - initialShowCount
-                })`;
+          if (repos.length === 0) {
+            list.innerHTML = '<div class="hint">No repositories match the filter.</div>';
+            return;
+          }
+
+          list.innerHTML = repos.map(repo => `
+            <label style="display: flex; align-items: center; gap: 8px; padding: 4px 0; border-bottom: 1px solid var(--border-color); font-weight: normal;">
+              <input type="checkbox" class="remote-repo-cb" value="${repo.name}" ${repo.cloned ? 'disabled' : 'checked'} style="width: auto;" />
+              <span style="flex: 1;">${repo.name}${repo.archived ? ' 🗄️' : ''}</span>
+              <span style="color: #9ca0b0; font-size: 0.85em;">${repo.language || ''}</span>
+              <span style="font-size: 0.85em; color: ${repo.cloned ? '#4caf50' : '#9ca0b0'};">${repo.cloned ? 'cloned' : 'missing'}</span>
+            </label>
+          `).join('');
+        } catch (e) {
+          list.innerHTML = `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
+        }
+      }
+
+      async function cloneRemoteRepos() {
+        const req = getRemoteRequest();
+        if (!req.org || !req.rootPath) {
+          showToast('Error', 'Please enter an organization or group and configure a root path.', 'error');
+          return;
+        }
+        req.repos = Array.from(document.querySelectorAll(".remote-repo-cb:checked")).map(cb => cb.value);
+
+        const syncLog = document.getElementById("sync-log");
+        syncLog.classList.remove("hidden");
+        syncLog.innerHTML = "";
+
+        try {
+          const response = await fetch(`/api/${req.provider}/clone`, {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify(req),
+          });
+          if (!response.ok) throw new Error(await response.text());
+
+          const reader = response.body.getReader();
+          const decoder = new TextDecoder();
+          while (true) {
+            const { done, value } = await reader.read();
+            if (done) break;
+            for (const line of decoder.decode(value, { stream: true }).split("\n")) {
+              if (!line.trim() || line.startsWith("CLONE_INIT:")) continue;
+              if (line.startsWith("CLONE_COMPLETE:")) {
+                showToast('Clone Complete', `${line.split(":")[1]} repositories cloned.`, 'success');
+                continue;
               }
-            };
-            container.appendChild(showMoreBtn);
+              const div = document.createElement("div");
+              div.style.color = line.includes("[ERROR]") ? "#f38ba8" : (line.includes("✓") ? "#a6e3a1" : "#9ca0b0");
+              div.textContent = line;
+              syncLog.appendChild(div);
+              syncLog.scrollTop = syncLog.scrollHeight;
+            }
           }
+          loadRemoteRepos();
+          loadBranchInfo();
+        } catch (e) {
+          showToast('Error', `Clone failed: ${e.message}`, 'error');
+        }
+      }
 
-          // Also populate the dropdown if we are in Spring Boot mode
-          const typeInput = document.querySelector('input[name="migrationType"]:checked');
-          if (typeInput && typeInput.value === 'spring-boot') {
-              populateSpringVersions(versions);
+      async function loadMergeRequests() {
+        const req = getRemoteRequest();
+        const list = document.getElementById("mr-list");
+        if (!req.group) {
+          showToast('Error', 'Please enter a GitLab group in the Maintenance tab first.', 'error');
+          return;
+        }
+        req.sourceBranch = document.getElementById("mr-source-branch").value.trim();
+
+        const statusIcons = { success: '✅', failed: '❌', running: '🔄', pending: '⏳', canceled: '⛔', skipped: '⏭️' };
+        list.innerHTML = '<div class="hint">Loading...</div>';
+        try {
+          const res = await fetch("/api/gitlab/merge-requests", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify(req),
+          });
+          if (!res.ok) throw new Error(await res.text());
+          const mrs = (await res.json()) || [];
+
+          if (mrs.length === 0) {
+            list.innerHTML = '<div class="hint">No open merge requests.</div>';
+            return;
           }
 
+          list.innerHTML = mrs.map(mr => `
+            <div style="display: flex; align-items: center; gap: 8px; padding: 4px 0; border-bottom: 1px solid var(--border-color);">
+              <span title="Pipeline: ${mr.pipelineStatus || 'none'}">${statusIcons[mr.pipelineStatus] || '➖'}</span>
+              <a href="${mr.webUrl}" target="_blank" style="flex: 1; color: var(--accent-color);">${mr.title}</a>
+              <span style="color: #9ca0b0; font-size: 0.85em;">${mr.sourceBranch} → ${mr.targetBranch}</span>
+              <span style="color: #9ca0b0; font-size: 0.85em;">${new Date(mr.createdAt).toLocaleDateString()}</span>
+            </div>
+          `).join('');
         } catch (e) {
-          container.innerHTML = `<div class="log-error">Error: ${e.message}</div>`;
+          list.innerHTML = `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
         }
       }
 
-      function populateSpringVersions(versions) {
-        const select = document.getElementById("targetBootVersion");
-        if (!select) return;
-        select.innerHTML = '<option value="">Select Version...</option>';
+      async function loadMavenUpdates() {
+        const rootPath = document.getElementById("rootPath")?.value;
+        if (!rootPath) {
+          showToast('Error', 'Please configure a root path in Project Setup first.', 'error');
+          return;
+        }
+        const btn = document.getElementById("maven-updates-btn");
+        const list = document.getElementById("maven-updates-list");
+        btn.disabled = true;
+        list.innerHTML = '<div class="hint">Analyzing (first run per repo can take a while)...</div>';
 
-        versions.forEach((v) => {
-            // Add the branch (Major.Minor) as value - OpenRewrite only has recipes for minor versions
-            if (v.Versions && v.Versions.length > 0) {
-                const opt = document.createElement("option");
-                opt.value = v.Branch; // Use Major.Minor (e.g., "3.5") instead of full version
-                opt.innerText = `Spring Boot ${v.Branch} (latest: ${v.Versions[0]})`;
-                select.appendChild(opt);
+        const reports = [];
+        try {
+          const res = await fetch("/api/outdated-maven", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({ rootPath, excluded: getExcludedProjects(), group: getSelectedGroup(), maven: getMavenSettings() }),
+          });
+          if (!res.ok) throw new Error(await res.text());
+
+          const reader = res.body.getReader();
+          const decoder = new TextDecoder();
+          let buffer = '';
+          while (true) {
+            const { done, value } = await reader.read();
+            if (done) break;
+            buffer += decoder.decode(value, { stream: true });
+            const lines = buffer.split("\n");
+            buffer = lines.pop();
+            for (const line of lines) {
+              if (line.startsWith("REPO_START:")) {
+                list.innerHTML = `<div class="hint">Analyzing ${escapeHtml(line.substring(11))}...</div>`;
+              } else if (line.startsWith("REPO_RESULT:")) {
+                reports.push(JSON.parse(line.substring(12)));
+              }
             }
-        });
+          }
+
+          if (reports.length === 0) {
+            list.innerHTML = '<div class="hint">No Maven projects found.</div>';
+            return;
+          }
+          list.innerHTML = reports.map(r => {
+            if (r.error) {
+              return `<div style="padding: 4px 0;"><strong>${escapeHtml(r.repoName)}</strong> <span class="log-error">${escapeHtml(r.error)}</span></div>`;
+            }
+            const updates = r.updates || [];
+            const rows = updates.map(u => `
+              <tr>
+                <td>${u.kind === 'plugin' ? '🔌' : '📦'} ${escapeHtml(u.groupId)}:<strong>${escapeHtml(u.artifactId)}</strong></td>
+                <td>${escapeHtml(u.current)}</td>
+                <td style="color: #a6e3a1;">${escapeHtml(u.latest)}</td>
+                <td style="color: #9ca0b0;">${escapeHtml(u.section || 'Plugins')}</td>
+              </tr>`).join('');
+            return `<details style="padding: 4px 0; border-bottom: 1px solid var(--border-color);">
+              <summary style="cursor: pointer;"><strong>${escapeHtml(r.repoName)}</strong>
+                <span style="color: #9ca0b0; font-size: 0.85em;">${updates.filter(u => u.kind === 'dependency').length} dependencies, ${updates.filter(u => u.kind === 'plugin').length} plugins outdated</span></summary>
+              ${updates.length === 0 ? '<div class="hint">Everything up to date.</div>' : `<table class="data-table" style="margin-top: 6px;"><thead><tr><th>Artifact</th><th>Current</th><th>Latest</th><th>Section</th></tr></thead><tbody>${rows}</tbody></table>`}
+            </details>`;
+          }).join('');
+        } catch (e) {
+          list.innerHTML = `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
+        } finally {
+          btn.disabled = false;
+        }
       }
 
-      function updateMigrationOptions() {
-        const typeInput = document.querySelector('input[name="migrationType"]:checked');
-        if (!typeInput) return;
-        const type = typeInput.value;
+      const jakartaStatusLabels = {
+        replace: '🔁 Replace',
+        upgrade: '⬆️ Upgrade',
+        blocked: '⛔ Blocked',
+        unknown: '❔ Unknown',
+      };
 
-        const title = document.getElementById('migration-options-title');
-        const select = document.getElementById('targetBootVersion');
-        const hint = document.getElementById('migration-hint');
-        const card = document.getElementById('migration-options-card');
-        const container = select ? select.parentElement : null;
+      async function checkJakartaReadiness() {
+        const rootPath = document.getElementById("rootPath")?.value;
+        if (!rootPath) {
+          showToast('Error', 'Please configure a root path in Project Setup first.', 'error');
+          return;
+        }
+        const btn = document.getElementById("jakarta-readiness-btn");
+        const list = document.getElementById("jakarta-readiness-list");
+        btn.disabled = true;
+        list.innerHTML = '<div class="hint">Resolving dependency trees...</div>';
 
-        if (!select) return;
+        const reports = [];
+        try {
+          const res = await fetch("/api/jakarta-readiness", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({ rootPath, excluded: getExcludedProjects(), group: getSelectedGroup(), maven: getMavenSettings() }),
+          });
+          if (!res.ok) throw new Error(await res.text());
 
-        // Reset
-        select.innerHTML = '';
-        select.style.display = 'block';
-        if (container) container.style.justifyContent = '';
+          const reader = res.body.getReader();
+          const decoder = new TextDecoder();
+          let buffer = '';
+          while (true) {
+            const { done, value } = await reader.read();
+            if (done) break;
+            buffer += decoder.decode(value, { stream: true });
+            const lines = buffer.split("\n");
+            buffer = lines.pop();
+            for (const line of lines) {
+              if (line.startsWith("REPO_START:")) {
+                list.innerHTML = `<div class="hint">Checking ${escapeHtml(line.substring(11))}...</div>`;
+              } else if (line.startsWith("REPO_RESULT:")) {
+                reports.push(JSON.parse(line.substring(12)));
+              }
+            }
+          }
 
-        if (type === 'spring-boot') {
-            title.innerText = 'Target Spring Boot Version';
-            hint.innerText = 'Runs OpenRewrite to upgrade Spring Boot version.';
-            if (window.springVersionsCache) {
-                 populateSpringVersions(window.springVersionsCache);
-            } else {
-                 loadSpringVersions();
+          if (reports.length === 0) {
+            list.innerHTML = '<div class="hint">No Maven projects found.</div>';
+            return;
+          }
+          list.innerHTML = reports.map(r => {
+            if (r.error) {
+              return `<div style="padding: 4px 0;"><strong>${escapeHtml(r.repoName)}</strong> <span class="log-error">${escapeHtml(r.error)}</span></div>`;
             }
-        } else if (type === 'java-version') {
-            title.innerText = 'Target Java Version';
-            hint.innerText = 'Runs OpenRewrite to upgrade Java version.';
-            // LTS Versions: 25, 21, 17, 11, 8
-            const versions = ['25', '21', '17', '11', '8'];
-            versions.forEach(v => {
-                const opt = document.createElement("option");
-                opt.value = v;
-                opt.innerText = 'Java ' + v + (v === '25' ? ' (Latest LTS)' : '');
-                select.appendChild(opt);
-            });
-        } else if (type === 'jakarta-ee') {
-            title.innerText = 'Jakarta EE Migration';
-            hint.innerText = 'Runs OpenRewrite to migrate from javax to jakarta namespace.';
-            select.style.display = 'none';
-            if (container) container.style.justifyContent = 'flex-end';
-        } else if (type === 'quarkus') {
-            title.innerText = 'Quarkus Migration';
-            hint.innerText = 'Runs OpenRewrite to migrate Quarkus 1.x to 2.x.';
-            select.style.display = 'none';
-            if (container) container.style.justifyContent = 'flex-end';
+            const findings = [...(r.blockers || []), ...(r.actions || [])];
+            const rows = findings.map(f => `
+              <tr>
+                <td>${jakartaStatusLabels[f.status] || escapeHtml(f.status)}</td>
+                <td><strong>${escapeHtml(f.artifact)}</strong>:${escapeHtml(f.version)}${f.via ? `<div style="color: #9ca0b0; font-size: 0.85em;">via ${escapeHtml(f.via)}</div>` : ''}</td>
+                <td style="color: #9ca0b0;">${f.apis.map(escapeHtml).join('<br>')}</td>
+                <td>${escapeHtml(f.reason)}</td>
+              </tr>`).join('');
+            const status = r.ready
+              ? `<span class="status-good">✅ Ready</span>`
+              : `<span class="status-bad">⛔ ${r.blockers.length} blocker${r.blockers.length === 1 ? '' : 's'}</span>`;
+            return `<details style="padding: 4px 0; border-bottom: 1px solid var(--border-color);" ${r.ready ? '' : 'open'}>
+              <summary style="cursor: pointer;"><strong>${escapeHtml(r.repoName)}</strong> ${status}
+                <span style="color: #9ca0b0; font-size: 0.85em;">${(r.actions || []).length} to replace or upgrade</span></summary>
+              ${findings.length === 0 ? '<div class="hint">No javax.* dependencies found.</div>' : `<table class="data-table" style="margin-top: 6px;"><thead><tr><th>Status</th><th>Artifact</th><th>javax APIs</th><th>Details</th></tr></thead><tbody>${rows}</tbody></table>`}
+            </details>`;
+          }).join('');
+        } catch (e) {
+          list.innerHTML = `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
+        } finally {
+          btn.disabled = false;
         }
       }
 
-      // Track if OpenRewrite versions have been loaded
-      let openRewriteVersionsLoaded = false;
-
-      async function checkOpenRewriteVersions() {
-        // Skip if already loaded
-        if (openRewriteVersionsLoaded) {
+      async function importPlatformFindings() {
+        const rootPath = document.getElementById("rootPath")?.value;
+        if (!rootPath) {
+          showToast('Error', 'Please configure a root path in Project Setup first.', 'error');
           return;
         }
 
-        const container = document.getElementById("openrewrite-versions");
+        const btn = document.getElementById("findings-import-btn");
+        const list = document.getElementById("findings-list");
+        btn.disabled = true;
+        list.innerHTML = '<div class="hint">Importing...</div>';
+        try {
+          const res = await fetch("/api/findings/import", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({
+              rootPath,
+              excluded: getExcludedProjects(),
+              group: getSelectedGroup(),
+              provider: document.getElementById("findings-provider").value,
+              token: document.getElementById("findings-token").value,
+              baseUrl: document.getElementById("findings-gitlab-url").value.trim(),
+            }),
+          });
+          if (!res.ok) throw new Error(await res.text());
+          const text = await res.text();
+          const errors = text.split('\n').filter(l => l.includes('[ERROR]'));
+          const done = text.match(/IMPORT_COMPLETE:(\d+)/);
+          showToast('Import finished', `${done ? done[1] : 0} repos imported${errors.length ? `, ${errors.length} errors` : ''}.`, errors.length ? 'warning' : 'success');
+          await loadConsolidatedFindings();
+          if (errors.length) {
+            list.insertAdjacentHTML('afterbegin', errors.map(e => `<div class="log-error">${escapeHtml(e.trim())}</div>`).join(''));
+          }
+        } catch (e) {
+          list.innerHTML = `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
+        } finally {
+          btn.disabled = false;
+        }
+      }
 
+      async function loadConsolidatedFindings() {
+        const rootPath = document.getElementById("rootPath")?.value || '';
+        const list = document.getElementById("findings-list");
+        list.innerHTML = '<div class="hint">Loading...</div>';
         try {
-          const res = await fetch("/api/openrewrite-versions");
-          if (!res.ok) throw new Error(res.statusText);
-          const data = await res.json();
+          const res = await fetch(`/api/findings?rootPath=${encodeURIComponent(rootPath)}`);
+          if (!res.ok) throw new Error(await res.text());
+          const repos = ((await res.json()) || []).filter(r => (r.findings || []).length > 0);
+          if (repos.length === 0) {
+            list.innerHTML = '<div class="hint">No stored findings. Run a scan or import platform alerts first.</div>';
+            return;
+          }
 
-          openRewriteVersionsLoaded = true;
-          container.innerHTML = "";
+          list.innerHTML = repos.map(r => `
+            <div style="padding: 8px 0; border-bottom: 1px solid var(--border-color);">
+              <strong>${escapeHtml(r.repoName)}</strong>
+              <span style="color: #9ca0b0; font-size: 0.85em;">${r.findings.length} findings · ${Object.keys(r.sources).join(', ')}</span>
+              ${r.findings.map(f => `
+                <div style="display: flex; gap: 8px; align-items: center; font-size: 0.85em; margin-top: 4px;">
+                  ${getSeverityBadge(f.severity)}
+                  ${f.url ? `<a href="${escapeHtml(f.url)}" target="_blank" rel="noopener" style="color: #89b4fa;">${escapeHtml(f.cve)}</a>` : escapeHtml(f.cve)}
+                  <span>${escapeHtml(f.package)}${f.version ? ' @ ' + escapeHtml(f.version) : ''}</span>
+                  ${f.fixedIn ? `<span style="color: #a6e3a1;">→ ${escapeHtml(f.fixedIn)}</span>` : ''}
+                  <span style="color: #9ca0b0; margin-left: auto;">${(f.sources || []).join(' + ')}</span>
+                </div>`).join('')}
+            </div>`).join('');
+        } catch (e) {
+          list.innerHTML = `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
+        }
+      }
 
-          data.forEach((item) => {
-            const card = document.createElement("div");
-            card.style.cssText =
-              "flex: 1; min-width: 200px; padding: 12px; background: var(--bg-color); border-radius: 6px; border: 1px solid var(--border-color);";
+      async function auditGitignore() {
+        const rootPath = document.getElementById("rootPath")?.value;
+        if (!rootPath) {
+          showToast('Error', 'Please configure a root path in Project Setup first.', 'error');
+          return;
+        }
 
-            const hasUpdate = item.updateAvailable;
-            const statusColor = hasUpdate ? "#f9e2af" : "#a6e3a1";
-            const statusIcon = hasUpdate ? "⚠️" : "✅";
-            const statusText = hasUpdate ? "Update available" : "Up to date";
+        const list = document.getElementById("gitignore-list");
+        list.innerHTML = '<div class="hint">Analyzing...</div>';
+        try {
+          const res = await fetch("/api/gitignore-audit", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({ rootPath, excluded: getExcludedProjects(), group: getSelectedGroup() }),
+          });
+          if (!res.ok) throw new Error(await res.text());
+          const reports = ((await res.json()) || []).filter(r => r.error || (r.missingRules || []).length || (r.offenders || []).length);
 
-            card.innerHTML = `
-              <div style="font-weight: 500; margin-bottom: 8px; color: var(--text-color);">
-                ${item.component}
-              </div>
-              <div style="display: flex; justify-content: space-between; font-size: 0.9em; margin-bottom: 4px;">
-                <span style="color: #6c7086;">Using:</span>
-                <span style="color: var(--text-color); font-family: monospace;">${
-                  item.currentVersion
-                }</span>
-              </div>
-              <div style="display: flex; justify-content: space-between; font-size: 0.9em; margin-bottom: 8px;">
-                <span style="color: #6c7086;">Latest:</span>
-                <span style="color: ${
-                  hasUpdate ? "#a6e3a1" : "var(--text-color)"
-                }; font-family: monospace;">${item.latestVersion}</span>
-              </div>
-              <div style="display: flex; justify-content: space-between; align-items: center;">
-                <span style="color: ${statusColor}; font-size: 0.85em;">${statusIcon} ${statusText}</span>
-                <a href="${
-                  item.mavenCentralUrl
-                }" target="_blank" style="color: #89b4fa; font-size: 0.85em; text-decoration: none;">
-                  Maven Central ↗
-                </a>
-              </div>
-            `;
+          if (reports.length === 0) {
+            list.innerHTML = '<div class="hint">All .gitignore files look good.</div>';
+            return;
+          }
 
-            container.appendChild(card);
+          list.innerHTML = reports.map(r => {
+            const offenders = r.offenders || [];
+            const details = r.error
+              ? `<div class="log-error">${escapeHtml(r.error)}</div>`
+              : `<div style="color: #9ca0b0; font-size: 0.85em;">
+                   ${(r.missingRules || []).length ? `Missing: ${r.missingRules.join(', ')}` : ''}
+                   ${offenders.length ? `<br>Tracked: ${offenders.slice(0, 5).map(o => o.path).join(', ')}${offenders.length > 5 ? ` (+${offenders.length - 5} more)` : ''}` : ''}
+                 </div>`;
+            return `
+              <label style="display: flex; align-items: flex-start; gap: 8px; padding: 6px 0; border-bottom: 1px solid var(--border-color); font-weight: normal;">
+                <input type="checkbox" class="gitignore-repo-cb" value="${r.repoName}" ${r.error ? 'disabled' : 'checked'} style="width: auto; margin-top: 3px;" />
+                <div style="flex: 1;"><strong>${r.repoName}</strong> <span style="color: #9ca0b0; font-size: 0.85em;">${r.projectType || ''}</span>${details}</div>
+              </label>`;
+          }).join('');
+        } catch (e) {
+          list.innerHTML = `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
+        }
+      }
+
+      async function fixGitignore() {
+        const rootPath = document.getElementById("rootPath")?.value;
+        const repos = Array.from(document.querySelectorAll(".gitignore-repo-cb:checked")).map(cb => cb.value);
+        if (!rootPath || repos.length === 0) {
+          showToast('Error', 'Please run the analysis and select at least one repository.', 'error');
+          return;
+        }
+
+        const syncLog = document.getElementById("sync-log");
+        syncLog.classList.remove("hidden");
+        syncLog.innerHTML = "";
+
+        try {
+          const response = await fetch("/api/gitignore-fix", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({
+              rootPath,
+              excluded: getExcludedProjects(),
+              group: getSelectedGroup(),
+              repos,
+              branch: document.getElementById("gitignore-branch").value.trim(),
+              signing: getSigningSettings(),
+            }),
           });
+          if (!response.ok) throw new Error(await response.text());
+
+          const reader = response.body.getReader();
+          const decoder = new TextDecoder();
+          while (true) {
+            const { done, value } = await reader.read();
+            if (done) break;
+            for (const line of decoder.decode(value, { stream: true }).split("\n")) {
+              if (!line.trim() || line.startsWith("REPO_DONE:")) continue;
+              if (line.startsWith("GITIGNORE_COMPLETE:")) {
+                showToast('.gitignore Fixed', `${line.split(":")[1]} repositories updated.`, 'success');
+                continue;
+              }
+              const div = document.createElement("div");
+              if (line.startsWith("REPO_START:")) {
+                div.style.fontWeight = "bold";
+                div.textContent = line.substring("REPO_START:".length);
+              } else {
+                div.style.color = line.includes("[ERROR]") ? "#f38ba8" : "#9ca0b0";
+                div.textContent = line;
+              }
+              syncLog.appendChild(div);
+              syncLog.scrollTop = syncLog.scrollHeight;
+            }
+          }
+          auditGitignore();
+          loadBranchInfo();
         } catch (e) {
-          container.innerHTML = `<div class="log-error">Error: ${e.message}</div>`;
+          showToast('Error', `.gitignore fix failed: ${e.message}`, 'error');
         }
       }
 
-      async function scanSpringProjects() {
-        const container = document.getElementById("spring-projects-list");
-        const rootPath = document.getElementById("rootPath").value;
+      async function loadGitignoreStandard() {
+        try {
+          const res = await fetch("/api/gitignore-standard");
+          if (!res.ok) throw new Error(await res.text());
+          document.getElementById("gitignore-standard").value = ((await res.json()).rules || []).join("\n");
+        } catch (e) {
+          console.error("Failed to load the standard .gitignore block", e);
+        }
+      }
 
-        // Calculate Excluded Folders from Checkboxes
-        const excluded = ["node_modules", "target", "dist", ".idea", ".vscode"]; // Standard defaults
-        document
-          .querySelectorAll('#folder-list-container input[type="checkbox"]')
-          .forEach((cb) => {
-            if (!cb.checked) {
-              excluded.push(cb.value);
-            }
+      async function saveGitignoreStandard() {
+        try {
+          const res = await fetch("/api/gitignore-standard", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({ rules: document.getElementById("gitignore-standard").value.split("\n") }),
           });
+          if (!res.ok) throw new Error(await res.text());
+          await loadGitignoreStandard();
+          showToast('Saved', 'Standard .gitignore block saved.', 'success');
+        } catch (e) {
+          showToast('Error', `Could not save the block: ${e.message}`, 'error');
+        }
+      }
 
+      async function normalizeGitignore() {
+        const rootPath = document.getElementById("rootPath")?.value;
         if (!rootPath) {
-          alert("Please configure the project path in Project Setup first.");
+          showToast('Error', 'Please configure a root path in Project Setup first.', 'error');
           return;
         }
+        const dryRun = document.getElementById("gitignore-normalize-dry-run").checked;
 
-        container.innerHTML = '<div class="log-info">Scanning...</div>';
+        const syncLog = document.getElementById("sync-log");
+        syncLog.classList.remove("hidden");
+        syncLog.innerHTML = "";
 
         try {
-          const res = await fetch("/api/scan-spring", {
+          const response = await fetch("/api/gitignore-normalize", {
             method: "POST",
             headers: { "Content-Type": "application/json" },
-            body: JSON.stringify({ RootPath: rootPath, Excluded: excluded }),
+            body: JSON.stringify({
+              rootPath,
+              excluded: getExcludedProjects(),
+              group: getSelectedGroup(),
+              branch: document.getElementById("gitignore-branch").value.trim(),
+              signing: getSigningSettings(),
+              dryRun,
+            }),
           });
-          if (!res.ok) throw new Error(res.statusText);
-          const results = await res.json();
+          if (!response.ok) throw new Error(await response.text());
 
-          container.innerHTML = "";
+          const reader = response.body.getReader();
+          const decoder = new TextDecoder();
+          while (true) {
+            const { done, value } = await reader.read();
+            if (done) break;
+            for (const line of decoder.decode(value, { stream: true }).split("\n")) {
+              if (!line.trim() || line.startsWith("REPO_DONE:")) continue;
+              if (line.startsWith("GITIGNORE_NORMALIZE_COMPLETE:")) {
+                showToast('.gitignore Normalized', `${line.split(":")[1]} repositories ${dryRun ? 'would be updated' : 'updated'}.`, 'success');
+                continue;
+              }
+              const div = document.createElement("div");
+              if (line.startsWith("REPO_START:")) {
+                div.style.fontWeight = "bold";
+                div.textContent = line.substring("REPO_START:".length);
+              } else {
+                div.style.color = line.includes("[ERROR]") ? "#f38ba8" : line.includes("[WARN]") ? "#fab387" : "#9ca0b0";
+                div.textContent = line;
+              }
+              syncLog.appendChild(div);
+              syncLog.scrollTop = syncLog.scrollHeight;
+            }
+          }
+          if (!dryRun) loadBranchInfo();
+        } catch (e) {
+          showToast('Error', `.gitignore normalization failed: ${e.message}`, 'error');
+        }
+      }
 
-          // Handle new SpringScanResult structure
-          if (!results || !results.Projects || results.Projects.length === 0) {
-            let html = '<div class="hint">No Spring Boot projects found.</div>';
+      function formatBytes(bytes) {
+        if (bytes < 1024) return `${bytes} B`;
+        const units = ["KiB", "MiB", "GiB", "TiB"];
+        let value = bytes / 1024, unit = 0;
+        while (value >= 1024 && unit < units.length - 1) {
+          value /= 1024;
+          unit++;
+        }
+        return `${value.toFixed(value < 10 ? 1 : 0)} ${units[unit]}`;
+      }
 
-            // Show debug log if available
-            if (results && results.DebugLog && results.DebugLog.length > 0) {
-              html +=
-                '<div style="margin-top:20px; border-top:1px solid var(--border-color); padding-top:10px;">';
-              html += "<h4>Debug Log</h4>";
-              html +=
-                '<div style="font-family:monospace; font-size:0.8em; color:#a6adc8; max-height:200px; overflow-y:auto;">';
-              results.DebugLog.forEach((line) => {
-                html += `<div>${line}</div>`;
-              });
-              html += "</div></div>";
-            }
+      async function loadRepoSizeReport() {
+        const rootPath = document.getElementById("rootPath")?.value;
+        const group = getSelectedGroup();
+        if (!rootPath && !group) {
+          showToast('Error', 'Please configure a root path in Project Setup first.', 'error');
+          return;
+        }
 
-            container.innerHTML = html;
+        const list = document.getElementById("repo-size-list");
+        list.innerHTML = '<div class="hint">Analyzing history, this can take a while for large repositories...</div>';
+        try {
+          const res = await fetch("/api/repo-size-report", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({ rootPath, excluded: getExcludedProjects(), group }),
+          });
+          if (!res.ok) throw new Error(await res.text());
+          const reports = (await res.json()) || [];
+          if (reports.length === 0) {
+            list.innerHTML = '<div class="hint">No repositories found.</div>';
             return;
           }
 
-          // Display found projects
-          results.Projects.forEach((proj) => {
-            const div = document.createElement("div");
-            div.style.padding = "10px";
-            div.style.borderBottom = "1px solid var(--border-color)";
+          list.innerHTML = reports.map(r => {
+            if (r.error) {
+              return `<div style="padding: 6px 0; border-bottom: 1px solid var(--border-color);"><strong>${escapeHtml(r.repoName)}</strong> <span class="log-error">${escapeHtml(r.error)}</span></div>`;
+            }
+            const flags = [r.lfsCandidate ? '<span class="status-badge status-warn">LFS</span>' : '',
+                           r.historyCleanup ? '<span class="status-badge status-bad">History cleanup</span>' : ''].join(' ');
+            const blobs = (r.largestBlobs || []).slice(0, 5).map(b =>
+              `${escapeHtml(b.path || b.hash.substring(0, 10))} (${formatBytes(b.size)}${b.inHead ? '' : ', history only'})`).join(', ');
+            return `
+              <div style="padding: 6px 0; border-bottom: 1px solid var(--border-color);">
+                <strong>${escapeHtml(r.repoName)}</strong>
+                <span style="color: #9ca0b0; font-size: 0.85em;">work tree ${formatBytes(r.workTreeBytes)} · .git ${formatBytes(r.gitDirBytes)}</span> ${flags}
+                ${blobs ? `<div style="color: #9ca0b0; font-size: 0.85em;">Largest: ${blobs}</div>` : ''}
+                ${(r.recommendations || []).map(rec => `<div class="log-warning" style="font-size: 0.85em;">${escapeHtml(rec)}</div>`).join('')}
+              </div>`;
+          }).join('');
+        } catch (e) {
+          list.innerHTML = `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
+        }
+      }
 
-            div.innerHTML = `
-                    <div style="font-weight:bold;">${proj.RepoName}</div>
-                    <div style="font-size:0.9em; color: #a6adc8;">Aktuell: <span style="color: var(--accent-color);">${proj.CurrentVersion}</span></div>
-                `;
-            container.appendChild(div);
+      async function auditLFS() {
+        const rootPath = document.getElementById("rootPath")?.value;
+        const group = getSelectedGroup();
+        if (!rootPath && !group) {
+          showToast('Error', 'Please configure a root path in Project Setup first.', 'error');
+          return;
+        }
+
+        const list = document.getElementById("lfs-list");
+        list.innerHTML = '<div class="hint">Analyzing...</div>';
+        try {
+          const res = await fetch("/api/lfs-audit", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({ rootPath, excluded: getExcludedProjects(), group }),
           });
+          if (!res.ok) throw new Error(await res.text());
+          const audit = await res.json();
+          const reports = (audit.repos || []).filter(r => r.error || r.largeFiles.length > 0);
+          const warning = audit.lfsInstalled ? '' : '<div class="log-warning">git-lfs is not installed, dry runs and migrations are not possible.</div>';
+          if (reports.length === 0) {
+            list.innerHTML = warning + '<div class="hint">No large files stored without LFS.</div>';
+            return;
+          }
+
+          const patterns = new Set();
+          list.innerHTML = warning + reports.map(r => {
+            (r.suggestedPatterns || []).forEach(p => patterns.add(p));
+            const details = r.error
+              ? `<div class="log-error">${escapeHtml(r.error)}</div>`
+              : `<div style="color: #9ca0b0; font-size: 0.85em;">
+                   ${r.largeFiles.slice(0, 5).map(f => `${escapeHtml(f.path)} (${formatBytes(f.size)})`).join(', ')}${r.largeFiles.length > 5 ? ` (+${r.largeFiles.length - 5} more)` : ''}
+                   ${r.usesLfs ? `<br>LFS already tracks: ${r.patterns.map(escapeHtml).join(', ')}` : ''}
+                 </div>`;
+            return `
+              <label style="display: flex; align-items: flex-start; gap: 8px; padding: 6px 0; border-bottom: 1px solid var(--border-color); font-weight: normal;">
+                <input type="checkbox" class="lfs-repo-cb" value="${escapeHtml(r.repoPath)}" ${r.error ? 'disabled' : 'checked'} style="width: auto; margin-top: 3px;" />
+                <div style="flex: 1;"><strong>${escapeHtml(r.repoName)}</strong>${details}</div>
+              </label>`;
+          }).join('');
+          const input = document.getElementById("lfs-dry-run-patterns");
+          if (!input.value.trim()) input.value = [...patterns].join(" ");
         } catch (e) {
-          container.innerHTML = `<div class="log-error">Error scanning: ${e.message}</div>`;
+          list.innerHTML = `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
         }
       }
 
-      // Folder Picker: Call backend API to open native OS dialog
-      async function pickFolder(targetId) {
+      async function lfsDryRun() {
+        const patterns = document.getElementById("lfs-dry-run-patterns").value.split(/\s+/).filter(p => p);
+        const repos = Array.from(document.querySelectorAll(".lfs-repo-cb:checked")).map(cb => cb.value);
+        if (patterns.length === 0 || repos.length === 0) {
+          showToast('Error', 'Please run the analysis, select repositories and enter at least one pattern.', 'error');
+          return;
+        }
+
+        const log = document.getElementById("lfs-log");
+        log.classList.remove("hidden");
+        log.innerHTML = "";
         try {
-          const res = await fetch("/api/pick-folder");
-          if (!res.ok) {
-            const text = await res.text();
-            throw new Error(text);
-          }
-          const data = await res.json();
-          if (data.path) {
-            document.getElementById(targetId).value = data.path;
-            if (targetId === "rootPath") {
-              loadFolders();
-              loadDashboardStats(data.path);
-              // Reset security repos when path changes
-              securityReposLoaded = false;
+          const response = await fetch("/api/lfs-dry-run", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({
+              rootPath: document.getElementById("rootPath")?.value,
+              excluded: getExcludedProjects(),
+              group: getSelectedGroup(),
+              repos,
+              patterns,
+            }),
+          });
+          if (!response.ok) throw new Error(await response.text());
+
+          const reader = response.body.getReader();
+          const decoder = new TextDecoder();
+          while (true) {
+            const { done, value } = await reader.read();
+            if (done) break;
+            for (const line of decoder.decode(value, { stream: true }).split("\n")) {
+              if (!line.trim() || line.startsWith("REPO_DONE:") || line === "LFS_DRY_RUN_COMPLETE") continue;
+              const div = document.createElement("div");
+              if (line.startsWith("REPO_START:")) {
+                div.style.fontWeight = "bold";
+                div.textContent = line.substring("REPO_START:".length);
+              } else {
+                div.style.color = line.includes("[ERROR]") ? "#f38ba8" : "#9ca0b0";
+                div.textContent = line;
+              }
+              log.appendChild(div);
+              log.scrollTop = log.scrollHeight;
             }
           }
         } catch (e) {
-          console.error(e);
-          alert("Error opening dialog: " + e.message);
+          showToast('Error', `LFS dry run failed: ${e.message}`, 'error');
         }
       }
 
-      async function loadFolders() {
-        const rootPath = document.getElementById("rootPath").value;
-        const container = document.getElementById("folder-list-container");
-
-        if (!rootPath) {
-          container.innerHTML = "";
+      // Hands the patterns to the run configuration; the migration runs on the work branch of the next run
+      function useLFSPatternsInRun() {
+        const patterns = document.getElementById("lfs-dry-run-patterns").value.trim();
+        if (!patterns) {
+          showToast('Error', 'Please enter at least one pattern.', 'error');
           return;
         }
+        document.getElementById("lfsPatterns").value = patterns;
+        showToast('Git LFS', 'Patterns set in Project Setup. Select the repositories and start a run to migrate.', 'success');
+        showTab("settings");
+      }
 
-        // Save path immediately to localStorage for better UX
-        const saved = localStorage.getItem("gitHousekeeper_settings");
-        let settings = {};
-        if (saved) {
-            try { settings = JSON.parse(saved); } catch(e) {}
+      // Looks up the newest release of each repo's parent POM and pre-fills the per-repo target versions
+      async function suggestParentVersions() {
+        const rootPath = document.getElementById("rootPath").value;
+        const group = getSelectedGroup();
+        if (!rootPath && !group) {
+          showToast('Error', 'Please specify a project path first.', 'error');
+          return;
         }
-        settings.rootPath = rootPath;
-        localStorage.setItem("gitHousekeeper_settings", JSON.stringify(settings));
-
-        container.innerHTML = '<div class="log-info">Loading folders...</div>';
 
+        const list = document.getElementById("parent-suggestions");
+        list.innerHTML = '<div class="hint">Looking up parent versions...</div>';
         try {
-          const res = await fetch("/api/list-folders", {
+          const res = await fetch("/api/parent-suggestions", {
             method: "POST",
             headers: { "Content-Type": "application/json" },
-            body: JSON.stringify({ Path: rootPath }),
+            body: JSON.stringify({ rootPath, excluded: getExcludedProjects(), group }),
           });
+          if (!res.ok) throw new Error(await res.text());
+          const suggestions = (await res.json()) || [];
+          if (suggestions.length === 0) {
+            list.innerHTML = '<div class="hint">No repository with a parent POM found.</div>';
+            return;
+          }
 
-          if (!res.ok) throw new Error(res.statusText);
-          const data = await res.json();
+          const outdated = suggestions.filter(s => s.outdated).length;
+          list.innerHTML = `
+            <div style="display: flex; align-items: center; gap: 10px; margin-bottom: 6px;">
+              <span class="hint" style="margin: 0; flex: 1;">${outdated} of ${suggestions.length} repositories have a newer parent.</span>
+              <button class="btn btn-secondary" onclick="useLatestParentVersions()" aria-label="Use the latest parent version for all repositories">⬆️ Use Latest for All</button>
+              <button class="btn btn-secondary" onclick="clearParentVersions()" aria-label="Clear the per-repository parent versions">✖ Clear</button>
+            </div>` + suggestions.map(s => `
+            <div style="display: flex; align-items: center; gap: 10px; padding: 4px 0; border-bottom: 1px solid var(--border-color); font-size: 0.9em;">
+              <strong style="flex: 1;">${escapeHtml(s.repoName)}</strong>
+              <span style="font-family: 'Consolas', monospace; color: #9ca0b0; flex: 1;" title="${escapeHtml(s.groupId || '')}">${escapeHtml(s.artifactId || '')}:${escapeHtml(s.current || '?')}</span>
+              ${s.error
+                ? `<span class="log-error" style="width: 200px;">${escapeHtml(s.error)}</span>`
+                : `<input type="text" class="parent-version-input" data-repo="${escapeHtml(s.repoName)}" data-latest="${escapeHtml(s.latest || '')}"
+                    value="${s.outdated ? escapeHtml(s.latest) : ''}" placeholder="${escapeHtml(s.latest || '')}" aria-label="Parent version for ${escapeHtml(s.repoName)}" style="width: 200px;" />`}
+            </div>`).join('');
+        } catch (e) {
+          list.innerHTML = "";
+          showToast('Error', `Parent version lookup failed: ${e.message}`, 'error');
+        }
+      }
 
-          container.innerHTML = "";
+      function useLatestParentVersions() {
+        document.querySelectorAll(".parent-version-input").forEach(input => { input.value = input.dataset.latest; });
+      }
 
-          if (data.Error) {
-            container.innerHTML = `<div class="log-error">${data.Error}</div>`;
-            return;
-          }
+      function clearParentVersions() {
+        document.querySelectorAll(".parent-version-input").forEach(input => { input.value = ""; });
+      }
 
-          if (data.IsRepo) {
-            container.innerHTML =
-              '<div class="hint">None (Current folder is a Git Repository)</div>';
-            return;
-          }
+      // Per-repo parent versions keyed by repo folder name; empty fields use the common parent version
+      function getRepoParentVersions() {
+        const versions = {};
+        document.querySelectorAll(".parent-version-input").forEach(input => {
+          if (input.value.trim()) versions[input.dataset.repo] = input.value.trim();
+        });
+        return versions;
+      }
 
-          if (!data.Folders || data.Folders.length === 0) {
-            container.innerHTML =
-              '<div class="hint">No subfolders found.</div>';
+      async function listTags() {
+        const rootPath = document.getElementById("rootPath")?.value;
+        if (!rootPath) {
+          showToast('Error', 'Please configure a root path in Project Setup first.', 'error');
+          return;
+        }
+
+        const list = document.getElementById("tags-list");
+        list.innerHTML = '<div class="hint">Loading...</div>';
+        try {
+          const res = await fetch("/api/list-tags", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({
+              rootPath,
+              excluded: getExcludedProjects(),
+              group: getSelectedGroup(),
+              tags: {
+                pattern: document.getElementById("tagPattern").value.trim(),
+                prefix: document.getElementById("tagPrefix").value.trim(),
+              },
+            }),
+          });
+          if (!res.ok) throw new Error(await res.text());
+          const repos = (await res.json()) || [];
+          if (repos.length === 0) {
+            list.innerHTML = '<div class="hint">No repositories found.</div>';
             return;
           }
 
-          const ignoreDisplay = [
-            ".git",
-            "node_modules",
-            "target",
-            "dist",
-            ".idea",
-            ".vscode",
-          ];
-
-          data.Folders.forEach((folder) => {
-            if (ignoreDisplay.includes(folder)) return;
-
-            const div = document.createElement("div");
-            div.style.marginBottom = "5px";
+          list.innerHTML = repos.map(r => {
+            const drift = !r.latestTag ? '<span style="color: #9ca0b0;">no tag</span>'
+              : r.commitsSinceTag === 0 ? '<span style="color: #a6e3a1;">HEAD is tagged</span>'
+              : `<span style="color: #f9e2af;">${r.commitsSinceTag} commit${r.commitsSinceTag === 1 ? '' : 's'} since tag</span>`;
+            return `
+              <div style="display: flex; align-items: center; gap: 10px; padding: 4px 0; border-bottom: 1px solid var(--border-color); font-size: 0.9em;">
+                <strong style="flex: 1;">${escapeHtml(r.repoName)}</strong>
+                <span style="color: #9ca0b0;">${escapeHtml(r.branch || '')}</span>
+                <span style="font-family: 'Consolas', monospace; width: 180px;" title="${escapeHtml((r.recentTags || []).join(', '))}">${escapeHtml(r.latestTag || '-')}</span>
+                <span style="width: 150px; text-align: right;">${r.error ? `<span class="log-error">${escapeHtml(r.error)}</span>` : drift}</span>
+              </div>`;
+          }).join('');
+        } catch (e) {
+          list.innerHTML = `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
+        }
+      }
 
-            const checkbox = document.createElement("input");
-            checkbox.type = "checkbox";
-            checkbox.id = "folder_" + folder;
-            checkbox.value = folder;
-            checkbox.checked = true; // Default checked
-            checkbox.style.width = "auto";
-            checkbox.style.marginRight = "10px";
+      async function updateChangelogs() {
+        const rootPath = document.getElementById("rootPath")?.value;
+        const group = getSelectedGroup();
+        if (!rootPath && !group) {
+          showToast('Error', 'Please configure a root path in Project Setup first.', 'error');
+          return;
+        }
+        const dryRun = document.getElementById("changelog-dry-run").checked;
 
-            const label = document.createElement("label");
-            label.htmlFor = "folder_" + folder;
-            label.textContent = folder;
-            label.style.display = "inline";
-            label.style.fontWeight = "normal";
+        const log = document.getElementById("changelog-log");
+        log.classList.remove("hidden");
+        log.innerHTML = "";
 
-            div.appendChild(checkbox);
-            div.appendChild(label);
-            container.appendChild(div);
+        try {
+          const response = await fetch("/api/changelog", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({
+              rootPath,
+              excluded: getExcludedProjects(),
+              group,
+              tags: {
+                pattern: document.getElementById("tagPattern").value.trim(),
+                prefix: document.getElementById("tagPrefix").value.trim(),
+              },
+              branch: document.getElementById("changelog-branch").value.trim(),
+              signing: getSigningSettings(),
+              dryRun,
+            }),
           });
+          if (!response.ok) throw new Error(await response.text());
 
-          if (container.children.length === 0) {
-            container.innerHTML =
-              '<div class="hint">No relevant subfolders found.</div>';
+          const reader = response.body.getReader();
+          const decoder = new TextDecoder();
+          while (true) {
+            const { done, value } = await reader.read();
+            if (done) break;
+            for (const line of decoder.decode(value, { stream: true }).split("\n")) {
+              if (!line.trim() || line.startsWith("REPO_DONE:")) continue;
+              if (line.startsWith("CHANGELOG_COMPLETE:")) {
+                showToast('Changelogs', `${line.split(":")[1]} repositories ${dryRun ? 'have unreleased commits' : 'updated'}.`, 'success');
+                continue;
+              }
+              const div = document.createElement("div");
+              if (line.startsWith("REPO_START:")) {
+                div.style.fontWeight = "bold";
+                div.textContent = line.substring("REPO_START:".length);
+              } else {
+                div.style.color = line.includes("[ERROR]") ? "#f38ba8" : line.includes("[WARN]") ? "#fab387" : "#9ca0b0";
+                div.textContent = line;
+              }
+              log.appendChild(div);
+              log.scrollTop = log.scrollHeight;
+            }
           }
+          if (!dryRun) loadBranchInfo();
         } catch (e) {
-          container.innerHTML = `<div class="log-error">Error loading folders: ${e.message}</div>`;
+          showToast('Error', `Changelog update failed: ${e.message}`, 'error');
         }
       }
 
+      function getReleaseRequest() {
+        return {
+          rootPath: document.getElementById("rootPath")?.value,
+          excluded: getExcludedProjects(),
+          group: getSelectedGroup(),
+          tags: {
+            pattern: document.getElementById("tagPattern").value.trim(),
+            prefix: document.getElementById("tagPrefix").value.trim(),
+          },
+          strategy: document.getElementById("release-strategy").value,
+          changelog: document.getElementById("release-changelog").checked,
+          push: document.getElementById("release-push").checked,
+          signing: getSigningSettings(),
+        };
+      }
 
-      async function runOpenRewriteAnalysis() {
-        const rootPath = document.getElementById("rootPath").value;
-        const targetVersionSelect = document.getElementById("targetBootVersion");
-        const targetVersion = targetVersionSelect ? targetVersionSelect.value : "";
-
-        const typeInput = document.querySelector('input[name="migrationType"]:checked');
-        const migrationType = typeInput ? typeInput.value : "spring-boot";
-
-        const container = document.getElementById("migration-report-container");
-        const log = document.getElementById("migration-log");
-        const progressContainer = document.getElementById("migration-progress");
-        const progressBar = document.getElementById("progress-bar");
-        const progressText = document.getElementById("progress-text");
-        const progressEta = document.getElementById("progress-eta");
-        const progressPercent = document.getElementById("progress-percent");
+      async function planRelease() {
+        const req = getReleaseRequest();
+        if (!req.rootPath && !req.group) {
+          showToast('Error', 'Please configure a root path in Project Setup first.', 'error');
+          return;
+        }
 
-        // Get excluded folders
-        const excluded = ["node_modules", "target", "dist", ".idea", ".vscode"];
-        document
-          .querySelectorAll('#folder-list-container input[type="checkbox"]')
-          .forEach((cb) => {
-            if (!cb.checked) {
-              excluded.push(cb.value);
-            }
+        const list = document.getElementById("release-plan");
+        list.innerHTML = '<div class="hint">Loading...</div>';
+        try {
+          const res = await fetch("/api/release/plan", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify(req),
           });
+          if (!res.ok) throw new Error(await res.text());
+          const plans = (await res.json()) || [];
+          if (plans.length === 0) {
+            list.innerHTML = '<div class="hint">No repositories found.</div>';
+            return;
+          }
 
-        if (!rootPath) {
-          alert("Please configure the project path first.");
-          return;
+          list.innerHTML = plans.map(p => {
+            const files = (p.files || []).map(f => `${f.file} ${f.version}`).join(', ');
+            return `
+              <div style="display: flex; align-items: center; gap: 10px; padding: 4px 0; border-bottom: 1px solid var(--border-color); font-size: 0.9em;">
+                <input type="checkbox" class="release-repo-cb" data-repo="${escapeHtml(p.repoPath)}" ${p.error || p.commits === 0 ? '' : 'checked'} style="width: auto;" />
+                <strong style="flex: 1;">${escapeHtml(p.repoName)}</strong>
+                <span style="color: #9ca0b0;">${escapeHtml(p.branch || '')}</span>
+                <span style="font-family: 'Consolas', monospace; width: 120px;" title="${escapeHtml(files)}">${escapeHtml(p.latestTag || '-')}</span>
+                ${p.error ? `<span class="log-error" style="width: 330px; text-align: right;">${escapeHtml(p.error)}</span>` : `
+                <span style="width: 130px; color: ${p.commits === 0 ? '#9ca0b0' : '#f9e2af'};">${p.commits} commit${p.commits === 1 ? '' : 's'} since tag</span>
+                <input type="text" class="release-version-input" data-repo="${escapeHtml(p.repoPath)}" value="${escapeHtml(p.version)}" style="width: 100px;" aria-label="Version to release" />
+                <span style="font-family: 'Consolas', monospace; width: 90px;">${escapeHtml(p.tag)}</span>`}
+              </div>`;
+          }).join('');
+        } catch (e) {
+          list.innerHTML = `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
         }
+      }
 
-        // Validate target version only if needed
-        if ((migrationType === 'spring-boot' || migrationType === 'java-version') && !targetVersion) {
-          alert("Please select a target version.");
+      async function releaseSelected() {
+        const repos = Array.from(document.querySelectorAll(".release-repo-cb:checked")).map(cb => cb.dataset.repo);
+        if (repos.length === 0) {
+          showToast('Error', 'Plan the release and select at least one repository first.', 'error');
           return;
         }
+        const dryRun = document.getElementById("release-dry-run").checked;
+        if (!dryRun && !confirm(`Release ${repos.length} repositories? Versions are committed and tagged${document.getElementById("release-push").checked ? ' and pushed' : ''}.`)) return;
 
-        container.classList.remove("hidden");
-        progressContainer.classList.add("hidden");
-        log.innerHTML =
-          '<div class="log-info">Starting OpenRewrite analysis... This may take a while.</div>';
-        isProcessRunning = true; // Mark process as running
+        const versions = {};
+        document.querySelectorAll(".release-version-input").forEach(input => {
+          if (repos.includes(input.dataset.repo) && input.value.trim()) versions[input.dataset.repo] = input.value.trim();
+        });
 
-        let totalProjects = 0;
+        const log = document.getElementById("release-log");
+        log.classList.remove("hidden");
+        log.innerHTML = "";
 
         try {
-          const res = await fetch("/api/analyze-spring", {
+          const response = await fetch("/api/release", {
             method: "POST",
             headers: { "Content-Type": "application/json" },
-            body: JSON.stringify({
-              RootPath: rootPath,
-              Excluded: excluded,
-              TargetVersion: targetVersion,
-              MigrationType: migrationType
-            }),
+            body: JSON.stringify({ ...getReleaseRequest(), repos, versions, dryRun }),
           });
+          if (!response.ok) throw new Error(await response.text());
 
-          const reader = res.body.getReader();
-          const decoder = new TextDecoder("utf-8");
-
+          const reader = response.body.getReader();
+          const decoder = new TextDecoder();
           while (true) {
             const { done, value } = await reader.read();
             if (done) break;
+            for (const line of decoder.decode(value, { stream: true }).split("\n")) {
+              if (!line.trim() || line.startsWith("REPO_DONE:")) continue;
+              if (line.startsWith("RELEASE_COMPLETE:")) {
+                showToast('Release', `${line.split(":")[1]} repositories ${dryRun ? 'ready to release' : 'released'}.`, 'success');
+                continue;
+              }
+              const div = document.createElement("div");
+              if (line.startsWith("REPO_START:")) {
+                div.style.fontWeight = "bold";
+                div.textContent = line.substring("REPO_START:".length);
+              } else {
+                div.style.color = line.includes("[ERROR]") ? "#f38ba8" : line.includes("[WARN]") ? "#fab387" : "#9ca0b0";
+                div.textContent = line;
+              }
+              log.appendChild(div);
+              log.scrollTop = log.scrollHeight;
+            }
+          }
+          if (!dryRun) {
+            planRelease();
+            loadBranchInfo();
+          }
+        } catch (e) {
+          showToast('Error', `Release failed: ${e.message}`, 'error');
+        }
+      }
 
-            const chunk = decoder.decode(value, { stream: true });
-            const lines = chunk.split("\n");
+      let repoStatuses = [];
 
-            for (let line of lines) {
-              if (!line.trim()) continue;
+      async function loadRepoStatus() {
+        const rootPath = document.getElementById("rootPath")?.value;
+        const group = getSelectedGroup();
+        if (!rootPath && !group) {
+          showToast('Error', 'Please configure a root path in Project Setup first.', 'error');
+          return;
+        }
 
-              // Handle progress markers
-              if (line.startsWith("PROGRESS_INIT:")) {
-                totalProjects = parseInt(line.split(":")[1]);
-                progressContainer.classList.remove("hidden");
-                progressBar.style.width = "0%";
-                progressText.textContent = `Analyzing... 0/${totalProjects}`;
-                progressEta.textContent = "Estimated: calculating...";
-                progressPercent.textContent = "0%";
-                // Clear repo status list
-                const repoStatusItems = document.getElementById("repo-status-items");
-                if (repoStatusItems) {
-                  repoStatusItems.innerHTML = "";
-                }
-                continue;
-              }
+        const list = document.getElementById("repo-status-list");
+        list.innerHTML = '<div class="hint">Loading...</div>';
+        try {
+          const res = await fetch("/api/repo-status", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({ rootPath, excluded: getExcludedProjects(), group }),
+          });
+          if (!res.ok) throw new Error(await res.text());
+          repoStatuses = (await res.json()) || [];
+          renderRepoStatus();
+        } catch (e) {
+          list.innerHTML = `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
+        }
+      }
 
-              // Handle individual repo queued (show as "running" with animated progress)
-              if (line.startsWith("REPO_QUEUED:")) {
-                const repoName = line.split(":")[1];
-                const repoStatusItems = document.getElementById("repo-status-items");
-                if (repoStatusItems) {
-                  const repoItem = document.createElement("div");
-                  repoItem.id = `repo-status-${repoName}`;
-                  repoItem.className = "repo-card running";
-                  repoItem.innerHTML = `
-                    <div class="repo-card-header">
-                      <span class="icon">🔄</span>
-                      <span class="name">${repoName}</span>
-                      <span class="status running">analyzing...</span>
-                    </div>
-                    <div class="repo-progress-bar">
-                      <div class="fill running"></div>
-                    </div>
-                  `;
-                  repoStatusItems.appendChild(repoItem);
-                }
-                continue;
-              }
+      function renderRepoStatus() {
+        const list = document.getElementById("repo-status-list");
+        const attentionOnly = document.getElementById("repo-status-attention").checked;
+        const needsAttention = s => s.error || s.dirty || s.ahead || s.behind || s.stashes || s.operation || s.detached;
+        const statuses = attentionOnly ? repoStatuses.filter(needsAttention) : repoStatuses;
+        if (statuses.length === 0) {
+          list.innerHTML = `<div class="hint">${repoStatuses.length ? 'All repositories are clean and in sync.' : 'No repositories found.'}</div>`;
+          return;
+        }
 
-              // Handle individual repo done
-              if (line.startsWith("REPO_DONE:")) {
-                const parts = line.split(":");
-                const repoName = parts[1];
-                const status = parts[2]; // SUCCESS or FAILED
-                const duration = parseFloat(parts[3]);
+        const changes = s => [
+          s.staged ? `${s.staged} staged` : '',
+          s.modified ? `${s.modified} modified` : '',
+          s.untracked ? `${s.untracked} untracked` : '',
+          s.conflicted ? `${s.conflicted} conflicted` : '',
+        ].filter(Boolean).join(', ');
+
+        list.innerHTML = `
+          <table style="width: 100%; border-collapse: collapse; font-size: 0.9em;">
+            <thead>
+              <tr style="text-align: left; color: #9ca0b0;">
+                <th>Repository</th><th>Branch</th><th>Remote</th><th>Working Tree</th><th>Upstream</th><th>Stashes</th><th>In Progress</th>
+              </tr>
+            </thead>
+            <tbody>
+              ${statuses.map(s => `
+                <tr style="border-bottom: 1px solid var(--border-color);">
+                  <td><strong>${escapeHtml(s.name)}</strong></td>
+                  ${s.error ? `<td colspan="6" class="log-error">${escapeHtml(s.error)}</td>` : `
+                  <td style="font-family: 'Consolas', monospace;">${s.detached ? `<span style="color: #f9e2af;">detached @ ${escapeHtml(s.head || '')}</span>` : escapeHtml(s.branch)}</td>
+                  <td>${remoteCell(s)}</td>
+                  <td style="color: ${s.conflicted ? '#f38ba8' : s.dirty ? '#f9e2af' : '#a6e3a1'};">${s.dirty ? escapeHtml(changes(s)) : 'clean'}</td>
+                  <td>${s.upstream ? `${escapeHtml(s.upstream)} <span style="color: #9ca0b0;">↑${s.ahead} ↓${s.behind}</span>` : '<span style="color: #9ca0b0;">none</span>'}</td>
+                  <td>${s.stashes || ''}</td>
+                  <td style="color: #f38ba8;">${escapeHtml(s.operation || '')}</td>`}
+                </tr>`).join('')}
+            </tbody>
+          </table>`;
+      }
 
-                const repoItem = document.getElementById(`repo-status-${repoName}`);
-                if (repoItem) {
-                  const isSuccess = status === "SUCCESS";
-                  const icon = isSuccess ? "✅" : "❌";
-                  const statusClass = isSuccess ? "success" : "failed";
+      // A select for repositories with several remotes, GitHousekeeper fetches from and pushes to the chosen one
+      function remoteCell(s) {
+        const remotes = s.remotes || [];
+        if (remotes.length < 2) {
+          return remotes.length ? escapeHtml(s.remote) : '<span style="color: #9ca0b0;">none</span>';
+        }
+        const index = repoStatuses.indexOf(s);
+        return `<select onchange="setRepoRemote(${index}, this.value)" style="padding: 2px 4px;">
+          ${s.remote ? '' : '<option value="" selected>choose...</option>'}
+          ${remotes.map(r => `<option value="${escapeHtml(r)}" ${r === s.remote ? 'selected' : ''}>${escapeHtml(r)}</option>`).join('')}
+        </select>`;
+      }
 
-                  repoItem.className = `repo-card ${statusClass}`;
-                  repoItem.innerHTML = `
-                    <div class="repo-card-header">
-                      <span class="icon">${icon}</span>
-                      <span class="name">${repoName}</span>
-                      <span class="status ${statusClass}">${duration.toFixed(1)}s</span>
-                    </div>
-                    <div class="repo-progress-bar">
-                      <div class="fill ${statusClass}"></div>
-                    </div>
-                  `;
-                }
-                continue;
-              }
+      async function setRepoRemote(index, remote) {
+        const s = repoStatuses[index];
+        try {
+          const res = await fetch("/api/repo-remote", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({ path: s.path, remote }),
+          });
+          if (!res.ok) throw new Error(await res.text());
+          repoStatuses[index] = await res.json();
+          renderRepoStatus();
+          showToast('Remote', `${s.name} now uses ${remote}.`, 'success');
+        } catch (e) {
+          showToast('Error', e.message, 'error');
+          renderRepoStatus();
+        }
+      }
 
-              if (line.startsWith("PROGRESS_UPDATE:")) {
-                const parts = line.split(":");
-                const completed = parseInt(parts[1]);
-                const total = parseInt(parts[2]);
-                const remainingSecs = parseFloat(parts[3]);
+      async function checkEnvironment() {
+        const list = document.getElementById("environment-list");
+        list.innerHTML = '<div class="hint">Checking...</div>';
+        try {
+          const res = await fetch("/api/environment");
+          if (!res.ok) throw new Error(await res.text());
+          const report = await res.json();
+          list.innerHTML = `
+            <table style="width: 100%; border-collapse: collapse; font-size: 0.9em;">
+              <tbody>
+                ${report.tools.map(t => `
+                  <tr style="border-bottom: 1px solid var(--border-color);">
+                    <td style="width: 24px; color: ${t.available && !t.error ? '#a6e3a1' : t.available ? '#f9e2af' : '#f38ba8'};">${t.available ? '✓' : '✗'}</td>
+                    <td><strong>${escapeHtml(t.name)}</strong></td>
+                    <td>${t.available
+                      ? `${escapeHtml(t.version || t.error || '')} <div style="color: #9ca0b0; font-size: 0.85em;">${escapeHtml(t.path)}</div>`
+                      : `<span style="color: #f9e2af;">${escapeHtml(t.hint)}</span>`}</td>
+                    <td style="text-align: right;">${t.installable ? `<button class="btn btn-secondary" onclick="installTool('${escapeHtml(t.name)}', this)" aria-label="Install ${escapeHtml(t.name)}">⬇️ Install</button>` : ''}</td>
+                  </tr>`).join('')}
+              </tbody>
+            </table>
+            <div class="hint">${escapeHtml(report.os)}/${escapeHtml(report.arch)}, ${report.missing.length ? `not installed: ${escapeHtml(report.missing.join(', '))}` : 'all tools installed'}</div>`;
+        } catch (e) {
+          list.innerHTML = `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
+        }
+      }
 
-                const percent = Math.round((completed / total) * 100);
-                progressBar.style.width = percent + "%";
-                progressText.textContent = `Analyzing... ${completed}/${total}`;
-                progressPercent.textContent = percent + "%";
+      async function installTool(name, btn) {
+        if (!confirm(`Install ${name} on the server? It is downloaded from the internet and put into the tool folder of GitHousekeeper's data directory (or installed with Homebrew/Chocolatey if available).`)) return;
+        btn.disabled = true;
+        btn.textContent = 'Installing...';
+        try {
+          const res = await fetch("/api/install-tool", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({ tool: name }),
+          });
+          if (!res.ok) throw new Error(await res.text());
+          const result = await res.json();
+          if (result.success) {
+            showToast('Installed', `${name} ${result.status.version || ''} installed (${result.method}).`, 'success');
+            checkEnvironment();
+          } else {
+            showToast('Installation failed', result.log[result.log.length - 1] || name, 'error', 10000);
+            btn.disabled = false;
+            btn.textContent = '⬇️ Install';
+          }
+        } catch (e) {
+          showToast('Error', e.message, 'error');
+          btn.disabled = false;
+          btn.textContent = '⬇️ Install';
+        }
+      }
 
-                if (remainingSecs > 0) {
-                  const mins = Math.floor(remainingSecs / 60);
-                  const secs = Math.round(remainingSecs % 60);
-                  if (mins > 0) {
-                    progressEta.textContent = `Estimated: ~${mins}m ${secs}s remaining`;
-                  } else {
-                    progressEta.textContent = `Estimated: ~${secs}s remaining`;
-                  }
-                } else {
-                  progressEta.textContent = "Finishing...";
-                }
-                continue;
-              }
+      async function checkGitAccess() {
+        const rootPath = document.getElementById("rootPath")?.value;
+        const group = getSelectedGroup();
+        if (!rootPath && !group) {
+          showToast('Error', 'Please configure a root path in Project Setup first.', 'error');
+          return;
+        }
 
-              if (line.startsWith("PROGRESS_DONE:")) {
-                const totalSecs = parseFloat(line.split(":")[1]);
-                const mins = Math.floor(totalSecs / 60);
-                const secs = Math.round(totalSecs % 60);
-                progressBar.style.width = "100%";
-                progressPercent.textContent = "100%";
-                progressText.textContent = `Completed ${totalProjects}/${totalProjects}`;
-                if (mins > 0) {
-                  progressEta.textContent = `Total time: ${mins}m ${secs}s`;
-                } else {
-                  progressEta.textContent = `Total time: ${secs}s`;
-                }
-                continue;
-              }
+        const list = document.getElementById("git-access-list");
+        list.innerHTML = '<div class="hint">Checking...</div>';
+        try {
+          const res = await fetch("/api/git-auth-check", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({ rootPath, excluded: getExcludedProjects(), group }),
+          });
+          if (!res.ok) throw new Error(await res.text());
+          const report = await res.json();
+          const noRemote = report.repos.filter(r => r.status === 'no-remote');
+          list.innerHTML = report.hosts.map(h => `
+            <div style="padding: 6px 0; border-bottom: 1px solid var(--border-color);">
+              <div style="display: flex; gap: 8px; align-items: center;">
+                <span style="color: ${h.status === 'ok' ? '#a6e3a1' : '#f38ba8'};">${h.status === 'ok' ? '✓' : '✗'}</span>
+                <strong>${escapeHtml(h.host || h.url)}</strong>
+                <span style="color: #9ca0b0;">${escapeHtml(h.protocol)}</span>
+                <span style="color: #9ca0b0; font-size: 0.85em; margin-left: auto;">${h.repos.length} repositories</span>
+              </div>
+              ${h.status === 'ok' ? '' : `
+                <div style="padding-left: 22px; font-size: 0.9em;">
+                  <div style="color: #f9e2af;">${escapeHtml(h.status)}: ${escapeHtml(h.hint || '')}</div>
+                  ${h.message ? `<pre style="margin: 4px 0; white-space: pre-wrap; font-size: 0.85em; color: #9ca0b0;">${escapeHtml(h.message)}</pre>` : ''}
+                  <div style="color: #9ca0b0;">${escapeHtml(h.repos.join(', '))}</div>
+                </div>`}
+            </div>`).join('')
+            + (noRemote.length ? `<div class="hint">Without a remote: ${escapeHtml(noRemote.map(r => r.name).join(', '))}</div>` : '')
+            + (report.hosts.length ? '' : '<div class="hint">No remotes found.</div>');
+          showToast('Git Access', report.failed ? `${report.failed} repositories cannot reach their remote.` : 'All remotes are reachable.', report.failed ? 'error' : 'success');
+        } catch (e) {
+          list.innerHTML = `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
+        }
+      }
+
+      function stashRequestBody(extra) {
+        return JSON.stringify({
+          rootPath: document.getElementById("rootPath")?.value,
+          excluded: getExcludedProjects(),
+          group: getSelectedGroup(),
+          ...extra,
+        });
+      }
+
+      async function loadStashes() {
+        const list = document.getElementById("stash-list");
+        list.innerHTML = '<div class="hint">Loading...</div>';
+        try {
+          const res = await fetch("/api/stashes", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: stashRequestBody({ managedOnly: document.getElementById("stash-managed-only").checked }),
+          });
+          if (!res.ok) throw new Error(await res.text());
+          const repos = ((await res.json()) || []).filter(r => r.error || r.stashes.length);
+          if (repos.length === 0) {
+            list.innerHTML = '<div class="hint">No stashes found.</div>';
+            return;
+          }
+          list.innerHTML = repos.map(r => `
+            <div style="padding: 6px 0; border-bottom: 1px solid var(--border-color);">
+              <strong>${escapeHtml(r.name)}</strong>
+              ${r.error ? `<div class="log-error">${escapeHtml(r.error)}</div>` : ''}
+              ${r.stashes.map(st => `
+                <div style="display: flex; gap: 8px; padding: 2px 0 2px 10px; font-size: 0.9em;">
+                  <span style="font-family: 'Consolas', monospace; color: #9ca0b0;">${escapeHtml(st.ref)}</span>
+                  <span style="flex: 1; color: ${st.managed ? '#f9e2af' : 'inherit'};">${escapeHtml(st.name || st.message)}</span>
+                  <span style="color: #9ca0b0;">${escapeHtml(st.branch)}</span>
+                  <span style="color: #9ca0b0; width: 150px; text-align: right;">${new Date(st.created).toLocaleString()}</span>
+                </div>`).join('')}
+            </div>`).join('');
+        } catch (e) {
+          list.innerHTML = `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
+        }
+      }
+
+      async function runStashOperation(operation) {
+        const name = document.getElementById("stash-name").value.trim();
+        if (!name) {
+          showToast('Error', 'Please enter a stash name.', 'error');
+          return;
+        }
+        if (operation === 'drop' && !confirm(`Drop the stash '${name}' in all repositories? The stashed changes are lost.`)) return;
 
-              // Check if line contains HTML (from migration summary)
-              if (
-                line.includes('<div class="migration-summary">') ||
-                line.includes('<div class="summary-section">') ||
-                line.includes("</div>")
-              ) {
-                // Render HTML directly
-                log.innerHTML += line;
-                log.scrollTop = log.scrollHeight;
-                continue;
-              }
+        const list = document.getElementById("stash-list");
+        list.innerHTML = '<div class="hint">Working...</div>';
+        try {
+          const res = await fetch(`/api/stashes/${operation}`, {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: stashRequestBody({ name }),
+          });
+          if (!res.ok) throw new Error(await res.text());
+          const results = (await res.json()) || [];
+          const failed = results.filter(r => r.status === 'failed');
+          const done = results.filter(r => ['stashed', 'restored', 'dropped'].includes(r.status));
+          list.innerHTML = results.filter(r => r.status !== 'clean' && r.status !== 'missing').map(r => `
+            <div style="display: flex; gap: 8px; padding: 2px 0; font-size: 0.9em;">
+              <strong style="width: 220px;">${escapeHtml(r.name)}</strong>
+              <span class="${r.status === 'failed' ? 'log-error' : ''}">${escapeHtml(r.status)}${r.error ? `: ${escapeHtml(r.error)}` : ''}</span>
+            </div>`).join('') || `<div class="hint">Nothing to do: no repository ${operation === 'create' ? 'has uncommitted changes' : `has a stash named '${escapeHtml(name)}'`}.</div>`;
+          if (failed.length) {
+            showToast('Stashes', `${done.length} ${operation === 'create' ? 'stashed' : operation === 'restore' ? 'restored' : 'dropped'}, ${failed.length} failed.`, 'error');
+          } else {
+            showToast('Stashes', `${done.length} repositories ${operation === 'create' ? 'stashed' : operation === 'restore' ? 'restored' : 'dropped'}.`, 'success');
+          }
+        } catch (e) {
+          list.innerHTML = `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
+        }
+      }
 
-              // Regular log output - escape HTML
-              const formattedLine = line
-                .replace(/</g, "&lt;")
-                .replace(/>/g, "&gt;");
+      async function analyzeRemoteBranches() {
+        const rootPath = document.getElementById("rootPath")?.value;
+        if (!rootPath) {
+          showToast('Error', 'Please configure a root path in Project Setup first.', 'error');
+          return;
+        }
 
-              // Color coding
-              let cssClass = "log-info";
-              if (line.includes("✗") || line.toLowerCase().includes("error")) {
-                cssClass = "log-error";
-              } else if (line.includes("✓") || line.includes(">>>")) {
-                cssClass = "log-success";
-              } else if (line.includes("Changes detected")) {
-                cssClass = "log-warning";
-              }
+        const minAge = parseInt(document.getElementById("remote-branches-age").value, 10) || 0;
+        const mergedOnly = document.getElementById("remote-branches-merged").checked;
+        const list = document.getElementById("remote-branches-list");
+        list.innerHTML = '<div class="hint">Analyzing...</div>';
+        try {
+          const res = await fetch("/api/remote-branches", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({
+              rootPath,
+              excluded: getExcludedProjects(),
+              group: getSelectedGroup(),
+              fetch: document.getElementById("remote-branches-fetch").checked,
+            }),
+          });
+          if (!res.ok) throw new Error(await res.text());
 
-              log.innerHTML += `<div class="${cssClass}">${formattedLine}</div>`;
-              log.scrollTop = log.scrollHeight;
-            }
+          const reports = ((await res.json()) || []).map(r => ({
+            ...r,
+            branches: (r.branches || []).filter(b => !b.protected && b.ageDays >= minAge && (!mergedOnly || b.merged)),
+          })).filter(r => r.error || r.branches.length);
+
+          if (reports.length === 0) {
+            list.innerHTML = '<div class="hint">No stale remote branches found.</div>';
+            return;
           }
 
-          log.innerHTML +=
-            '<div class="log-success" style="margin-top: 20px; border-top: 1px solid #444; padding-top: 10px;">--- Analysis Complete ---</div>';
-          isProcessRunning = false; // Mark process as complete
-          showToast('Analysis complete', 'Migration analysis has finished successfully.', 'success', 4000);
+          list.innerHTML = reports.map(r => `
+            <div style="padding: 6px 0; border-bottom: 1px solid var(--border-color);">
+              <strong>${escapeHtml(r.repoName)}</strong>
+              <span style="color: #9ca0b0; font-size: 0.85em;">${r.defaultBranch ? `default: ${escapeHtml(r.defaultBranch)}` : ''}</span>
+              ${r.error ? `<div class="log-error">${escapeHtml(r.error)}</div>` : ''}
+              ${r.branches.map(b => `
+                <label style="display: flex; align-items: center; gap: 8px; padding: 2px 0 2px 10px; font-weight: normal; font-size: 0.9em;">
+                  <input type="checkbox" class="remote-branch-cb" data-repo="${escapeHtml(r.repoName)}" data-branch="${escapeHtml(b.name)}" ${b.merged ? 'checked' : ''} style="width: auto;" />
+                  <span style="font-family: 'Consolas', monospace; flex: 1;">${escapeHtml(b.name)}</span>
+                  <span style="color: ${b.merged ? '#a6e3a1' : '#f9e2af'};">${b.merged ? 'merged' : 'unmerged'}</span>
+                  <span style="color: #9ca0b0; width: 90px; text-align: right;">${b.ageDays} days</span>
+                  <span style="color: #9ca0b0; width: 160px; overflow: hidden; text-overflow: ellipsis; white-space: nowrap;">${escapeHtml(b.author || '')}</span>
+                </label>`).join('')}
+            </div>`).join('');
         } catch (e) {
-          log.innerHTML += `<div class="log-error">Error: ${e.message}</div>`;
-          progressContainer.classList.add("hidden");
-          isProcessRunning = false; // Mark process as complete
-          showToast('Error', `Analysis failed: ${e.message}`, 'error');
+          list.innerHTML = `<div class="log-error">Error: ${escapeHtml(e.message)}</div>`;
         }
       }
 
-      // ==================== MAINTENANCE TAB ====================
+      async function deleteRemoteBranches() {
+        const rootPath = document.getElementById("rootPath")?.value;
+        const selected = Array.from(document.querySelectorAll(".remote-branch-cb:checked"))
+          .map(cb => ({ repo: cb.dataset.repo, branch: cb.dataset.branch }));
+        if (!rootPath || selected.length === 0) {
+          showToast('Error', 'Please run the analysis and select at least one branch.', 'error');
+          return;
+        }
 
-      function getExcludedProjects() {
-        const excluded = ["node_modules", "target", "dist", ".idea", ".vscode"];
-        document.querySelectorAll('#folder-list-container input[type="checkbox"]').forEach((cb) => {
-          if (!cb.checked) {
-            excluded.push(cb.value);
+        // Every branch is confirmed on its own, a deleted remote branch cannot be restored from here
+        const branches = selected.filter(b => confirm(`Delete branch '${b.branch}' on origin of ${b.repo}?`));
+        if (branches.length === 0) return;
+
+        const syncLog = document.getElementById("sync-log");
+        syncLog.classList.remove("hidden");
+        syncLog.innerHTML = "";
+
+        try {
+          const response = await fetch("/api/remote-branches/delete", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({ rootPath, excluded: getExcludedProjects(), group: getSelectedGroup(), branches }),
+          });
+          if (!response.ok) throw new Error(await response.text());
+
+          const reader = response.body.getReader();
+          const decoder = new TextDecoder();
+          while (true) {
+            const { done, value } = await reader.read();
+            if (done) break;
+            for (const line of decoder.decode(value, { stream: true }).split("\n")) {
+              if (!line.trim() || line.startsWith("REPO_DONE:")) continue;
+              if (line.startsWith("DELETE_COMPLETE:")) {
+                showToast('Remote Branches Deleted', `${line.split(":")[1]} of ${branches.length} branches deleted.`, 'success');
+                continue;
+              }
+              const div = document.createElement("div");
+              if (line.startsWith("REPO_START:")) {
+                div.style.fontWeight = "bold";
+                div.textContent = line.substring("REPO_START:".length);
+              } else {
+                div.style.color = line.includes("[ERROR]") ? "#f38ba8" : "#9ca0b0";
+                div.textContent = line;
+              }
+              syncLog.appendChild(div);
+              syncLog.scrollTop = syncLog.scrollHeight;
+            }
           }
-        });
-        return excluded;
+          analyzeRemoteBranches();
+        } catch (e) {
+          showToast('Error', `Deleting remote branches failed: ${e.message}`, 'error');
+        }
       }
 
       async function loadBranchInfo() {
@@ -1590,7 +5114,7 @@
           const response = await fetch("/api/list-branches", {
             method: "POST",
             headers: { "Content-Type": "application/json" },
-            body: JSON.stringify({ rootPath, excluded }),
+            body: JSON.stringify({ rootPath, excluded, group: getSelectedGroup() }),
           });
 
           if (!response.ok) throw new Error("Failed to load branches");
@@ -1634,7 +5158,7 @@
           showToast('Loaded', `${repos.length} repositories found`, 'success', 2000);
 
         } catch (e) {
-          container.innerHTML = `<div style="color: #ef5350; grid-column: 1 / -1; text-align: center; padding: 40px;">Error: ${e.message}</div>`;
+          container.innerHTML = `<div style="color: #ef5350; grid-column: 1 / -1; text-align: center; padding: 40px;">Error: ${escapeHtml(e.message)}</div>`;
           showToast('Error', e.message, 'error');
         }
       }
@@ -1661,12 +5185,20 @@
         syncLog.innerHTML = "";
         isProcessRunning = true;
 
+        // Repos currently being synced, shown next to the progress
+        const syncing = new Set();
+        let progressLabel = "Syncing... 0";
+        const showSyncProgress = () => {
+          progressText.textContent = syncing.size ? `${progressLabel} (${Array.from(syncing).join(", ")})` : progressLabel;
+        };
+
         try {
           const excluded = getExcludedProjects();
+          const concurrency = parseInt(document.getElementById("sync-concurrency")?.value, 10) || 0;
           const response = await fetch("/api/sync-branches", {
             method: "POST",
             headers: { "Content-Type": "application/json" },
-            body: JSON.stringify({ rootPath, excluded }),
+            body: JSON.stringify({ rootPath, excluded, group: getSelectedGroup(), concurrency }),
           });
 
           const reader = response.body.getReader();
@@ -1686,7 +5218,8 @@
 
               if (line.startsWith("SYNC_INIT:")) {
                 const total = parseInt(line.split(":")[1]);
-                progressText.textContent = `Syncing... 0/${total}`;
+                progressLabel = `Syncing... 0/${total}`;
+                showSyncProgress();
                 progressPercent.textContent = "0%";
                 progressBar.style.width = "0%";
                 progressBar.setAttribute("aria-valuenow", "0");
@@ -1698,15 +5231,24 @@
                 const current = parseInt(parts[1]);
                 const total = parseInt(parts[2]);
                 const percent = Math.round((current / total) * 100);
-                progressText.textContent = `Syncing... ${current}/${total}`;
+                progressLabel = `Syncing... ${current}/${total}`;
+                showSyncProgress();
                 progressPercent.textContent = `${percent}%`;
                 progressBar.style.width = `${percent}%`;
                 progressBar.setAttribute("aria-valuenow", percent.toString());
                 continue;
               }
 
+              if (line.startsWith("REPO_SYNCING:")) {
+                syncing.add(line.substring("REPO_SYNCING:".length));
+                showSyncProgress();
+                continue;
+              }
+
               if (line.startsWith("REPO_START:")) {
                 const repoName = line.split(":")[1];
+                syncing.delete(repoName);
+                showSyncProgress();
                 syncLog.innerHTML += `<div style="color: #7c8aff; margin-top: 10px; font-weight: bold;">▶ ${repoName}</div>`;
                 syncLog.scrollTop = syncLog.scrollHeight;
                 continue;
@@ -1737,7 +5279,7 @@
           loadBranchInfo(); // Refresh the branch list
 
         } catch (e) {
-          syncLog.innerHTML += `<div style="color: #ef5350;">Error: ${e.message}</div>`;
+          syncLog.innerHTML += `<div style="color: #ef5350;">Error: ${escapeHtml(e.message)}</div>`;
           showToast('Error', e.message, 'error');
         } finally {
           btn.disabled = false;
@@ -1850,7 +5392,7 @@
         } catch (e) {
           container.innerHTML = `
             <div style="color: #f38ba8; text-align: center; padding: 20px;">
-              Error loading repositories: ${e.message}
+              Error loading repositories: ${escapeHtml(e.message)}
             </div>`;
         }
       }
@@ -1876,7 +5418,7 @@
           const response = await fetch("/api/list-branches", {
             method: "POST",
             headers: { "Content-Type": "application/json" },
-            body: JSON.stringify({ rootPath, excluded }),
+            body: JSON.stringify({ rootPath, excluded, group: getSelectedGroup() }),
           });
 
           if (!response.ok) throw new Error("Failed to load branches");
@@ -2021,6 +5563,9 @@
         const goInfo = document.getElementById('go-info');
         const pythonInfo = document.getElementById('python-info');
         const phpInfo = document.getElementById('php-info');
+        const rustInfo = document.getElementById('rust-info');
+        const dotnetInfo = document.getElementById('dotnet-info');
+        const rubyInfo = document.getElementById('ruby-info');
 
         // Hide all
         autoInfo.classList.add('hidden');
@@ -2030,6 +5575,9 @@
         goInfo.classList.add('hidden');
         pythonInfo.classList.add('hidden');
         phpInfo.classList.add('hidden');
+        rustInfo.classList.add('hidden');
+        dotnetInfo.classList.add('hidden');
+        rubyInfo.classList.add('hidden');
 
         // Show selected
         switch (scanner) {
@@ -2038,6 +5586,7 @@
             break;
           case 'owasp':
             owaspInfo.classList.remove('hidden');
+            loadOwaspDatabase();
             break;
           case 'trivy':
             trivyInfo.classList.remove('hidden');
@@ -2069,6 +5618,24 @@
               checkPhpAvailability();
             }
             break;
+          case 'cargo-audit':
+            rustInfo.classList.remove('hidden');
+            if (!rustCheckDone) {
+              checkRustAvailability();
+            }
+            break;
+          case 'dotnet':
+            dotnetInfo.classList.remove('hidden');
+            if (!dotnetCheckDone) {
+              checkDotnetAvailability();
+            }
+            break;
+          case 'bundler-audit':
+            rubyInfo.classList.remove('hidden');
+            if (!rubyCheckDone) {
+              checkRubyAvailability();
+            }
+            break;
         }
       }
 
@@ -2168,6 +5735,102 @@
         phpCheckDone = true;
       }
 
+      // Rust availability check
+      let rustCheckDone = false;
+      async function checkRustAvailability() {
+        const statusIcon = document.getElementById('rust-status-icon');
+        const statusText = document.getElementById('rust-status');
+        const installHint = document.getElementById('rust-install-hint');
+
+        statusIcon.textContent = '⏳';
+        statusText.textContent = 'Checking cargo audit availability...';
+        installHint.classList.add('hidden');
+
+        try {
+          const res = await fetch('/api/check-rust');
+          const data = await res.json();
+
+          if (data.available) {
+            statusIcon.textContent = '✅';
+            statusText.textContent = `cargo audit installed (${data.version || 'available'})`;
+            installHint.classList.add('hidden');
+          } else {
+            statusIcon.textContent = '❌';
+            statusText.textContent = 'cargo audit not found';
+            installHint.classList.remove('hidden');
+          }
+        } catch (e) {
+          statusIcon.textContent = '⚠️';
+          statusText.textContent = 'Could not check cargo audit';
+        }
+
+        rustCheckDone = true;
+      }
+
+      // .NET availability check
+      let dotnetCheckDone = false;
+      async function checkDotnetAvailability() {
+        const statusIcon = document.getElementById('dotnet-status-icon');
+        const statusText = document.getElementById('dotnet-status');
+        const installHint = document.getElementById('dotnet-install-hint');
+
+        statusIcon.textContent = '⏳';
+        statusText.textContent = 'Checking .NET SDK availability...';
+        installHint.classList.add('hidden');
+
+        try {
+          const res = await fetch('/api/check-dotnet');
+          const data = await res.json();
+
+          if (data.available) {
+            statusIcon.textContent = '✅';
+            statusText.textContent = `.NET SDK installed (${data.version || 'available'})`;
+            installHint.classList.add('hidden');
+          } else {
+            statusIcon.textContent = '❌';
+            statusText.textContent = '.NET SDK not found';
+            installHint.classList.remove('hidden');
+          }
+        } catch (e) {
+          statusIcon.textContent = '⚠️';
+          statusText.textContent = 'Could not check the .NET SDK';
+        }
+
+        dotnetCheckDone = true;
+      }
+
+      // Ruby availability check
+      let rubyCheckDone = false;
+      async function checkRubyAvailability() {
+        const statusIcon = document.getElementById('ruby-status-icon');
+        const statusText = document.getElementById('ruby-status');
+        const installHint = document.getElementById('ruby-install-hint');
+
+        statusIcon.textContent = '⏳';
+        statusText.textContent = 'Checking bundler-audit availability...';
+        installHint.classList.add('hidden');
+
+        try {
+          const res = await fetch('/api/check-ruby');
+          const data = await res.json();
+
+          if (data.available) {
+            statusIcon.textContent = '✅';
+            statusText.textContent = `bundler-audit installed (${data.version || 'available'})`;
+            installHint.classList.add('hidden');
+          } else {
+            statusIcon.textContent = '❌';
+            statusText.textContent = 'bundler-audit not found';
+            installHint.classList.remove('hidden');
+          }
+        } catch (e) {
+          statusIcon.textContent = '⚠️';
+          statusText.textContent = 'Could not check bundler-audit';
+        }
+
+        rubyCheckDone = true;
+      }
+
       // Get severity color
       function getSeverityColor(severity) {
         switch ((severity || '').toUpperCase()) {
@@ -2185,6 +5848,15 @@
         return `<span style="background: ${color}22; color: ${color}; padding: 2px 8px; border-radius: 4px; font-size: 0.75em; font-weight: bold;">${severity}</span>`;
       }
 
+      // Advisory page of a finding: NVD for CVEs, the Trivy/tfsec check (KSV001, AVD-AWS-0086) or the
+      // kubesec docs for misconfigurations, OSV for everything else
+      function findingUrl(f) {
+        if (f.scanTarget && f.scanTarget.startsWith('config:')) {
+          return /\d/.test(f.cve) ? 'https://avd.aquasec.com/misconfig/' + f.cve.toLowerCase() : 'https://kubesec.io/';
+        }
+        return (f.cve.startsWith('CVE-') ? 'https://nvd.nist.gov/vuln/detail/' : 'https://osv.dev/vulnerability/') + f.cve;
+      }
+
       // Run security scan
       async function runSecurityScan() {
         if (isProcessRunning) {
@@ -2233,7 +5905,11 @@
         let summaryStats = { critical: 0, high: 0, medium: 0, low: 0, total: 0 };
 
         // Get selected target branch
-        const targetBranch = document.getElementById('security-branch-select')?.value || '';
+        const targetBranch = document.getElementById('security-remote-branch')?.value.trim()
+          || document.getElementById('security-branch-select')?.value || '';
+        const failOnCount = document.getElementById('security-fail-on')?.value.trim() || '';
+        let policyResult = '';
+        let abortReason = '';
 
         try {
           const res = await fetch('/api/security-scan', {
@@ -2242,8 +5918,20 @@
             body: JSON.stringify({
               rootPath: rootPath,
               excluded: getExcludedProjects(),
+              group: getSelectedGroup(),
               scanner: scanner,
-              targetBranch: targetBranch
+              targetBranch: targetBranch,
+              worktree: document.getElementById('security-worktree')?.checked || false,
+              maven: getMavenSettings(),
+              containerImages: document.getElementById('security-images-select')?.value || '',
+              configScan: document.getElementById('security-config-select')?.value || '',
+              minSeverity: document.getElementById('security-min-severity')?.value || '',
+              failOn: failOnCount === '' ? null : { severity: 'CRITICAL', maxCount: parseInt(failOnCount) || 0 },
+              refresh: document.getElementById('security-refresh')?.checked || false,
+              compare: document.getElementById('security-compare')?.value === 'compare',
+              onlyNew: document.getElementById('security-compare')?.value === 'onlyNew',
+              emailReport: document.getElementById('security-email-report')?.checked || false,
+              emailTo: getRepoSelection('security-email-to')
             })
           });
 
@@ -2299,6 +5987,37 @@
                 continue;
               }
 
+              // SCAN_WARNING:message / SCAN_INFO:message
+              if (line.startsWith("SCAN_WARNING:")) {
+                showToast('Security scan', line.substring(13), 'warning');
+                continue;
+              }
+              if (line.startsWith("SCAN_INFO:")) {
+                repoList.innerHTML += `<div style="padding: 4px 0; color: #9ca0b0;">ℹ️ ${escapeHtml(line.substring(10))}</div>`;
+                continue;
+              }
+              // SCAN_OWASP_DB:line (Maven output of the NVD database update before the OWASP scans)
+              if (line.startsWith("SCAN_OWASP_DB:")) {
+                progressText.textContent = `Updating NVD database: ${line.substring(14).replace(/^\[\w+\]\s*/, '').substring(0, 100)}`;
+                continue;
+              }
+
+              // SCAN_MISSING_TOOL:{json} / SCAN_ABORT:reason (preflight, nothing was scanned)
+              if (line.startsWith("SCAN_MISSING_TOOL:")) {
+                const tool = JSON.parse(line.substring(18));
+                repoList.innerHTML += `<div style="padding: 4px 0;">
+                  <span style="color: #f38ba8;">✗ ${escapeHtml(tool.name)}</span>
+                  <span style="color: #9ca0b0;">needed by ${escapeHtml(tool.repos.join(', '))}</span>
+                  <div style="padding-left: 16px; color: #f9e2af; font-size: 0.9em;">${escapeHtml(tool.hint)}</div>
+                  ${['govulncheck', 'trivy', 'pip-audit'].includes(tool.name) ? `<button class="btn btn-secondary" style="margin-left: 16px;" onclick="installTool('${escapeHtml(tool.name)}', this)">⬇️ Install ${escapeHtml(tool.name)}</button>` : ''}
+                </div>`;
+                continue;
+              }
+              if (line.startsWith("SCAN_ABORT:")) {
+                abortReason = line.substring(11);
+                continue;
+              }
+
               // REPO_START:name
               if (line.startsWith("REPO_START:")) {
                 const repoName = line.split(":")[1];
@@ -2322,7 +6041,8 @@
                     const cveCount = result.findings ? result.findings.length : 0;
                     const statusColor = result.error ? '#f38ba8' : (cveCount > 0 ? '#fab387' : '#a6e3a1');
                     const statusText = result.error ? '✗ Skipped' : (cveCount > 0 ? `⚠ ${cveCount} CVEs` : '✓ Clean');
-                    repoEl.innerHTML = `<span style="color: ${statusColor};">${statusText}</span> <span style="color: #9ca0b0;">${result.repoName}</span>`;
+                    const cachedText = result.cachedAt ? ` <span style="color: #6c7086; font-size: 0.85em;">(cached ${new Date(result.cachedAt).toLocaleString()})</span>` : '';
+                    repoEl.innerHTML = `<span style="color: ${statusColor};">${statusText}</span> <span style="color: #9ca0b0;">${result.repoName}</span>${cachedText}`;
                   }
                 } catch (e) {
                   console.error('Failed to parse repo result:', e);
@@ -2347,6 +6067,12 @@
                 continue;
               }
 
+              // SCAN_POLICY:PASS or SCAN_POLICY:FAIL:reason
+              if (line.startsWith("SCAN_POLICY:")) {
+                policyResult = line.substring(12);
+                continue;
+              }
+
               // SCAN_COMPLETE
               if (line.startsWith("SCAN_COMPLETE")) {
                 progressPercent.textContent = "100%";
@@ -2358,15 +6084,29 @@
             }
           }
 
+          if (abortReason) {
+            resultsDiv.innerHTML = `<div style="color: #f38ba8; grid-column: 1 / -1; text-align: center; padding: 40px;">Scan aborted: ${escapeHtml(abortReason)}. Install them and scan again.</div>`;
+            showToast('Scan aborted', abortReason, 'error', 8000);
+            return;
+          }
+
           // Display results
           displaySecurityResults();
           displaySecuritySummary(summaryStats);
+          updateAutoFixPanel();
+          if (document.getElementById('security-compare')?.value) {
+            displaySecurityDelta();
+          }
 
-          showToast('Scan complete', `${securityScanResults.length} repositories scanned.`, 'success', 3000);
+          if (policyResult.startsWith('FAIL')) {
+            showToast('Scan policy failed', policyResult.substring(5), 'error');
+          } else {
+            showToast('Scan complete', `${securityScanResults.length} repositories scanned.${policyResult ? ' Policy passed.' : ''}`, 'success', 3000);
+          }
 
         } catch (e) {
           showToast('Error', e.message, 'error');
-          resultsDiv.innerHTML = `<div style="color: #f38ba8; grid-column: 1 / -1; text-align: center; padding: 40px;">Error: ${e.message}</div>`;
+          resultsDiv.innerHTML = `<div style="color: #f38ba8; grid-column: 1 / -1; text-align: center; padding: 40px;">Error: ${escapeHtml(e.message)}</div>`;
         } finally {
           btn.disabled = false;
           btn.innerHTML = '🔍 Scan for Vulnerabilities';
@@ -2427,28 +6167,57 @@
             case 'php':
               projectBadge = '<span style="background: #8892BF22; color: #8892BF; padding: 2px 6px; border-radius: 4px; font-size: 0.7em; margin-left: 8px;">🐘 PHP</span>';
               break;
+            case 'rust':
+              projectBadge = '<span style="background: #DEA58422; color: #DEA584; padding: 2px 6px; border-radius: 4px; font-size: 0.7em; margin-left: 8px;">🦀 Rust</span>';
+              break;
+            case 'dotnet':
+              projectBadge = '<span style="background: #b4a0f522; color: #b4a0f5; padding: 2px 6px; border-radius: 4px; font-size: 0.7em; margin-left: 8px;">🟣 .NET</span>';
+              break;
+            case 'ruby':
+              projectBadge = '<span style="background: #CC342D22; color: #f38ba8; padding: 2px 6px; border-radius: 4px; font-size: 0.7em; margin-left: 8px;">💎 Ruby</span>';
+              break;
             case 'trivy':
               projectBadge = '<span style="background: #a6e3a122; color: #a6e3a1; padding: 2px 6px; border-radius: 4px; font-size: 0.7em; margin-left: 8px;">🐳 Trivy</span>';
               break;
+            case 'kubernetes':
+              projectBadge = '<span style="background: #326CE522; color: #89b4fa; padding: 2px 6px; border-radius: 4px; font-size: 0.7em; margin-left: 8px;">☸️ Kubernetes</span>';
+              break;
+            case 'terraform':
+              projectBadge = '<span style="background: #7B42BC22; color: #cba6f7; padding: 2px 6px; border-radius: 4px; font-size: 0.7em; margin-left: 8px;">🏗️ Terraform</span>';
+              break;
           }
 
           // Branch badge (show if available)
           const branchBadge = result.scannedBranch
             ? `<span style="background: #cba6f722; color: #cba6f7; padding: 2px 6px; border-radius: 4px; font-size: 0.7em; margin-left: 8px;">🔀 ${result.scannedBranch}</span>`
             : '';
+          const subProjects = result.subProjects || [];
+          const subProjectsBadge = subProjects.length > 0
+            ? `<span style="background: #f5c2e722; color: #f5c2e7; padding: 2px 6px; border-radius: 4px; font-size: 0.7em; margin-left: 8px;" title="${escapeHtml(subProjects.map(p => `${p.path} (${p.projectType}, ${p.scanner}): ${p.error ? p.error : p.findings + ' findings'}`).join('\n'))}">📂 ${subProjects.length} sub-projects</span>`
+            : '';
+          const cachedBadge = result.cachedAt
+            ? `<span style="background: #89b4fa22; color: #89b4fa; padding: 2px 6px; border-radius: 4px; font-size: 0.7em; margin-left: 8px;" title="Dependencies unchanged, result of an earlier scan. Use Force refresh to scan again.">🗄️ cached ${new Date(result.cachedAt).toLocaleString()}</span>`
+            : '';
 
           html += `<div class="card" style="border-left: 4px solid ${cardColor};">
             <div style="display: flex; justify-content: space-between; align-items: center; margin-bottom: 10px;">
-              <h4 style="margin: 0; color: ${cardColor};">📁 ${result.repoName}${projectBadge}${branchBadge}</h4>
+              <h4 style="margin: 0; color: ${cardColor};">📁 ${result.repoName}${projectBadge}${subProjectsBadge}${branchBadge}${cachedBadge}</h4>
               <div style="display: flex; align-items: center; gap: 10px;">
                 <span style="color: #9ca0b0; font-size: 0.85em;">${result.duration ? result.duration.toFixed(1) + 's' : ''}</span>
+                ${getFixableFindings(result).length > 0 ? `<button onclick="autoFixVulnerabilities('${result.repoName.replace(/'/g, "\\'")}')" class="btn btn-secondary" style="padding: 4px 8px; font-size: 0.75em;" title="Bump the vulnerable dependencies to their fix versions">🩹 ${getFixableFindings(result).length}</button>` : ''}
                 <button onclick="exportSingleRepoSecurityPdf('${result.repoName.replace(/'/g, "\\'")}')" class="btn btn-secondary" style="padding: 4px 8px; font-size: 0.75em;" title="Export PDF for this repo">📄</button>
               </div>
             </div>`;
 
+          if (result.warning) {
+            html += `<div style="color: #f9e2af; padding: 10px; margin-bottom: 10px; background: #f9e2af22; border-radius: 4px;">
+              <strong>Warning:</strong> ${escapeHtml(result.warning)}
+            </div>`;
+          }
+
           if (hasError) {
             html += `<div style="color: #f38ba8; padding: 10px; background: #f38ba822; border-radius: 4px;">
-              <strong>Error:</strong> ${result.error}
+              <strong>Error:</strong> ${escapeHtml(result.error)}
             </div>`;
           } else if (cveCount === 0) {
             html += `<div style="color: #a6e3a1; padding: 10px; background: #a6e3a122; border-radius: 4px;">
@@ -2478,10 +6247,10 @@
               for (const f of bySeverity[sev]) {
                 html += `<div style="padding: 8px; margin-bottom: 8px; background: var(--input-bg); border-radius: 4px; border-left: 3px solid ${getSeverityColor(f.severity)};">
                   <div style="display: flex; justify-content: space-between; align-items: center; margin-bottom: 4px;">
-                    <a href="https://nvd.nist.gov/vuln/detail/${f.cve}" target="_blank" style="color: #89b4fa; text-decoration: none; font-weight: bold;">${f.cve}</a>
-                    ${getSeverityBadge(f.severity)}
+                    <a href="${findingUrl(f)}" target="_blank" style="color: #89b4fa; text-decoration: none; font-weight: bold;">${f.cve}</a>
+                    <span>${f.change === 'new' ? '<span style="background: #f38ba822; color: #f38ba8; padding: 1px 6px; border-radius: 4px; font-size: 0.75em; margin-right: 6px;" title="Not found by the previous scan">🆕 NEW</span>' : ''}${f.cvssScore ? `<span style="font-size: 0.8em; color: #a6adc8; margin-right: 6px;" title="CVSS v3 base score">CVSS ${f.cvssScore.toFixed(1)}</span>` : ''}${getSeverityBadge(f.severity)}</span>
                   </div>
-                  <div style="font-size: 0.85em; color: #cdd6f4;">${f.package}${f.version ? ' @ ' + f.version : ''}</div>
+                  <div style="font-size: 0.85em; color: #cdd6f4;">${f.subPath ? `<span style="background: #f5c2e722; color: #f5c2e7; padding: 1px 6px; border-radius: 4px; font-size: 0.85em; margin-right: 4px;" title="Sub-project">📂 ${escapeHtml(f.subPath)}</span>` : ''}${f.package}${f.version ? ' @ ' + f.version : ''}${f.scanTarget ? ` <span style="background: #89b4fa22; color: #89b4fa; padding: 1px 6px; border-radius: 4px; font-size: 0.85em;">${f.scanTarget.startsWith('config:') ? '⚙️' : '🐳'} ${escapeHtml(f.scanTarget.replace(/^(image|config):/, ''))}</span>` : ''}</div>
                   ${f.fixedIn ? `<div style="font-size: 0.8em; color: #a6e3a1;">Fixed in: ${f.fixedIn}</div>` : ''}
                   ${f.description ? `<div style="font-size: 0.8em; color: #9ca0b0; margin-top: 4px;">${f.description.substring(0, 150)}${f.description.length > 150 ? '...' : ''}</div>` : ''}
                 </div>`;
@@ -2490,12 +6259,142 @@
             html += `</div>`;
           }
 
+          // Compare mode: what the previous scan found that is gone now
+          const fixed = result.fixed || [];
+          if (result.previousScanAt) {
+            const newCount = (result.findings || []).filter(f => f.change === 'new').length;
+            html += `<div style="margin-top: 10px; font-size: 0.85em; color: #9ca0b0;">Since the scan of ${new Date(result.previousScanAt).toLocaleString()}: ${newCount} new, ${fixed.length} fixed</div>`;
+          }
+          if (fixed.length > 0) {
+            html += `<details style="margin-top: 6px; font-size: 0.85em;">
+              <summary style="cursor: pointer; color: #a6e3a1;">✅ ${fixed.length} fixed</summary>
+              ${fixed.map(f => `
+                <div style="padding: 6px 8px; margin-top: 6px; background: var(--input-bg); border-radius: 4px;">
+                  <strong>${escapeHtml(f.cve)}</strong> ${escapeHtml(f.package)} ${getSeverityBadge(f.severity)}
+                </div>`).join('')}
+            </details>`;
+          }
+
+          // Accepted risks are listed apart from the actionable findings
+          const suppressed = result.suppressed || [];
+          if (suppressed.length > 0) {
+            html += `<details style="margin-top: 10px; font-size: 0.85em;">
+              <summary style="cursor: pointer; color: #9ca0b0;">🔕 ${suppressed.length} suppressed</summary>
+              ${suppressed.map(f => `
+                <div style="padding: 6px 8px; margin-top: 6px; background: var(--input-bg); border-radius: 4px; opacity: 0.8;">
+                  <strong>${escapeHtml(f.cve)}</strong> ${escapeHtml(f.package)} ${getSeverityBadge(f.severity)}
+                  <div style="color: #9ca0b0;">${escapeHtml(f.justification || 'No justification')}${f.expires ? ` · until ${escapeHtml(f.expires)}` : ''}</div>
+                </div>`).join('')}
+            </details>`;
+          }
+
           html += `</div>`;
         }
 
         resultsDiv.innerHTML = html;
       }
 
+      // Dependency findings of the repository root with a known fix version; image findings are fixed
+      // in the Dockerfile instead, sub-project findings in the sub-project
+      function getFixableFindings(result) {
+        return (result.findings || []).filter(f => f.fixedIn && !f.scanTarget && !f.subPath);
+      }
+
+      function updateAutoFixPanel() {
+        const panel = document.getElementById('security-autofix');
+        const fixable = securityScanResults.reduce((sum, r) => sum + getFixableFindings(r).length, 0);
+        panel.classList.toggle('hidden', fixable === 0);
+        document.getElementById('security-fix-count').textContent = `${fixable} findings with a fix version`;
+      }
+
+      // Bumps the fixable dependencies of one repo (or all scanned repos) on a fix branch
+      async function autoFixVulnerabilities(repoName) {
+        if (isProcessRunning) {
+          showToast('Process running', 'Please wait until the current process is complete.', 'warning');
+          return;
+        }
+        const rootPath = document.getElementById("rootPath").value.trim();
+
+        const repos = {};
+        for (const result of securityScanResults) {
+          if (repoName && result.repoName !== repoName) continue;
+          const fixes = getFixableFindings(result).map(f => ({ package: f.package, fixedIn: f.fixedIn, cve: f.cve }));
+          if (fixes.length > 0) repos[result.repoName] = fixes;
+        }
+        const count = Object.keys(repos).length;
+        if (count === 0) {
+          showToast('Nothing to fix', 'No findings with a fix version.', 'info');
+          return;
+        }
+        const branch = document.getElementById('security-fix-branch').value.trim() || 'security-fix';
+        const push = document.getElementById('security-fix-push').checked;
+        if (!confirm(`Bump vulnerable dependencies in ${count} repositories on branch '${branch}'${push ? ' and push it' : ''}?`)) return;
+
+        isProcessRunning = true;
+        const btn = document.getElementById('security-fix-all-btn');
+        const fixLog = document.getElementById('security-fix-log');
+        document.getElementById('security-autofix').classList.remove('hidden');
+        btn.disabled = true;
+        fixLog.classList.remove('hidden');
+        fixLog.innerHTML = '';
+
+        try {
+          const response = await fetch('/api/security-fix', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({
+              rootPath,
+              excluded: getExcludedProjects(),
+              group: getSelectedGroup(),
+              repos,
+              branch,
+              push,
+              maven: getMavenSettings(),
+              signing: getSigningSettings(),
+            }),
+          });
+          if (!response.ok) throw new Error(await response.text());
+
+          const reader = response.body.getReader();
+          const decoder = new TextDecoder();
+          let buffer = '';
+          while (true) {
+            const { done, value } = await reader.read();
+            if (done) break;
+            buffer += decoder.decode(value, { stream: true });
+            const lines = buffer.split("\n");
+            buffer = lines.pop();
+            for (const line of lines) {
+              if (!line.trim() || line.startsWith("REPO_DONE:") || line.startsWith("RUN_ID:")) continue;
+              if (line.startsWith("SECURITY_FIX_COMPLETE:")) {
+                showToast('Auto-fix complete', `${line.split(":")[1]} of ${count} repositories fixed.`, 'success');
+                continue;
+              }
+              if (line.startsWith("TOOLS_ABORT:")) {
+                showToast('Auto-fix aborted', `${line.split(":")[1]} required tools are not installed.`, 'error', 8000);
+                continue;
+              }
+              const div = document.createElement("div");
+              if (line.startsWith("REPO_START:")) {
+                div.style.fontWeight = "bold";
+                div.style.marginTop = "6px";
+                div.textContent = line.substring("REPO_START:".length);
+              } else {
+                div.style.color = line.includes("[ERROR]") ? "#f38ba8" : (line.includes("[SKIP]") ? "#f9e2af" : "#9ca0b0");
+                div.textContent = line;
+              }
+              fixLog.appendChild(div);
+              fixLog.scrollTop = fixLog.scrollHeight;
+            }
+          }
+        } catch (e) {
+          showToast('Error', `Auto-fix failed: ${e.message}`, 'error');
+        } finally {
+          btn.disabled = false;
+          isProcessRunning = false;
+        }
+      }
+
       // Display security summary
       function displaySecuritySummary(stats) {
         const summaryDiv = document.getElementById('security-summary');
@@ -2511,6 +6410,25 @@
         document.getElementById('security-low').textContent = stats.low || 0;
       }
 
+      // displaySecurityDelta shows the net new CRITICAL findings of the last 7 days from the scan history
+      async function displaySecurityDelta() {
+        const deltaDiv = document.getElementById('security-delta');
+        const rootPath = document.getElementById('rootPath').value.trim();
+        try {
+          const res = await fetch(`/api/findings/delta?days=7&severity=CRITICAL&rootPath=${encodeURIComponent(rootPath)}`);
+          if (!res.ok) throw new Error(await res.text());
+          const delta = await res.json();
+          const color = delta.netNew > 0 ? '#f38ba8' : '#a6e3a1';
+          deltaDiv.innerHTML = `<strong style="color: ${color};">Net new CRITICALs this week: ${delta.netNew > 0 ? '+' : ''}${delta.netNew}</strong>
+            <span style="color: #9ca0b0;">(${delta.new} new, ${delta.fixed} fixed since ${new Date(delta.since).toLocaleDateString()})</span>
+            ${delta.repos.filter(r => r.new.length > 0).map(r => `<div style="font-size: 0.85em; color: #9ca0b0;">${escapeHtml(r.repoName)}${r.branch ? ` (${escapeHtml(r.branch)})` : ''}: ${r.new.map(f => escapeHtml(f.cve)).join(', ')}</div>`).join('')}`;
+          deltaDiv.classList.remove('hidden');
+        } catch (e) {
+          console.error('Failed to load the findings delta', e);
+          deltaDiv.classList.add('hidden');
+        }
+      }
+
       // Export security report as PDF
       async function exportSecurityPdf() {
         if (securityScanResults.length === 0) {
@@ -2566,7 +6484,7 @@
             <h3>📁 ${result.repoName}</h3>`;
 
           if (result.error) {
-            html += `<p style="color: #e64553;">Error: ${result.error}</p>`;
+            html += `<p style="color: #e64553;">Error: ${escapeHtml(result.error)}</p>`;
           } else if (cveCount === 0) {
             html += `<p style="color: #40a02b;">✓ No vulnerabilities found</p>`;
           } else {
@@ -2576,7 +6494,7 @@
               html += `<tr>
                 <td class="cve-id">${f.cve}</td>
                 <td>${f.severity}</td>
-                <td>${f.package}</td>
+                <td>${f.package}${f.scanTarget ? ' (' + f.scanTarget + ')' : ''}</td>
                 <td>${f.version || '-'}</td>
                 <td>${f.fixedIn || '-'}</td>
               </tr>`;
@@ -2678,7 +6596,7 @@
 
         if (result.error) {
           html += `<h2>Error</h2>
-          <p style="color: #e64553; background: #f5f5f5; padding: 15px; border-radius: 8px;">${result.error}</p>`;
+          <p style="color: #e64553; background: #f5f5f5; padding: 15px; border-radius: 8px;">${escapeHtml(result.error)}</p>`;
         } else if (cveCount === 0) {
           html += `<div class="summary-box clean" style="margin-top: 20px; text-align: center;">
             <strong style="color: #40a02b; font-size: 1.2em;">✓ No vulnerabilities found</strong>
@@ -2700,7 +6618,7 @@
             html += `<tr>
               <td class="cve-id">${f.cve}</td>
               <td>${f.severity}</td>
-              <td>${f.package}</td>
+              <td>${f.package}${f.scanTarget ? ' (' + f.scanTarget + ')' : ''}</td>
               <td>${f.version || '-'}</td>
               <td>${f.fixedIn || '-'}</td>
               <td style="font-size: 0.85em;">${f.description ? f.description.substring(0, 100) + (f.description.length > 100 ? '...' : '') : '-'}</td>
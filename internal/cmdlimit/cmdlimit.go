@@ -0,0 +1,185 @@
+// Package cmdlimit limits how many external processes of a kind run at the same time, shared by
+// every feature (runs, dashboard, security scans, analyses) so parallel requests cannot start
+// dozens of Maven or npm processes at once.
+package cmdlimit
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Environment variables overriding the number of concurrent processes per class
+const (
+	MaxMavenEnv = "GITHOUSEKEEPER_MAX_MAVEN" // mvn and mvnw, default: half the CPUs
+	MaxGitEnv   = "GITHOUSEKEEPER_MAX_GIT"   // git, default: twice the CPUs
+	MaxNodeEnv  = "GITHOUSEKEEPER_MAX_NODE"  // npm, yarn, pnpm, node, default: half the CPUs
+	MaxToolsEnv = "GITHOUSEKEEPER_MAX_TOOLS" // Scanners and every other tool, default: half the CPUs
+)
+
+// Process classes sharing a limit
+const (
+	ClassMaven = "maven"
+	ClassGit   = "git"
+	ClassNode  = "node"
+	ClassTools = "tools"
+)
+
+// Status is the current usage of a class
+type Status struct {
+	Class   string `json:"class"`
+	Limit   int    `json:"limit"`
+	Running int    `json:"running"`
+	Waiting int    `json:"waiting"`
+}
+
+type pool struct {
+	slots   chan struct{}
+	waiting int
+}
+
+var (
+	mu    sync.Mutex
+	pools map[string]*pool
+)
+
+// Configure reads the limits from the environment. Without a call the defaults apply.
+func Configure() error {
+	limits := defaultLimits()
+	envs := map[string]string{ClassMaven: MaxMavenEnv, ClassGit: MaxGitEnv, ClassNode: MaxNodeEnv, ClassTools: MaxToolsEnv}
+	for class, env := range envs {
+		value := os.Getenv(env)
+		if value == "" {
+			continue
+		}
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return fmt.Errorf("invalid %s '%s', expected a positive number", env, value)
+		}
+		limits[class] = limit
+	}
+	configured := newPools(limits)
+	mu.Lock()
+	pools = configured
+	mu.Unlock()
+	return nil
+}
+
+func defaultLimits() map[string]int {
+	half := max(1, runtime.NumCPU()/2)
+	return map[string]int{ClassMaven: half, ClassGit: 2 * runtime.NumCPU(), ClassNode: half, ClassTools: half}
+}
+
+func newPools(limits map[string]int) map[string]*pool {
+	result := make(map[string]*pool, len(limits))
+	for class, limit := range limits {
+		result[class] = &pool{slots: make(chan struct{}, limit)}
+	}
+	return result
+}
+
+func poolFor(class string) *pool {
+	mu.Lock()
+	defer mu.Unlock()
+	if pools == nil {
+		pools = newPools(defaultLimits())
+	}
+	return pools[class]
+}
+
+// ClassOf returns the class of a command, looking through "cmd /C <tool>" wrappers on Windows
+func ClassOf(cmd *exec.Cmd) string {
+	args := cmd.Args
+	if len(args) == 0 {
+		args = []string{cmd.Path}
+	}
+	name := toolName(args[0])
+	if name == "cmd" && len(args) > 2 && strings.EqualFold(args[1], "/C") {
+		name = toolName(args[2])
+	}
+	switch name {
+	case "mvn", "mvnw":
+		return ClassMaven
+	case "git":
+		return ClassGit
+	case "npm", "npx", "yarn", "pnpm", "node", "corepack":
+		return ClassNode
+	}
+	return ClassTools
+}
+
+func toolName(path string) string {
+	name := strings.ToLower(filepath.Base(path))
+	for _, ext := range []string{".exe", ".cmd", ".bat"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name
+}
+
+// Acquire blocks until a process of the command's class may start; call release once it has exited
+func Acquire(cmd *exec.Cmd) (release func()) {
+	return AcquireClass(ClassOf(cmd), strings.Join(cmd.Args, " "))
+}
+
+// AcquireClass is like Acquire for callers that need the slot before building the command,
+// e.g. so a timeout only covers the run and not the wait. command is used for logging only.
+func AcquireClass(class, command string) (release func()) {
+	p := poolFor(class)
+	if p == nil {
+		p = poolFor(ClassTools)
+	}
+
+	select {
+	case p.slots <- struct{}{}:
+	default:
+		mu.Lock()
+		p.waiting++
+		mu.Unlock()
+		slog.Debug("Waiting for a free process slot", "class", class, "command", command)
+		p.slots <- struct{}{}
+		mu.Lock()
+		p.waiting--
+		mu.Unlock()
+	}
+
+	var once sync.Once
+	return func() { once.Do(func() { <-p.slots }) }
+}
+
+// Run runs cmd within the limit of its class
+func Run(cmd *exec.Cmd) error {
+	defer Acquire(cmd)()
+	return cmd.Run()
+}
+
+// Output runs cmd within the limit of its class and returns its stdout
+func Output(cmd *exec.Cmd) ([]byte, error) {
+	defer Acquire(cmd)()
+	return cmd.Output()
+}
+
+// CombinedOutput runs cmd within the limit of its class and returns stdout and stderr
+func CombinedOutput(cmd *exec.Cmd) ([]byte, error) {
+	defer Acquire(cmd)()
+	return cmd.CombinedOutput()
+}
+
+// Usage returns the limit and current usage of every class
+func Usage() []Status {
+	poolFor(ClassTools) // Make sure the pools exist
+	mu.Lock()
+	defer mu.Unlock()
+	result := make([]Status, 0, len(pools))
+	for class, p := range pools {
+		result = append(result, Status{Class: class, Limit: cap(p.slots), Running: len(p.slots), Waiting: p.waiting})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Class < result[j].Class })
+	return result
+}
@@ -0,0 +1,92 @@
+package cmdlimit
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// ===========================================
+// Tests for Process Classes
+// ===========================================
+
+func TestClassOf(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"mvn", "clean", "install"}, ClassMaven},
+		{[]string{"/repo/mvnw", "verify"}, ClassMaven},
+		{[]string{"cmd", "/C", "mvnw.cmd", "verify"}, ClassMaven},
+		{[]string{"git", "status"}, ClassGit},
+		{[]string{"pnpm", "audit"}, ClassNode},
+		{[]string{"corepack", "yarn", "npm", "audit"}, ClassNode},
+		{[]string{"trivy", "fs", "."}, ClassTools},
+	}
+	for _, tt := range tests {
+		cmd := exec.Command(tt.args[0], tt.args[1:]...)
+		if got := ClassOf(cmd); got != tt.expected {
+			t.Errorf("ClassOf(%v) = %s, expected %s", tt.args, got, tt.expected)
+		}
+	}
+}
+
+// ===========================================
+// Tests for Limits
+// ===========================================
+
+func TestAcquire_BlocksAtLimit(t *testing.T) {
+	t.Setenv(MaxMavenEnv, "1")
+	if err := Configure(); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+	t.Cleanup(func() {
+		mu.Lock()
+		pools = nil
+		mu.Unlock()
+	})
+
+	release := Acquire(exec.Command("mvn", "verify"))
+	acquired := make(chan func())
+	go func() { acquired <- Acquire(exec.Command("mvnw", "verify")) }()
+
+	select {
+	case <-acquired:
+		t.Fatal("Expected the second Maven process to wait")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if usage := usageOf(ClassMaven); usage.Running != 1 || usage.Waiting != 1 || usage.Limit != 1 {
+		t.Errorf("Unexpected usage: %+v", usage)
+	}
+
+	// Other classes are not affected
+	Acquire(exec.Command("git", "status"))()
+
+	release()
+	release() // Releasing twice must not free a second slot
+	select {
+	case second := <-acquired:
+		second()
+	case <-time.After(time.Second):
+		t.Fatal("Expected the waiting process to start after the release")
+	}
+	if usage := usageOf(ClassMaven); usage.Running != 0 || usage.Waiting != 0 {
+		t.Errorf("Expected all slots to be free, got %+v", usage)
+	}
+}
+
+func TestConfigure_InvalidLimit(t *testing.T) {
+	t.Setenv(MaxNodeEnv, "0")
+	if err := Configure(); err == nil {
+		t.Error("Expected an error for a limit below 1")
+	}
+}
+
+func usageOf(class string) Status {
+	for _, status := range Usage() {
+		if status.Class == class {
+			return status
+		}
+	}
+	return Status{}
+}
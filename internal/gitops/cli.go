@@ -0,0 +1,155 @@
+package gitops
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorecode/updates/internal/cmdlimit"
+)
+
+// cliRepo runs the git executable for every operation
+type cliRepo struct {
+	path string
+}
+
+// OpenCLI returns a git access that always uses the git executable
+func OpenCLI(path string) Repo {
+	return &cliRepo{path: path}
+}
+
+func (r *cliRepo) Run(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.path
+	output, err := cmdlimit.CombinedOutput(cmd)
+	if err != nil {
+		return "", fmt.Errorf("%s: %s", err, strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// output runs a read command and returns stdout only (stderr would corrupt parsed output)
+func (r *cliRepo) output(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.path
+	output, err := cmdlimit.Output(cmd)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(output), "\n"), nil
+}
+
+func (r *cliRepo) CurrentBranch() (string, error) {
+	branch, err := r.output("rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	if branch == "HEAD" {
+		return "", nil
+	}
+	return branch, nil
+}
+
+func (r *cliRepo) Head() (string, error) {
+	return r.output("rev-parse", "HEAD")
+}
+
+func (r *cliRepo) Branches() ([]Branch, error) {
+	output, err := r.output("for-each-ref", "--format=%(refname:short)|%(upstream:short)|%(upstream:track)", "refs/heads/")
+	if err != nil {
+		return nil, err
+	}
+	branches := []Branch{}
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+		branches = append(branches, parseBranchLine(line))
+	}
+	sort.Slice(branches, func(i, j int) bool { return branches[i].Name < branches[j].Name })
+	return branches, nil
+}
+
+// parseBranchLine parses "name|upstream|[ahead X, behind Y]" as printed by for-each-ref
+func parseBranchLine(line string) Branch {
+	parts := strings.Split(line, "|")
+	branch := Branch{Name: parts[0]}
+	if len(parts) > 1 {
+		branch.Upstream = parts[1]
+	}
+	if len(parts) > 2 {
+		track := strings.Trim(parts[2], "[]")
+		for _, item := range strings.Split(track, ", ") {
+			fields := strings.Fields(item)
+			switch {
+			case item == "gone":
+				branch.Gone = true
+			case len(fields) == 2 && fields[0] == "ahead":
+				branch.Ahead, _ = strconv.Atoi(fields[1])
+			case len(fields) == 2 && fields[0] == "behind":
+				branch.Behind, _ = strconv.Atoi(fields[1])
+			}
+		}
+	}
+	return branch
+}
+
+func (r *cliRepo) Tags() ([]Tag, error) {
+	output, err := r.output("for-each-ref", "--format=%(refname:short)|%(objectname)|%(*objectname)", "refs/tags/")
+	if err != nil {
+		return nil, err
+	}
+	tags := []Tag{}
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(line, "|")
+		if len(parts) != 3 {
+			continue
+		}
+		tag := Tag{Name: parts[0], Commit: parts[1]}
+		if parts[2] != "" {
+			tag.Commit = parts[2] // Annotated tag, use the peeled commit
+		}
+		if date, err := r.output("log", "-1", "--format=%ct", tag.Commit); err == nil {
+			if ts, err := strconv.ParseInt(date, 10, 64); err == nil {
+				tag.Date = time.Unix(ts, 0)
+			}
+		}
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags, nil
+}
+
+func (r *cliRepo) Status() ([]string, error) {
+	output, err := r.output("status", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	if output == "" {
+		return nil, nil
+	}
+	return strings.Split(output, "\n"), nil
+}
+
+func (r *cliRepo) Log(n int) ([]Commit, error) {
+	output, err := r.output("log", "-n", strconv.Itoa(n), "--format=%H%x1f%an%x1f%ae%x1f%ct%x1f%s")
+	if err != nil {
+		return nil, err
+	}
+	commits := []Commit{}
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(line, "\x1f")
+		if len(parts) != 5 {
+			continue
+		}
+		commit := Commit{Hash: parts[0], Author: parts[1], Email: parts[2], Subject: parts[4]}
+		if ts, err := strconv.ParseInt(parts[3], 10, 64); err == nil {
+			commit.Date = time.Unix(ts, 0)
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
@@ -0,0 +1,126 @@
+// Package gitops abstracts the git access of GitHousekeeper. Ref reads (HEAD, branches, tags, log)
+// are served in-process by go-git, so they work without spawning a git process per repo and even
+// without git installed; everything else, and every read go-git cannot handle (e.g. repository
+// extensions it does not support), falls back to the git CLI. The working tree status is the
+// exception: go-git hashes every tracked file without the index stat cache, so on large trees the
+// CLI is many times faster and go-git only answers when the CLI is missing.
+package gitops
+
+import (
+	"os/exec"
+	"time"
+)
+
+// Branch is a local branch with its upstream tracking state
+type Branch struct {
+	Name     string `json:"name"`
+	Upstream string `json:"upstream"` // Short name, e.g. "origin/main" ("" = not tracking)
+	Ahead    int    `json:"ahead"`
+	Behind   int    `json:"behind"`
+	Gone     bool   `json:"gone"` // The upstream branch was deleted on the remote
+}
+
+// Tag is a tag with the date of the commit it points to
+type Tag struct {
+	Name   string    `json:"name"`
+	Commit string    `json:"commit"`
+	Date   time.Time `json:"date"`
+}
+
+// Commit is one entry of the log
+type Commit struct {
+	Hash    string    `json:"hash"`
+	Author  string    `json:"author"`
+	Email   string    `json:"email"`
+	Date    time.Time `json:"date"` // Committer date
+	Subject string    `json:"subject"`
+}
+
+// Repo is the git access to one repository
+type Repo interface {
+	// CurrentBranch returns the checked-out branch, "" for a detached HEAD
+	CurrentBranch() (string, error)
+	// Head returns the SHA of HEAD
+	Head() (string, error)
+	// Branches lists the local branches, sorted by name
+	Branches() ([]Branch, error)
+	// Tags lists all tags, sorted by name
+	Tags() ([]Tag, error)
+	// Status returns the working tree changes in 'git status --porcelain' format (untracked files included)
+	Status() ([]string, error)
+	// Log returns the newest n commits reachable from HEAD
+	Log(n int) ([]Commit, error)
+	// Run executes any other git command in the repository and returns its trimmed output
+	Run(args ...string) (string, error)
+}
+
+// Open returns the git access for the repository at path: go-git for reads with the CLI as fallback,
+// or the CLI alone if go-git cannot open the repository
+func Open(path string) Repo {
+	cli := OpenCLI(path)
+	goGit, err := openGoGit(path)
+	if err != nil {
+		return cli
+	}
+	return &fallbackRepo{primary: goGit, cli: cli}
+}
+
+// CLIAvailable reports whether the git executable is installed (needed for write operations)
+func CLIAvailable() bool {
+	_, err := exec.LookPath("git")
+	return err == nil
+}
+
+// fallbackRepo answers reads with go-git and retries them with the CLI if go-git fails (status the other way round)
+type fallbackRepo struct {
+	primary Repo
+	cli     Repo
+}
+
+func (r *fallbackRepo) CurrentBranch() (string, error) {
+	if branch, err := r.primary.CurrentBranch(); err == nil {
+		return branch, nil
+	}
+	return r.cli.CurrentBranch()
+}
+
+func (r *fallbackRepo) Head() (string, error) {
+	if head, err := r.primary.Head(); err == nil {
+		return head, nil
+	}
+	return r.cli.Head()
+}
+
+func (r *fallbackRepo) Branches() ([]Branch, error) {
+	if branches, err := r.primary.Branches(); err == nil {
+		return branches, nil
+	}
+	return r.cli.Branches()
+}
+
+func (r *fallbackRepo) Tags() ([]Tag, error) {
+	if tags, err := r.primary.Tags(); err == nil {
+		return tags, nil
+	}
+	return r.cli.Tags()
+}
+
+// Status asks the CLI first, see the package comment
+func (r *fallbackRepo) Status() ([]string, error) {
+	status, err := r.cli.Status()
+	if err == nil || CLIAvailable() {
+		return status, err
+	}
+	return r.primary.Status()
+}
+
+func (r *fallbackRepo) Log(n int) ([]Commit, error) {
+	if commits, err := r.primary.Log(n); err == nil {
+		return commits, nil
+	}
+	return r.cli.Log(n)
+}
+
+func (r *fallbackRepo) Run(args ...string) (string, error) {
+	return r.cli.Run(args...)
+}
@@ -0,0 +1,282 @@
+package gitops
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// ===========================================
+// Helpers
+// ===========================================
+
+var testSignature = object.Signature{Name: "Test User", Email: "test@test.com", When: time.Unix(1700000000, 0)}
+
+func commitFile(t *testing.T, repo *git.Repository, name, content string, offset time.Duration) plumbing.Hash {
+	t.Helper()
+	worktree, _ := repo.Worktree()
+	file, _ := worktree.Filesystem.Create(name)
+	file.Write([]byte(content))
+	file.Close()
+	worktree.Add(name)
+	sig := testSignature
+	sig.When = sig.When.Add(offset)
+	hash, err := worktree.Commit("Update "+name, &git.CommitOptions{Author: &sig, Committer: &sig})
+	if err != nil {
+		t.Fatalf("commit failed: %v", err)
+	}
+	return hash
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=Test User", "GIT_AUTHOR_EMAIL=test@test.com", "GIT_COMMITTER_NAME=Test User", "GIT_COMMITTER_EMAIL=test@test.com")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, output)
+	}
+}
+
+// ===========================================
+// Tests for the go-git Implementation
+// ===========================================
+
+func TestGoGitRepo_InMemory(t *testing.T) {
+	repo, err := git.Init(memory.NewStorage(), memfs.New())
+	if err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	base := commitFile(t, repo, "file.txt", "v1", 0)
+
+	// origin/main is two commits ahead of base, local main one commit ahead
+	remoteRef := plumbing.NewRemoteReferenceName("origin", "master")
+	repo.Storer.SetReference(plumbing.NewHashReference(remoteRef, base))
+	worktree, _ := repo.Worktree()
+	worktree.Checkout(&git.CheckoutOptions{Branch: "refs/heads/upstream", Create: true, Hash: base})
+	commitFile(t, repo, "remote.txt", "1", time.Minute)
+	upstream := commitFile(t, repo, "remote.txt", "2", 2*time.Minute)
+	repo.Storer.SetReference(plumbing.NewHashReference(remoteRef, upstream))
+	worktree.Checkout(&git.CheckoutOptions{Branch: "refs/heads/master"})
+	repo.Storer.RemoveReference("refs/heads/upstream")
+	commitFile(t, repo, "local.txt", "1", 3*time.Minute)
+
+	cfg, _ := repo.Config()
+	cfg.Branches["master"] = &config.Branch{Name: "master", Remote: "origin", Merge: "refs/heads/master"}
+	repo.SetConfig(cfg)
+
+	if _, err := repo.CreateTag("v1.0.0", base, &git.CreateTagOptions{Tagger: &testSignature, Message: "Release"}); err != nil {
+		t.Fatalf("tag failed: %v", err)
+	}
+
+	r := FromGoGit(repo)
+	if branch, _ := r.CurrentBranch(); branch != "master" {
+		t.Errorf("Expected master, got '%s'", branch)
+	}
+
+	branches, err := r.Branches()
+	if err != nil {
+		t.Fatalf("Branches failed: %v", err)
+	}
+	expected := []Branch{{Name: "master", Upstream: "origin/master", Ahead: 1, Behind: 2}}
+	if !reflect.DeepEqual(branches, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, branches)
+	}
+
+	tags, err := r.Tags()
+	if err != nil || len(tags) != 1 || tags[0].Name != "v1.0.0" || tags[0].Commit != base.String() {
+		t.Errorf("Expected annotated tag peeled to the base commit, got %+v (%v)", tags, err)
+	}
+
+	commits, err := r.Log(2)
+	if err != nil || len(commits) != 2 || commits[0].Subject != "Update local.txt" || commits[0].Author != "Test User" {
+		t.Errorf("Unexpected log: %+v (%v)", commits, err)
+	}
+
+	if status, err := r.Status(); err != nil || len(status) != 0 {
+		t.Errorf("Expected a clean worktree, got %v (%v)", status, err)
+	}
+	file, _ := worktree.Filesystem.Create("new.txt")
+	file.Close()
+	if status, _ := r.Status(); !reflect.DeepEqual(status, []string{"?? new.txt"}) {
+		t.Errorf("Expected the untracked file, got %v", status)
+	}
+
+	if _, err := r.Run("fetch"); err == nil {
+		t.Error("Expected Run to be unsupported without the CLI")
+	}
+}
+
+// ===========================================
+// Tests for CLI Parity
+// ===========================================
+
+func TestOpen_MatchesCLI(t *testing.T) {
+	if !CLIAvailable() {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	origin := filepath.Join(dir, "origin.git")
+	repoPath := filepath.Join(dir, "repo")
+	runGit(t, dir, "init", "--bare", "-b", "main", origin)
+	runGit(t, dir, "clone", origin, repoPath)
+	os.WriteFile(filepath.Join(repoPath, "file.txt"), []byte("v1"), 0644)
+	runGit(t, repoPath, "add", "-A")
+	runGit(t, repoPath, "commit", "-m", "Initial commit")
+	runGit(t, repoPath, "push", "-u", "origin", "main")
+	runGit(t, repoPath, "tag", "-a", "v1.0.0", "-m", "Release")
+	runGit(t, repoPath, "tag", "light")
+	os.WriteFile(filepath.Join(repoPath, "file.txt"), []byte("v2"), 0644)
+	runGit(t, repoPath, "commit", "-am", "Second commit\n\nBody")
+	runGit(t, repoPath, "branch", "feature")
+	os.WriteFile(filepath.Join(repoPath, "file.txt"), []byte("dirty"), 0644)
+	os.WriteFile(filepath.Join(repoPath, "new.txt"), []byte("new"), 0644)
+
+	goGit, cli := Open(repoPath), OpenCLI(repoPath)
+	if _, ok := goGit.(*fallbackRepo); !ok {
+		t.Fatalf("Expected go-git to open the repository, got %T", goGit)
+	}
+
+	check := func(name string, fn func(Repo) (interface{}, error)) {
+		fromGoGit, err1 := fn(goGit)
+		fromCLI, err2 := fn(cli)
+		if err1 != nil || err2 != nil {
+			t.Fatalf("%s failed: %v / %v", name, err1, err2)
+		}
+		if !reflect.DeepEqual(fromGoGit, fromCLI) {
+			t.Errorf("%s differs:\ngo-git: %+v\ncli:    %+v", name, fromGoGit, fromCLI)
+		}
+	}
+	check("CurrentBranch", func(r Repo) (interface{}, error) { return r.CurrentBranch() })
+	check("Head", func(r Repo) (interface{}, error) { return r.Head() })
+	check("Branches", func(r Repo) (interface{}, error) { return r.Branches() })
+	check("Status", func(r Repo) (interface{}, error) { return r.Status() })
+	check("Tags", func(r Repo) (interface{}, error) {
+		tags, err := r.Tags()
+		for i := range tags {
+			tags[i].Date = tags[i].Date.UTC()
+		}
+		return tags, err
+	})
+	check("Log", func(r Repo) (interface{}, error) {
+		commits, err := r.Log(5)
+		for i := range commits {
+			commits[i].Date = commits[i].Date.UTC()
+		}
+		return commits, err
+	})
+}
+
+func TestStatus_GlobalExcludes(t *testing.T) {
+	if !CLIAvailable() {
+		t.Skip("git not installed")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	os.MkdirAll(filepath.Join(home, ".config", "git"), 0755)
+	os.WriteFile(filepath.Join(home, ".config", "git", "ignore"), []byte("# editor files\n*.log\n"), 0644)
+
+	repoPath := filepath.Join(t.TempDir(), "repo")
+	runGit(t, filepath.Dir(repoPath), "init", "-b", "main", repoPath)
+	os.WriteFile(filepath.Join(repoPath, "debug.log"), []byte("log"), 0644)
+	os.WriteFile(filepath.Join(repoPath, "notes.tmp"), []byte("tmp"), 0644)
+	os.WriteFile(filepath.Join(repoPath, "new.txt"), []byte("new"), 0644)
+
+	// Open serves the status from the CLI, the go-git fallback applies the global excludes itself
+	goGit, err := openGoGit(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"?? new.txt", "?? notes.tmp"}
+	if status, err := goGit.Status(); err != nil || !reflect.DeepEqual(status, expected) {
+		t.Errorf("Expected %v, got %v (%v)", expected, status, err)
+	}
+
+	// core.excludesFile takes precedence over the default location
+	os.WriteFile(filepath.Join(home, ".gitconfig"), []byte("[core]\n\texcludesFile = ~/ignore\n"), 0644)
+	os.WriteFile(filepath.Join(home, "ignore"), []byte("*.tmp\n"), 0644)
+	expected = []string{"?? debug.log", "?? new.txt"}
+	for _, repo := range []Repo{goGit, Open(repoPath)} {
+		if status, err := repo.Status(); err != nil || !reflect.DeepEqual(status, expected) {
+			t.Errorf("%T: expected %v, got %v (%v)", repo, expected, status, err)
+		}
+	}
+}
+
+// ===========================================
+// Benchmarks
+// ===========================================
+
+// benchmarkRepo creates a repository with many tracked files and one modified file
+func benchmarkRepo(b *testing.B, files int) string {
+	b.Helper()
+	if !CLIAvailable() {
+		b.Skip("git not installed")
+	}
+	repoPath := filepath.Join(b.TempDir(), "repo")
+	for i := 0; i < files; i++ {
+		path := filepath.Join(repoPath, fmt.Sprintf("dir%03d", i%100), fmt.Sprintf("file%05d.txt", i))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(fmt.Sprintf("content %d\n", i)), 0644)
+	}
+	for _, args := range [][]string{{"init", "-q", "-b", "main"}, {"add", "-A"}, {"-c", "user.name=Test User", "-c", "user.email=test@test.com", "commit", "-qm", "Initial commit"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoPath
+		if output, err := cmd.CombinedOutput(); err != nil {
+			b.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	os.WriteFile(filepath.Join(repoPath, "dir000", "file00000.txt"), []byte("changed\n"), 0644)
+	return repoPath
+}
+
+// BenchmarkStatus compares go-git with the CLI on a 30k file tree (go test -bench Status ./internal/gitops)
+func BenchmarkStatus(b *testing.B) {
+	repoPath := benchmarkRepo(b, 30000)
+	goGit, err := openGoGit(repoPath)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for name, repo := range map[string]Repo{"go-git": goGit, "cli": OpenCLI(repoPath), "open": Open(repoPath)} {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if status, err := repo.Status(); err != nil || len(status) != 1 {
+					b.Fatalf("unexpected status %v (%v)", status, err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkHead shows where go-git pays off: ref reads without a process per call
+func BenchmarkHead(b *testing.B) {
+	repoPath := benchmarkRepo(b, 10)
+	goGit, err := openGoGit(repoPath)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for name, repo := range map[string]Repo{"go-git": goGit, "cli": OpenCLI(repoPath)} {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.Head(); err != nil {
+					b.Fatal(err)
+				}
+				if _, err := repo.CurrentBranch(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package gitops
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// goGitRepo serves reads in-process. Run is not supported, use it behind fallbackRepo.
+type goGitRepo struct {
+	repo *git.Repository
+}
+
+func openGoGit(path string) (*goGitRepo, error) {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		return nil, err
+	}
+	return &goGitRepo{repo: repo}, nil
+}
+
+// FromGoGit wraps an already opened go-git repository, e.g. an in-memory repository in tests
+func FromGoGit(repo *git.Repository) Repo {
+	return &goGitRepo{repo: repo}
+}
+
+func (r *goGitRepo) Run(args ...string) (string, error) {
+	return "", fmt.Errorf("git %s: not supported without the git CLI", strings.Join(args, " "))
+}
+
+func (r *goGitRepo) CurrentBranch() (string, error) {
+	head, err := r.repo.Head()
+	if err != nil {
+		return "", err
+	}
+	if !head.Name().IsBranch() {
+		return "", nil
+	}
+	return head.Name().Short(), nil
+}
+
+func (r *goGitRepo) Head() (string, error) {
+	head, err := r.repo.Head()
+	if err != nil {
+		return "", err
+	}
+	return head.Hash().String(), nil
+}
+
+func (r *goGitRepo) Branches() ([]Branch, error) {
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return nil, err
+	}
+	refs, err := r.repo.Branches()
+	if err != nil {
+		return nil, err
+	}
+
+	branches := []Branch{}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		branch := Branch{Name: ref.Name().Short()}
+		tracking, ok := cfg.Branches[branch.Name]
+		if !ok || tracking.Remote == "" || tracking.Merge == "" {
+			branches = append(branches, branch)
+			return nil
+		}
+
+		if tracking.Remote == "." {
+			branch.Upstream = tracking.Merge.Short()
+		} else {
+			branch.Upstream = tracking.Remote + "/" + tracking.Merge.Short()
+		}
+		upstream, err := r.repo.Reference(plumbing.NewRemoteReferenceName(tracking.Remote, tracking.Merge.Short()), true)
+		if tracking.Remote == "." {
+			upstream, err = r.repo.Reference(tracking.Merge, true)
+		}
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			branch.Gone = true
+		} else if err != nil {
+			return err
+		} else if branch.Ahead, branch.Behind, err = r.aheadBehind(ref.Hash(), upstream.Hash()); err != nil {
+			return err
+		}
+		branches = append(branches, branch)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(branches, func(i, j int) bool { return branches[i].Name < branches[j].Name })
+	return branches, nil
+}
+
+func (r *goGitRepo) Tags() ([]Tag, error) {
+	refs, err := r.repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+	tags := []Tag{}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		tag := Tag{Name: ref.Name().Short(), Commit: ref.Hash().String()}
+		commit, err := r.peel(ref.Hash())
+		if err != nil {
+			return err
+		}
+		if commit != nil {
+			tag.Commit = commit.Hash.String()
+			tag.Date = commit.Committer.When
+		}
+		tags = append(tags, tag)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags, nil
+}
+
+// peel resolves annotated tags to the commit they point to (nil for tags of trees or blobs)
+func (r *goGitRepo) peel(hash plumbing.Hash) (*object.Commit, error) {
+	for {
+		obj, err := r.repo.Object(plumbing.AnyObject, hash)
+		if err != nil {
+			return nil, err
+		}
+		switch o := obj.(type) {
+		case *object.Commit:
+			return o, nil
+		case *object.Tag:
+			hash = o.Target
+		default:
+			return nil, nil
+		}
+	}
+}
+
+func (r *goGitRepo) Status() ([]string, error) {
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	worktree.Excludes = append(worktree.Excludes, globalExcludes()...)
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for path, file := range status {
+		if file.Staging == git.Unmodified && file.Worktree == git.Unmodified {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%c%c %s", file.Staging, file.Worktree, path))
+	}
+	// git lists changes by path
+	sort.Slice(lines, func(i, j int) bool { return lines[i][3:] < lines[j][3:] })
+	return lines, nil
+}
+
+// globalExcludes reads the user's global ignore file, which go-git does not apply by itself: core.excludesFile
+// of the global git config, else $XDG_CONFIG_HOME/git/ignore (~/.config/git/ignore)
+func globalExcludes() []gitignore.Pattern {
+	var path string
+	if cfg, err := config.LoadConfig(config.GlobalScope); err == nil {
+		path = cfg.Raw.Section("core").Option("excludesfile")
+	}
+	home, _ := os.UserHomeDir()
+	switch {
+	case strings.HasPrefix(path, "~/") && home != "":
+		path = filepath.Join(home, path[2:])
+	case path == "" && os.Getenv("XDG_CONFIG_HOME") != "":
+		path = filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "git", "ignore")
+	case path == "" && home != "":
+		path = filepath.Join(home, ".config", "git", "ignore")
+	}
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var patterns []gitignore.Pattern
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, gitignore.ParsePattern(line, nil))
+	}
+	return patterns
+}
+
+func (r *goGitRepo) Log(n int) ([]Commit, error) {
+	iter, err := r.repo.Log(&git.LogOptions{Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	commits := []Commit{}
+	err = iter.ForEach(func(c *object.Commit) error {
+		if len(commits) == n {
+			return storer.ErrStop
+		}
+		subject, _, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+		commits = append(commits, Commit{
+			Hash:    c.Hash.String(),
+			Author:  c.Author.Name,
+			Email:   c.Author.Email,
+			Date:    c.Committer.When,
+			Subject: strings.TrimSpace(subject),
+		})
+		return nil
+	})
+	return commits, err
+}
+
+// Flags of the ahead/behind walk
+const (
+	reachLocal    = 1
+	reachUpstream = 2
+	reachBoth     = reachLocal | reachUpstream
+)
+
+// aheadBehind counts the commits only reachable from local (ahead) and only from upstream (behind).
+// Like git it walks both histories newest first and stops once only common commits are left,
+// so the cost depends on the divergence, not on the size of the history.
+func (r *goGitRepo) aheadBehind(local, upstream plumbing.Hash) (int, int, error) {
+	if local == upstream {
+		return 0, 0, nil
+	}
+
+	flags := make(map[plumbing.Hash]int)
+	queue := &commitQueue{}
+	push := func(hash plumbing.Hash, flag int) error {
+		if flags[hash]|flag == flags[hash] {
+			return nil
+		}
+		commit, err := r.repo.CommitObject(hash)
+		if err != nil {
+			return err
+		}
+		flags[hash] |= flag
+		heap.Push(queue, commit)
+		return nil
+	}
+	if err := push(local, reachLocal); err != nil {
+		return 0, 0, err
+	}
+	if err := push(upstream, reachUpstream); err != nil {
+		return 0, 0, err
+	}
+
+	for queue.Len() > 0 && !queue.allCommon(flags) {
+		commit := heap.Pop(queue).(*object.Commit)
+		for _, parent := range commit.ParentHashes {
+			if err := push(parent, flags[commit.Hash]); err != nil {
+				// Shallow clones end in missing parents, count what is there
+				if errors.Is(err, plumbing.ErrObjectNotFound) {
+					continue
+				}
+				return 0, 0, err
+			}
+		}
+	}
+
+	ahead, behind := 0, 0
+	for _, flag := range flags {
+		switch flag {
+		case reachLocal:
+			ahead++
+		case reachUpstream:
+			behind++
+		}
+	}
+	return ahead, behind, nil
+}
+
+// commitQueue is a max-heap of commits by committer date
+type commitQueue []*object.Commit
+
+func (q commitQueue) Len() int           { return len(q) }
+func (q commitQueue) Less(i, j int) bool { return q[i].Committer.When.After(q[j].Committer.When) }
+func (q commitQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *commitQueue) Push(x any)        { *q = append(*q, x.(*object.Commit)) }
+func (q *commitQueue) Pop() any {
+	old := *q
+	commit := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return commit
+}
+
+// allCommon reports whether every queued commit is reachable from both sides
+func (q commitQueue) allCommon(flags map[plumbing.Hash]int) bool {
+	for _, commit := range q {
+		if flags[commit.Hash] != reachBoth {
+			return false
+		}
+	}
+	return true
+}
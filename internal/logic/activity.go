@@ -0,0 +1,88 @@
+package logic
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// abandonedAfterDays without any commit marks a repo as a candidate for archiving
+const abandonedAfterDays = 365
+
+// GitActivity summarizes the commit history of a repo
+type GitActivity struct {
+	Commits90d       int    `json:"commits90d"`       // Non-merge commits in the last 90 days
+	Commits365d      int    `json:"commits365d"`      // Non-merge commits in the last year
+	Contributors     int    `json:"contributors"`     // Distinct authors (by e-mail) of all time
+	Contributors365d int    `json:"contributors365d"` // Distinct authors of the last year
+	LastRelease      string `json:"lastRelease,omitempty"`
+	LastReleaseDate  string `json:"lastReleaseDate,omitempty"`
+	LastReleaseDays  int    `json:"lastReleaseDays"` // Days since the newest tag, -1 without tags
+	Abandoned        bool   `json:"abandoned"`       // No commit for abandonedAfterDays
+}
+
+// AnalyzeGitActivity reads commit frequency, contributors and the newest tag with the git CLI
+func AnalyzeGitActivity(repoPath string, now time.Time) (GitActivity, error) {
+	activity := GitActivity{LastReleaseDays: -1}
+
+	since := now.AddDate(0, 0, -365).Format(time.RFC3339)
+	out, err := gitOutput(repoPath, "log", "--no-merges", "--since="+since, "--format=%ct%x09%aE")
+	if err != nil {
+		return activity, err
+	}
+	activity.Commits90d, activity.Commits365d, activity.Contributors365d = parseActivityLog(out, now)
+	activity.Abandoned = activity.Commits365d == 0
+
+	// shortlog needs an explicit revision when stdin is not a terminal
+	if out, err := gitOutput(repoPath, "shortlog", "-sne", "HEAD"); err == nil {
+		activity.Contributors = countShortlogAuthors(out)
+	}
+
+	out, err = gitOutput(repoPath, "for-each-ref", "--sort=-creatordate", "--count=1", "--format=%(refname:short)%09%(creatordate:unix)", "refs/tags")
+	if err == nil && out != "" {
+		name, stamp, _ := strings.Cut(out, "\t")
+		if seconds, err := strconv.ParseInt(stamp, 10, 64); err == nil {
+			released := time.Unix(seconds, 0)
+			activity.LastRelease = name
+			activity.LastReleaseDate = released.Format("2006-01-02")
+			activity.LastReleaseDays = int(now.Sub(released).Hours() / 24)
+		}
+	}
+	return activity, nil
+}
+
+// parseActivityLog counts the commits of the last 90 and 365 days and the distinct authors
+// in "<unix time>\t<author e-mail>" lines
+func parseActivityLog(out string, now time.Time) (commits90d, commits365d, authors int) {
+	seen := make(map[string]bool)
+	cutoff90 := now.AddDate(0, 0, -90).Unix()
+	cutoff365 := now.AddDate(0, 0, -365).Unix()
+	for _, line := range strings.Split(out, "\n") {
+		stamp, email, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok {
+			continue
+		}
+		seconds, err := strconv.ParseInt(stamp, 10, 64)
+		if err != nil || seconds < cutoff365 {
+			continue
+		}
+		commits365d++
+		if seconds >= cutoff90 {
+			commits90d++
+		}
+		seen[strings.ToLower(email)] = true
+	}
+	return commits90d, commits365d, len(seen)
+}
+
+// countShortlogAuthors counts the distinct e-mails of "git shortlog -sne" output ("  12\tName <mail>")
+func countShortlogAuthors(out string) int {
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(out, "\n") {
+		start, end := strings.LastIndex(line, "<"), strings.LastIndex(line, ">")
+		if start >= 0 && end > start {
+			seen[strings.ToLower(line[start+1:end])] = true
+		}
+	}
+	return len(seen)
+}
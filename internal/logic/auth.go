@@ -0,0 +1,276 @@
+package logic
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Environment variables enabling authentication (any of them turns it on)
+const (
+	AuthTokenEnv     = "GITHOUSEKEEPER_AUTH_TOKEN"     // Token with full access
+	ReadOnlyTokenEnv = "GITHOUSEKEEPER_READONLY_TOKEN" // Token that can only read
+	UsersFileEnv     = "GITHOUSEKEEPER_USERS_FILE"     // JSON file with named users for basic auth
+)
+
+// ListenAddrEnv sets the address the server listens on, default localhost:8080
+const ListenAddrEnv = "GITHOUSEKEEPER_ADDR"
+
+// Roles of authenticated users
+const (
+	RoleAdmin    = "admin"
+	RoleReadOnly = "readonly"
+)
+
+const (
+	sessionCookie = "githousekeeper_session"
+	sessionTTL    = 12 * time.Hour
+)
+
+// AuthUser is an entry of the users file. The password is stored as bcrypt hash, e.g. created with:
+// htpasswd -nbBC 12 "" 'secret' | tr -d ':\n'. Users with the SHA-256 hex digest of earlier versions
+// still log in; their digest is replaced by a bcrypt hash on the next login.
+type AuthUser struct {
+	Name           string `json:"name"`
+	PasswordHash   string `json:"passwordHash,omitempty"`
+	PasswordSHA256 string `json:"passwordSha256,omitempty"` // Deprecated: migrated to PasswordHash
+	Role           string `json:"role"`                     // RoleAdmin (default) or RoleReadOnly
+}
+
+// Session is a browser session; state-changing requests must echo its CSRF token
+type Session struct {
+	ID        string    `json:"-"`
+	User      string    `json:"user"`
+	Role      string    `json:"role"`
+	CSRFToken string    `json:"csrfToken"`
+	Expires   time.Time `json:"expires"`
+}
+
+// Authenticator checks the credentials of API requests
+type Authenticator struct {
+	tokens map[string]string // SHA-256 of the token -> role
+
+	usersMu   sync.Mutex
+	users     []AuthUser
+	usersFile string          // Rewritten when a SHA-256 digest is migrated to bcrypt
+	verified  map[string]bool // Credentials bcrypt accepted already, so not every request pays for it
+
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+// AuthenticatorFromEnv configures authentication from the GITHOUSEKEEPER_* variables.
+// Without any of them authentication is disabled.
+func AuthenticatorFromEnv() (*Authenticator, error) {
+	a := &Authenticator{tokens: make(map[string]string), verified: make(map[string]bool), sessions: make(map[string]*Session)}
+	if token := os.Getenv(AuthTokenEnv); token != "" {
+		a.tokens[sha256Hex(token)] = RoleAdmin
+	}
+	if token := os.Getenv(ReadOnlyTokenEnv); token != "" {
+		a.tokens[sha256Hex(token)] = RoleReadOnly
+	}
+	if path := os.Getenv(UsersFileEnv); path != "" {
+		if err := readJSONFile(path, &a.users); err != nil {
+			return nil, fmt.Errorf("users file: %v", err)
+		}
+		a.usersFile = path
+		for i, user := range a.users {
+			if user.Role == "" {
+				a.users[i].Role = RoleAdmin
+			}
+			switch {
+			case user.Name == "":
+				return nil, fmt.Errorf("users file: user %d needs a name", i+1)
+			case user.PasswordHash != "":
+				if _, err := bcrypt.Cost([]byte(user.PasswordHash)); err != nil {
+					return nil, fmt.Errorf("users file: passwordHash of user %s is no bcrypt hash: %v", user.Name, err)
+				}
+			case len(user.PasswordSHA256) != 64:
+				return nil, fmt.Errorf("users file: user %s needs a passwordHash (bcrypt)", user.Name)
+			}
+			if a.users[i].Role != RoleAdmin && a.users[i].Role != RoleReadOnly {
+				return nil, fmt.Errorf("users file: unknown role '%s' of user %s", user.Role, user.Name)
+			}
+		}
+	}
+	return a, nil
+}
+
+// Enabled reports whether requests must be authenticated
+func (a *Authenticator) Enabled() bool {
+	return a != nil && (len(a.tokens) > 0 || len(a.users) > 0)
+}
+
+func sha256Hex(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// Authenticate checks a bearer token or basic auth credentials (user from the users file,
+// or any user name with a token as password). bearer is true for token requests without a browser session.
+func (a *Authenticator) Authenticate(r *http.Request) (user, role string, bearer bool, ok bool) {
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		role, ok := a.tokenRole(strings.TrimPrefix(header, "Bearer "))
+		return "token", role, true, ok
+	}
+	name, password, hasBasic := r.BasicAuth()
+	if !hasBasic {
+		return "", "", false, false
+	}
+	if u, ok := a.checkUser(name, password); ok {
+		return u.Name, u.Role, false, true
+	}
+	if role, ok := a.tokenRole(password); ok {
+		if name == "" {
+			name = "token"
+		}
+		return name, role, false, true
+	}
+	return "", "", false, false
+}
+
+// checkUser verifies the password of a user of the users file. A SHA-256 digest of earlier versions is
+// replaced by a bcrypt hash on the first successful login and the users file is rewritten.
+func (a *Authenticator) checkUser(name, password string) (AuthUser, bool) {
+	a.usersMu.Lock()
+	defer a.usersMu.Unlock()
+	for i, u := range a.users {
+		if !secureEqual(u.Name, name) {
+			continue
+		}
+		if u.PasswordHash != "" {
+			key := sha256Hex(u.PasswordHash + "\x00" + password)
+			if a.verified[key] {
+				return u, true
+			}
+			if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) != nil {
+				continue
+			}
+			a.verified[key] = true
+			return u, true
+		}
+		if secureEqual(strings.ToLower(u.PasswordSHA256), sha256Hex(password)) {
+			a.migrateUser(i, password)
+			return a.users[i], true
+		}
+	}
+	return AuthUser{}, false
+}
+
+// migrateUser replaces the SHA-256 digest of a user by a bcrypt hash of the password and saves the users
+// file. If it cannot be saved the user stays migrated until the server restarts.
+func (a *Authenticator) migrateUser(i int, password string) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		slog.Warn("Could not hash password", "user", a.users[i].Name, "error", err)
+		return
+	}
+	a.users[i].PasswordHash, a.users[i].PasswordSHA256 = string(hash), ""
+	a.verified[sha256Hex(string(hash)+"\x00"+password)] = true
+	if err := saveUsersFile(a.usersFile, a.users); err != nil {
+		slog.Warn("Could not save the users file, password stays a SHA-256 digest on disk", "user", a.users[i].Name, "error", err)
+		return
+	}
+	slog.Info("Password migrated to bcrypt", "user", a.users[i].Name)
+}
+
+// saveUsersFile replaces the users file, keeping its permissions
+func saveUsersFile(path string, users []AuthUser) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(users, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (a *Authenticator) tokenRole(token string) (string, bool) {
+	digest := sha256Hex(token)
+	for stored, role := range a.tokens {
+		if secureEqual(stored, digest) {
+			return role, true
+		}
+	}
+	return "", false
+}
+
+// Session returns the browser session of the request, creating one (and its cookie) if needed.
+// A session belongs to one user; a different login starts a new session.
+func (a *Authenticator) Session(w http.ResponseWriter, r *http.Request, user, role string) *Session {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	for id, s := range a.sessions {
+		if now.After(s.Expires) {
+			delete(a.sessions, id)
+		}
+	}
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		if s, ok := a.sessions[cookie.Value]; ok && s.User == user && s.Role == role {
+			return s
+		}
+	}
+
+	s := &Session{ID: randomToken(), User: user, Role: role, CSRFToken: randomToken(), Expires: now.Add(sessionTTL)}
+	a.sessions[s.ID] = s
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    s.ID,
+		Path:     "/",
+		Expires:  s.Expires,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+	return s
+}
+
+// ValidCSRF reports whether token is the CSRF token of the session
+func (s *Session) ValidCSRF(token string) bool {
+	return token != "" && secureEqual(token, s.CSRFToken)
+}
+
+func randomToken() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// IsLoopbackAddr reports whether a listen address (host:port) only accepts local connections
+func IsLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil || host == "" {
+		return false // ":8080" listens on all interfaces
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,237 @@
+package logic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Issues of a base image
+const (
+	BaseImageLatest = "latest" // No tag, or "latest": the image changes without notice
+	BaseImageEOL    = "eol"    // Deprecated image or end-of-life version
+)
+
+// BaseImage is an external image referenced by FROM in a Dockerfile of a repo
+type BaseImage struct {
+	File       string `json:"file"`                 // Dockerfile, relative to the repo
+	Image      string `json:"image"`                // Reference as written (ARG defaults substituted)
+	Issue      string `json:"issue,omitempty"`      // BaseImageLatest, BaseImageEOL or "" if fine
+	Detail     string `json:"detail,omitempty"`     // Explanation of the issue
+	Suggestion string `json:"suggestion,omitempty"` // Supported replacement, if one is known
+}
+
+// minSupportedImages are the oldest supported release lines of common official images (as of October 2026);
+// tags starting with an older version are end-of-life
+var minSupportedImages = map[string]string{
+	"node":   "22",
+	"python": "3.10",
+	"golang": "1.26",
+	"php":    "8.2",
+	"ruby":   "3.3",
+	"alpine": "3.21",
+	"ubuntu": "22.04",
+	"debian": "12",
+}
+
+// deprecatedImages are official images that are no longer maintained, with their successor
+// (a successor with tag is suggested as is, otherwise the version of the old tag is kept)
+var deprecatedImages = map[string]string{
+	"openjdk": "eclipse-temurin",
+	"java":    "eclipse-temurin",
+	"centos":  "rockylinux:9",
+}
+
+// eolCodenames are end-of-life Debian and Ubuntu releases used as tags or tag variants, with their successor
+var eolCodenames = map[string]string{
+	"jessie": "bookworm", "stretch": "bookworm", "buster": "bookworm", "bullseye": "bookworm",
+	"xenial": "jammy", "bionic": "jammy", "focal": "jammy",
+}
+
+var imageVersionPattern = regexp.MustCompile(`^\d+(?:\.\d+)?`)
+
+// AuditBaseImages lists the base images of all Dockerfiles in a repo and flags unpinned and end-of-life images
+func AuditBaseImages(repoPath string) []BaseImage {
+	result := []BaseImage{}
+	for _, dockerfile := range FindDockerfiles(repoPath) {
+		images, err := ParseBaseImages(filepath.Join(repoPath, filepath.FromSlash(dockerfile)))
+		if err != nil {
+			continue
+		}
+		for _, image := range images {
+			finding := BaseImage{File: dockerfile, Image: image}
+			finding.Issue, finding.Detail, finding.Suggestion = checkBaseImage(image)
+			result = append(result, finding)
+		}
+	}
+	return result
+}
+
+// splitImageRef splits "registry/name:tag@digest" into its repository, tag and digest
+func splitImageRef(ref string) (name, tag, digest string) {
+	name, digest, _ = strings.Cut(ref, "@")
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, tag = name[:i], name[i+1:]
+	}
+	return name, tag, digest
+}
+
+// officialImageName returns the Docker Hub library name of an image ("docker.io/library/node" -> "node")
+// or "" for images of other registries and namespaces
+func officialImageName(name string) string {
+	name = strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(name), "docker.io/"), "library/")
+	if strings.Contains(name, "/") {
+		return ""
+	}
+	return name
+}
+
+// checkBaseImage returns the issue of an image reference, its explanation and a replacement
+func checkBaseImage(ref string) (issue, detail, suggestion string) {
+	name, tag, digest := splitImageRef(ref)
+	official := officialImageName(name)
+
+	if successor, ok := deprecatedImages[official]; ok {
+		suggestion = successor
+		if version := imageVersionPattern.FindString(tag); version != "" && !strings.Contains(successor, ":") {
+			suggestion = successor + ":" + version
+		}
+		return BaseImageEOL, fmt.Sprintf("The %s image is deprecated", official), suggestion
+	}
+
+	if digest == "" && (tag == "" || tag == "latest") {
+		return BaseImageLatest, "Not pinned to a version, 'latest' changes without notice", ""
+	}
+
+	// Debian/Ubuntu codenames as tag ("debian:buster") or variant ("node:22-bullseye")
+	for _, part := range strings.FieldsFunc(tag, func(r rune) bool { return r == '-' || r == '_' }) {
+		if successor, ok := eolCodenames[part]; ok {
+			suggestion = name + ":" + strings.Replace(tag, part, successor, 1)
+			return BaseImageEOL, fmt.Sprintf("%s is an end-of-life release", part), suggestion
+		}
+	}
+
+	// Build images bundling the deprecated openjdk image ("maven:3.8-openjdk-11")
+	if strings.Contains(tag, "openjdk") {
+		suggestion = name + ":" + strings.Replace(tag, "openjdk", "eclipse-temurin", 1)
+		return BaseImageEOL, "Variant based on the deprecated openjdk image", suggestion
+	}
+
+	if minVersion, ok := minSupportedImages[official]; ok {
+		version := imageVersionPattern.FindString(tag)
+		if version != "" && compareImageVersions(version, minVersion) < 0 {
+			suggestion = name + ":" + minVersion + strings.TrimPrefix(tag, version)
+			return BaseImageEOL, fmt.Sprintf("%s %s is end-of-life, oldest supported is %s", official, version, minVersion), suggestion
+		}
+	}
+	return "", "", ""
+}
+
+// compareImageVersions compares dotted numeric versions, missing parts count as 0 ("3" < "3.10")
+func compareImageVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// replaceBaseImageIn replaces the image reference from with to in the FROM and ARG lines of a Dockerfile.
+// Only whole references match: "openjdk:8" does not touch "openjdk:8-jre".
+func replaceBaseImageIn(content, from, to string) (string, int) {
+	pattern := regexp.MustCompile(`(^|[\s="'])` + regexp.QuoteMeta(from) + `($|[\s"'])`)
+	replacement := "${1}" + strings.ReplaceAll(to, "$", "$$") + "${2}"
+	lines := strings.SplitAfter(content, "\n")
+	count := 0
+	for i, line := range lines {
+		keyword, _, _ := strings.Cut(strings.TrimSpace(line), " ")
+		if !strings.EqualFold(keyword, "FROM") && !strings.EqualFold(keyword, "ARG") {
+			continue
+		}
+		body := strings.TrimRight(line, "\r\n")
+		replaced := pattern.ReplaceAllString(body, replacement)
+		if replaced != body {
+			count += len(pattern.FindAllStringIndex(body, -1))
+			lines[i] = replaced + line[len(body):]
+		}
+	}
+	return strings.Join(lines, ""), count
+}
+
+// baseImageChanges returns the updated content of every Dockerfile of a repo referencing from
+func baseImageChanges(repoPath, from, to string) (map[string]string, []string, error) {
+	changes := make(map[string]string)
+	var files []string
+	for _, dockerfile := range FindDockerfiles(repoPath) {
+		data, err := os.ReadFile(filepath.Join(repoPath, filepath.FromSlash(dockerfile)))
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not read %s: %v", dockerfile, err)
+		}
+		if updated, count := replaceBaseImageIn(string(data), from, to); count > 0 {
+			changes[dockerfile] = updated
+			files = append(files, dockerfile)
+		}
+	}
+	return changes, files, nil
+}
+
+// UpdateBaseImage replaces the base image from with to in all Dockerfiles of a repo and commits the
+// change on branch (created if needed), signed if configured. It reports false if no Dockerfile
+// references the image.
+func UpdateBaseImage(repoPath, from, to, branch string, signing SigningSettings, log func(string)) (bool, error) {
+	if _, files, err := baseImageChanges(repoPath, from, to); err != nil || len(files) == 0 {
+		if err == nil {
+			log(fmt.Sprintf("  No Dockerfile uses %s.", from))
+		}
+		return false, err
+	}
+
+	if err := requireCleanWorktree(repoPath); err != nil {
+		return false, err
+	}
+	if branch != "" {
+		if err := checkoutOrCreateBranch(repoPath, branch, log); err != nil {
+			return false, err
+		}
+	}
+
+	// An existing branch may differ from the checked-out one
+	changes, files, err := baseImageChanges(repoPath, from, to)
+	if err != nil {
+		return false, err
+	}
+	if len(files) == 0 {
+		log(fmt.Sprintf("  Branch '%s' already uses %s.", branch, to))
+		return false, nil
+	}
+	for _, dockerfile := range files {
+		if err := os.WriteFile(filepath.Join(repoPath, filepath.FromSlash(dockerfile)), []byte(changes[dockerfile]), 0644); err != nil {
+			return false, fmt.Errorf("could not write %s: %v", dockerfile, err)
+		}
+		log(fmt.Sprintf("  [INFO] %s: %s -> %s", dockerfile, from, to))
+	}
+	if err := runGitCommand(repoPath, append([]string{"add", "--"}, files...)...); err != nil {
+		return false, fmt.Errorf("git add failed: %v", err)
+	}
+	verb, err := newCommitQueue(CommitPerFile, signing).commit(repoPath, fmt.Sprintf("Update base image %s to %s", from, to))
+	if err != nil {
+		return false, fmt.Errorf("git commit failed: %v", err)
+	}
+	log(fmt.Sprintf("  %d Dockerfile(s) updated and %s.", len(files), verb))
+	return true, nil
+}
@@ -0,0 +1,180 @@
+package logic
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxCompilationErrors caps the compilation errors kept per build
+const maxCompilationErrors = 50
+
+// maxBuildFailureLogLines caps the structured failure lines written to the run log
+const maxBuildFailureLogLines = 20
+
+// CompilationError is a compiler error of a failed build
+type CompilationError struct {
+	File    string `json:"file"` // Relative to the repo if inside it
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"` // Including javac's "symbol:" / "location:" details
+}
+
+// BuildFailure is the structured cause of a failed Maven build
+type BuildFailure struct {
+	FailedModules     []string           `json:"failedModules,omitempty"`     // Reactor modules with FAILURE
+	FailedGoals       []string           `json:"failedGoals,omitempty"`       // "Failed to execute goal ..." messages
+	CompilationErrors []CompilationError `json:"compilationErrors,omitempty"` // Max. 50
+	MissingArtifacts  []string           `json:"missingArtifacts,omitempty"`  // group:artifact:type:version that could not be resolved
+}
+
+// Empty reports whether the parser found nothing to structure
+func (f *BuildFailure) Empty() bool {
+	return len(f.FailedModules) == 0 && len(f.FailedGoals) == 0 && len(f.CompilationErrors) == 0 && len(f.MissingArtifacts) == 0
+}
+
+// Lines describes the failure for the run log, most specific cause first
+func (f *BuildFailure) Lines() []string {
+	var lines []string
+	for _, a := range f.MissingArtifacts {
+		lines = append(lines, "Missing artifact: "+a)
+	}
+	for _, e := range f.CompilationErrors {
+		location := e.File
+		if e.Line > 0 {
+			location = fmt.Sprintf("%s:%d", e.File, e.Line)
+		}
+		message, _, _ := strings.Cut(e.Message, "\n")
+		lines = append(lines, fmt.Sprintf("Compilation error: %s: %s", location, message))
+	}
+	if len(f.FailedModules) > 0 {
+		lines = append(lines, "Failed modules: "+strings.Join(f.FailedModules, ", "))
+	}
+	for _, goal := range f.FailedGoals {
+		lines = append(lines, goal)
+	}
+	return lines
+}
+
+var (
+	// "[ERROR] /repo/src/main/java/Foo.java:[12,5] cannot find symbol"
+	compilationErrorPattern = regexp.MustCompile(`^\[ERROR\]\s+(.+?\.(?:java|kt|kts|groovy|scala)):\[(\d+)(?:,(\d+))?\]\s+(.*)$`)
+	// kotlinc: "[ERROR] file:///repo/src/Foo.kt:12:5 Unresolved reference: bar"
+	kotlinErrorPattern = regexp.MustCompile(`^\[ERROR\]\s+(?:file://)?(.+?\.kts?):(\d+):(\d+)\s+(.*)$`)
+	// javac continues an error with indented "symbol:" and "location:" lines
+	compilationDetailPattern = regexp.MustCompile(`^\[ERROR\]\s{2,}(\S.*)$|^\s+(symbol|location|required|found|reason)\s*:\s*(.*)$`)
+	// Reactor summary: "[INFO] core ................................ FAILURE [  2.345 s]"
+	reactorFailurePattern = regexp.MustCompile(`^\[INFO\]\s+(.+?)\s+\.{2,}\s*FAILURE\b`)
+	failedGoalPattern     = regexp.MustCompile(`^\[ERROR\]\s+(Failed to execute goal .*)$`)
+	// "Could not find artifact com.example:lib:jar:2.0 in central", "Could not transfer artifact ... from/to"
+	missingArtifactPattern     = regexp.MustCompile(`Could not (?:find|transfer) artifact (\S+)`)
+	artifactCoordinatesPattern = regexp.MustCompile(`^[\w.\-]+:[\w.\-]+(?::[\w.\-]+){1,3}$`)
+)
+
+// unresolvedListMarker starts the list of "The following artifacts could not be resolved: a:b:jar:1 (absent), c:d:jar:2"
+const unresolvedListMarker = "artifacts could not be resolved: "
+
+// ParseBuildFailure extracts failed modules, failed goals, compilation errors and unresolvable artifacts
+// from the output of a failed Maven build. Files inside repoPath are reported relative to it.
+func ParseBuildFailure(output, repoPath string) *BuildFailure {
+	failure := &BuildFailure{}
+	seen := make(map[string]bool)
+	addOnce := func(list *[]string, value string) {
+		if value != "" && !seen[value] {
+			seen[value] = true
+			*list = append(*list, value)
+		}
+	}
+
+	var current *CompilationError
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimSpace(line)
+
+		if current != nil {
+			if m := compilationDetailPattern.FindStringSubmatch(line); m != nil && !compilationErrorPattern.MatchString(trimmed) {
+				detail := strings.TrimSpace(m[1])
+				if detail == "" {
+					detail = m[2] + ": " + strings.TrimSpace(m[3])
+				}
+				current.Message += "\n" + detail
+				continue
+			}
+			current = nil
+		}
+
+		if m := compilationErrorPattern.FindStringSubmatch(trimmed); m != nil {
+			current = addCompilationError(failure, seen, repoPath, m[1], m[2], m[3], m[4])
+			continue
+		}
+		if m := kotlinErrorPattern.FindStringSubmatch(trimmed); m != nil {
+			current = addCompilationError(failure, seen, repoPath, m[1], m[2], m[3], m[4])
+			continue
+		}
+		if m := reactorFailurePattern.FindStringSubmatch(trimmed); m != nil {
+			addOnce(&failure.FailedModules, m[1])
+			continue
+		}
+		if _, list, ok := strings.Cut(trimmed, unresolvedListMarker); ok {
+			for _, artifact := range strings.Split(list, ", ") {
+				// Entries end with " (absent)" or, for the last one, with ": <reason>"
+				artifact, _, _ = strings.Cut(artifact, " (")
+				artifact, _, _ = strings.Cut(artifact, ": ")
+				if artifactCoordinatesPattern.MatchString(artifact) {
+					addOnce(&failure.MissingArtifacts, artifact)
+				}
+			}
+		}
+		for _, m := range missingArtifactPattern.FindAllStringSubmatch(trimmed, -1) {
+			addOnce(&failure.MissingArtifacts, m[1])
+		}
+		if m := failedGoalPattern.FindStringSubmatch(trimmed); m != nil {
+			// The message ends with a hint to re-run with -e or -X
+			goal, _, _ := strings.Cut(m[1], " -> [Help")
+			addOnce(&failure.FailedGoals, strings.TrimSpace(goal))
+		}
+	}
+	return failure
+}
+
+// reportBuildFailure logs a failed build and records its structured cause in the entry. The complete output
+// is only logged when nothing in it is recognized.
+func reportBuildFailure(entry *ReportEntry, what string, err error, output, repoPath string, log func(string)) {
+	failure := ParseBuildFailure(output, repoPath)
+	if failure.Empty() {
+		log(fmt.Sprintf("  [ERROR] %s failed: %v\nOutput:\n%s", what, err, output))
+		return
+	}
+	entry.BuildFailure = failure
+	log(fmt.Sprintf("  [ERROR] %s failed: %v", what, err))
+	lines := failure.Lines()
+	for i, line := range lines {
+		if i == maxBuildFailureLogLines {
+			log(fmt.Sprintf("    ... %d more, see the run report", len(lines)-i))
+			break
+		}
+		// Not tagged [ERROR], the report lists them in the build failure section instead of its errors
+		log("    " + line)
+	}
+}
+
+// addCompilationError records a compiler error once; nil if it is a duplicate or over the limit
+func addCompilationError(failure *BuildFailure, seen map[string]bool, repoPath, file, line, column, message string) *CompilationError {
+	if repoPath != "" {
+		if rel, err := filepath.Rel(repoPath, file); err == nil && !strings.HasPrefix(rel, "..") {
+			file = filepath.ToSlash(rel)
+		}
+	}
+	key := file + ":" + line + ":" + column + ":" + message
+	if seen[key] || len(failure.CompilationErrors) >= maxCompilationErrors {
+		return nil
+	}
+	seen[key] = true
+	e := CompilationError{File: file, Message: strings.TrimSpace(message)}
+	e.Line, _ = strconv.Atoi(line)
+	e.Column, _ = strconv.Atoi(column)
+	failure.CompilationErrors = append(failure.CompilationErrors, e)
+	return &failure.CompilationErrors[len(failure.CompilationErrors)-1]
+}
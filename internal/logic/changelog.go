@@ -0,0 +1,291 @@
+package logic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ChangelogFile is the changelog maintained in the repository root
+const ChangelogFile = "CHANGELOG.md"
+
+// UnreleasedVersion heads the changelog section of commits not released yet
+const UnreleasedVersion = "Unreleased"
+
+// changelogTypes are the section titles of the conventional commit types, in changelog order;
+// commits of other types and free-form messages go to "Other Changes"
+var changelogTypes = []struct{ Type, Title string }{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance"},
+	{"revert", "Reverts"},
+	{"refactor", "Refactoring"},
+	{"docs", "Documentation"},
+	{"build", "Build"},
+	{"ci", "CI"},
+	{"test", "Tests"},
+	{"style", "Style"},
+	{"chore", "Chores"},
+	{"other", "Other Changes"},
+}
+
+var (
+	conventionalCommitPattern = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?:\s+(.+)$`)
+	changelogHeadingPattern   = regexp.MustCompile(`(?m)^## `)
+)
+
+// ChangelogEntry is a commit in the changelog
+type ChangelogEntry struct {
+	Hash     string `json:"hash"`
+	Type     string `json:"type"` // Conventional commit type, "other" for free-form messages
+	Scope    string `json:"scope,omitempty"`
+	Subject  string `json:"subject"`
+	Breaking bool   `json:"breaking,omitempty"` // "feat!:" or a BREAKING CHANGE footer
+}
+
+// ChangelogSection groups the entries of a commit type
+type ChangelogSection struct {
+	Type    string           `json:"type"`
+	Title   string           `json:"title"`
+	Entries []ChangelogEntry `json:"entries"`
+}
+
+// Changelog lists the commits of a repo since its latest release tag
+type Changelog struct {
+	RepoName string             `json:"repoName"`
+	RepoPath string             `json:"repoPath"`
+	SinceTag string             `json:"sinceTag,omitempty"` // "" when the repo has no release tag: all commits
+	Commits  int                `json:"commits"`
+	Breaking []ChangelogEntry   `json:"breaking,omitempty"`
+	Sections []ChangelogSection `json:"sections,omitempty"`
+	Error    string             `json:"error,omitempty"`
+}
+
+// parseConventionalCommit splits "feat(api)!: add paging" into type, scope, subject and the breaking
+// marker; free-form messages are of type "other"
+func parseConventionalCommit(subject, body string) ChangelogEntry {
+	entry := ChangelogEntry{Type: "other", Subject: strings.TrimSpace(subject)}
+	if m := conventionalCommitPattern.FindStringSubmatch(entry.Subject); m != nil {
+		entry.Type, entry.Scope, entry.Breaking, entry.Subject = strings.ToLower(m[1]), m[2], m[3] == "!", m[4]
+		if !knownChangelogType(entry.Type) {
+			entry.Type = "other"
+		}
+	}
+	if strings.Contains(body, "BREAKING CHANGE:") || strings.Contains(body, "BREAKING-CHANGE:") {
+		entry.Breaking = true
+	}
+	return entry
+}
+
+func knownChangelogType(commitType string) bool {
+	for _, t := range changelogTypes {
+		if t.Type == commitType {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildChangelog collects the commits on HEAD since the latest release tag (merges and commits only
+// touching CHANGELOG.md left out) and groups them by conventional commit type
+func BuildChangelog(repoPath string, tags TagSettings) Changelog {
+	changelog := Changelog{RepoName: filepath.Base(repoPath), RepoPath: repoPath}
+	args := []string{"log", "--no-merges", "--format=%h%x1f%s%x1f%b%x1e"}
+	if tag := getLatestTag(repoPath, tags); tag != "No Tags" {
+		changelog.SinceTag = tag
+		args = append(args, tag+"..HEAD")
+	}
+	args = append(args, "--", ".", ":(exclude)"+ChangelogFile)
+	output, err := gitOutput(repoPath, args...)
+	if err != nil {
+		changelog.Error = fmt.Sprintf("git log failed: %v", err)
+		return changelog
+	}
+
+	byType := make(map[string][]ChangelogEntry)
+	for _, record := range strings.Split(output, "\x1e") {
+		fields := strings.SplitN(strings.TrimSpace(record), "\x1f", 3)
+		if len(fields) < 2 {
+			continue
+		}
+		body := ""
+		if len(fields) == 3 {
+			body = fields[2]
+		}
+		entry := parseConventionalCommit(fields[1], body)
+		entry.Hash = fields[0]
+		changelog.Commits++
+		if entry.Breaking {
+			changelog.Breaking = append(changelog.Breaking, entry)
+		}
+		byType[entry.Type] = append(byType[entry.Type], entry)
+	}
+	for _, t := range changelogTypes {
+		if entries := byType[t.Type]; len(entries) > 0 {
+			changelog.Sections = append(changelog.Sections, ChangelogSection{Type: t.Type, Title: t.Title, Entries: entries})
+		}
+	}
+	return changelog
+}
+
+// RenderChangelogSection renders the changelog as a "## [version] - date" section; the Unreleased
+// section has no date
+func RenderChangelogSection(changelog Changelog, version string, date time.Time) string {
+	if version == "" {
+		version = UnreleasedVersion
+	}
+	var b strings.Builder
+	b.WriteString("## [" + version + "]")
+	if version != UnreleasedVersion {
+		b.WriteString(" - " + date.Format("2006-01-02"))
+	}
+	b.WriteString("\n")
+	line := func(entry ChangelogEntry) {
+		b.WriteString("- ")
+		if entry.Scope != "" {
+			b.WriteString("**" + entry.Scope + ":** ")
+		}
+		b.WriteString(entry.Subject + " (" + entry.Hash + ")\n")
+	}
+	if len(changelog.Breaking) > 0 {
+		b.WriteString("\n### ⚠ Breaking Changes\n\n")
+		for _, entry := range changelog.Breaking {
+			line(entry)
+		}
+	}
+	for _, section := range changelog.Sections {
+		b.WriteString("\n### " + section.Title + "\n\n")
+		for _, entry := range section.Entries {
+			line(entry)
+		}
+	}
+	return b.String()
+}
+
+// mergeChangelog puts the section into the content of a CHANGELOG.md: the section of version is
+// replaced, otherwise it goes before the newest section. A missing file gets a title.
+func mergeChangelog(content, section, version string) string {
+	if version == "" {
+		version = UnreleasedVersion
+	}
+	if strings.TrimSpace(content) == "" {
+		return "# Changelog\n\nAll notable changes to this project are documented in this file.\n\n" + section
+	}
+	headings := changelogHeadingPattern.FindAllStringIndex(content, -1)
+	for i, heading := range headings {
+		title := content[heading[0]:]
+		title = title[:strings.IndexByte(title+"\n", '\n')]
+		if !changelogHeadingMatches(title, version) {
+			continue
+		}
+		end := len(content)
+		if i+1 < len(headings) {
+			end = headings[i+1][0]
+		}
+		rest := content[end:]
+		if rest != "" {
+			rest = "\n" + rest
+		}
+		return content[:heading[0]] + section + rest
+	}
+	if len(headings) == 0 {
+		return strings.TrimRight(content, "\n") + "\n\n" + section
+	}
+	return content[:headings[0][0]] + section + "\n" + content[headings[0][0]:]
+}
+
+// changelogHeadingMatches tells whether a "## ..." heading is the section of the version:
+// "## [1.2.0] - 2024-05-01", "## 1.2.0" and "## v1.2.0 (2024-05-01)" all are the section of 1.2.0
+func changelogHeadingMatches(heading, version string) bool {
+	title := strings.TrimSpace(strings.TrimPrefix(heading, "## "))
+	title = strings.TrimPrefix(title, "[")
+	title = strings.TrimPrefix(title, "v")
+	version = strings.TrimPrefix(version, "v")
+	if !strings.HasPrefix(strings.ToLower(title), strings.ToLower(version)) {
+		return false
+	}
+	rest := title[len(version):]
+	return rest == "" || strings.ContainsRune("] (-", rune(rest[0]))
+}
+
+// writeChangelog merges the changelog since the latest tag into CHANGELOG.md and commits it on the
+// current branch through the commit queue. It reports false when there is nothing new to write.
+func writeChangelog(repoPath string, tags TagSettings, version string, commits *commitQueue, log func(string)) (bool, error) {
+	changelog := BuildChangelog(repoPath, tags)
+	if changelog.Error != "" {
+		return false, fmt.Errorf("%s", changelog.Error)
+	}
+	if changelog.Commits == 0 {
+		log("  No commits since the latest release tag, changelog unchanged.")
+		return false, nil
+	}
+	changed, err := writeChangelogFile(repoPath, changelog, version, version)
+	if err != nil {
+		return false, err
+	}
+	if !changed {
+		log(fmt.Sprintf("  %s is up to date.", ChangelogFile))
+		return false, nil
+	}
+	if err := runGitCommand(repoPath, "add", "--", ChangelogFile); err != nil {
+		return false, fmt.Errorf("git add failed: %v", err)
+	}
+	verb, err := commits.commit(repoPath, "Update "+ChangelogFile)
+	if err != nil {
+		return false, fmt.Errorf("git commit failed: %v", err)
+	}
+	since := changelog.SinceTag
+	if since == "" {
+		since = "the first commit"
+	}
+	log(fmt.Sprintf("  [INFO] %s updated with %d commit(s) since %s and %s.", ChangelogFile, changelog.Commits, since, verb))
+	return true, nil
+}
+
+// writeChangelogFile merges the section of the version into CHANGELOG.md, replacing the section
+// of replace (the version itself, or Unreleased when releasing). It reports false if nothing changed.
+func writeChangelogFile(repoPath string, changelog Changelog, version, replace string) (bool, error) {
+	path := filepath.Join(repoPath, ChangelogFile)
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("could not read %s: %v", ChangelogFile, err)
+	}
+	merged := mergeChangelog(string(content), RenderChangelogSection(changelog, version, time.Now()), replace)
+	if merged == string(content) {
+		return false, nil
+	}
+	if err := os.WriteFile(path, []byte(merged), 0644); err != nil {
+		return false, fmt.Errorf("could not write %s: %v", ChangelogFile, err)
+	}
+	return true, nil
+}
+
+// UpdateChangelog writes the changelog since the latest release tag to CHANGELOG.md and commits it on
+// branch (created if needed), signed if configured. It reports false if there is nothing new to write.
+func UpdateChangelog(repoPath string, tags TagSettings, version, branch string, signing SigningSettings, log func(string)) (bool, error) {
+	if err := requireCleanWorktree(repoPath); err != nil {
+		return false, err
+	}
+	if branch != "" {
+		if err := checkoutOrCreateBranch(repoPath, branch, log); err != nil {
+			return false, err
+		}
+	}
+	return writeChangelog(repoPath, tags, version, newCommitQueue(CommitPerFile, signing), log)
+}
+
+// processChangelog is the changelog step of a run, on the work branch. With the single commit
+// strategy the changelog joins the one commit of the run, whose own changes it cannot list yet.
+func processChangelog(repoPath string, enabled bool, tags TagSettings, commits *commitQueue, log func(string)) bool {
+	if !enabled {
+		return true
+	}
+	if _, err := writeChangelog(repoPath, tags, UnreleasedVersion, commits, log); err != nil {
+		log(fmt.Sprintf("  [ERROR] Changelog: %v", err))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,154 @@
+package logic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CI systems detected by DetectCI
+const (
+	CIGitHubActions = "GitHub Actions"
+	CIGitLab        = "GitLab CI"
+	CIJenkins       = "Jenkins"
+	CIAzure         = "Azure Pipelines"
+	CICircleCI      = "CircleCI"
+	CIBitbucket     = "Bitbucket Pipelines"
+	CITravis        = "Travis CI"
+	CIDrone         = "Drone"
+	CIWoodpecker    = "Woodpecker"
+)
+
+// ciConfigPaths are the files and folders of common CI systems, relative to the repo root
+var ciConfigPaths = []struct {
+	path   string
+	system string
+}{
+	{".github/workflows", CIGitHubActions},
+	{".gitlab-ci.yml", CIGitLab},
+	{"Jenkinsfile", CIJenkins},
+	{"azure-pipelines.yml", CIAzure},
+	{".azure-pipelines", CIAzure},
+	{".circleci/config.yml", CICircleCI},
+	{"bitbucket-pipelines.yml", CIBitbucket},
+	{".travis.yml", CITravis},
+	{".drone.yml", CIDrone},
+	{".woodpecker.yml", CIWoodpecker},
+}
+
+// deprecatedRunners are hosted runner images that are retired or announced for retirement
+// (GitHub Actions runs-on and Azure Pipelines vmImage labels, compared case-insensitively)
+var deprecatedRunners = map[string]bool{
+	"ubuntu-16.04": true, "ubuntu-18.04": true, "ubuntu-20.04": true,
+	"windows-2016": true, "windows-2019": true, "vs2017-win2016": true, "vs2015-win2012r2": true, "win1803": true,
+	"macos-10.13": true, "macos-10.14": true, "macos-10.15": true, "macos-11": true, "macos-12": true, "macos-13": true,
+}
+
+// deprecatedActions maps official actions to the highest major version running on a retired Node.js runtime
+var deprecatedActions = map[string]int{
+	"actions/checkout":          3,
+	"actions/setup-java":        3,
+	"actions/setup-node":        3,
+	"actions/setup-python":      4,
+	"actions/setup-go":          4,
+	"actions/cache":             3,
+	"actions/upload-artifact":   3,
+	"actions/download-artifact": 3,
+}
+
+var (
+	runsOnPattern  = regexp.MustCompile(`(?m)^\s*(?:-\s*)?runs-on:\s*\[?\s*['"]?([\w.-]+)`)
+	vmImagePattern = regexp.MustCompile(`(?mi)^\s*(?:-\s*)?vmImage:\s*['"]?([\w.-]+)`)
+	usesPattern    = regexp.MustCompile(`(?m)^\s*(?:-\s*)?uses:\s*['"]?([\w.-]+/[\w.-]+)@v(\d+)`)
+)
+
+// CIInfo is the CI configuration of a repo
+type CIInfo struct {
+	Systems  []string `json:"systems"`            // Detected CI systems, empty = no CI
+	Files    []string `json:"files"`              // Configuration files, relative to the repo
+	Warnings []string `json:"warnings,omitempty"` // Deprecated runner images and actions
+}
+
+// DetectCI finds the CI configuration of a repo and flags deprecated runner images and actions
+func DetectCI(repoPath string) CIInfo {
+	info := CIInfo{Systems: []string{}, Files: []string{}}
+	seen := make(map[string]bool)
+	for _, candidate := range ciConfigPaths {
+		files := ciFiles(repoPath, candidate.path)
+		if len(files) == 0 {
+			continue
+		}
+		if !seen[candidate.system] {
+			seen[candidate.system] = true
+			info.Systems = append(info.Systems, candidate.system)
+		}
+		for _, file := range files {
+			info.Files = append(info.Files, file)
+			if candidate.system == CIGitHubActions || candidate.system == CIAzure {
+				info.Warnings = append(info.Warnings, ciWarnings(repoPath, file)...)
+			}
+		}
+	}
+	return info
+}
+
+// ciFiles returns the config file at rel, or the YAML files of the folder at rel
+func ciFiles(repoPath, rel string) []string {
+	path := filepath.Join(repoPath, filepath.FromSlash(rel))
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	if !stat.IsDir() {
+		return []string{rel}
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if !entry.IsDir() && (ext == ".yml" || ext == ".yaml") {
+			files = append(files, rel+"/"+entry.Name())
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// ciWarnings checks a GitHub Actions workflow or Azure pipeline for deprecated runners and actions
+func ciWarnings(repoPath, rel string) []string {
+	data, err := os.ReadFile(filepath.Join(repoPath, filepath.FromSlash(rel)))
+	if err != nil {
+		return nil
+	}
+	content := string(data)
+
+	var warnings []string
+	seen := make(map[string]bool)
+	add := func(warning string) {
+		if !seen[warning] {
+			seen[warning] = true
+			warnings = append(warnings, warning)
+		}
+	}
+	for _, pattern := range []*regexp.Regexp{runsOnPattern, vmImagePattern} {
+		for _, m := range pattern.FindAllStringSubmatch(content, -1) {
+			if deprecatedRunners[strings.ToLower(m[1])] {
+				add(fmt.Sprintf("%s: deprecated runner image %s", rel, m[1]))
+			}
+		}
+	}
+	for _, m := range usesPattern.FindAllStringSubmatch(content, -1) {
+		major, _ := strconv.Atoi(m[2])
+		if maxDeprecated, ok := deprecatedActions[strings.ToLower(m[1])]; ok && major <= maxDeprecated {
+			add(fmt.Sprintf("%s: %s@v%d runs on a retired Node.js version", rel, m[1], major))
+		}
+	}
+	return warnings
+}
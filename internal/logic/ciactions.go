@@ -0,0 +1,245 @@
+package logic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Issues of CI actions and includes
+const (
+	CIActionUnpinned   = "unpinned"   // A branch (@master) or no ref: changes without notice
+	CIActionDeprecated = "deprecated" // Runs on a retired Node.js version
+)
+
+// CIAction is a GitHub action or GitLab include used by a CI configuration
+type CIAction struct {
+	File       string `json:"file"`           // Relative to the repo
+	System     string `json:"system"`         // CIGitHubActions or CIGitLab
+	Name       string `json:"name"`           // owner/repo[/path], GitLab project, component or remote URL
+	Ref        string `json:"ref,omitempty"`  // Tag, branch or commit
+	Pinning    string `json:"pinning"`        // "sha", "tag", "branch", "none" or "url" for remote includes
+	Uses       string `json:"uses,omitempty"` // Reference as written (name@ref), empty for includes UpdateCIAction cannot replace
+	Issue      string `json:"issue,omitempty"`
+	Suggestion string `json:"suggestion,omitempty"` // Replacement reference, if one is known
+}
+
+var (
+	actionUsesPattern = regexp.MustCompile(`(?m)^\s*(?:-\s*)?uses:\s*['"]?([^\s'"#]+)`)
+	actionTagPattern  = regexp.MustCompile(`^v?(\d+)(\.\d+)*([-+][\w.]+)?$`)
+	actionSHAPattern  = regexp.MustCompile(`^[0-9a-f]{40}$`)
+)
+
+// ciActionFiles returns the GitHub workflows and the GitLab CI file of a repo
+func ciActionFiles(repoPath string) []string {
+	return append(ciFiles(repoPath, ".github/workflows"), ciFiles(repoPath, ".gitlab-ci.yml")...)
+}
+
+// AuditCIActions lists the actions of the GitHub workflows and the includes of the GitLab CI file and
+// flags branch refs and deprecated action versions. Local actions and includes are skipped.
+func AuditCIActions(repoPath string) []CIAction {
+	var result []CIAction
+	for _, file := range ciActionFiles(repoPath) {
+		data, err := os.ReadFile(filepath.Join(repoPath, filepath.FromSlash(file)))
+		if err != nil {
+			continue
+		}
+		if file == ".gitlab-ci.yml" {
+			result = append(result, parseGitLabIncludes(data, file)...)
+		} else {
+			result = append(result, parseWorkflowActions(string(data), file)...)
+		}
+	}
+	return result
+}
+
+// parseWorkflowActions returns the actions a GitHub workflow uses, each reference once
+func parseWorkflowActions(content, file string) []CIAction {
+	var result []CIAction
+	seen := make(map[string]bool)
+	for _, m := range actionUsesPattern.FindAllStringSubmatch(content, -1) {
+		uses := m[1]
+		if strings.HasPrefix(uses, "./") || strings.HasPrefix(uses, "docker://") || seen[uses] {
+			continue
+		}
+		seen[uses] = true
+		name, ref, _ := strings.Cut(uses, "@")
+		action := CIAction{File: file, System: CIGitHubActions, Name: name, Ref: ref, Pinning: refPinning(ref), Uses: uses}
+		classifyCIAction(&action)
+		result = append(result, action)
+	}
+	return result
+}
+
+// parseGitLabIncludes returns the project, component and remote includes of a .gitlab-ci.yml;
+// GitLab's own templates and local files are skipped
+func parseGitLabIncludes(data []byte, file string) []CIAction {
+	var config struct {
+		Include interface{} `yaml:"include"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil
+	}
+	entries, ok := config.Include.([]interface{})
+	if !ok && config.Include != nil {
+		entries = []interface{}{config.Include}
+	}
+
+	var result []CIAction
+	for _, entry := range entries {
+		action := CIAction{File: file, System: CIGitLab}
+		switch include := entry.(type) {
+		case string:
+			if !strings.HasPrefix(include, "https://") && !strings.HasPrefix(include, "http://") {
+				continue // Local file
+			}
+			action.Name, action.Pinning = include, "url"
+		case map[string]interface{}:
+			switch {
+			case include["component"] != nil:
+				component, _ := include["component"].(string)
+				action.Name, action.Ref, _ = strings.Cut(component, "@")
+				action.Pinning, action.Uses = refPinning(action.Ref), component
+				if action.Ref == "~latest" {
+					action.Pinning = "branch"
+				}
+			case include["project"] != nil:
+				action.Name, _ = include["project"].(string)
+				action.Ref = scalarString(include["ref"])
+				action.Pinning = refPinning(action.Ref)
+			case include["remote"] != nil:
+				action.Name, _ = include["remote"].(string)
+				action.Pinning = "url"
+			default:
+				continue // local or template
+			}
+		default:
+			continue
+		}
+		if action.Name == "" {
+			continue
+		}
+		classifyCIAction(&action)
+		result = append(result, action)
+	}
+	return result
+}
+
+// refPinning classifies a ref as commit, version tag, branch or missing
+func refPinning(ref string) string {
+	switch {
+	case ref == "":
+		return "none"
+	case actionSHAPattern.MatchString(ref):
+		return "sha"
+	case actionTagPattern.MatchString(ref):
+		return "tag"
+	}
+	return "branch"
+}
+
+// classifyCIAction flags refs that move and official actions on a retired Node.js version, which
+// get the first supported major version as suggestion
+func classifyCIAction(action *CIAction) {
+	if action.Pinning == "branch" || action.Pinning == "none" {
+		action.Issue = CIActionUnpinned
+		return
+	}
+	if action.System != CIGitHubActions || action.Pinning != "tag" {
+		return
+	}
+	maxDeprecated, ok := deprecatedActions[strings.ToLower(action.Name)]
+	if !ok {
+		return
+	}
+	major, _ := strconv.Atoi(actionTagPattern.FindStringSubmatch(action.Ref)[1])
+	if major <= maxDeprecated {
+		action.Issue = CIActionDeprecated
+		action.Suggestion = fmt.Sprintf("%s@v%d", action.Name, maxDeprecated+1)
+	}
+}
+
+// replaceCIActionIn replaces the reference from with to in the uses: and component: lines of a CI
+// file. Only whole references match: "actions/checkout@v3" does not touch "actions/checkout@v3.5.0".
+func replaceCIActionIn(content, from, to string) (string, int) {
+	pattern := regexp.MustCompile(`^(\s*(?:-\s*)?(?:uses|component):\s*['"]?)` + regexp.QuoteMeta(from) + `($|[\s'"#])`)
+	replacement := "${1}" + strings.ReplaceAll(to, "$", "$$") + "${2}"
+	lines := strings.SplitAfter(content, "\n")
+	count := 0
+	for i, line := range lines {
+		body := strings.TrimRight(line, "\r\n")
+		if replaced := pattern.ReplaceAllString(body, replacement); replaced != body {
+			count++
+			lines[i] = replaced + line[len(body):]
+		}
+	}
+	return strings.Join(lines, ""), count
+}
+
+// ciActionChanges returns the updated content of every CI file of a repo referencing from
+func ciActionChanges(repoPath, from, to string) (map[string]string, []string, error) {
+	changes := make(map[string]string)
+	var files []string
+	for _, file := range ciActionFiles(repoPath) {
+		data, err := os.ReadFile(filepath.Join(repoPath, filepath.FromSlash(file)))
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not read %s: %v", file, err)
+		}
+		if updated, count := replaceCIActionIn(string(data), from, to); count > 0 {
+			changes[file] = updated
+			files = append(files, file)
+		}
+	}
+	return changes, files, nil
+}
+
+// UpdateCIAction replaces the action or component reference from with to in the CI files of a repo
+// and commits the change on branch (created if needed), signed if configured. It reports false if no
+// CI file uses it.
+func UpdateCIAction(repoPath, from, to, branch string, signing SigningSettings, log func(string)) (bool, error) {
+	if _, files, err := ciActionChanges(repoPath, from, to); err != nil || len(files) == 0 {
+		if err == nil {
+			log(fmt.Sprintf("  No CI file uses %s.", from))
+		}
+		return false, err
+	}
+
+	if err := requireCleanWorktree(repoPath); err != nil {
+		return false, err
+	}
+	if branch != "" {
+		if err := checkoutOrCreateBranch(repoPath, branch, log); err != nil {
+			return false, err
+		}
+	}
+
+	// An existing branch may differ from the checked-out one
+	changes, files, err := ciActionChanges(repoPath, from, to)
+	if err != nil {
+		return false, err
+	}
+	if len(files) == 0 {
+		log(fmt.Sprintf("  Branch '%s' already uses %s.", branch, to))
+		return false, nil
+	}
+	for _, file := range files {
+		if err := os.WriteFile(filepath.Join(repoPath, filepath.FromSlash(file)), []byte(changes[file]), 0644); err != nil {
+			return false, fmt.Errorf("could not write %s: %v", file, err)
+		}
+		log(fmt.Sprintf("  [INFO] %s: %s -> %s", file, from, to))
+	}
+	if err := runGitCommand(repoPath, append([]string{"add", "--"}, files...)...); err != nil {
+		return false, fmt.Errorf("git add failed: %v", err)
+	}
+	verb, err := newCommitQueue(CommitPerFile, signing).commit(repoPath, fmt.Sprintf("Update CI action %s to %s", from, to))
+	if err != nil {
+		return false, fmt.Errorf("git commit failed: %v", err)
+	}
+	log(fmt.Sprintf("  %d CI file(s) updated and %s.", len(files), verb))
+	return true, nil
+}
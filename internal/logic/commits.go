@@ -0,0 +1,148 @@
+package logic
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Commit strategies of a housekeeping run
+const (
+	CommitPerFile = "per-file" // One commit per changed file (default)
+	CommitPerRule = "per-rule" // One commit per replacement rule
+	CommitSingle  = "single"   // One commit per repository for the whole run
+)
+
+// SigningSettings controls the signing of housekeeping commits (git commit -S)
+type SigningSettings struct {
+	Enabled bool   `json:"enabled"`
+	Format  string `json:"format"` // "gpg" (default), "ssh" or "x509"
+	Key     string `json:"key"`    // GPG key ID or SSH key path; empty = user.signingkey from the git config
+}
+
+// commitArgs returns the git arguments for a (possibly signed) commit with the message
+func (s SigningSettings) commitArgs(message string) []string {
+	if !s.Enabled {
+		return []string{"commit", "-m", message}
+	}
+	return append(s.configArgs(), "commit", "-S", "-m", message)
+}
+
+// configArgs returns the "-c" options selecting the signing format and key
+func (s SigningSettings) configArgs() []string {
+	var args []string
+	if s.Format != "" {
+		args = append(args, "-c", "gpg.format="+s.Format)
+	}
+	if key := strings.TrimSpace(s.Key); key != "" {
+		args = append(args, "-c", "user.signingkey="+key)
+	}
+	return args
+}
+
+// VerifySigning creates a signed commit in a throw-away repository, so a missing key or
+// agent is reported before a run starts instead of failing in every repository
+func VerifySigning(s SigningSettings) error {
+	switch s.Format {
+	case "", "gpg", "ssh", "x509":
+	default:
+		return fmt.Errorf("unknown signing format '%s', expected gpg, ssh or x509", s.Format)
+	}
+	s.Enabled = true
+
+	dir, err := os.MkdirTemp("", "githousekeeper-signing-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := runGitCommand(dir, "init", "-q"); err != nil {
+		return err
+	}
+	// The identity is irrelevant for the test commit, but git refuses to commit without one
+	if name, _ := gitOutput(dir, "config", "user.name"); name == "" {
+		runGitCommand(dir, "config", "user.name", "GitHousekeeper")
+	}
+	if email, _ := gitOutput(dir, "config", "user.email"); email == "" {
+		runGitCommand(dir, "config", "user.email", "githousekeeper@localhost")
+	}
+	if err := runGitCommand(dir, append(s.commitArgs("Signing test"), "--allow-empty")...); err != nil {
+		return fmt.Errorf("signing a test commit failed: %v", err)
+	}
+	return nil
+}
+
+// commitQueue creates the housekeeping commits of a repo according to the commit strategy.
+// A nil queue commits immediately (per-file, unsigned).
+type commitQueue struct {
+	strategy string
+	signing  SigningSettings
+	messages []string // Collected messages of the single commit
+}
+
+func newCommitQueue(strategy string, signing SigningSettings) *commitQueue {
+	return &commitQueue{strategy: strategy, signing: signing}
+}
+
+// gitCommit commits the staged changes, signed if configured
+func (q *commitQueue) gitCommit(repoPath, message string) error {
+	signing := SigningSettings{}
+	if q != nil {
+		signing = q.signing
+	}
+	return runGitCommand(repoPath, signing.commitArgs(message)...)
+}
+
+// commit commits the staged changes, or collects them for the single commit.
+// Returns the verb for the log ("committed" or "staged").
+func (q *commitQueue) commit(repoPath, message string) (string, error) {
+	if q != nil && q.strategy == CommitSingle {
+		q.messages = append(q.messages, message)
+		return "staged", nil
+	}
+	if err := q.gitCommit(repoPath, message); err != nil {
+		return "", err
+	}
+	if q != nil && q.signing.Enabled {
+		return "committed (signed)", nil
+	}
+	return "committed", nil
+}
+
+// groupsByRule reports whether replacements are committed rule by rule instead of file by file
+func (q *commitQueue) groupsByRule() bool {
+	return q != nil && (q.strategy == CommitPerRule || q.strategy == CommitSingle)
+}
+
+// replacementCommitMessage describes the changes of one replacement rule
+func replacementCommitMessage(r Replacement, files int) string {
+	short := func(s string) string {
+		runes := []rune(strings.Join(strings.Fields(s), " "))
+		if len(runes) > 50 {
+			return string(runes[:47]) + "..."
+		}
+		return string(runes)
+	}
+	noun := "files"
+	if files == 1 {
+		noun = "file"
+	}
+	return fmt.Sprintf("Replace '%s' with '%s' in %d %s", short(r.Search), short(r.Replace), files, noun)
+}
+
+// flush creates the single commit of the run, if anything was staged
+func (q *commitQueue) flush(repoPath string, log func(string)) {
+	if q == nil || q.strategy != CommitSingle || len(q.messages) == 0 {
+		return
+	}
+	message := q.messages[0]
+	if len(q.messages) > 1 {
+		message = fmt.Sprintf("Housekeeping (%d changes)\n\n- %s", len(q.messages), strings.Join(q.messages, "\n- "))
+	}
+	if err := q.gitCommit(repoPath, message); err != nil {
+		log(fmt.Sprintf("  [ERROR] git commit failed: %v", err))
+		return
+	}
+	log(fmt.Sprintf("  Housekeeping changes committed in a single commit (%d changes).", len(q.messages)))
+	q.messages = nil
+}
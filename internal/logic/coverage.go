@@ -0,0 +1,147 @@
+package logic
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// coverageReports are the report locations checked per repo, in order. Reports are only read,
+// never generated: they exist when the build or CI ran the tests with coverage in this checkout.
+var coverageReports = []struct {
+	path  string
+	parse func(string) (float64, error)
+}{
+	{"target/site/jacoco/jacoco.xml", parseJaCoCo},
+	{"build/reports/jacoco/test/jacocoTestReport.xml", parseJaCoCo},
+	{"coverage/lcov.info", parseLcov},
+	{"lcov.info", parseLcov},
+	{"coverage.xml", parseCobertura},
+	{"coverage/cobertura-coverage.xml", parseCobertura},
+}
+
+// isTestFile reports whether a repo-relative path (slash separated) is a test source file
+func isTestFile(relPath string) bool {
+	name := strings.ToLower(filepath.Base(relPath))
+	ext := filepath.Ext(name)
+	switch ext {
+	case ".java", ".kt", ".groovy", ".scala":
+		return strings.Contains("/"+relPath, "/src/test/")
+	case ".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs":
+		base := strings.TrimSuffix(name, ext)
+		return strings.HasSuffix(base, ".test") || strings.HasSuffix(base, ".spec") || strings.Contains("/"+relPath, "/__tests__/")
+	case ".go":
+		return strings.HasSuffix(name, "_test.go")
+	case ".py":
+		return strings.HasPrefix(name, "test_") || strings.HasSuffix(name, "_test.py")
+	case ".php":
+		return strings.HasSuffix(name, "test.php") && strings.Contains("/"+strings.ToLower(relPath), "/tests/")
+	}
+	return false
+}
+
+// findCoverage returns the line coverage in percent of the first existing report (ok = false without report)
+func findCoverage(repoPath string) (percent float64, source string, ok bool) {
+	for _, report := range coverageReports {
+		path := filepath.Join(repoPath, filepath.FromSlash(report.path))
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		percent, err := report.parse(path)
+		if err != nil {
+			continue
+		}
+		return percent, report.path, true
+	}
+	return 0, "", false
+}
+
+// parseJaCoCo reads the report-level LINE counter of a JaCoCo XML report
+func parseJaCoCo(path string) (float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var report struct {
+		Counters []struct {
+			Type    string `xml:"type,attr"`
+			Missed  int    `xml:"missed,attr"`
+			Covered int    `xml:"covered,attr"`
+		} `xml:"counter"`
+	}
+	decoder := xml.NewDecoder(f)
+	decoder.Strict = false // Reports reference report.dtd, which is not available
+	if err := decoder.Decode(&report); err != nil {
+		return 0, err
+	}
+	for _, c := range report.Counters {
+		if c.Type == "LINE" && c.Missed+c.Covered > 0 {
+			return percentOf(c.Covered, c.Missed+c.Covered), nil
+		}
+	}
+	return 0, fmt.Errorf("no LINE counter in %s", path)
+}
+
+// parseLcov sums the found (LF) and hit (LH) lines of all files in an lcov tracefile
+func parseLcov(path string) (float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	found, hit := 0, 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if value, ok := strings.CutPrefix(line, "LF:"); ok {
+			n, _ := strconv.Atoi(value)
+			found += n
+		} else if value, ok := strings.CutPrefix(line, "LH:"); ok {
+			n, _ := strconv.Atoi(value)
+			hit += n
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	if found == 0 {
+		return 0, fmt.Errorf("no lines in %s", path)
+	}
+	return percentOf(hit, found), nil
+}
+
+// parseCobertura reads the line-rate of a Cobertura XML report (coverage.py, Istanbul, ...)
+func parseCobertura(path string) (float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var report struct {
+		XMLName  xml.Name `xml:"coverage"`
+		LineRate string   `xml:"line-rate,attr"`
+	}
+	decoder := xml.NewDecoder(f)
+	decoder.Strict = false
+	if err := decoder.Decode(&report); err != nil {
+		return 0, err
+	}
+	rate, err := strconv.ParseFloat(report.LineRate, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid line-rate in %s", path)
+	}
+	return float64(int(rate*1000+0.5)) / 10, nil
+}
+
+// percentOf returns part/total in percent, rounded to one decimal
+func percentOf(part, total int) float64 {
+	return float64(int(float64(part)*1000/float64(total)+0.5)) / 10
+}
@@ -14,6 +14,10 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/gorecode/updates/internal/cmdlimit"
+	"github.com/gorecode/updates/internal/gitops"
+	"github.com/gorecode/updates/internal/logic/score"
 )
 
 // DashboardStats holds the aggregated data for the dashboard
@@ -40,19 +44,63 @@ type RepoHealth struct {
 	JavaVersion    string `json:"javaVersion"`
 	LastCommit     string `json:"lastCommit"`
 	HasBuildErrors bool   `json:"hasBuildErrors"`
+	// Java versions beyond the POM and the ones older than the build target
+	JavaSources   []JavaVersionSource `json:"javaSources,omitempty"`
+	JavaConflicts []string            `json:"javaConflicts,omitempty"`
 	// New fields for enhanced dashboard
 	Framework     string `json:"framework"`     // React, Angular, Vue, Next.js, Express, Spring Boot, Go, Python, PHP, etc.
 	NodeVersion   string `json:"nodeVersion"`   // Node.js version from package.json or .nvmrc
 	GoVersion     string `json:"goVersion"`     // Go version from go.mod
 	PythonVersion string `json:"pythonVersion"` // Python version from .python-version or pyproject.toml
 	PhpVersion    string `json:"phpVersion"`    // PHP version from composer.json
+	RustVersion   string `json:"rustVersion"`   // Rust version from rust-toolchain.toml or Cargo.toml
+	DotnetVersion string `json:"dotnetVersion"` // .NET SDK from global.json, else the target framework
+	RubyVersion   string `json:"rubyVersion"`   // Ruby version from .ruby-version, Gemfile or Gemfile.lock
 	OutdatedDeps  int    `json:"outdatedDeps"`  // Count of outdated dependencies
-	ProjectType   string `json:"projectType"`   // "maven", "npm", "yarn", "pnpm", "go", "python", "php", "unknown"
+	ProjectType   string `json:"projectType"`   // "maven", "npm", "yarn", "pnpm", "go", "python", "php", "rust", "dotnet", "ruby", "unknown"
+	// Dependency freshness: average release-date delta of the direct dependencies to their latest release
+	AvgDependencyAgeDays int             `json:"avgDependencyAgeDays"`
+	DependencyAges       []DependencyAge `json:"dependencyAges,omitempty"`
+	// Tests: files in test source sets and line coverage of an existing JaCoCo, lcov or Cobertura report
+	TestFileCount  int      `json:"testFileCount"`
+	Coverage       *float64 `json:"coverage,omitempty"`       // Percent, nil without report
+	CoverageSource string   `json:"coverageSource,omitempty"` // Report the coverage was read from
+	// CI: detected systems (empty = no CI) and deprecated runner images or actions
+	CISystems  []string `json:"ciSystems"`
+	CIFiles    []string `json:"ciFiles,omitempty"`
+	CIWarnings []string `json:"ciWarnings,omitempty"`
+	// Actions of the GitHub workflows and includes of the GitLab CI file with their pinned versions
+	CIActions []CIAction `json:"ciActions,omitempty"`
+	// Renovate and Dependabot configuration (empty = dependencies are not updated automatically)
+	DependencyBots []DependencyBot `json:"dependencyBots,omitempty"`
+	// Standard files: README, LICENSE, CODEOWNERS, CONTRIBUTING, .gitignore
+	Hygiene RepoHygiene `json:"hygiene"`
+	// Base images of all Dockerfiles, with unpinned and end-of-life images flagged
+	BaseImages []BaseImage `json:"baseImages,omitempty"`
+	// Kubernetes manifests and Helm charts, with the images they reference flagged like base images
+	Kubernetes *KubernetesInventory `json:"kubernetes,omitempty"`
+	// Terraform configurations with the version pinning of their providers and modules
+	Terraform *TerraformInventory `json:"terraform,omitempty"`
+	// Commit frequency, contributors and release age (nil without the git CLI)
+	Activity *GitActivity `json:"activity,omitempty"`
+	// Support windows of the detected runtimes and frameworks (endoflife.date, cached)
+	SupportWindows []RuntimeSupport `json:"supportWindows,omitempty"`
+	// Points deducted by the rules of the score policy
+	ScoreDeductions []score.Deduction `json:"scoreDeductions,omitempty"`
 }
 
-// StreamDashboardStats scans and streams results in real-time
-func StreamDashboardStats(rootPath string, excluded []string, onResult func(interface{})) {
-	repos := FindGitRepos(rootPath, excluded)
+// StreamDashboardStats scans and streams results in real-time.
+// ignorePaths are global patterns skipped during TODO counting and health checks (see IgnoreMatcher).
+func StreamDashboardStats(rootPath string, excluded []string, ignorePaths []string, maven MavenSettings, policy *score.Policy, onResult func(interface{})) {
+	StreamRepoStats(FindGitRepos(rootPath, excluded), ignorePaths, maven, policy, onResult)
+}
+
+// StreamRepoStats streams the dashboard results of the given repos, e.g. the repos of a group.
+// Health scores are computed with policy (nil = built-in penalties).
+func StreamRepoStats(repos []string, ignorePaths []string, maven MavenSettings, policy *score.Policy, onResult func(interface{})) {
+	if policy == nil {
+		policy = score.DefaultPolicy()
+	}
 
 	// 1. Send Init Event
 	onResult(map[string]interface{}{
@@ -76,7 +124,7 @@ func StreamDashboardStats(rootPath string, excluded []string, onResult func(inte
 			sem <- struct{}{}        // Acquire token
 			defer func() { <-sem }() // Release token
 
-			health, deps := analyzeRepoHealth(path)
+			health, deps := analyzeRepoHealth(path, NewIgnoreMatcher(path, ignorePaths), maven, policy)
 
 			// Send Repo Result - protected by mutex
 			mu.Lock()
@@ -97,38 +145,46 @@ func StreamDashboardStats(rootPath string, excluded []string, onResult func(inte
 	})
 }
 
-func analyzeRepoHealth(path string) (RepoHealth, []string) {
+func analyzeRepoHealth(path string, ignore *IgnoreMatcher, maven MavenSettings, policy *score.Policy) (RepoHealth, []string) {
 	repoName := filepath.Base(path)
 	health := RepoHealth{
-		Name:        repoName,
-		Path:        path,
-		HealthScore: 100,
+		Name: repoName,
+		Path: path,
 	}
 
 	var dependencies []string
+	var junit4 bool
 
-	// 1. Get Last Commit Date
-	// git log -1 --format=%cd --date=short
-	cmd := exec.Command("git", "log", "-1", "--format=%cd", "--date=short")
-	cmd.Dir = path
-	out, err := cmd.Output()
-	if err == nil {
-		health.LastCommit = strings.TrimSpace(string(out))
-	} else {
-		health.LastCommit = "-"
+	// 1. Get Last Commit Date (read in-process, works without git installed)
+	health.LastCommit = "-"
+	var lastCommit time.Time
+	if commits, err := gitops.Open(path).Log(1); err == nil && len(commits) > 0 {
+		lastCommit = commits[0].Date
+		health.LastCommit = lastCommit.Format("2006-01-02")
 	}
 
 	// 2. Scan for TODOs/FIXMEs
-	err = filepath.WalkDir(path, func(filePath string, d os.DirEntry, err error) error {
+	err := filepath.WalkDir(path, func(filePath string, d os.DirEntry, err error) error {
 		if err != nil {
 			return nil
 		}
+		relPath, _ := filepath.Rel(path, filePath)
 		if d.IsDir() {
 			if d.Name() == ".git" || d.Name() == "target" || d.Name() == "node_modules" || d.Name() == "dist" {
 				return filepath.SkipDir
 			}
+			// Generated code and vendored third-party folders should not tank the score
+			if ignore.Matches(relPath) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ignore.Matches(relPath) {
 			return nil
 		}
+		if isTestFile(filepath.ToSlash(relPath)) {
+			health.TestFileCount++
+		}
 		ext := strings.ToLower(filepath.Ext(filePath))
 		if ext == ".java" || ext == ".xml" || ext == ".md" || ext == ".properties" || ext == ".yml" || ext == ".yaml" || ext == ".js" || ext == ".ts" {
 			count := countKeywordsInFile(filePath, []string{"TODO", "FIXME"})
@@ -137,15 +193,9 @@ func analyzeRepoHealth(path string) (RepoHealth, []string) {
 		return nil
 	})
 
-	todoPenalty := health.TodoCount / 5
-	if todoPenalty > 20 {
-		todoPenalty = 20
-	}
-	health.HealthScore -= todoPenalty
-
 	// 3. Robust Scan: Use Maven Effective POM to resolve versions (handles BOMs, Properties, Parent inheritance)
 	// This is slower but accurate.
-	sbVer, javaVer, err := getEffectivePomInfo(path)
+	sbVer, javaVer, err := getEffectivePomInfo(path, maven)
 	if err == nil {
 		if sbVer != "" {
 			health.SpringBootVer = sbVer
@@ -190,23 +240,19 @@ func analyzeRepoHealth(path string) (RepoHealth, []string) {
 				}
 
 				if dep.ArtifactId == "junit" {
-					health.HealthScore -= 5
-				}
-			}
-
-			// Penalize old Spring Boot
-			if health.SpringBootVer != "" {
-				if strings.HasPrefix(health.SpringBootVer, "2.") {
-					health.HealthScore -= 20
-				} else if strings.HasPrefix(health.SpringBootVer, "1.") {
-					health.HealthScore -= 40
+					junit4 = true
 				}
 			}
 		}
 	}
 
-	if health.HealthScore < 0 {
-		health.HealthScore = 0
+	// Java versions of Gradle, tool configs, Dockerfiles and CI, conflicting ones are shown as a warning
+	toolchain := DetectJavaToolchain(path, health.JavaVersion)
+	if len(toolchain.Sources) > 0 {
+		health.JavaSources, health.JavaConflicts = toolchain.Sources, toolchain.Conflicts
+		if health.JavaVersion == "" && toolchain.Sources[0].Source == JavaSourceGradle {
+			health.JavaVersion = toolchain.Sources[0].Version
+		}
 	}
 
 	// 5. Detect Project Type and Framework
@@ -234,16 +280,66 @@ func analyzeRepoHealth(path string) (RepoHealth, []string) {
 		// Collect PHP dependencies
 		phpDeps := getPhpDependencies(path)
 		dependencies = append(dependencies, phpDeps...)
+	case "rust":
+		health.RustVersion = getRustVersion(path)
+		// Collect Rust crates
+		rustDeps := getRustDependencies(path)
+		dependencies = append(dependencies, rustDeps...)
+	case "dotnet":
+		health.DotnetVersion = getDotnetVersion(path)
+		// Collect NuGet packages
+		dotnetDeps := getDotnetDependencies(path)
+		dependencies = append(dependencies, dotnetDeps...)
+	case "ruby":
+		health.RubyVersion = getRubyVersion(path)
+		// Collect gems
+		rubyDeps := getRubyDependencies(path)
+		dependencies = append(dependencies, rubyDeps...)
 	}
 
 	// 7. Check for Outdated Dependencies
-	health.OutdatedDeps = getOutdatedDependencyCount(path, health.ProjectType)
+	health.OutdatedDeps = getOutdatedDependencyCount(path, health.ProjectType, maven)
+
+	// 8. Dependency Age (registry metadata, cached)
+	health.DependencyAges = AnalyzeDependencyAges(path, health.ProjectType)
+	health.AvgDependencyAgeDays = AverageDaysBehind(health.DependencyAges)
 
 	// Set Framework to Spring Boot if detected
 	if health.SpringBootVer != "" && health.Framework == "" {
 		health.Framework = "Spring Boot"
 	}
 
+	// Support windows (endoflife.date, cached)
+	health.SupportWindows = RuntimeSupportWindows(health, time.Now())
+
+	// 9. Test Coverage (reports left by the last build, never generated here)
+	if percent, source, ok := findCoverage(path); ok {
+		health.Coverage, health.CoverageSource = &percent, source
+	}
+
+	// 10. CI Configuration
+	ci := DetectCI(path)
+	health.CISystems, health.CIFiles, health.CIWarnings = ci.Systems, ci.Files, ci.Warnings
+	health.CIActions = AuditCIActions(path)
+	health.DependencyBots = DetectDependencyBots(path)
+	health.Hygiene = CheckRepoHygiene(path)
+
+	// 11. Infrastructure: Dockerfile base images, Kubernetes manifests, Terraform
+	health.BaseImages = AuditBaseImages(path)
+	if k8s := FindKubernetes(path); !k8s.Empty() {
+		health.Kubernetes = &k8s
+	}
+	health.Terraform = FindTerraform(path)
+
+	// 12. Git Activity
+	if activity, err := AnalyzeGitActivity(path, time.Now()); err == nil {
+		health.Activity = &activity
+	}
+
+	// 13. Health Score from the policy
+	result := policy.Evaluate(healthFacts(path, health, junit4, lastCommit))
+	health.HealthScore, health.ScoreDeductions = result.Score, result.Deductions
+
 	return health, dependencies
 }
 
@@ -334,19 +430,14 @@ func ParsePOM(path string) (*MinimalProjectSimpleFixed, error) {
 	return &project, nil
 }
 
-func getEffectivePomInfo(dir string) (springVer, javaVer string, err error) {
+func getEffectivePomInfo(dir string, maven MavenSettings) (springVer, javaVer string, err error) {
 	// Use help:effective-pom to see the resolved versions
-	// Add timeout context to prevent hanging
+	// Add timeout context to prevent hanging; it starts once a Maven slot is free
+	defer cmdlimit.AcquireClass(cmdlimit.ClassMaven, "help:effective-pom "+dir)()
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	var cmd *exec.Cmd
-	if strings.Contains(strings.ToLower(os.Getenv("OS")), "windows") {
-		cmd = exec.CommandContext(ctx, "cmd", "/C", "mvn", "help:effective-pom", "-N")
-	} else {
-		cmd = exec.CommandContext(ctx, "mvn", "help:effective-pom", "-N")
-	}
-	cmd.Dir = dir
+	cmd := maven.CommandContext(ctx, dir, "help:effective-pom", "-N")
 
 	// Capture output
 	outputBytes, err := cmd.CombinedOutput()
@@ -393,7 +484,8 @@ func getEffectivePomInfo(dir string) (springVer, javaVer string, err error) {
 	return springVer, javaVer, nil
 }
 
-// detectProjectTypeAndFramework detects the project type (npm, yarn, pnpm, maven, go, python) and framework
+// detectProjectTypeAndFramework detects the project type (npm, yarn, pnpm, maven, go, python, php,
+// rust, dotnet, ruby) and framework
 func detectProjectTypeAndFramework(repoPath string) (projectType string, framework string) {
 	// Check for Maven project
 	if _, err := os.Stat(filepath.Join(repoPath, "pom.xml")); err == nil {
@@ -423,6 +515,27 @@ func detectProjectTypeAndFramework(repoPath string) (projectType string, framewo
 		return projectType, framework
 	}
 
+	// Check for Rust project (Cargo.toml)
+	if _, err := os.Stat(filepath.Join(repoPath, "Cargo.toml")); err == nil {
+		projectType = "rust"
+		framework = detectRustFramework(repoPath)
+		return projectType, framework
+	}
+
+	// Check for .NET project (solution or project file)
+	if DotnetProjectFile(repoPath) != "" {
+		projectType = "dotnet"
+		framework = detectDotnetFramework(repoPath)
+		return projectType, framework
+	}
+
+	// Check for Ruby project (Bundler)
+	if _, err := os.Stat(filepath.Join(repoPath, "Gemfile")); err == nil {
+		projectType = "ruby"
+		framework = detectRubyFramework(repoPath)
+		return projectType, framework
+	}
+
 	// Check for pnpm
 	if _, err := os.Stat(filepath.Join(repoPath, "pnpm-lock.yaml")); err == nil {
 		projectType = "pnpm"
@@ -706,7 +819,7 @@ type OutdatedResult struct {
 }
 
 // getOutdatedDependencyCount checks for outdated dependencies
-func getOutdatedDependencyCount(repoPath string, projectType string) int {
+func getOutdatedDependencyCount(repoPath string, projectType string, maven MavenSettings) int {
 	switch projectType {
 	case "npm":
 		return getNpmOutdatedCount(repoPath)
@@ -715,8 +828,11 @@ func getOutdatedDependencyCount(repoPath string, projectType string) int {
 	case "pnpm":
 		return getPnpmOutdatedCount(repoPath)
 	case "maven":
-		// Maven outdated checking is more complex and slow, skip for now
-		return 0
+		// Slow on the first run, the report is cached until pom.xml changes
+		report := AnalyzeMavenUpdates(repoPath, maven)
+		return CountMavenDependencyUpdates(report.Updates)
+	case "dotnet":
+		return getDotnetOutdatedCount(repoPath)
 	}
 	return 0
 }
@@ -725,7 +841,7 @@ func getOutdatedDependencyCount(repoPath string, projectType string) int {
 func getNpmOutdatedCount(repoPath string) int {
 	cmd := exec.Command("npm", "outdated", "--json")
 	cmd.Dir = repoPath
-	output, _ := cmd.Output() // npm outdated returns exit code 1 if there are outdated packages
+	output, _ := cmdlimit.Output(cmd) // npm outdated returns exit code 1 if there are outdated packages
 
 	if len(output) == 0 {
 		return 0
@@ -759,7 +875,7 @@ func getYarnOutdatedCount(repoPath string) int {
 	// Yarn Classic
 	cmd := exec.Command("yarn", "outdated", "--json")
 	cmd.Dir = repoPath
-	output, _ := cmd.Output()
+	output, _ := cmdlimit.Output(cmd)
 
 	if len(output) == 0 {
 		return 0
@@ -792,7 +908,7 @@ func getYarnOutdatedCount(repoPath string) int {
 func getPnpmOutdatedCount(repoPath string) int {
 	cmd := exec.Command("pnpm", "outdated", "--json")
 	cmd.Dir = repoPath
-	output, _ := cmd.Output()
+	output, _ := cmdlimit.Output(cmd)
 
 	if len(output) == 0 {
 		return 0
@@ -1138,3 +1254,228 @@ func getPhpDependencies(repoPath string) []string {
 	}
 	return deps
 }
+
+// cargoDependencyPattern matches a crate of a dependency table: serde = "1.0", tokio = { version = "1" },
+// shared.workspace = true
+var cargoDependencyPattern = regexp.MustCompile(`^([A-Za-z0-9_-]+)(?:\.workspace)?\s*=\s*(.*)$`)
+
+var cargoVersionPattern = regexp.MustCompile(`version\s*=\s*"([^"]*)"`)
+
+// isCargoDependencyTable tells whether a Cargo.toml table lists dependencies of the build: the
+// normal, workspace and platform-specific ones, not dev- and build-dependencies
+func isCargoDependencyTable(table string) bool {
+	return table == "dependencies" || table == "workspace.dependencies" ||
+		(strings.HasPrefix(table, "target.") && strings.HasSuffix(table, ".dependencies"))
+}
+
+// readCargoDependencies returns [crate, version] pairs of the dependencies in Cargo.toml, in file
+// order; path and git dependencies have no version
+func readCargoDependencies(cargoPath string) [][2]string {
+	data, err := os.ReadFile(cargoPath)
+	if err != nil {
+		return nil
+	}
+
+	var result [][2]string
+	table := ""
+	crate := -1 // Index of the crate of a [dependencies.<crate>] table
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			table = strings.TrimSpace(strings.Trim(line, "[]"))
+			crate = -1
+			if i := strings.LastIndex(table, "."); i > 0 && isCargoDependencyTable(table[:i]) {
+				result = append(result, [2]string{strings.Trim(table[i+1:], `"`), ""})
+				crate = len(result) - 1
+			}
+			continue
+		}
+		if crate >= 0 {
+			if m := cargoVersionPattern.FindStringSubmatch(line); m != nil && strings.HasPrefix(line, "version") {
+				result[crate][1] = m[1]
+			}
+			continue
+		}
+		if !isCargoDependencyTable(table) {
+			continue
+		}
+		m := cargoDependencyPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		version := ""
+		if value := m[2]; strings.HasPrefix(value, `"`) {
+			version = strings.SplitN(strings.TrimPrefix(value, `"`), `"`, 2)[0]
+		} else if v := cargoVersionPattern.FindStringSubmatch(value); v != nil {
+			version = v[1]
+		}
+		result = append(result, [2]string{m[1], version})
+	}
+	return result
+}
+
+// detectRustFramework detects the Rust framework used
+func detectRustFramework(repoPath string) string {
+	allDeps := make(map[string]bool)
+	for _, dep := range readCargoDependencies(filepath.Join(repoPath, "Cargo.toml")) {
+		allDeps[dep[0]] = true
+	}
+
+	// Framework detection (more specific first)
+	if allDeps["tauri"] {
+		return "Tauri"
+	}
+	if allDeps["bevy"] {
+		return "Bevy"
+	}
+	if allDeps["actix-web"] {
+		return "Actix Web"
+	}
+	if allDeps["axum"] {
+		return "Axum"
+	}
+	if allDeps["rocket"] {
+		return "Rocket"
+	}
+	if allDeps["warp"] {
+		return "Warp"
+	}
+	if allDeps["tonic"] {
+		return "Tonic"
+	}
+
+	return "Rust"
+}
+
+// getRustVersion reads the Rust version from the toolchain file, else the rust-version of Cargo.toml;
+// a channel like "stable" is only returned when Cargo.toml has no rust-version
+func getRustVersion(repoPath string) string {
+	channel := ""
+	channelRe := regexp.MustCompile(`(?m)^\s*channel\s*=\s*"([^"]+)"`)
+	if content, err := os.ReadFile(filepath.Join(repoPath, "rust-toolchain.toml")); err == nil {
+		if match := channelRe.FindSubmatch(content); match != nil {
+			channel = string(match[1])
+		}
+	} else if content, err := os.ReadFile(filepath.Join(repoPath, "rust-toolchain")); err == nil {
+		// Legacy file: the bare channel or the TOML format
+		if match := channelRe.FindSubmatch(content); match != nil {
+			channel = string(match[1])
+		} else {
+			channel = strings.TrimSpace(string(content))
+		}
+	}
+	if channel != "" && channel[0] >= '0' && channel[0] <= '9' {
+		return channel
+	}
+
+	if content, err := os.ReadFile(filepath.Join(repoPath, "Cargo.toml")); err == nil {
+		re := regexp.MustCompile(`(?m)^\s*rust-version\s*=\s*"([^"]+)"`)
+		if match := re.FindSubmatch(content); match != nil {
+			return string(match[1])
+		}
+	}
+
+	return channel
+}
+
+// getRustDependencies collects the crates from Cargo.toml
+func getRustDependencies(repoPath string) []string {
+	var deps []string
+	for _, dep := range readCargoDependencies(filepath.Join(repoPath, "Cargo.toml")) {
+		deps = append(deps, dep[0])
+		if len(deps) >= 10 {
+			break
+		}
+	}
+	return deps
+}
+
+// gemPattern matches a gem of the Gemfile: gem 'rails', '~> 7.1' or gem "puma"
+var gemPattern = regexp.MustCompile(`^gem\s*\(?\s*['"]([^'"]+)['"]`)
+
+// readGemfileGems returns the gems required in the Gemfile, in file order
+func readGemfileGems(repoPath string) []string {
+	data, err := os.ReadFile(filepath.Join(repoPath, "Gemfile"))
+	if err != nil {
+		return nil
+	}
+	var gems []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if m := gemPattern.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			gems = append(gems, m[1])
+		}
+	}
+	return gems
+}
+
+// detectRubyFramework detects the Ruby framework used
+func detectRubyFramework(repoPath string) string {
+	allDeps := make(map[string]bool)
+	for _, gem := range readGemfileGems(repoPath) {
+		allDeps[gem] = true
+	}
+
+	// Framework detection (more specific first)
+	if allDeps["rails"] || allDeps["railties"] {
+		return "Rails"
+	}
+	if allDeps["hanami"] {
+		return "Hanami"
+	}
+	if allDeps["sinatra"] {
+		return "Sinatra"
+	}
+	if allDeps["jekyll"] {
+		return "Jekyll"
+	}
+
+	return "Ruby"
+}
+
+// getRubyVersion reads the Ruby version from .ruby-version, else the ruby directive of the Gemfile or
+// the RUBY VERSION section of Gemfile.lock
+func getRubyVersion(repoPath string) string {
+	if content, err := os.ReadFile(filepath.Join(repoPath, ".ruby-version")); err == nil {
+		// "3.2.2" or "ruby-3.2.2"
+		if version := strings.TrimPrefix(strings.TrimSpace(string(content)), "ruby-"); version != "" {
+			return version
+		}
+	}
+
+	if content, err := os.ReadFile(filepath.Join(repoPath, "Gemfile")); err == nil {
+		re := regexp.MustCompile(`(?m)^\s*ruby\s*\(?\s*['"]([^'"]+)['"]`)
+		if match := re.FindSubmatch(content); match != nil {
+			return strings.TrimLeft(string(match[1]), "~>= ")
+		}
+	}
+
+	if content, err := os.ReadFile(filepath.Join(repoPath, "Gemfile.lock")); err == nil {
+		// RUBY VERSION
+		//    ruby 3.2.2p53
+		re := regexp.MustCompile(`RUBY VERSION\s+ruby ([0-9.]+)`)
+		if match := re.FindSubmatch(content); match != nil {
+			return string(match[1])
+		}
+	}
+
+	return ""
+}
+
+// getRubyDependencies collects the gems from the Gemfile
+func getRubyDependencies(repoPath string) []string {
+	var deps []string
+	for _, gem := range readGemfileGems(repoPath) {
+		// Skip framework gems that are already tracked
+		if gem == "rails" {
+			continue
+		}
+		deps = append(deps, gem)
+		if len(deps) >= 10 {
+			break
+		}
+	}
+	return deps
+}
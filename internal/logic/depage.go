@@ -0,0 +1,216 @@
+package logic
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gorecode/updates/internal/logic/registry"
+)
+
+// DirectDependency is a dependency declared with a concrete version in a build file
+type DirectDependency struct {
+	Ecosystem string `json:"ecosystem"` // "maven", "npm", "go", "python", "php"
+	Name      string `json:"name"`      // Registry name ("group:artifact" for Maven)
+	Version   string `json:"version"`
+}
+
+// DependencyAge describes how far a dependency is behind its latest release
+type DependencyAge struct {
+	DirectDependency
+	Latest         string `json:"latest"`
+	VersionsBehind int    `json:"versionsBehind"`
+	DaysBehind     int    `json:"daysBehind"` // Release date of latest minus release date of the used version
+}
+
+// maxDependencyAgeLookups caps the registry lookups per repo to keep the dashboard responsive
+const maxDependencyAgeLookups = 40
+
+// AnalyzeDependencyAges looks up the direct dependencies of a repo in their registries.
+// Dependencies whose metadata cannot be loaded (offline, private packages) are skipped.
+func AnalyzeDependencyAges(repoPath, projectType string) []DependencyAge {
+	deps := collectDirectDependencies(repoPath, projectType)
+	if len(deps) > maxDependencyAgeLookups {
+		deps = deps[:maxDependencyAgeLookups]
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 4)
+	var result []DependencyAge
+
+	for _, dep := range deps {
+		wg.Add(1)
+		go func(dep DirectDependency) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			releases, err := registry.Fetch(dep.Ecosystem, dep.Name)
+			if err != nil {
+				return
+			}
+			age, ok := computeDependencyAge(dep, releases)
+			if !ok {
+				return
+			}
+			mu.Lock()
+			result = append(result, age)
+			mu.Unlock()
+		}(dep)
+	}
+	wg.Wait()
+	return result
+}
+
+// computeDependencyAge compares the used version with the release history
+func computeDependencyAge(dep DirectDependency, releases *registry.Releases) (DependencyAge, bool) {
+	if releases == nil || releases.Latest == "" {
+		return DependencyAge{}, false
+	}
+	age := DependencyAge{DirectDependency: dep, Latest: releases.Latest}
+
+	for _, v := range releases.Versions {
+		if registry.CompareVersions(v, dep.Version) > 0 && registry.CompareVersions(v, releases.Latest) <= 0 {
+			age.VersionsBehind++
+		}
+	}
+
+	latestAt, okLatest := releases.Released[releases.Latest]
+	usedAt, okUsed := releases.Released[dep.Version]
+	if !okUsed && dep.Ecosystem == "go" && age.VersionsBehind > 0 {
+		// The Go proxy list has no dates, the used version is looked up individually
+		if t, err := registry.GoVersionTime(dep.Name, dep.Version); err == nil {
+			usedAt, okUsed = t, true
+		}
+	}
+	if okLatest && okUsed && latestAt.After(usedAt) {
+		age.DaysBehind = int(latestAt.Sub(usedAt).Hours() / 24)
+	}
+	return age, true
+}
+
+// AverageDaysBehind is the dependency freshness metric: mean release-date delta over all checked dependencies
+func AverageDaysBehind(ages []DependencyAge) int {
+	if len(ages) == 0 {
+		return 0
+	}
+	total := 0
+	for _, a := range ages {
+		total += a.DaysBehind
+	}
+	return total / len(ages)
+}
+
+// exactVersionPattern accepts plain versions, optionally with a range operator that pins a minimum (^1.2.3, ~1.2, >=1.0)
+var exactVersionPattern = regexp.MustCompile(`^(\^|~|~=|==|>=|=|v)?\s*([0-9]+(\.[0-9A-Za-z-]+)*)$`)
+
+var goPseudoVersionPattern = regexp.MustCompile(`[0-9]{14}-[0-9a-f]{12}$`)
+
+// normalizeDeclaredVersion strips range operators; returns "" for ranges that cannot be mapped to one version
+func normalizeDeclaredVersion(v string) string {
+	m := exactVersionPattern.FindStringSubmatch(strings.TrimSpace(v))
+	if m == nil {
+		return ""
+	}
+	return m[2]
+}
+
+// collectDirectDependencies reads the direct dependencies with a concrete version from the build file
+func collectDirectDependencies(repoPath, projectType string) []DirectDependency {
+	var deps []DirectDependency
+	add := func(ecosystem, name, version string) {
+		if v := normalizeDeclaredVersion(version); name != "" && v != "" {
+			deps = append(deps, DirectDependency{Ecosystem: ecosystem, Name: name, Version: v})
+		}
+	}
+
+	switch projectType {
+	case "maven":
+		if project, err := ParsePOM(filepath.Join(repoPath, "pom.xml")); err == nil {
+			for _, dep := range project.Dependencies {
+				// Property references and managed versions cannot be resolved without Maven
+				if dep.Version != "" && !strings.Contains(dep.Version, "$") {
+					add("maven", dep.GroupId+":"+dep.ArtifactId, dep.Version)
+				}
+			}
+		}
+	case "npm", "yarn", "pnpm":
+		var pkg PackageJSON
+		if data, err := os.ReadFile(filepath.Join(repoPath, "package.json")); err == nil && json.Unmarshal(data, &pkg) == nil {
+			for name, version := range pkg.Dependencies {
+				add("npm", name, version)
+			}
+			for name, version := range pkg.DevDependencies {
+				add("npm", name, version)
+			}
+		}
+	case "go":
+		for _, dep := range readGoModRequires(filepath.Join(repoPath, "go.mod")) {
+			// The module proxy expects the exact "v"-prefixed version; pseudo-versions have no release
+			if strings.HasPrefix(dep[1], "v") && !goPseudoVersionPattern.MatchString(dep[1]) {
+				deps = append(deps, DirectDependency{Ecosystem: "go", Name: dep[0], Version: dep[1]})
+			}
+		}
+	case "python":
+		if file, err := os.Open(filepath.Join(repoPath, "requirements.txt")); err == nil {
+			defer file.Close()
+			re := regexp.MustCompile(`^([A-Za-z0-9_.\-]+)(\[[^\]]*\])?\s*==\s*([^\s;#]+)`)
+			scanner := bufio.NewScanner(file)
+			for scanner.Scan() {
+				if m := re.FindStringSubmatch(strings.TrimSpace(scanner.Text())); m != nil {
+					add("python", m[1], m[3])
+				}
+			}
+		}
+	case "php":
+		var composer ComposerJSON
+		if data, err := os.ReadFile(filepath.Join(repoPath, "composer.json")); err == nil && json.Unmarshal(data, &composer) == nil {
+			for name, version := range composer.Require {
+				if name == "php" || strings.HasPrefix(name, "ext-") || !strings.Contains(name, "/") {
+					continue
+				}
+				add("php", name, version)
+			}
+		}
+	}
+	return deps
+}
+
+// readGoModRequires returns [module, version] pairs of the direct requirements in go.mod
+func readGoModRequires(goModPath string) [][2]string {
+	data, err := os.ReadFile(goModPath)
+	if err != nil {
+		return nil
+	}
+
+	var result [][2]string
+	inRequire := false
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "require (") || strings.HasPrefix(line, "require("):
+			inRequire = true
+			continue
+		case inRequire && line == ")":
+			inRequire = false
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimPrefix(line, "require ")
+		case !inRequire:
+			continue
+		}
+		if strings.Contains(line, "// indirect") {
+			continue
+		}
+		parts := strings.Fields(line)
+		if len(parts) >= 2 {
+			result = append(result, [2]string{parts[0], parts[1]})
+		}
+	}
+	return result
+}
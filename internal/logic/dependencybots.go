@@ -0,0 +1,253 @@
+package logic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Dependency update bots detected by DetectDependencyBots
+const (
+	BotRenovate   = "renovate"
+	BotDependabot = "dependabot"
+)
+
+const dependencyBotsFile = "dependency-bots.json"
+
+// renovateConfigPaths are the config files Renovate reads, in its order of precedence
+var renovateConfigPaths = []string{
+	"renovate.json", "renovate.json5",
+	".github/renovate.json", ".github/renovate.json5",
+	".gitlab/renovate.json", ".gitlab/renovate.json5",
+	".renovaterc", ".renovaterc.json", ".renovaterc.json5",
+}
+
+// dependabotEcosystems maps project types to Dependabot package ecosystems
+var dependabotEcosystems = map[string]string{
+	"maven": "maven", "npm": "npm", "yarn": "npm", "pnpm": "npm", "go": "gomod", "python": "pip",
+	"php": "composer", "rust": "cargo", "dotnet": "nuget", "ruby": "bundler",
+}
+
+// DefaultRenovateTemplate is the Renovate config committed when no org template is configured
+const DefaultRenovateTemplate = `{
+  "$schema": "https://docs.renovatebot.com/renovate-schema.json",
+  "extends": ["config:recommended"]
+}
+`
+
+// DefaultDependabotTemplate is the Dependabot config committed when no org template is configured,
+// with one weekly update per detected package ecosystem
+const DefaultDependabotTemplate = `version: 2
+updates:
+{{- range .Updates}}
+  - package-ecosystem: "{{.Ecosystem}}"
+    directory: "{{.Directory}}"
+    schedule:
+      interval: "weekly"
+{{- end}}
+`
+
+// DependencyBot is a dependency update bot configured in a repo
+type DependencyBot struct {
+	Tool string `json:"tool"` // BotRenovate or BotDependabot
+	File string `json:"file"` // Config file, relative to the repo
+}
+
+// DetectDependencyBots finds the Renovate and Dependabot configuration of a repo, including the
+// "renovate" key of package.json
+func DetectDependencyBots(repoPath string) []DependencyBot {
+	var bots []DependencyBot
+	for _, path := range renovateConfigPaths {
+		if _, err := os.Stat(filepath.Join(repoPath, filepath.FromSlash(path))); err == nil {
+			bots = append(bots, DependencyBot{Tool: BotRenovate, File: path})
+			break
+		}
+	}
+	if len(bots) == 0 {
+		var pkg struct {
+			Renovate json.RawMessage `json:"renovate"`
+		}
+		if data, err := os.ReadFile(filepath.Join(repoPath, "package.json")); err == nil && json.Unmarshal(data, &pkg) == nil && len(pkg.Renovate) > 0 {
+			bots = append(bots, DependencyBot{Tool: BotRenovate, File: "package.json"})
+		}
+	}
+	for _, path := range []string{".github/dependabot.yml", ".github/dependabot.yaml"} {
+		if _, err := os.Stat(filepath.Join(repoPath, filepath.FromSlash(path))); err == nil {
+			bots = append(bots, DependencyBot{Tool: BotDependabot, File: path})
+			break
+		}
+	}
+	return bots
+}
+
+// DependencyBotSettings holds the org-standard config templates (Go text/template); empty = default
+type DependencyBotSettings struct {
+	Renovate   string `json:"renovate,omitempty"`
+	Dependabot string `json:"dependabot,omitempty"`
+}
+
+// WithDefaults fills in the default template of every tool without own template
+func (s DependencyBotSettings) WithDefaults() DependencyBotSettings {
+	if strings.TrimSpace(s.Renovate) == "" {
+		s.Renovate = DefaultRenovateTemplate
+	}
+	if strings.TrimSpace(s.Dependabot) == "" {
+		s.Dependabot = DefaultDependabotTemplate
+	}
+	return s
+}
+
+var dependencyBotsMu sync.Mutex
+
+func dependencyBotsPath() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, dependencyBotsFile), nil
+}
+
+// LoadDependencyBotSettings returns the stored templates, missing ones filled in with the defaults
+func LoadDependencyBotSettings() (DependencyBotSettings, error) {
+	dependencyBotsMu.Lock()
+	defer dependencyBotsMu.Unlock()
+
+	var settings DependencyBotSettings
+	path, err := dependencyBotsPath()
+	if err != nil {
+		return settings.WithDefaults(), err
+	}
+	if err := readJSONFile(path, &settings); err != nil && !os.IsNotExist(err) {
+		return settings.WithDefaults(), err
+	}
+	return settings.WithDefaults(), nil
+}
+
+// SaveDependencyBotSettings validates and stores the templates; templates equal to the default are
+// stored empty so that they follow later changes of the default
+func SaveDependencyBotSettings(settings DependencyBotSettings) (DependencyBotSettings, error) {
+	for name, text := range map[string]string{"Renovate": settings.Renovate, "Dependabot": settings.Dependabot} {
+		if _, err := template.New(name).Parse(text); err != nil {
+			return settings, fmt.Errorf("invalid %s template: %v", name, err)
+		}
+	}
+	if settings.Renovate == DefaultRenovateTemplate {
+		settings.Renovate = ""
+	}
+	if settings.Dependabot == DefaultDependabotTemplate {
+		settings.Dependabot = ""
+	}
+
+	dependencyBotsMu.Lock()
+	defer dependencyBotsMu.Unlock()
+	path, err := dependencyBotsPath()
+	if err != nil {
+		return settings, err
+	}
+	return settings.WithDefaults(), writeJSONFile(path, settings)
+}
+
+// DependabotUpdate is a package ecosystem Dependabot updates
+type DependabotUpdate struct {
+	Ecosystem string
+	Directory string
+}
+
+// DependencyBotTemplateData is what the config templates can use
+type DependencyBotTemplateData struct {
+	RepoName string
+	Updates  []DependabotUpdate // Ecosystems of the repo root, Dockerfiles and GitHub Actions
+}
+
+// dependencyBotTemplateData collects the package ecosystems of a repo for the templates
+func dependencyBotTemplateData(repoPath string) DependencyBotTemplateData {
+	data := DependencyBotTemplateData{RepoName: filepath.Base(repoPath)}
+	projectType, _ := detectProjectTypeAndFramework(repoPath)
+	if ecosystem := dependabotEcosystems[projectType]; ecosystem != "" {
+		data.Updates = append(data.Updates, DependabotUpdate{Ecosystem: ecosystem, Directory: "/"})
+	}
+	if len(FindDockerfiles(repoPath)) > 0 {
+		data.Updates = append(data.Updates, DependabotUpdate{Ecosystem: "docker", Directory: "/"})
+	}
+	if len(ciFiles(repoPath, ".github/workflows")) > 0 {
+		data.Updates = append(data.Updates, DependabotUpdate{Ecosystem: "github-actions", Directory: "/"})
+	}
+	return data
+}
+
+// RenderDependencyBotConfig renders the template of the tool for a repo and returns the file to
+// write it to
+func RenderDependencyBotConfig(repoPath, tool string, settings DependencyBotSettings) (file, content string, err error) {
+	settings = settings.WithDefaults()
+	text := settings.Renovate
+	file = "renovate.json"
+	if tool == BotDependabot {
+		text, file = settings.Dependabot, ".github/dependabot.yml"
+	}
+	tmpl, err := template.New(tool).Parse(text)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid %s template: %v", tool, err)
+	}
+	data := dependencyBotTemplateData(repoPath)
+	if tool == BotDependabot && len(data.Updates) == 0 {
+		return "", "", fmt.Errorf("no package ecosystem Dependabot supports found")
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", "", fmt.Errorf("could not render the %s template: %v", tool, err)
+	}
+	return file, out.String(), nil
+}
+
+// SeedDependencyBotConfig commits the templated config of the tool on branch (created if needed),
+// signed if configured, when the repo has no dependency update bot yet. It reports false if one is configured.
+func SeedDependencyBotConfig(repoPath, tool string, settings DependencyBotSettings, branch string, signing SigningSettings, log func(string)) (bool, error) {
+	if bots := DetectDependencyBots(repoPath); len(bots) > 0 {
+		log(fmt.Sprintf("  Already configured: %s.", bots[0].File))
+		return false, nil
+	}
+
+	if err := requireCleanWorktree(repoPath); err != nil {
+		return false, err
+	}
+	if branch != "" {
+		if err := checkoutOrCreateBranch(repoPath, branch, log); err != nil {
+			return false, err
+		}
+	}
+
+	// An existing branch may have the config already
+	if bots := DetectDependencyBots(repoPath); len(bots) > 0 {
+		log(fmt.Sprintf("  Branch '%s' already has %s.", branch, bots[0].File))
+		return false, nil
+	}
+	file, content, err := RenderDependencyBotConfig(repoPath, tool, settings)
+	if err != nil {
+		return false, err
+	}
+	path := filepath.Join(repoPath, filepath.FromSlash(file))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return false, fmt.Errorf("could not write %s: %v", file, err)
+	}
+	if err := runGitCommand(repoPath, "add", "--", file); err != nil {
+		return false, fmt.Errorf("git add failed: %v", err)
+	}
+	name := "Renovate"
+	if tool == BotDependabot {
+		name = "Dependabot"
+	}
+	verb, err := newCommitQueue(CommitPerFile, signing).commit(repoPath, fmt.Sprintf("Add %s configuration", name))
+	if err != nil {
+		return false, fmt.Errorf("git commit failed: %v", err)
+	}
+	log(fmt.Sprintf("  [INFO] %s added and %s.", file, verb))
+	return true, nil
+}
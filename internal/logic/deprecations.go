@@ -0,0 +1,267 @@
+package logic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxDeprecationsPerRepo caps the structured deprecations stored per repo in a run report
+const maxDeprecationsPerRepo = 2000
+
+// Deprecation is one deprecation warning of a build
+type Deprecation struct {
+	Module  string `json:"module,omitempty"` // Reactor module of multi-module builds
+	File    string `json:"file,omitempty"`   // Relative to the repo if inside it, "" for warnings without location
+	Line    int    `json:"line,omitempty"`
+	Symbol  string `json:"symbol,omitempty"` // Deprecated API, e.g. "org.example.Client.connect()"
+	Message string `json:"message"`
+}
+
+// key identifies a deprecation across runs; the line is left out since it shifts with unrelated edits
+func (d Deprecation) key() string {
+	return d.Module + "|" + d.File + "|" + d.Symbol + "|" + d.Message
+}
+
+var (
+	// javac: "[WARNING] /repo/src/main/java/Foo.java:[12,34] [deprecation] getFoo() in Bar has been deprecated"
+	javacWarningPattern = regexp.MustCompile(`^\[WARNING\]\s+(.+?\.java):\[(\d+)(?:,\d+)?\]\s+(.*)$`)
+	// kotlinc: "[WARNING] file:///repo/src/Foo.kt:12:5 'bar(): Unit' is deprecated. Use baz" or
+	// "[WARNING] /repo/src/Foo.kt: (12, 5): 'bar(): Unit' is deprecated."
+	kotlinWarningPattern = regexp.MustCompile(`^\[WARNING\]\s+(?:file://)?(.+?\.kts?)(?::(\d+):\d+|: \((\d+), \d+\):)\s+(.*)$`)
+	javacMemberPattern   = regexp.MustCompile(`^(?:\[(?:deprecation|removal)\]\s*)?(.+?) in (\S+) has been deprecated`)
+	javacTypePattern     = regexp.MustCompile(`^(?:\[(?:deprecation|removal)\]\s*)?(\S+) has been deprecated`)
+	kotlinSymbolPattern  = regexp.MustCompile(`^'([^']+)' is deprecated`)
+	// javac summaries repeat what the individual warnings already say
+	deprecationSummaryPattern = regexp.MustCompile(`(?i)uses? or overrides? a deprecated API|recompile with -Xlint:deprecation`)
+)
+
+// ParseDeprecations extracts the deprecation warnings of a Maven build output. Files inside repoPath are
+// reported relative to it; duplicates (Maven repeats compiler warnings) are removed.
+func ParseDeprecations(output, repoPath string) []Deprecation {
+	deprecations := []Deprecation{}
+	seen := make(map[string]bool)
+	module := ""
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if m := reactorBuildingPattern.FindStringSubmatch(line); m != nil {
+			module = m[1]
+			continue
+		}
+		if !strings.HasPrefix(line, "[WARNING]") || !strings.Contains(strings.ToLower(line), "deprecat") ||
+			strings.Contains(strings.ToLower(line), "showdeprecation") || deprecationSummaryPattern.MatchString(line) {
+			continue
+		}
+
+		d := Deprecation{Module: module}
+		if m := javacWarningPattern.FindStringSubmatch(line); m != nil {
+			d.File, d.Message = m[1], m[3]
+			d.Line, _ = strconv.Atoi(m[2])
+			if s := javacMemberPattern.FindStringSubmatch(d.Message); s != nil {
+				d.Symbol = s[2] + "." + s[1]
+			} else if s := javacTypePattern.FindStringSubmatch(d.Message); s != nil {
+				d.Symbol = s[1]
+			}
+		} else if m := kotlinWarningPattern.FindStringSubmatch(line); m != nil {
+			d.File, d.Message = m[1], m[4]
+			d.Line, _ = strconv.Atoi(m[2] + m[3])
+			if s := kotlinSymbolPattern.FindStringSubmatch(d.Message); s != nil {
+				d.Symbol = s[1]
+			}
+		} else {
+			d.Message = strings.TrimSpace(strings.TrimPrefix(line, "[WARNING]"))
+		}
+		d.Message = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(d.Message, "[deprecation]"), "[removal]"))
+		if d.File != "" && repoPath != "" {
+			if rel, err := filepath.Rel(repoPath, d.File); err == nil && !strings.HasPrefix(rel, "..") {
+				d.File = filepath.ToSlash(rel)
+			}
+		}
+
+		id := d.key() + "|" + strconv.Itoa(d.Line)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		deprecations = append(deprecations, d)
+		if len(deprecations) >= maxDeprecationsPerRepo {
+			break
+		}
+	}
+	return deprecations
+}
+
+// SymbolCount counts the uses of a deprecated API
+type SymbolCount struct {
+	Symbol string   `json:"symbol"`
+	Count  int      `json:"count"`
+	Repos  []string `json:"repos"`
+}
+
+// RepoDeprecations are the deprecations of one repo of a run
+type RepoDeprecations struct {
+	RepoName     string        `json:"repoName"`
+	RepoPath     string        `json:"repoPath"`
+	Count        int           `json:"count"`
+	Delta        *int          `json:"delta,omitempty"` // Change since the previous run, nil if the repo was not in it
+	Deprecations []Deprecation `json:"deprecations"`
+}
+
+// DeprecationDiff compares the deprecations with the previous run of the same root path
+type DeprecationDiff struct {
+	PreviousRunID string    `json:"previousRunId"`
+	PreviousAt    time.Time `json:"previousAt"`
+	PreviousTotal int       `json:"previousTotal"` // Of the repos in both runs
+	Fixed         int       `json:"fixed"`         // Deprecations gone since the previous run
+	New           int       `json:"new"`           // Deprecations not in the previous run
+}
+
+// DeprecationReport aggregates the deprecations of a run across its repos
+type DeprecationReport struct {
+	RunID    string             `json:"runId"`
+	Label    string             `json:"label,omitempty"`
+	RootPath string             `json:"rootPath"`
+	RunAt    time.Time          `json:"runAt"`
+	Total    int                `json:"total"`
+	Repos    []RepoDeprecations `json:"repos"`   // Most deprecations first
+	Symbols  []SymbolCount      `json:"symbols"` // Most used deprecated APIs first
+	Diff     *DeprecationDiff   `json:"diff,omitempty"`
+}
+
+// BuildDeprecationReport aggregates the deprecations of a run report and, if previous is not nil,
+// diffs them against it (only repos processed in both runs are compared)
+func BuildDeprecationReport(report, previous *RunReport) *DeprecationReport {
+	result := &DeprecationReport{
+		RunID: report.RunID, Label: report.Label, RootPath: report.RootPath, RunAt: report.StartedAt,
+		Repos: []RepoDeprecations{}, Symbols: []SymbolCount{},
+	}
+	previousRepos := make(map[string][]Deprecation)
+	if previous != nil {
+		result.Diff = &DeprecationDiff{PreviousRunID: previous.RunID, PreviousAt: previous.StartedAt}
+		for _, repo := range previous.Repos {
+			// Reports from before the structured deprecations have no details and cannot be compared
+			if repo.DeprecationDetails != nil {
+				previousRepos[repo.RepoPath] = repo.DeprecationDetails
+			}
+		}
+	}
+
+	symbols := make(map[string]*SymbolCount)
+	for _, repo := range report.Repos {
+		deprecations := repo.DeprecationDetails
+		if deprecations == nil {
+			deprecations = []Deprecation{}
+		}
+		entry := RepoDeprecations{RepoName: repo.RepoName, RepoPath: repo.RepoPath, Count: len(deprecations), Deprecations: deprecations}
+		result.Total += len(deprecations)
+
+		if before, ok := previousRepos[repo.RepoPath]; ok {
+			delta := len(deprecations) - len(before)
+			entry.Delta = &delta
+			fixed, added := deprecationDifference(before, deprecations)
+			result.Diff.PreviousTotal += len(before)
+			result.Diff.Fixed += fixed
+			result.Diff.New += added
+		}
+		result.Repos = append(result.Repos, entry)
+
+		for _, d := range deprecations {
+			symbol := d.Symbol
+			if symbol == "" {
+				symbol = d.Message
+			}
+			count := symbols[symbol]
+			if count == nil {
+				count = &SymbolCount{Symbol: symbol, Repos: []string{}}
+				symbols[symbol] = count
+			}
+			count.Count++
+			if len(count.Repos) == 0 || count.Repos[len(count.Repos)-1] != repo.RepoName {
+				count.Repos = append(count.Repos, repo.RepoName)
+			}
+		}
+	}
+
+	for _, count := range symbols {
+		result.Symbols = append(result.Symbols, *count)
+	}
+	sort.Slice(result.Symbols, func(i, j int) bool {
+		if result.Symbols[i].Count != result.Symbols[j].Count {
+			return result.Symbols[i].Count > result.Symbols[j].Count
+		}
+		return result.Symbols[i].Symbol < result.Symbols[j].Symbol
+	})
+	sort.SliceStable(result.Repos, func(i, j int) bool { return result.Repos[i].Count > result.Repos[j].Count })
+	return result
+}
+
+// deprecationDifference counts the deprecations only before (fixed) and only after (added). Identical
+// warnings on several lines count separately.
+func deprecationDifference(before, after []Deprecation) (fixed, added int) {
+	counts := make(map[string]int)
+	for _, d := range before {
+		counts[d.key()]++
+	}
+	for _, d := range after {
+		counts[d.key()]--
+	}
+	for _, n := range counts {
+		if n > 0 {
+			fixed += n
+		} else {
+			added -= n
+		}
+	}
+	return fixed, added
+}
+
+// PreviousRunReport returns the newest report of the same root path saved before the given run, nil if none
+func PreviousRunReport(report *RunReport) (*RunReport, error) {
+	dir, err := dataSubDir("reports")
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	// Run IDs start with the timestamp, so reverse name order is newest first
+	for i := len(entries) - 1; i >= 0; i-- {
+		id := strings.TrimSuffix(entries[i].Name(), ".json")
+		if entries[i].IsDir() || !ValidRunID(id) || id >= report.RunID {
+			continue
+		}
+		previous, err := LoadRunReport(id)
+		if err != nil {
+			continue
+		}
+		if previous.RootPath == report.RootPath {
+			return previous, nil
+		}
+	}
+	return nil, nil
+}
+
+// LatestRunReport returns the newest saved run report
+func LatestRunReport() (*RunReport, error) {
+	dir, err := dataSubDir("reports")
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		id := strings.TrimSuffix(entries[i].Name(), ".json")
+		if !entries[i].IsDir() && ValidRunID(id) {
+			return LoadRunReport(id)
+		}
+	}
+	return nil, fmt.Errorf("no run report found")
+}
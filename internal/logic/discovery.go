@@ -0,0 +1,334 @@
+package logic
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DiscoveryDepthEnv limits how many folder levels below a root are searched for repositories,
+// e.g. 2 for root/team/repo; unset or 0 = no limit
+const DiscoveryDepthEnv = "GITHOUSEKEEPER_DISCOVERY_DEPTH"
+
+// discoveryCacheTTL drops cached repo lists even without a filesystem event, for network drives
+// that do not report changes
+const discoveryCacheTTL = 10 * time.Minute
+
+// maxWatchedDirs caps the folders watched for one cached repo list; larger trees are not cached
+const maxWatchedDirs = 5000
+
+// skippedDirs are dependency and build output folders, which never hold repositories of their own.
+// They are skipped before being read, whatever the request excludes.
+var skippedDirs = map[string]bool{
+	"node_modules": true, "bower_components": true, "target": true, ".gradle": true, "__pycache__": true,
+	".venv": true, ".tox": true, ".terraform": true, ".next": true, ".nuxt": true, ".idea": true, ".vscode": true,
+}
+
+var (
+	discoveryMu    sync.Mutex
+	discoveryDepth int // Set by ConfigureDiscovery
+)
+
+// ConfigureDiscovery reads the search depth from the environment. Without a call there is no limit.
+func ConfigureDiscovery() error {
+	value := os.Getenv(DiscoveryDepthEnv)
+	depth := 0
+	if value != "" {
+		var err error
+		if depth, err = strconv.Atoi(value); err != nil || depth < 0 {
+			return fmt.Errorf("invalid %s '%s', expected a number of folder levels or 0 for no limit", DiscoveryDepthEnv, value)
+		}
+	}
+	discoveryMu.Lock()
+	discoveryDepth = depth
+	discoveryMu.Unlock()
+	return nil
+}
+
+// FindGitRepos returns the repositories below root, sorted by path. Folders named in excluded and the
+// skippedDirs are not searched. The result is cached until a searched folder changes.
+func FindGitRepos(root string, excluded []string) []string {
+	discoveryMu.Lock()
+	depth := discoveryDepth
+	discoveryMu.Unlock()
+
+	root = longPath(root)
+	key := discoveryKey(root, excluded, depth)
+	if repos, ok := repoCache.get(key); ok {
+		return repos
+	}
+	repos, dirs, err := walkForRepos(root, excluded, depth)
+	if err != nil {
+		slog.Warn("Error searching for repositories", "root", root, "error", err)
+		return repos
+	}
+	repoCache.put(key, repos, dirs)
+	return append([]string(nil), repos...)
+}
+
+// FindGitReposInRoots discovers the repos under several roots; empty roots are ignored. A repo reachable
+// from more than one root (nested roots, symlinked folders) is returned once, with the path of the first.
+func FindGitReposInRoots(roots []string, excluded []string) []string {
+	seen := make(map[string]bool)
+	var repos []string
+	for _, root := range roots {
+		if strings.TrimSpace(root) == "" {
+			continue
+		}
+		for _, repo := range FindGitRepos(root, excluded) {
+			key := filepath.Clean(repo)
+			if resolved, err := filepath.EvalSymlinks(repo); err == nil {
+				key = resolved
+			}
+			if !seen[key] {
+				seen[key] = true
+				repos = append(repos, repo)
+			}
+		}
+	}
+	return repos
+}
+
+func discoveryKey(root string, excluded []string, depth int) string {
+	sorted := append([]string(nil), excluded...)
+	sort.Strings(sorted)
+	return filepath.Clean(root) + "\x00" + strconv.Itoa(depth) + "\x00" + strings.Join(sorted, "\x00")
+}
+
+// repoWalker searches the folders of a tree in parallel
+type repoWalker struct {
+	excluded map[string]bool
+	maxDepth int
+	slots    chan struct{} // Bounds the folders read at the same time
+	wg       sync.WaitGroup
+
+	mu    sync.Mutex
+	repos []string
+	dirs  []string // Searched folders outside of repositories, watched for new and removed repos
+}
+
+// walkForRepos searches root up to maxDepth levels (0 = no limit). Folders that cannot be read are skipped.
+func walkForRepos(root string, excluded []string, maxDepth int) (repos, dirs []string, err error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !info.IsDir() {
+		return nil, nil, nil
+	}
+
+	w := &repoWalker{excluded: make(map[string]bool), maxDepth: maxDepth, slots: make(chan struct{}, max(4, 2*runtime.NumCPU()))}
+	for _, ex := range excluded {
+		// .git is never excluded, it is how repositories are detected
+		if ex != ".git" {
+			w.excluded[ex] = true
+		}
+	}
+	w.wg.Add(1)
+	w.visit(root, 0, false)
+	w.wg.Wait()
+
+	// Same order as a sequential walk: folder by folder, names sorted
+	sort.Slice(w.repos, func(i, j int) bool {
+		return strings.ReplaceAll(w.repos[i], string(filepath.Separator), "\x00") < strings.ReplaceAll(w.repos[j], string(filepath.Separator), "\x00")
+	})
+	return w.repos, w.dirs, nil
+}
+
+func (w *repoWalker) visit(dir string, depth int, insideRepo bool) {
+	defer w.wg.Done()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		slog.Debug("Skipping unreadable folder", "path", dir, "error", err)
+		return
+	}
+
+	isRepo := false
+	for _, e := range entries {
+		if e.Name() == ".git" && e.IsDir() {
+			isRepo = true
+			break
+		}
+	}
+	w.mu.Lock()
+	if isRepo {
+		w.repos = append(w.repos, dir)
+	} else if !insideRepo {
+		w.dirs = append(w.dirs, dir)
+	}
+	w.mu.Unlock()
+
+	if w.maxDepth > 0 && depth >= w.maxDepth {
+		return
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() || name == ".git" || w.excluded[name] || skippedDirs[name] {
+			continue
+		}
+		child := filepath.Join(dir, name)
+		w.wg.Add(1)
+		select {
+		case w.slots <- struct{}{}:
+			go func() {
+				defer func() { <-w.slots }()
+				w.visit(child, depth+1, insideRepo || isRepo)
+			}()
+		default:
+			// All workers busy: continue in this goroutine instead of waiting
+			w.visit(child, depth+1, insideRepo || isRepo)
+		}
+	}
+}
+
+// discoveryEntry is a cached repo list with the folders it was found in
+type discoveryEntry struct {
+	repos   []string
+	dirs    []string
+	created time.Time
+}
+
+// discoveryCache keeps repo lists until a filesystem event in one of their folders (a repository
+// cloned, removed or renamed) or the TTL invalidates them
+type discoveryCache struct {
+	mu      sync.Mutex
+	watcher *fsnotify.Watcher
+	failed  bool // No watcher available: nothing is cached
+	entries map[string]*discoveryEntry
+	byDir   map[string]map[string]bool // Watched folder -> keys of the entries searching it
+
+	// Changes reported by the watcher, applied by the next get or put. The watcher goroutine never
+	// takes mu, which is held while adding and removing watches.
+	changedMu  sync.Mutex
+	changed    []string
+	changedAll bool // Events were lost
+}
+
+var repoCache = &discoveryCache{entries: make(map[string]*discoveryEntry), byDir: make(map[string]map[string]bool)}
+
+func (c *discoveryCache) get(key string) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.applyChanges()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Since(entry.created) > discoveryCacheTTL {
+		c.drop(key)
+		return nil, false
+	}
+	return append([]string(nil), entry.repos...), true
+}
+
+func (c *discoveryCache) put(key string, repos, dirs []string) {
+	if len(dirs) > maxWatchedDirs {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.watcher == nil && !c.failed {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			slog.Warn("Repository discovery is not cached, folders cannot be watched", "error", err)
+			c.failed = true
+			return
+		}
+		c.watcher = watcher
+		go c.watch(watcher)
+	}
+	if c.failed {
+		return
+	}
+	c.applyChanges()
+	c.drop(key)
+
+	c.entries[key] = &discoveryEntry{repos: repos, dirs: dirs, created: time.Now()}
+	for _, dir := range dirs {
+		if c.byDir[dir] == nil {
+			if err := c.watcher.Add(dir); err != nil {
+				// E.g. the inotify limit is reached: rather search again than serve a stale list
+				slog.Debug("Cannot watch folder, repository list not cached", "path", dir, "error", err)
+				c.drop(key)
+				return
+			}
+			c.byDir[dir] = make(map[string]bool)
+		}
+		c.byDir[dir][key] = true
+	}
+}
+
+// drop removes an entry and the watches no other entry needs; c.mu must be held
+func (c *discoveryCache) drop(key string) {
+	entry, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	delete(c.entries, key)
+	for _, dir := range entry.dirs {
+		keys := c.byDir[dir]
+		if keys == nil {
+			continue
+		}
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(c.byDir, dir)
+			c.watcher.Remove(dir)
+		}
+	}
+}
+
+// applyChanges drops the entries whose folders changed; c.mu must be held
+func (c *discoveryCache) applyChanges() {
+	c.changedMu.Lock()
+	changed, all := c.changed, c.changedAll
+	c.changed, c.changedAll = nil, false
+	c.changedMu.Unlock()
+
+	if all {
+		for key := range c.entries {
+			c.drop(key)
+		}
+		return
+	}
+	for _, dir := range changed {
+		for key := range c.byDir[dir] {
+			c.drop(key)
+		}
+	}
+}
+
+func (c *discoveryCache) watch(watcher *fsnotify.Watcher) {
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			// Changed files do not matter, only folders (repositories) appearing or disappearing
+			if event.Has(fsnotify.Create) || event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+				c.changedMu.Lock()
+				c.changed = append(c.changed, filepath.Dir(event.Name), event.Name)
+				c.changedMu.Unlock()
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			// Events may have been lost (queue overflow): forget everything
+			slog.Debug("Folder watch error, repository lists dropped", "error", err)
+			c.changedMu.Lock()
+			c.changedAll = true
+			c.changedMu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package logic
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// dockerfileSkipDirs are never searched for Dockerfiles (dependencies and build output)
+var dockerfileSkipDirs = map[string]bool{
+	".git": true, "node_modules": true, "target": true, "vendor": true, "dist": true, "build": true,
+}
+
+// IsDockerfile reports whether name is a Dockerfile ("Dockerfile", "Dockerfile.prod", "api.Dockerfile", "Containerfile")
+func IsDockerfile(name string) bool {
+	lower := strings.ToLower(name)
+	return lower == "dockerfile" || lower == "containerfile" ||
+		strings.HasPrefix(lower, "dockerfile.") || strings.HasSuffix(lower, ".dockerfile")
+}
+
+// FindDockerfiles returns the Dockerfiles of a repo as paths relative to repoPath
+func FindDockerfiles(repoPath string) []string {
+	var result []string
+	filepath.Walk(repoPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if path != repoPath && dockerfileSkipDirs[info.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if IsDockerfile(info.Name()) {
+			if rel, err := filepath.Rel(repoPath, path); err == nil {
+				result = append(result, filepath.ToSlash(rel))
+			}
+		}
+		return nil
+	})
+	return result
+}
+
+var (
+	dockerArgPattern  = regexp.MustCompile(`(?i)^ARG\s+([A-Za-z_][A-Za-z0-9_]*)(?:=(.*))?$`)
+	dockerFromPattern = regexp.MustCompile(`(?i)^FROM\s+(?:--platform=\S+\s+)?(\S+)(?:\s+AS\s+(\S+))?`)
+	dockerVarPattern  = regexp.MustCompile(`\$\{?([A-Za-z_][A-Za-z0-9_]*)(?::?-([^}]*))?\}?`)
+)
+
+// ParseBaseImages returns the external images referenced by FROM in a Dockerfile.
+// Build stage references and "scratch" are skipped; ARG defaults are substituted,
+// images that still contain unresolved variables are skipped.
+func ParseBaseImages(dockerfilePath string) ([]string, error) {
+	file, err := os.Open(dockerfilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	args := make(map[string]string)
+	stages := make(map[string]bool)
+	seen := make(map[string]bool)
+	var images []string
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if m := dockerArgPattern.FindStringSubmatch(line); m != nil {
+			if _, exists := args[m[1]]; !exists {
+				args[m[1]] = strings.Trim(strings.TrimSpace(m[2]), `"'`)
+			}
+			continue
+		}
+
+		m := dockerFromPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		image := dockerVarPattern.ReplaceAllStringFunc(m[1], func(v string) string {
+			sub := dockerVarPattern.FindStringSubmatch(v)
+			if val := args[sub[1]]; val != "" {
+				return val
+			}
+			return sub[2]
+		})
+		if m[2] != "" {
+			stages[strings.ToLower(m[2])] = true
+		}
+
+		if image == "" || strings.Contains(image, "$") || strings.EqualFold(image, "scratch") || stages[strings.ToLower(image)] {
+			continue
+		}
+		if !seen[image] {
+			seen[image] = true
+			images = append(images, image)
+		}
+	}
+	return images, scanner.Err()
+}
@@ -0,0 +1,259 @@
+package logic
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gorecode/updates/internal/cmdlimit"
+)
+
+// dotnetProjectDepth bounds how deep the project files of a solution are looked for
+const dotnetProjectDepth = 3
+
+// DotnetProjectFile returns the solution, else the project file in the directory, "" when it has
+// neither; dotnet commands need it as argument when a directory has more than one
+func DotnetProjectFile(dir string) string {
+	for _, patterns := range [][]string{{"*.sln", "*.slnx"}, {"*.csproj", "*.fsproj", "*.vbproj"}} {
+		for _, pattern := range patterns {
+			if matches, _ := filepath.Glob(filepath.Join(dir, pattern)); len(matches) > 0 {
+				return matches[0]
+			}
+		}
+	}
+	return ""
+}
+
+// isDotnetProjectFile tells whether the file is an SDK project file
+func isDotnetProjectFile(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".csproj" || ext == ".fsproj" || ext == ".vbproj"
+}
+
+// findDotnetProjects returns the project files of the repo, the ones of a solution live in
+// subdirectories; build output is skipped
+func findDotnetProjects(repoPath string) []string {
+	var projects []string
+	filepath.WalkDir(repoPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			rel, _ := filepath.Rel(repoPath, path)
+			name := d.Name()
+			if path != repoPath && (name == "bin" || name == "obj" || name == "node_modules" || name == "packages" || name[0] == '.' ||
+				strings.Count(rel, string(filepath.Separator)) >= dotnetProjectDepth) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if isDotnetProjectFile(d.Name()) {
+			projects = append(projects, path)
+		}
+		return nil
+	})
+	return projects
+}
+
+// DotnetProject represents the parts of an SDK-style project file used for detection
+type DotnetProject struct {
+	Sdk            string `xml:"Sdk,attr"`
+	PropertyGroups []struct {
+		TargetFramework  string `xml:"TargetFramework"`
+		TargetFrameworks string `xml:"TargetFrameworks"`
+		UseMaui          string `xml:"UseMaui"`
+		UseWPF           string `xml:"UseWPF"`
+		UseWindowsForms  string `xml:"UseWindowsForms"`
+	} `xml:"PropertyGroup"`
+	ItemGroups []struct {
+		PackageReferences []struct {
+			Include string `xml:"Include,attr"`
+			Version string `xml:"Version,attr"`
+		} `xml:"PackageReference"`
+	} `xml:"ItemGroup"`
+}
+
+// readDotnetProjects parses the project files of the repo, unreadable ones are skipped
+func readDotnetProjects(repoPath string) []DotnetProject {
+	var projects []DotnetProject
+	for _, path := range findDotnetProjects(repoPath) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var project DotnetProject
+		if err := xml.Unmarshal(data, &project); err == nil {
+			projects = append(projects, project)
+		}
+	}
+	return projects
+}
+
+// targetFrameworks returns the target framework monikers of the project, e.g. "net8.0"
+func (p DotnetProject) targetFrameworks() []string {
+	var result []string
+	for _, group := range p.PropertyGroups {
+		for _, tfm := range strings.Split(group.TargetFramework+";"+group.TargetFrameworks, ";") {
+			if tfm = strings.TrimSpace(tfm); tfm != "" {
+				result = append(result, tfm)
+			}
+		}
+	}
+	return result
+}
+
+// detectDotnetFramework detects the .NET application framework of the projects in the repo
+func detectDotnetFramework(repoPath string) string {
+	sdks := make(map[string]bool)
+	packages := make(map[string]bool)
+	maui, wpf, winForms := false, false, false
+	for _, project := range readDotnetProjects(repoPath) {
+		sdks[project.Sdk] = true
+		for _, group := range project.ItemGroups {
+			for _, ref := range group.PackageReferences {
+				packages[ref.Include] = true
+			}
+		}
+		for _, group := range project.PropertyGroups {
+			maui = maui || strings.EqualFold(strings.TrimSpace(group.UseMaui), "true")
+			wpf = wpf || strings.EqualFold(strings.TrimSpace(group.UseWPF), "true")
+			winForms = winForms || strings.EqualFold(strings.TrimSpace(group.UseWindowsForms), "true")
+		}
+	}
+
+	// Framework detection (more specific first)
+	if sdks["Microsoft.NET.Sdk.BlazorWebAssembly"] || packages["Microsoft.AspNetCore.Components.WebAssembly"] {
+		return "Blazor"
+	}
+	if maui {
+		return "MAUI"
+	}
+	if sdks["Microsoft.NET.Sdk.Functions"] || packages["Microsoft.Azure.Functions.Worker"] || packages["Microsoft.NET.Sdk.Functions"] {
+		return "Azure Functions"
+	}
+	if sdks["Microsoft.NET.Sdk.Web"] {
+		return "ASP.NET Core"
+	}
+	if wpf {
+		return "WPF"
+	}
+	if winForms {
+		return "WinForms"
+	}
+	if sdks["Microsoft.NET.Sdk.Worker"] {
+		return "Worker Service"
+	}
+
+	return ".NET"
+}
+
+// dotnetTfmPattern extracts the version of .NET (Core) target frameworks: net8.0, net8.0-windows,
+// netcoreapp3.1; netstandard and .NET Framework (net48) do not match
+var dotnetTfmPattern = regexp.MustCompile(`^net(?:coreapp)?(\d+\.\d+)`)
+
+// getDotnetVersion reads the SDK version pinned in global.json, else the highest .NET version the
+// projects target
+func getDotnetVersion(repoPath string) string {
+	var global struct {
+		Sdk struct {
+			Version string `json:"version"`
+		} `json:"sdk"`
+	}
+	if data, err := os.ReadFile(filepath.Join(repoPath, "global.json")); err == nil && json.Unmarshal(data, &global) == nil && global.Sdk.Version != "" {
+		return global.Sdk.Version
+	}
+
+	version := ""
+	for _, project := range readDotnetProjects(repoPath) {
+		for _, tfm := range project.targetFrameworks() {
+			if m := dotnetTfmPattern.FindStringSubmatch(tfm); m != nil && (version == "" || compareImageVersions(m[1], version) > 0) {
+				version = m[1]
+			}
+		}
+	}
+	return version
+}
+
+// getDotnetDependencies collects the NuGet packages referenced by the projects
+func getDotnetDependencies(repoPath string) []string {
+	var deps []string
+	seen := make(map[string]bool)
+	for _, project := range readDotnetProjects(repoPath) {
+		for _, group := range project.ItemGroups {
+			for _, ref := range group.PackageReferences {
+				if ref.Include == "" || seen[ref.Include] {
+					continue
+				}
+				seen[ref.Include] = true
+				deps = append(deps, ref.Include)
+				if len(deps) >= 10 {
+					return deps
+				}
+			}
+		}
+	}
+	return deps
+}
+
+// DotnetOutdatedReport is the JSON report of the dotnet-outdated tool
+type DotnetOutdatedReport struct {
+	Projects []struct {
+		Name             string `json:"Name"`
+		TargetFrameworks []struct {
+			Name         string `json:"Name"`
+			Dependencies []struct {
+				Name            string `json:"Name"`
+				ResolvedVersion string `json:"ResolvedVersion"`
+				LatestVersion   string `json:"LatestVersion"`
+			} `json:"Dependencies"`
+		} `json:"TargetFrameworks"`
+	} `json:"Projects"`
+}
+
+// countDotnetOutdated counts the distinct outdated packages of the report, a package outdated in
+// several projects or target frameworks once
+func countDotnetOutdated(report DotnetOutdatedReport) int {
+	outdated := make(map[string]bool)
+	for _, project := range report.Projects {
+		for _, framework := range project.TargetFrameworks {
+			for _, dep := range framework.Dependencies {
+				outdated[dep.Name] = true
+			}
+		}
+	}
+	return len(outdated)
+}
+
+// getDotnetOutdatedCount runs dotnet outdated (dotnet tool install --global dotnet-outdated-tool)
+// and counts outdated packages; 0 when the tool is not installed
+func getDotnetOutdatedCount(repoPath string) int {
+	target := DotnetProjectFile(repoPath)
+	if target == "" {
+		return 0
+	}
+	report, err := os.CreateTemp("", "dotnet-outdated-*.json")
+	if err != nil {
+		return 0
+	}
+	report.Close()
+	defer os.Remove(report.Name())
+
+	cmd := exec.Command("dotnet", "outdated", target, "--output", report.Name(), "--output-format", "json")
+	cmd.Dir = repoPath
+	cmdlimit.Output(cmd) // dotnet outdated returns a non-zero exit code with --fail-on-updates only
+
+	data, err := os.ReadFile(report.Name())
+	if err != nil || len(data) == 0 {
+		return 0
+	}
+	var outdated DotnetOutdatedReport
+	if err := json.Unmarshal(data, &outdated); err != nil {
+		return 0
+	}
+	return countDotnetOutdated(outdated)
+}
@@ -0,0 +1,251 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gorecode/updates/internal/cmdlimit"
+)
+
+// toolVersionTimeout bounds one version probe; JVM based tools (mvn, gradle) start slowly
+const toolVersionTimeout = 30 * time.Second
+
+// toolSpec describes an external program GitHousekeeper runs and how to get it
+type toolSpec struct {
+	name        string
+	versionArgs []string
+	install     map[string]string // Install command per GOOS
+	url         string            // Download or installation page
+}
+
+// knownTools are the programs reported by InspectEnvironment, in display order
+var knownTools = []toolSpec{
+	{"git", []string{"--version"}, map[string]string{"linux": "sudo apt install git", "darwin": "brew install git", "windows": "winget install Git.Git"}, "https://git-scm.com/downloads"},
+	{"mvn", []string{"--version"}, map[string]string{"linux": "sudo apt install maven", "darwin": "brew install maven", "windows": "choco install maven"}, "https://maven.apache.org/download.cgi"},
+	{"java", []string{"-version"}, map[string]string{"linux": "sudo apt install openjdk-21-jdk", "darwin": "brew install openjdk@21", "windows": "winget install EclipseAdoptium.Temurin.21.JDK"}, "https://adoptium.net"},
+	{"node", []string{"--version"}, map[string]string{"linux": "sudo apt install nodejs npm", "darwin": "brew install node", "windows": "winget install OpenJS.NodeJS.LTS"}, "https://nodejs.org"},
+	{"npm", []string{"--version"}, map[string]string{"linux": "sudo apt install npm", "darwin": "brew install node", "windows": "winget install OpenJS.NodeJS.LTS"}, "https://nodejs.org"},
+	{"yarn", []string{"--version"}, map[string]string{"": "corepack enable (ships with Node.js) or npm install -g yarn"}, "https://yarnpkg.com/getting-started/install"},
+	{"pnpm", []string{"--version"}, map[string]string{"": "corepack enable (ships with Node.js) or npm install -g pnpm"}, "https://pnpm.io/installation"},
+	{"go", []string{"version"}, map[string]string{"linux": "sudo apt install golang-go", "darwin": "brew install go", "windows": "winget install GoLang.Go"}, "https://go.dev/dl/"},
+	{"govulncheck", []string{"-version"}, map[string]string{"": "go install golang.org/x/vuln/cmd/govulncheck@latest"}, "https://go.dev/doc/tutorial/govulncheck"},
+	{"trivy", []string{"--version"}, map[string]string{"darwin": "brew install trivy", "windows": "choco install trivy"}, "https://aquasecurity.github.io/trivy/latest/getting-started/installation/"},
+	{"pip-audit", []string{"--version"}, map[string]string{"": "pip install pip-audit (or pipx install pip-audit)"}, "https://pypi.org/project/pip-audit/"},
+	{"composer", []string{"--version"}, map[string]string{"darwin": "brew install composer", "windows": "choco install composer"}, "https://getcomposer.org/download/"},
+	{"cargo", []string{"--version"}, map[string]string{"": "curl --proto '=https' --tlsv1.2 -sSf https://sh.rustup.rs | sh"}, "https://rustup.rs"},
+	{"cargo-audit", []string{"audit", "--version"}, map[string]string{"": "cargo install cargo-audit --locked"}, "https://github.com/rustsec/rustsec/tree/main/cargo-audit"},
+	{"dotnet", []string{"--version"}, map[string]string{"linux": "sudo apt install dotnet-sdk-8.0", "darwin": "brew install --cask dotnet-sdk", "windows": "winget install Microsoft.DotNet.SDK.8"}, "https://dotnet.microsoft.com/download"},
+	{"bundle-audit", []string{"version"}, map[string]string{"": "gem install bundler-audit"}, "https://github.com/rubysec/bundler-audit"},
+	{"kubesec", []string{"version"}, map[string]string{"darwin": "brew install kubesec", "": "go install github.com/controlplaneio/kubesec/v2@latest"}, "https://github.com/controlplaneio/kubesec"},
+	{"tfsec", []string{"--version"}, map[string]string{"darwin": "brew install tfsec", "": "go install github.com/aquasecurity/tfsec/cmd/tfsec@latest"}, "https://github.com/aquasecurity/tfsec"},
+	{"helm", []string{"version", "--short"}, map[string]string{"darwin": "brew install helm", "windows": "choco install kubernetes-helm"}, "https://helm.sh/docs/intro/install/"},
+	{"gradle", []string{"--version"}, map[string]string{"darwin": "brew install gradle", "windows": "choco install gradle"}, "https://gradle.org/install/"},
+	{"docker", []string{"--version"}, map[string]string{"linux": "sudo apt install docker.io", "darwin": "brew install --cask docker", "windows": "winget install Docker.DockerDesktop"}, "https://docs.docker.com/get-docker/"},
+}
+
+// findToolSpec returns the spec of a known tool, nil for others
+func findToolSpec(name string) *toolSpec {
+	for i := range knownTools {
+		if knownTools[i].name == name {
+			return &knownTools[i]
+		}
+	}
+	return nil
+}
+
+// InstallHint tells how to install a tool on this operating system
+func InstallHint(name string) string {
+	spec := findToolSpec(name)
+	if spec == nil {
+		return fmt.Sprintf("Install %s and make sure it is on the PATH of the server.", name)
+	}
+	command := spec.install[runtime.GOOS]
+	if command == "" {
+		command = spec.install[""]
+	}
+	if command == "" {
+		return fmt.Sprintf("Install %s from %s and make sure it is on the PATH of the server.", name, spec.url)
+	}
+	return fmt.Sprintf("Install with: %s (see %s), then restart the server if the PATH changed.", command, spec.url)
+}
+
+// ToolStatus is the availability of one external program
+type ToolStatus struct {
+	Name      string `json:"name"`
+	Available bool   `json:"available"`
+	Path      string `json:"path,omitempty"`
+	Version   string `json:"version,omitempty"`
+	Error     string `json:"error,omitempty"` // Found, but the version could not be read
+	Hint      string `json:"hint,omitempty"`  // How to install it when missing
+	// Installable tells that InstallTool can install the missing tool
+	Installable bool `json:"installable,omitempty"`
+}
+
+// EnvironmentReport lists the external programs GitHousekeeper can use on this server
+type EnvironmentReport struct {
+	OS      string       `json:"os"`
+	Arch    string       `json:"arch"`
+	Tools   []ToolStatus `json:"tools"`
+	Missing []string     `json:"missing"`
+}
+
+// versionPattern finds the line of a version output that carries the version
+var versionPattern = regexp.MustCompile(`\d+\.\d+`)
+
+// toolVersion returns the line of the version output with the version number, e.g. the
+// "Gradle 8.7" below gradle's banner or java's first line on stderr
+func toolVersion(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); versionPattern.MatchString(line) {
+			return line
+		}
+	}
+	return strings.TrimSpace(output)
+}
+
+// inspectTool looks the tool up on the PATH and reads its version
+func inspectTool(spec toolSpec) ToolStatus {
+	status := ToolStatus{Name: spec.name}
+	path, err := exec.LookPath(spec.name)
+	if err != nil {
+		status.Hint = InstallHint(spec.name)
+		status.Installable = slices.Contains(InstallableTools, spec.name)
+		return status
+	}
+	status.Available, status.Path = true, path
+
+	ctx, cancel := context.WithTimeout(context.Background(), toolVersionTimeout)
+	defer cancel()
+	cmd := programCommand(ctx, spec.name, spec.versionArgs...)
+	cmd.WaitDelay = killWaitDelay
+	output, err := cmdlimit.CombinedOutput(cmd)
+	if err != nil {
+		status.Error = fmt.Sprintf("%s %s failed: %v", spec.name, strings.Join(spec.versionArgs, " "), err)
+		return status
+	}
+	status.Version = toolVersion(string(output))
+	return status
+}
+
+// InspectEnvironment reports the availability and version of every program GitHousekeeper can
+// use, probed in parallel
+func InspectEnvironment() EnvironmentReport {
+	report := EnvironmentReport{OS: runtime.GOOS, Arch: runtime.GOARCH, Tools: make([]ToolStatus, len(knownTools)), Missing: []string{}}
+	names := make([]string, len(knownTools))
+	for i, spec := range knownTools {
+		names[i] = spec.name
+	}
+	forEachRepo(names, func(i int, _ string) {
+		report.Tools[i] = inspectTool(knownTools[i])
+	})
+	for _, tool := range report.Tools {
+		if !tool.Available {
+			report.Missing = append(report.Missing, tool.Name)
+		}
+	}
+	return report
+}
+
+// ToolNeeds maps a program to the repositories (paths) a job needs it for
+type ToolNeeds map[string][]string
+
+// Add records that the repository needs the tool
+func (n ToolNeeds) Add(tool, repoPath string) {
+	if !slices.Contains(n[tool], repoPath) {
+		n[tool] = append(n[tool], repoPath)
+	}
+}
+
+// AddMaven records what a Maven build of the repository needs: java, and mvn unless the Maven
+// wrapper of the repository is used
+func (n ToolNeeds) AddMaven(repoPath string, maven MavenSettings) {
+	n.Add("java", repoPath)
+	if maven.executable(repoPath) == mavenWithoutWrapper(maven).executable("") {
+		n.Add("mvn", repoPath)
+	}
+}
+
+// mavenWithoutWrapper are the settings resolving the installed Maven, the one used where a
+// repository has no wrapper
+func mavenWithoutWrapper(maven MavenSettings) MavenSettings {
+	return MavenSettings{MavenHome: maven.MavenHome}
+}
+
+// MissingTool is a program a job needs that is not installed
+type MissingTool struct {
+	Name  string   `json:"name"`
+	Hint  string   `json:"hint"`
+	Repos []string `json:"repos"` // Names of the repositories needing it
+}
+
+// Missing returns the needed programs that cannot be found, sorted by name. mvn is looked up in
+// the Maven home of the settings when one is configured.
+func (n ToolNeeds) Missing(maven MavenSettings) []MissingTool {
+	var missing []MissingTool
+	for _, tool := range sortedKeys(n) {
+		command := tool
+		if tool == "mvn" {
+			command = mavenWithoutWrapper(maven).executable("")
+		}
+		if _, err := exec.LookPath(command); err == nil {
+			continue
+		}
+		m := MissingTool{Name: tool, Hint: InstallHint(tool)}
+		if command != tool {
+			m.Hint = fmt.Sprintf("%s was not found. Check the Maven home in the Maven settings.", command)
+		}
+		for _, repo := range n[tool] {
+			m.Repos = append(m.Repos, filepath.Base(repo))
+		}
+		missing = append(missing, m)
+	}
+	return missing
+}
+
+// RunToolNeeds returns the programs a housekeeping run needs for the repositories: git for all,
+// Maven and Java for Maven builds, go for Go modules and composer for PHP projects
+func RunToolNeeds(repos []string, maven MavenSettings, verificationLevel string) ToolNeeds {
+	needs := ToolNeeds{}
+	for _, repo := range repos {
+		needs.Add("git", repo)
+		projectType, _ := detectProjectTypeAndFramework(repo)
+		switch projectType {
+		case "maven":
+			if verificationLevel == VerifyNone {
+				continue
+			}
+			if cfg, err := LoadRepoConfig(repo); err == nil && cfg.SkipBuild {
+				continue
+			}
+			needs.AddMaven(repo, maven)
+		case "go":
+			needs.Add("go", repo)
+		case "php":
+			needs.Add("composer", repo)
+		}
+	}
+	return needs
+}
+
+// SecurityFixToolNeeds returns the programs automatic dependency fixes need: git for all
+// repositories and the build tool of each project
+func SecurityFixToolNeeds(repos []string, maven MavenSettings) ToolNeeds {
+	needs := ToolNeeds{}
+	for _, repo := range repos {
+		needs.Add("git", repo)
+		switch projectType, _ := detectProjectTypeAndFramework(repo); projectType {
+		case "maven":
+			needs.AddMaven(repo, maven)
+		case "npm", "yarn", "pnpm", "go":
+			needs.Add(projectType, repo)
+		}
+	}
+	return needs
+}
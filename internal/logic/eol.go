@@ -0,0 +1,208 @@
+package logic
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorecode/updates/internal/logic/registry"
+)
+
+// endOfLifeURL is the endoflife.date API, a variable so tests can point it to a local server
+var endOfLifeURL = "https://endoflife.date/api"
+
+// eolSoonDays is the support window left from which a runtime is flagged as ending soon
+const eolSoonDays = 180
+
+// Support status of a runtime
+const (
+	SupportActive = "supported"
+	SupportSoon   = "ending-soon" // Less than eolSoonDays left
+	SupportEOL    = "eol"
+)
+
+// ReleaseCycle is one release line of a product on endoflife.date
+type ReleaseCycle struct {
+	Cycle  string `json:"cycle"`
+	Latest string `json:"latest,omitempty"`
+	EOL    string `json:"eol,omitempty"` // Date of the end of support, "" if unknown or not planned
+	Ended  bool   `json:"ended"`         // Support ended without a known date
+	LTS    bool   `json:"lts"`           // Long-term support release that has entered LTS
+}
+
+// ProductCycles is the cached cycle list of a product
+type ProductCycles struct {
+	Cycles    []ReleaseCycle `json:"cycles"`
+	FetchedAt time.Time      `json:"fetchedAt"`
+}
+
+// RuntimeSupport annotates a detected runtime or framework version with its support window
+type RuntimeSupport struct {
+	Name     string `json:"name"`             // Display name, e.g. "Node.js"
+	Version  string `json:"version"`          // Version as detected in the repo
+	Cycle    string `json:"cycle"`            // Matched release cycle
+	EOL      string `json:"eol,omitempty"`    // End of support date
+	DaysLeft *int   `json:"daysLeft"`         // Negative = out of support, nil = no date
+	Status   string `json:"status"`           // SupportActive, SupportSoon or SupportEOL
+	Latest   string `json:"latest,omitempty"` // Latest release of the cycle
+}
+
+// eolProducts maps the runtimes of RepoHealth to endoflife.date products
+var eolProducts = []struct {
+	name    string
+	product string
+	version func(RepoHealth) string
+}{
+	{"Spring Boot", "spring-boot", func(h RepoHealth) string { return h.SpringBootVer }},
+	{"Java", "eclipse-temurin", func(h RepoHealth) string { return h.JavaVersion }},
+	{"Node.js", "nodejs", func(h RepoHealth) string { return h.NodeVersion }},
+	{"Go", "go", func(h RepoHealth) string { return h.GoVersion }},
+	{"Python", "python", func(h RepoHealth) string { return h.PythonVersion }},
+	{"PHP", "php", func(h RepoHealth) string { return h.PhpVersion }},
+	{".NET", "dotnet", func(h RepoHealth) string { return h.DotnetVersion }},
+	{"Ruby", "ruby", func(h RepoHealth) string { return h.RubyVersion }},
+}
+
+// eolRetryAfter suppresses further requests for a product after a failed one, so an offline
+// dashboard scan does not wait for the timeout once per repo
+const eolRetryAfter = 10 * time.Minute
+
+var (
+	eolMemCache   = make(map[string]*ProductCycles)
+	eolFailures   = make(map[string]time.Time)
+	eolMemCacheMu sync.Mutex
+)
+
+// eolVersionPattern extracts the version number of constraints like ">=18", "^8.1", "v20.11.0" or "18.x"
+var eolVersionPattern = regexp.MustCompile(`\d+(?:\.\d+)*`)
+
+// RuntimeSupportWindows annotates every detected runtime of a repo with its end-of-life date.
+// Products endoflife.date cannot be reached for are left out.
+func RuntimeSupportWindows(health RepoHealth, now time.Time) []RuntimeSupport {
+	var result []RuntimeSupport
+	for _, p := range eolProducts {
+		version := p.version(health)
+		if version == "" || version == "-" {
+			continue
+		}
+		cycles, err := FetchReleaseCycles(p.product)
+		if err != nil {
+			continue
+		}
+		if support, ok := matchSupportWindow(p.name, version, cycles.Cycles, now); ok {
+			result = append(result, support)
+		}
+	}
+	return result
+}
+
+// matchSupportWindow finds the release cycle of version (the longest matching cycle) and its support status
+func matchSupportWindow(name, version string, cycles []ReleaseCycle, now time.Time) (RuntimeSupport, bool) {
+	number := eolVersionPattern.FindString(version)
+	if number == "" {
+		return RuntimeSupport{}, false
+	}
+	if name == "Java" {
+		number = strings.TrimPrefix(number, "1.") // 1.8 = 8
+	}
+
+	var match *ReleaseCycle
+	for i, c := range cycles {
+		if (number == c.Cycle || strings.HasPrefix(number, c.Cycle+".")) && (match == nil || len(c.Cycle) > len(match.Cycle)) {
+			match = &cycles[i]
+		}
+	}
+	if match == nil {
+		return RuntimeSupport{}, false
+	}
+
+	support := RuntimeSupport{Name: name, Version: version, Cycle: match.Cycle, EOL: match.EOL, Latest: match.Latest, Status: SupportActive}
+	if match.Ended {
+		support.Status = SupportEOL
+	}
+	if eol, err := time.Parse("2006-01-02", match.EOL); err == nil {
+		days := int(math.Floor(eol.Sub(now).Hours() / 24))
+		support.DaysLeft = &days
+		switch {
+		case days < 0:
+			support.Status = SupportEOL
+		case days < eolSoonDays:
+			support.Status = SupportSoon
+		}
+	}
+	return support, true
+}
+
+// FetchReleaseCycles returns the release cycles of an endoflife.date product, using the memory and disk cache
+func FetchReleaseCycles(product string) (*ProductCycles, error) {
+	eolMemCacheMu.Lock()
+	if cached, ok := eolMemCache[product]; ok && cacheFresh(cached.FetchedAt, registry.DefaultTTL) {
+		eolMemCacheMu.Unlock()
+		return cached, nil
+	}
+	if failed, ok := eolFailures[product]; ok && time.Since(failed) < eolRetryAfter {
+		eolMemCacheMu.Unlock()
+		return nil, fmt.Errorf("endoflife.date unavailable for %s, retrying after %s", product, failed.Add(eolRetryAfter).Format("15:04"))
+	}
+	eolMemCacheMu.Unlock()
+
+	cachePath := cacheFilePath("eol-cache", product)
+	if cachePath != "" {
+		var cached ProductCycles
+		if err := readJSONFile(cachePath, &cached); err == nil && cacheFresh(cached.FetchedAt, registry.DefaultTTL) {
+			eolMemCacheMu.Lock()
+			eolMemCache[product] = &cached
+			eolMemCacheMu.Unlock()
+			return &cached, nil
+		}
+	}
+
+	// "eol" is a date, or a boolean when there is none (true = already ended)
+	var raw []struct {
+		Cycle  json.RawMessage `json:"cycle"`
+		Latest string          `json:"latest"`
+		EOL    json.RawMessage `json:"eol"`
+		LTS    json.RawMessage `json:"lts"`
+	}
+	if err := registry.GetJSON(endOfLifeURL+"/"+product+".json", &raw); err != nil {
+		eolMemCacheMu.Lock()
+		eolFailures[product] = time.Now()
+		eolMemCacheMu.Unlock()
+		return nil, err
+	}
+	cycles := &ProductCycles{FetchedAt: time.Now()}
+	for _, r := range raw {
+		cycle := ReleaseCycle{Cycle: strings.Trim(string(r.Cycle), `"`), Latest: r.Latest}
+		var eol interface{}
+		json.Unmarshal(r.EOL, &eol)
+		switch v := eol.(type) {
+		case string:
+			cycle.EOL = v
+		case bool:
+			cycle.Ended = v
+		}
+		// "lts" is the date the cycle enters LTS, or a boolean
+		var lts interface{}
+		json.Unmarshal(r.LTS, &lts)
+		switch v := lts.(type) {
+		case string:
+			start, err := time.Parse("2006-01-02", v)
+			cycle.LTS = err == nil && !start.After(cycles.FetchedAt)
+		case bool:
+			cycle.LTS = v
+		}
+		cycles.Cycles = append(cycles.Cycles, cycle)
+	}
+
+	eolMemCacheMu.Lock()
+	eolMemCache[product] = cycles
+	eolMemCacheMu.Unlock()
+	if cachePath != "" {
+		writeJSONFile(cachePath, cycles)
+	}
+	return cycles, nil
+}
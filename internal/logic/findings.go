@@ -0,0 +1,177 @@
+package logic
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SecurityFinding is a vulnerability in the findings store, independent of who reported it
+type SecurityFinding struct {
+	CVE         string   `json:"cve"`
+	Severity    string   `json:"severity"` // CRITICAL, HIGH, MEDIUM, LOW, UNKNOWN
+	CVSSScore   float64  `json:"cvssScore,omitempty"`
+	Package     string   `json:"package"`
+	Version     string   `json:"version,omitempty"`
+	FixedIn     string   `json:"fixedIn,omitempty"`
+	Description string   `json:"description,omitempty"`
+	ScanTarget  string   `json:"scanTarget,omitempty"`
+	SubPath     string   `json:"subPath,omitempty"` // Sub-project of a monorepo, empty for the repository root
+	URL         string   `json:"url,omitempty"`
+	Sources     []string `json:"sources,omitempty"` // Who reported it: scanner names, "dependabot", "gitlab"
+}
+
+// SourceFindings is the latest result of one source for a repo
+type SourceFindings struct {
+	UpdatedAt time.Time         `json:"updatedAt"`
+	Findings  []SecurityFinding `json:"findings"`
+}
+
+// RepoFindings holds the findings of all sources for one repo.
+// Each source replaces its previous result, so fixed vulnerabilities disappear on the next scan/import.
+type RepoFindings struct {
+	RepoName string                    `json:"repoName"`
+	RepoPath string                    `json:"repoPath"`
+	Sources  map[string]SourceFindings `json:"sources"`
+}
+
+var findingsStoreMu sync.Mutex
+
+// SaveFindings replaces the findings of one source for a repo
+func SaveFindings(repoPath, source string, findings []SecurityFinding) error {
+	findingsStoreMu.Lock()
+	defer findingsStoreMu.Unlock()
+
+	if _, err := dataSubDir("findings"); err != nil {
+		return err
+	}
+	path := cacheFilePath("findings", repoPath)
+
+	var rf RepoFindings
+	if err := readJSONFile(path, &rf); err != nil || rf.Sources == nil {
+		rf = RepoFindings{Sources: make(map[string]SourceFindings)}
+	}
+	rf.RepoName = filepath.Base(repoPath)
+	rf.RepoPath = repoPath
+	if findings == nil {
+		findings = []SecurityFinding{}
+	}
+	rf.Sources[source] = SourceFindings{UpdatedAt: time.Now(), Findings: findings}
+	return writeJSONFile(path, &rf)
+}
+
+// LoadFindings returns the stored findings of a repo (empty if none were stored yet)
+func LoadFindings(repoPath string) RepoFindings {
+	findingsStoreMu.Lock()
+	defer findingsStoreMu.Unlock()
+
+	rf := RepoFindings{RepoName: filepath.Base(repoPath), RepoPath: repoPath}
+	if path := cacheFilePath("findings", repoPath); path != "" {
+		readJSONFile(path, &rf)
+	}
+	if rf.Sources == nil {
+		rf.Sources = make(map[string]SourceFindings)
+	}
+	return rf
+}
+
+// ListFindings returns the stored findings of all repos, sorted by repo path
+func ListFindings() ([]RepoFindings, error) {
+	dir, err := dataSubDir("findings")
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	findingsStoreMu.Lock()
+	defer findingsStoreMu.Unlock()
+
+	result := []RepoFindings{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		var rf RepoFindings
+		if err := readJSONFile(filepath.Join(dir, e.Name()), &rf); err == nil {
+			result = append(result, rf)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].RepoPath < result[j].RepoPath })
+	return result, nil
+}
+
+var severityRank = map[string]int{"CRITICAL": 4, "HIGH": 3, "MEDIUM": 2, "LOW": 1}
+
+// ValidSeverity reports whether s is one of CRITICAL, HIGH, MEDIUM or LOW
+func ValidSeverity(s string) bool {
+	_, ok := severityRank[s]
+	return ok
+}
+
+// SeverityAtLeast reports whether severity is min or worse. UNKNOWN ratings never reach a threshold.
+func SeverityAtLeast(severity, min string) bool {
+	rank, ok := severityRank[strings.ToUpper(severity)]
+	return ok && rank >= severityRank[min]
+}
+
+// Key identifies the vulnerability in a package, across sources and scans
+func (f SecurityFinding) Key() string {
+	return strings.ToUpper(f.CVE) + "|" + strings.ToLower(f.Package) + "|" + f.ScanTarget + "|" + f.SubPath
+}
+
+// Consolidated merges the findings of all sources: the same vulnerability in the same package
+// is listed once with every source that reported it and the highest severity any source assigned.
+func (rf RepoFindings) Consolidated() []SecurityFinding {
+	sources := make([]string, 0, len(rf.Sources))
+	for source := range rf.Sources {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	index := make(map[string]int)
+	result := []SecurityFinding{}
+	for _, source := range sources {
+		for _, f := range rf.Sources[source].Findings {
+			key := f.Key()
+			i, ok := index[key]
+			if !ok {
+				f.Sources = []string{source}
+				index[key] = len(result)
+				result = append(result, f)
+				continue
+			}
+
+			merged := &result[i]
+			merged.Sources = append(merged.Sources, source)
+			if severityRank[f.Severity] > severityRank[merged.Severity] {
+				merged.Severity = f.Severity
+			}
+			if f.CVSSScore > merged.CVSSScore {
+				merged.CVSSScore = f.CVSSScore
+			}
+			if merged.Version == "" {
+				merged.Version = f.Version
+			}
+			if merged.FixedIn == "" {
+				merged.FixedIn = f.FixedIn
+			}
+			if merged.Description == "" {
+				merged.Description = f.Description
+			}
+			if merged.URL == "" {
+				merged.URL = f.URL
+			}
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return severityRank[result[i].Severity] > severityRank[result[j].Severity]
+	})
+	return result
+}
@@ -0,0 +1,191 @@
+package logic
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gorecode/updates/internal/cmdlimit"
+)
+
+// Formatters a run can apply
+const (
+	FormatterSpotless = "spotless" // mvn spotless:apply, for builds with the spotless-maven-plugin
+	FormatterPrettier = "prettier" // prettier --write ., for Node.js projects using Prettier
+	FormatterGofmt    = "gofmt"    // gofmt -w, for Go modules
+)
+
+// maxFormatSummaryFiles limits the files listed in the diff summary of the formatting step
+const maxFormatSummaryFiles = 20
+
+// FormatSettings enables the formatting step of a run
+type FormatSettings struct {
+	Enabled    bool     `json:"enabled"`
+	Formatters []string `json:"formatters,omitempty"` // Formatters to apply where the repo is set up for them, empty = all
+}
+
+// Validate rejects unknown formatters
+func (s FormatSettings) Validate() error {
+	for _, formatter := range s.Formatters {
+		if formatter != FormatterSpotless && formatter != FormatterPrettier && formatter != FormatterGofmt {
+			return fmt.Errorf("unknown formatter '%s', expected spotless, prettier or gofmt", formatter)
+		}
+	}
+	return nil
+}
+
+// prettierConfigFiles are the config files marking a project that uses Prettier
+var prettierConfigFiles = []string{
+	".prettierrc", ".prettierrc.json", ".prettierrc.yaml", ".prettierrc.yml", ".prettierrc.json5", ".prettierrc.js",
+	".prettierrc.cjs", ".prettierrc.mjs", ".prettierrc.toml", "prettier.config.js", "prettier.config.cjs", "prettier.config.mjs",
+}
+
+// DetectFormatters returns the formatters the repo is set up for: Spotless in the root pom.xml,
+// Prettier as dependency or config of the root package.json, gofmt for a root go.mod
+func DetectFormatters(repoPath string) []string {
+	var formatters []string
+	if data, err := os.ReadFile(filepath.Join(repoPath, "pom.xml")); err == nil && strings.Contains(string(data), "spotless-maven-plugin") {
+		formatters = append(formatters, FormatterSpotless)
+	}
+	if usesPrettier(repoPath) {
+		formatters = append(formatters, FormatterPrettier)
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, "go.mod")); err == nil {
+		formatters = append(formatters, FormatterGofmt)
+	}
+	return formatters
+}
+
+func usesPrettier(repoPath string) bool {
+	data, err := os.ReadFile(filepath.Join(repoPath, "package.json"))
+	if err != nil {
+		return false
+	}
+	var pkg struct {
+		Prettier        json.RawMessage   `json:"prettier"`
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if json.Unmarshal(data, &pkg) == nil {
+		if len(pkg.Prettier) > 0 || pkg.Dependencies["prettier"] != "" || pkg.DevDependencies["prettier"] != "" {
+			return true
+		}
+	}
+	for _, name := range prettierConfigFiles {
+		if _, err := os.Stat(filepath.Join(repoPath, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// processFormatting runs the enabled formatters the repo is set up for, logs a diff summary and
+// commits the result through the commit queue of the run. A missing formatter is skipped with a
+// warning; false when a formatter or the commit failed.
+func processFormatting(repoPath string, settings FormatSettings, maven MavenSettings, commits *commitQueue, log func(string)) bool {
+	if !settings.Enabled {
+		return true
+	}
+	var formatters []string
+	for _, formatter := range DetectFormatters(repoPath) {
+		if len(settings.Formatters) == 0 || slices.Contains(settings.Formatters, formatter) {
+			formatters = append(formatters, formatter)
+		}
+	}
+	if len(formatters) == 0 {
+		log("  Formatting skipped: no enabled formatter is set up for this repository.")
+		return true
+	}
+
+	var applied []string
+	for _, formatter := range formatters {
+		log(fmt.Sprintf("  Running %s...", formatter))
+		output, err := runFormatter(repoPath, formatter, maven)
+		if err == exec.ErrNotFound {
+			log(fmt.Sprintf("  [WARNING] %s is not installed, formatting with it skipped.", formatter))
+			continue
+		}
+		if err != nil {
+			log(fmt.Sprintf("  [ERROR] %s failed: %v\n%s", formatter, err, strings.TrimSpace(output)))
+			return false
+		}
+		applied = append(applied, formatter)
+	}
+
+	// Formatters only rewrite files, new files (caches, reports) are not committed
+	stat, err := gitOutput(repoPath, "diff", "--shortstat")
+	if err != nil {
+		log(fmt.Sprintf("  [ERROR] git diff failed: %v", err))
+		return false
+	}
+	if stat == "" {
+		log("  Formatting made no changes.")
+		return true
+	}
+	log(fmt.Sprintf("  [INFO] Formatting (%s): %s", strings.Join(applied, ", "), strings.TrimSpace(stat)))
+	if numstat, err := gitOutput(repoPath, "diff", "--numstat"); err == nil {
+		lines := strings.Split(numstat, "\n")
+		for _, line := range lines[:min(len(lines), maxFormatSummaryFiles)] {
+			if fields := strings.SplitN(line, "\t", 3); len(fields) == 3 {
+				log(fmt.Sprintf("    %s +%s -%s", fields[2], fields[0], fields[1]))
+			}
+		}
+		if len(lines) > maxFormatSummaryFiles {
+			log(fmt.Sprintf("    ... and %d more file(s)", len(lines)-maxFormatSummaryFiles))
+		}
+	}
+
+	if err := runGitCommand(repoPath, "add", "-u"); err != nil {
+		log(fmt.Sprintf("  [ERROR] git add failed: %v", err))
+		return false
+	}
+	verb, err := commits.commit(repoPath, "Apply code formatting ("+strings.Join(applied, ", ")+")")
+	if err != nil {
+		log(fmt.Sprintf("  [ERROR] git commit failed: %v", err))
+		return false
+	}
+	log(fmt.Sprintf("  Formatting %s.", verb))
+	return true
+}
+
+// runFormatter runs a formatter on the whole repo; exec.ErrNotFound if its program is missing
+func runFormatter(repoPath, formatter string, maven MavenSettings) (string, error) {
+	switch formatter {
+	case FormatterSpotless:
+		if _, err := exec.LookPath(maven.executable(repoPath)); err != nil {
+			return "", exec.ErrNotFound
+		}
+		output, err := cmdlimit.CombinedOutput(maven.Command(repoPath, "-B", "-q", "spotless:apply"))
+		return string(output), err
+	case FormatterPrettier:
+		if _, err := exec.LookPath("npx"); err != nil {
+			return "", exec.ErrNotFound
+		}
+		// --no-install: the project's own Prettier version, never a download
+		return runTool(repoPath, "npx", "--no-install", "prettier", "--write", ".")
+	case FormatterGofmt:
+		if _, err := exec.LookPath("gofmt"); err != nil {
+			return "", exec.ErrNotFound
+		}
+		files, err := gitOutput(repoPath, "ls-files", "--", "*.go")
+		if err != nil {
+			return "", err
+		}
+		var sources []string
+		for _, file := range strings.Split(files, "\n") {
+			// Vendored code and test fixtures are not ours to format
+			if file != "" && !strings.HasPrefix(file, "vendor/") && !strings.Contains("/"+file, "/testdata/") {
+				sources = append(sources, file)
+			}
+		}
+		if len(sources) == 0 {
+			return "", nil
+		}
+		return runTool(repoPath, "gofmt", append([]string{"-w"}, sources...)...)
+	}
+	return "", fmt.Errorf("unknown formatter '%s'", formatter)
+}
@@ -0,0 +1,212 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorecode/updates/internal/cmdlimit"
+)
+
+// gitAuthTimeout bounds one remote check, e.g. against a firewall silently dropping SSH
+const gitAuthTimeout = 30 * time.Second
+
+// Results of a remote access check
+const (
+	AuthOK          = "ok"
+	AuthCredentials = "credentials" // Missing or rejected credentials (token, password, SSH key)
+	AuthHostKey     = "host-key"    // Unknown or changed SSH host key
+	AuthNetwork     = "network"     // Host unreachable, DNS, proxy or TLS problem
+	AuthNotFound    = "not-found"   // Repository not found (on GitHub/GitLab also: no access)
+	AuthTimeout     = "timeout"
+	AuthNoRemote    = "no-remote" // The repository has no remote
+	AuthError       = "error"
+)
+
+// RemoteHostCheck is the access check of one remote host, done once for all repositories using it
+type RemoteHostCheck struct {
+	Host     string   `json:"host"`     // e.g. "github.com" or "git.example.com:2222"
+	Protocol string   `json:"protocol"` // ssh, https, http, git or file
+	URL      string   `json:"url"`      // Remote URL checked, without password
+	Status   string   `json:"status"`
+	Message  string   `json:"message,omitempty"` // What git said
+	Hint     string   `json:"hint,omitempty"`
+	Repos    []string `json:"repos"` // Names of the repositories using this host
+}
+
+// RepoAuthStatus is the access result of one repository, the one of its remote host
+type RepoAuthStatus struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Host   string `json:"host,omitempty"`
+	Status string `json:"status"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+// GitAuthReport is the result of CheckGitAccess
+type GitAuthReport struct {
+	Hosts    []RemoteHostCheck `json:"hosts"`
+	Repos    []RepoAuthStatus  `json:"repos"`
+	SSHAgent bool              `json:"sshAgent"` // An ssh-agent is reachable (SSH_AUTH_SOCK is set)
+	Failed   int               `json:"failed"`   // Repositories that cannot reach their remote (without no-remote)
+}
+
+// scpLikeURL matches "user@host:path" and "host:path" remotes (SSH), but not "C:\path"
+var scpLikeURL = regexp.MustCompile(`^(?:[^@/\\]+@)?([^:/\\]{2,}):`)
+
+// remoteHost returns the protocol and host of a remote URL and the URL without a password. Local
+// paths and file:// URLs have the protocol "file" and no host.
+func remoteHost(remoteURL string) (protocol, host, redacted string) {
+	if strings.Contains(remoteURL, "://") {
+		u, err := url.Parse(remoteURL)
+		if err != nil {
+			return "", "", remoteURL
+		}
+		if _, hasPassword := u.User.Password(); hasPassword {
+			u.User = url.User(u.User.Username())
+		}
+		protocol = strings.TrimPrefix(strings.TrimSuffix(u.Scheme, "+ssh"), "git+")
+		if protocol == "file" {
+			return protocol, "", u.String()
+		}
+		return protocol, u.Host, u.String()
+	}
+	if m := scpLikeURL.FindStringSubmatch(remoteURL); m != nil {
+		return "ssh", m[1], remoteURL
+	}
+	return "file", "", remoteURL
+}
+
+// gitAuthFailures maps git and ssh messages to a status and a hint, first match wins
+var gitAuthFailures = []struct {
+	pattern *regexp.Regexp
+	status  string
+	hint    string
+}{
+	{regexp.MustCompile(`(?i)host key verification failed|remote host identification has changed|no .* host key is known`), AuthHostKey,
+		"The SSH host key is unknown or has changed. Connect once with 'ssh -T <host>' and verify the fingerprint, or fix ~/.ssh/known_hosts."},
+	{regexp.MustCompile(`(?i)permission denied \(publickey|sign_and_send_pubkey|agent refused operation`), AuthCredentials,
+		"The SSH key was rejected or is not loaded. Add it to the ssh-agent ('ssh-add') and to your account on the host."},
+	{regexp.MustCompile(`(?i)could not read (username|password)|terminal prompts disabled|authentication failed|invalid username or password|\b(401|403)\b|access denied|http basic: access denied`), AuthCredentials,
+		"No valid credentials for this host. Configure a credential helper or a token (e.g. 'git credential-manager' or a personal access token), then check again."},
+	{regexp.MustCompile(`(?i)repository not found|does not appear to be a git repository|project you were looking for could not be found|\b404\b`), AuthNotFound,
+		"The repository was not found. Check the remote URL; GitHub and GitLab also report this when your credentials have no access."},
+	{regexp.MustCompile(`(?i)could not resolve host|name or service not known|connection (refused|timed out|reset)|network is unreachable|no route to host|unable to access|ssl|certificate|proxy|operation timed out`), AuthNetwork,
+		"The host cannot be reached. Check the network, VPN, proxy settings and TLS certificates."},
+}
+
+// classifyGitAuthFailure returns the status and hint for the output of a failed remote command
+func classifyGitAuthFailure(output string) (status, hint string) {
+	for _, f := range gitAuthFailures {
+		if f.pattern.MatchString(output) {
+			return f.status, f.hint
+		}
+	}
+	return AuthError, "See the message of git."
+}
+
+// RemoteURL returns the URL of the repository's primary remote, "" if it has none
+func RemoteURL(repoPath string) string {
+	remote := PrimaryRemote(repoPath)
+	if remote == "" {
+		return ""
+	}
+	remoteURL, _ := gitOutput(repoPath, "remote", "get-url", remote)
+	return remoteURL
+}
+
+// checkRemoteAccess runs 'git ls-remote' against the primary remote without any prompt, so missing
+// credentials fail instead of waiting for input
+func checkRemoteAccess(repoPath string) (status, message, hint string) {
+	ctx, cancel := context.WithTimeout(context.Background(), gitAuthTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", "ls-remote", primaryRemoteOrOrigin(repoPath), "HEAD")
+	cmd.Dir = repoPath
+	cmd.WaitDelay = killWaitDelay
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GCM_INTERACTIVE=never", "GIT_ASKPASS=", "SSH_ASKPASS=")
+	if os.Getenv("GIT_SSH_COMMAND") == "" && os.Getenv("GIT_SSH") == "" {
+		if sshCommand, _ := gitOutput(repoPath, "config", "core.sshCommand"); sshCommand == "" {
+			cmd.Env = append(cmd.Env, "GIT_SSH_COMMAND=ssh -o BatchMode=yes -o ConnectTimeout=15")
+		}
+	}
+	output, err := cmdlimit.CombinedOutput(cmd)
+	if err == nil {
+		return AuthOK, "", ""
+	}
+	message = strings.TrimSpace(string(output))
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return AuthTimeout, message, "No answer within " + gitAuthTimeout.String() + ". A firewall may block the port, or git waits for input it cannot get."
+	}
+	if message == "" {
+		message = err.Error()
+	}
+	status, hint = classifyGitAuthFailure(message)
+	return status, message, hint
+}
+
+// CheckGitAccess checks once per remote host (protocol and host) whether the primary remote can be reached with the
+// configured credentials, and reports the result for every repository using the host. Checks run in
+// parallel; nothing is fetched.
+func CheckGitAccess(repos []string) GitAuthReport {
+	report := GitAuthReport{Hosts: []RemoteHostCheck{}, Repos: make([]RepoAuthStatus, len(repos))}
+	_, report.SSHAgent = os.LookupEnv("SSH_AUTH_SOCK")
+
+	hosts := make(map[string]*RemoteHostCheck)
+	checkedRepo := make(map[string]string) // Host key -> repository whose remote is checked
+	repoHost := make([]string, len(repos))
+	urls := make([]string, len(repos))
+	forEachRepo(repos, func(i int, repo string) {
+		urls[i] = RemoteURL(repo)
+	})
+	for i, repo := range repos {
+		name := filepath.Base(repo)
+		report.Repos[i] = RepoAuthStatus{Name: name, Path: repo}
+		if urls[i] == "" {
+			report.Repos[i].Status = AuthNoRemote
+			report.Repos[i].Hint = "The repository has no remote (or several and none is chosen with 'git config githousekeeper.remote <name>')."
+			continue
+		}
+		protocol, host, redacted := remoteHost(urls[i])
+		key := protocol + "://" + host
+		if host == "" {
+			key = redacted // Local remotes: every path is its own "host"
+		}
+		repoHost[i] = key
+		if hosts[key] == nil {
+			hosts[key] = &RemoteHostCheck{Host: host, Protocol: protocol, URL: redacted}
+			checkedRepo[key] = repo
+		}
+		hosts[key].Repos = append(hosts[key].Repos, name)
+	}
+
+	keys := sortedKeys(hosts)
+	forEachRepo(keys, func(_ int, key string) {
+		h := hosts[key]
+		h.Status, h.Message, h.Hint = checkRemoteAccess(checkedRepo[key])
+		if h.Protocol == "ssh" && h.Status == AuthCredentials && !report.SSHAgent {
+			h.Hint += " No ssh-agent is running for the server (SSH_AUTH_SOCK is not set)."
+		}
+	})
+	for _, key := range keys {
+		report.Hosts = append(report.Hosts, *hosts[key])
+	}
+	sort.SliceStable(report.Hosts, func(i, j int) bool { return report.Hosts[i].Status != AuthOK && report.Hosts[j].Status == AuthOK })
+
+	for i := range report.Repos {
+		if key := repoHost[i]; key != "" {
+			h := hosts[key]
+			report.Repos[i].Host, report.Repos[i].Status, report.Repos[i].Hint = h.Host, h.Status, h.Hint
+		}
+		if status := report.Repos[i].Status; status != AuthOK && status != AuthNoRemote {
+			report.Failed++
+		}
+	}
+	return report
+}
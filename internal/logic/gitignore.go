@@ -0,0 +1,458 @@
+package logic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// GitignoreRule is an entry of the curated ignore template
+type GitignoreRule struct {
+	Pattern    string   `json:"pattern"`
+	Ecosystems []string `json:"ecosystems,omitempty"` // Project types the rule is suggested for (empty = all)
+	AnyProject bool     `json:"anyProject,omitempty"` // Flag committed matches in every repo, not only in the listed ecosystems
+}
+
+// gitignoreTemplate is the curated list of paths that should practically never be committed
+var gitignoreTemplate = []GitignoreRule{
+	// OS and editor files
+	{Pattern: ".DS_Store"},
+	{Pattern: "Thumbs.db"},
+	{Pattern: ".idea/"},
+	{Pattern: "*.iml"},
+	{Pattern: ".vscode/"},
+	{Pattern: "*.swp"},
+	{Pattern: "*.log"},
+	// Build output and dependencies
+	{Pattern: "target/", Ecosystems: []string{"maven"}, AnyProject: true},
+	{Pattern: "node_modules/", Ecosystems: []string{"npm", "yarn", "pnpm"}, AnyProject: true},
+	{Pattern: "__pycache__/", Ecosystems: []string{"python"}, AnyProject: true},
+	{Pattern: "*.pyc", Ecosystems: []string{"python"}, AnyProject: true},
+	{Pattern: ".venv/", Ecosystems: []string{"python"}},
+	{Pattern: "vendor/", Ecosystems: []string{"php"}},
+}
+
+// GitignoreOffender is a tracked file that matches an ignore rule
+type GitignoreOffender struct {
+	Path string `json:"path"`
+	Rule string `json:"rule"`
+}
+
+// GitignoreReport is the .gitignore analysis of a single repo
+type GitignoreReport struct {
+	RepoName     string              `json:"repoName"`
+	RepoPath     string              `json:"repoPath"`
+	ProjectType  string              `json:"projectType"`
+	MissingRules []string            `json:"missingRules"`
+	Offenders    []GitignoreOffender `json:"offenders"`
+	Error        string              `json:"error,omitempty"`
+}
+
+// maxReportedOffenders limits the list per repo (a committed node_modules has thousands of files)
+const maxReportedOffenders = 200
+
+// AnalyzeGitignore compares the tracked files and the .gitignore of a repo with the template
+func AnalyzeGitignore(repoPath string) GitignoreReport {
+	report := GitignoreReport{
+		RepoName: filepath.Base(repoPath),
+		RepoPath: repoPath,
+	}
+	report.ProjectType, _ = detectProjectTypeAndFramework(repoPath)
+
+	tracked, err := gitOutput(repoPath, "ls-files")
+	if err != nil {
+		report.Error = fmt.Sprintf("git ls-files failed: %v", err)
+		return report
+	}
+
+	existing := readGitignorePatterns(filepath.Join(repoPath, ".gitignore"))
+	offendingRules := make(map[string]bool)
+
+	for _, rule := range gitignoreTemplate {
+		applies := ruleAppliesTo(rule, report.ProjectType)
+		if !applies && !rule.AnyProject {
+			continue
+		}
+
+		matcher := &IgnoreMatcher{}
+		matcher.add(rule.Pattern)
+
+		for _, file := range strings.Split(tracked, "\n") {
+			if file == "" || !matcher.Matches(file) {
+				continue
+			}
+			offendingRules[rule.Pattern] = true
+			if len(report.Offenders) < maxReportedOffenders {
+				report.Offenders = append(report.Offenders, GitignoreOffender{Path: file, Rule: rule.Pattern})
+			}
+		}
+
+		if existing[normalizeGitignorePattern(rule.Pattern)] {
+			continue
+		}
+		if offendingRules[rule.Pattern] || applies {
+			report.MissingRules = append(report.MissingRules, rule.Pattern)
+		}
+	}
+
+	return report
+}
+
+func ruleAppliesTo(rule GitignoreRule, projectType string) bool {
+	if len(rule.Ecosystems) == 0 {
+		return true
+	}
+	for _, e := range rule.Ecosystems {
+		if e == projectType {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeGitignorePattern makes "/target", "target/" and "target" comparable
+func normalizeGitignorePattern(p string) string {
+	p = strings.TrimSpace(p)
+	p = strings.TrimPrefix(p, "**/")
+	p = strings.TrimPrefix(p, "/")
+	return strings.TrimSuffix(p, "/")
+}
+
+func readGitignorePatterns(path string) map[string]bool {
+	patterns := make(map[string]bool)
+	for _, p := range readIgnoreFile(path) {
+		patterns[normalizeGitignorePattern(p)] = true
+	}
+	return patterns
+}
+
+// FixGitignore appends the missing rules to .gitignore, untracks the offending files
+// and commits the result on the given branch (created if it does not exist), signed if configured.
+func FixGitignore(report GitignoreReport, branch string, signing SigningSettings, log func(string)) error {
+	if len(report.MissingRules) == 0 && len(report.Offenders) == 0 {
+		log("  Nothing to fix.")
+		return nil
+	}
+	repoPath := report.RepoPath
+
+	if err := requireCleanWorktree(repoPath); err != nil {
+		return err
+	}
+
+	if branch != "" {
+		if err := checkoutOrCreateBranch(repoPath, branch, log); err != nil {
+			return err
+		}
+	}
+
+	if len(report.MissingRules) > 0 {
+		gitignorePath := filepath.Join(repoPath, ".gitignore")
+		content, err := os.ReadFile(gitignorePath)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("could not read .gitignore: %v", err)
+		}
+
+		var sb strings.Builder
+		sb.Write(content)
+		if len(content) > 0 && !strings.HasSuffix(string(content), "\n") {
+			sb.WriteString("\n")
+		}
+		if len(content) > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("# Added by GitHousekeeper\n")
+		for _, rule := range report.MissingRules {
+			sb.WriteString(rule + "\n")
+		}
+
+		if err := os.WriteFile(gitignorePath, []byte(sb.String()), 0644); err != nil {
+			return fmt.Errorf("could not write .gitignore: %v", err)
+		}
+		if err := runGitCommand(repoPath, "add", ".gitignore"); err != nil {
+			return fmt.Errorf("git add .gitignore failed: %v", err)
+		}
+		log(fmt.Sprintf("  [INFO] Added %d rules to .gitignore", len(report.MissingRules)))
+	}
+
+	// Untrack everything matching an offending rule, not only the (truncated) reported list
+	rules := make(map[string]bool)
+	for _, o := range report.Offenders {
+		rules[o.Rule] = true
+	}
+	var pathspecs []string
+	for rule := range rules {
+		pathspecs = append(pathspecs, gitignorePathspec(rule))
+	}
+	sort.Strings(pathspecs)
+	if len(pathspecs) > 0 {
+		args := append([]string{"rm", "-r", "--cached", "--quiet", "--ignore-unmatch", "--"}, pathspecs...)
+		if err := runGitCommand(repoPath, args...); err != nil {
+			return fmt.Errorf("git rm --cached failed: %v", err)
+		}
+		log(fmt.Sprintf("  [INFO] Untracked files matching: %s", strings.Join(pathspecs, ", ")))
+	}
+
+	verb, err := newCommitQueue(CommitPerFile, signing).commit(repoPath, "Improve .gitignore and untrack generated files")
+	if err != nil {
+		return fmt.Errorf("git commit failed: %v", err)
+	}
+	log(fmt.Sprintf("  .gitignore updated and %s.", verb))
+	return nil
+}
+
+// gitignorePathspec converts a template pattern into a git pathspec matching at any depth
+func gitignorePathspec(pattern string) string {
+	p := normalizeGitignorePattern(pattern)
+	if strings.HasSuffix(pattern, "/") {
+		return ":(glob)**/" + p + "/**"
+	}
+	return ":(glob)**/" + p
+}
+
+// Markers of the standard block NormalizeGitignore maintains in a .gitignore
+const (
+	gitignoreBlockStart = "# >>> Organization standard (managed by GitHousekeeper)"
+	gitignoreBlockEnd   = "# <<< Organization standard"
+)
+
+const gitignoreStandardFile = "gitignore-standard.json"
+
+// GitignoreStandard is the org-standard block merged into every .gitignore; no rules = all patterns
+// of the curated template, whatever the project type
+type GitignoreStandard struct {
+	Rules []string `json:"rules"`
+}
+
+var gitignoreStandardMu sync.Mutex
+
+func gitignoreStandardPath() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, gitignoreStandardFile), nil
+}
+
+// DefaultGitignoreStandard returns every pattern of the curated template
+func DefaultGitignoreStandard() GitignoreStandard {
+	var standard GitignoreStandard
+	for _, rule := range gitignoreTemplate {
+		standard.Rules = append(standard.Rules, rule.Pattern)
+	}
+	return standard
+}
+
+// LoadGitignoreStandard returns the stored standard block, the default if none is stored
+func LoadGitignoreStandard() (GitignoreStandard, error) {
+	gitignoreStandardMu.Lock()
+	defer gitignoreStandardMu.Unlock()
+
+	var standard GitignoreStandard
+	path, err := gitignoreStandardPath()
+	if err != nil {
+		return DefaultGitignoreStandard(), err
+	}
+	if err := readJSONFile(path, &standard); err != nil && !os.IsNotExist(err) {
+		return DefaultGitignoreStandard(), err
+	}
+	if len(standard.Rules) == 0 {
+		return DefaultGitignoreStandard(), nil
+	}
+	return standard, nil
+}
+
+// SaveGitignoreStandard stores the standard block without blank lines and duplicates; an empty
+// block restores the default
+func SaveGitignoreStandard(standard GitignoreStandard) (GitignoreStandard, error) {
+	var rules []string
+	seen := make(map[string]bool)
+	for _, rule := range standard.Rules {
+		rule = strings.TrimSpace(rule)
+		if rule == "" || seen[rule] {
+			continue
+		}
+		if rule == gitignoreBlockStart || rule == gitignoreBlockEnd {
+			return standard, fmt.Errorf("'%s' is reserved for the block markers", rule)
+		}
+		seen[rule] = true
+		rules = append(rules, rule)
+	}
+	standard.Rules = rules
+
+	gitignoreStandardMu.Lock()
+	defer gitignoreStandardMu.Unlock()
+	path, err := gitignoreStandardPath()
+	if err != nil {
+		return standard, err
+	}
+	if err := writeJSONFile(path, standard); err != nil {
+		return standard, err
+	}
+	if len(rules) == 0 {
+		return DefaultGitignoreStandard(), nil
+	}
+	return standard, nil
+}
+
+// mergeGitignoreBlock replaces (or appends) the standard block of a .gitignore with the rules not
+// already ignored outside of it. Merging the same rules again gives the same content.
+func mergeGitignoreBlock(content string, rules []string) (string, []string) {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if content == "" {
+		lines = nil
+	}
+	var outside []string
+	previous := make(map[string]bool) // Normalized rules of the existing block
+	inBlock := false
+	insertAt := -1
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == gitignoreBlockStart:
+			inBlock, insertAt = true, len(outside)
+		case trimmed == gitignoreBlockEnd && inBlock:
+			inBlock = false
+		case inBlock:
+			if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+				previous[normalizeGitignorePattern(trimmed)] = true
+			}
+		default:
+			outside = append(outside, line)
+		}
+	}
+
+	existing := make(map[string]bool)
+	for _, line := range outside {
+		if trimmed := strings.TrimSpace(line); trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			existing[normalizeGitignorePattern(trimmed)] = true
+		}
+	}
+	var block, added []string
+	for _, rule := range rules {
+		key := normalizeGitignorePattern(rule)
+		if existing[key] {
+			continue
+		}
+		existing[key] = true
+		block = append(block, rule)
+		if !previous[key] {
+			added = append(added, rule)
+		}
+	}
+	if len(block) > 0 {
+		block = append(append([]string{gitignoreBlockStart}, block...), gitignoreBlockEnd)
+	}
+
+	if insertAt < 0 {
+		insertAt = len(outside)
+		if len(block) > 0 && insertAt > 0 && strings.TrimSpace(outside[insertAt-1]) != "" {
+			block = append([]string{""}, block...)
+		}
+	}
+	merged := append(append(append([]string{}, outside[:insertAt]...), block...), outside[insertAt:]...)
+	if len(merged) == 0 {
+		return "", added
+	}
+	return strings.Join(merged, "\n") + "\n", added
+}
+
+// GitignoreNormalization is the result of merging the standard block into a repo's .gitignore
+type GitignoreNormalization struct {
+	RepoName string              `json:"repoName"`
+	Added    []string            `json:"added,omitempty"`   // Rules new to the .gitignore
+	Changed  bool                `json:"changed"`           // .gitignore was (or would be) rewritten
+	Tracked  []GitignoreOffender `json:"tracked,omitempty"` // Tracked files matching a standard rule
+}
+
+// NormalizeGitignore merges the standard block into the .gitignore of a repo and commits it on branch
+// (created if needed), signed if configured; tracked files matching a standard rule are reported, not
+// untracked (see FixGitignore). A dry run only reports.
+func NormalizeGitignore(repoPath string, rules []string, branch string, signing SigningSettings, dryRun bool, log func(string)) (GitignoreNormalization, error) {
+	result := GitignoreNormalization{RepoName: filepath.Base(repoPath)}
+	gitignorePath := filepath.Join(repoPath, ".gitignore")
+	merge := func() (string, error) {
+		content, err := os.ReadFile(gitignorePath)
+		if err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("could not read .gitignore: %v", err)
+		}
+		merged, added := mergeGitignoreBlock(string(content), rules)
+		result.Added, result.Changed = added, merged != string(content)
+		return merged, nil
+	}
+
+	merged, err := merge()
+	if err != nil {
+		return result, err
+	}
+	verb := "committed"
+	if result.Changed && !dryRun {
+		if err := requireCleanWorktree(repoPath); err != nil {
+			return result, err
+		}
+		if branch != "" {
+			if err := checkoutOrCreateBranch(repoPath, branch, log); err != nil {
+				return result, err
+			}
+		}
+		// An existing branch may have the block already
+		if merged, err = merge(); err != nil {
+			return result, err
+		}
+		if result.Changed {
+			if err := os.WriteFile(gitignorePath, []byte(merged), 0644); err != nil {
+				return result, fmt.Errorf("could not write .gitignore: %v", err)
+			}
+			if err := runGitCommand(repoPath, "add", "--", ".gitignore"); err != nil {
+				return result, fmt.Errorf("git add .gitignore failed: %v", err)
+			}
+			if verb, err = newCommitQueue(CommitPerFile, signing).commit(repoPath, "Normalize .gitignore"); err != nil {
+				return result, fmt.Errorf("git commit failed: %v", err)
+			}
+		}
+	}
+	switch {
+	case !result.Changed:
+		log("  .gitignore already has the standard rules.")
+	case dryRun:
+		log(fmt.Sprintf("  Would update .gitignore (new rules: %s).", strings.Join(result.Added, ", ")))
+	default:
+		log(fmt.Sprintf("  [INFO] .gitignore normalized and %s (new rules: %s).", verb, strings.Join(result.Added, ", ")))
+	}
+
+	tracked, err := gitOutput(repoPath, "ls-files")
+	if err != nil {
+		return result, fmt.Errorf("git ls-files failed: %v", err)
+	}
+	for _, rule := range rules {
+		matcher := &IgnoreMatcher{}
+		matcher.add(rule)
+		for _, file := range strings.Split(tracked, "\n") {
+			if file != "" && matcher.Matches(file) && len(result.Tracked) < maxReportedOffenders {
+				result.Tracked = append(result.Tracked, GitignoreOffender{Path: file, Rule: rule})
+			}
+		}
+	}
+	if len(result.Tracked) > 0 {
+		log(fmt.Sprintf("  [WARN] %d tracked file(s) should be ignored, e.g. %s (%s).", len(result.Tracked), result.Tracked[0].Path, result.Tracked[0].Rule))
+	}
+	return result, nil
+}
+
+// checkoutOrCreateBranch switches to branch, creating it from the current HEAD if needed
+func checkoutOrCreateBranch(repoPath, branch string, log func(string)) error {
+	if branchExists(repoPath, branch) {
+		if err := runGitCommand(repoPath, "checkout", branch); err != nil {
+			return fmt.Errorf("checkout %s failed: %v", branch, err)
+		}
+		log(fmt.Sprintf("  Switched to existing branch '%s'.", branch))
+		return nil
+	}
+	if err := runGitCommand(repoPath, "checkout", "-b", branch); err != nil {
+		return fmt.Errorf("could not create branch '%s': %v", branch, err)
+	}
+	log(fmt.Sprintf("  Branch '%s' created.", branch))
+	return nil
+}
@@ -0,0 +1,112 @@
+package logic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gorecode/updates/internal/gitops"
+)
+
+// GitState describes clone states that break checkout, pull and tag handling,
+// typically found in repos cloned by CI (shallow, detached HEAD)
+type GitState struct {
+	Shallow  bool   `json:"shallow"`
+	Detached bool   `json:"detached"`
+	Head     string `json:"head"` // HEAD SHA
+}
+
+// InspectGitState reports whether the repo is a shallow clone and whether HEAD is detached
+func InspectGitState(path string) GitState {
+	var state GitState
+	shallow, _ := gitOutput(path, "rev-parse", "--is-shallow-repository")
+	state.Shallow = shallow == "true"
+	repo := gitops.Open(path)
+	state.Head, _ = repo.Head()
+	branch, err := repo.CurrentBranch()
+	state.Detached = err != nil || branch == ""
+	return state
+}
+
+// currentBranch returns the checked-out branch, "HEAD" if it is detached (like 'git rev-parse
+// --abbrev-ref HEAD') and "" if it cannot be read
+func currentBranch(path string) string {
+	branch, err := gitops.Open(path).CurrentBranch()
+	if err != nil {
+		return ""
+	}
+	if branch == "" {
+		return "HEAD"
+	}
+	return branch
+}
+
+// requireCleanWorktree fails if tracked files have uncommitted changes; untracked files do not count
+func requireCleanWorktree(path string) error {
+	status, err := gitops.Open(path).Status()
+	if err != nil {
+		return fmt.Errorf("git status failed: %v", err)
+	}
+	for _, line := range status {
+		if !strings.HasPrefix(line, "??") {
+			return fmt.Errorf("uncommitted changes present, commit or stash them first")
+		}
+	}
+	return nil
+}
+
+// Unshallow fetches the complete history of a shallow clone, so tags and merge bases can be resolved
+func Unshallow(path string, log func(string)) error {
+	log("  Shallow clone detected, fetching the complete history (git fetch --unshallow)...")
+	if err := retryGitCommand(path, log, "fetch", "--unshallow", "--tags", primaryRemoteOrOrigin(path)); err != nil {
+		return fmt.Errorf("shallow clone could not be unshallowed: %v", err)
+	}
+	log("  History fetched.")
+	return nil
+}
+
+// headOnBranch reports whether the detached HEAD commit is contained in a local or remote branch,
+// i.e. whether leaving it loses nothing
+func headOnBranch(path string) bool {
+	branches, err := gitOutput(path, "for-each-ref", "--contains", "HEAD", "--format=%(refname)", "refs/heads/", "refs/remotes/")
+	return err == nil && strings.TrimSpace(branches) != ""
+}
+
+// prepareGitState makes a CI-style clone processable: shallow clones are unshallowed and a detached
+// HEAD is accepted only if its commit is on a branch. Returns why the repo cannot be processed otherwise.
+func prepareGitState(path string, log func(string)) error {
+	state := InspectGitState(path)
+	if state.Shallow {
+		if err := Unshallow(path, log); err != nil {
+			return err
+		}
+	}
+	if state.Detached {
+		if !headOnBranch(path) {
+			return fmt.Errorf("detached HEAD at %s has commits that are on no branch; create a branch for them first", shortSHA(state.Head))
+		}
+		log(fmt.Sprintf("  Detached HEAD at %s, re-attaching to a branch.", shortSHA(state.Head)))
+	}
+	return nil
+}
+
+// checkoutBranch checks out a branch, creating it from the primary remote if the clone does not have it locally
+// (single-branch or detached CI clones often lack the default branch)
+func checkoutBranch(path, branch string) error {
+	if branchExists(path, branch) {
+		return runGitCommand(path, "checkout", branch)
+	}
+	remote := primaryRemoteOrOrigin(path)
+	remoteRef := "refs/remotes/" + remote + "/" + branch
+	if runGitCommand(path, "show-ref", "--verify", "--quiet", remoteRef) != nil {
+		// Narrow fetch refspecs do not include the branch, fetch it explicitly
+		if err := runGitCommand(path, "fetch", remote, "+refs/heads/"+branch+":"+remoteRef); err != nil {
+			return fmt.Errorf("branch '%s' exists neither locally nor on %s: %v", branch, remote, err)
+		}
+	}
+	if err := runGitCommand(path, "checkout", "--no-track", "-b", branch, remoteRef); err != nil {
+		return err
+	}
+	// Set the upstream by hand, --track refuses refs outside the fetch refspec
+	runGitCommand(path, "config", "branch."+branch+".remote", remote)
+	return runGitCommand(path, "config", "branch."+branch+".merge", "refs/heads/"+branch)
+}
@@ -0,0 +1,103 @@
+package logic
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GoSettings controls the Go module housekeeping of a run
+type GoSettings struct {
+	UpdateMode string   `json:"updateMode"` // "" = no bulk update, "patch" = go get -u=patch ./..., "minor" = go get -u ./...
+	Modules    []string `json:"modules"`    // Targeted bumps, "module@version" ("module" alone means @latest)
+	GoVersion  string   `json:"goVersion"`  // New go directive, e.g. "1.22"
+	Toolchain  string   `json:"toolchain"`  // New toolchain directive, e.g. "go1.22.4" ("none" removes it)
+}
+
+// readGoModFiles returns go.mod and go.sum, to detect whether the module was changed
+func readGoModFiles(repoPath string) []byte {
+	mod, _ := os.ReadFile(filepath.Join(repoPath, "go.mod"))
+	sum, _ := os.ReadFile(filepath.Join(repoPath, "go.sum"))
+	return append(mod, sum...)
+}
+
+// processGoModule updates the go/toolchain directives and dependencies of a Go module,
+// tidies it and verifies the build. Returns false if a step failed.
+func processGoModule(repoPath string, settings GoSettings, forceBuild bool, log func(string)) bool {
+	before := readGoModFiles(repoPath)
+
+	run := func(description string, args ...string) bool {
+		log(fmt.Sprintf("  %s...", description))
+		if output, err := runTool(repoPath, "go", args...); err != nil {
+			log(fmt.Sprintf("  [ERROR] %s failed: %v\nOutput:\n%s", description, err, output))
+			return false
+		}
+		return true
+	}
+
+	updated := false
+	if v := strings.TrimPrefix(strings.TrimSpace(settings.GoVersion), "go"); v != "" {
+		if !run(fmt.Sprintf("Setting go directive to %s", v), "mod", "edit", "-go="+v) {
+			return false
+		}
+		updated = true
+	}
+	if tc := strings.TrimSpace(settings.Toolchain); tc != "" {
+		if tc != "none" && !strings.HasPrefix(tc, "go") {
+			tc = "go" + tc
+		}
+		if !run(fmt.Sprintf("Setting toolchain directive to %s", tc), "mod", "edit", "-toolchain="+tc) {
+			return false
+		}
+		updated = true
+	}
+
+	for _, module := range settings.Modules {
+		module = strings.TrimSpace(module)
+		if module == "" {
+			continue
+		}
+		if !strings.Contains(module, "@") {
+			module += "@latest"
+		}
+		if !run("go get "+module, "get", module) {
+			return false
+		}
+		updated = true
+	}
+
+	switch settings.UpdateMode {
+	case "patch":
+		if !run("Updating dependencies (patch releases)", "get", "-u=patch", "./...") {
+			return false
+		}
+		updated = true
+	case "minor":
+		if !run("Updating dependencies (minor and patch releases)", "get", "-u", "./...") {
+			return false
+		}
+		updated = true
+	}
+
+	if updated && !run("Running go mod tidy", "mod", "tidy") {
+		return false
+	}
+
+	changed := !bytes.Equal(before, readGoModFiles(repoPath))
+	if changed {
+		log("  go.mod updated.")
+	} else if updated {
+		log("  go.mod already up to date.")
+	}
+
+	if !changed && !forceBuild {
+		return true
+	}
+	if !run("Verifying build (go build ./...)", "build", "./...") {
+		return false
+	}
+	log("  Go build successful.")
+	return true
+}
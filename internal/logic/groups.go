@@ -0,0 +1,174 @@
+package logic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// groupsFile holds the repository groups, relative to DataDir
+const groupsFile = "groups.json"
+
+// RepoGroup is a named set of repositories, e.g. "payments team" or "legacy", so operations
+// can target a logical set instead of a directory layout
+type RepoGroup struct {
+	ID        string    `json:"id"` // Derived from the name like profile IDs, e.g. "payments-team"
+	Name      string    `json:"name"`
+	Repos     []string  `json:"repos"` // Absolute paths of repositories or of folders containing repositories
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+var groupsMu sync.Mutex
+
+func groupsPath() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, groupsFile), nil
+}
+
+func readGroups() ([]RepoGroup, error) {
+	path, err := groupsPath()
+	if err != nil {
+		return nil, err
+	}
+	var groups []RepoGroup
+	if err := readJSONFile(path, &groups); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s: %v", groupsFile, err)
+	}
+	return groups, nil
+}
+
+func writeGroups(groups []RepoGroup) error {
+	path, err := groupsPath()
+	if err != nil {
+		return err
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].ID < groups[j].ID })
+	return writeJSONFile(path, groups)
+}
+
+// ListGroups returns all repository groups, sorted by ID
+func ListGroups() ([]RepoGroup, error) {
+	groupsMu.Lock()
+	defer groupsMu.Unlock()
+	groups, err := readGroups()
+	if groups == nil {
+		groups = []RepoGroup{}
+	}
+	return groups, err
+}
+
+// LoadGroup returns the group with the given ID or name
+func LoadGroup(idOrName string) (*RepoGroup, error) {
+	groupsMu.Lock()
+	defer groupsMu.Unlock()
+	groups, err := readGroups()
+	if err != nil {
+		return nil, err
+	}
+	id := ProfileID(idOrName)
+	for i := range groups {
+		if groups[i].ID == id {
+			return &groups[i], nil
+		}
+	}
+	return nil, fmt.Errorf("repository group '%s' not found", idOrName)
+}
+
+// SaveGroup creates the group or replaces the one with the same name.
+// Paths must be absolute; duplicates are dropped.
+func SaveGroup(name string, repos []string) (*RepoGroup, error) {
+	name = strings.TrimSpace(name)
+	id := ProfileID(name)
+	if id == "" {
+		return nil, fmt.Errorf("group name must contain letters or digits")
+	}
+
+	seen := make(map[string]bool)
+	var paths []string
+	for _, repo := range repos {
+		repo = strings.TrimSpace(repo)
+		if repo == "" {
+			continue
+		}
+		if !filepath.IsAbs(repo) {
+			return nil, fmt.Errorf("repository path '%s' is not absolute", repo)
+		}
+		repo = filepath.Clean(repo)
+		if !seen[repo] {
+			seen[repo] = true
+			paths = append(paths, repo)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("group '%s' has no repositories", name)
+	}
+
+	groupsMu.Lock()
+	defer groupsMu.Unlock()
+	groups, err := readGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	group := RepoGroup{ID: id, Name: name, Repos: paths, UpdatedAt: time.Now()}
+	replaced := false
+	for i := range groups {
+		if groups[i].ID == id {
+			groups[i] = group
+			replaced = true
+		}
+	}
+	if !replaced {
+		groups = append(groups, group)
+	}
+	if err := writeGroups(groups); err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// DeleteGroup removes the group with the given ID
+func DeleteGroup(id string) error {
+	groupsMu.Lock()
+	defer groupsMu.Unlock()
+	groups, err := readGroups()
+	if err != nil {
+		return err
+	}
+	for i := range groups {
+		if groups[i].ID == id {
+			return writeGroups(append(groups[:i], groups[i+1:]...))
+		}
+	}
+	return fmt.Errorf("repository group '%s' not found", id)
+}
+
+// Resolve resolves the group's entries to git repositories: an entry is either a repository
+// or a folder whose repositories are discovered like a root path. Entries that no longer exist are returned as missing.
+func (g *RepoGroup) Resolve(excluded []string) (repos []string, missing []string) {
+	seen := make(map[string]bool)
+	for _, entry := range g.Repos {
+		if info, err := os.Stat(entry); err != nil || !info.IsDir() {
+			missing = append(missing, entry)
+			continue
+		}
+		found := []string{entry}
+		if !IsGitRepo(entry) {
+			found = FindGitRepos(entry, excluded)
+		}
+		for _, repo := range found {
+			if !seen[repo] {
+				seen[repo] = true
+				repos = append(repos, repo)
+			}
+		}
+	}
+	return repos, missing
+}
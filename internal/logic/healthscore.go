@@ -0,0 +1,96 @@
+package logic
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gorecode/updates/internal/logic/score"
+)
+
+// ScorePolicyEnv points to the YAML health score policy, default <data dir>/score-policy.yaml
+const ScorePolicyEnv = "GITHOUSEKEEPER_SCORE_POLICY"
+
+// ScorePolicyPath returns the configured policy file (which may not exist)
+func ScorePolicyPath() (string, error) {
+	if path := os.Getenv(ScorePolicyEnv); path != "" {
+		return path, nil
+	}
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "score-policy.yaml"), nil
+}
+
+// LoadScorePolicy reads the health score policy; without a policy file the built-in penalties apply
+func LoadScorePolicy() (*score.Policy, error) {
+	path, err := ScorePolicyPath()
+	if err != nil {
+		return nil, err
+	}
+	return score.Load(path)
+}
+
+// knownVulnerabilities counts the distinct findings of all sources in the findings store:
+// all severities and CRITICAL/HIGH only
+func knownVulnerabilities(repoPath string) (all, critical int) {
+	seen := make(map[string]bool)
+	for _, source := range LoadFindings(repoPath).Sources {
+		for _, f := range source.Findings {
+			key := f.CVE + "|" + f.Package
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			all++
+			if severity := strings.ToUpper(f.Severity); severity == "CRITICAL" || severity == "HIGH" {
+				critical++
+			}
+		}
+	}
+	return all, critical
+}
+
+// healthFacts collects the score factors of an analyzed repo
+func healthFacts(repoPath string, health RepoHealth, junit4 bool, lastCommit time.Time) score.Facts {
+	facts := score.Facts{
+		score.FactorTodos:           float64(health.TodoCount),
+		score.FactorSpringBootMajor: score.MajorVersion(health.SpringBootVer),
+		score.FactorOutdatedDeps:    float64(health.OutdatedDeps),
+		score.FactorLastCommitDays:  -1,
+	}
+	if junit4 {
+		facts[score.FactorJUnit4] = 1
+	}
+	if !lastCommit.IsZero() {
+		facts[score.FactorLastCommitDays] = math.Floor(time.Since(lastCommit).Hours() / 24)
+	}
+	if len(health.CISystems) == 0 {
+		facts[score.FactorMissingCI] = 1
+	}
+	facts[score.FactorDeprecatedCI] = float64(len(health.CIWarnings))
+	for _, image := range health.BaseImages {
+		if image.Issue != "" {
+			facts[score.FactorBaseImageIssues]++
+		}
+	}
+	for _, support := range health.SupportWindows {
+		if support.Status == SupportEOL {
+			facts[score.FactorEOLRuntimes]++
+		}
+	}
+	if health.TestFileCount == 0 {
+		facts[score.FactorMissingTests] = 1
+	}
+	facts[score.FactorUncoveredPercent] = -1
+	if health.Coverage != nil {
+		facts[score.FactorUncoveredPercent] = 100 - *health.Coverage
+	}
+	all, critical := knownVulnerabilities(repoPath)
+	facts[score.FactorCVEs] = float64(all)
+	facts[score.FactorCriticalCVEs] = float64(critical)
+	return facts
+}
@@ -0,0 +1,294 @@
+package logic
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Standard files every repo should have, in the order they are reported
+const (
+	HygieneReadme       = "README"
+	HygieneLicense      = "LICENSE"
+	HygieneCodeowners   = "CODEOWNERS"
+	HygieneContributing = "CONTRIBUTING"
+	HygieneGitignore    = ".gitignore"
+)
+
+// HygieneFiles lists the standard files checked by CheckRepoHygiene
+var HygieneFiles = []string{HygieneReadme, HygieneLicense, HygieneCodeowners, HygieneContributing, HygieneGitignore}
+
+const hygieneTemplatesFile = "hygiene-templates.json"
+
+// hygieneLocations are the directories GitHub and GitLab look for a standard file in
+var hygieneLocations = map[string][]string{
+	HygieneReadme:       {".", ".github", "docs"},
+	HygieneLicense:      {"."},
+	HygieneCodeowners:   {".", ".github", ".gitlab", "docs"},
+	HygieneContributing: {".", ".github", ".gitlab", "docs"},
+	HygieneGitignore:    {"."},
+}
+
+// hygieneSeedPaths are the files written for missing standard files, relative to the repo
+var hygieneSeedPaths = map[string]string{
+	HygieneReadme:       "README.md",
+	HygieneLicense:      "LICENSE",
+	HygieneCodeowners:   "CODEOWNERS",
+	HygieneContributing: "CONTRIBUTING.md",
+	HygieneGitignore:    ".gitignore",
+}
+
+// RepoHygiene reports which standard files a repo has
+type RepoHygiene struct {
+	Found   map[string]string `json:"found,omitempty"`   // Standard file -> path relative to the repo
+	Missing []string          `json:"missing,omitempty"` // Standard files not found, in HygieneFiles order
+}
+
+// CheckRepoHygiene looks for the standard files in the places GitHub and GitLab read them from:
+// README.md, README.rst and the like, LICENSE, LICENCE or COPYING with any extension.
+func CheckRepoHygiene(repoPath string) RepoHygiene {
+	hygiene := RepoHygiene{Found: make(map[string]string)}
+	for _, name := range HygieneFiles {
+		if path := findHygieneFile(repoPath, name); path != "" {
+			hygiene.Found[name] = path
+		} else {
+			hygiene.Missing = append(hygiene.Missing, name)
+		}
+	}
+	return hygiene
+}
+
+// findHygieneFile returns the first file of the standard file name in its locations, "" if none
+func findHygieneFile(repoPath, name string) string {
+	prefixes := []string{strings.ToLower(name)}
+	if name == HygieneLicense {
+		prefixes = append(prefixes, "licence", "copying")
+	}
+	for _, dir := range hygieneLocations[name] {
+		entries, err := os.ReadDir(filepath.Join(repoPath, dir))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			file := strings.ToLower(entry.Name())
+			for _, prefix := range prefixes {
+				if file == prefix || (name != HygieneGitignore && strings.HasPrefix(file, prefix+".")) {
+					return filepath.ToSlash(filepath.Join(dir, entry.Name()))
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// HygieneTemplates holds the templates (Go text/template) the missing standard files are seeded from.
+// An empty README, CONTRIBUTING or .gitignore template means the default; LICENSE and CODEOWNERS
+// depend on the organization and are only seeded once configured.
+type HygieneTemplates struct {
+	Readme       string `json:"readme,omitempty"`
+	License      string `json:"license,omitempty"`
+	Codeowners   string `json:"codeowners,omitempty"`
+	Contributing string `json:"contributing,omitempty"`
+	Gitignore    string `json:"gitignore,omitempty"`
+}
+
+// DefaultReadmeTemplate is the README seeded when no org template is configured
+const DefaultReadmeTemplate = `# {{.RepoName}}
+
+TODO: Describe what this repository is for, how to build it and how to run it.
+`
+
+// DefaultContributingTemplate is the CONTRIBUTING.md seeded when no org template is configured
+const DefaultContributingTemplate = `# Contributing to {{.RepoName}}
+
+1. Create a branch for your change.
+2. Keep the build and the tests green.
+3. Open a merge request and ask the code owners for a review.
+`
+
+// DefaultGitignoreTemplate is the .gitignore seeded when no org template is configured: the curated
+// rules of the .gitignore audit that apply to the project type
+const DefaultGitignoreTemplate = `{{range .GitignoreRules}}{{.}}
+{{end}}`
+
+// WithDefaults fills in the default templates of README, CONTRIBUTING and .gitignore
+func (t HygieneTemplates) WithDefaults() HygieneTemplates {
+	if strings.TrimSpace(t.Readme) == "" {
+		t.Readme = DefaultReadmeTemplate
+	}
+	if strings.TrimSpace(t.Contributing) == "" {
+		t.Contributing = DefaultContributingTemplate
+	}
+	if strings.TrimSpace(t.Gitignore) == "" {
+		t.Gitignore = DefaultGitignoreTemplate
+	}
+	return t
+}
+
+// Template returns the template of a standard file, "" if none is configured
+func (t HygieneTemplates) Template(name string) string {
+	switch name {
+	case HygieneReadme:
+		return t.Readme
+	case HygieneLicense:
+		return t.License
+	case HygieneCodeowners:
+		return t.Codeowners
+	case HygieneContributing:
+		return t.Contributing
+	case HygieneGitignore:
+		return t.Gitignore
+	}
+	return ""
+}
+
+var hygieneMu sync.Mutex
+
+func hygieneTemplatesPath() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, hygieneTemplatesFile), nil
+}
+
+// LoadHygieneTemplates returns the stored templates with the defaults filled in
+func LoadHygieneTemplates() (HygieneTemplates, error) {
+	hygieneMu.Lock()
+	defer hygieneMu.Unlock()
+
+	var templates HygieneTemplates
+	path, err := hygieneTemplatesPath()
+	if err != nil {
+		return templates.WithDefaults(), err
+	}
+	if err := readJSONFile(path, &templates); err != nil && !os.IsNotExist(err) {
+		return templates.WithDefaults(), err
+	}
+	return templates.WithDefaults(), nil
+}
+
+// SaveHygieneTemplates validates and stores the templates; templates equal to the default are stored
+// empty so that they follow later changes of the default
+func SaveHygieneTemplates(templates HygieneTemplates) (HygieneTemplates, error) {
+	for _, name := range HygieneFiles {
+		if _, err := template.New(name).Parse(templates.Template(name)); err != nil {
+			return templates, fmt.Errorf("invalid %s template: %v", name, err)
+		}
+	}
+	if templates.Readme == DefaultReadmeTemplate {
+		templates.Readme = ""
+	}
+	if templates.Contributing == DefaultContributingTemplate {
+		templates.Contributing = ""
+	}
+	if templates.Gitignore == DefaultGitignoreTemplate {
+		templates.Gitignore = ""
+	}
+
+	hygieneMu.Lock()
+	defer hygieneMu.Unlock()
+	path, err := hygieneTemplatesPath()
+	if err != nil {
+		return templates, err
+	}
+	return templates.WithDefaults(), writeJSONFile(path, templates)
+}
+
+// HygieneTemplateData is what the standard file templates can use
+type HygieneTemplateData struct {
+	RepoName       string
+	ProjectType    string
+	Year           int
+	GitignoreRules []string // Rules of the .gitignore audit for the project type
+}
+
+func hygieneTemplateData(repoPath string) HygieneTemplateData {
+	data := HygieneTemplateData{RepoName: filepath.Base(repoPath), Year: time.Now().Year()}
+	data.ProjectType, _ = detectProjectTypeAndFramework(repoPath)
+	for _, rule := range gitignoreTemplate {
+		if ruleAppliesTo(rule, data.ProjectType) {
+			data.GitignoreRules = append(data.GitignoreRules, rule.Pattern)
+		}
+	}
+	return data
+}
+
+// SeedHygieneFiles writes the requested standard files (empty = all) the repo is missing from their
+// templates and commits them on branch (created if needed), signed if configured. Files without
+// template are skipped. It returns the seeded files.
+func SeedHygieneFiles(repoPath string, names []string, templates HygieneTemplates, branch string, signing SigningSettings, log func(string)) ([]string, error) {
+	if len(names) == 0 {
+		names = HygieneFiles
+	}
+	templates = templates.WithDefaults()
+	missing := func() []string {
+		var result []string
+		for _, name := range CheckRepoHygiene(repoPath).Missing {
+			for _, requested := range names {
+				if requested == name {
+					result = append(result, name)
+				}
+			}
+		}
+		return result
+	}
+	if len(missing()) == 0 {
+		log("  No requested file missing.")
+		return nil, nil
+	}
+
+	if err := requireCleanWorktree(repoPath); err != nil {
+		return nil, err
+	}
+	if branch != "" {
+		if err := checkoutOrCreateBranch(repoPath, branch, log); err != nil {
+			return nil, err
+		}
+	}
+
+	// An existing branch may have some of the files already
+	data := hygieneTemplateData(repoPath)
+	var seeded []string
+	for _, name := range missing() {
+		text := templates.Template(name)
+		if strings.TrimSpace(text) == "" {
+			log(fmt.Sprintf("  [WARN] %s missing, but no template configured.", name))
+			continue
+		}
+		tmpl, err := template.New(name).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s template: %v", name, err)
+		}
+		var out bytes.Buffer
+		if err := tmpl.Execute(&out, data); err != nil {
+			return nil, fmt.Errorf("could not render the %s template: %v", name, err)
+		}
+		file := hygieneSeedPaths[name]
+		if err := os.WriteFile(filepath.Join(repoPath, file), out.Bytes(), 0644); err != nil {
+			return nil, fmt.Errorf("could not write %s: %v", file, err)
+		}
+		log(fmt.Sprintf("  [INFO] %s added.", file))
+		seeded = append(seeded, file)
+	}
+	if len(seeded) == 0 {
+		return nil, nil
+	}
+	if err := runGitCommand(repoPath, append([]string{"add", "--"}, seeded...)...); err != nil {
+		return nil, fmt.Errorf("git add failed: %v", err)
+	}
+	verb, err := newCommitQueue(CommitPerFile, signing).commit(repoPath, "Add "+strings.Join(seeded, ", "))
+	if err != nil {
+		return nil, fmt.Errorf("git commit failed: %v", err)
+	}
+	log(fmt.Sprintf("  %d file(s) added and %s.", len(seeded), verb))
+	return seeded, nil
+}
@@ -0,0 +1,106 @@
+package logic
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RepoIgnoreFile is the per-repo file listing paths to skip during TODO counting and health checks.
+// One pattern per line, '#' starts a comment (same spirit as .gitignore, without negation).
+const RepoIgnoreFile = ".githousekeeper-ignore"
+
+// IgnoreMatcher decides whether a path inside a repository should be skipped by scans
+type IgnoreMatcher struct {
+	patterns []string
+}
+
+// NewIgnoreMatcher combines the global patterns with the patterns from the repo's ignore file
+func NewIgnoreMatcher(repoPath string, globalPatterns []string) *IgnoreMatcher {
+	m := newPathMatcher(globalPatterns)
+	for _, p := range readIgnoreFile(filepath.Join(repoPath, RepoIgnoreFile)) {
+		m.add(p)
+	}
+	return m
+}
+
+// newPathMatcher matches the given patterns only, without a repo ignore file
+func newPathMatcher(patterns []string) *IgnoreMatcher {
+	m := &IgnoreMatcher{}
+	for _, p := range patterns {
+		m.add(p)
+	}
+	return m
+}
+
+func (m *IgnoreMatcher) add(pattern string) {
+	pattern = strings.TrimSpace(filepath.ToSlash(pattern))
+	pattern = strings.TrimPrefix(pattern, "./")
+	pattern = strings.TrimSuffix(strings.TrimSuffix(pattern, "/**"), "/") // "dir/**" is the same as "dir"
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return
+	}
+	m.patterns = append(m.patterns, pattern)
+}
+
+// Matches reports whether the path (relative to the repo root) is ignored.
+// Patterns without a slash match any path segment (e.g. "generated", "*.pb.go"),
+// patterns with a slash match the path or one of its parent directories (e.g. "src/third_party").
+// A leading "**/" matches at any depth.
+func (m *IgnoreMatcher) Matches(relPath string) bool {
+	if m == nil || len(m.patterns) == 0 {
+		return false
+	}
+	relPath = strings.TrimPrefix(filepath.ToSlash(relPath), "./")
+	if relPath == "" || relPath == "." {
+		return false
+	}
+	segments := strings.Split(relPath, "/")
+
+	for _, pattern := range m.patterns {
+		anyDepth := strings.HasPrefix(pattern, "**/")
+		p := strings.TrimPrefix(pattern, "**/")
+
+		if !strings.Contains(p, "/") {
+			for _, seg := range segments {
+				if ok, _ := filepath.Match(p, seg); ok {
+					return true
+				}
+			}
+			continue
+		}
+
+		// Check the path itself and every parent directory
+		depth := strings.Count(p, "/") + 1
+		for start := 0; start+depth <= len(segments); start++ {
+			if start > 0 && !anyDepth {
+				break
+			}
+			candidate := strings.Join(segments[start:start+depth], "/")
+			if ok, _ := filepath.Match(p, candidate); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func readIgnoreFile(path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns
+}
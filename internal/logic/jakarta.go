@@ -0,0 +1,379 @@
+package logic
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorecode/updates/internal/cmdlimit"
+	"github.com/gorecode/updates/internal/logic/registry"
+)
+
+// Jakarta readiness of a dependency
+const (
+	JakartaReplace = "replace" // javax API artifact, swap for its Jakarta coordinates
+	JakartaUpgrade = "upgrade" // Library with a Jakarta-compatible release
+	JakartaBlocked = "blocked" // Library without a Jakarta-compatible release
+	JakartaUnknown = "unknown" // Maven Central could not be checked
+)
+
+// JakartaFinding is a dependency that keeps a repo on the javax.* namespace
+type JakartaFinding struct {
+	Artifact string   `json:"artifact"`      // groupId:artifactId
+	Version  string   `json:"version"`       // Resolved version
+	Via      string   `json:"via,omitempty"` // Direct dependency that pulls it in, empty if declared directly
+	APIs     []string `json:"apis"`          // javax API artifacts it depends on (the artifact itself for JakartaReplace)
+	Status   string   `json:"status"`        // JakartaReplace, JakartaUpgrade, JakartaBlocked or JakartaUnknown
+	Target   string   `json:"target,omitempty"`
+	Reason   string   `json:"reason"`
+}
+
+// JakartaReadiness is the Jakarta EE readiness report of one Maven repo
+type JakartaReadiness struct {
+	RepoName  string           `json:"repoName"`
+	RepoPath  string           `json:"repoPath"`
+	Ready     bool             `json:"ready"`    // No blockers: every javax dependency can be replaced or upgraded
+	Blockers  []JakartaFinding `json:"blockers"` // JakartaBlocked and JakartaUnknown findings
+	Actions   []JakartaFinding `json:"actions"`  // JakartaReplace and JakartaUpgrade findings
+	CheckedAt time.Time        `json:"checkedAt"`
+	Error     string           `json:"error,omitempty"`
+}
+
+// jakartaAPI is an EE API artifact. Artifacts in javax.* groups never moved; the Jakarta artifacts
+// kept the javax packages until JakartaFrom (e.g. jakarta.servlet-api 4.x is still javax.servlet).
+type jakartaAPI struct {
+	Coordinates string // groupId:artifactId
+	JakartaFrom string // First version on the jakarta namespace, "" if never
+	Replacement string // Jakarta coordinates and version to use instead
+}
+
+var jakartaAPIs = []jakartaAPI{
+	{"javax.servlet:javax.servlet-api", "", "jakarta.servlet:jakarta.servlet-api:6.0.0"},
+	{"javax.servlet:servlet-api", "", "jakarta.servlet:jakarta.servlet-api:6.0.0"},
+	{"jakarta.servlet:jakarta.servlet-api", "5.0.0", "jakarta.servlet:jakarta.servlet-api:6.0.0"},
+	{"javax.persistence:javax.persistence-api", "", "jakarta.persistence:jakarta.persistence-api:3.1.0"},
+	{"javax.persistence:persistence-api", "", "jakarta.persistence:jakarta.persistence-api:3.1.0"},
+	{"jakarta.persistence:jakarta.persistence-api", "3.0.0", "jakarta.persistence:jakarta.persistence-api:3.1.0"},
+	{"javax.validation:validation-api", "", "jakarta.validation:jakarta.validation-api:3.0.2"},
+	{"jakarta.validation:jakarta.validation-api", "3.0.0", "jakarta.validation:jakarta.validation-api:3.0.2"},
+	{"javax.xml.bind:jaxb-api", "", "jakarta.xml.bind:jakarta.xml.bind-api:4.0.2"},
+	{"jakarta.xml.bind:jakarta.xml.bind-api", "3.0.0", "jakarta.xml.bind:jakarta.xml.bind-api:4.0.2"},
+	{"javax.ws.rs:javax.ws.rs-api", "", "jakarta.ws.rs:jakarta.ws.rs-api:3.1.0"},
+	{"jakarta.ws.rs:jakarta.ws.rs-api", "3.0.0", "jakarta.ws.rs:jakarta.ws.rs-api:3.1.0"},
+	{"javax.annotation:javax.annotation-api", "", "jakarta.annotation:jakarta.annotation-api:2.1.1"},
+	{"jakarta.annotation:jakarta.annotation-api", "2.0.0", "jakarta.annotation:jakarta.annotation-api:2.1.1"},
+	{"javax.inject:javax.inject", "", "jakarta.inject:jakarta.inject-api:2.0.1"},
+	{"jakarta.inject:jakarta.inject-api", "2.0.0", "jakarta.inject:jakarta.inject-api:2.0.1"},
+	{"javax.transaction:javax.transaction-api", "", "jakarta.transaction:jakarta.transaction-api:2.0.1"},
+	{"jakarta.transaction:jakarta.transaction-api", "2.0.0", "jakarta.transaction:jakarta.transaction-api:2.0.1"},
+	{"javax.mail:javax.mail-api", "", "jakarta.mail:jakarta.mail-api:2.1.3"},
+	{"com.sun.mail:javax.mail", "", "org.eclipse.angus:jakarta.mail:2.0.3"},
+	{"jakarta.mail:jakarta.mail-api", "2.0.0", "jakarta.mail:jakarta.mail-api:2.1.3"},
+	{"javax.activation:activation", "", "jakarta.activation:jakarta.activation-api:2.1.3"},
+	{"javax.activation:javax.activation-api", "", "jakarta.activation:jakarta.activation-api:2.1.3"},
+	{"jakarta.activation:jakarta.activation-api", "2.0.0", "jakarta.activation:jakarta.activation-api:2.1.3"},
+	{"javax.enterprise:cdi-api", "", "jakarta.enterprise:jakarta.enterprise.cdi-api:4.0.1"},
+	{"jakarta.enterprise:jakarta.enterprise.cdi-api", "3.0.0", "jakarta.enterprise:jakarta.enterprise.cdi-api:4.0.1"},
+	{"javax.json:javax.json-api", "", "jakarta.json:jakarta.json-api:2.1.3"},
+	{"jakarta.json:jakarta.json-api", "2.0.0", "jakarta.json:jakarta.json-api:2.1.3"},
+	{"javax.websocket:javax.websocket-api", "", "jakarta.websocket:jakarta.websocket-api:2.1.1"},
+	{"jakarta.websocket:jakarta.websocket-api", "2.0.0", "jakarta.websocket:jakarta.websocket-api:2.1.1"},
+	{"javax.el:javax.el-api", "", "jakarta.el:jakarta.el-api:5.0.1"},
+	{"jakarta.el:jakarta.el-api", "4.0.0", "jakarta.el:jakarta.el-api:5.0.1"},
+	{"javax.xml.ws:jaxws-api", "", "jakarta.xml.ws:jakarta.xml.ws-api:4.0.2"},
+	{"jakarta.xml.ws:jakarta.xml.ws-api", "3.0.0", "jakarta.xml.ws:jakarta.xml.ws-api:4.0.2"},
+	{"javax.faces:javax.faces-api", "", "jakarta.faces:jakarta.faces-api:4.0.1"},
+	{"javax.jms:javax.jms-api", "", "jakarta.jms:jakarta.jms-api:3.1.0"},
+	{"jakarta.jms:jakarta.jms-api", "3.0.0", "jakarta.jms:jakarta.jms-api:3.1.0"},
+}
+
+// jakartaLibrary is a well-known library whose Jakarta support does not show in its POM alone
+type jakartaLibrary struct {
+	Group       string // groupId
+	Artifact    string // artifactId, "" for the whole group
+	SubGroups   bool   // Also matches groupIds below Group (org.glassfish.jersey.core, ...)
+	JakartaFrom string // First Jakarta-compatible version, "" if there is none
+	Note        string // Hint for libraries without a Jakarta release
+}
+
+// jakartaLibraries is checked in order, artifact entries before their group
+var jakartaLibraries = []jakartaLibrary{
+	{Group: "org.springframework.boot", JakartaFrom: "3.0.0"},
+	{Group: "org.springframework.security", JakartaFrom: "6.0.0"},
+	{Group: "org.springframework.data", JakartaFrom: "3.0.0"},
+	{Group: "org.springframework", JakartaFrom: "6.0.0"},
+	{Group: "org.hibernate", Artifact: "hibernate-entitymanager", Note: "merged into org.hibernate.orm:hibernate-core 6"},
+	{Group: "org.hibernate", Artifact: "hibernate-core", JakartaFrom: "6.0.0"},
+	{Group: "org.hibernate.validator", JakartaFrom: "7.0.0"},
+	{Group: "org.apache.tomcat.embed", JakartaFrom: "10.0.0"},
+	{Group: "org.eclipse.jetty", SubGroups: true, JakartaFrom: "11.0.0"},
+	{Group: "org.glassfish.jersey", SubGroups: true, JakartaFrom: "3.0.0"},
+	{Group: "org.jboss.resteasy", SubGroups: true, JakartaFrom: "6.0.0"},
+	{Group: "org.apache.cxf", SubGroups: true, JakartaFrom: "4.0.0"},
+	{Group: "org.glassfish.jaxb", JakartaFrom: "3.0.0"},
+	{Group: "com.sun.xml.bind", JakartaFrom: "3.0.0"},
+	{Group: "org.thymeleaf", Artifact: "thymeleaf-spring5", Note: "use org.thymeleaf:thymeleaf-spring6"},
+	{Group: "org.springdoc", Artifact: "springdoc-openapi-ui", Note: "use org.springdoc:springdoc-openapi-starter-webmvc-ui 2.x"},
+	{Group: "io.springfox", Note: "Springfox is unmaintained, migrate to springdoc-openapi 2.x"},
+}
+
+// findJakartaAPI returns the javax API entry of an artifact, nil if it is not one or already on jakarta.*.
+// Unresolved versions count as javax only for the javax.* artifacts.
+func findJakartaAPI(group, artifact, version string) *jakartaAPI {
+	coordinates := group + ":" + artifact
+	for i, api := range jakartaAPIs {
+		if api.Coordinates != coordinates {
+			continue
+		}
+		if api.JakartaFrom == "" {
+			return &jakartaAPIs[i]
+		}
+		if version == "" || strings.Contains(version, "$") || registry.CompareVersions(version, api.JakartaFrom) >= 0 {
+			return nil
+		}
+		return &jakartaAPIs[i]
+	}
+	return nil
+}
+
+// findJakartaLibrary returns the known Jakarta support of a library, nil if it is not listed
+func findJakartaLibrary(group, artifact string) *jakartaLibrary {
+	for i, lib := range jakartaLibraries {
+		if lib.Artifact != "" {
+			if lib.Group == group && lib.Artifact == artifact {
+				return &jakartaLibraries[i]
+			}
+			continue
+		}
+		if group == lib.Group || (lib.SubGroups && strings.HasPrefix(group, lib.Group+".")) {
+			return &jakartaLibraries[i]
+		}
+	}
+	return nil
+}
+
+// dependencyNode is an artifact of the dependency:tree output
+type dependencyNode struct {
+	Group, Artifact, Version, Scope string
+	Parent                          *dependencyNode // nil for the project itself
+	Direct                          *dependencyNode // Depth-1 ancestor (the node itself if direct)
+}
+
+func (n *dependencyNode) coordinates() string { return n.Group + ":" + n.Artifact }
+
+// parseDependencyTree reads the artifacts of `mvn dependency:tree`. Every module starts a new tree.
+func parseDependencyTree(output string) []*dependencyNode {
+	var nodes []*dependencyNode
+	var stack []*dependencyNode // Ancestors by depth
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		text, ok := strings.CutPrefix(scanner.Text(), "[INFO] ")
+		if !ok || strings.TrimSpace(text) == "" || strings.HasPrefix(text, "---") {
+			stack = nil // A tree ends with the next blank line, goal or log level
+			continue
+		}
+		start := strings.IndexFunc(text, func(r rune) bool { return !strings.ContainsRune("|+-\\ ", r) })
+		if start < 0 {
+			continue
+		}
+		fields := strings.Fields(text[start:])
+		parts := strings.Split(fields[0], ":")
+		// groupId:artifactId:type[:classifier]:version[:scope]
+		if len(parts) < 4 || len(parts) > 6 {
+			continue
+		}
+		depth := start / 3
+		if depth > len(stack) || (depth == 0 && start != 0) {
+			continue // Not part of a tree
+		}
+
+		node := &dependencyNode{Group: parts[0], Artifact: parts[1]}
+		switch len(parts) {
+		case 4: // Project: groupId:artifactId:packaging:version
+			node.Version = parts[3]
+		case 5:
+			node.Version, node.Scope = parts[3], parts[4]
+		case 6:
+			node.Version, node.Scope = parts[4], parts[5]
+		}
+		stack = stack[:depth]
+		if depth > 0 {
+			node.Parent = stack[depth-1]
+			node.Direct = node
+			if depth > 1 {
+				node.Direct = node.Parent.Direct
+			}
+			nodes = append(nodes, node)
+		}
+		stack = append(stack, node)
+	}
+	return nodes
+}
+
+// AnalyzeJakartaReadiness inspects the dependency tree of a Maven repo for artifacts still on the javax.*
+// APIs. javax APIs declared directly can be replaced; libraries pulling them in are checked for a
+// Jakarta-compatible release, known libraries by table, others by the POM of their latest release.
+func AnalyzeJakartaReadiness(repoPath string, maven MavenSettings) JakartaReadiness {
+	report := JakartaReadiness{RepoName: filepath.Base(repoPath), RepoPath: repoPath, Blockers: []JakartaFinding{}, Actions: []JakartaFinding{}}
+	cmd := maven.Command(repoPath, "-B", "dependency:tree")
+	output, err := cmdlimit.CombinedOutput(cmd)
+	report.CheckedAt = time.Now()
+	if err != nil {
+		report.Error = fmt.Sprintf("dependency:tree failed: %v", err)
+		return report
+	}
+	for _, finding := range jakartaFindings(parseDependencyTree(string(output))) {
+		if finding.Status == JakartaBlocked || finding.Status == JakartaUnknown {
+			report.Blockers = append(report.Blockers, finding)
+		} else {
+			report.Actions = append(report.Actions, finding)
+		}
+	}
+	report.Ready = len(report.Blockers) == 0
+	return report
+}
+
+// jakartaFindings groups the javax APIs of a dependency tree by the artifact that requires them
+func jakartaFindings(nodes []*dependencyNode) []JakartaFinding {
+	byArtifact := make(map[string]*JakartaFinding)
+	var order []string
+	add := func(node *dependencyNode, api string) *JakartaFinding {
+		key := node.coordinates() + ":" + node.Version
+		finding, ok := byArtifact[key]
+		if !ok {
+			finding = &JakartaFinding{Artifact: node.coordinates(), Version: node.Version, APIs: []string{}}
+			if node.Direct != node {
+				finding.Via = node.Direct.coordinates()
+			}
+			byArtifact[key] = finding
+			order = append(order, key)
+		}
+		if !slices.Contains(finding.APIs, api) {
+			finding.APIs = append(finding.APIs, api)
+		}
+		return finding
+	}
+
+	for _, node := range nodes {
+		api := findJakartaAPI(node.Group, node.Artifact, node.Version)
+		if api == nil || node.Scope == "test" {
+			continue
+		}
+		owner := node.Parent
+		// APIs required by other APIs (jaxb-api -> activation) go away with their parent
+		if owner != nil && owner.Parent != nil && findJakartaAPI(owner.Group, owner.Artifact, owner.Version) != nil {
+			continue
+		}
+		if owner == nil || owner.Parent == nil {
+			// Declared directly by the project
+			finding := add(node, node.coordinates())
+			finding.Status, finding.Target = JakartaReplace, api.Replacement
+			finding.Reason = "javax API, replace with " + api.Replacement
+			continue
+		}
+		add(owner, node.coordinates())
+	}
+
+	var findings []JakartaFinding
+	for _, key := range order {
+		finding := byArtifact[key]
+		if finding.Status == "" {
+			classifyJakartaLibrary(finding)
+		}
+		sort.Strings(finding.APIs)
+		findings = append(findings, *finding)
+	}
+	return findings
+}
+
+// classifyJakartaLibrary decides whether a library pulling in javax APIs has a Jakarta-compatible release
+func classifyJakartaLibrary(finding *JakartaFinding) {
+	group, artifact, _ := strings.Cut(finding.Artifact, ":")
+	if lib := findJakartaLibrary(group, artifact); lib != nil {
+		if lib.JakartaFrom == "" {
+			finding.Status, finding.Reason = JakartaBlocked, "No Jakarta-compatible release"
+			if lib.Note != "" {
+				finding.Reason += ", " + lib.Note
+			}
+			return
+		}
+		finding.Status, finding.Target = JakartaUpgrade, lib.JakartaFrom
+		finding.Reason = "Jakarta-compatible from " + lib.JakartaFrom
+		return
+	}
+
+	releases, err := registry.Fetch("maven", finding.Artifact)
+	if err != nil {
+		finding.Status, finding.Reason = JakartaUnknown, fmt.Sprintf("Could not check Maven Central: %v", err)
+		return
+	}
+	if releases.Latest == "" || registry.CompareVersions(releases.Latest, finding.Version) <= 0 {
+		finding.Status, finding.Reason = JakartaBlocked, "No newer release than "+finding.Version
+		return
+	}
+	javax, err := pomJavaxAPIs(group, artifact, releases.Latest)
+	if err != nil {
+		finding.Status, finding.Reason = JakartaUnknown, fmt.Sprintf("Could not check %s: %v", releases.Latest, err)
+		return
+	}
+	if len(javax) > 0 {
+		finding.Status = JakartaBlocked
+		finding.Reason = fmt.Sprintf("Latest release %s still depends on %s", releases.Latest, strings.Join(javax, ", "))
+		return
+	}
+	finding.Status, finding.Target = JakartaUpgrade, releases.Latest
+	finding.Reason = fmt.Sprintf("Latest release %s no longer depends on javax APIs", releases.Latest)
+}
+
+// pomJavaxAPIs returns the javax APIs a released POM depends on (test and optional dependencies excluded).
+// Released POMs never change, so the result is cached without expiry.
+func pomJavaxAPIs(group, artifact, version string) ([]string, error) {
+	key := group + ":" + artifact + ":" + version
+	cachePath := cacheFilePath("jakarta-cache", key)
+	var cached struct {
+		APIs []string `json:"apis"`
+	}
+	if cachePath != "" {
+		if err := readJSONFile(cachePath, &cached); err == nil {
+			return cached.APIs, nil
+		}
+	}
+
+	body, err := registry.POM(group, artifact, version)
+	if err != nil {
+		return nil, err
+	}
+	var pom struct {
+		Dependencies []struct {
+			GroupId    string `xml:"groupId"`
+			ArtifactId string `xml:"artifactId"`
+			Version    string `xml:"version"`
+			Scope      string `xml:"scope"`
+			Optional   string `xml:"optional"`
+		} `xml:"dependencies>dependency"`
+	}
+	if err := xml.Unmarshal(body, &pom); err != nil {
+		return nil, err
+	}
+
+	cached.APIs = []string{}
+	for _, dep := range pom.Dependencies {
+		if dep.Scope == "test" || strings.TrimSpace(dep.Optional) == "true" {
+			continue
+		}
+		if findJakartaAPI(dep.GroupId, dep.ArtifactId, dep.Version) != nil {
+			cached.APIs = append(cached.APIs, dep.GroupId+":"+dep.ArtifactId)
+		}
+	}
+	if cachePath != "" {
+		writeJSONFile(cachePath, &cached)
+	}
+	return cached.APIs, nil
+}
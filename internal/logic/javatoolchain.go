@@ -0,0 +1,211 @@
+package logic
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Places a Java version is declared in
+const (
+	JavaSourceMaven      = "Maven"
+	JavaSourceGradle     = "Gradle"
+	JavaSourceSDKMAN     = "SDKMAN"
+	JavaSourceAsdf       = "asdf"
+	JavaSourceJenv       = "jenv"
+	JavaSourceDockerfile = "Dockerfile"
+	JavaSourceSetupJava  = "setup-java"
+)
+
+// JavaVersionSource is a Java version declared by a build file, tool config, Dockerfile or CI workflow
+type JavaVersionSource struct {
+	Source  string `json:"source"`  // JavaSourceMaven, JavaSourceGradle, ...
+	File    string `json:"file"`    // Relative to the repo
+	Version string `json:"version"` // As written, e.g. "17.0.9-tem" or "eclipse-temurin:21-jre"
+	Major   int    `json:"major"`   // Feature release, 1.8 = 8
+}
+
+// JavaToolchain collects all Java versions of a repo and the ones conflicting with the build target
+type JavaToolchain struct {
+	Target    int                 `json:"target"` // Release the build compiles for (Maven or Gradle), 0 if unknown
+	Sources   []JavaVersionSource `json:"sources"`
+	Conflicts []string            `json:"conflicts,omitempty"`
+}
+
+var (
+	javaNumberPattern      = regexp.MustCompile(`\d+(?:\.\d+)?`)
+	gradleToolchainPattern = regexp.MustCompile(`(?:JavaLanguageVersion\.of|jvmToolchain)\(\s*["']?(\d+)`)
+	gradleSourceCompat     = regexp.MustCompile(`(?:sourceCompatibility|targetCompatibility|release)\s*(?:=|\.set\()\s*(?:JavaVersion\.VERSION_)?["']?([\d._]+)`)
+	setupJavaPattern       = regexp.MustCompile(`(?m)^\s*(?:-\s*)?java-version:\s*['"]?([\w.+-]+)`)
+	// JDK and build images name the Java version in the tag, e.g. "21-jre", "3.9-eclipse-temurin-21" or "8-jdk17"
+	javaImageTagPattern = regexp.MustCompile(`(?:jdk|jre|temurin|openjdk|corretto|zulu|semeru|liberica|sapmachine|graalvm|java)-?(\d+)`)
+)
+
+// javaImages are images whose tag starts with the Java version ("eclipse-temurin:17-jre", "amazoncorretto:21")
+var javaImages = map[string]bool{
+	"eclipse-temurin": true, "openjdk": true, "java": true, "amazoncorretto": true, "zulu-openjdk": true,
+	"ibm-semeru-runtimes": true, "sapmachine": true, "liberica-openjdk-debian": true, "liberica-openjdk-alpine": true,
+	"openjdk-jre": true, "jdk": true, "jre": true,
+}
+
+// DetectJavaToolchain finds the Java versions of a repo beyond the POM: Gradle toolchains and compatibility,
+// .sdkmanrc, .tool-versions, .java-version, Dockerfile base images and setup-java in GitHub Actions.
+// pomVersion is the Java version already resolved from the POM ("" if none).
+func DetectJavaToolchain(repoPath, pomVersion string) JavaToolchain {
+	toolchain := JavaToolchain{Sources: []JavaVersionSource{}}
+	addMajor := func(source, file, version string, major int) {
+		if major > 0 {
+			toolchain.Sources = append(toolchain.Sources, JavaVersionSource{Source: source, File: file, Version: version, Major: major})
+		}
+	}
+	add := func(source, file, version string) { addMajor(source, file, version, javaMajor(version)) }
+
+	add(JavaSourceMaven, "pom.xml", pomVersion)
+	for _, file := range []string{"build.gradle.kts", "build.gradle"} {
+		if version := gradleJavaVersion(filepath.Join(repoPath, file)); version != "" {
+			add(JavaSourceGradle, file, version)
+			break
+		}
+	}
+	if len(toolchain.Sources) > 0 {
+		toolchain.Target = toolchain.Sources[0].Major
+	}
+
+	add(JavaSourceSDKMAN, ".sdkmanrc", toolConfigValue(filepath.Join(repoPath, ".sdkmanrc"), "java", "="))
+	add(JavaSourceAsdf, ".tool-versions", toolConfigValue(filepath.Join(repoPath, ".tool-versions"), "java", " "))
+	if data, err := os.ReadFile(filepath.Join(repoPath, ".java-version")); err == nil {
+		add(JavaSourceJenv, ".java-version", strings.TrimSpace(string(data)))
+	}
+
+	for _, dockerfile := range FindDockerfiles(repoPath) {
+		images, err := ParseBaseImages(filepath.Join(repoPath, filepath.FromSlash(dockerfile)))
+		if err != nil {
+			continue
+		}
+		for _, image := range images {
+			addMajor(JavaSourceDockerfile, dockerfile, image, dockerJavaMajor(image))
+		}
+	}
+
+	for _, workflow := range ciFiles(repoPath, ".github/workflows") {
+		data, err := os.ReadFile(filepath.Join(repoPath, filepath.FromSlash(workflow)))
+		if err != nil {
+			continue
+		}
+		for _, m := range setupJavaPattern.FindAllStringSubmatch(string(data), -1) {
+			add(JavaSourceSetupJava, workflow, m[1])
+		}
+	}
+
+	toolchain.Conflicts = javaConflicts(toolchain)
+	return toolchain
+}
+
+// javaConflicts reports the sources using an older Java than the build target, which cannot compile or run
+// the project. A newer JDK is fine. Without a target, sources that disagree with each other are reported.
+func javaConflicts(toolchain JavaToolchain) []string {
+	var conflicts []string
+	seen := make(map[string]bool)
+	add := func(conflict string) {
+		if !seen[conflict] {
+			seen[conflict] = true
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	if toolchain.Target > 0 {
+		target := toolchain.Sources[0]
+		for _, source := range toolchain.Sources[1:] {
+			if source.Major < toolchain.Target {
+				add(fmt.Sprintf("%s targets Java %d, but %s (%s) uses Java %d", target.File, toolchain.Target, source.File, source.Source, source.Major))
+			}
+		}
+		return conflicts
+	}
+
+	majors := make(map[int][]string)
+	for _, source := range toolchain.Sources {
+		majors[source.Major] = append(majors[source.Major], source.File)
+	}
+	if len(majors) < 2 {
+		return nil
+	}
+	var parts []string
+	for major, files := range majors {
+		parts = append(parts, fmt.Sprintf("Java %d in %s", major, strings.Join(files, ", ")))
+	}
+	sort.Strings(parts)
+	add("Different Java versions: " + strings.Join(parts, "; "))
+	return conflicts
+}
+
+// javaMajor returns the feature release of a Java version string: "1.8" and "VERSION_1_8" = 8,
+// "17.0.9-tem" and "temurin-17.0.9+9" = 17. Unresolved properties and ranges return 0.
+func javaMajor(version string) int {
+	if version == "" || strings.Contains(version, "$") {
+		return 0
+	}
+	number := javaNumberPattern.FindString(strings.ReplaceAll(version, "_", "."))
+	if rest, ok := strings.CutPrefix(number, "1."); ok {
+		number = rest
+	}
+	major, _ := strconv.Atoi(strings.Split(number, ".")[0])
+	return major
+}
+
+// gradleJavaVersion reads the Java version of a Gradle build script, preferring the toolchain
+// over sourceCompatibility/targetCompatibility
+func gradleJavaVersion(buildFile string) string {
+	data, err := os.ReadFile(buildFile)
+	if err != nil {
+		return ""
+	}
+	if m := gradleToolchainPattern.FindStringSubmatch(string(data)); m != nil {
+		return m[1]
+	}
+	if m := gradleSourceCompat.FindStringSubmatch(string(data)); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// toolConfigValue reads the value of tool in a "tool<sep>value" file such as .sdkmanrc or .tool-versions
+func toolConfigValue(file, tool, sep string) string {
+	f, err := os.Open(file)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		if name, value, ok := strings.Cut(line, sep); ok && strings.TrimSpace(name) == tool {
+			// .tool-versions may list fallback versions after the first
+			if fields := strings.Fields(value); len(fields) > 0 {
+				return fields[0]
+			}
+		}
+	}
+	return ""
+}
+
+// dockerJavaMajor returns the Java version of a JDK or build image, 0 for other images
+func dockerJavaMajor(image string) int {
+	name, tag, _ := splitImageRef(image)
+	if m := javaImageTagPattern.FindStringSubmatch(tag); m != nil {
+		major, _ := strconv.Atoi(m[1])
+		return major
+	}
+	if javaImages[path.Base(strings.ToLower(name))] {
+		return javaMajor(tag)
+	}
+	return 0
+}
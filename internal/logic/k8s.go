@@ -0,0 +1,238 @@
+package logic
+
+import (
+	"bytes"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// kubernetesManifestMaxSize bounds the YAML files parsed when looking for manifests
+const kubernetesManifestMaxSize = 1 << 20
+
+// KubernetesInventory lists the Kubernetes manifests and Helm charts of a repo and the container
+// images they reference
+type KubernetesInventory struct {
+	Manifests []string    `json:"manifests,omitempty"` // Plain manifests, relative to the repo
+	Charts    []string    `json:"charts,omitempty"`    // Helm chart directories, relative to the repo ("." for the root)
+	Images    []BaseImage `json:"images,omitempty"`    // File is the manifest or values.yaml referencing the image
+}
+
+// Empty reports whether the repo has neither manifests nor charts
+func (k KubernetesInventory) Empty() bool {
+	return len(k.Manifests) == 0 && len(k.Charts) == 0
+}
+
+// FindKubernetes looks for Kubernetes manifests (YAML documents with apiVersion and kind) and Helm
+// charts (directories with a Chart.yaml) and audits the images they reference like base images.
+// The templates of a chart are not valid YAML and are left to helm template.
+func FindKubernetes(repoPath string) KubernetesInventory {
+	var inventory KubernetesInventory
+	filepath.Walk(repoPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(repoPath, path)
+		rel = filepath.ToSlash(rel)
+		if info.IsDir() {
+			if path != repoPath && dockerfileSkipDirs[info.Name()] {
+				return filepath.SkipDir
+			}
+			if info.Name() == "templates" && isHelmChart(filepath.Dir(path)) {
+				return filepath.SkipDir
+			}
+			if isHelmChart(path) {
+				inventory.Charts = append(inventory.Charts, rel)
+				inventory.Images = append(inventory.Images, helmValuesImages(path, rel)...)
+			}
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(info.Name()))
+		if (ext != ".yaml" && ext != ".yml") || info.Size() > kubernetesManifestMaxSize {
+			return nil
+		}
+		// Chart.yaml and values files are not manifests
+		if isHelmChart(filepath.Dir(path)) {
+			return nil
+		}
+		images, ok := manifestImages(path)
+		if !ok {
+			return nil
+		}
+		inventory.Manifests = append(inventory.Manifests, rel)
+		for _, image := range images {
+			inventory.Images = append(inventory.Images, auditImage(rel, image))
+		}
+		return nil
+	})
+	return inventory
+}
+
+// isHelmChart tells whether the directory is the root of a Helm chart
+func isHelmChart(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "Chart.yaml"))
+	return err == nil
+}
+
+// auditImage flags an unpinned or end-of-life image referenced by file
+func auditImage(file, image string) BaseImage {
+	finding := BaseImage{File: file, Image: image}
+	finding.Issue, finding.Detail, finding.Suggestion = checkBaseImage(image)
+	return finding
+}
+
+// manifestImages parses the YAML documents of the file and returns the images of their containers;
+// ok is false when no document is a Kubernetes object
+func manifestImages(path string) (images []string, ok bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	seen := make(map[string]bool)
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			if err != io.EOF {
+				return nil, false // Templated or otherwise invalid YAML
+			}
+			break
+		}
+		object, isMap := doc.(map[string]interface{})
+		if !isMap {
+			continue
+		}
+		apiVersion, _ := object["apiVersion"].(string)
+		kind, _ := object["kind"].(string)
+		if apiVersion == "" || kind == "" {
+			continue
+		}
+		ok = true
+		collectImages(object, func(image string) {
+			if !seen[image] {
+				seen[image] = true
+				images = append(images, image)
+			}
+		})
+	}
+	return images, ok
+}
+
+// collectImages passes every string "image" field of the object tree to add, templated values skipped
+func collectImages(node interface{}, add func(string)) {
+	switch value := node.(type) {
+	case map[string]interface{}:
+		for _, key := range slices.Sorted(maps.Keys(value)) {
+			child := value[key]
+			if image, isString := child.(string); key == "image" && isString {
+				if image = strings.TrimSpace(image); image != "" && !strings.Contains(image, "{{") && !strings.Contains(image, "$") {
+					add(image)
+				}
+				continue
+			}
+			collectImages(child, add)
+		}
+	case []interface{}:
+		for _, child := range value {
+			collectImages(child, add)
+		}
+	}
+}
+
+// helmValuesImages returns the images configured in the values.yaml of a chart: "image: repo:tag" or
+// the common "image: {registry, repository, tag}" block, whose tag defaults to the appVersion
+func helmValuesImages(chartDir, rel string) []BaseImage {
+	var chart struct {
+		AppVersion string `yaml:"appVersion"`
+	}
+	if data, err := os.ReadFile(filepath.Join(chartDir, "Chart.yaml")); err == nil {
+		yaml.Unmarshal(data, &chart)
+	}
+	data, err := os.ReadFile(filepath.Join(chartDir, "values.yaml"))
+	if err != nil {
+		return nil
+	}
+	var values interface{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil
+	}
+
+	file := filepath.ToSlash(filepath.Join(rel, "values.yaml"))
+	var result []BaseImage
+	seen := make(map[string]bool)
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch value := node.(type) {
+		case map[string]interface{}:
+			for _, key := range slices.Sorted(maps.Keys(value)) {
+				child := value[key]
+				if key == "image" {
+					if image := helmImageRef(child, chart.AppVersion); image != "" {
+						if !seen[image] {
+							seen[image] = true
+							result = append(result, auditImage(file, image))
+						}
+						continue
+					}
+				}
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range value {
+				walk(child)
+			}
+		}
+	}
+	walk(values)
+	return result
+}
+
+// helmImageRef builds the image reference of an image value, "" when it is none
+func helmImageRef(value interface{}, appVersion string) string {
+	switch image := value.(type) {
+	case string:
+		if strings.Contains(image, "{{") {
+			return ""
+		}
+		return strings.TrimSpace(image)
+	case map[string]interface{}:
+		repository, _ := image["repository"].(string)
+		if repository == "" || strings.Contains(repository, "{{") {
+			return ""
+		}
+		if registry, _ := image["registry"].(string); registry != "" {
+			repository = strings.TrimSuffix(registry, "/") + "/" + repository
+		}
+		tag := scalarString(image["tag"])
+		if tag == "" {
+			tag = appVersion
+		}
+		if digest, _ := image["digest"].(string); digest != "" {
+			return repository + "@" + digest
+		}
+		if tag == "" {
+			return repository
+		}
+		return repository + ":" + tag
+	}
+	return ""
+}
+
+// scalarString returns a YAML scalar as string; unquoted tags like 1.25 are parsed as numbers, so
+// trailing zeros of an unquoted tag are lost
+func scalarString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case nil:
+		return ""
+	default:
+		out, _ := yaml.Marshal(v)
+		return strings.TrimSpace(string(out))
+	}
+}
@@ -0,0 +1,250 @@
+package logic
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorecode/updates/internal/cmdlimit"
+)
+
+// LFSSettings moves files to Git LFS during a run. The files at HEAD matching the patterns are converted
+// to LFS pointers in a new commit on the work branch (git add --renormalize), history is not rewritten.
+type LFSSettings struct {
+	Patterns []string `json:"patterns"` // .gitattributes patterns, e.g. "*.psd" or "assets/videos/**"
+}
+
+// HeadFile is a file of the HEAD tree with its blob size
+type HeadFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// LFSReport describes the Git LFS usage of a repo
+type LFSReport struct {
+	RepoName          string     `json:"repoName"`
+	RepoPath          string     `json:"repoPath"`
+	UsesLFS           bool       `json:"usesLfs"`
+	Patterns          []string   `json:"patterns"`                    // filter=lfs patterns of .gitattributes
+	LargeFiles        []HeadFile `json:"largeFiles"`                  // Files at HEAD stored without LFS, largest first
+	SuggestedPatterns []string   `json:"suggestedPatterns,omitempty"` // Patterns covering the large files
+	Error             string     `json:"error,omitempty"`
+}
+
+// LFSAvailable reports whether the git-lfs extension is installed
+func LFSAvailable() bool {
+	return cmdlimit.Run(exec.Command("git", "lfs", "version")) == nil
+}
+
+// AnalyzeLFS reads the LFS patterns of a repo and lists files at HEAD that are too large to be stored without LFS.
+// LFS pointers are tiny, so every large blob at HEAD is stored in Git itself.
+func AnalyzeLFS(repoPath string) LFSReport {
+	report := LFSReport{RepoName: filepath.Base(repoPath), RepoPath: repoPath, Patterns: lfsPatterns(repoPath), LargeFiles: []HeadFile{}}
+	report.UsesLFS = len(report.Patterns) > 0
+
+	files, err := headFiles(repoPath)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+	suggested := make(map[string]bool)
+	for _, file := range files {
+		if file.Size < largeBlobBytes {
+			continue
+		}
+		report.LargeFiles = append(report.LargeFiles, file)
+		pattern := file.Path
+		if ext := path.Ext(file.Path); ext != "" {
+			pattern = "*" + ext
+		}
+		if !suggested[pattern] {
+			suggested[pattern] = true
+			report.SuggestedPatterns = append(report.SuggestedPatterns, pattern)
+		}
+	}
+	sort.SliceStable(report.LargeFiles, func(i, j int) bool { return report.LargeFiles[i].Size > report.LargeFiles[j].Size })
+	sort.Strings(report.SuggestedPatterns)
+	return report
+}
+
+// lfsPatterns returns the patterns of the root .gitattributes that use the lfs filter
+func lfsPatterns(repoPath string) []string {
+	patterns := []string{}
+	data, err := os.ReadFile(filepath.Join(repoPath, ".gitattributes"))
+	if err != nil {
+		return patterns
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		for _, attr := range fields[1:] {
+			if attr == "filter=lfs" {
+				patterns = append(patterns, fields[0])
+				break
+			}
+		}
+	}
+	return patterns
+}
+
+// headFiles lists the blobs of the HEAD tree with their sizes (git ls-tree -r -l, NUL separated for unusual names)
+func headFiles(repoPath string) ([]HeadFile, error) {
+	out, err := gitOutput(repoPath, "ls-tree", "-r", "-l", "-z", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("git ls-tree failed: %v", err)
+	}
+	var files []HeadFile
+	for _, line := range strings.Split(out, "\x00") {
+		// "<mode> blob <hash> <size>\t<path>"
+		meta, filePath, ok := strings.Cut(line, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 4 || fields[1] != "blob" {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			continue
+		}
+		files = append(files, HeadFile{Path: filePath, Size: size})
+	}
+	return files, nil
+}
+
+// lfsPatternMatches applies a .gitattributes pattern to a repo-relative path: patterns without a slash
+// match the file name at any depth, "dir/**" matches everything below dir, others the whole path
+func lfsPatternMatches(pattern, filePath string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+		return strings.HasPrefix(filePath, prefix+"/")
+	}
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(filePath))
+		return ok
+	}
+	ok, _ := path.Match(pattern, filePath)
+	return ok
+}
+
+// lfsMigrationFiles returns the files at HEAD matching one of the patterns that are not LFS pointers yet
+func lfsMigrationFiles(repoPath string, patterns []string) ([]HeadFile, error) {
+	files, err := headFiles(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	pointers := make(map[string]bool)
+	if out, err := gitOutput(repoPath, "lfs", "ls-files", "--name-only"); err == nil {
+		for _, name := range strings.Split(out, "\n") {
+			pointers[strings.TrimSpace(name)] = true
+		}
+	}
+	var result []HeadFile
+	for _, file := range files {
+		if pointers[file.Path] {
+			continue
+		}
+		for _, pattern := range patterns {
+			if lfsPatternMatches(pattern, file.Path) {
+				result = append(result, file)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// LFSDryRun logs what a migration of the patterns would convert at HEAD and how much
+// of the history matches (git lfs migrate info), without changing anything
+func LFSDryRun(repoPath string, patterns []string, log func(string)) error {
+	if !LFSAvailable() {
+		return fmt.Errorf("git-lfs is not installed")
+	}
+	files, err := lfsMigrationFiles(repoPath, patterns)
+	if err != nil {
+		return err
+	}
+	var total int64
+	for _, file := range files {
+		total += file.Size
+		log(fmt.Sprintf("  would move %s (%d KiB)", file.Path, file.Size>>10))
+	}
+	log(fmt.Sprintf("  %d file(s) at HEAD, %d KiB would move to Git LFS.", len(files), total>>10))
+
+	out, err := gitOutput(repoPath, "lfs", "migrate", "info", "--include="+strings.Join(patterns, ","))
+	if err != nil {
+		return fmt.Errorf("git lfs migrate info failed: %v", err)
+	}
+	log("  History of the current branch (git lfs migrate info):")
+	for _, line := range strings.Split(out, "\n") {
+		if strings.TrimSpace(line) != "" {
+			log("    " + line)
+		}
+	}
+	return nil
+}
+
+// processLFSMigration tracks the patterns in .gitattributes and converts the matching files at HEAD
+// to LFS pointers, committed through the commit queue of the run. Returns false on error.
+func processLFSMigration(repoPath string, settings LFSSettings, commits *commitQueue, log func(string)) bool {
+	if len(settings.Patterns) == 0 {
+		return true
+	}
+	log(fmt.Sprintf("  Moving %s to Git LFS...", strings.Join(settings.Patterns, ", ")))
+	if !LFSAvailable() {
+		log("  [ERROR] git-lfs is not installed.")
+		return false
+	}
+	if err := runGitCommand(repoPath, "lfs", "install", "--local"); err != nil {
+		log(fmt.Sprintf("  [ERROR] git lfs install failed: %v", err))
+		return false
+	}
+
+	// The files must be tracked before the clean filter turns them into pointers
+	if err := runGitCommand(repoPath, append([]string{"lfs", "track"}, settings.Patterns...)...); err != nil {
+		log(fmt.Sprintf("  [ERROR] git lfs track failed: %v", err))
+		return false
+	}
+	if status, _ := gitOutput(repoPath, "status", "--porcelain", "--", ".gitattributes"); status != "" {
+		if err := runGitCommand(repoPath, "add", ".gitattributes"); err != nil {
+			log(fmt.Sprintf("  [ERROR] git add .gitattributes failed: %v", err))
+			return false
+		}
+		verb, err := commits.commit(repoPath, "Track "+strings.Join(settings.Patterns, ", ")+" with Git LFS")
+		if err != nil {
+			log(fmt.Sprintf("  [ERROR] git commit failed: %v", err))
+			return false
+		}
+		log(fmt.Sprintf("  [INFO] .gitattributes updated and %s.", verb))
+	}
+
+	files, err := lfsMigrationFiles(repoPath, settings.Patterns)
+	if err != nil {
+		log(fmt.Sprintf("  [ERROR] %v", err))
+		return false
+	}
+	if len(files) == 0 {
+		log("  No files to move, new files are stored in LFS from now on.")
+		return true
+	}
+	// Re-adding the files runs the LFS clean filter of the tracked patterns, the index gets the pointers
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	if err := runGitCommand(repoPath, append([]string{"add", "--renormalize", "--"}, paths...)...); err != nil {
+		log(fmt.Sprintf("  [ERROR] git add --renormalize failed: %v", err))
+		return false
+	}
+	if _, err := commits.commit(repoPath, fmt.Sprintf("Move %d file(s) to Git LFS", len(files))); err != nil {
+		log(fmt.Sprintf("  [ERROR] git commit failed: %v", err))
+		return false
+	}
+	log(fmt.Sprintf("  %d file(s) moved to Git LFS.", len(files)))
+	return true
+}
@@ -0,0 +1,234 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Environment variables configuring the server log
+const (
+	LogLevelEnv   = "GITHOUSEKEEPER_LOG_LEVEL"  // debug, info (default), warn or error
+	LogFormatEnv  = "GITHOUSEKEEPER_LOG_FORMAT" // text (default) or json
+	LogFileEnv    = "GITHOUSEKEEPER_LOG_FILE"   // Log file path, default <data dir>/logs/githousekeeper.log, "off" = console only
+	LogMaxSizeEnv = "GITHOUSEKEEPER_LOG_MAX_MB" // Size in MB after which the log file is rotated (default 10)
+)
+
+const (
+	logBackups  = 3    // Rotated files kept next to the log file (.1 = newest)
+	logTailSize = 1000 // Entries kept in memory for /api/logs/tail
+)
+
+// LogEntry is a log record as returned by TailLogs
+type LogEntry struct {
+	Time    time.Time         `json:"time"`
+	Level   string            `json:"level"`
+	Message string            `json:"message"`
+	Attrs   map[string]string `json:"attrs,omitempty"`
+}
+
+// logTail keeps the latest entries so the UI can show server diagnostics without file access
+var logTail = &tailBuffer{}
+
+type tailBuffer struct {
+	mu      sync.Mutex
+	entries []LogEntry
+	levels  []slog.Level
+	next    int
+}
+
+func (b *tailBuffer) add(entry LogEntry, level slog.Level) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.entries) < logTailSize {
+		b.entries = append(b.entries, entry)
+		b.levels = append(b.levels, level)
+		return
+	}
+	b.entries[b.next], b.levels[b.next] = entry, level
+	b.next = (b.next + 1) % logTailSize
+}
+
+// TailLogs returns the newest n log entries at minLevel or above, oldest first
+func TailLogs(n int, minLevel slog.Level) []LogEntry {
+	b := logTail
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	result := []LogEntry{}
+	for i := range b.entries {
+		idx := (b.next + i) % len(b.entries)
+		if b.levels[idx] >= minLevel {
+			result = append(result, b.entries[idx])
+		}
+	}
+	if n > 0 && len(result) > n {
+		result = result[len(result)-n:]
+	}
+	return result
+}
+
+// ParseLogLevel parses debug, info, warn or error ("" = info)
+func ParseLogLevel(value string) (slog.Level, error) {
+	var level slog.Level
+	if value == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		return level, fmt.Errorf("unknown log level '%s', expected debug, info, warn or error", value)
+	}
+	return level, nil
+}
+
+// tailHandler records every handled entry in logTail before passing it on
+type tailHandler struct {
+	slog.Handler
+	attrs []slog.Attr
+}
+
+func (h *tailHandler) Handle(ctx context.Context, r slog.Record) error {
+	entry := LogEntry{Time: r.Time, Level: r.Level.String(), Message: r.Message}
+	addAttr := func(a slog.Attr) bool {
+		if entry.Attrs == nil {
+			entry.Attrs = make(map[string]string)
+		}
+		entry.Attrs[a.Key] = a.Value.String()
+		return true
+	}
+	for _, a := range h.attrs {
+		addAttr(a)
+	}
+	r.Attrs(addAttr)
+	logTail.add(entry, r.Level)
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *tailHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &tailHandler{Handler: h.Handler.WithAttrs(attrs), attrs: append(append([]slog.Attr{}, h.attrs...), attrs...)}
+}
+
+func (h *tailHandler) WithGroup(name string) slog.Handler {
+	return &tailHandler{Handler: h.Handler.WithGroup(name), attrs: h.attrs}
+}
+
+// SetupLogging installs the default slog logger configured by the GITHOUSEKEEPER_LOG_* variables:
+// console output plus a size-rotated log file, text or JSON. Returns the log file path ("" = none).
+func SetupLogging() (string, error) {
+	level, err := ParseLogLevel(os.Getenv(LogLevelEnv))
+	if err != nil {
+		return "", err
+	}
+
+	var out io.Writer = os.Stdout
+	path := os.Getenv(LogFileEnv)
+	if path == "" {
+		dir, err := dataSubDir("logs")
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(dir, "githousekeeper.log")
+	}
+	if strings.EqualFold(path, "off") {
+		path = ""
+	} else {
+		maxMB := 10
+		if value := os.Getenv(LogMaxSizeEnv); value != "" {
+			if maxMB, err = strconv.Atoi(value); err != nil || maxMB < 1 {
+				return "", fmt.Errorf("invalid %s '%s'", LogMaxSizeEnv, value)
+			}
+		}
+		file, err := OpenRotatingFile(path, int64(maxMB)<<20, logBackups)
+		if err != nil {
+			return "", err
+		}
+		out = io.MultiWriter(os.Stdout, file)
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch format := os.Getenv(LogFormatEnv); format {
+	case "", "text":
+		handler = slog.NewTextHandler(out, options)
+	case "json":
+		handler = slog.NewJSONHandler(out, options)
+	default:
+		return "", fmt.Errorf("unknown log format '%s', expected text or json", format)
+	}
+	slog.SetDefault(slog.New(&tailHandler{Handler: handler}))
+	return path, nil
+}
+
+// RotatingFile is an append-only log file that is renamed to <path>.1 (older ones to .2, ...)
+// once it exceeds maxSize
+type RotatingFile struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	backups int
+	file    *os.File
+	size    int64
+}
+
+// OpenRotatingFile opens (or creates) the log file at path
+func OpenRotatingFile(path string, maxSize int64, backups int) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f := &RotatingFile{path: path, maxSize: maxSize, backups: backups}
+	return f, f.open()
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *RotatingFile) rotate() error {
+	f.file.Close()
+	os.Remove(fmt.Sprintf("%s.%d", f.path, f.backups))
+	for i := f.backups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", f.path, i), fmt.Sprintf("%s.%d", f.path, i+1))
+	}
+	if f.backups > 0 {
+		os.Rename(f.path, f.path+".1")
+	} else {
+		os.Remove(f.path)
+	}
+	return f.open()
+}
+
+// Close closes the log file
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
@@ -1,10 +1,9 @@
 package logic
 
 import (
-	"encoding/xml"
+	"context"
 	"fmt"
-	"io"
-	"net/http"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -12,28 +11,58 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/gorecode/updates/internal/cmdlimit"
+	"github.com/gorecode/updates/internal/logic/registry"
 )
 
 type Replacement struct {
 	Search  string
 	Replace string
+	Type    string   // "" = text search/replace, "regex" = regular expression, "property" = set the pom property named in Search
+	Include []string // Optional path globs the rule is limited to, e.g. "**/*.yaml" or "src/main/resources"
+	Exclude []string // Optional path globs the rule never touches, e.g. "src/test"
 }
 
 type ReportEntry struct {
 	RepoPath          string
 	Messages          []string
 	Success           bool
+	Skipped           bool // Left alone (skip in .githousekeeper.yaml or uncommitted changes with DirtySkip)
 	DeprecationOutput string
+	Tests             *TestSummary  // Surefire/Failsafe results of the build, nil if no tests ran
+	BuildFailure      *BuildFailure // Structured cause of a failed build, nil if it succeeded or was not recognized
+	Deprecations      []Deprecation // Structured deprecation warnings of the build
+	BuildStatus       string        // BuildSuccess, BuildFailed, BuildTimedOut, BuildSkipped or "" if no build ran
+	TimedOut          string        // Step that exceeded its timeout (StepGit, StepMaven, ...), "" if none did
+	Snapshot          RepoSnapshot  // Pre-run git state, used for rollback
 }
 
 type RepoOptions struct {
 	Replacements        []Replacement
 	ReplacementScope    string // "all", "pom-only", "exclude-pom"
 	TargetParentVersion string
+	TargetNodeVersion   string // Node.js version for .nvmrc, engines.node and setup-node, "" = unchanged
 	VersionBumpStrategy string
-	RunCleanInstall     bool
+	RunCleanInstall     bool   // Build even without changes
+	VerificationLevel   string // What the build runs: VerifyDefault, VerifyNone, VerifyCompile, VerifyTest, VerifyVerify or VerifyCustom
+	VerificationGoal    string // Maven goals and options of VerifyCustom, e.g. "clean verify -Pci"
 	ExcludedFolders     []string
 	TargetBranch        string // "housekeeping", "custom-name", or "" (for master)
+	CommitStrategy      string // "per-file" (default), "per-rule" or "single"
+	Signing             SigningSettings
+	CreateTag           CreateTagSettings // Annotated tag on the result of the run
+	DirtyTree           string            // Handling of uncommitted changes: DirtyStashRestore (default), DirtyStash, DirtySkip or DirtyAbort
+	RunID               string            // ID of the run this repo belongs to (used to label stashes)
+	Tags                TagSettings
+	Maven               MavenSettings
+	Go                  GoSettings
+	Python              PythonSettings
+	Php                 PhpSettings
+	LFS                 LFSSettings
+	Format              FormatSettings
+	Changelog           bool // CHANGELOG.md updated with the commits since the latest release tag
+	Timeouts            StepTimeouts
 	Log                 func(string)
 }
 
@@ -43,48 +72,93 @@ func IsGitRepo(path string) bool {
 	return err == nil && info.IsDir()
 }
 
-func FindGitRepos(root string, excluded []string) []string {
-	var repos []string
-
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
+// FilterRepos narrows discovered repos to an explicit selection. Entries of include and exclude
+// match a repo by absolute path, by path relative to one of the roots or by folder name. An empty
+// include keeps every repo; exclude wins over include. Returns the include entries that matched no repo.
+func FilterRepos(repos []string, roots []string, include, exclude []string) (filtered []string, unmatched []string) {
+	matches := func(repo, entry string) bool {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			return false
 		}
-
-		if info.IsDir() {
-			// Check for .git FIRST, before exclusions
-			if info.Name() == ".git" {
-				repoPath := filepath.Dir(path)
-				repos = append(repos, repoPath)
-				return filepath.SkipDir
+		if filepath.IsAbs(entry) {
+			return filepath.Clean(entry) == filepath.Clean(repo)
+		}
+		if entry == filepath.Base(repo) {
+			return true
+		}
+		for _, root := range roots {
+			if root != "" && filepath.Join(root, entry) == filepath.Clean(repo) {
+				return true
 			}
+		}
+		return false
+	}
 
-			// Then check exclusions (but .git should never be excluded from detection)
-			for _, ex := range excluded {
-				if ex == ".git" {
-					continue // Don't exclude .git from detection - we need it to find repos
-				}
-				if info.Name() == ex {
-					return filepath.SkipDir
-				}
+	used := make([]bool, len(include))
+	for _, repo := range repos {
+		keep := len(include) == 0
+		for i, entry := range include {
+			if matches(repo, entry) {
+				keep = true
+				used[i] = true
 			}
 		}
-		return nil
-	})
-
-	if err != nil {
-		fmt.Printf("Error searching: %v\n", err)
+		for _, entry := range exclude {
+			if matches(repo, entry) {
+				keep = false
+			}
+		}
+		if keep {
+			filtered = append(filtered, repo)
+		}
 	}
-	return repos
+	for i, entry := range include {
+		if !used[i] && strings.TrimSpace(entry) != "" {
+			unmatched = append(unmatched, entry)
+		}
+	}
+	return filtered, unmatched
 }
 
 func ProcessRepo(path string, opts RepoOptions) ReportEntry {
+	timer := newStepTimer(opts.Timeouts)
+	entry := processRepo(path, opts, timer)
+	entry.TimedOut = timer.timedOut
+	if entry.Snapshot.RepoPath == "" {
+		return entry
+	}
+
+	log := opts.Log
+	if log == nil {
+		log = func(msg string) {
+			slog.Info(strings.TrimSpace(msg))
+		}
+	}
+	captureLog := func(msg string) {
+		entry.Messages = append(entry.Messages, msg)
+		log(msg)
+	}
+
+	if opts.CreateTag.Enabled && entry.Success {
+		entry.Snapshot.CreatedTag, entry.Snapshot.TagPushed = createRunTag(path, opts.CreateTag, opts.Signing, opts.RunID, captureLog)
+	}
+	if opts.DirtyTree == DirtyStashRestore || opts.DirtyTree == "" {
+		restoreStashedChanges(&entry.Snapshot, captureLog)
+	} else if entry.Snapshot.StashMessage != "" {
+		captureLog("  [INFO] Uncommitted changes stay stashed; a rollback or 'git stash pop' restores them.")
+	}
+	return entry
+}
+
+// processRepo runs the housekeeping steps; ProcessRepo tags the result afterwards
+func processRepo(path string, opts RepoOptions, timer *stepTimer) ReportEntry {
 	entry := ReportEntry{RepoPath: path, Success: true}
-	// Use provided logger or fallback to stdout
+	// Use provided logger or fallback to the server log
 	log := opts.Log
 	if log == nil {
 		log = func(msg string) {
-			fmt.Println(msg)
+			slog.Info(strings.TrimSpace(msg))
 		}
 	}
 
@@ -96,22 +170,101 @@ func ProcessRepo(path string, opts RepoOptions) ReportEntry {
 
 	captureLog(fmt.Sprintf("Processing: %s", path))
 
+	// Network git commands can hang on unreachable remotes or credential prompts
+	gitStep := func(args ...string) error {
+		ctx, cancel := timer.context(StepGit)
+		defer cancel()
+		return timer.check(ctx, StepGit, runGitCommandContext(ctx, path, args...))
+	}
+	// A timed-out command is not retried, the repo has failed by then
+	retriedGitStep := func(args ...string) error {
+		return Retry(RetryGit, captureLog, func() error {
+			err := gitStep(args...)
+			if timer.timedOut != "" {
+				return Permanent(err)
+			}
+			return err
+		})
+	}
+
+	// Repos can opt out of steps or override defaults via a checked-in .githousekeeper.yaml
+	cfg, err := LoadRepoConfig(path)
+	if err != nil {
+		captureLog(fmt.Sprintf("  [ERROR] %v", err))
+		entry.Success = false
+		return entry
+	}
+	if cfg.Skip {
+		captureLog(fmt.Sprintf("  Skipped (skip: true in %s).", RepoConfigFile))
+		entry.Skipped = true
+		return entry
+	}
+	if overrides := cfg.describe(); overrides != "" {
+		captureLog(fmt.Sprintf("  %s: %s", RepoConfigFile, overrides))
+	}
+	opts = cfg.apply(opts)
+
+	// Never check out another branch over local work unless the run is allowed to stash it
+	if opts.DirtyTree == DirtySkip || opts.DirtyTree == DirtyAbort {
+		changes, err := uncommittedChanges(path)
+		if err != nil {
+			captureLog(fmt.Sprintf("  [ERROR] %v", err))
+			entry.Success = false
+			return entry
+		}
+		if len(changes) > 0 {
+			if opts.DirtyTree == DirtySkip {
+				captureLog(fmt.Sprintf("  [WARNING] Skipped: %d uncommitted change(s) in the working tree.", len(changes)))
+				entry.Skipped = true
+			} else {
+				captureLog(fmt.Sprintf("  [ERROR] %d uncommitted change(s) in the working tree, repository not processed.", len(changes)))
+				entry.Success = false
+			}
+			return entry
+		}
+	}
+
+	// CI clones are often shallow or detached, which breaks tags, checkout and pull
+	if err := prepareGitState(path, captureLog); err != nil {
+		captureLog(fmt.Sprintf("  [ERROR] Cannot process repository: %v", err))
+		entry.Success = false
+		return entry
+	}
+
+	// 0. Remember where we started so the run can be rolled back
+	if err := captureOriginalState(path, opts.RunID, &entry.Snapshot, captureLog); err != nil {
+		captureLog(fmt.Sprintf("  [ERROR] %v", err))
+		entry.Success = false
+		return entry
+	}
+
 	// 1. Detect and switch to default branch (main or master)
-	defaultBranch := getDefaultBranch(path)
+	defaultBranch := cfg.BaseBranch
+	if defaultBranch == "" {
+		defaultBranch = getDefaultBranch(path)
+	}
 	captureLog(fmt.Sprintf("  Switching to %s and updating...", defaultBranch))
-	err := runGitCommand(path, "checkout", defaultBranch)
+	err = checkoutBranch(path, defaultBranch)
 	if err != nil {
 		captureLog(fmt.Sprintf("  [ERROR] Checkout %s failed: %v", defaultBranch, err))
 		entry.Success = false
 		return entry
 	}
 
-	err = runGitCommand(path, "fetch", "-p")
-	if err != nil {
+	if remote := PrimaryRemote(path); remote != "" {
+		err = retriedGitStep("fetch", "-p", remote)
+	} else {
+		err = retriedGitStep("fetch", "-p")
+	}
+	if err != nil && timer.timedOut != "" {
+		captureLog(fmt.Sprintf("  [ERROR] Fetch -p failed: %v", err))
+		entry.Success = false
+		return entry
+	} else if err != nil {
 		captureLog(fmt.Sprintf("  [WARNING] Fetch -p failed: %v", err))
 	}
 
-	err = runGitCommand(path, "pull")
+	err = retriedGitStep("pull")
 	if err != nil {
 		captureLog(fmt.Sprintf("  [ERROR] Pull %s failed: %v", defaultBranch, err))
 		entry.Success = false
@@ -121,6 +274,7 @@ func ProcessRepo(path string, opts RepoOptions) ReportEntry {
 
 	// 2. Branch Logic
 	targetBranch := strings.TrimSpace(opts.TargetBranch)
+	workBranchCreated := false
 
 	if targetBranch == "" {
 		captureLog(fmt.Sprintf("  No target branch specified. Continuing on %s.", defaultBranch))
@@ -141,9 +295,13 @@ func ProcessRepo(path string, opts RepoOptions) ReportEntry {
 
 			// For custom branches (not housekeeping), try to pull updates if tracking remote
 			if targetBranch != "housekeeping" {
-				err := runGitCommand(path, "pull")
+				err := gitStep("pull")
 				if err == nil {
 					captureLog("  Branch updated (Pull).")
+				} else if timer.timedOut != "" {
+					captureLog(fmt.Sprintf("  [ERROR] Pull %s failed: %v", targetBranch, err))
+					entry.Success = false
+					return entry
 				} else {
 					// It's okay if pull fails (e.g. local only branch), just log info
 					captureLog("  Pull not possible (maybe local only), continuing.")
@@ -158,10 +316,15 @@ func ProcessRepo(path string, opts RepoOptions) ReportEntry {
 				return entry
 			}
 			captureLog(fmt.Sprintf("  Branch '%s' created.", targetBranch))
+			workBranchCreated = true
 		}
 	}
 
-	tag := getLatestTag(path)
+	entry.Snapshot.WorkBranch = currentBranch(path)
+	entry.Snapshot.WorkBranchCreated = workBranchCreated
+	entry.Snapshot.BaseHead, _ = gitOutput(path, "rev-parse", "HEAD")
+
+	tag := getLatestTag(path, opts.Tags)
 	captureLog(fmt.Sprintf("  Current Tag: %s", tag))
 
 	// Handle replacements based on scope
@@ -180,47 +343,151 @@ func ProcessRepo(path string, opts RepoOptions) ReportEntry {
 		projectReplacements = opts.Replacements
 	}
 
-	processPomXml(path, tag, pomReplacements, opts.TargetParentVersion, opts.VersionBumpStrategy, captureLog)
-	processCiSettingsXml(path, captureLog)
-	projectChangesMade := processProjectReplacements(path, projectReplacements, opts.ExcludedFolders, opts.ReplacementScope, captureLog)
+	tagVersion := TagVersion(tag, opts.Tags.Prefix)
+	if cfg.SkipVersionBump {
+		tagVersion = ""
+	}
+	commits := newCommitQueue(opts.CommitStrategy, opts.Signing)
+	processPomXml(path, tagVersion, pomReplacements, opts.TargetParentVersion, opts.VersionBumpStrategy, commits, captureLog)
+	processCiSettingsXml(path, commits, captureLog)
+	processNodeVersion(path, opts.TargetNodeVersion, commits, captureLog)
+	ctx, cancel := timer.context(StepReplacements)
+	projectChangesMade := processProjectReplacements(ctx, path, projectReplacements, opts.ExcludedFolders, opts.ReplacementScope, commits, captureLog)
+	replacementsErr := timer.check(ctx, StepReplacements, nil)
+	cancel()
+	if replacementsErr != nil {
+		commits.flush(path, captureLog)
+		captureLog(fmt.Sprintf("  [ERROR] Replacements stopped: %v. The changes made so far are committed.", replacementsErr))
+		entry.Success = false
+		return entry
+	}
+
+	// Large files move to Git LFS in a commit of their own, the history stays untouched
+	if !processLFSMigration(path, opts.LFS, commits, captureLog) {
+		entry.Success = false
+	}
+
+	// Formatting goes in a commit of its own (unless the strategy is single), so that reviewers can skip it
+	if !processFormatting(path, opts.Format, opts.Maven, commits, captureLog) {
+		entry.Success = false
+	}
+
+	// The changelog comes last, so that it covers the commits of this run too
+	if !processChangelog(path, opts.Changelog, opts.Tags, commits, captureLog) {
+		entry.Success = false
+	}
+	commits.flush(path, captureLog)
+
+	if timer.expired() {
+		captureLog(fmt.Sprintf("  [ERROR] %v, build not started.", timer.expire()))
+		entry.Success = false
+		return entry
+	}
+
+	// Go, Python and PHP projects are updated and verified with their own tooling instead of Maven
+	forceVerify := opts.VerificationLevel != VerifyNone && (projectChangesMade || opts.RunCleanInstall)
+	projectType, _ := detectProjectTypeAndFramework(path)
+	var projectOK bool
+	switch projectType {
+	case "go":
+		projectOK = processGoModule(path, opts.Go, forceVerify, captureLog)
+	case "python":
+		projectOK = processPythonProject(path, opts.Python, forceVerify, captureLog)
+	case "php":
+		projectOK = processPhpProject(path, opts.Php, forceVerify, captureLog)
+	}
+	if projectType == "go" || projectType == "python" || projectType == "php" {
+		if forceVerify {
+			entry.BuildStatus = buildStatus(projectOK)
+		}
+		// A failed LFS, formatting or changelog step keeps the repo failed
+		entry.Success = entry.Success && projectOK
+		return entry
+	}
 
 	var buildOutput string
 
-	if projectChangesMade || opts.RunCleanInstall {
-		if opts.RunCleanInstall {
-			captureLog("  Running Maven Clean Install (explicitly requested)...")
+	if cfg.SkipBuild {
+		captureLog(fmt.Sprintf("  Build skipped (%s).", RepoConfigFile))
+		entry.BuildStatus = BuildSkipped
+	} else if opts.VerificationLevel == VerifyNone {
+		captureLog("  Build skipped (verification level none).")
+		entry.BuildStatus = BuildSkipped
+	} else if forceVerify && cfg.BuildCommand != "" {
+		captureLog(fmt.Sprintf("  Running build command from %s (%s)...", RepoConfigFile, cfg.BuildCommand))
+		ctx, cancel := timer.context(StepMaven)
+		output, err := runShellCommandContext(ctx, path, cfg.BuildCommand)
+		err = timer.check(ctx, StepMaven, err)
+		cancel()
+		buildOutput = output
+		if err != nil {
+			reportBuildFailure(&entry, "Build", err, buildOutput, path, captureLog)
+			entry.Success = false
 		} else {
-			captureLog("  Changes were made. Running Maven Re-import...")
+			captureLog("  Build successful.")
 		}
-
-		var cmd *exec.Cmd
-		// Add -Dmaven.compiler.showDeprecation=true to capture deprecations in the same run
-		if strings.Contains(strings.ToLower(os.Getenv("OS")), "windows") {
-			cmd = exec.Command("cmd", "/C", "mvn", "clean", "install", "-DskipTests", "-Dmaven.compiler.showDeprecation=true")
+		entry.BuildStatus = buildStatusOf(err)
+	} else if forceVerify {
+		if opts.RunCleanInstall {
+			captureLog(fmt.Sprintf("  Running %s (explicitly requested)...", verificationName(opts.VerificationLevel, opts.VerificationGoal)))
 		} else {
-			cmd = exec.Command("mvn", "clean", "install", "-DskipTests", "-Dmaven.compiler.showDeprecation=true")
+			captureLog(fmt.Sprintf("  Changes were made. Running %s...", verificationName(opts.VerificationLevel, opts.VerificationGoal)))
 		}
-		cmd.Dir = path
 
-		outputBytes, err := cmd.CombinedOutput()
-		buildOutput = string(outputBytes)
+		// Reports older than the build are left over from earlier builds (file times may be whole seconds)
+		started := time.Now().Truncate(time.Second)
+		// Only network and repository manager failures are retried, a broken build fails the same way again
+		err := Retry(RetryMaven, captureLog, func() error {
+			// The timeout starts once a Maven slot is free
+			defer cmdlimit.AcquireClass(cmdlimit.ClassMaven, "build "+path)()
+			ctx, cancel := timer.context(StepMaven)
+			defer cancel()
+			cmd := opts.Maven.CommandContext(ctx, path, verificationArgs(opts.VerificationLevel, opts.VerificationGoal)...)
+			outputBytes, err := cmd.CombinedOutput()
+			buildOutput = string(outputBytes)
+			err = timer.check(ctx, StepMaven, err)
+			if err != nil && (timer.timedOut != "" || !isTransientMavenFailure(buildOutput)) {
+				return Permanent(err)
+			}
+			return err
+		})
 
 		if err != nil {
-			captureLog(fmt.Sprintf("  [ERROR] Maven Build failed: %v\nOutput:\n%s", err, buildOutput))
+			reportBuildFailure(&entry, "Maven Build", err, buildOutput, path, captureLog)
 			entry.Success = false
 		} else {
 			captureLog("  Maven Build successful.")
 		}
+		entry.BuildStatus = buildStatusOf(err)
+
+		if tests := ParseTestReports(path, started); tests != nil {
+			entry.Tests = tests
+			captureLog("  Tests: " + tests.String())
+			for _, failure := range tests.Failed {
+				captureLog(fmt.Sprintf("  [ERROR] Test failed: %s.%s: %s", failure.Class, failure.Name, failure.Message))
+			}
+		}
 	}
 
 	if buildOutput != "" {
 		// Parse deprecations from the build we just ran
 		entry.DeprecationOutput = parseDeprecationsFromOutput(buildOutput, captureLog)
+		entry.Deprecations = ParseDeprecations(buildOutput, path)
+	} else if cfg.SkipDeprecationCheck || cfg.SkipBuild {
+		captureLog(fmt.Sprintf("  Deprecation check skipped (%s).", RepoConfigFile))
+	} else if opts.VerificationLevel == VerifyNone {
+		captureLog("  Deprecation check skipped (verification level none).")
 	} else {
 		// No build ran yet. If we want to check deprecations, we must run a build now.
 		// Since the user didn't ask for a build (runCleanInstall=false) and no changes were made,
 		// we run 'clean compile' just for deprecations.
-		entry.DeprecationOutput = checkDeprecations(path, captureLog)
+		ctx, cancel := timer.context(StepMaven)
+		entry.DeprecationOutput, entry.Deprecations = checkDeprecations(ctx, path, opts.Maven, captureLog)
+		if err := timer.check(ctx, StepMaven, nil); err != nil {
+			captureLog(fmt.Sprintf("  [ERROR] Deprecation check stopped: %v", err))
+			entry.Success = false
+		}
+		cancel()
 	}
 
 	return entry
@@ -233,13 +500,14 @@ func branchExists(path, branchName string) bool {
 
 // getDefaultBranch determines the default branch (main or master) for a repository
 func getDefaultBranch(path string) string {
+	remote := primaryRemoteOrOrigin(path)
 	// Try to get the default branch from remote HEAD
-	cmd := exec.Command("git", "symbolic-ref", "refs/remotes/origin/HEAD")
+	cmd := exec.Command("git", "symbolic-ref", "refs/remotes/"+remote+"/HEAD")
 	cmd.Dir = path
-	output, err := cmd.Output()
+	output, err := cmdlimit.Output(cmd)
 	if err == nil {
 		// Output: "refs/remotes/origin/main" → extract "main"
-		branch := strings.TrimPrefix(strings.TrimSpace(string(output)), "refs/remotes/origin/")
+		branch := strings.TrimPrefix(strings.TrimSpace(string(output)), "refs/remotes/"+remote+"/")
 		if branch != "" {
 			return branch
 		}
@@ -251,9 +519,9 @@ func getDefaultBranch(path string) string {
 	}
 
 	// Check if remote has "main"
-	cmd = exec.Command("git", "ls-remote", "--heads", "origin", "main")
+	cmd = exec.Command("git", "ls-remote", "--heads", remote, "main")
 	cmd.Dir = path
-	output, err = cmd.Output()
+	output, err = cmdlimit.Output(cmd)
 	if err == nil && len(output) > 0 {
 		return "main"
 	}
@@ -268,7 +536,7 @@ func checkAndDeleteOldHousekeeping(path string, log func(string)) {
 
 	cmd := exec.Command("git", "log", "-1", "--format=%cI", "housekeeping")
 	cmd.Dir = path
-	output, err := cmd.Output()
+	output, err := cmdlimit.Output(cmd)
 	if err != nil {
 		log(fmt.Sprintf("  [WARNING] Could not read date of housekeeping: %v", err))
 		return
@@ -296,30 +564,22 @@ func checkAndDeleteOldHousekeeping(path string, log func(string)) {
 }
 
 func runGitCommand(dir string, args ...string) error {
-	cmd := exec.Command("git", args...)
+	return runGitCommandContext(context.Background(), dir, args...)
+}
+
+// runGitCommandContext is like runGitCommand but git is killed when ctx is done
+func runGitCommandContext(ctx context.Context, dir string, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Dir = dir
-	output, err := cmd.CombinedOutput()
+	cmd.WaitDelay = killWaitDelay
+	output, err := cmdlimit.CombinedOutput(cmd)
 	if err != nil {
 		return fmt.Errorf("%s: %s", err, string(output))
 	}
 	return nil
 }
 
-func getLatestTag(path string) string {
-	cmd := exec.Command("git", "tag", "--sort=-v:refname")
-	cmd.Dir = path
-	output, err := cmd.Output()
-	if err != nil {
-		return "No Tags"
-	}
-	lines := strings.Split(string(output), "\n")
-	if len(lines) > 0 && lines[0] != "" {
-		return strings.TrimSpace(lines[0])
-	}
-	return "No Tags"
-}
-
-func processPomXml(repoPath, tag string, replacements []Replacement, targetParentVersion string, versionBumpStrategy string, log func(string)) {
+func processPomXml(repoPath, tagVersion string, replacements []Replacement, targetParentVersion string, versionBumpStrategy string, commits *commitQueue, log func(string)) {
 	pomPath := filepath.Join(repoPath, "pom.xml")
 	contentBytes, err := os.ReadFile(pomPath)
 	if err != nil {
@@ -332,110 +592,24 @@ func processPomXml(repoPath, tag string, replacements []Replacement, targetParen
 	content := string(contentBytes)
 	originalContent := content
 
-	cleanTag := strings.TrimPrefix(tag, "v")
+	cleanTag := tagVersion
+	var oldProjectVersion, newProjectVersion string
 
 	if cleanTag != "" && cleanTag != "No Tags" {
-		excludePatterns := []string{
-			`(?s)<parent>.*?</parent>`,
-			`(?s)<dependencies>.*?</dependencies>`,
-			`(?s)<dependencyManagement>.*?</dependencyManagement>`,
-			`(?s)<build>.*?</build>`,
-			`(?s)<profiles>.*?</profiles>`,
-		}
-
-		var excludedRanges [][]int
-		for _, pat := range excludePatterns {
-			re := regexp.MustCompile(pat)
-			matches := re.FindAllStringIndex(content, -1)
-			excludedRanges = append(excludedRanges, matches...)
-		}
-
-		reVersion := regexp.MustCompile(`<version>(.*?)</version>`)
-		versionMatches := reVersion.FindAllStringSubmatchIndex(content, -1)
-
-		var projectVersionMatch []int
-
-		for _, match := range versionMatches {
-			vStart := match[0]
-			vEnd := match[1]
-
-			isExcluded := false
-			for _, rng := range excludedRanges {
-				if vStart >= rng[0] && vEnd <= rng[1] {
-					isExcluded = true
-					break
-				}
-			}
-
-			if !isExcluded {
-				projectVersionMatch = match
-				break
-			}
-		}
+		projectVersionMatch := findProjectVersion(content)
 
 		if projectVersionMatch != nil {
 			currentProjectVersion := content[projectVersionMatch[2]:projectVersionMatch[3]]
 
 			if currentProjectVersion == cleanTag {
-				parts := strings.Split(cleanTag, ".")
-				var newVersion string
-
-				if len(parts) >= 3 {
-					major := parts[0]
-					minor := parts[1]
-					patch := parts[2]
-
-					var majorInt, minorInt, patchInt int
-					fmt.Sscanf(major, "%d", &majorInt)
-					fmt.Sscanf(minor, "%d", &minorInt)
-					fmt.Sscanf(patch, "%d", &patchInt)
-
-					switch versionBumpStrategy {
-					case "major":
-						majorInt++
-						minorInt = 0
-						patchInt = 0
-					case "minor":
-						minorInt++
-						patchInt = 0
-					default: // "patch" or empty
-						patchInt++
-					}
-
-					newVersion = fmt.Sprintf("%d.%d.%d", majorInt, minorInt, patchInt)
-
-				} else if len(parts) == 2 {
-					major := parts[0]
-					minor := parts[1]
-
-					var majorInt, minorInt int
-					fmt.Sscanf(major, "%d", &majorInt)
-					fmt.Sscanf(minor, "%d", &minorInt)
-
-					switch versionBumpStrategy {
-					case "major":
-						majorInt++
-						minorInt = 0
-					case "minor":
-						minorInt++
-					default: // "patch"
-						// 1.2 -> 1.2.1
-						newVersion = fmt.Sprintf("%d.%d.1", majorInt, minorInt)
-						// If we treat 2 parts as Major.Minor, patch bump adds a part.
-						// If we treat as Major.Minor, minor bump is 1.3
-						// Major bump is 2.0
-					}
-
-					if newVersion == "" {
-						newVersion = fmt.Sprintf("%d.%d", majorInt, minorInt)
-					}
-				}
+				newVersion := bumpVersion(cleanTag, versionBumpStrategy)
 
 				if newVersion != "" {
 					absStart := projectVersionMatch[2]
 					absEnd := projectVersionMatch[3]
 
 					content = content[:absStart] + newVersion + content[absEnd:]
+					oldProjectVersion, newProjectVersion = currentProjectVersion, newVersion
 
 					log(fmt.Sprintf("  [INFO] Version in pom.xml updated (%s): %s -> %s", versionBumpStrategy, currentProjectVersion, newVersion))
 				}
@@ -448,8 +622,19 @@ func processPomXml(repoPath, tag string, replacements []Replacement, targetParen
 	}
 
 	for _, r := range replacements {
-		if r.Search != "" {
-			newContent, changed := performFuzzyReplacement(content, r.Search, r.Replace)
+		if !r.AppliesTo("pom.xml") {
+			continue
+		}
+		if r.Search != "" && r.Type == ReplacementTypeProperty {
+			if newContent, changed := applyPomReplacement(content, r, "pom.xml", log); changed {
+				content = newContent
+			} else if _, _, defined := setPomProperty(content, strings.TrimSpace(r.Search), ""); defined {
+				log(fmt.Sprintf("  [INFO] Property '%s' already up to date.", r.Search))
+			} else {
+				log(fmt.Sprintf("  [INFO] Property '%s' not defined in pom.xml.", r.Search))
+			}
+		} else if r.Search != "" {
+			newContent, changed := applyReplacement(content, r)
 			if changed {
 				content = newContent
 				log(fmt.Sprintf("  [INFO] Custom replacement performed: '%s' -> '%s'", r.Search, r.Replace))
@@ -502,31 +687,48 @@ func processPomXml(repoPath, tag string, replacements []Replacement, targetParen
 		log("  [INFO] Repositories block updated.")
 	}
 
-	if content != originalContent {
-		err = os.WriteFile(pomPath, []byte(content), 0644)
-		if err != nil {
-			log(fmt.Sprintf("  [ERROR] Could not write pom.xml: %v", err))
-			return
+	// Multi-module builds: carry the version bump and replacements over to the child poms
+	var changedModules []string
+	if modules := DiscoverMavenModules(repoPath); len(modules) > 0 {
+		log(fmt.Sprintf("  [INFO] Multi-module build with %d modules.", len(modules)))
+		rootArtifactId := ""
+		if rootPom, err := readReactorPom(pomPath); err == nil {
+			rootArtifactId = rootPom.ArtifactId
+		}
+		changedModules = updateModulePoms(repoPath, modules, rootArtifactId, oldProjectVersion, newProjectVersion, replacements, log)
+	}
+
+	if content != originalContent || len(changedModules) > 0 {
+		if content != originalContent {
+			err = os.WriteFile(pomPath, []byte(content), 0644)
+			if err != nil {
+				log(fmt.Sprintf("  [ERROR] Could not write pom.xml: %v", err))
+				return
+			}
 		}
 
-		err = runGitCommand(repoPath, "add", "pom.xml")
+		err = runGitCommand(repoPath, append([]string{"add", "pom.xml"}, changedModules...)...)
 		if err != nil {
 			log(fmt.Sprintf("  [ERROR] git add pom.xml failed: %v", err))
 			return
 		}
 
-		err = runGitCommand(repoPath, "commit", "-m", "Update pom.xml")
+		message := "Update pom.xml"
+		if len(changedModules) > 0 {
+			message = fmt.Sprintf("Update pom.xml and %d module poms", len(changedModules))
+		}
+		verb, err := commits.commit(repoPath, message)
 		if err != nil {
 			log(fmt.Sprintf("  [ERROR] git commit failed: %v", err))
 			return
 		}
-		log("  pom.xml updated and committed.")
+		log(fmt.Sprintf("  pom.xml updated and %s.", verb))
 	} else {
 		log("  No changes to pom.xml.")
 	}
 }
 
-func processCiSettingsXml(repoPath string, log func(string)) {
+func processCiSettingsXml(repoPath string, commits *commitQueue, log func(string)) {
 	ciPath := filepath.Join(repoPath, "ci-settings.xml")
 	contentBytes, err := os.ReadFile(ciPath)
 	if err != nil {
@@ -593,94 +795,93 @@ func processCiSettingsXml(repoPath string, log func(string)) {
 			return
 		}
 
-		err = runGitCommand(repoPath, "commit", "-m", "Update ci-settings.xml")
+		verb, err := commits.commit(repoPath, "Update ci-settings.xml")
 		if err != nil {
 			log(fmt.Sprintf("  [ERROR] git commit failed: %v", err))
 			return
 		}
-		log("  ci-settings.xml updated and committed.")
+		log(fmt.Sprintf("  ci-settings.xml updated and %s.", verb))
 	} else {
 		log("  No changes to ci-settings.xml.")
 	}
 }
 
-func processProjectReplacements(root string, replacements []Replacement, excludedFolders []string, scope string, log func(string)) bool {
+func processProjectReplacements(ctx context.Context, root string, replacements []Replacement, excludedFolders []string, scope string, commits *commitQueue, log func(string)) bool {
 	if len(replacements) == 0 {
 		return false
 	}
 
-	changesMade := false
-
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if info.IsDir() {
-			for _, ex := range excludedFolders {
-				if info.Name() == ex {
-					return filepath.SkipDir
+	// One commit per rule: every rule gets its own pass over the files
+	if commits.groupsByRule() {
+		changesMade := false
+		for _, r := range replacements {
+			count := 0
+			if ctx.Err() != nil {
+				break
+			}
+			replaceInProjectFiles(ctx, root, []Replacement{r}, excludedFolders, log, func(path string) {
+				if err := runGitCommand(root, "add", path); err == nil {
+					count++
 				}
+			})
+			if count == 0 {
+				continue
 			}
-			if info.Name() == ".git" || info.Name() == "target" || info.Name() == "node_modules" {
-				return filepath.SkipDir
+			changesMade = true
+			if _, err := commits.commit(root, replacementCommitMessage(r, count)); err != nil {
+				log(fmt.Sprintf("  [ERROR] git commit failed: %v", err))
 			}
-			return nil
 		}
+		return changesMade
+	}
+
+	changesMade := false
+	replaceInProjectFiles(ctx, root, replacements, excludedFolders, log, func(path string) {
+		if err := runGitCommand(root, "add", path); err == nil {
+			commits.commit(root, fmt.Sprintf("Update %s via project-wide replacement", filepath.Base(path)))
+		}
+		changesMade = true
+	})
+	return changesMade
+}
 
+// replaceInProjectFiles applies the replacements to all files except pom.xml and calls
+// changed for every file that was written
+func replaceInProjectFiles(ctx context.Context, root string, replacements []Replacement, excludedFolders []string, log func(string), changed func(path string)) {
+	err := walkReplaceableFiles(ctx, root, excludedFolders, log, func(path string, info os.FileInfo, content string) {
 		// Skip pom.xml if scope is "exclude-pom" (pom.xml is handled separately by processPomXml)
 		// Also skip pom.xml for "all" scope since it's already processed by processPomXml
 		if info.Name() == "pom.xml" {
-			return nil
-		}
-
-		contentBytes, err := os.ReadFile(path)
-		if err != nil {
-			log(fmt.Sprintf("    [WARNING] Could not read file %s: %v", path, err))
-			return nil
-		}
-
-		for i := 0; i < len(contentBytes) && i < 1024; i++ {
-			if contentBytes[i] == 0 {
-				return nil
-			}
+			return
 		}
 
-		content := string(contentBytes)
 		fileChanged := false
 
+		rel, _ := filepath.Rel(root, path)
 		for _, r := range replacements {
-			newContent, changed := performFuzzyReplacement(content, r.Search, r.Replace)
-			if changed {
+			if !r.AppliesTo(rel) {
+				continue
+			}
+			newContent, ok := applyReplacement(content, r) // pom properties only exist in pom.xml
+			if ok {
 				content = newContent
 				fileChanged = true
 			}
 		}
 
 		if fileChanged {
-			err = os.WriteFile(path, []byte(content), info.Mode())
-			if err != nil {
+			if err := os.WriteFile(path, []byte(content), info.Mode()); err != nil {
 				log(fmt.Sprintf("    [ERROR] Could not write file %s: %v", path, err))
 			} else {
 				log(fmt.Sprintf("    [INFO] File updated: %s", path))
-
-				err = runGitCommand(root, "add", path)
-				if err == nil {
-					runGitCommand(root, "commit", "-m", fmt.Sprintf("Update %s via project-wide replacement", filepath.Base(path)))
-				}
-
-				changesMade = true
+				changed(path)
 			}
 		}
-
-		return nil
 	})
 
 	if err != nil {
 		log(fmt.Sprintf("  [ERROR] Error searching for replacements: %v", err))
 	}
-
-	return changesMade
 }
 
 func performFuzzyReplacement(content, search, replace string) (string, bool) {
@@ -766,30 +967,35 @@ func performFuzzyReplacement(content, search, replace string) (string, bool) {
 	return currentContent, changed
 }
 
-func checkDeprecations(path string, log func(string)) string {
+func checkDeprecations(ctx context.Context, path string, maven MavenSettings, log func(string)) (string, []Deprecation) {
 	log("  Checking for deprecations (separate run)...")
 
-	var cmd *exec.Cmd
-	if strings.Contains(strings.ToLower(os.Getenv("OS")), "windows") {
-		cmd = exec.Command("cmd", "/C", "mvn", "clean", "compile", "-Dmaven.compiler.showDeprecation=true")
-	} else {
-		cmd = exec.Command("mvn", "clean", "compile", "-Dmaven.compiler.showDeprecation=true")
-	}
-	cmd.Dir = path
+	cmd := maven.CommandContext(ctx, path, "clean", "compile", "-Dmaven.compiler.showDeprecation=true")
 
 	// We ignore error here because we only care about the output logs
-	output, _ := cmd.CombinedOutput()
-	return parseDeprecationsFromOutput(string(output), log)
+	output, _ := cmdlimit.CombinedOutput(cmd)
+	return parseDeprecationsFromOutput(string(output), log), ParseDeprecations(string(output), path)
 }
 
+// reactorBuildingPattern matches the header Maven prints before each module of a multi-module build
+var reactorBuildingPattern = regexp.MustCompile(`^\[INFO\] Building (\S+) \S+\s+\[\d+/\d+\]`)
+
 func parseDeprecationsFromOutput(output string, log func(string)) string {
 	lines := strings.Split(output, "\n")
 	var warnings []string
 	count := 0
+	module, headerModule := "", ""
+	modulesWithWarnings := 0
 
 	for _, line := range lines {
 		lower := strings.ToLower(line)
 
+		// Multi-module builds: group the warnings by reactor module
+		if m := reactorBuildingPattern.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			module = m[1]
+			continue
+		}
+
 		// Skip [INFO] lines - they're not actual warnings
 		if strings.Contains(lower, "[info]") {
 			continue
@@ -801,6 +1007,11 @@ func parseDeprecationsFromOutput(output string, log func(string)) string {
 			// Clean up line
 			line = strings.TrimSpace(line)
 			if line != "" {
+				if module != headerModule {
+					warnings = append(warnings, fmt.Sprintf("--- %s ---", module))
+					headerModule = module
+					modulesWithWarnings++
+				}
 				warnings = append(warnings, line)
 				count++
 				if count >= 100 {
@@ -811,7 +1022,11 @@ func parseDeprecationsFromOutput(output string, log func(string)) string {
 	}
 
 	if len(warnings) > 0 {
-		log(fmt.Sprintf("  %d deprecation warnings found.", len(warnings)))
+		if modulesWithWarnings > 0 {
+			log(fmt.Sprintf("  %d deprecation warnings found in %d modules.", count, modulesWithWarnings))
+		} else {
+			log(fmt.Sprintf("  %d deprecation warnings found.", count))
+		}
 		return strings.Join(warnings, "\n")
 	}
 
@@ -821,16 +1036,6 @@ func parseDeprecationsFromOutput(output string, log func(string)) string {
 
 // Spring Boot Logic
 
-type MavenMetadata struct {
-	Versioning Versioning `xml:"versioning"`
-}
-
-type Versioning struct {
-	Latest   string   `xml:"latest"`
-	Release  string   `xml:"release"`
-	Versions []string `xml:"versions>version"`
-}
-
 type SpringVersionInfo struct {
 	Branch         string
 	Versions       []string
@@ -838,25 +1043,14 @@ type SpringVersionInfo struct {
 }
 
 func GetSpringVersions() ([]SpringVersionInfo, error) {
-	resp, err := http.Get("https://repo1.maven.org/maven2/org/springframework/boot/spring-boot-starter-parent/maven-metadata.xml")
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	metadata, err := registry.Metadata("org.springframework.boot", "spring-boot-starter-parent")
 	if err != nil {
 		return nil, err
 	}
 
-	var metadata MavenMetadata
-	if err := xml.Unmarshal(body, &metadata); err != nil {
-		return nil, err
-	}
-
 	// Group by Major.Minor
 	grouped := make(map[string][]string)
-	for _, v := range metadata.Versioning.Versions {
+	for _, v := range metadata.Versions {
 		// Filter for stable versions (no M1, RC1, SNAPSHOT) if desired
 		if strings.Contains(v, "SNAPSHOT") {
 			continue
@@ -934,14 +1128,14 @@ type SpringScanResult struct {
 	DebugLog []string
 }
 
-func ScanProjectsForSpring(root string, excluded []string) SpringScanResult {
+func ScanProjectsForSpring(root string, excluded []string, maven MavenSettings) SpringScanResult {
 	var result SpringScanResult
 	result.Projects = make([]ProjectSpringStatus, 0)
 	result.DebugLog = make([]string, 0)
 
 	log := func(msg string) {
 		result.DebugLog = append(result.DebugLog, msg)
-		fmt.Println("[SPRING SCAN]", msg) // Also print to stdout
+		slog.Debug(msg, "scope", "spring-scan")
 	}
 
 	log(fmt.Sprintf("Starting scan in: %s", root))
@@ -1005,7 +1199,7 @@ func ScanProjectsForSpring(root string, excluded []string) SpringScanResult {
 			} else {
 				log("  <parent> is not spring-boot-starter-parent. Trying Effective-POM analysis...")
 				// Fallback: Run Maven to get effective pom
-				v, err := getSpringBootVersionFromMaven(filepath.Dir(path))
+				v, err := getSpringBootVersionFromMaven(filepath.Dir(path), maven)
 				if err == nil && v != "" {
 					log(fmt.Sprintf("  Found (via Maven): %s", v))
 					result.Projects = append(result.Projects, ProjectSpringStatus{
@@ -1029,18 +1223,12 @@ func ScanProjectsForSpring(root string, excluded []string) SpringScanResult {
 	return result
 }
 
-func getSpringBootVersionFromMaven(dir string) (string, error) {
+func getSpringBootVersionFromMaven(dir string, maven MavenSettings) (string, error) {
 	// Use help:effective-pom to see the resolved versions
-	var cmd *exec.Cmd
-	if strings.Contains(strings.ToLower(os.Getenv("OS")), "windows") {
-		cmd = exec.Command("cmd", "/C", "mvn", "help:effective-pom", "-N")
-	} else {
-		cmd = exec.Command("mvn", "help:effective-pom", "-N")
-	}
-	cmd.Dir = dir
+	cmd := maven.Command(dir, "help:effective-pom", "-N")
 
 	// Capture output
-	outputBytes, err := cmd.CombinedOutput()
+	outputBytes, err := cmdlimit.CombinedOutput(cmd)
 	if err != nil {
 		return "", err
 	}
@@ -1080,7 +1268,7 @@ func GetOpenRewriteVersions(currentPluginVersion, currentRecipeVersion string) (
 	result := []OpenRewriteVersionInfo{}
 
 	// Fetch rewrite-maven-plugin latest version
-	pluginLatest, err := fetchLatestVersion("https://repo1.maven.org/maven2/org/openrewrite/maven/rewrite-maven-plugin/maven-metadata.xml")
+	pluginLatest, err := fetchLatestVersion("org.openrewrite.maven", "rewrite-maven-plugin")
 	if err != nil {
 		pluginLatest = "unknown"
 	}
@@ -1094,7 +1282,7 @@ func GetOpenRewriteVersions(currentPluginVersion, currentRecipeVersion string) (
 	})
 
 	// Fetch rewrite-spring latest version
-	recipeLatest, err := fetchLatestVersion("https://repo1.maven.org/maven2/org/openrewrite/recipe/rewrite-spring/maven-metadata.xml")
+	recipeLatest, err := fetchLatestVersion("org.openrewrite.recipe", "rewrite-spring")
 	if err != nil {
 		recipeLatest = "unknown"
 	}
@@ -1110,23 +1298,10 @@ func GetOpenRewriteVersions(currentPluginVersion, currentRecipeVersion string) (
 	return result, nil
 }
 
-func fetchLatestVersion(metadataURL string) (string, error) {
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(metadataURL)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+func fetchLatestVersion(group, artifact string) (string, error) {
+	metadata, err := registry.Metadata(group, artifact)
 	if err != nil {
 		return "", err
 	}
-
-	var metadata MavenMetadata
-	if err := xml.Unmarshal(body, &metadata); err != nil {
-		return "", err
-	}
-
-	return metadata.Versioning.Latest, nil
+	return metadata.Latest, nil
 }
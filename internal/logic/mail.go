@@ -0,0 +1,273 @@
+package logic
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SMTPPasswordEnv overrides the stored SMTP password, so it does not have to be saved in the data directory
+const SMTPPasswordEnv = "GITHOUSEKEEPER_SMTP_PASSWORD"
+
+const smtpFile = "smtp.json"
+
+// Connection security of the SMTP server
+const (
+	SMTPStartTLS = "starttls" // Plain connection upgraded with STARTTLS (port 587, default)
+	SMTPTLS      = "tls"      // Implicit TLS (port 465)
+	SMTPNone     = "none"     // Unencrypted, internal relays only
+)
+
+// SMTPSettings configures the email delivery of run and scan reports
+type SMTPSettings struct {
+	Host       string   `json:"host"`
+	Port       int      `json:"port"`
+	Security   string   `json:"security"` // SMTPStartTLS (default), SMTPTLS or SMTPNone
+	Username   string   `json:"username,omitempty"`
+	Password   string   `json:"password,omitempty"`
+	From       string   `json:"from"`
+	Recipients []string `json:"recipients,omitempty"` // Default distribution list
+}
+
+// Configured reports whether reports can be sent
+func (s SMTPSettings) Configured() bool {
+	return s.Host != "" && s.From != ""
+}
+
+// Redacted returns the settings without the password, for the UI
+func (s SMTPSettings) Redacted() SMTPSettings {
+	s.Password = ""
+	return s
+}
+
+var smtpMu sync.Mutex
+
+func smtpPath() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, smtpFile), nil
+}
+
+// LoadSMTPSettings returns the stored SMTP settings (empty if none are saved)
+func LoadSMTPSettings() (SMTPSettings, error) {
+	smtpMu.Lock()
+	defer smtpMu.Unlock()
+
+	var settings SMTPSettings
+	path, err := smtpPath()
+	if err != nil {
+		return settings, err
+	}
+	if err := readJSONFile(path, &settings); err != nil && !os.IsNotExist(err) {
+		return settings, err
+	}
+	if password := os.Getenv(SMTPPasswordEnv); password != "" {
+		settings.Password = password
+	}
+	return settings, nil
+}
+
+// SaveSMTPSettings validates and stores the SMTP settings. An empty password keeps the stored one.
+func SaveSMTPSettings(settings SMTPSettings) (SMTPSettings, error) {
+	settings.Host = strings.TrimSpace(settings.Host)
+	if settings.Security == "" {
+		settings.Security = SMTPStartTLS
+	}
+	if settings.Security != SMTPStartTLS && settings.Security != SMTPTLS && settings.Security != SMTPNone {
+		return settings, fmt.Errorf("unknown security '%s', expected starttls, tls or none", settings.Security)
+	}
+	if settings.Port == 0 {
+		settings.Port = 587
+		if settings.Security == SMTPTLS {
+			settings.Port = 465
+		}
+	}
+	if settings.Host != "" {
+		if _, err := mail.ParseAddress(settings.From); err != nil {
+			return settings, fmt.Errorf("invalid sender address '%s'", settings.From)
+		}
+	}
+	recipients, err := ParseRecipients(settings.Recipients)
+	if err != nil {
+		return settings, err
+	}
+	settings.Recipients = recipients
+
+	smtpMu.Lock()
+	defer smtpMu.Unlock()
+	path, err := smtpPath()
+	if err != nil {
+		return settings, err
+	}
+	if settings.Password == "" {
+		var stored SMTPSettings
+		if readJSONFile(path, &stored) == nil {
+			settings.Password = stored.Password
+		}
+	}
+	return settings, writeJSONFile(path, settings)
+}
+
+// ParseRecipients validates email addresses; entries may contain several addresses separated by ',' or ';'
+func ParseRecipients(entries []string) ([]string, error) {
+	var recipients []string
+	for _, entry := range entries {
+		for _, address := range strings.FieldsFunc(entry, func(r rune) bool { return r == ',' || r == ';' }) {
+			if address = strings.TrimSpace(address); address == "" {
+				continue
+			}
+			parsed, err := mail.ParseAddress(address)
+			if err != nil {
+				return nil, fmt.Errorf("invalid email address '%s'", address)
+			}
+			recipients = append(recipients, parsed.Address)
+		}
+	}
+	return recipients, nil
+}
+
+// Attachment is a file attached to an email
+type Attachment struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// Email is an HTML message with optional attachments
+type Email struct {
+	To          []string
+	Subject     string
+	HTML        string
+	Attachments []Attachment
+}
+
+// buildMessage encodes the email as a MIME message (multipart/mixed if there are attachments)
+func buildMessage(from string, email Email, now time.Time) []byte {
+	var buf bytes.Buffer
+	header := func(name, value string) { fmt.Fprintf(&buf, "%s: %s\r\n", name, value) }
+	header("From", from)
+	header("To", strings.Join(email.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", email.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+
+	body := func() {
+		header("Content-Type", `text/html; charset="utf-8"`)
+		header("Content-Transfer-Encoding", "base64")
+		buf.WriteString("\r\n")
+		writeBase64(&buf, []byte(email.HTML))
+	}
+	if len(email.Attachments) == 0 {
+		body()
+		return buf.Bytes()
+	}
+
+	boundary := fmt.Sprintf("githousekeeper-%x", now.UnixNano())
+	header("Content-Type", fmt.Sprintf(`multipart/mixed; boundary="%s"`, boundary))
+	buf.WriteString("\r\n")
+	fmt.Fprintf(&buf, "--%s\r\n", boundary)
+	body()
+	for _, a := range email.Attachments {
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		header("Content-Type", a.ContentType)
+		header("Content-Transfer-Encoding", "base64")
+		header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Name}))
+		buf.WriteString("\r\n")
+		writeBase64(&buf, a.Data)
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+	return buf.Bytes()
+}
+
+// writeBase64 writes data base64 encoded in lines of 76 characters (RFC 2045)
+func writeBase64(buf *bytes.Buffer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+}
+
+// SendEmail delivers the email through the configured SMTP server
+func SendEmail(settings SMTPSettings, email Email) error {
+	if !settings.Configured() {
+		return fmt.Errorf("SMTP is not configured")
+	}
+	if len(email.To) == 0 {
+		return fmt.Errorf("no recipients")
+	}
+	from, err := mail.ParseAddress(settings.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address '%s'", settings.From)
+	}
+
+	addr := net.JoinHostPort(settings.Host, strconv.Itoa(settings.Port))
+	tlsConfig := &tls.Config{ServerName: settings.Host}
+	var client *smtp.Client
+	if settings.Security == SMTPTLS {
+		conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 30 * time.Second}, "tcp", addr, tlsConfig)
+		if err != nil {
+			return err
+		}
+		client, err = smtp.NewClient(conn, settings.Host)
+		if err != nil {
+			conn.Close()
+			return err
+		}
+	} else {
+		conn, err := net.DialTimeout("tcp", addr, 30*time.Second)
+		if err != nil {
+			return err
+		}
+		client, err = smtp.NewClient(conn, settings.Host)
+		if err != nil {
+			conn.Close()
+			return err
+		}
+	}
+	defer client.Close()
+
+	if settings.Security != SMTPTLS && settings.Security != SMTPNone {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS failed: %v", err)
+		}
+	}
+	if settings.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", settings.Username, settings.Password, settings.Host)); err != nil {
+			return fmt.Errorf("authentication failed: %v", err)
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, to := range email.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("recipient %s rejected: %v", to, err)
+		}
+	}
+	data, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := data.Write(buildMessage(settings.From, email, time.Now())); err != nil {
+		return err
+	}
+	if err := data.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
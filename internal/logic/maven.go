@@ -0,0 +1,73 @@
+package logic
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// MavenSettings controls how Maven is invoked for a run, scan or analysis
+type MavenSettings struct {
+	UseWrapper   bool     `json:"useWrapper"`   // Prefer ./mvnw (mvnw.cmd on Windows) when the repo has one
+	MavenHome    string   `json:"mavenHome"`    // Use <MavenHome>/bin/mvn instead of the mvn on PATH
+	SettingsFile string   `json:"settingsFile"` // Passed as -s, e.g. a settings.xml with corporate mirrors
+	Profiles     string   `json:"profiles"`     // Comma-separated profiles, passed as -P
+	ExtraArgs    []string `json:"extraArgs"`    // Additional flags, e.g. "-Dmaven.repo.local=/tmp/m2"
+}
+
+// executable returns the Maven binary to use for the repo in dir
+func (s MavenSettings) executable(dir string) string {
+	if s.UseWrapper {
+		wrapper := "mvnw"
+		if isWindows() {
+			wrapper = "mvnw.cmd"
+		}
+		if _, err := os.Stat(filepath.Join(dir, wrapper)); err == nil {
+			return filepath.Join(dir, wrapper)
+		}
+	}
+	if s.MavenHome != "" {
+		mvn := "mvn"
+		if isWindows() {
+			mvn = "mvn.cmd"
+		}
+		return filepath.Join(s.MavenHome, "bin", mvn)
+	}
+	return "mvn"
+}
+
+// Args returns the configured flags followed by the given goals and arguments
+func (s MavenSettings) Args(args ...string) []string {
+	var result []string
+	if s.SettingsFile != "" {
+		result = append(result, "-s", s.SettingsFile)
+	}
+	if profiles := strings.TrimSpace(s.Profiles); profiles != "" {
+		result = append(result, "-P"+profiles)
+	}
+	for _, arg := range s.ExtraArgs {
+		if arg = strings.TrimSpace(arg); arg != "" {
+			result = append(result, arg)
+		}
+	}
+	return append(result, args...)
+}
+
+// Command builds the Maven command for the repo in dir
+func (s MavenSettings) Command(dir string, args ...string) *exec.Cmd {
+	return s.CommandContext(context.Background(), dir, args...)
+}
+
+// CommandContext is like Command but the process is killed when ctx is done
+func (s MavenSettings) CommandContext(ctx context.Context, dir string, args ...string) *exec.Cmd {
+	cmd := programCommand(ctx, s.executable(dir), s.Args(args...)...)
+	cmd.Dir = dir
+	if ctx.Done() != nil {
+		// Only cancellable commands leave the server's process group (and with it Ctrl+C)
+		cmd.WaitDelay = killWaitDelay
+		killProcessGroup(cmd)
+	}
+	return cmd
+}
@@ -0,0 +1,178 @@
+package logic
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gorecode/updates/internal/cmdlimit"
+)
+
+// MavenUpdate is a dependency or plugin with a newer version, as reported by versions-maven-plugin
+type MavenUpdate struct {
+	GroupId    string `json:"groupId"`
+	ArtifactId string `json:"artifactId"`
+	Current    string `json:"current"`
+	Latest     string `json:"latest"`
+	Kind       string `json:"kind"`              // "dependency" or "plugin"
+	Section    string `json:"section,omitempty"` // e.g. "Dependency Management", "Dependencies"
+}
+
+// MavenUpdateReport is the update analysis of one Maven repo
+type MavenUpdateReport struct {
+	RepoName  string        `json:"repoName"`
+	RepoPath  string        `json:"repoPath"`
+	Updates   []MavenUpdate `json:"updates"`
+	CheckedAt time.Time     `json:"checkedAt"`
+	PomHash   string        `json:"pomHash"` // Cache key: the report is reused while pom.xml is unchanged
+	Error     string        `json:"error,omitempty"`
+}
+
+// mavenUpdatesCacheTTL limits how long a report is reused; new releases appear without pom changes
+const mavenUpdatesCacheTTL = 24 * time.Hour
+
+var (
+	// "The following dependencies in Dependencies have newer versions:"
+	mavenDependencySectionPattern = regexp.MustCompile(`The following dependencies in (.+) have newer versions:`)
+	// "  org.slf4j:slf4j-api ............ 1.7.36 -> 2.0.13" (long names wrap before the versions)
+	mavenUpdateLinePattern   = regexp.MustCompile(`^\s+(\S+?)\s*(?:\.{2,}\s*(?:(\S+)\s+->\s+(\S+))?)?\s*$`)
+	mavenVersionsOnlyPattern = regexp.MustCompile(`^\s*(?:\.*\s*)?(\S+)\s+->\s+(\S+)\s*$`)
+)
+
+// ParseMavenUpdatesOutput extracts the updates from the output of
+// versions:display-dependency-updates and versions:display-plugin-updates.
+// Multi-module builds report the same artifact once per module; duplicates are dropped.
+func ParseMavenUpdatesOutput(output string) []MavenUpdate {
+	var result []MavenUpdate
+	seen := make(map[string]bool)
+
+	kind, section := "", ""
+	pending := "" // Artifact whose versions are on the next line
+
+	add := func(artifact, current, latest string) {
+		group, id, ok := strings.Cut(artifact, ":")
+		if !ok {
+			// Plugins of the default group are listed without groupId
+			group, id = "org.apache.maven.plugins", artifact
+		}
+		key := kind + "|" + section + "|" + group + ":" + id
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		result = append(result, MavenUpdate{GroupId: group, ArtifactId: id, Current: current, Latest: latest, Kind: kind, Section: section})
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		text, ok := strings.CutPrefix(line, "[INFO]")
+		if !ok {
+			if strings.HasPrefix(line, "[") {
+				pending = "" // Warnings interrupt a wrapped entry
+			}
+			continue
+		}
+
+		if m := mavenDependencySectionPattern.FindStringSubmatch(text); m != nil {
+			kind, section, pending = "dependency", m[1], ""
+			continue
+		}
+		if strings.Contains(text, "The following plugin updates are available:") {
+			kind, section, pending = "plugin", "", ""
+			continue
+		}
+		if kind == "" {
+			continue
+		}
+
+		if pending != "" {
+			if m := mavenVersionsOnlyPattern.FindStringSubmatch(text); m != nil {
+				add(pending, m[1], m[2])
+			}
+			pending = ""
+			continue
+		}
+
+		m := mavenUpdateLinePattern.FindStringSubmatch(text)
+		if m == nil || strings.TrimSpace(text) == "" {
+			// Blank line or next goal output: the list has ended
+			kind, section = "", ""
+			continue
+		}
+		if m[2] == "" {
+			if strings.Contains(m[1], ":") || strings.HasSuffix(m[1], "-plugin") {
+				pending = strings.TrimRight(m[1], ".")
+			} else {
+				kind, section = "", ""
+			}
+			continue
+		}
+		add(m[1], m[2], m[3])
+	}
+	return result
+}
+
+// pomHash identifies the content of the root pom.xml
+func pomHash(repoPath string) string {
+	data, err := os.ReadFile(filepath.Join(repoPath, "pom.xml"))
+	if err != nil {
+		return ""
+	}
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// AnalyzeMavenUpdates runs versions-maven-plugin for dependency and plugin updates.
+// Reports are cached per repo until pom.xml changes or the cache expires, because the
+// plugin has to resolve metadata of every artifact and takes a while.
+func AnalyzeMavenUpdates(repoPath string, maven MavenSettings) MavenUpdateReport {
+	report := MavenUpdateReport{RepoName: filepath.Base(repoPath), RepoPath: repoPath, PomHash: pomHash(repoPath)}
+	if report.PomHash == "" {
+		report.Error = "no pom.xml found"
+		return report
+	}
+
+	if _, err := dataSubDir("maven-updates"); err == nil {
+		var cached MavenUpdateReport
+		if err := readJSONFile(cacheFilePath("maven-updates", repoPath), &cached); err == nil &&
+			cached.PomHash == report.PomHash && cached.Error == "" && time.Since(cached.CheckedAt) < mavenUpdatesCacheTTL {
+			return cached
+		}
+	}
+
+	cmd := maven.Command(repoPath, "-B", "versions:display-dependency-updates", "versions:display-plugin-updates")
+	output, err := cmdlimit.CombinedOutput(cmd)
+	report.CheckedAt = time.Now()
+	if err != nil {
+		report.Error = fmt.Sprintf("versions-maven-plugin failed: %v", err)
+		return report
+	}
+	report.Updates = ParseMavenUpdatesOutput(string(output))
+	if report.Updates == nil {
+		report.Updates = []MavenUpdate{}
+	}
+
+	if path := cacheFilePath("maven-updates", repoPath); path != "" {
+		writeJSONFile(path, &report)
+	}
+	return report
+}
+
+// CountMavenDependencyUpdates counts the distinct outdated dependencies (plugins are not counted)
+func CountMavenDependencyUpdates(updates []MavenUpdate) int {
+	seen := make(map[string]bool)
+	for _, u := range updates {
+		if u.Kind == "dependency" {
+			seen[u.GroupId+":"+u.ArtifactId] = true
+		}
+	}
+	return len(seen)
+}
@@ -0,0 +1,150 @@
+package logic
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorecode/updates/internal/logic/patch"
+)
+
+// maxMigrationAnalyses limits the stored analyses per repo and migration target
+const maxMigrationAnalyses = 50
+
+// MigrationAnalysis is the stored result of one migration analysis of a repo
+type MigrationAnalysis struct {
+	Time      time.Time `json:"time"`
+	Files     []string  `json:"files"`    // Files the recipes would still change
+	Findings  []string  `json:"findings"` // Recognized changes as "category: file: description"
+	Additions int       `json:"additions"`
+	Deletions int       `json:"deletions"`
+}
+
+// MigrationHistory is the analysis series of one repo for one migration type and target version
+type MigrationHistory struct {
+	RepoPath      string              `json:"repoPath"`
+	MigrationType string              `json:"migrationType"`
+	TargetVersion string              `json:"targetVersion"`
+	Analyses      []MigrationAnalysis `json:"analyses"` // Oldest first
+}
+
+// MigrationDelta compares an analysis with the previous one of the same target
+type MigrationDelta struct {
+	Since         time.Time `json:"since"`         // Time of the previous analysis
+	FilesFixed    int       `json:"filesFixed"`    // Files that needed changes before and no longer do
+	FilesAdded    int       `json:"filesAdded"`    // Files that need changes now and did not before
+	FindingsFixed int       `json:"findingsFixed"` // Recognized changes no longer proposed
+	FindingsNew   int       `json:"findingsNew"`   // Recognized changes proposed for the first time
+}
+
+// String describes the delta for the analysis log, e.g. "12 files fixed, 3 new findings since 2026-09-17"
+func (d MigrationDelta) String() string {
+	parts := []string{fmt.Sprintf("%d files fixed", d.FilesFixed)}
+	if d.FilesAdded > 0 {
+		parts = append(parts, fmt.Sprintf("%d files newly affected", d.FilesAdded))
+	}
+	if d.FindingsFixed > 0 {
+		parts = append(parts, fmt.Sprintf("%d findings resolved", d.FindingsFixed))
+	}
+	parts = append(parts, fmt.Sprintf("%d new findings", d.FindingsNew))
+	return fmt.Sprintf("%s since %s", strings.Join(parts, ", "), d.Since.Format("2006-01-02"))
+}
+
+var migrationHistoryMu sync.Mutex
+
+// NewMigrationAnalysis condenses a parsed patch (nil = no changes required) for the history
+func NewMigrationAnalysis(summary *patch.Summary, at time.Time) MigrationAnalysis {
+	analysis := MigrationAnalysis{Time: at, Files: []string{}, Findings: []string{}}
+	if summary == nil {
+		return analysis
+	}
+	for _, file := range summary.Files {
+		analysis.Files = append(analysis.Files, file.Path)
+	}
+	for _, change := range summary.Changes {
+		analysis.Findings = append(analysis.Findings, change.Category+": "+change.File+": "+change.Description)
+	}
+	sort.Strings(analysis.Files)
+	sort.Strings(analysis.Findings)
+	analysis.Additions, analysis.Deletions = summary.Additions, summary.Deletions
+	return analysis
+}
+
+// migrationHistoryKey identifies the history of a repo and migration target
+func migrationHistoryKey(repoPath, migrationType, targetVersion string) string {
+	return repoPath + "|" + migrationType + "|" + targetVersion
+}
+
+// LoadMigrationHistory returns the stored analyses of a repo for a migration target (empty if none)
+func LoadMigrationHistory(repoPath, migrationType, targetVersion string) (MigrationHistory, error) {
+	migrationHistoryMu.Lock()
+	defer migrationHistoryMu.Unlock()
+	return loadMigrationHistory(repoPath, migrationType, targetVersion)
+}
+
+func loadMigrationHistory(repoPath, migrationType, targetVersion string) (MigrationHistory, error) {
+	history := MigrationHistory{RepoPath: repoPath, MigrationType: migrationType, TargetVersion: targetVersion, Analyses: []MigrationAnalysis{}}
+	if _, err := dataSubDir("migration-history"); err != nil {
+		return history, err
+	}
+	path := cacheFilePath("migration-history", migrationHistoryKey(repoPath, migrationType, targetVersion))
+	if err := readJSONFile(path, &history); err != nil && !os.IsNotExist(err) {
+		return history, err
+	}
+	return history, nil
+}
+
+// SaveMigrationAnalysis appends an analysis to the history of a repo and migration target and returns the
+// delta to the previous analysis (nil for the first one)
+func SaveMigrationAnalysis(repoPath, migrationType, targetVersion string, analysis MigrationAnalysis) (*MigrationDelta, error) {
+	migrationHistoryMu.Lock()
+	defer migrationHistoryMu.Unlock()
+
+	history, err := loadMigrationHistory(repoPath, migrationType, targetVersion)
+	if err != nil {
+		return nil, err
+	}
+	var delta *MigrationDelta
+	if n := len(history.Analyses); n > 0 {
+		d := compareMigrationAnalyses(history.Analyses[n-1], analysis)
+		delta = &d
+	}
+	history.Analyses = append(history.Analyses, analysis)
+	if len(history.Analyses) > maxMigrationAnalyses {
+		history.Analyses = history.Analyses[len(history.Analyses)-maxMigrationAnalyses:]
+	}
+	path := cacheFilePath("migration-history", migrationHistoryKey(repoPath, migrationType, targetVersion))
+	return delta, writeJSONFile(path, history)
+}
+
+// compareMigrationAnalyses counts the files and findings that disappeared or appeared between two analyses
+func compareMigrationAnalyses(previous, current MigrationAnalysis) MigrationDelta {
+	delta := MigrationDelta{Since: previous.Time}
+	delta.FilesFixed, delta.FilesAdded = setDifference(previous.Files, current.Files)
+	delta.FindingsFixed, delta.FindingsNew = setDifference(previous.Findings, current.Findings)
+	return delta
+}
+
+// setDifference counts the entries only in before (removed) and only in after (added)
+func setDifference(before, after []string) (removed, added int) {
+	inAfter := make(map[string]bool, len(after))
+	for _, entry := range after {
+		inAfter[entry] = true
+	}
+	inBefore := make(map[string]bool, len(before))
+	for _, entry := range before {
+		inBefore[entry] = true
+		if !inAfter[entry] {
+			removed++
+		}
+	}
+	for entry := range inAfter {
+		if !inBefore[entry] {
+			added++
+		}
+	}
+	return removed, added
+}
@@ -0,0 +1,248 @@
+package logic
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorecode/updates/internal/logic/registry"
+)
+
+// Environment variables overriding the stored network settings
+const (
+	OfflineEnv       = "GITHOUSEKEEPER_OFFLINE"        // "true" = no outbound requests, cached data only
+	CABundleEnv      = "GITHOUSEKEEPER_CA_BUNDLE"      // PEM file with additional trusted CA certificates
+	MavenPasswordEnv = "GITHOUSEKEEPER_MAVEN_PASSWORD" // Password or token of the Maven repository manager
+)
+
+const networkFile = "network.json"
+
+// defaultRequestTimeout applies when no timeout is configured
+const defaultRequestTimeout = 15
+
+// NetworkSettings configures the outbound HTTP requests (registries, endoflife.date, OSV, GitHub, GitLab).
+// Without a proxy the standard HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables apply.
+type NetworkSettings struct {
+	HTTPProxy      string `json:"httpProxy,omitempty"`  // e.g. http://proxy.example.com:3128
+	HTTPSProxy     string `json:"httpsProxy,omitempty"` // Defaults to HTTPProxy
+	NoProxy        string `json:"noProxy,omitempty"`    // Comma-separated hosts and domains reached directly
+	CABundle       string `json:"caBundle,omitempty"`   // PEM file trusted in addition to the system CAs
+	TimeoutSeconds int    `json:"timeoutSeconds"`       // Per request, default 15
+	Offline        bool   `json:"offline"`              // No outbound requests, cached data only
+	// Repository manager (Nexus, Artifactory) used instead of Maven Central for Maven versions and POMs
+	MavenRepository string `json:"mavenRepository,omitempty"` // e.g. https://nexus.example.com/repository/maven-public
+	MavenUsername   string `json:"mavenUsername,omitempty"`
+	MavenPassword   string `json:"mavenPassword,omitempty"`
+}
+
+// Redacted returns the settings without the repository password, for the UI
+func (s NetworkSettings) Redacted() NetworkSettings {
+	s.MavenPassword = ""
+	return s
+}
+
+var (
+	networkMu sync.Mutex
+	// baseTransport is the unmodified default transport the settings are applied to
+	baseTransport = http.DefaultTransport.(*http.Transport).Clone()
+)
+
+func networkPath() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, networkFile), nil
+}
+
+// LoadNetworkSettings returns the stored network settings with the environment overrides applied
+func LoadNetworkSettings() (NetworkSettings, error) {
+	networkMu.Lock()
+	defer networkMu.Unlock()
+
+	settings := NetworkSettings{TimeoutSeconds: defaultRequestTimeout}
+	path, err := networkPath()
+	if err != nil {
+		return settings, err
+	}
+	if err := readJSONFile(path, &settings); err != nil && !os.IsNotExist(err) {
+		return settings, err
+	}
+	switch strings.ToLower(os.Getenv(OfflineEnv)) {
+	case "true", "1":
+		settings.Offline = true
+	case "false", "0":
+		settings.Offline = false
+	}
+	if bundle := os.Getenv(CABundleEnv); bundle != "" {
+		settings.CABundle = bundle
+	}
+	if password := os.Getenv(MavenPasswordEnv); password != "" {
+		settings.MavenPassword = password
+	}
+	return settings, nil
+}
+
+// SaveNetworkSettings validates, stores and applies the network settings. An empty repository password
+// keeps the stored one.
+func SaveNetworkSettings(settings NetworkSettings) (NetworkSettings, error) {
+	settings.HTTPProxy = strings.TrimSpace(settings.HTTPProxy)
+	settings.HTTPSProxy = strings.TrimSpace(settings.HTTPSProxy)
+	settings.NoProxy = strings.TrimSpace(settings.NoProxy)
+	settings.CABundle = strings.TrimSpace(settings.CABundle)
+	settings.MavenRepository = strings.TrimRight(strings.TrimSpace(settings.MavenRepository), "/")
+	settings.MavenUsername = strings.TrimSpace(settings.MavenUsername)
+	if settings.MavenRepository != "" {
+		u, err := url.Parse(settings.MavenRepository)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return settings, fmt.Errorf("invalid Maven repository URL '%s', expected http(s)://host/path", settings.MavenRepository)
+		}
+	}
+	if settings.TimeoutSeconds < 0 {
+		return settings, fmt.Errorf("invalid timeout %d, expected seconds >= 0", settings.TimeoutSeconds)
+	}
+	if settings.TimeoutSeconds == 0 {
+		settings.TimeoutSeconds = defaultRequestTimeout
+	}
+	if _, err := newTransport(settings); err != nil {
+		return settings, err
+	}
+
+	networkMu.Lock()
+	path, err := networkPath()
+	if err == nil {
+		if settings.MavenPassword == "" {
+			var stored NetworkSettings
+			if readJSONFile(path, &stored) == nil {
+				settings.MavenPassword = stored.MavenPassword
+			}
+		}
+		err = writeJSONFile(path, settings)
+	}
+	networkMu.Unlock()
+	if err != nil {
+		return settings, err
+	}
+	return settings, ApplyNetworkSettings(settings)
+}
+
+// ApplyNetworkSettings configures the default HTTP transport (proxy, CA bundle), the request timeout
+// of the registry lookups, the offline mode and the Maven repository
+func ApplyNetworkSettings(settings NetworkSettings) error {
+	transport, err := newTransport(settings)
+	if err != nil {
+		return err
+	}
+	timeout := settings.TimeoutSeconds
+	if timeout <= 0 {
+		timeout = defaultRequestTimeout
+	}
+	http.DefaultTransport = transport
+	registry.Default.HTTP = &http.Client{Transport: transport, Timeout: time.Duration(timeout) * time.Second}
+	registry.Default.Offline = settings.Offline
+	if settings.MavenRepository != "" {
+		// The search API only exists on Maven Central, versions come from maven-metadata.xml
+		registry.Default.Endpoints.MavenRepository = settings.MavenRepository
+		registry.Default.Endpoints.MavenSearch = ""
+	} else {
+		registry.Default.Endpoints.MavenRepository = registry.DefaultEndpoints.MavenRepository
+		registry.Default.Endpoints.MavenSearch = registry.DefaultEndpoints.MavenSearch
+	}
+	registry.Default.MavenUsername = settings.MavenUsername
+	registry.Default.MavenPassword = settings.MavenPassword
+	return nil
+}
+
+// newTransport builds the transport of the settings from the unmodified default transport
+func newTransport(settings NetworkSettings) (*http.Transport, error) {
+	transport := baseTransport.Clone()
+
+	if settings.HTTPProxy != "" || settings.HTTPSProxy != "" {
+		httpProxy, err := parseProxyURL(settings.HTTPProxy)
+		if err != nil {
+			return nil, err
+		}
+		httpsProxy, err := parseProxyURL(settings.HTTPSProxy)
+		if err != nil {
+			return nil, err
+		}
+		if httpsProxy == nil {
+			httpsProxy = httpProxy
+		}
+		noProxy := settings.NoProxy
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			if bypassProxy(req.URL.Hostname(), noProxy) {
+				return nil, nil
+			}
+			if req.URL.Scheme == "https" {
+				return httpsProxy, nil
+			}
+			return httpProxy, nil
+		}
+	}
+
+	if settings.CABundle != "" {
+		pem, err := os.ReadFile(settings.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA bundle: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in CA bundle %s", settings.CABundle)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+	return transport, nil
+}
+
+// parseProxyURL validates a proxy URL; "" means no proxy and returns nil
+func parseProxyURL(raw string) (*url.URL, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL '%s'", raw)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5" {
+		return nil, fmt.Errorf("unsupported proxy scheme '%s', expected http, https or socks5", u.Scheme)
+	}
+	return u, nil
+}
+
+// bypassProxy reports whether host matches the NO_PROXY style list: "*", exact hosts, domains
+// (".example.com" or "example.com" also match their sub-domains) and IP addresses
+func bypassProxy(host, noProxy string) bool {
+	host = strings.ToLower(host)
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return true
+		}
+		if h, _, err := net.SplitHostPort(entry); err == nil {
+			entry = h // Ports are ignored
+		}
+		domain := strings.TrimPrefix(entry, ".")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
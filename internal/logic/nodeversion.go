@@ -0,0 +1,175 @@
+package logic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// nodeTargetPattern accepts a Node.js major or full version as bump target, e.g. "22" or "22.11.0"
+	nodeTargetPattern = regexp.MustCompile(`^\d+(?:\.\d+){0,2}$`)
+	// nodeSimpleVersion is a single version with optional operator and wildcards: "18", "v18.17.0", ">=18", "^18.x"
+	nodeSimpleVersion = regexp.MustCompile(`^([~^>=<v\s]*)(\d+)((?:\.(?:\d+|x|\*))*)$`)
+	enginesPattern    = regexp.MustCompile(`"engines"\s*:\s*\{[^}]*\}`)
+	enginesNode       = regexp.MustCompile(`("node"\s*:\s*")([^"]*)(")`)
+	// node-version of actions/setup-node; matrix lists and expressions are left alone
+	workflowNodeVersion = regexp.MustCompile(`(?m)^(\s*(?:-\s*)?node-version:\s*)(['"]?)(v?\d[\w.]*)(['"]?)`)
+)
+
+// NodeLTSVersions returns the Node.js LTS release lines that are still supported, newest first (endoflife.date)
+func NodeLTSVersions(now time.Time) ([]ReleaseCycle, error) {
+	cycles, err := FetchReleaseCycles("nodejs")
+	if err != nil {
+		return nil, err
+	}
+	result := []ReleaseCycle{}
+	for _, c := range cycles.Cycles {
+		if !c.LTS || c.Ended {
+			continue
+		}
+		if eol, err := time.Parse("2006-01-02", c.EOL); err == nil && eol.Before(now) {
+			continue
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+// bumpNodeVersion moves a single version to target, keeping the operator, a "v" prefix and ".x" wildcards.
+// Aliases like "lts/*", ranges and versions already on the target major are returned unchanged.
+func bumpNodeVersion(version, target string) (string, bool) {
+	m := nodeSimpleVersion.FindStringSubmatch(strings.TrimSpace(version))
+	if m == nil {
+		return version, false
+	}
+	major, _, _ := strings.Cut(target, ".")
+	if m[2] == major {
+		return version, false
+	}
+	suffix := ""
+	if !strings.Contains(target, ".") && strings.Trim(m[3], ".x*") == "" {
+		suffix = m[3] // "18.x" -> "22.x"
+	}
+	return m[1] + target + suffix, true
+}
+
+// updateEnginesNode updates engines.node of a package.json, keeping the formatting of the file.
+// Compound ranges ("^18 || ^20", ">=16 <21") become ">=<target>".
+func updateEnginesNode(content, target string) (string, bool) {
+	engines := enginesPattern.FindStringIndex(content)
+	if engines == nil {
+		return content, false
+	}
+	block := content[engines[0]:engines[1]]
+	m := enginesNode.FindStringSubmatchIndex(block)
+	if m == nil {
+		return content, false
+	}
+	constraint := block[m[4]:m[5]]
+	updated, changed := bumpNodeVersion(constraint, target)
+	if !changed && !nodeSimpleVersion.MatchString(strings.TrimSpace(constraint)) && strings.ContainsAny(constraint, "0123456789") {
+		updated, changed = ">="+target, ">="+target != constraint
+	}
+	if !changed {
+		return content, false
+	}
+	block = block[:m[4]] + updated + block[m[5]:]
+	return content[:engines[0]] + block + content[engines[1]:], true
+}
+
+// updateWorkflowNodeVersions updates the literal node-version values of a GitHub Actions workflow
+func updateWorkflowNodeVersions(content, target string) (string, int) {
+	count := 0
+	updated := workflowNodeVersion.ReplaceAllStringFunc(content, func(line string) string {
+		m := workflowNodeVersion.FindStringSubmatch(line)
+		if m[2] != m[4] {
+			return line // Unbalanced quotes
+		}
+		version, changed := bumpNodeVersion(m[3], target)
+		if !changed {
+			return line
+		}
+		count++
+		return m[1] + m[2] + version + m[4]
+	})
+	return updated, count
+}
+
+// processNodeVersion moves .nvmrc, .node-version, package.json engines.node and the node-version of
+// GitHub Actions workflows to the target Node.js version and commits the changes
+func processNodeVersion(repoPath, target string, commits *commitQueue, log func(string)) {
+	target = strings.TrimPrefix(strings.TrimSpace(target), "v")
+	if target == "" {
+		return
+	}
+	if !nodeTargetPattern.MatchString(target) {
+		log(fmt.Sprintf("  [ERROR] Invalid Node.js version '%s', expected e.g. 22 or 22.11.0.", target))
+		return
+	}
+
+	changes := make(map[string]string)
+	var files []string
+	found := false
+	update := func(rel string, apply func(string) (string, bool)) {
+		data, err := os.ReadFile(filepath.Join(repoPath, filepath.FromSlash(rel)))
+		if err != nil {
+			return
+		}
+		found = true
+		if updated, changed := apply(string(data)); changed {
+			changes[rel] = updated
+			files = append(files, rel)
+		}
+	}
+
+	versionFile := func(content string) (string, bool) {
+		version, changed := bumpNodeVersion(content, target)
+		if !changed {
+			return content, false
+		}
+		if strings.HasSuffix(content, "\n") {
+			version += "\n"
+		}
+		return version, true
+	}
+	update(".nvmrc", versionFile)
+	update(".node-version", versionFile)
+	update("package.json", func(content string) (string, bool) { return updateEnginesNode(content, target) })
+	if found {
+		for _, workflow := range ciFiles(repoPath, ".github/workflows") {
+			update(workflow, func(content string) (string, bool) {
+				updated, count := updateWorkflowNodeVersions(content, target)
+				return updated, count > 0
+			})
+		}
+	}
+	if !found {
+		return
+	}
+	if len(files) == 0 {
+		log(fmt.Sprintf("  [INFO] Node.js version is already %s.", target))
+		return
+	}
+
+	for _, rel := range files {
+		if err := os.WriteFile(filepath.Join(repoPath, filepath.FromSlash(rel)), []byte(changes[rel]), 0644); err != nil {
+			log(fmt.Sprintf("  [ERROR] Could not write %s: %v", rel, err))
+			return
+		}
+		log(fmt.Sprintf("  [INFO] Node.js version updated in %s.", rel))
+	}
+	if err := runGitCommand(repoPath, append([]string{"add", "--"}, files...)...); err != nil {
+		log(fmt.Sprintf("  [ERROR] git add failed: %v", err))
+		return
+	}
+	verb, err := commits.commit(repoPath, "Update Node.js to "+target)
+	if err != nil {
+		log(fmt.Sprintf("  [ERROR] git commit failed: %v", err))
+		return
+	}
+	log(fmt.Sprintf("  Node.js version updated in %d file(s) and %s.", len(files), verb))
+}
@@ -0,0 +1,244 @@
+package logic
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorecode/updates/internal/logic/registry"
+)
+
+// OSVVulnerability is the part of an OSV.dev advisory needed to enrich scanner findings
+type OSVVulnerability struct {
+	ID        string            `json:"id"`
+	Aliases   []string          `json:"aliases,omitempty"`
+	Summary   string            `json:"summary,omitempty"`
+	Severity  string            `json:"severity,omitempty"`  // CRITICAL, HIGH, MEDIUM, LOW ("" if the advisory has no rating)
+	CVSSScore float64           `json:"cvssScore,omitempty"` // CVSS v3 base score (0 if unknown)
+	Fixed     map[string]string `json:"fixed,omitempty"`     // Lower-cased package name -> fix versions (comma separated)
+	FetchedAt time.Time         `json:"fetchedAt"`
+}
+
+// osvCacheTTL is how long advisories are reused; ratings rarely change once published
+const osvCacheTTL = 7 * 24 * time.Hour
+
+// osvAPIURL is a variable so tests can point it to a local server
+var osvAPIURL = "https://api.osv.dev/v1"
+
+var (
+	osvMemCache   = make(map[string]*OSVVulnerability)
+	osvMemCacheMu sync.Mutex
+)
+
+// LookupOSV returns the OSV advisory for an ID (PYSEC-, GHSA-, GO-, CVE-, ...), using the memory and disk cache
+func LookupOSV(id string) (*OSVVulnerability, error) {
+	osvMemCacheMu.Lock()
+	if cached, ok := osvMemCache[id]; ok && cacheFresh(cached.FetchedAt, osvCacheTTL) {
+		osvMemCacheMu.Unlock()
+		return cached, nil
+	}
+	osvMemCacheMu.Unlock()
+
+	cachePath := cacheFilePath("osv-cache", id)
+	if cachePath != "" {
+		var cached OSVVulnerability
+		if err := readJSONFile(cachePath, &cached); err == nil && cacheFresh(cached.FetchedAt, osvCacheTTL) {
+			osvMemCacheMu.Lock()
+			osvMemCache[id] = &cached
+			osvMemCacheMu.Unlock()
+			return &cached, nil
+		}
+	}
+
+	var raw struct {
+		ID       string   `json:"id"`
+		Aliases  []string `json:"aliases"`
+		Summary  string   `json:"summary"`
+		Severity []struct {
+			Type  string `json:"type"`
+			Score string `json:"score"`
+		} `json:"severity"`
+		Affected []struct {
+			Package struct {
+				Name string `json:"name"`
+			} `json:"package"`
+			Ranges []struct {
+				Events []struct {
+					Fixed string `json:"fixed"`
+				} `json:"events"`
+			} `json:"ranges"`
+		} `json:"affected"`
+		DatabaseSpecific struct {
+			Severity string `json:"severity"`
+		} `json:"database_specific"`
+	}
+	if err := registry.GetJSON(osvAPIURL+"/vulns/"+url.PathEscape(id), &raw); err != nil {
+		return nil, fmt.Errorf("OSV lookup for %s failed: %v", id, err)
+	}
+
+	vuln := &OSVVulnerability{
+		ID:        raw.ID,
+		Aliases:   raw.Aliases,
+		Summary:   raw.Summary,
+		Fixed:     make(map[string]string),
+		FetchedAt: time.Now(),
+	}
+	for _, sev := range raw.Severity {
+		if sev.Type != "CVSS_V3" {
+			continue
+		}
+		if score, err := CVSS3BaseScore(sev.Score); err == nil && score > vuln.CVSSScore {
+			vuln.CVSSScore = score
+		}
+	}
+	if vuln.CVSSScore > 0 {
+		vuln.Severity = SeverityFromCVSS(vuln.CVSSScore)
+	} else {
+		// GitHub advisories carry a rating even without a vector ("MODERATE" = MEDIUM)
+		switch strings.ToUpper(raw.DatabaseSpecific.Severity) {
+		case "CRITICAL", "HIGH", "LOW":
+			vuln.Severity = strings.ToUpper(raw.DatabaseSpecific.Severity)
+		case "MODERATE", "MEDIUM":
+			vuln.Severity = "MEDIUM"
+		}
+	}
+	for _, affected := range raw.Affected {
+		name := strings.ToLower(affected.Package.Name)
+		for _, r := range affected.Ranges {
+			for _, ev := range r.Events {
+				if ev.Fixed == "" {
+					continue
+				}
+				if vuln.Fixed[name] != "" {
+					vuln.Fixed[name] += ","
+				}
+				vuln.Fixed[name] += ev.Fixed
+			}
+		}
+	}
+
+	osvMemCacheMu.Lock()
+	osvMemCache[id] = vuln
+	osvMemCacheMu.Unlock()
+	if cachePath != "" {
+		writeJSONFile(cachePath, vuln)
+	}
+	return vuln, nil
+}
+
+// FixVersionFor returns the smallest fix version of the package that is newer than the used version.
+// Falls back to the newest fix if the used version is unknown; "" if the advisory has no fix.
+func (v *OSVVulnerability) FixVersionFor(pkg, usedVersion string) string {
+	fixes := v.Fixed[strings.ToLower(pkg)]
+	if fixes == "" && len(v.Fixed) == 1 {
+		// Package naming differs between scanners and OSV (e.g. Go module vs. package path)
+		for _, f := range v.Fixed {
+			fixes = f
+		}
+	}
+	if !registry.IsVersion(usedVersion) {
+		usedVersion = "" // Ranges like ">=1.0,<1.2" are no usable version
+	}
+	best := ""
+	for _, fix := range strings.Split(fixes, ",") {
+		if fix == "" {
+			continue
+		}
+		if usedVersion != "" && registry.CompareVersions(fix, usedVersion) <= 0 {
+			continue
+		}
+		if best == "" || (usedVersion != "" && registry.CompareVersions(fix, best) < 0) || (usedVersion == "" && registry.CompareVersions(fix, best) > 0) {
+			best = fix
+		}
+	}
+	return best
+}
+
+// SeverityFromCVSS maps a CVSS base score to the severity buckets used in the findings
+func SeverityFromCVSS(score float64) string {
+	switch {
+	case score >= 9.0:
+		return "CRITICAL"
+	case score >= 7.0:
+		return "HIGH"
+	case score >= 4.0:
+		return "MEDIUM"
+	default:
+		return "LOW"
+	}
+}
+
+// CVSS3BaseScore computes the base score of a CVSS v3.x vector ("CVSS:3.1/AV:N/AC:L/...").
+// A plain numeric score is accepted as well.
+func CVSS3BaseScore(vector string) (float64, error) {
+	var plain float64
+	if _, err := fmt.Sscanf(vector, "%g", &plain); err == nil && !strings.Contains(vector, "/") {
+		return plain, nil
+	}
+	if !strings.HasPrefix(vector, "CVSS:3") {
+		return 0, fmt.Errorf("unsupported CVSS vector '%s'", vector)
+	}
+
+	metrics := make(map[string]string)
+	for _, part := range strings.Split(vector, "/")[1:] {
+		if kv := strings.SplitN(part, ":", 2); len(kv) == 2 {
+			metrics[kv[0]] = kv[1]
+		}
+	}
+
+	weights := map[string]map[string]float64{
+		"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+		"AC": {"L": 0.77, "H": 0.44},
+		"UI": {"N": 0.85, "R": 0.62},
+		"C":  {"H": 0.56, "L": 0.22, "N": 0},
+		"I":  {"H": 0.56, "L": 0.22, "N": 0},
+		"A":  {"H": 0.56, "L": 0.22, "N": 0},
+	}
+	value := make(map[string]float64)
+	for metric, table := range weights {
+		w, ok := table[metrics[metric]]
+		if !ok {
+			return 0, fmt.Errorf("CVSS vector '%s' lacks metric %s", vector, metric)
+		}
+		value[metric] = w
+	}
+
+	changed := metrics["S"] == "C"
+	if !changed && metrics["S"] != "U" {
+		return 0, fmt.Errorf("CVSS vector '%s' lacks metric S", vector)
+	}
+	pr := map[string]float64{"N": 0.85, "L": 0.62, "H": 0.27}
+	if changed {
+		pr = map[string]float64{"N": 0.85, "L": 0.68, "H": 0.5}
+	}
+	privileges, ok := pr[metrics["PR"]]
+	if !ok {
+		return 0, fmt.Errorf("CVSS vector '%s' lacks metric PR", vector)
+	}
+
+	iss := 1 - (1-value["C"])*(1-value["I"])*(1-value["A"])
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, nil
+	}
+	exploitability := 8.22 * value["AV"] * value["AC"] * privileges * value["UI"]
+	if changed {
+		return cvssRoundUp(math.Min(1.08*(impact+exploitability), 10)), nil
+	}
+	return cvssRoundUp(math.Min(impact+exploitability, 10)), nil
+}
+
+// cvssRoundUp is the "Roundup" function of the CVSS v3.1 specification
+func cvssRoundUp(x float64) float64 {
+	i := int(math.Round(x * 100000))
+	if i%10000 == 0 {
+		return float64(i) / 100000
+	}
+	return float64(i/10000+1) / 10
+}
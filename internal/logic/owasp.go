@@ -0,0 +1,245 @@
+package logic
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorecode/updates/internal/cmdlimit"
+)
+
+// OwaspPlugin is the dependency-check Maven plugin used for OWASP scans and NVD database updates
+const OwaspPlugin = "org.owasp:dependency-check-maven:12.1.0"
+
+// NVDApiKeyEnv overrides the stored NVD API key
+const NVDApiKeyEnv = "GITHOUSEKEEPER_NVD_API_KEY"
+
+const owaspFile = "owasp.json"
+
+// owaspUpdatedMarker is written into the data directory after a successful update
+const owaspUpdatedMarker = "githousekeeper-updated"
+
+// OwaspSettings configures the NVD database shared by the OWASP dependency-check scans of all
+// repositories, instead of one download per local Maven repository
+type OwaspSettings struct {
+	// NVDApiKey speeds the download up from hours to minutes: https://nvd.nist.gov/developers/request-an-api-key
+	NVDApiKey     string `json:"nvdApiKey,omitempty"`
+	DataDirectory string `json:"dataDirectory,omitempty"` // Default <data dir>/owasp-data
+}
+
+// Redacted returns the settings without the API key, for the UI
+func (s OwaspSettings) Redacted() OwaspSettings {
+	s.NVDApiKey = ""
+	return s
+}
+
+var (
+	owaspMu sync.Mutex
+	// owaspUpdateMu allows one database update at a time, dependency-check locks the database anyway
+	owaspUpdateMu sync.Mutex
+)
+
+func owaspPath() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, owaspFile), nil
+}
+
+// LoadOwaspSettings returns the stored OWASP settings with the environment override applied
+func LoadOwaspSettings() (OwaspSettings, error) {
+	owaspMu.Lock()
+	defer owaspMu.Unlock()
+
+	var settings OwaspSettings
+	path, err := owaspPath()
+	if err != nil {
+		return settings, err
+	}
+	if err := readJSONFile(path, &settings); err != nil && !os.IsNotExist(err) {
+		return settings, err
+	}
+	if key := os.Getenv(NVDApiKeyEnv); key != "" {
+		settings.NVDApiKey = key
+	}
+	return settings, nil
+}
+
+// SaveOwaspSettings validates and stores the OWASP settings. An empty API key keeps the stored one.
+func SaveOwaspSettings(settings OwaspSettings) (OwaspSettings, error) {
+	settings.NVDApiKey = strings.TrimSpace(settings.NVDApiKey)
+	settings.DataDirectory = strings.TrimSpace(settings.DataDirectory)
+	if settings.DataDirectory != "" && !filepath.IsAbs(settings.DataDirectory) {
+		return settings, fmt.Errorf("data directory '%s' must be an absolute path", settings.DataDirectory)
+	}
+
+	owaspMu.Lock()
+	defer owaspMu.Unlock()
+	path, err := owaspPath()
+	if err != nil {
+		return settings, err
+	}
+	if settings.NVDApiKey == "" {
+		var stored OwaspSettings
+		if readJSONFile(path, &stored) == nil {
+			settings.NVDApiKey = stored.NVDApiKey
+		}
+	}
+	return settings, writeJSONFile(path, settings)
+}
+
+// dataDir returns the configured database directory, created if missing
+func (s OwaspSettings) dataDir() (string, error) {
+	if s.DataDirectory == "" {
+		return dataSubDir("owasp-data")
+	}
+	if err := os.MkdirAll(s.DataDirectory, 0755); err != nil {
+		return "", err
+	}
+	return s.DataDirectory, nil
+}
+
+// ScanArgs returns the plugin flags of a scan reading the shared database. The database is updated
+// once before the scans by UpdateOwaspDatabase, so the scans themselves do not update it.
+func (s OwaspSettings) ScanArgs() ([]string, error) {
+	dir, err := s.dataDir()
+	if err != nil {
+		return nil, err
+	}
+	return []string{"-DdataDirectory=" + dir, "-DautoUpdate=false"}, nil
+}
+
+// OwaspDatabase is the state of the shared NVD database
+type OwaspDatabase struct {
+	DataDirectory string     `json:"dataDirectory"`
+	Exists        bool       `json:"exists"`
+	UpdatedAt     *time.Time `json:"updatedAt,omitempty"`
+	AgeHours      float64    `json:"ageHours,omitempty"`
+	SizeBytes     int64      `json:"sizeBytes"`
+	HasAPIKey     bool       `json:"hasApiKey"`
+	Updating      bool       `json:"updating"`
+}
+
+// InspectOwaspDatabase reports where the shared NVD database is, its size and when it was last
+// updated: by the marker of UpdateOwaspDatabase, else by the newest database file
+func InspectOwaspDatabase() (OwaspDatabase, error) {
+	settings, err := LoadOwaspSettings()
+	if err != nil {
+		return OwaspDatabase{}, err
+	}
+	dir, err := settings.dataDir()
+	if err != nil {
+		return OwaspDatabase{}, err
+	}
+	db := OwaspDatabase{DataDirectory: dir, HasAPIKey: settings.NVDApiKey != ""}
+	if owaspUpdateMu.TryLock() {
+		owaspUpdateMu.Unlock()
+	} else {
+		db.Updating = true
+	}
+
+	var newest time.Time
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		db.SizeBytes += info.Size()
+		// H2 database of dependency-check, e.g. odc.mv.db
+		if strings.HasSuffix(d.Name(), ".mv.db") {
+			db.Exists = true
+			if info.ModTime().After(newest) {
+				newest = info.ModTime()
+			}
+		}
+		return nil
+	})
+	if info, err := os.Stat(filepath.Join(dir, owaspUpdatedMarker)); err == nil && db.Exists {
+		newest = info.ModTime()
+	}
+	if db.Exists {
+		db.UpdatedAt = &newest
+		db.AgeHours = time.Since(newest).Hours()
+	}
+	return db, nil
+}
+
+// lineWriter passes every complete line written to it to log
+type lineWriter struct {
+	mu      sync.Mutex
+	pending []byte
+	log     func(string)
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending = append(w.pending, p...)
+	for {
+		i := bytes.IndexAny(w.pending, "\r\n")
+		if i < 0 {
+			return len(p), nil
+		}
+		if line := strings.TrimSpace(string(w.pending[:i])); line != "" {
+			w.log(line)
+		}
+		w.pending = w.pending[i+1:]
+	}
+}
+
+// flush logs an unterminated last line
+func (w *lineWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if line := strings.TrimSpace(string(w.pending)); line != "" {
+		w.log(line)
+	}
+	w.pending = nil
+}
+
+// UpdateOwaspDatabase downloads or refreshes the shared NVD database with the update-only goal,
+// passing every output line of Maven to log. A running update is waited for. The first download
+// takes 10+ minutes with an NVD API key and much longer without.
+func UpdateOwaspDatabase(ctx context.Context, maven MavenSettings, log func(string)) error {
+	settings, err := LoadOwaspSettings()
+	if err != nil {
+		return err
+	}
+	dir, err := settings.dataDir()
+	if err != nil {
+		return err
+	}
+	if !owaspUpdateMu.TryLock() {
+		log("Waiting for the running NVD database update...")
+		owaspUpdateMu.Lock()
+	}
+	defer owaspUpdateMu.Unlock()
+
+	args := []string{"-B", OwaspPlugin + ":update-only", "-DdataDirectory=" + dir}
+	if settings.NVDApiKey != "" {
+		args = append(args, "-DnvdApiKey="+settings.NVDApiKey)
+	} else {
+		log("No NVD API key configured, the download is rate limited and may take hours.")
+	}
+	// update-only needs no project, the data directory is a neutral place to run it in
+	maven.UseWrapper = false
+	cmd := maven.CommandContext(ctx, dir, args...)
+	out := &lineWriter{log: log}
+	cmd.Stdout, cmd.Stderr = out, out
+	err = cmdlimit.Run(cmd)
+	out.flush()
+	if err != nil {
+		return fmt.Errorf("NVD database update failed: %v", err)
+	}
+	return os.WriteFile(filepath.Join(dir, owaspUpdatedMarker), []byte(time.Now().Format(time.RFC3339)+"\n"), 0644)
+}
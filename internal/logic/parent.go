@@ -0,0 +1,68 @@
+package logic
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gorecode/updates/internal/logic/registry"
+)
+
+// ParentSuggestion is the parent POM of a repo and the newest release of that parent
+type ParentSuggestion struct {
+	RepoName   string `json:"repoName"`
+	RepoPath   string `json:"repoPath"`
+	GroupID    string `json:"groupId,omitempty"`
+	ArtifactID string `json:"artifactId,omitempty"`
+	Current    string `json:"current,omitempty"`
+	Latest     string `json:"latest,omitempty"`
+	Outdated   bool   `json:"outdated"` // Latest is newer than Current
+	Error      string `json:"error,omitempty"`
+}
+
+// ReadPomParent returns the <parent> coordinates of the root pom.xml; ok is false without a pom.xml or parent
+func ReadPomParent(repoPath string) (parent Parent, ok bool, err error) {
+	data, err := os.ReadFile(filepath.Join(repoPath, "pom.xml"))
+	if os.IsNotExist(err) {
+		return parent, false, nil
+	}
+	if err != nil {
+		return parent, false, err
+	}
+	var project MinimalProjectSimpleFixed
+	if err := xml.Unmarshal(data, &project); err != nil {
+		return parent, false, err
+	}
+	parent = Parent{
+		GroupId:    strings.TrimSpace(project.Parent.GroupId),
+		ArtifactId: strings.TrimSpace(project.Parent.ArtifactId),
+		Version:    strings.TrimSpace(project.Parent.Version),
+	}
+	return parent, parent.GroupId != "" && parent.ArtifactId != "", nil
+}
+
+// SuggestParentVersion looks up the newest release of the repo's parent POM. Repos without a parent
+// return nil. Corporate parents need a configured repository manager (see NetworkSettings).
+func SuggestParentVersion(repoPath string) *ParentSuggestion {
+	parent, ok, err := ReadPomParent(repoPath)
+	suggestion := &ParentSuggestion{RepoName: filepath.Base(repoPath), RepoPath: repoPath}
+	if err != nil {
+		suggestion.Error = "pom.xml: " + err.Error()
+		return suggestion
+	}
+	if !ok {
+		return nil
+	}
+	suggestion.GroupID, suggestion.ArtifactID, suggestion.Current = parent.GroupId, parent.ArtifactId, parent.Version
+
+	latest, err := registry.Latest("maven", parent.GroupId+":"+parent.ArtifactId)
+	if err != nil {
+		suggestion.Error = err.Error()
+		return suggestion
+	}
+	suggestion.Latest = latest
+	// A property or missing version cannot be compared, any release is a suggestion then
+	suggestion.Outdated = !registry.IsVersion(parent.Version) || registry.CompareVersions(latest, parent.Version) > 0
+	return suggestion
+}
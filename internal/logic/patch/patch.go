@@ -0,0 +1,256 @@
+// Package patch parses the unified diffs OpenRewrite writes (target/rewrite/rewrite.patch) into a typed
+// model of files, hunks and categorized changes, so clients can render, filter and process them.
+package patch
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Change categories, in display order (see Categories)
+const (
+	CategoryAnnotations   = "annotations"   // e.g. @RequestMapping(method=GET) -> @GetMapping
+	CategoryImports       = "imports"       // Changed imports, e.g. javax -> jakarta
+	CategoryModernization = "modernization" // Newer language features and APIs
+	CategoryConfiguration = "configuration" // Changed or deprecated properties
+	CategoryRemoval       = "removal"       // Removed deprecated or unnecessary code
+)
+
+// Categories lists the change categories in display order
+var Categories = []string{CategoryAnnotations, CategoryImports, CategoryModernization, CategoryConfiguration, CategoryRemoval}
+
+// File states
+const (
+	FileModified = "modified"
+	FileAdded    = "added"
+	FileDeleted  = "deleted"
+	FileRenamed  = "renamed"
+)
+
+// Line kinds
+const (
+	LineContext = "context"
+	LineAdded   = "added"
+	LineRemoved = "removed"
+)
+
+// Summary is the parsed patch of one repository
+type Summary struct {
+	Files     []File   `json:"files"`
+	Changes   []Change `json:"changes"` // Recognized changes, empty if none could be categorized
+	Additions int      `json:"additions"`
+	Deletions int      `json:"deletions"`
+}
+
+// File is the diff of one file
+type File struct {
+	Path      string `json:"path"`              // Path after the change (before for deleted files)
+	OldPath   string `json:"oldPath,omitempty"` // Path before a rename
+	Status    string `json:"status"`            // FileModified, FileAdded, FileDeleted or FileRenamed
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	Hunks     []Hunk `json:"hunks"`
+}
+
+// Hunk is one "@@ -a,b +c,d @@" block of a file diff
+type Hunk struct {
+	Header   string `json:"header"` // Text after the second @@, usually the enclosing declaration
+	OldStart int    `json:"oldStart"`
+	OldLines int    `json:"oldLines"`
+	NewStart int    `json:"newStart"`
+	NewLines int    `json:"newLines"`
+	Lines    []Line `json:"lines"`
+}
+
+// Line is a line of a hunk
+type Line struct {
+	Kind string `json:"kind"` // LineContext, LineAdded or LineRemoved
+	Text string `json:"text"` // Without the leading marker
+}
+
+// Change is a recognized kind of change with the snippets before and after
+type Change struct {
+	Category    string `json:"category"`
+	File        string `json:"file"`
+	Description string `json:"description"`
+	Before      string `json:"before,omitempty"`
+	After       string `json:"after,omitempty"`
+}
+
+var hunkPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@ ?(.*)$`)
+
+// lookahead is how many lines after a removed line its replacement is searched
+const lookahead = 4
+
+// Parse reads a unified diff (git format) into a Summary
+func Parse(patch string) Summary {
+	summary := Summary{Files: []File{}, Changes: []Change{}}
+	var file *File
+	var hunk *Hunk
+
+	flush := func() {
+		if file == nil {
+			return
+		}
+		if hunk != nil {
+			file.Hunks = append(file.Hunks, *hunk)
+			hunk = nil
+		}
+		summary.Files = append(summary.Files, *file)
+		summary.Additions += file.Additions
+		summary.Deletions += file.Deletions
+		file = nil
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(patch, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			flush()
+			file = &File{Status: FileModified, Hunks: []Hunk{}}
+			if parts := strings.Fields(line); len(parts) >= 4 {
+				file.Path = strings.TrimPrefix(parts[3], "b/")
+			}
+			continue
+		}
+		if file == nil {
+			continue
+		}
+
+		// File headers come before the first hunk
+		if hunk == nil {
+			switch {
+			case strings.HasPrefix(line, "new file mode"):
+				file.Status = FileAdded
+				continue
+			case strings.HasPrefix(line, "deleted file mode"):
+				file.Status = FileDeleted
+				continue
+			case strings.HasPrefix(line, "rename from "):
+				file.Status, file.OldPath = FileRenamed, strings.TrimPrefix(line, "rename from ")
+				continue
+			case strings.HasPrefix(line, "--- "):
+				if old := strings.TrimPrefix(line, "--- "); old != "/dev/null" && file.Status == FileDeleted {
+					file.Path = strings.TrimPrefix(old, "a/")
+				}
+				continue
+			case strings.HasPrefix(line, "+++ "):
+				if current := strings.TrimPrefix(line, "+++ "); current != "/dev/null" {
+					file.Path = strings.TrimPrefix(current, "b/")
+				}
+				continue
+			}
+		}
+
+		if m := hunkPattern.FindStringSubmatch(line); m != nil {
+			if hunk != nil {
+				file.Hunks = append(file.Hunks, *hunk)
+			}
+			hunk = &Hunk{Header: m[5], OldStart: atoi(m[1], 0), OldLines: atoi(m[2], 1), NewStart: atoi(m[3], 0), NewLines: atoi(m[4], 1), Lines: []Line{}}
+			continue
+		}
+		if hunk == nil || line == "" {
+			continue
+		}
+		switch line[0] {
+		case '+':
+			hunk.Lines = append(hunk.Lines, Line{Kind: LineAdded, Text: line[1:]})
+			file.Additions++
+		case '-':
+			hunk.Lines = append(hunk.Lines, Line{Kind: LineRemoved, Text: line[1:]})
+			file.Deletions++
+		case ' ':
+			hunk.Lines = append(hunk.Lines, Line{Kind: LineContext, Text: line[1:]})
+		}
+	}
+	flush()
+
+	seen := make(map[string]bool)
+	for _, f := range summary.Files {
+		for _, change := range categorize(f) {
+			key := change.Category + "|" + change.File + "|" + change.Description
+			if !seen[key] {
+				seen[key] = true
+				summary.Changes = append(summary.Changes, change)
+			}
+		}
+	}
+	return summary
+}
+
+func atoi(s string, fallback int) int {
+	if n, err := strconv.Atoi(s); err == nil {
+		return n
+	}
+	return fallback
+}
+
+// categorize recognizes the changes of a file: every removed line is paired with the next added line of its hunk
+func categorize(f File) []Change {
+	var changes []Change
+	isConfig := strings.HasSuffix(f.Path, ".properties") || strings.HasSuffix(f.Path, ".yml") || strings.HasSuffix(f.Path, ".yaml")
+	add := func(category, description, before, after string) {
+		changes = append(changes, Change{Category: category, File: f.Path, Description: description, Before: before, After: after})
+	}
+
+	for _, hunk := range f.Hunks {
+		for i, line := range hunk.Lines {
+			if line.Kind != LineRemoved {
+				continue
+			}
+			removed := strings.TrimSpace(line.Text)
+			added := ""
+			for j := i + 1; j < len(hunk.Lines) && j <= i+lookahead; j++ {
+				if hunk.Lines[j].Kind == LineAdded {
+					added = strings.TrimSpace(hunk.Lines[j].Text)
+					break
+				}
+			}
+
+			// RequestMapping -> GetMapping/PostMapping/etc.
+			if strings.Contains(removed, "@RequestMapping") && strings.Contains(removed, "RequestMethod") {
+				for _, method := range []string{"Get", "Post", "Put", "Delete", "Patch"} {
+					if strings.Contains(added, "@"+method+"Mapping") {
+						add(CategoryAnnotations, fmt.Sprintf("@RequestMapping(method=%s) → @%sMapping", strings.ToUpper(method), method), removed, added)
+						break
+					}
+				}
+			}
+
+			// Import changes (RequestMethod imports are covered by the annotation changes)
+			if strings.HasPrefix(removed, "import ") && strings.HasPrefix(added, "import ") && !strings.Contains(removed, "RequestMethod") {
+				oldImport := strings.TrimSuffix(strings.TrimPrefix(removed, "import "), ";")
+				newImport := strings.TrimSuffix(strings.TrimPrefix(added, "import "), ";")
+				if oldImport != newImport {
+					add(CategoryImports, oldImport+" → "+newImport, removed, added)
+				}
+			}
+
+			// HibernateProxy pattern matching
+			if strings.Contains(removed, "instanceof HibernateProxy") && strings.Contains(removed, "((HibernateProxy)") &&
+				strings.Contains(added, "instanceof HibernateProxy hp") {
+				add(CategoryModernization, "instanceof + cast → Pattern Matching (Java 16+)", removed, added)
+			}
+
+			// String.format -> formatted
+			if strings.Contains(removed, "String.format(") && strings.Contains(added, ".formatted(") {
+				add(CategoryModernization, "String.format() → String.formatted()", removed, added)
+			}
+
+			// @Autowired removal
+			if strings.Contains(removed, "@Autowired") && !strings.Contains(added, "@Autowired") {
+				add(CategoryRemoval, "Removed unnecessary @Autowired (constructor injection)", removed, "")
+			}
+
+			// Configuration property changes
+			if isConfig && (strings.Contains(removed, "=") || strings.Contains(removed, ":")) &&
+				(strings.Contains(removed, "deprecated") || strings.Contains(added, "#")) {
+				property := strings.TrimSpace(strings.Split(strings.Split(removed, "=")[0], ":")[0])
+				if property != "" && !strings.HasPrefix(property, "#") {
+					add(CategoryConfiguration, fmt.Sprintf("Property '%s' deprecated/changed", property), removed, added)
+				}
+			}
+		}
+	}
+	return changes
+}
@@ -0,0 +1,98 @@
+package patch
+
+import (
+	"strings"
+	"testing"
+)
+
+const samplePatch = `diff --git a/src/main/java/com/acme/UserController.java b/src/main/java/com/acme/UserController.java
+index 1111111..2222222 100644
+--- a/src/main/java/com/acme/UserController.java
++++ b/src/main/java/com/acme/UserController.java
+@@ -1,8 +1,7 @@ package com.acme;
+-import javax.validation.Valid;
++import jakarta.validation.Valid;
+ import org.springframework.web.bind.annotation.*;
+
+ public class UserController {
+-    @RequestMapping(value = "/users", method = RequestMethod.GET)
++    @GetMapping("/users")
+     public List<User> list() {
+-        return String.format("%s", name);
++        return "%s".formatted(name);
+@@ -20,3 +19,2 @@ public class UserController {
+-    @Autowired
+     public UserController(UserService service) {
+diff --git a/src/main/resources/old.properties b/src/main/resources/old.properties
+deleted file mode 100644
+index 3333333..0000000
+--- a/src/main/resources/old.properties
++++ /dev/null
+@@ -1 +0,0 @@
+-server.port=8080
+`
+
+// ===========================================
+// Tests for Patch Parsing
+// ===========================================
+
+func TestParse_FilesAndHunks(t *testing.T) {
+	summary := Parse(samplePatch)
+	if len(summary.Files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(summary.Files))
+	}
+
+	controller := summary.Files[0]
+	if controller.Path != "src/main/java/com/acme/UserController.java" || controller.Status != FileModified {
+		t.Errorf("Unexpected file: %s (%s)", controller.Path, controller.Status)
+	}
+	if len(controller.Hunks) != 2 || controller.Additions != 3 || controller.Deletions != 4 {
+		t.Errorf("Expected 2 hunks, +3 -4, got %d hunks, +%d -%d", len(controller.Hunks), controller.Additions, controller.Deletions)
+	}
+	hunk := controller.Hunks[1]
+	if hunk.OldStart != 20 || hunk.OldLines != 3 || hunk.NewStart != 19 || hunk.NewLines != 2 || hunk.Header != "public class UserController {" {
+		t.Errorf("Unexpected hunk header: %+v", hunk)
+	}
+	if hunk.Lines[0].Kind != LineRemoved || hunk.Lines[1].Kind != LineContext {
+		t.Errorf("Unexpected hunk lines: %+v", hunk.Lines)
+	}
+
+	deleted := summary.Files[1]
+	if deleted.Status != FileDeleted || deleted.Path != "src/main/resources/old.properties" || deleted.Hunks[0].NewLines != 0 {
+		t.Errorf("Unexpected deleted file: %+v", deleted)
+	}
+	if summary.Additions != 3 || summary.Deletions != 5 {
+		t.Errorf("Expected +3 -5 in total, got +%d -%d", summary.Additions, summary.Deletions)
+	}
+}
+
+func TestParse_Categories(t *testing.T) {
+	summary := Parse(samplePatch)
+	found := make(map[string]Change)
+	for _, change := range summary.Changes {
+		found[change.Category] = change
+	}
+
+	if c := found[CategoryAnnotations]; c.Description != "@RequestMapping(method=GET) → @GetMapping" || c.After != `@GetMapping("/users")` {
+		t.Errorf("Unexpected annotation change: %+v", c)
+	}
+	if c := found[CategoryImports]; c.Description != "javax.validation.Valid → jakarta.validation.Valid" {
+		t.Errorf("Unexpected import change: %+v", c)
+	}
+	if c := found[CategoryModernization]; !strings.Contains(c.Description, "formatted") {
+		t.Errorf("Unexpected modernization change: %+v", c)
+	}
+	if c := found[CategoryRemoval]; c.File != "src/main/java/com/acme/UserController.java" || c.Before != "@Autowired" {
+		t.Errorf("Unexpected removal change: %+v", c)
+	}
+	if len(summary.Changes) != 4 {
+		t.Errorf("Expected 4 changes, got %+v", summary.Changes)
+	}
+}
+
+func TestParse_Empty(t *testing.T) {
+	summary := Parse("")
+	if len(summary.Files) != 0 || len(summary.Changes) != 0 || summary.Files == nil || summary.Changes == nil {
+		t.Errorf("Expected an empty summary with empty lists, got %+v", summary)
+	}
+}
@@ -0,0 +1,53 @@
+package logic
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PhpSettings controls the Composer dependency bumps of a run
+type PhpSettings struct {
+	Update        bool     `json:"update"`        // Update all dependencies within their constraints
+	Packages      []string `json:"packages"`      // Update only these packages ("vendor/name") and their dependencies
+	VerifyCommand string   `json:"verifyCommand"` // Optional, e.g. "composer test"; run after dependencies changed
+}
+
+// processPhpProject runs composer update --with-dependencies and the optional verification.
+// Returns false if a step failed.
+func processPhpProject(repoPath string, settings PhpSettings, forceVerify bool, log func(string)) bool {
+	lockPath := filepath.Join(repoPath, "composer.lock")
+	before, _ := os.ReadFile(lockPath)
+
+	var packages []string
+	for _, p := range settings.Packages {
+		if p = strings.TrimSpace(p); p != "" {
+			packages = append(packages, p)
+		}
+	}
+
+	enabled := settings.Update || len(packages) > 0
+	if enabled {
+		args := []string{"update", "--with-dependencies", "--no-interaction", "--no-progress"}
+		if !settings.Update {
+			args = append(args, packages...)
+		}
+		log(fmt.Sprintf("  Running composer %s...", strings.Join(args, " ")))
+		if output, err := runTool(repoPath, "composer", args...); err != nil {
+			log(fmt.Sprintf("  [ERROR] composer update failed: %v\nOutput:\n%s", err, output))
+			return false
+		}
+	}
+
+	after, _ := os.ReadFile(lockPath)
+	changed := !bytes.Equal(before, after)
+	if changed {
+		log("  composer.lock updated.")
+	} else if enabled {
+		log("  PHP dependencies already up to date.")
+	}
+
+	return verifyEcosystem(repoPath, "PHP", settings.VerifyCommand, changed || forceVerify, log)
+}
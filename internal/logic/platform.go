@@ -0,0 +1,163 @@
+package logic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// isWindows reports whether external commands have to go through cmd.exe (mvn.cmd, mvnw.cmd)
+func isWindows() bool {
+	return runtime.GOOS == "windows"
+}
+
+// cmdMetacharacters need quoting in a cmd.exe command line
+const cmdMetacharacters = " \t\"&|<>^()%!"
+
+// cmdQuote quotes an argument for a command line run through cmd.exe. Arguments with spaces or cmd
+// metacharacters are enclosed in double quotes; backslashes before quotes are doubled as the C runtime
+// of the started program expects.
+func cmdQuote(arg string) string {
+	if arg == "" {
+		return `""`
+	}
+	if !strings.ContainsAny(arg, cmdMetacharacters) {
+		return arg
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	slashes := 0
+	for _, r := range arg {
+		switch r {
+		case '\\':
+			slashes++
+		case '"':
+			b.WriteString(strings.Repeat(`\`, slashes+1))
+			slashes = 0
+		default:
+			slashes = 0
+		}
+		b.WriteRune(r)
+	}
+	b.WriteString(strings.Repeat(`\`, slashes))
+	b.WriteByte('"')
+	return b.String()
+}
+
+// cmdLine is the complete cmd.exe command line running command. With /S, cmd only strips the outer
+// quotes, so a quoted program path (e.g. below "Program Files") and quoted arguments stay intact.
+func cmdLine(command string) string {
+	return `cmd.exe /S /C "` + command + `"`
+}
+
+// programCommand runs a program with arguments. On Windows it goes through cmd.exe, which batch files
+// such as mvn.cmd need, with every argument quoted for cmd.
+func programCommand(ctx context.Context, name string, args ...string) *exec.Cmd {
+	if !isWindows() {
+		return exec.CommandContext(ctx, name, args...)
+	}
+	// cmd.Args keep the plain arguments for cmdlimit.ClassOf and logging, CreateProcess gets the quoted line
+	cmd := exec.CommandContext(ctx, "cmd", append([]string{"/C", name}, args...)...)
+	quoted := []string{cmdQuote(name)}
+	for _, arg := range args {
+		quoted = append(quoted, cmdQuote(arg))
+	}
+	setCmdLine(cmd, cmdLine(strings.Join(quoted, " ")))
+	return cmd
+}
+
+// shellCommand runs a user-configured command line through the OS shell, as typed
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if !isWindows() {
+		return exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd := exec.CommandContext(ctx, "cmd", "/C", command)
+	setCmdLine(cmd, cmdLine(command))
+	return cmd
+}
+
+// longPath prepares a root for walking deep trees: on Windows, Go only lifts the 260 character limit
+// for absolute paths
+func longPath(path string) string {
+	if !isWindows() {
+		return path
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// ConfigureGitEnvironment makes every git started by the server print paths unquoted (non-ASCII file
+// names as they are instead of octal escapes) and, on Windows, accept paths longer than 260 characters.
+// The settings are passed with GIT_CONFIG_COUNT (git 2.31+) after any already set there.
+func ConfigureGitEnvironment() {
+	settings := [][2]string{{"core.quotePath", "false"}}
+	if isWindows() {
+		settings = append(settings, [2]string{"core.longpaths", "true"})
+	}
+	for key, value := range gitConfigEnv(os.Getenv, settings) {
+		os.Setenv(key, value)
+	}
+}
+
+// gitConfigEnv returns the GIT_CONFIG_* variables adding settings to the ones of the environment
+func gitConfigEnv(getenv func(string) string, settings [][2]string) map[string]string {
+	count, _ := strconv.Atoi(getenv("GIT_CONFIG_COUNT"))
+	env := make(map[string]string)
+	for _, setting := range settings {
+		env[fmt.Sprintf("GIT_CONFIG_KEY_%d", count)] = setting[0]
+		env[fmt.Sprintf("GIT_CONFIG_VALUE_%d", count)] = setting[1]
+		count++
+	}
+	env["GIT_CONFIG_COUNT"] = strconv.Itoa(count)
+	return env
+}
+
+// windowsPathPattern matches absolute Windows paths: C:\... and \\server\share
+var windowsPathPattern = regexp.MustCompile(`^(?:[A-Za-z]:\\|\\\\[^\\])`)
+
+// SlashPathsJSON rewrites the absolute Windows paths in a JSON document (values and object keys) to
+// forward slashes. Other strings, e.g. replacement patterns, are left alone. Returns data unchanged if it
+// is not JSON.
+func SlashPathsJSON(data []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return data
+	}
+	var out bytes.Buffer
+	if err := json.NewEncoder(&out).Encode(slashPaths(document)); err != nil {
+		return data
+	}
+	return out.Bytes()
+}
+
+func slashPaths(v interface{}) interface{} {
+	switch value := v.(type) {
+	case string:
+		if windowsPathPattern.MatchString(value) {
+			return strings.ReplaceAll(value, `\`, "/")
+		}
+	case []interface{}:
+		for i := range value {
+			value[i] = slashPaths(value[i])
+		}
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			result[slashPaths(key).(string)] = slashPaths(item)
+		}
+		return result
+	}
+	return v
+}
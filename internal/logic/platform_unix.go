@@ -0,0 +1,20 @@
+//go:build !windows
+
+package logic
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killProcessGroup makes cancelling cmd kill its children too, e.g. the commands of "sh -c" or the
+// test JVMs forked by Maven, which would otherwise keep running and hold the output open
+func killProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
+
+// setCmdLine is only needed for cmd.exe on Windows
+func setCmdLine(cmd *exec.Cmd, line string) {}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package logic
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killProcessGroup is a no-op on Windows; children of a killed command are abandoned after killWaitDelay
+func killProcessGroup(cmd *exec.Cmd) {}
+
+// setCmdLine passes the command line to CreateProcess as is. cmd.exe does not follow the quoting rules
+// Go applies to cmd.Args, so commands run through it need a line quoted by cmdLine.
+func setCmdLine(cmd *exec.Cmd, line string) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CmdLine = line
+}
@@ -0,0 +1,114 @@
+package logic
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gorecode/updates/internal/logic/registry"
+)
+
+// ReplacementTypeProperty sets the Maven property named in Search to Replace
+// ("latest" resolves the newest release of the artifacts using the property from Maven Central)
+const ReplacementTypeProperty = "property"
+
+// LatestPropertyValue is the Replace value that looks the version up on Maven Central
+const LatestPropertyValue = "latest"
+
+var (
+	pomPropertiesPattern = regexp.MustCompile(`(?s)<properties>.*?</properties>`)
+	pomArtifactPattern   = regexp.MustCompile(`(?s)<(dependency|plugin)>(.*?)</(?:dependency|plugin)>`)
+	pomGroupIdPattern    = regexp.MustCompile(`<groupId>\s*(.*?)\s*</groupId>`)
+	pomArtifactIdPattern = regexp.MustCompile(`<artifactId>\s*(.*?)\s*</artifactId>`)
+)
+
+// setPomProperty changes the value of a property in the <properties> block.
+// Returns the new content, the previous value and whether the property is defined.
+func setPomProperty(content, name, value string) (string, string, bool) {
+	loc := pomPropertiesPattern.FindStringIndex(content)
+	if loc == nil {
+		return content, "", false
+	}
+	block := content[loc[0]:loc[1]]
+	tag := regexp.QuoteMeta(name)
+	re := regexp.MustCompile(`<` + tag + `>\s*(.*?)\s*</` + tag + `>`)
+	m := re.FindStringSubmatchIndex(block)
+	if m == nil {
+		return content, "", false
+	}
+	old := block[m[2]:m[3]]
+	block = block[:m[2]] + value + block[m[3]:]
+	return content[:loc[0]] + block + content[loc[1]:], old, true
+}
+
+// propertyArtifacts returns the "group:artifact" coordinates whose version is ${name}
+func propertyArtifacts(content, name string) []string {
+	reference := regexp.MustCompile(`<version>\s*\$\{` + regexp.QuoteMeta(name) + `\}\s*</version>`)
+	var result []string
+	for _, m := range pomArtifactPattern.FindAllStringSubmatch(content, -1) {
+		body := m[2]
+		if !reference.MatchString(body) {
+			continue
+		}
+		artifact := pomArtifactIdPattern.FindStringSubmatch(body)
+		if artifact == nil {
+			continue
+		}
+		group := "org.apache.maven.plugins" // Default for plugins without groupId
+		if g := pomGroupIdPattern.FindStringSubmatch(body); g != nil {
+			group = g[1]
+		} else if m[1] == "dependency" {
+			continue
+		}
+		result = append(result, group+":"+artifact[1])
+	}
+	return result
+}
+
+// resolveLatestPropertyValue looks up the newest release of the first artifact using the property
+func resolveLatestPropertyValue(content, name string) (string, error) {
+	artifacts := propertyArtifacts(content, name)
+	if len(artifacts) == 0 {
+		return "", fmt.Errorf("no dependency or plugin uses ${%s}", name)
+	}
+	releases, err := registry.Fetch("maven", artifacts[0])
+	if err != nil {
+		return "", err
+	}
+	if releases.Latest == "" {
+		return "", fmt.Errorf("no release of %s found", artifacts[0])
+	}
+	return releases.Latest, nil
+}
+
+// applyPomReplacement applies one replacement rule to the content of a pom file.
+// Text and regex rules use applyReplacement; property rules set a <properties> value.
+func applyPomReplacement(content string, r Replacement, file string, log func(string)) (string, bool) {
+	if r.Type != ReplacementTypeProperty {
+		return applyReplacement(content, r)
+	}
+
+	name := strings.TrimSpace(r.Search)
+	value := strings.TrimSpace(r.Replace)
+	if _, current, ok := setPomProperty(content, name, ""); !ok || value == "" {
+		return content, false
+	} else if strings.EqualFold(value, LatestPropertyValue) {
+		latest, err := resolveLatestPropertyValue(content, name)
+		if err != nil {
+			log(fmt.Sprintf("  [WARNING] %s: latest version for property '%s' unknown: %v", file, name, err))
+			return content, false
+		}
+		// Never downgrade, e.g. when the project already uses a milestone
+		if registry.CompareVersions(latest, current) <= 0 {
+			return content, false
+		}
+		value = latest
+	}
+
+	updated, old, _ := setPomProperty(content, name, value)
+	if old == value {
+		return content, false
+	}
+	log(fmt.Sprintf("  [INFO] %s: property %s updated: %s -> %s", file, name, old, value))
+	return updated, true
+}
@@ -0,0 +1,89 @@
+package logic
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+)
+
+// FilePreview is a file a replacement run would change
+type FilePreview struct {
+	Path string `json:"path"` // Relative to the repo
+	Diff string `json:"diff"` // Unified diff
+}
+
+// ReplacementPreview lists the changes the replacements would make in one repo
+type ReplacementPreview struct {
+	RepoPath string        `json:"repoPath"`
+	RepoName string        `json:"repoName"`
+	Files    []FilePreview `json:"files"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// PreviewReplacements applies the replacements in memory to the current working tree and
+// returns the resulting diffs. Nothing is written, checked out or built.
+// The scope routing matches ProcessRepo: poms of the reactor get the pom rules, all other files the project rules.
+func PreviewReplacements(repoPath string, replacements []Replacement, scope string, excludedFolders []string) ReplacementPreview {
+	preview := ReplacementPreview{RepoPath: repoPath, RepoName: filepath.Base(repoPath), Files: []FilePreview{}}
+	if len(replacements) == 0 {
+		return preview
+	}
+
+	pomRules, projectRules := replacements, replacements
+	switch scope {
+	case "pom-only":
+		projectRules = nil
+	case "exclude-pom":
+		pomRules = nil
+	}
+
+	reactorPoms := map[string]bool{"pom.xml": true}
+	if len(pomRules) > 0 {
+		for _, module := range DiscoverMavenModules(repoPath) {
+			reactorPoms[module.PomPath] = true
+		}
+	}
+
+	quiet := func(string) {}
+	err := walkReplaceableFiles(context.Background(), repoPath, excludedFolders, quiet, func(path string, info os.FileInfo, content string) {
+		rel, err := filepath.Rel(repoPath, path)
+		if err != nil {
+			return
+		}
+
+		updated := content
+		if info.Name() == "pom.xml" {
+			if !reactorPoms[rel] {
+				return
+			}
+			for _, r := range pomRules {
+				if r.Search == "" || !r.AppliesTo(rel) {
+					continue
+				}
+				if newContent, ok := applyPomReplacement(updated, r, rel, quiet); ok {
+					updated = newContent
+				}
+			}
+		} else {
+			for _, r := range projectRules {
+				if !r.AppliesTo(rel) {
+					continue
+				}
+				if newContent, ok := applyReplacement(updated, r); ok {
+					updated = newContent
+				}
+			}
+		}
+
+		if updated != content {
+			preview.Files = append(preview.Files, FilePreview{
+				Path: filepath.ToSlash(rel),
+				Diff: unifiedDiff(filepath.ToSlash(rel), content, updated),
+			})
+		}
+	})
+	if err != nil {
+		preview.Error = err.Error()
+	}
+	return preview
+}
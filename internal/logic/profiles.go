@@ -0,0 +1,148 @@
+package logic
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// profilesFile holds the saved run profiles, relative to DataDir
+const profilesFile = "profiles.json"
+
+// RunProfile is a saved run configuration that can be executed again with one call,
+// e.g. "Monthly Housekeeping" or "Spring 3.4 Migration"
+type RunProfile struct {
+	ID        string          `json:"id"` // Derived from the name, e.g. "monthly-housekeeping"
+	Name      string          `json:"name"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+	Request   json.RawMessage `json:"request"` // The complete run request (root path, exclusions, replacements, ...)
+}
+
+var (
+	profilesMu         sync.Mutex
+	profileIDPattern   = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	profileNameCleaner = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// ProfileID derives the ID of a profile from its name
+func ProfileID(name string) string {
+	return strings.Trim(profileNameCleaner.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// ValidProfileID reports whether id looks like an ID created by ProfileID (guards against odd URLs)
+func ValidProfileID(id string) bool {
+	return profileIDPattern.MatchString(id)
+}
+
+func profilesPath() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, profilesFile), nil
+}
+
+func readProfiles() ([]RunProfile, error) {
+	path, err := profilesPath()
+	if err != nil {
+		return nil, err
+	}
+	var profiles []RunProfile
+	if err := readJSONFile(path, &profiles); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s: %v", profilesFile, err)
+	}
+	return profiles, nil
+}
+
+func writeProfiles(profiles []RunProfile) error {
+	path, err := profilesPath()
+	if err != nil {
+		return err
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].ID < profiles[j].ID })
+	return writeJSONFile(path, profiles)
+}
+
+// ListProfiles returns all saved profiles, sorted by ID
+func ListProfiles() ([]RunProfile, error) {
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+	profiles, err := readProfiles()
+	if profiles == nil {
+		profiles = []RunProfile{}
+	}
+	return profiles, err
+}
+
+// LoadProfile returns the profile with the given ID
+func LoadProfile(id string) (*RunProfile, error) {
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+	profiles, err := readProfiles()
+	if err != nil {
+		return nil, err
+	}
+	for i := range profiles {
+		if profiles[i].ID == id {
+			return &profiles[i], nil
+		}
+	}
+	return nil, fmt.Errorf("profile '%s' not found", id)
+}
+
+// SaveProfile creates the profile or replaces the one with the same name
+func SaveProfile(name string, request json.RawMessage) (*RunProfile, error) {
+	name = strings.TrimSpace(name)
+	id := ProfileID(name)
+	if id == "" {
+		return nil, fmt.Errorf("profile name must contain letters or digits")
+	}
+	if len(request) == 0 || !json.Valid(request) {
+		return nil, fmt.Errorf("profile '%s' has no valid run request", name)
+	}
+
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+	profiles, err := readProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	profile := RunProfile{ID: id, Name: name, UpdatedAt: time.Now(), Request: request}
+	replaced := false
+	for i := range profiles {
+		if profiles[i].ID == id {
+			profiles[i] = profile
+			replaced = true
+		}
+	}
+	if !replaced {
+		profiles = append(profiles, profile)
+	}
+	if err := writeProfiles(profiles); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// DeleteProfile removes the profile with the given ID
+func DeleteProfile(id string) error {
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+	profiles, err := readProfiles()
+	if err != nil {
+		return err
+	}
+	for i := range profiles {
+		if profiles[i].ID == id {
+			return writeProfiles(append(profiles[:i], profiles[i+1:]...))
+		}
+	}
+	return fmt.Errorf("profile '%s' not found", id)
+}
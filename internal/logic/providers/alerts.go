@@ -0,0 +1,192 @@
+package providers
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// PlatformAlert is a vulnerability reported by the hosting platform's own scanner
+// (GitHub Dependabot alerts, GitLab dependency scanning)
+type PlatformAlert struct {
+	ID        string  `json:"id"`       // CVE if known, otherwise the advisory ID (GHSA-..., GitLab identifier)
+	Severity  string  `json:"severity"` // CRITICAL, HIGH, MEDIUM, LOW
+	CVSSScore float64 `json:"cvssScore,omitempty"`
+	Package   string  `json:"package"`
+	Version   string  `json:"version,omitempty"` // Installed version, or the vulnerable range if the platform only reports that
+	FixedIn   string  `json:"fixedIn,omitempty"`
+	Summary   string  `json:"summary,omitempty"`
+	URL       string  `json:"url,omitempty"`
+	File      string  `json:"file,omitempty"` // Manifest or lock file the dependency was found in
+}
+
+// normalizePlatformSeverity maps the platform ratings ("moderate", "Critical", ...) to ours
+func normalizePlatformSeverity(s string) string {
+	switch strings.ToUpper(s) {
+	case "CRITICAL":
+		return "CRITICAL"
+	case "HIGH":
+		return "HIGH"
+	case "MODERATE", "MEDIUM":
+		return "MEDIUM"
+	case "LOW":
+		return "LOW"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+type dependabotAlert struct {
+	HTMLURL    string `json:"html_url"`
+	Dependency struct {
+		Package struct {
+			Name string `json:"name"`
+		} `json:"package"`
+		ManifestPath string `json:"manifest_path"`
+	} `json:"dependency"`
+	SecurityAdvisory struct {
+		GHSAID   string `json:"ghsa_id"`
+		CVEID    string `json:"cve_id"`
+		Summary  string `json:"summary"`
+		Severity string `json:"severity"`
+		CVSS     struct {
+			Score float64 `json:"score"`
+		} `json:"cvss"`
+	} `json:"security_advisory"`
+	SecurityVulnerability struct {
+		VulnerableVersionRange string `json:"vulnerable_version_range"`
+		FirstPatchedVersion    *struct {
+			Identifier string `json:"identifier"`
+		} `json:"first_patched_version"`
+	} `json:"security_vulnerability"`
+}
+
+// ListDependabotAlerts returns the open Dependabot alerts of a repository ("owner/name")
+func (c *GitHubClient) ListDependabotAlerts(fullName string) ([]PlatformAlert, error) {
+	owner, name, ok := strings.Cut(fullName, "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid repository name '%s'", fullName)
+	}
+
+	const perPage = 100
+	var result []PlatformAlert
+
+	for page := 1; ; page++ {
+		endpoint := fmt.Sprintf("%s/repos/%s/%s/dependabot/alerts?state=open&per_page=%d&page=%d",
+			c.BaseURL, url.PathEscape(owner), url.PathEscape(name), perPage, page)
+
+		var alerts []dependabotAlert
+		if err := c.getJSON(endpoint, &alerts); err != nil {
+			return nil, err
+		}
+
+		for _, a := range alerts {
+			alert := PlatformAlert{
+				ID:        a.SecurityAdvisory.CVEID,
+				Severity:  normalizePlatformSeverity(a.SecurityAdvisory.Severity),
+				CVSSScore: a.SecurityAdvisory.CVSS.Score,
+				Package:   a.Dependency.Package.Name,
+				Version:   a.SecurityVulnerability.VulnerableVersionRange,
+				Summary:   a.SecurityAdvisory.Summary,
+				URL:       a.HTMLURL,
+				File:      a.Dependency.ManifestPath,
+			}
+			if alert.ID == "" {
+				alert.ID = a.SecurityAdvisory.GHSAID
+			}
+			if a.SecurityVulnerability.FirstPatchedVersion != nil {
+				alert.FixedIn = a.SecurityVulnerability.FirstPatchedVersion.Identifier
+			}
+			result = append(result, alert)
+		}
+
+		if len(alerts) < perPage {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+type gitlabVulnerabilityFinding struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Severity    string `json:"severity"`
+	Solution    string `json:"solution"`
+	Identifiers []struct {
+		ExternalType string `json:"external_type"`
+		Name         string `json:"name"`
+		URL          string `json:"url"`
+	} `json:"identifiers"`
+	Location struct {
+		File       string `json:"file"`
+		Dependency struct {
+			Package struct {
+				Name string `json:"name"`
+			} `json:"package"`
+			Version string `json:"version"`
+		} `json:"dependency"`
+	} `json:"location"`
+}
+
+// gitlabSolutionVersion extracts the version from solutions like "Upgrade to version 2.31.0 or above."
+var gitlabSolutionVersion = regexp.MustCompile(`(?i)upgrade to (?:version )?v?([0-9][0-9A-Za-z.\-+]*[0-9A-Za-z])`)
+
+// ListDependencyScanningFindings returns the dependency scanning findings of the latest
+// default branch pipeline of a project ("group/project" path or numeric ID)
+func (c *GitLabClient) ListDependencyScanningFindings(project string) ([]PlatformAlert, error) {
+	const perPage = 100
+	var result []PlatformAlert
+
+	for page := 1; ; page++ {
+		endpoint := fmt.Sprintf("%s/projects/%s/vulnerability_findings?report_type[]=dependency_scanning&per_page=%d&page=%d",
+			c.BaseURL, url.PathEscape(project), perPage, page)
+
+		var findings []gitlabVulnerabilityFinding
+		if err := c.getJSON(endpoint, &findings); err != nil {
+			return nil, err
+		}
+
+		for _, f := range findings {
+			alert := PlatformAlert{
+				Severity: normalizePlatformSeverity(f.Severity),
+				Package:  f.Location.Dependency.Package.Name,
+				Version:  f.Location.Dependency.Version,
+				Summary:  f.Name,
+				File:     f.Location.File,
+			}
+			for _, id := range f.Identifiers {
+				if strings.EqualFold(id.ExternalType, "cve") {
+					alert.ID, alert.URL = id.Name, id.URL
+					break
+				}
+				if alert.ID == "" {
+					alert.ID, alert.URL = id.Name, id.URL
+				}
+			}
+			if m := gitlabSolutionVersion.FindStringSubmatch(f.Solution); m != nil {
+				alert.FixedIn = m[1]
+			}
+			result = append(result, alert)
+		}
+
+		if len(findings) < perPage {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// remoteURLPattern matches https://host/path(.git), ssh://git@host/path and git@host:path remotes
+var remoteURLPattern = regexp.MustCompile(`^(?:[a-z+]+://)?(?:[^@/]+@)?([^/:]+)(?::[0-9]+)?[:/](.+?)(?:\.git)?/?$`)
+
+// ParseRemoteURL splits a git remote URL into host and repository path ("group/sub/project")
+func ParseRemoteURL(remote string) (host, path string, ok bool) {
+	m := remoteURLPattern.FindStringSubmatch(strings.TrimSpace(remote))
+	if m == nil || !strings.Contains(m[2], "/") {
+		return "", "", false
+	}
+	return strings.ToLower(m[1]), m[2], true
+}
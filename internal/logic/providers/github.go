@@ -0,0 +1,120 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// GitHubAPIURL is the default GitHub REST API endpoint
+const GitHubAPIURL = "https://api.github.com"
+
+// GitHubClient is a minimal client for the GitHub REST API
+type GitHubClient struct {
+	BaseURL    string
+	Token      string
+	HTTPClient *http.Client
+}
+
+// NewGitHubClient creates a client for github.com. Token may be empty for public repos only.
+func NewGitHubClient(token string) *GitHubClient {
+	return &GitHubClient{
+		BaseURL:    GitHubAPIURL,
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type githubRepo struct {
+	Name          string   `json:"name"`
+	FullName      string   `json:"full_name"`
+	Description   string   `json:"description"`
+	CloneURL      string   `json:"clone_url"`
+	SSHURL        string   `json:"ssh_url"`
+	HTMLURL       string   `json:"html_url"`
+	DefaultBranch string   `json:"default_branch"`
+	Language      string   `json:"language"`
+	Topics        []string `json:"topics"`
+	Archived      bool     `json:"archived"`
+	Private       bool     `json:"private"`
+}
+
+// ListOrgRepos returns all repositories of an organization (all pages)
+func (c *GitHubClient) ListOrgRepos(org string) ([]RemoteRepo, error) {
+	if org == "" {
+		return nil, fmt.Errorf("organization name is required")
+	}
+
+	const perPage = 100
+	var result []RemoteRepo
+
+	for page := 1; ; page++ {
+		endpoint := fmt.Sprintf("%s/orgs/%s/repos?type=all&per_page=%d&page=%d", c.BaseURL, url.PathEscape(org), perPage, page)
+
+		var repos []githubRepo
+		if err := c.getJSON(endpoint, &repos); err != nil {
+			return nil, err
+		}
+
+		for _, r := range repos {
+			result = append(result, RemoteRepo{
+				Name:          r.Name,
+				FullName:      r.FullName,
+				Description:   r.Description,
+				CloneURL:      r.CloneURL,
+				SSHURL:        r.SSHURL,
+				WebURL:        r.HTMLURL,
+				DefaultBranch: r.DefaultBranch,
+				Language:      r.Language,
+				Topics:        r.Topics,
+				Archived:      r.Archived,
+				Private:       r.Private,
+			})
+		}
+
+		if len(repos) < perPage {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+func (c *GitHubClient) getJSON(endpoint string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		json.Unmarshal(body, &apiErr)
+		if apiErr.Message == "" {
+			apiErr.Message = resp.Status
+		}
+		return fmt.Errorf("GitHub API error (%d): %s", resp.StatusCode, apiErr.Message)
+	}
+
+	return json.Unmarshal(body, v)
+}
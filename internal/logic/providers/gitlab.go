@@ -0,0 +1,226 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// GitLabAPIURL is the default GitLab REST API endpoint (gitlab.com)
+const GitLabAPIURL = "https://gitlab.com/api/v4"
+
+// GitLabClient is a minimal client for the GitLab REST API (v4)
+type GitLabClient struct {
+	BaseURL    string
+	Token      string
+	HTTPClient *http.Client
+}
+
+// NewGitLabClient creates a client for the given instance. An empty baseURL means gitlab.com,
+// a bare host like "https://gitlab.example.com" gets the "/api/v4" suffix appended.
+func NewGitLabClient(baseURL, token string) *GitLabClient {
+	baseURL = strings.TrimSuffix(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		baseURL = GitLabAPIURL
+	} else if !strings.HasSuffix(baseURL, "/api/v4") {
+		baseURL += "/api/v4"
+	}
+	return &GitLabClient{
+		BaseURL:    baseURL,
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type gitlabProject struct {
+	Path              string   `json:"path"`
+	PathWithNamespace string   `json:"path_with_namespace"`
+	Description       string   `json:"description"`
+	HTTPURLToRepo     string   `json:"http_url_to_repo"`
+	SSHURLToRepo      string   `json:"ssh_url_to_repo"`
+	WebURL            string   `json:"web_url"`
+	DefaultBranch     string   `json:"default_branch"`
+	Topics            []string `json:"topics"`
+	Archived          bool     `json:"archived"`
+	Visibility        string   `json:"visibility"`
+}
+
+// MergeRequest is an open merge request together with the status of its latest pipeline
+type MergeRequest struct {
+	ProjectID      int    `json:"projectId"`
+	IID            int    `json:"iid"`
+	Title          string `json:"title"`
+	WebURL         string `json:"webUrl"`
+	SourceBranch   string `json:"sourceBranch"`
+	TargetBranch   string `json:"targetBranch"`
+	Author         string `json:"author"`
+	CreatedAt      string `json:"createdAt"`
+	PipelineStatus string `json:"pipelineStatus"` // e.g. "success", "failed", "running"; empty if there is no pipeline
+}
+
+type gitlabMergeRequest struct {
+	ProjectID    int    `json:"project_id"`
+	IID          int    `json:"iid"`
+	Title        string `json:"title"`
+	WebURL       string `json:"web_url"`
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	CreatedAt    string `json:"created_at"`
+	Author       struct {
+		Username string `json:"username"`
+	} `json:"author"`
+	HeadPipeline *struct {
+		Status string `json:"status"`
+	} `json:"head_pipeline"`
+}
+
+const gitlabPerPage = 100
+
+// ListGroupProjects returns all projects of a group including its subgroups (all pages)
+func (c *GitLabClient) ListGroupProjects(group string) ([]RemoteRepo, error) {
+	if group == "" {
+		return nil, fmt.Errorf("group path is required")
+	}
+
+	var result []RemoteRepo
+	for page := 1; ; page++ {
+		endpoint := fmt.Sprintf("%s/groups/%s/projects?include_subgroups=true&per_page=%d&page=%d",
+			c.BaseURL, url.PathEscape(group), gitlabPerPage, page)
+
+		var projects []gitlabProject
+		if err := c.getJSON(endpoint, &projects); err != nil {
+			return nil, err
+		}
+
+		for _, p := range projects {
+			result = append(result, RemoteRepo{
+				Name:          gitlabProjectDir(group, p),
+				FullName:      p.PathWithNamespace,
+				Description:   p.Description,
+				CloneURL:      p.HTTPURLToRepo,
+				SSHURL:        p.SSHURLToRepo,
+				WebURL:        p.WebURL,
+				DefaultBranch: p.DefaultBranch,
+				Topics:        p.Topics,
+				Archived:      p.Archived,
+				Private:       p.Visibility != "public",
+			})
+		}
+
+		if len(projects) < gitlabPerPage {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// gitlabProjectDir is the clone directory of a project below the root path: its path relative to the
+// group, e.g. "payments/api" for "acme/backend/payments/api" in group "acme/backend", so that projects
+// of the same name in different subgroups do not share a directory. Projects shared from other
+// namespaces keep their full path.
+func gitlabProjectDir(group string, p gitlabProject) string {
+	if p.PathWithNamespace == "" {
+		return p.Path
+	}
+	prefix := strings.Trim(group, "/") + "/"
+	if len(p.PathWithNamespace) > len(prefix) && strings.EqualFold(p.PathWithNamespace[:len(prefix)], prefix) {
+		return p.PathWithNamespace[len(prefix):]
+	}
+	return p.PathWithNamespace
+}
+
+// ListGroupMergeRequests returns the open merge requests of a group (including subgroups)
+// whose source branch is sourceBranch, each with the status of its head pipeline.
+func (c *GitLabClient) ListGroupMergeRequests(group, sourceBranch string) ([]MergeRequest, error) {
+	if group == "" {
+		return nil, fmt.Errorf("group path is required")
+	}
+
+	var result []MergeRequest
+	for page := 1; ; page++ {
+		endpoint := fmt.Sprintf("%s/groups/%s/merge_requests?state=opened&per_page=%d&page=%d",
+			c.BaseURL, url.PathEscape(group), gitlabPerPage, page)
+		if sourceBranch != "" {
+			endpoint += "&source_branch=" + url.QueryEscape(sourceBranch)
+		}
+
+		var mrs []gitlabMergeRequest
+		if err := c.getJSON(endpoint, &mrs); err != nil {
+			return nil, err
+		}
+
+		for _, mr := range mrs {
+			result = append(result, MergeRequest{
+				ProjectID:      mr.ProjectID,
+				IID:            mr.IID,
+				Title:          mr.Title,
+				WebURL:         mr.WebURL,
+				SourceBranch:   mr.SourceBranch,
+				TargetBranch:   mr.TargetBranch,
+				Author:         mr.Author.Username,
+				CreatedAt:      mr.CreatedAt,
+				PipelineStatus: c.pipelineStatus(mr.ProjectID, mr.IID),
+			})
+		}
+
+		if len(mrs) < gitlabPerPage {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// pipelineStatus reads the head pipeline, which the list endpoint does not include
+func (c *GitLabClient) pipelineStatus(projectID, iid int) string {
+	var mr gitlabMergeRequest
+	endpoint := fmt.Sprintf("%s/projects/%d/merge_requests/%d", c.BaseURL, projectID, iid)
+	if err := c.getJSON(endpoint, &mr); err != nil || mr.HeadPipeline == nil {
+		return ""
+	}
+	return mr.HeadPipeline.Status
+}
+
+func (c *GitLabClient) getJSON(endpoint string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	if c.Token != "" {
+		req.Header.Set("PRIVATE-TOKEN", c.Token)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Message interface{} `json:"message"` // string or object with field errors
+			Error   string      `json:"error"`
+		}
+		json.Unmarshal(body, &apiErr)
+		msg := apiErr.Error
+		if apiErr.Message != nil {
+			msg = fmt.Sprint(apiErr.Message)
+		}
+		if msg == "" {
+			msg = resp.Status
+		}
+		return fmt.Errorf("GitLab API error (%d): %s", resp.StatusCode, msg)
+	}
+
+	return json.Unmarshal(body, v)
+}
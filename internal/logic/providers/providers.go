@@ -0,0 +1,152 @@
+// Package providers talks to remote Git hosting services (GitHub, GitLab, ...) so repositories
+// can be discovered and cloned before running housekeeping on them.
+package providers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gorecode/updates/internal/cmdlimit"
+)
+
+// RemoteRepo is a repository as reported by a hosting provider
+type RemoteRepo struct {
+	Name          string   `json:"name"`
+	FullName      string   `json:"fullName"`
+	Description   string   `json:"description,omitempty"`
+	CloneURL      string   `json:"cloneUrl"`
+	SSHURL        string   `json:"sshUrl"`
+	WebURL        string   `json:"webUrl"`
+	DefaultBranch string   `json:"defaultBranch"`
+	Language      string   `json:"language,omitempty"`
+	Topics        []string `json:"topics,omitempty"`
+	Archived      bool     `json:"archived"`
+	Private       bool     `json:"private"`
+	Cloned        bool     `json:"cloned"` // true if the repo already exists below the root path
+}
+
+// RepoFilter narrows down the repos returned by a provider
+type RepoFilter struct {
+	Topic           string `json:"topic"`
+	Language        string `json:"language"`
+	IncludeArchived bool   `json:"includeArchived"`
+}
+
+// FilterRepos returns the repos matching the filter (topic and language are case-insensitive)
+func FilterRepos(repos []RemoteRepo, filter RepoFilter) []RemoteRepo {
+	var result []RemoteRepo
+	for _, repo := range repos {
+		if repo.Archived && !filter.IncludeArchived {
+			continue
+		}
+		if filter.Language != "" && !strings.EqualFold(repo.Language, filter.Language) {
+			continue
+		}
+		if filter.Topic != "" {
+			found := false
+			for _, t := range repo.Topics {
+				if strings.EqualFold(t, filter.Topic) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				continue
+			}
+		}
+		result = append(result, repo)
+	}
+	return result
+}
+
+// MarkCloned sets Cloned for every repo whose directory already exists below rootPath
+func MarkCloned(repos []RemoteRepo, rootPath string) {
+	for i := range repos {
+		if info, err := os.Stat(filepath.Join(rootPath, repos[i].Name, ".git")); err == nil && info.IsDir() {
+			repos[i].Cloned = true
+		}
+	}
+}
+
+// CloneOptions configures CloneMissing
+type CloneOptions struct {
+	RootPath string
+	Username string // Username for HTTPS basic auth (e.g. "x-access-token" for GitHub, "oauth2" for GitLab)
+	Token    string // Access token; passed as HTTP header so it never ends up in .git/config
+	UseSSH   bool   // Clone via SSH URL instead of HTTPS
+	Update   bool   // Fetch and fast-forward repos that are already cloned
+	Log      func(string)
+}
+
+// CloneMissing clones every repo that does not exist below RootPath yet and, with Update set,
+// fast-forwards the ones that do. Returns the number of newly cloned repos.
+func CloneMissing(repos []RemoteRepo, opts CloneOptions) int {
+	log := opts.Log
+	if log == nil {
+		log = func(msg string) { slog.Info(strings.TrimSpace(msg)) }
+	}
+
+	cloned := 0
+	for _, repo := range repos {
+		// Names come from the remote API; never let one like "../x" escape the root
+		if !filepath.IsLocal(repo.Name) {
+			log(fmt.Sprintf("  [ERROR] Invalid repository name '%s', skipping.", repo.Name))
+			continue
+		}
+		target := filepath.Join(opts.RootPath, repo.Name)
+		if _, err := os.Stat(target); err == nil {
+			if !opts.Update {
+				log(fmt.Sprintf("  %s already exists, skipping.", repo.Name))
+				continue
+			}
+			if output, err := runAuthGit(target, opts, "pull", "--ff-only", "--prune"); err != nil {
+				log(fmt.Sprintf("  [WARNING] Update %s failed: %v\n%s", repo.Name, err, strings.TrimSpace(string(output))))
+			} else {
+				log(fmt.Sprintf("  ✓ %s updated", repo.Name))
+			}
+			continue
+		}
+
+		url := repo.CloneURL
+		if opts.UseSSH && repo.SSHURL != "" {
+			url = repo.SSHURL
+		}
+
+		if output, err := runAuthGit("", opts, "clone", url, target); err != nil {
+			log(fmt.Sprintf("  [ERROR] Clone %s failed: %v\n%s", repo.Name, err, strings.TrimSpace(string(output))))
+			continue
+		}
+		log(fmt.Sprintf("  ✓ %s cloned", repo.Name))
+		cloned++
+	}
+	return cloned
+}
+
+// runAuthGit runs git with the token as HTTP header (HTTPS only) and prompts disabled. The header is
+// passed in the environment, so the token does not show up in the process list.
+func runAuthGit(dir string, opts CloneOptions, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if opts.Token != "" && !opts.UseSSH {
+		auth := base64.StdEncoding.EncodeToString([]byte(opts.Username + ":" + opts.Token))
+		cmd.Env = append(cmd.Env, gitConfigEnv(os.Getenv, "http.extraHeader", "Authorization: Basic "+auth)...)
+	}
+	return cmdlimit.CombinedOutput(cmd)
+}
+
+// gitConfigEnv returns the GIT_CONFIG_* variables adding a setting after the ones of the environment
+func gitConfigEnv(getenv func(string) string, key, value string) []string {
+	count, _ := strconv.Atoi(getenv("GIT_CONFIG_COUNT"))
+	return []string{
+		fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", count, key),
+		fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", count, value),
+		fmt.Sprintf("GIT_CONFIG_COUNT=%d", count+1),
+	}
+}
@@ -0,0 +1,191 @@
+package logic
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gorecode/updates/internal/logic/registry"
+)
+
+// PythonSettings controls the Python dependency bumps of a run
+type PythonSettings struct {
+	Update        bool     `json:"update"`        // Bump all dependencies
+	Packages      []string `json:"packages"`      // Targeted bumps, "name" (latest) or "name==version"
+	VerifyCommand string   `json:"verifyCommand"` // Optional, e.g. "pytest -q"; run after dependencies changed
+}
+
+func (s PythonSettings) enabled() bool {
+	return s.Update || len(s.Packages) > 0
+}
+
+// requirementPinPattern matches "name==1.2.3" lines, keeping extras, markers and comments
+var requirementPinPattern = regexp.MustCompile(`^(\s*)([A-Za-z0-9_.\-]+)(\[[^\]]*\])?(\s*==\s*)([^\s;#]+)(.*)$`)
+
+// splitPythonPackage splits "name==version" (or "name@version") into name and version
+func splitPythonPackage(spec string) (string, string) {
+	if name, version, ok := strings.Cut(spec, "=="); ok {
+		return strings.TrimSpace(name), strings.TrimSpace(version)
+	}
+	if name, version, ok := strings.Cut(spec, "@"); ok {
+		return strings.TrimSpace(name), strings.TrimSpace(version)
+	}
+	return strings.TrimSpace(spec), ""
+}
+
+// normalizePythonName compares package names the way pip does (case and -/_/. insensitive)
+func normalizePythonName(name string) string {
+	return strings.NewReplacer("_", "-", ".", "-").Replace(strings.ToLower(name))
+}
+
+// bumpRequirementPins rewrites the "==" pins of a requirements.txt. targets maps normalized
+// package names to the wanted version ("" = latest stable); with all set, every pin is bumped.
+// latest resolves the newest version of a package.
+func bumpRequirementPins(content string, targets map[string]string, all bool, latest func(string) (string, error), log func(string)) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		m := requirementPinPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		name, current := m[2], m[5]
+		target, wanted := targets[normalizePythonName(name)]
+		if !wanted && !all {
+			continue
+		}
+		if target == "" {
+			v, err := latest(name)
+			if err != nil {
+				log(fmt.Sprintf("  [WARNING] Could not determine the latest version of %s: %v", name, err))
+				continue
+			}
+			target = v
+		}
+		if target == current || (!wanted && registry.CompareVersions(target, current) <= 0) {
+			continue
+		}
+		lines[i] = m[1] + name + m[3] + m[4] + target + m[6]
+		log(fmt.Sprintf("  %s %s -> %s", name, current, target))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// pythonDependencyFiles returns the files whose change means the dependencies were bumped
+func pythonDependencyFiles(repoPath string) []byte {
+	var all []byte
+	for _, name := range []string{"requirements.txt", "pyproject.toml", "poetry.lock"} {
+		data, _ := os.ReadFile(filepath.Join(repoPath, name))
+		all = append(all, data...)
+	}
+	return all
+}
+
+// processPythonProject bumps the dependencies with the tool the project uses:
+// poetry (poetry.lock), pip-compile (requirements.in) or pin rewriting of a plain requirements.txt.
+// Returns false if a step failed.
+func processPythonProject(repoPath string, settings PythonSettings, forceVerify bool, log func(string)) bool {
+	before := pythonDependencyFiles(repoPath)
+
+	run := func(description, name string, args ...string) bool {
+		log(fmt.Sprintf("  %s...", description))
+		if output, err := runTool(repoPath, name, args...); err != nil {
+			log(fmt.Sprintf("  [ERROR] %s failed: %v\nOutput:\n%s", description, err, output))
+			return false
+		}
+		return true
+	}
+
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(repoPath, name))
+		return err == nil
+	}
+
+	if settings.enabled() {
+		switch {
+		case exists("poetry.lock"):
+			if settings.Update && !run("Updating dependencies (poetry update)", "poetry", "update", "--no-interaction") {
+				return false
+			}
+			for _, spec := range settings.Packages {
+				name, version := splitPythonPackage(spec)
+				if version == "" {
+					version = "latest"
+				}
+				if !run(fmt.Sprintf("poetry add %s@%s", name, version), "poetry", "add", "--no-interaction", name+"@"+version) {
+					return false
+				}
+			}
+		case exists("requirements.in"):
+			args := []string{"--quiet", "--output-file", "requirements.txt"}
+			if settings.Update {
+				args = append(args, "--upgrade")
+			}
+			for _, spec := range settings.Packages {
+				name, version := splitPythonPackage(spec)
+				if version != "" {
+					name += "==" + version
+				}
+				args = append(args, "--upgrade-package", name)
+			}
+			if !run("Recompiling requirements.txt (pip-compile)", "pip-compile", append(args, "requirements.in")...) {
+				return false
+			}
+		case exists("requirements.txt"):
+			path := filepath.Join(repoPath, "requirements.txt")
+			data, err := os.ReadFile(path)
+			if err != nil {
+				log(fmt.Sprintf("  [ERROR] Could not read requirements.txt: %v", err))
+				return false
+			}
+			targets := make(map[string]string)
+			for _, spec := range settings.Packages {
+				name, version := splitPythonPackage(spec)
+				targets[normalizePythonName(name)] = version
+			}
+			latest := func(name string) (string, error) {
+				releases, err := registry.Fetch("python", name)
+				if err != nil {
+					return "", err
+				}
+				return releases.Latest, nil
+			}
+			log("  Bumping pinned versions in requirements.txt...")
+			updated := bumpRequirementPins(string(data), targets, settings.Update, latest, log)
+			if updated != string(data) {
+				if err := os.WriteFile(path, []byte(updated), 0644); err != nil {
+					log(fmt.Sprintf("  [ERROR] Could not write requirements.txt: %v", err))
+					return false
+				}
+			}
+		default:
+			log("  [WARNING] No poetry.lock, requirements.in or requirements.txt found, Python dependencies not bumped.")
+		}
+	}
+
+	changed := !bytes.Equal(before, pythonDependencyFiles(repoPath))
+	if changed {
+		log("  Python dependencies updated.")
+	} else if settings.enabled() {
+		log("  Python dependencies already up to date.")
+	}
+
+	return verifyEcosystem(repoPath, "Python", settings.VerifyCommand, changed || forceVerify, log)
+}
+
+// verifyEcosystem runs the opt-in verification command of an ecosystem
+func verifyEcosystem(repoPath, ecosystem, command string, needed bool, log func(string)) bool {
+	command = strings.TrimSpace(command)
+	if command == "" || !needed {
+		return true
+	}
+	log(fmt.Sprintf("  Verifying %s project (%s)...", ecosystem, command))
+	if output, err := runShellCommand(repoPath, command); err != nil {
+		log(fmt.Sprintf("  [ERROR] Verification failed: %v\nOutput:\n%s", err, output))
+		return false
+	}
+	log("  Verification successful.")
+	return true
+}
@@ -0,0 +1,101 @@
+package logic
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gorecode/updates/internal/logic/registry"
+)
+
+// quarkusBOM is the platform BOM whose releases define the Quarkus streams
+const quarkusBOM = "io.quarkus.platform:quarkus-bom"
+
+var (
+	quarkusBOMPattern = regexp.MustCompile(`<artifactId>\s*quarkus-(?:universe-)?bom\s*</artifactId>`)
+	// "Update: io.quarkus.platform:quarkus-bom:pom:3.2.9.Final -> 3.8.1", "Add: io.quarkus:quarkus-rest", ...
+	quarkusChangePattern = regexp.MustCompile(`^\s*(?:\[INFO\]\s*)?((?:Update|Add|Remove|Drop):\s+\S.*)$`)
+)
+
+// IsQuarkusProject reports whether the root pom.xml imports the Quarkus platform BOM
+func IsQuarkusProject(repoPath string) bool {
+	data, err := os.ReadFile(filepath.Join(repoPath, "pom.xml"))
+	return err == nil && quarkusBOMPattern.Match(data)
+}
+
+// QuarkusStreams returns the released Quarkus streams (major.minor), newest first
+func QuarkusStreams() ([]string, error) {
+	releases, err := registry.Fetch("maven", quarkusBOM)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	streams := []string{}
+	for _, v := range releases.Versions {
+		parts := strings.SplitN(v, ".", 3)
+		if len(parts) < 2 || seen[parts[0]+"."+parts[1]] {
+			continue
+		}
+		seen[parts[0]+"."+parts[1]] = true
+		streams = append(streams, parts[0]+"."+parts[1])
+	}
+	sort.Slice(streams, func(i, j int) bool { return registry.CompareVersions(streams[i], streams[j]) > 0 })
+	return streams, nil
+}
+
+// QuarkusPlatformVersion returns the newest platform release of a stream ("3.15"), or the newest overall if
+// stream is empty. The update goal has to run with this plugin version to know the recipes of the stream.
+func QuarkusPlatformVersion(stream string) (string, error) {
+	releases, err := registry.Fetch("maven", quarkusBOM)
+	if err != nil {
+		return "", err
+	}
+	if stream == "" {
+		return releases.Latest, nil
+	}
+	latest := ""
+	for _, v := range releases.Versions {
+		if (v == stream || strings.HasPrefix(v, stream+".")) && (latest == "" || registry.CompareVersions(v, latest) > 0) {
+			latest = v
+		}
+	}
+	return latest, nil
+}
+
+// QuarkusUpdateArgs builds the Maven arguments of a `quarkus update` dry run. pluginVersion "" uses the
+// plugin version of the project; the proposed changes end up in target/rewrite/rewrite.patch.
+func QuarkusUpdateArgs(pluginVersion, stream string) []string {
+	plugin := "io.quarkus.platform:quarkus-maven-plugin:update"
+	if pluginVersion != "" {
+		plugin = "io.quarkus.platform:quarkus-maven-plugin:" + pluginVersion + ":update"
+	}
+	// The update goal covers all modules from the root; both spellings of the dry-run flag are
+	// passed, the plugin ignores the one it does not know
+	args := []string{"-N", plugin, "-Dquarkus.update.dryRun=true", "-DrewriteDryRun=true"}
+	if stream != "" {
+		args = append(args, "-Dstream="+stream)
+	}
+	return args
+}
+
+// ParseQuarkusUpdateOutput extracts the recommended platform and extension changes ("Update: ...",
+// "Add: ...", "Remove: ...") from the output of the update goal
+func ParseQuarkusUpdateOutput(output string) []string {
+	changes := []string{}
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if m := quarkusChangePattern.FindStringSubmatch(scanner.Text()); m != nil {
+			change := strings.TrimSpace(m[1])
+			if !seen[change] {
+				seen[change] = true
+				changes = append(changes, change)
+			}
+		}
+	}
+	return changes
+}
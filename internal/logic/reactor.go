@@ -0,0 +1,173 @@
+package logic
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// reactorPom is the part of a pom.xml needed to walk a multi-module build
+type reactorPom struct {
+	ArtifactId string   `xml:"artifactId"`
+	Parent     Parent   `xml:"parent"`
+	Modules    []string `xml:"modules>module"`
+	Profiles   []struct {
+		Modules []string `xml:"modules>module"`
+	} `xml:"profiles>profile"`
+}
+
+// ReactorModule is a child module of a multi-module Maven build
+type ReactorModule struct {
+	PomPath    string // Relative to the repo, e.g. "service/api/pom.xml"
+	ArtifactId string
+}
+
+func readReactorPom(path string) (*reactorPom, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var pom reactorPom
+	if err := xml.Unmarshal(data, &pom); err != nil {
+		return nil, err
+	}
+	return &pom, nil
+}
+
+// DiscoverMavenModules returns the child modules declared via <modules> (including profile
+// modules), recursively. The root pom itself is not part of the result.
+func DiscoverMavenModules(repoPath string) []ReactorModule {
+	var result []ReactorModule
+	visited := map[string]bool{"pom.xml": true}
+
+	var walk func(pomRel string)
+	walk = func(pomRel string) {
+		pom, err := readReactorPom(filepath.Join(repoPath, pomRel))
+		if err != nil {
+			return
+		}
+		modules := pom.Modules
+		for _, profile := range pom.Profiles {
+			modules = append(modules, profile.Modules...)
+		}
+		for _, module := range modules {
+			module = strings.TrimSpace(module)
+			if module == "" {
+				continue
+			}
+			// A module is a directory with a pom.xml or a path to a pom file
+			childRel := filepath.Join(filepath.Dir(pomRel), filepath.FromSlash(module))
+			if !strings.HasSuffix(childRel, ".xml") {
+				childRel = filepath.Join(childRel, "pom.xml")
+			}
+			childRel = filepath.Clean(childRel)
+			if visited[childRel] || strings.HasPrefix(childRel, "..") {
+				continue
+			}
+			visited[childRel] = true
+
+			child, err := readReactorPom(filepath.Join(repoPath, childRel))
+			if err != nil {
+				continue
+			}
+			result = append(result, ReactorModule{PomPath: childRel, ArtifactId: child.ArtifactId})
+			walk(childRel)
+		}
+	}
+	walk("pom.xml")
+	return result
+}
+
+// pomStructurePattern matches the blocks whose <version> tags are not the project version
+var pomStructurePattern = regexp.MustCompile(`(?s)<parent>.*?</parent>|<dependencies>.*?</dependencies>|<dependencyManagement>.*?</dependencyManagement>|<build>.*?</build>|<profiles>.*?</profiles>`)
+
+// findProjectVersion returns the submatch indices of the project's own <version> tag, or nil
+// if the pom inherits its version from the parent
+func findProjectVersion(content string) []int {
+	excludedRanges := pomStructurePattern.FindAllStringIndex(content, -1)
+	reVersion := regexp.MustCompile(`<version>(.*?)</version>`)
+	for _, match := range reVersion.FindAllStringSubmatchIndex(content, -1) {
+		isExcluded := false
+		for _, rng := range excludedRanges {
+			if match[0] >= rng[0] && match[1] <= rng[1] {
+				isExcluded = true
+				break
+			}
+		}
+		if !isExcluded {
+			return match
+		}
+	}
+	return nil
+}
+
+// updateModulePoms carries a project version bump and the pom replacements over to the
+// child modules, so a multi-module build is not left half-updated. Parent references to
+// a reactor artifact and explicit module versions equal to oldVersion are set to newVersion.
+// Returns the relative paths of the poms that were written.
+func updateModulePoms(repoPath string, modules []ReactorModule, rootArtifactId, oldVersion, newVersion string, replacements []Replacement, log func(string)) []string {
+	reactorArtifacts := map[string]bool{rootArtifactId: true}
+	for _, m := range modules {
+		reactorArtifacts[m.ArtifactId] = true
+	}
+
+	parentPattern := regexp.MustCompile(`(?s)<parent>.*?</parent>`)
+	parentVersionPattern := regexp.MustCompile(`<version>\s*(.*?)\s*</version>`)
+	parentArtifactPattern := regexp.MustCompile(`<artifactId>\s*(.*?)\s*</artifactId>`)
+
+	var changed []string
+	for _, module := range modules {
+		path := filepath.Join(repoPath, module.PomPath)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log(fmt.Sprintf("  [ERROR] Could not read %s: %v", module.PomPath, err))
+			continue
+		}
+		content := string(data)
+		original := content
+
+		if newVersion != "" {
+			if loc := parentPattern.FindStringIndex(content); loc != nil {
+				block := content[loc[0]:loc[1]]
+				artifact := parentArtifactPattern.FindStringSubmatch(block)
+				version := parentVersionPattern.FindStringSubmatchIndex(block)
+				if artifact != nil && reactorArtifacts[artifact[1]] && version != nil && block[version[2]:version[3]] == oldVersion {
+					block = block[:version[2]] + newVersion + block[version[3]:]
+					content = content[:loc[0]] + block + content[loc[1]:]
+				}
+			}
+			if match := findProjectVersion(content); match != nil && content[match[2]:match[3]] == oldVersion {
+				content = content[:match[2]] + newVersion + content[match[3]:]
+			}
+		}
+
+		for _, r := range replacements {
+			if r.Search == "" || !r.AppliesTo(module.PomPath) {
+				continue
+			}
+			if newContent, ok := applyPomReplacement(content, r, module.PomPath, log); ok {
+				content = newContent
+				if r.Type != ReplacementTypeProperty {
+					log(fmt.Sprintf("  [INFO] Custom replacement performed in %s: '%s' -> '%s'", module.PomPath, r.Search, r.Replace))
+				}
+			}
+		}
+
+		if content == original {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			log(fmt.Sprintf("  [ERROR] Could not write %s: %v", module.PomPath, err))
+			continue
+		}
+		changed = append(changed, module.PomPath)
+	}
+
+	if len(changed) > 0 {
+		log(fmt.Sprintf("  [INFO] %d module poms updated.", len(changed)))
+	}
+	return changed
+}
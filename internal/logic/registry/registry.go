@@ -0,0 +1,581 @@
+// Package registry resolves the released versions of packages from Maven Central, npm, the Go module
+// proxy, PyPI and Packagist. Results are kept in a memory and disk cache and requests to each host are
+// rate limited, so scanning many repositories does not hammer the registries.
+package registry
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Releases is the release history of a package as reported by its registry
+type Releases struct {
+	Latest    string               `json:"latest"`    // Newest stable version
+	Versions  []string             `json:"versions"`  // All stable versions (any order)
+	Released  map[string]time.Time `json:"released"`  // Release date per version (may be incomplete)
+	FetchedAt time.Time            `json:"fetchedAt"` // When the metadata was loaded from the registry
+}
+
+// MavenMetadata is the maven-metadata.xml of an artifact
+type MavenMetadata struct {
+	Latest    string    `json:"latest"`
+	Release   string    `json:"release"`
+	Versions  []string  `json:"versions"` // All versions including milestones, oldest first
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+// Endpoints are the base URLs of the registries
+type Endpoints struct {
+	MavenSearch     string // Maven Central search API (release dates); "" resolves versions from maven-metadata.xml
+	MavenRepository string // Maven repository layout (maven-metadata.xml, POMs), e.g. a Nexus or Artifactory group
+	NPM             string
+	GoProxy         string
+	PyPI            string
+	Packagist       string
+}
+
+// DefaultEndpoints are the public registries
+var DefaultEndpoints = Endpoints{
+	MavenSearch:     "https://search.maven.org/solrsearch/select",
+	MavenRepository: "https://repo1.maven.org/maven2",
+	NPM:             "https://registry.npmjs.org",
+	GoProxy:         "https://proxy.golang.org",
+	PyPI:            "https://pypi.org/pypi",
+	Packagist:       "https://repo.packagist.org/p2",
+}
+
+// Defaults of a new Client
+const (
+	DefaultTTL      = 24 * time.Hour         // How long metadata is reused before it is fetched again
+	DefaultInterval = 100 * time.Millisecond // Minimum time between two requests to the same host
+)
+
+// ErrOffline is returned for lookups that are not cached while the client is offline
+var ErrOffline = errors.New("offline mode, no cached data available")
+
+// Client looks up package versions. The zero value is not usable, create clients with New.
+type Client struct {
+	Endpoints Endpoints
+	HTTP      *http.Client
+	TTL       time.Duration
+	// Offline sends no requests: cached data is used regardless of its age, everything else fails with ErrOffline
+	Offline bool
+	// MavenUsername and MavenPassword are sent as basic auth to Endpoints.MavenRepository (repository managers)
+	MavenUsername string
+	MavenPassword string
+	// CacheDir returns the directory of the disk cache; nil or an error keeps the cache in memory only
+	CacheDir func() (string, error)
+
+	limiter *rateLimiter
+	mu      sync.Mutex
+	mem     map[string]cacheEntry
+}
+
+// cacheEntry is a cached result as JSON, so callers always get their own copy
+type cacheEntry struct {
+	data      []byte
+	fetchedAt time.Time
+}
+
+// New creates a client for the public registries with an in-memory cache
+func New() *Client {
+	return &Client{
+		Endpoints: DefaultEndpoints,
+		HTTP:      &http.Client{Timeout: 15 * time.Second},
+		TTL:       DefaultTTL,
+		limiter:   &rateLimiter{interval: DefaultInterval, next: make(map[string]time.Time)},
+		mem:       make(map[string]cacheEntry),
+	}
+}
+
+// Default is the client used by the package-level functions
+var Default = New()
+
+// Fetch returns the release history of a package using the Default client.
+// ecosystem is one of "maven" (name "group:artifact"), "npm", "go", "python" or "php".
+func Fetch(ecosystem, name string) (*Releases, error) { return Default.Fetch(ecosystem, name) }
+
+// Latest returns the newest stable version of a package using the Default client
+func Latest(ecosystem, name string) (string, error) { return Default.Latest(ecosystem, name) }
+
+// Metadata returns the maven-metadata.xml of an artifact using the Default client
+func Metadata(group, artifact string) (*MavenMetadata, error) {
+	return Default.Metadata(group, artifact)
+}
+
+// GoVersionTime returns the release time of a Go module version using the Default client
+func GoVersionTime(module, version string) (time.Time, error) {
+	return Default.GoVersionTime(module, version)
+}
+
+// POM returns the POM of a released artifact using the Default client
+func POM(group, artifact, version string) ([]byte, error) {
+	return Default.POM(group, artifact, version)
+}
+
+// GetJSON loads a JSON document with the HTTP client and rate limit of the Default client
+func GetJSON(endpoint string, v interface{}) error { return Default.GetJSON(endpoint, v) }
+
+// Fetch returns the release history of a package, using the memory and disk cache
+func (c *Client) Fetch(ecosystem, name string) (*Releases, error) {
+	key := ecosystem + ":" + c.endpointOf(ecosystem) + ":" + name
+	var cached Releases
+	if c.cached(key, &cached) {
+		return &cached, nil
+	}
+
+	var releases *Releases
+	var err error
+	switch ecosystem {
+	case "maven":
+		releases, err = c.fetchMaven(name)
+	case "npm":
+		releases, err = c.fetchNpm(name)
+	case "go":
+		releases, err = c.fetchGo(name)
+	case "python":
+		releases, err = c.fetchPyPI(name)
+	case "php":
+		releases, err = c.fetchPackagist(name)
+	default:
+		return nil, fmt.Errorf("unsupported ecosystem '%s'", ecosystem)
+	}
+	if err != nil {
+		return nil, err
+	}
+	releases.FetchedAt = time.Now()
+	c.store(key, releases, releases.FetchedAt)
+	return releases, nil
+}
+
+// endpointOf returns the registry URLs an ecosystem is fetched from. They are part of the cache keys,
+// so switching to another registry (e.g. a repository manager) does not serve what the old one returned.
+func (c *Client) endpointOf(ecosystem string) string {
+	switch ecosystem {
+	case "maven":
+		return c.Endpoints.MavenSearch + "|" + c.Endpoints.MavenRepository
+	case "npm":
+		return c.Endpoints.NPM
+	case "go":
+		return c.Endpoints.GoProxy
+	case "python":
+		return c.Endpoints.PyPI
+	case "php":
+		return c.Endpoints.Packagist
+	}
+	return ""
+}
+
+// Latest returns the newest stable version of a package
+func (c *Client) Latest(ecosystem, name string) (string, error) {
+	releases, err := c.Fetch(ecosystem, name)
+	if err != nil {
+		return "", err
+	}
+	if releases.Latest == "" {
+		return "", fmt.Errorf("no release of %s found", name)
+	}
+	return releases.Latest, nil
+}
+
+// Metadata returns the maven-metadata.xml of an artifact from the Maven repository, using the cache
+func (c *Client) Metadata(group, artifact string) (*MavenMetadata, error) {
+	key := "maven-metadata:" + c.Endpoints.MavenRepository + ":" + group + ":" + artifact
+	var cached MavenMetadata
+	if c.cached(key, &cached) {
+		return &cached, nil
+	}
+
+	body, err := c.get(fmt.Sprintf("%s/%s/%s/maven-metadata.xml", c.Endpoints.MavenRepository, strings.ReplaceAll(group, ".", "/"), artifact))
+	if err != nil {
+		return nil, err
+	}
+	var raw struct {
+		Versioning struct {
+			Latest   string   `xml:"latest"`
+			Release  string   `xml:"release"`
+			Versions []string `xml:"versions>version"`
+		} `xml:"versioning"`
+	}
+	if err := xml.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+	metadata := &MavenMetadata{Latest: raw.Versioning.Latest, Release: raw.Versioning.Release, Versions: raw.Versioning.Versions, FetchedAt: time.Now()}
+	c.store(key, metadata, metadata.FetchedAt)
+	return metadata, nil
+}
+
+// POM returns the POM of a released artifact. Released POMs never change; callers cache what they derive.
+func (c *Client) POM(group, artifact, version string) ([]byte, error) {
+	return c.get(fmt.Sprintf("%s/%s/%s/%s/%s-%s.pom", c.Endpoints.MavenRepository, strings.ReplaceAll(group, ".", "/"), artifact, version, artifact, version))
+}
+
+// GetJSON loads a JSON document with the client's HTTP client and rate limit
+func (c *Client) GetJSON(endpoint string, v interface{}) error {
+	body, err := c.get(endpoint)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// get loads a document, waiting for the rate limit of its host
+func (c *Client) get(endpoint string) ([]byte, error) {
+	if c.Offline {
+		return nil, ErrOffline
+	}
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.MavenUsername != "" && c.Endpoints.MavenRepository != "" && strings.HasPrefix(endpoint, c.Endpoints.MavenRepository+"/") {
+		req.SetBasicAuth(c.MavenUsername, c.MavenPassword)
+	}
+	c.limiter.wait(req.URL.Host)
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("registry returned %s for %s", resp.Status, endpoint)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// cached reads a fresh cache entry (any entry when offline) into v, from memory or disk
+func (c *Client) cached(key string, v interface{}) bool {
+	c.mu.Lock()
+	entry, ok := c.mem[key]
+	c.mu.Unlock()
+	if !ok {
+		path := c.cachePath(key)
+		if path == "" {
+			return false
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return false
+		}
+		var fetched struct {
+			FetchedAt time.Time `json:"fetchedAt"`
+		}
+		if json.Unmarshal(data, &fetched) != nil {
+			return false
+		}
+		entry = cacheEntry{data: data, fetchedAt: fetched.FetchedAt}
+		c.mu.Lock()
+		c.mem[key] = entry
+		c.mu.Unlock()
+	}
+	return (c.Offline || time.Since(entry.fetchedAt) < c.TTL) && json.Unmarshal(entry.data, v) == nil
+}
+
+// store keeps a result in memory and on disk (written via a temp file)
+func (c *Client) store(key string, v interface{}, fetchedAt time.Time) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	c.mem[key] = cacheEntry{data: data, fetchedAt: fetchedAt}
+	c.mu.Unlock()
+
+	if path := c.cachePath(key); path != "" {
+		if err := os.WriteFile(path+".tmp", data, 0644); err == nil {
+			os.Rename(path+".tmp", path)
+		}
+	}
+}
+
+// cachePath returns the cache file of a key, "" without a disk cache
+func (c *Client) cachePath(key string) string {
+	if c.CacheDir == nil {
+		return ""
+	}
+	dir, err := c.CacheDir()
+	if err != nil {
+		return ""
+	}
+	sum := sha1.Sum([]byte(key))
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// rateLimiter spaces the requests to each host by a minimum interval
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     map[string]time.Time // Earliest time of the next request per host
+}
+
+// wait blocks until a request to host may be sent
+func (l *rateLimiter) wait(host string) {
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next[host]
+	if at.Before(now) {
+		at = now
+	}
+	l.next[host] = at.Add(l.interval)
+	l.mu.Unlock()
+	time.Sleep(time.Until(at))
+}
+
+// preReleasePattern matches versions that should not count as a release (1.0.0-rc1, 2.0.0.M3, 3.0-SNAPSHOT, ...)
+var preReleasePattern = regexp.MustCompile(`(?i)(^|[.\-+_0-9])(alpha|beta|rc|cr|snapshot|preview|pre|dev|canary|next|nightly|ea|m|a|b)([.\-_]?[0-9]+)?($|[.\-+_])`)
+
+// IsStable reports whether v looks like a final release
+func IsStable(v string) bool {
+	return v != "" && !preReleasePattern.MatchString(v)
+}
+
+// newReleases adds the stable versions and picks the newest as latest
+func newReleases(released map[string]time.Time) *Releases {
+	releases := &Releases{Released: make(map[string]time.Time)}
+	for v, t := range released {
+		if !IsStable(v) {
+			continue
+		}
+		releases.Versions = append(releases.Versions, v)
+		releases.Released[v] = t
+		if releases.Latest == "" || CompareVersions(v, releases.Latest) > 0 {
+			releases.Latest = v
+		}
+	}
+	return releases
+}
+
+func (c *Client) fetchMaven(coordinates string) (*Releases, error) {
+	parts := strings.SplitN(coordinates, ":", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid Maven coordinates '%s'", coordinates)
+	}
+	if c.Endpoints.MavenSearch == "" {
+		return c.fetchMavenMetadata(parts[0], parts[1])
+	}
+	query := url.Values{}
+	query.Set("q", fmt.Sprintf(`g:"%s" AND a:"%s"`, parts[0], parts[1]))
+	query.Set("core", "gav")
+	query.Set("rows", "200")
+	query.Set("wt", "json")
+
+	var result struct {
+		Response struct {
+			Docs []struct {
+				Version   string `json:"v"`
+				Timestamp int64  `json:"timestamp"`
+			} `json:"docs"`
+		} `json:"response"`
+	}
+	if err := c.GetJSON(c.Endpoints.MavenSearch+"?"+query.Encode(), &result); err != nil {
+		return nil, err
+	}
+
+	released := make(map[string]time.Time)
+	for _, doc := range result.Response.Docs {
+		released[doc.Version] = time.UnixMilli(doc.Timestamp)
+	}
+	return newReleases(released), nil
+}
+
+// fetchMavenMetadata derives the releases from maven-metadata.xml, for repositories without the search API.
+// The metadata has no release dates, so Released stays empty.
+func (c *Client) fetchMavenMetadata(group, artifact string) (*Releases, error) {
+	metadata, err := c.Metadata(group, artifact)
+	if err != nil {
+		return nil, err
+	}
+	releases := &Releases{Released: make(map[string]time.Time)}
+	for _, v := range metadata.Versions {
+		if !IsStable(v) {
+			continue
+		}
+		releases.Versions = append(releases.Versions, v)
+		if releases.Latest == "" || CompareVersions(v, releases.Latest) > 0 {
+			releases.Latest = v
+		}
+	}
+	return releases, nil
+}
+
+func (c *Client) fetchNpm(name string) (*Releases, error) {
+	var result struct {
+		DistTags struct {
+			Latest string `json:"latest"`
+		} `json:"dist-tags"`
+		Time map[string]string `json:"time"`
+	}
+	if err := c.GetJSON(c.Endpoints.NPM+"/"+strings.Replace(name, "/", "%2f", 1), &result); err != nil {
+		return nil, err
+	}
+
+	released := make(map[string]time.Time)
+	for v, ts := range result.Time {
+		if v == "created" || v == "modified" {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339, ts); err == nil {
+			released[v] = t
+		}
+	}
+	releases := newReleases(released)
+	if result.DistTags.Latest != "" {
+		releases.Latest = result.DistTags.Latest
+	}
+	return releases, nil
+}
+
+// escapeGoModulePath applies the module proxy case encoding ("Azure" -> "!azure")
+func escapeGoModulePath(path string) string {
+	var sb strings.Builder
+	for _, r := range path {
+		if r >= 'A' && r <= 'Z' {
+			sb.WriteRune('!')
+			sb.WriteRune(r + ('a' - 'A'))
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+func (c *Client) fetchGo(module string) (*Releases, error) {
+	base := c.Endpoints.GoProxy + "/" + escapeGoModulePath(module) + "/@v/"
+	body, err := c.get(base + "list")
+	if err != nil {
+		return nil, err
+	}
+
+	releases := &Releases{Released: make(map[string]time.Time)}
+	for _, v := range strings.Fields(string(body)) {
+		if !IsStable(v) {
+			continue
+		}
+		releases.Versions = append(releases.Versions, v)
+		if releases.Latest == "" || CompareVersions(v, releases.Latest) > 0 {
+			releases.Latest = v
+		}
+	}
+	// The list has no dates; only the latest one is needed to compute the age
+	if releases.Latest != "" {
+		if t, err := c.GoVersionTime(module, releases.Latest); err == nil {
+			releases.Released[releases.Latest] = t
+		}
+	}
+	return releases, nil
+}
+
+// GoVersionTime reads the release time of a module version from the proxy
+func (c *Client) GoVersionTime(module, version string) (time.Time, error) {
+	var info struct {
+		Time time.Time `json:"Time"`
+	}
+	err := c.GetJSON(c.Endpoints.GoProxy+"/"+escapeGoModulePath(module)+"/@v/"+version+".info", &info)
+	return info.Time, err
+}
+
+func (c *Client) fetchPyPI(name string) (*Releases, error) {
+	var result struct {
+		Info struct {
+			Version string `json:"version"`
+		} `json:"info"`
+		Releases map[string][]struct {
+			UploadTime string `json:"upload_time_iso_8601"`
+		} `json:"releases"`
+	}
+	if err := c.GetJSON(c.Endpoints.PyPI+"/"+url.PathEscape(name)+"/json", &result); err != nil {
+		return nil, err
+	}
+
+	released := make(map[string]time.Time)
+	for v, files := range result.Releases {
+		if len(files) == 0 {
+			continue // yanked or empty release
+		}
+		if t, err := time.Parse(time.RFC3339, files[0].UploadTime); err == nil {
+			released[v] = t
+		}
+	}
+	releases := newReleases(released)
+	if IsStable(result.Info.Version) {
+		releases.Latest = result.Info.Version
+	}
+	return releases, nil
+}
+
+func (c *Client) fetchPackagist(name string) (*Releases, error) {
+	var result struct {
+		Packages map[string][]struct {
+			Version string `json:"version"`
+			Time    string `json:"time"`
+		} `json:"packages"`
+	}
+	if err := c.GetJSON(c.Endpoints.Packagist+"/"+name+".json", &result); err != nil {
+		return nil, err
+	}
+
+	released := make(map[string]time.Time)
+	for _, v := range result.Packages[name] {
+		if t, err := time.Parse(time.RFC3339, v.Time); err == nil {
+			released[strings.TrimPrefix(v.Version, "v")] = t
+		}
+	}
+	return newReleases(released), nil
+}
+
+// CompareVersions compares dotted versions numerically segment by segment ("1.10" > "1.9").
+// Returns -1, 0 or 1. Non-numeric suffixes are ignored.
+func CompareVersions(a, b string) int {
+	pa, pb := versionNumbers(a), versionNumbers(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// IsVersion reports whether v starts with a version number (ranges like ">=1.0,<1.2" do not)
+func IsVersion(v string) bool {
+	return len(versionNumbers(v)) > 0
+}
+
+var versionNumberPattern = regexp.MustCompile(`[0-9]+`)
+
+func versionNumbers(v string) []int {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	// Only the leading numeric part counts ("1.2.3-jre" -> 1.2.3)
+	if idx := strings.IndexFunc(v, func(r rune) bool { return r != '.' && (r < '0' || r > '9') }); idx >= 0 {
+		v = v[:idx]
+	}
+	var nums []int
+	for _, s := range versionNumberPattern.FindAllString(v, -1) {
+		n := 0
+		fmt.Sscanf(s, "%d", &n)
+		nums = append(nums, n)
+	}
+	return nums
+}
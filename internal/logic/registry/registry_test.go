@@ -0,0 +1,215 @@
+package registry
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// ===========================================
+// Tests for Registry Lookups
+// ===========================================
+
+func TestIsStableVersion(t *testing.T) {
+	tests := map[string]bool{
+		"1.2.3":            true,
+		"4.3.0.RELEASE":    true,
+		"31.1-jre":         true,
+		"v1.9.0":           true,
+		"1.0.0-rc1":        false,
+		"2.0.0.M3":         false,
+		"3.0-SNAPSHOT":     false,
+		"5.0.0-beta.2":     false,
+		"19.0.0-canary-1a": false,
+		"":                 false,
+	}
+	for v, expected := range tests {
+		if got := IsStable(v); got != expected {
+			t.Errorf("IsStable(%q) = %v, expected %v", v, got, expected)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.10.0", "1.9.9", 1},
+		{"1.2", "1.2.0", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"31.1-jre", "32.0-jre", -1},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.expected {
+			t.Errorf("CompareVersions(%q, %q) = %d, expected %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+func TestFetch_Cache(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/left-pad" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"dist-tags":{"latest":"1.3.0"},"time":{
+			"created":"2016-01-01T00:00:00Z",
+			"1.0.0":"2016-01-01T00:00:00Z",
+			"1.3.0":"2016-03-01T00:00:00Z",
+			"2.0.0-beta.1":"2016-04-01T00:00:00Z"}}`))
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	client := New()
+	client.Endpoints.NPM = server.URL
+	client.CacheDir = func() (string, error) { return cacheDir, nil }
+
+	releases, err := client.Fetch("npm", "left-pad")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if releases.Latest != "1.3.0" || len(releases.Versions) != 2 {
+		t.Errorf("Unexpected releases: %+v", releases)
+	}
+
+	// A new client with the same cache directory is served from disk
+	other := New()
+	other.Endpoints.NPM = server.URL
+	other.CacheDir = client.CacheDir
+	if latest, err := other.Latest("npm", "left-pad"); err != nil || latest != "1.3.0" {
+		t.Fatalf("Cached Latest failed: %q (%v)", latest, err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 registry request, got %d", requests)
+	}
+}
+
+func TestFetch_CachePerEndpoint(t *testing.T) {
+	registry := func(latest string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"dist-tags":{"latest":"` + latest + `"},"time":{"` + latest + `":"2016-03-01T00:00:00Z"}}`))
+		}))
+	}
+	public, mirror := registry("1.3.0"), registry("1.2.0")
+	defer public.Close()
+	defer mirror.Close()
+
+	client := New()
+	client.Endpoints.NPM = public.URL
+	if latest, err := client.Latest("npm", "left-pad"); err != nil || latest != "1.3.0" {
+		t.Fatalf("Latest failed: %q (%v)", latest, err)
+	}
+
+	// Switching the registry does not serve what the previous one returned
+	client.Endpoints.NPM = mirror.URL
+	if latest, err := client.Latest("npm", "left-pad"); err != nil || latest != "1.2.0" {
+		t.Errorf("Expected the release of the new registry, got %q (%v)", latest, err)
+	}
+}
+
+func TestFetch_Offline(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"dist-tags":{"latest":"1.3.0"},"time":{"1.3.0":"2016-03-01T00:00:00Z"}}`))
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	client := New()
+	client.Endpoints.NPM = server.URL
+	client.CacheDir = func() (string, error) { return cacheDir, nil }
+	if _, err := client.Fetch("npm", "left-pad"); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	// Offline, an expired entry is still served and everything else fails without a request
+	offline := New()
+	offline.Endpoints.NPM = server.URL
+	offline.CacheDir = client.CacheDir
+	offline.TTL = time.Nanosecond
+	offline.Offline = true
+	if latest, err := offline.Latest("npm", "left-pad"); err != nil || latest != "1.3.0" {
+		t.Errorf("Expected the cached release, got %q (%v)", latest, err)
+	}
+	if _, err := offline.Fetch("npm", "is-odd"); !errors.Is(err, ErrOffline) {
+		t.Errorf("Expected ErrOffline, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 registry request, got %d", requests)
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/org/springframework/boot/spring-boot-starter-parent/maven-metadata.xml" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`<metadata><versioning><latest>3.5.0</latest><release>3.5.0</release>
+			<versions><version>3.4.6</version><version>3.5.0-RC1</version><version>3.5.0</version></versions></versioning></metadata>`))
+	}))
+	defer server.Close()
+
+	client := New()
+	client.Endpoints.MavenRepository = server.URL
+	metadata, err := client.Metadata("org.springframework.boot", "spring-boot-starter-parent")
+	if err != nil {
+		t.Fatalf("Metadata failed: %v", err)
+	}
+	if metadata.Latest != "3.5.0" || len(metadata.Versions) != 3 || metadata.Versions[1] != "3.5.0-RC1" {
+		t.Errorf("Unexpected metadata: %+v", metadata)
+	}
+}
+
+func TestFetch_MavenRepository(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "ci" || password != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/maven-public/org/example/lib/maven-metadata.xml" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`<metadata><versioning><latest>2.1.0-RC1</latest><release>2.1.0-RC1</release>
+			<versions><version>1.9.0</version><version>2.0.0</version><version>2.1.0-RC1</version></versions></versioning></metadata>`))
+	}))
+	defer server.Close()
+
+	client := New()
+	client.Endpoints.MavenRepository = server.URL + "/maven-public"
+	client.Endpoints.MavenSearch = ""
+	client.MavenUsername, client.MavenPassword = "ci", "token"
+	releases, err := client.Fetch("maven", "org.example:lib")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if releases.Latest != "2.0.0" || len(releases.Versions) != 2 {
+		t.Errorf("Unexpected releases: %+v", releases)
+	}
+
+	client = New()
+	client.Endpoints.MavenRepository = server.URL + "/maven-public"
+	if _, err := client.Metadata("org.example", "lib"); err == nil {
+		t.Error("Expected an error without credentials")
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := &rateLimiter{interval: 20 * time.Millisecond, next: make(map[string]time.Time)}
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		limiter.wait("repo.example.com")
+	}
+	limiter.wait("other.example.com")
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond || elapsed > time.Second {
+		t.Errorf("Expected 3 requests to one host to take about 40ms, took %s", elapsed)
+	}
+}
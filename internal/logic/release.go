@@ -0,0 +1,297 @@
+package logic
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gorecode/updates/internal/logic/registry"
+)
+
+// packageVersionPattern is the "version" field of a package.json or package-lock.json
+var packageVersionPattern = regexp.MustCompile(`("version"\s*:\s*")([^"]*)(")`)
+
+// bumpVersion raises a "major.minor.patch" or "major.minor" version by the strategy (default patch);
+// a patch bump of "1.2" gives "1.2.1". Numeric suffixes like "3-SNAPSHOT" count as their number.
+func bumpVersion(version, strategy string) string {
+	parts := strings.Split(version, ".")
+	if len(parts) < 2 {
+		return ""
+	}
+	var major, minor, patch int
+	fmt.Sscanf(parts[0], "%d", &major)
+	fmt.Sscanf(parts[1], "%d", &minor)
+	if len(parts) == 2 {
+		switch strategy {
+		case "major":
+			return fmt.Sprintf("%d.0", major+1)
+		case "minor":
+			return fmt.Sprintf("%d.%d", major, minor+1)
+		default:
+			return fmt.Sprintf("%d.%d.1", major, minor)
+		}
+	}
+	fmt.Sscanf(parts[2], "%d", &patch)
+	switch strategy {
+	case "major":
+		return fmt.Sprintf("%d.0.0", major+1)
+	case "minor":
+		return fmt.Sprintf("%d.%d.0", major, minor+1)
+	default:
+		return fmt.Sprintf("%d.%d.%d", major, minor, patch+1)
+	}
+}
+
+// ReleaseVersionFile is a file carrying the project version
+type ReleaseVersionFile struct {
+	File    string `json:"file"` // pom.xml or package.json
+	Version string `json:"version"`
+}
+
+// ReleasePlan is the preview of a release: the next version, its tag and the files to update
+type ReleasePlan struct {
+	RepoName       string               `json:"repoName"`
+	RepoPath       string               `json:"repoPath"`
+	Branch         string               `json:"branch"`
+	LatestTag      string               `json:"latestTag,omitempty"` // "" if the repo has no release tag
+	CurrentVersion string               `json:"currentVersion,omitempty"`
+	Version        string               `json:"version,omitempty"` // Version to release
+	Tag            string               `json:"tag,omitempty"`
+	Files          []ReleaseVersionFile `json:"files,omitempty"` // Version files and their current version
+	Commits        int                  `json:"commits"`         // Commits on HEAD since the latest tag
+	Error          string               `json:"error,omitempty"`
+}
+
+// ReleaseOptions control a release
+type ReleaseOptions struct {
+	Tags      TagSettings
+	Strategy  string // "major", "minor" or "patch" (default), used when Version is empty
+	Version   string // Explicit version to release, e.g. "2.0.0"
+	Changelog bool   // Turn the Unreleased section of CHANGELOG.md into the section of the version
+	Push      bool   // Push the branch and the tag to the primary remote
+	Signing   SigningSettings
+}
+
+// releaseVersionFiles reads the project version of the root pom.xml and package.json
+func releaseVersionFiles(repoPath string) []ReleaseVersionFile {
+	var files []ReleaseVersionFile
+	if data, err := os.ReadFile(filepath.Join(repoPath, "pom.xml")); err == nil {
+		if m := findProjectVersion(string(data)); m != nil {
+			files = append(files, ReleaseVersionFile{File: "pom.xml", Version: string(data[m[2]:m[3]])})
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(repoPath, "package.json")); err == nil {
+		var pkg struct {
+			Version string `json:"version"`
+		}
+		if json.Unmarshal(data, &pkg) == nil && pkg.Version != "" {
+			files = append(files, ReleaseVersionFile{File: "package.json", Version: pkg.Version})
+		}
+	}
+	return files
+}
+
+// releaseTagName is the tag of a release version: the tag prefix (default "v") and the version
+func releaseTagName(tags TagSettings, version string) string {
+	if tags.Prefix == "" {
+		return "v" + version
+	}
+	return tags.Prefix + version
+}
+
+// PlanRelease works out the next release of a repo without changing anything. The version is the
+// explicit one, else the project version (without -SNAPSHOT) if it is ahead of the latest tag or
+// there is no tag yet, else the tag version bumped by the strategy.
+func PlanRelease(repoPath string, opts ReleaseOptions) ReleasePlan {
+	plan := ReleasePlan{RepoName: filepath.Base(repoPath), RepoPath: repoPath}
+	plan.Branch = currentBranch(repoPath)
+	if plan.Branch == "HEAD" {
+		plan.Error = "detached HEAD, check out the branch to release"
+		return plan
+	}
+	if tag := getLatestTag(repoPath, opts.Tags); tag != "No Tags" {
+		plan.LatestTag = tag
+	}
+	plan.Files = releaseVersionFiles(repoPath)
+	if len(plan.Files) > 0 {
+		plan.CurrentVersion = plan.Files[0].Version
+	} else if plan.LatestTag != "" {
+		plan.CurrentVersion = TagVersion(plan.LatestTag, opts.Tags.Prefix)
+	}
+
+	tagVersion := TagVersion(plan.LatestTag, opts.Tags.Prefix)
+	current := strings.TrimSuffix(plan.CurrentVersion, "-SNAPSHOT")
+	switch {
+	case strings.TrimSpace(opts.Version) != "":
+		plan.Version = strings.TrimPrefix(strings.TrimSpace(opts.Version), "v")
+	case current != "" && (plan.LatestTag == "" || registry.CompareVersions(current, tagVersion) > 0):
+		plan.Version = current
+	case plan.LatestTag != "":
+		plan.Version = bumpVersion(tagVersion, opts.Strategy)
+	}
+	if plan.Version == "" {
+		plan.Error = "no project version and no release tag found, enter the version to release"
+		return plan
+	}
+	if !releaseVersionPattern.MatchString(plan.Version) {
+		plan.Error = fmt.Sprintf("'%s' is not a release version like 1.2.3", plan.Version)
+		return plan
+	}
+
+	plan.Tag = releaseTagName(opts.Tags, plan.Version)
+	if runGitCommand(repoPath, "show-ref", "--verify", "--quiet", "refs/tags/"+plan.Tag) == nil {
+		plan.Error = fmt.Sprintf("tag '%s' already exists", plan.Tag)
+		return plan
+	}
+	revRange := "HEAD"
+	if plan.LatestTag != "" {
+		revRange = plan.LatestTag + "..HEAD"
+	}
+	if count, err := gitOutput(repoPath, "rev-list", "--count", revRange); err == nil {
+		plan.Commits, _ = strconv.Atoi(count)
+	}
+	return plan
+}
+
+// setReleaseVersion writes the version to pom.xml (with the child modules of a multi-module build),
+// package.json and package-lock.json. It returns the files written.
+func setReleaseVersion(repoPath string, files []ReleaseVersionFile, version string, log func(string)) ([]string, error) {
+	var written []string
+	for _, file := range files {
+		if file.Version == version {
+			continue
+		}
+		path := filepath.Join(repoPath, file.File)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s: %v", file.File, err)
+		}
+		content := string(data)
+		switch file.File {
+		case "pom.xml":
+			m := findProjectVersion(content)
+			if m == nil {
+				continue
+			}
+			content = content[:m[2]] + version + content[m[3]:]
+		case "package.json":
+			content = setPackageVersion(content, file.Version, version, 1)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return nil, fmt.Errorf("could not write %s: %v", file.File, err)
+		}
+		log(fmt.Sprintf("  [INFO] Version in %s updated: %s -> %s", file.File, file.Version, version))
+		written = append(written, file.File)
+
+		switch file.File {
+		case "pom.xml":
+			if modules := DiscoverMavenModules(repoPath); len(modules) > 0 {
+				rootArtifactId := ""
+				if rootPom, err := readReactorPom(path); err == nil {
+					rootArtifactId = rootPom.ArtifactId
+				}
+				written = append(written, updateModulePoms(repoPath, modules, rootArtifactId, file.Version, version, nil, log)...)
+			}
+		case "package.json":
+			// The lock file repeats the version at the top and for the root package ("packages": {"": ...})
+			lockPath := filepath.Join(repoPath, "package-lock.json")
+			if lock, err := os.ReadFile(lockPath); err == nil {
+				if updated := setPackageVersion(string(lock), file.Version, version, 2); updated != string(lock) {
+					if err := os.WriteFile(lockPath, []byte(updated), 0644); err != nil {
+						return nil, fmt.Errorf("could not write package-lock.json: %v", err)
+					}
+					written = append(written, "package-lock.json")
+				}
+			}
+		}
+	}
+	return written, nil
+}
+
+// setPackageVersion replaces the first n "version" fields equal to oldVersion that come before the
+// dependencies, keeping the formatting of the file
+func setPackageVersion(content, oldVersion, newVersion string, n int) string {
+	end := len(content)
+	for _, marker := range []string{`"dependencies"`, `"devDependencies"`, `"node_modules/`} {
+		if i := strings.Index(content, marker); i >= 0 && i < end {
+			end = i
+		}
+	}
+	head := content[:end]
+	replaced := 0
+	head = packageVersionPattern.ReplaceAllStringFunc(head, func(match string) string {
+		m := packageVersionPattern.FindStringSubmatch(match)
+		if replaced >= n || m[2] != oldVersion {
+			return match
+		}
+		replaced++
+		return m[1] + newVersion + m[3]
+	})
+	return head + content[end:]
+}
+
+// Release sets the version in pom.xml and package.json, optionally moves the Unreleased section of
+// CHANGELOG.md to the version, commits "Release X.Y.Z" on the current branch, tags it and pushes
+// branch and tag. It returns the plan that was carried out.
+func Release(repoPath string, opts ReleaseOptions, log func(string)) (ReleasePlan, error) {
+	plan := PlanRelease(repoPath, opts)
+	if plan.Error != "" {
+		return plan, fmt.Errorf("%s", plan.Error)
+	}
+	if err := requireCleanWorktree(repoPath); err != nil {
+		return plan, err
+	}
+
+	files, err := setReleaseVersion(repoPath, plan.Files, plan.Version, log)
+	if err != nil {
+		return plan, err
+	}
+	if opts.Changelog {
+		if changelog := BuildChangelog(repoPath, opts.Tags); changelog.Error != "" {
+			return plan, fmt.Errorf("%s", changelog.Error)
+		} else if changelog.Commits > 0 {
+			changed, err := writeChangelogFile(repoPath, changelog, plan.Version, UnreleasedVersion)
+			if err != nil {
+				return plan, err
+			}
+			if changed {
+				log(fmt.Sprintf("  [INFO] %s: section %s added.", ChangelogFile, plan.Version))
+				files = append(files, ChangelogFile)
+			}
+		}
+	}
+
+	message := "Release " + plan.Version
+	if len(files) > 0 {
+		if err := runGitCommand(repoPath, append([]string{"add", "--"}, files...)...); err != nil {
+			return plan, fmt.Errorf("git add failed: %v", err)
+		}
+		if err := runGitCommand(repoPath, opts.Signing.commitArgs(message)...); err != nil {
+			return plan, fmt.Errorf("git commit failed: %v", err)
+		}
+		log(fmt.Sprintf("  Committed '%s' on %s.", message, plan.Branch))
+	}
+
+	args := []string{"tag", "-a", plan.Tag, "-m", message}
+	if opts.Signing.Enabled {
+		args = append(opts.Signing.configArgs(), "tag", "-s", plan.Tag, "-m", message)
+	}
+	if err := runGitCommand(repoPath, args...); err != nil {
+		return plan, fmt.Errorf("creating tag '%s' failed: %v", plan.Tag, err)
+	}
+	log(fmt.Sprintf("  Tag '%s' created on %s.", plan.Tag, plan.Branch))
+
+	if !opts.Push {
+		return plan, nil
+	}
+	remote := primaryRemoteOrOrigin(repoPath)
+	if err := retryGitCommand(repoPath, log, "push", remote, "HEAD", "refs/tags/"+plan.Tag); err != nil {
+		return plan, fmt.Errorf("pushing %s and tag '%s' failed: %v", plan.Branch, plan.Tag, err)
+	}
+	log(fmt.Sprintf("  %s and tag '%s' pushed to %s.", plan.Branch, plan.Tag, remote))
+	return plan, nil
+}
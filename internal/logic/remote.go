@@ -0,0 +1,77 @@
+package logic
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// RemoteConfigKey is the git config key choosing the remote of a clone, for forks whose own remote
+// is not "origin": git config githousekeeper.remote upstream
+const RemoteConfigKey = "githousekeeper.remote"
+
+// ListRemotes returns the remote names of a repository, sorted
+func ListRemotes(repoPath string) []string {
+	out, err := gitOutput(repoPath, "remote")
+	if err != nil || out == "" {
+		return nil
+	}
+	remotes := strings.Fields(out)
+	slices.Sort(remotes)
+	return remotes
+}
+
+// PrimaryRemote returns the remote GitHousekeeper resolves the default branch on, fetches from and
+// pushes to: the one chosen with githousekeeper.remote, "origin", git's checkout.defaultRemote, the
+// remote of the checked-out branch's upstream or the only remote. "" if there is none to pick.
+func PrimaryRemote(repoPath string) string {
+	remotes := ListRemotes(repoPath)
+	if len(remotes) == 0 {
+		return ""
+	}
+	candidates := []string{}
+	if chosen, _ := gitOutput(repoPath, "config", RemoteConfigKey); chosen != "" {
+		candidates = append(candidates, chosen)
+	}
+	candidates = append(candidates, "origin")
+	if remote, _ := gitOutput(repoPath, "config", "checkout.defaultRemote"); remote != "" {
+		candidates = append(candidates, remote)
+	}
+	if branch, err := gitOutput(repoPath, "symbolic-ref", "-q", "--short", "HEAD"); err == nil {
+		if remote, _ := gitOutput(repoPath, "config", "branch."+branch+".remote"); remote != "" {
+			candidates = append(candidates, remote)
+		}
+	}
+	for _, candidate := range candidates {
+		if slices.Contains(remotes, candidate) {
+			return candidate
+		}
+	}
+	if len(remotes) == 1 {
+		return remotes[0]
+	}
+	return ""
+}
+
+// SetPrimaryRemote stores the remote to use for the clone; "" returns to the automatic choice
+func SetPrimaryRemote(repoPath, remote string) error {
+	if remote == "" {
+		if err := runGitCommand(repoPath, "config", "--unset", RemoteConfigKey); err != nil && !strings.Contains(err.Error(), "exit status 5") {
+			return err
+		}
+		return nil
+	}
+	if !slices.Contains(ListRemotes(repoPath), remote) {
+		return fmt.Errorf("remote '%s' does not exist", remote)
+	}
+	return runGitCommand(repoPath, "config", RemoteConfigKey, remote)
+}
+
+// primaryRemoteOrOrigin is PrimaryRemote with "origin" when there is none, for commands that report
+// the missing remote themselves
+func primaryRemoteOrOrigin(repoPath string) string {
+	if remote := PrimaryRemote(repoPath); remote != "" {
+		return remote
+	}
+	return "origin"
+}
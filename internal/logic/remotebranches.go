@@ -0,0 +1,122 @@
+package logic
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RemoteBranch is a branch on the primary remote with the data needed to judge whether it is stale
+type RemoteBranch struct {
+	Name       string    `json:"name"` // Without the "<remote>/" prefix
+	LastCommit time.Time `json:"lastCommit"`
+	AgeDays    int       `json:"ageDays"`
+	Author     string    `json:"author"`
+	Merged     bool      `json:"merged"`    // Fully merged into the default branch
+	Protected  bool      `json:"protected"` // Default or configured base branch, never deleted
+}
+
+// RemoteBranchReport lists the remote branches of one repo, oldest first
+type RemoteBranchReport struct {
+	RepoPath      string         `json:"repoPath"`
+	RepoName      string         `json:"repoName"`
+	Remote        string         `json:"remote"` // Primary remote the branches are on
+	DefaultBranch string         `json:"defaultBranch"`
+	Branches      []RemoteBranch `json:"branches"`
+	Error         string         `json:"error,omitempty"`
+}
+
+// protectedRemoteBranches returns the branches that must never be deleted on the remote
+func protectedRemoteBranches(repoPath, defaultBranch string) map[string]bool {
+	protected := map[string]bool{defaultBranch: true}
+	if cfg, err := LoadRepoConfig(repoPath); err == nil && cfg.BaseBranch != "" {
+		protected[cfg.BaseBranch] = true
+	}
+	return protected
+}
+
+// AnalyzeRemoteBranches reports age, last author and merged status of every branch on the primary remote.
+// With fetch the remote-tracking refs are refreshed (and pruned) first.
+func AnalyzeRemoteBranches(repoPath string, fetch bool) RemoteBranchReport {
+	report := RemoteBranchReport{RepoPath: repoPath, RepoName: filepath.Base(repoPath), Branches: []RemoteBranch{}}
+	report.Remote = PrimaryRemote(repoPath)
+	if report.Remote == "" {
+		report.Error = "the repository has no remote"
+		return report
+	}
+	prefix := "refs/remotes/" + report.Remote + "/"
+
+	if fetch {
+		if err := runGitCommand(repoPath, "fetch", "--prune", report.Remote); err != nil {
+			report.Error = fmt.Sprintf("fetch failed: %v", err)
+			return report
+		}
+	}
+
+	report.DefaultBranch = getDefaultBranch(repoPath)
+	protected := protectedRemoteBranches(repoPath, report.DefaultBranch)
+
+	output, err := gitOutput(repoPath, "for-each-ref", "--format=%(refname)|%(committerdate:unix)|%(authorname)", prefix)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+
+	merged := make(map[string]bool)
+	if list, err := gitOutput(repoPath, "for-each-ref", "--format=%(refname)", "--merged="+prefix+report.DefaultBranch, prefix); err == nil {
+		for _, ref := range strings.Fields(list) {
+			merged[ref] = true
+		}
+	}
+
+	now := time.Now()
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), "|", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		name := strings.TrimPrefix(parts[0], prefix)
+		if name == "HEAD" {
+			continue
+		}
+		branch := RemoteBranch{
+			Name:      name,
+			Author:    parts[2],
+			Merged:    merged[parts[0]],
+			Protected: protected[name],
+		}
+		if ts, err := strconv.ParseInt(parts[1], 10, 64); err == nil {
+			branch.LastCommit = time.Unix(ts, 0)
+			branch.AgeDays = int(now.Sub(branch.LastCommit).Hours() / 24)
+		}
+		report.Branches = append(report.Branches, branch)
+	}
+
+	sort.SliceStable(report.Branches, func(i, j int) bool {
+		return report.Branches[i].LastCommit.Before(report.Branches[j].LastCommit)
+	})
+	return report
+}
+
+// DeleteRemoteBranch deletes the branch on the primary remote. The default branch and a base branch
+// configured in .githousekeeper.yaml are refused.
+func DeleteRemoteBranch(repoPath, branch string) error {
+	remote := PrimaryRemote(repoPath)
+	if remote == "" {
+		return fmt.Errorf("the repository has no remote")
+	}
+	branch = strings.TrimPrefix(strings.TrimSpace(branch), remote+"/")
+	if branch == "" || branch == "HEAD" || strings.HasPrefix(branch, "-") {
+		return fmt.Errorf("invalid branch name '%s'", branch)
+	}
+	if protectedRemoteBranches(repoPath, getDefaultBranch(repoPath))[branch] {
+		return fmt.Errorf("branch '%s' is protected and will not be deleted", branch)
+	}
+	if err := runGitCommand(repoPath, "show-ref", "--verify", "--quiet", "refs/remotes/"+remote+"/"+branch); err != nil {
+		return fmt.Errorf("branch '%s' does not exist on %s", branch, remote)
+	}
+	return retryGitCommand(repoPath, nil, "push", remote, "--delete", branch)
+}
@@ -0,0 +1,120 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ReplacementTypeRegex treats Search as a Go regular expression; Replace may reference
+// capture groups as $1 or ${name}
+const ReplacementTypeRegex = "regex"
+
+// ReplacementIssue describes a replacement rule that cannot be applied
+type ReplacementIssue struct {
+	Index  int    `json:"index"` // Position of the rule in the request
+	Search string `json:"search"`
+	Error  string `json:"error"`
+}
+
+// ValidateReplacements checks the rules before a run starts, e.g. that regex patterns compile
+func ValidateReplacements(replacements []Replacement) []ReplacementIssue {
+	var issues []ReplacementIssue
+	for i, r := range replacements {
+		if r.Search == "" {
+			continue
+		}
+		switch r.Type {
+		case "", ReplacementTypeProperty:
+		case ReplacementTypeRegex:
+			if _, err := regexp.Compile(r.Search); err != nil {
+				issues = append(issues, ReplacementIssue{Index: i, Search: r.Search, Error: err.Error()})
+			}
+		default:
+			issues = append(issues, ReplacementIssue{Index: i, Search: r.Search, Error: fmt.Sprintf("unknown replacement type '%s'", r.Type)})
+		}
+		for _, pattern := range append(append([]string{}, r.Include...), r.Exclude...) {
+			if _, err := filepath.Match(strings.TrimPrefix(filepath.ToSlash(pattern), "**/"), ""); err != nil {
+				issues = append(issues, ReplacementIssue{Index: i, Search: r.Search, Error: fmt.Sprintf("invalid file pattern '%s': %v", pattern, err)})
+			}
+		}
+	}
+	return issues
+}
+
+// AppliesTo reports whether the rule may change the file (path relative to the repo root).
+// Include and Exclude use the same patterns as the ignore file, see IgnoreMatcher.Matches.
+func (r Replacement) AppliesTo(relPath string) bool {
+	if len(r.Include) > 0 && !newPathMatcher(r.Include).Matches(relPath) {
+		return false
+	}
+	return !newPathMatcher(r.Exclude).Matches(relPath)
+}
+
+// performRegexReplacement replaces all matches of pattern, expanding capture groups in replace
+func performRegexReplacement(content, pattern, replace string) (string, bool) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return content, false
+	}
+	replace = strings.ReplaceAll(replace, "\r\n", "\n")
+	result := re.ReplaceAllString(content, replace)
+	return result, result != content
+}
+
+// applyReplacement applies a text or regex rule to file content.
+// Property rules are pom-specific and handled by applyPomReplacement.
+func applyReplacement(content string, r Replacement) (string, bool) {
+	switch r.Type {
+	case ReplacementTypeRegex:
+		return performRegexReplacement(content, r.Search, r.Replace)
+	case ReplacementTypeProperty:
+		return content, false
+	default:
+		return performFuzzyReplacement(content, r.Search, r.Replace)
+	}
+}
+
+// walkReplaceableFiles calls fn with the content of every text file below root,
+// skipping .git, build output and the excluded folders
+func walkReplaceableFiles(ctx context.Context, root string, excludedFolders []string, log func(string), fn func(path string, info os.FileInfo, content string)) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			for _, ex := range excludedFolders {
+				if info.Name() == ex {
+					return filepath.SkipDir
+				}
+			}
+			if info.Name() == ".git" || info.Name() == "target" || info.Name() == "node_modules" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		contentBytes, err := os.ReadFile(path)
+		if err != nil {
+			log(fmt.Sprintf("    [WARNING] Could not read file %s: %v", path, err))
+			return nil
+		}
+
+		// Binary files contain NUL bytes early on
+		for i := 0; i < len(contentBytes) && i < 1024; i++ {
+			if contentBytes[i] == 0 {
+				return nil
+			}
+		}
+
+		fn(path, info, string(contentBytes))
+		return nil
+	})
+}
@@ -0,0 +1,112 @@
+package logic
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RepoConfigFile is the checked-in file a repository uses to opt out of steps or override run defaults
+const RepoConfigFile = ".githousekeeper.yaml"
+
+// RepoConfig is the content of .githousekeeper.yaml. Every field is optional.
+type RepoConfig struct {
+	Skip                 bool     `yaml:"skip"`                 // Leave the repository out of housekeeping runs
+	BaseBranch           string   `yaml:"baseBranch"`           // Branch to update and branch off from, instead of main/master detection
+	SkipVersionBump      bool     `yaml:"skipVersionBump"`      // Never bump the project version
+	VersionBumpStrategy  string   `yaml:"versionBumpStrategy"`  // "major", "minor" or "patch", overrides the run setting
+	SkipParentUpdate     bool     `yaml:"skipParentUpdate"`     // Keep the <parent> version
+	SkipNodeUpdate       bool     `yaml:"skipNodeUpdate"`       // Keep .nvmrc, engines.node and the node-version of CI
+	SkipReplacements     bool     `yaml:"skipReplacements"`     // Ignore all replacement rules of the run
+	ExcludePaths         []string `yaml:"excludePaths"`         // Path globs no replacement touches, e.g. "src/test/resources"
+	BuildCommand         string   `yaml:"buildCommand"`         // Replaces mvn clean install and the Python/PHP verification, e.g. "make ci"
+	SkipBuild            bool     `yaml:"skipBuild"`            // Never run the Maven build or the Python/PHP verification
+	SkipDeprecationCheck bool     `yaml:"skipDeprecationCheck"` // No separate compile run for the deprecation report
+}
+
+// LoadRepoConfig reads the repository's .githousekeeper.yaml.
+// A missing file is an empty config; unknown keys and invalid values are an error.
+func LoadRepoConfig(repoPath string) (*RepoConfig, error) {
+	cfg := &RepoConfig{}
+	data, err := os.ReadFile(filepath.Join(repoPath, RepoConfigFile))
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %v", RepoConfigFile, err)
+	}
+
+	cfg.BaseBranch = strings.TrimSpace(cfg.BaseBranch)
+	switch cfg.VersionBumpStrategy {
+	case "", "major", "minor", "patch":
+	default:
+		return nil, fmt.Errorf("%s: invalid versionBumpStrategy '%s', expected major, minor or patch", RepoConfigFile, cfg.VersionBumpStrategy)
+	}
+	return cfg, nil
+}
+
+// apply returns the run options adjusted to the repository's config
+func (c *RepoConfig) apply(opts RepoOptions) RepoOptions {
+	if c.VersionBumpStrategy != "" {
+		opts.VersionBumpStrategy = c.VersionBumpStrategy
+	}
+	if c.SkipParentUpdate {
+		opts.TargetParentVersion = ""
+	}
+	if c.SkipNodeUpdate {
+		opts.TargetNodeVersion = ""
+	}
+	if c.SkipReplacements {
+		opts.Replacements = nil
+	} else if len(c.ExcludePaths) > 0 {
+		replacements := make([]Replacement, len(opts.Replacements))
+		for i, r := range opts.Replacements {
+			r.Exclude = append(append([]string{}, r.Exclude...), c.ExcludePaths...)
+			replacements[i] = r
+		}
+		opts.Replacements = replacements
+	}
+	if c.BuildCommand != "" {
+		opts.Python.VerifyCommand = c.BuildCommand
+		opts.Php.VerifyCommand = c.BuildCommand
+	}
+	if c.SkipBuild {
+		opts.RunCleanInstall = false
+		opts.Python.VerifyCommand = ""
+		opts.Php.VerifyCommand = ""
+	}
+	return opts
+}
+
+// describe lists the overrides for the run log
+func (c *RepoConfig) describe() string {
+	var parts []string
+	add := func(enabled bool, text string) {
+		if enabled {
+			parts = append(parts, text)
+		}
+	}
+	add(c.BaseBranch != "", "base branch "+c.BaseBranch)
+	add(c.SkipVersionBump, "no version bump")
+	add(c.VersionBumpStrategy != "", c.VersionBumpStrategy+" version bumps")
+	add(c.SkipParentUpdate, "no parent update")
+	add(c.SkipNodeUpdate, "no Node.js update")
+	add(c.SkipReplacements, "no replacements")
+	add(len(c.ExcludePaths) > 0, "excluded paths "+strings.Join(c.ExcludePaths, ", "))
+	add(c.BuildCommand != "", "build command '"+c.BuildCommand+"'")
+	add(c.SkipBuild, "no build")
+	add(c.SkipDeprecationCheck, "no deprecation check")
+	return strings.Join(parts, "; ")
+}
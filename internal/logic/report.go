@@ -0,0 +1,211 @@
+package logic
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Build outcome of a repo in the run report
+const (
+	BuildSuccess  = "success"
+	BuildFailed   = "failed"
+	BuildSkipped  = "skipped" // skipBuild in .githousekeeper.yaml or verification level none
+	BuildNotRun   = "not-run" // No changes and no build requested
+	BuildTimedOut = "timeout" // Killed after its timeout
+)
+
+func buildStatus(ok bool) string {
+	if ok {
+		return BuildSuccess
+	}
+	return BuildFailed
+}
+
+// buildStatusOf tells a build that timed out from one that failed
+func buildStatusOf(err error) string {
+	var timeout *TimeoutError
+	if errors.As(err, &timeout) {
+		return BuildTimedOut
+	}
+	return buildStatus(err == nil)
+}
+
+// ReportCommit is a commit created by the run
+type ReportCommit struct {
+	SHA     string `json:"sha"`
+	Subject string `json:"subject"`
+}
+
+// VersionBump is the project version before and after the run
+type VersionBump struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// RepoReport is the machine-readable result of one repo of a run
+type RepoReport struct {
+	RepoPath     string         `json:"repoPath"`
+	RepoName     string         `json:"repoName"`
+	Status       string         `json:"status"` // RepoStatusSuccess, RepoStatusFailed or RepoStatusSkipped
+	Branch       string         `json:"branch,omitempty"`
+	Commits      []ReportCommit `json:"commits"`
+	ChangedFiles []string       `json:"changedFiles"`
+	VersionBump  *VersionBump   `json:"versionBump,omitempty"`
+	BuildStatus  string         `json:"buildStatus"`
+	Deprecations int            `json:"deprecations"`
+	Warnings     []string       `json:"deprecationWarnings,omitempty"` // Deprecation warnings of the build (max. 100)
+	// Structured deprecations for /api/deprecations-report; nil in reports of older versions
+	DeprecationDetails []Deprecation `json:"deprecationDetails"`
+	Tests              *TestSummary  `json:"tests,omitempty"`        // Test results of builds with tests
+	BuildFailure       *BuildFailure `json:"buildFailure,omitempty"` // Structured cause of a failed build
+	TimedOut           string        `json:"timedOut,omitempty"`     // Step that exceeded its timeout: git, maven, replacements or repo
+	Tag                string        `json:"tag,omitempty"`
+	Errors             []string      `json:"errors,omitempty"`
+	DurationMs         int64         `json:"durationMs"`
+}
+
+// ReportSummary counts the repos of a run by outcome
+type ReportSummary struct {
+	Total     int `json:"total"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	Skipped   int `json:"skipped"`
+	Commits   int `json:"commits"`
+}
+
+// RunReport is the structured summary of a run, stored next to the run record for downstream tooling
+type RunReport struct {
+	RunID      string        `json:"runId"`
+	Label      string        `json:"label,omitempty"`
+	RootPath   string        `json:"rootPath"`
+	StartedAt  time.Time     `json:"startedAt"`
+	FinishedAt time.Time     `json:"finishedAt"`
+	Summary    ReportSummary `json:"summary"`
+	Repos      []RepoReport  `json:"repos"`
+}
+
+// NewRepoReport collects the result of a processed repo: the commits and changed files
+// between the pre-run state and the work branch, the project version change and the build outcome
+func NewRepoReport(entry ReportEntry, status string, duration time.Duration) RepoReport {
+	report := RepoReport{
+		RepoPath:           entry.RepoPath,
+		RepoName:           filepath.Base(entry.RepoPath),
+		Status:             status,
+		Branch:             entry.Snapshot.WorkBranch,
+		Commits:            []ReportCommit{},
+		ChangedFiles:       []string{},
+		BuildStatus:        entry.BuildStatus,
+		Tag:                entry.Snapshot.CreatedTag,
+		DeprecationDetails: entry.Deprecations,
+		Tests:              entry.Tests,
+		BuildFailure:       entry.BuildFailure,
+		TimedOut:           entry.TimedOut,
+		DurationMs:         duration.Milliseconds(),
+	}
+	if report.BuildStatus == "" {
+		report.BuildStatus = BuildNotRun
+	}
+	if report.DeprecationDetails == nil {
+		report.DeprecationDetails = []Deprecation{}
+	}
+	for _, line := range strings.Split(entry.DeprecationOutput, "\n") {
+		// "--- module ---" lines are headers of multi-module builds
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "--- ") {
+			report.Deprecations++
+			report.Warnings = append(report.Warnings, line)
+		}
+	}
+	for _, msg := range entry.Messages {
+		if strings.Contains(msg, "[ERROR]") {
+			// Build errors carry the complete output, keep the first line
+			first, _, _ := strings.Cut(strings.TrimSpace(msg), "\n")
+			report.Errors = append(report.Errors, first)
+		}
+	}
+
+	base, work := entry.Snapshot.BaseHead, entry.Snapshot.WorkBranch
+	if base == "" || work == "" {
+		return report
+	}
+	if log, err := gitOutput(entry.RepoPath, "log", "--reverse", "--format=%H%x1f%s", base+".."+work); err == nil && log != "" {
+		for _, line := range strings.Split(log, "\n") {
+			sha, subject, _ := strings.Cut(line, "\x1f")
+			report.Commits = append(report.Commits, ReportCommit{SHA: sha, Subject: subject})
+		}
+	}
+	if files, err := gitOutput(entry.RepoPath, "diff", "--name-only", base, work); err == nil && files != "" {
+		report.ChangedFiles = strings.Split(files, "\n")
+	}
+	from, to := pomVersionAt(entry.RepoPath, base), pomVersionAt(entry.RepoPath, work)
+	if from != to && to != "" {
+		report.VersionBump = &VersionBump{From: from, To: to}
+	}
+	return report
+}
+
+// pomVersionAt returns the project version of the root pom.xml at rev ("" without a pom)
+func pomVersionAt(repoPath, rev string) string {
+	content, err := gitOutput(repoPath, "show", rev+":pom.xml")
+	if err != nil {
+		return ""
+	}
+	if match := findProjectVersion(content); match != nil {
+		return content[match[2]:match[3]]
+	}
+	return ""
+}
+
+// Add appends a repo result and updates the summary
+func (r *RunReport) Add(repo RepoReport) {
+	r.Repos = append(r.Repos, repo)
+	r.Summary.Total++
+	r.Summary.Commits += len(repo.Commits)
+	switch repo.Status {
+	case RepoStatusSuccess:
+		r.Summary.Succeeded++
+	case RepoStatusSkipped:
+		r.Summary.Skipped++
+	default:
+		r.Summary.Failed++
+	}
+}
+
+func runReportPath(id string) (string, error) {
+	if !ValidRunID(id) {
+		return "", fmt.Errorf("invalid run ID '%s'", id)
+	}
+	dir, err := dataSubDir("reports")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, id+".json"), nil
+}
+
+// SaveRunReport persists the report of a run in the data directory
+func SaveRunReport(report *RunReport) error {
+	path, err := runReportPath(report.RunID)
+	if err != nil {
+		return err
+	}
+	return writeJSONFile(path, report)
+}
+
+// LoadRunReport loads the report of a run
+func LoadRunReport(id string) (*RunReport, error) {
+	path, err := runReportPath(id)
+	if err != nil {
+		return nil, err
+	}
+	var report RunReport
+	if err := readJSONFile(path, &report); err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("report of run '%s' not found", id)
+		}
+		return nil, err
+	}
+	return &report, nil
+}
@@ -0,0 +1,233 @@
+package logic
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNoPDFConverter is returned when neither wkhtmltopdf nor a Chromium-based browser is installed
+var ErrNoPDFConverter = errors.New("PDF export needs wkhtmltopdf or a Chromium-based browser (chromium, google-chrome, msedge) in PATH")
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"date": func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Format("2006-01-02 15:04")
+	},
+	"duration": func(ms int64) string {
+		return (time.Duration(ms) * time.Millisecond).Round(time.Second).String()
+	},
+	"short": shortSHA,
+	"join":  strings.Join,
+	"buildFailures": func(repos []RepoReport) []RepoReport {
+		var failed []RepoReport
+		for _, repo := range repos {
+			if repo.BuildFailure != nil {
+				failed = append(failed, repo)
+			}
+		}
+		return failed
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Housekeeping Report {{.RunID}}</title>
+<style>
+  body { font-family: -apple-system, "Segoe UI", Roboto, sans-serif; color: #222; margin: 32px; font-size: 14px; }
+  h1 { font-size: 22px; margin-bottom: 4px; }
+  h2 { font-size: 17px; border-bottom: 2px solid #ddd; padding-bottom: 4px; margin-top: 28px; }
+  h3 { font-size: 15px; margin: 20px 0 6px; }
+  table { border-collapse: collapse; width: 100%; margin: 8px 0; }
+  th, td { border: 1px solid #ddd; padding: 5px 8px; text-align: left; vertical-align: top; }
+  th { background: #f4f4f4; }
+  code { font-family: Consolas, monospace; font-size: 12px; }
+  ul { margin: 4px 0; padding-left: 20px; }
+  .meta { color: #666; }
+  .success { color: #1a7f37; font-weight: 600; }
+  .failed, .timeout { color: #cf222e; font-weight: 600; }
+  .skipped, .not-run { color: #777; font-weight: 600; }
+  .repo { page-break-inside: avoid; }
+  .message { white-space: pre-wrap; }
+  .warnings { font-family: Consolas, monospace; font-size: 11px; white-space: pre-wrap; background: #fafafa; border: 1px solid #eee; padding: 6px; }
+</style>
+</head>
+<body>
+<h1>Housekeeping Report{{if .Label}}: {{.Label}}{{end}}</h1>
+<div class="meta">Run {{.RunID}} · {{.RootPath}} · started {{date .StartedAt}} · finished {{date .FinishedAt}}</div>
+
+<h2>Summary</h2>
+<table>
+  <tr><th>Repositories</th><th>Succeeded</th><th>Failed</th><th>Skipped</th><th>Commits</th></tr>
+  <tr><td>{{.Summary.Total}}</td><td class="success">{{.Summary.Succeeded}}</td><td class="failed">{{.Summary.Failed}}</td><td class="skipped">{{.Summary.Skipped}}</td><td>{{.Summary.Commits}}</td></tr>
+</table>
+<table>
+  <tr><th>Repository</th><th>Status</th><th>Branch</th><th>Version</th><th>Build</th><th>Tests</th><th>Commits</th><th>Changed Files</th><th>Deprecations</th><th>Duration</th></tr>
+  {{range .Repos}}<tr>
+    <td>{{.RepoName}}</td>
+    <td class="{{.Status}}">{{.Status}}{{with .TimedOut}} (timeout: {{.}}){{end}}</td>
+    <td>{{.Branch}}</td>
+    <td>{{with .VersionBump}}{{.From}} → {{.To}}{{else}}-{{end}}</td>
+    <td class="{{.BuildStatus}}">{{.BuildStatus}}</td>
+    <td>{{with .Tests}}{{.Tests}} run{{if or .Failures .Errors}}, <span class="failed">{{.Failures}} failed, {{.Errors}} errors</span>{{end}}{{else}}-{{end}}</td>
+    <td>{{len .Commits}}</td>
+    <td>{{len .ChangedFiles}}</td>
+    <td>{{.Deprecations}}</td>
+    <td>{{duration .DurationMs}}</td>
+  </tr>{{end}}
+</table>
+
+{{with buildFailures .Repos}}<h2>Build Failures</h2>
+<table>
+  <tr><th>Repository</th><th>Failed Modules</th><th>Missing Artifacts</th><th>Compilation Errors</th><th>First Cause</th></tr>
+  {{range .}}<tr>
+    <td>{{.RepoName}}</td>
+    {{with .BuildFailure}}<td>{{if .FailedModules}}{{join .FailedModules ", "}}{{else}}-{{end}}</td>
+    <td>{{len .MissingArtifacts}}</td>
+    <td>{{len .CompilationErrors}}</td>
+    <td class="failed">{{index .Lines 0}}</td>{{end}}
+  </tr>{{end}}
+</table>{{end}}
+
+<h2>Details</h2>
+{{range .Repos}}<div class="repo">
+  <h3>{{.RepoName}} <span class="{{.Status}}">({{.Status}})</span></h3>
+  <div class="meta">{{.RepoPath}}{{if .Tag}} · tag {{.Tag}}{{end}}</div>
+  {{if .Errors}}<ul>{{range .Errors}}<li class="failed">{{.}}</li>{{end}}</ul>{{end}}
+  {{if .Commits}}<strong>Commits</strong><ul>{{range .Commits}}<li><code>{{short .SHA}}</code> {{.Subject}}</li>{{end}}</ul>{{end}}
+  {{with .BuildFailure}}<strong>Build failure</strong><ul>
+    {{range .MissingArtifacts}}<li class="failed">Missing artifact <code>{{.}}</code></li>{{end}}
+    {{range .CompilationErrors}}<li class="failed"><code>{{.File}}{{if .Line}}:{{.Line}}{{end}}</code>: <span class="message">{{.Message}}</span></li>{{end}}
+    {{if .FailedModules}}<li>Failed modules: {{join .FailedModules ", "}}</li>{{end}}
+    {{range .FailedGoals}}<li><code>{{.}}</code></li>{{end}}
+  </ul>{{end}}
+  {{with .Tests}}{{if .Failed}}<strong>Failed tests</strong><ul>{{range .Failed}}<li class="failed"><code>{{.Class}}.{{.Name}}</code>{{if .Message}}: {{.Message}}{{end}}</li>{{end}}</ul>{{end}}{{end}}
+  {{if .ChangedFiles}}<strong>Changed files</strong><ul>{{range .ChangedFiles}}<li><code>{{.}}</code></li>{{end}}</ul>{{end}}
+  {{if .Warnings}}<strong>Deprecations</strong><div class="warnings">{{range .Warnings}}{{.}}
+{{end}}</div>{{end}}
+  {{if not (or .Errors .Commits .ChangedFiles .Warnings)}}<div class="meta">No changes.</div>{{end}}
+</div>{{end}}
+</body>
+</html>
+`))
+
+// RenderRunReportHTML writes the report as a standalone HTML page (inline styles, no external assets),
+// suitable for attaching to a change-management ticket
+func RenderRunReportHTML(report *RunReport, w io.Writer) error {
+	return reportTemplate.Execute(w, report)
+}
+
+// RenderRunReportPDF converts the HTML report to PDF with wkhtmltopdf or a headless Chromium-based browser
+func RenderRunReportPDF(report *RunReport) ([]byte, error) {
+	converter, args := pdfConverter()
+	if converter == "" {
+		return nil, ErrNoPDFConverter
+	}
+
+	dir, err := os.MkdirTemp("", "housekeeping-report-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	htmlPath, pdfPath := filepath.Join(dir, "report.html"), filepath.Join(dir, "report.pdf")
+	var buf bytes.Buffer
+	if err := RenderRunReportHTML(report, &buf); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(htmlPath, buf.Bytes(), 0644); err != nil {
+		return nil, err
+	}
+
+	if output, err := runTool(dir, converter, args(htmlPath, pdfPath)...); err != nil {
+		return nil, fmt.Errorf("%s failed: %v\n%s", filepath.Base(converter), err, output)
+	}
+	return os.ReadFile(pdfPath)
+}
+
+// pdfConverter finds an installed HTML-to-PDF converter and the arguments to convert in to out
+func pdfConverter() (string, func(in, out string) []string) {
+	if path, err := exec.LookPath("wkhtmltopdf"); err == nil {
+		return path, func(in, out string) []string {
+			return []string{"--quiet", "--enable-local-file-access", in, out}
+		}
+	}
+	for _, browser := range []string{"chromium", "chromium-browser", "google-chrome", "google-chrome-stable", "chrome", "msedge"} {
+		if path, err := exec.LookPath(browser); err == nil {
+			return path, func(in, out string) []string {
+				url := filepath.ToSlash(in)
+				if !strings.HasPrefix(url, "/") {
+					url = "/" + url // Windows drive paths: file:///C:/...
+				}
+				return []string{"--headless", "--disable-gpu", "--no-pdf-header-footer", "--print-to-pdf=" + out, "file://" + url}
+			}
+		}
+	}
+	return "", nil
+}
+
+// ScanReportRepo is one repo of a security scan summary
+type ScanReportRepo struct {
+	RepoName string
+	Branch   string
+	Type     string // Project type, e.g. maven or npm
+	Critical int
+	High     int
+	Medium   int
+	Low      int
+	Error    string
+}
+
+// ScanReport summarizes a security scan for email delivery
+type ScanReport struct {
+	RootPath  string
+	ScannedAt time.Time
+	Policy    string // "PASS", "FAIL:<reason>" or "" without a policy
+	Repos     []ScanReportRepo
+}
+
+var scanReportTemplate = template.Must(template.New("scan").Funcs(template.FuncMap{
+	"date": func(t time.Time) string { return t.Format("2006-01-02 15:04") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Security Scan Report</title>
+<style>
+  body { font-family: -apple-system, "Segoe UI", Roboto, sans-serif; color: #222; margin: 32px; font-size: 14px; }
+  table { border-collapse: collapse; width: 100%; margin: 8px 0; }
+  th, td { border: 1px solid #ddd; padding: 5px 8px; text-align: left; }
+  th { background: #f4f4f4; }
+  .meta { color: #666; }
+  .critical { color: #cf222e; font-weight: 600; }
+  .high { color: #bc4c00; font-weight: 600; }
+</style>
+</head>
+<body>
+<h1>Security Scan Report</h1>
+<div class="meta">{{.RootPath}} · {{date .ScannedAt}}{{if .Policy}} · policy {{.Policy}}{{end}}</div>
+<table>
+  <tr><th>Repository</th><th>Branch</th><th>Type</th><th>Critical</th><th>High</th><th>Medium</th><th>Low</th><th>Error</th></tr>
+  {{range .Repos}}<tr>
+    <td>{{.RepoName}}</td><td>{{.Branch}}</td><td>{{.Type}}</td>
+    <td class="critical">{{.Critical}}</td><td class="high">{{.High}}</td><td>{{.Medium}}</td><td>{{.Low}}</td>
+    <td>{{.Error}}</td>
+  </tr>{{end}}
+</table>
+</body>
+</html>
+`))
+
+// RenderScanReportHTML writes the scan summary as a standalone HTML page
+func RenderScanReportHTML(report *ScanReport, w io.Writer) error {
+	return scanReportTemplate.Execute(w, report)
+}
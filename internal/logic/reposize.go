@@ -0,0 +1,216 @@
+package logic
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorecode/updates/internal/cmdlimit"
+)
+
+const (
+	// largeBlobBytes is the size from which a file belongs into Git LFS
+	largeBlobBytes = 5 << 20
+	// largeGitDirBytes is the .git size from which a history cleanup is recommended regardless of single blobs
+	largeGitDirBytes = 1 << 30
+	// defaultLargestBlobs is the number of blobs listed per repo
+	defaultLargestBlobs = 10
+)
+
+// LargeBlob is a file version stored in the history of a repo
+type LargeBlob struct {
+	Path   string `json:"path"`
+	Hash   string `json:"hash"`
+	Size   int64  `json:"size"`
+	InHead bool   `json:"inHead"` // Part of the current HEAD tree (false = only in history)
+}
+
+// RepoSizeReport describes the disk usage of a repo and what would make it smaller
+type RepoSizeReport struct {
+	RepoName        string      `json:"repoName"`
+	RepoPath        string      `json:"repoPath"`
+	WorkTreeBytes   int64       `json:"workTreeBytes"`
+	GitDirBytes     int64       `json:"gitDirBytes"`
+	LargestBlobs    []LargeBlob `json:"largestBlobs"`
+	LFSCandidate    bool        `json:"lfsCandidate"`   // Large files at HEAD that should move to Git LFS
+	HistoryCleanup  bool        `json:"historyCleanup"` // Large blobs only in history, or a very large .git
+	Recommendations []string    `json:"recommendations,omitempty"`
+	Error           string      `json:"error,omitempty"`
+}
+
+// AnalyzeRepoSize measures the working tree and .git and lists the top largest blobs of the whole history
+func AnalyzeRepoSize(repoPath string, top int) RepoSizeReport {
+	if top <= 0 {
+		top = defaultLargestBlobs
+	}
+	report := RepoSizeReport{RepoName: filepath.Base(repoPath), RepoPath: repoPath, LargestBlobs: []LargeBlob{}}
+
+	gitDir, err := gitOutput(repoPath, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		report.Error = fmt.Sprintf("not a git repository: %v", err)
+		return report
+	}
+	report.WorkTreeBytes = dirSize(repoPath, filepath.Join(repoPath, ".git"))
+	report.GitDirBytes = dirSize(gitDir, "")
+
+	blobs, err := largestBlobs(repoPath, top)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+	if len(blobs) > 0 {
+		head := headBlobs(repoPath)
+		for i := range blobs {
+			blobs[i].InHead = head[blobs[i].Hash]
+		}
+		report.LargestBlobs = blobs
+	}
+
+	var lfsFiles int
+	var historyBytes int64
+	for _, blob := range report.LargestBlobs {
+		if blob.Size < largeBlobBytes {
+			continue
+		}
+		if blob.InHead {
+			lfsFiles++
+		} else {
+			historyBytes += blob.Size
+		}
+	}
+	if lfsFiles > 0 {
+		report.LFSCandidate = true
+		report.Recommendations = append(report.Recommendations,
+			fmt.Sprintf("Move %d large file(s) at HEAD to Git LFS", lfsFiles))
+	}
+	if historyBytes > 0 {
+		report.HistoryCleanup = true
+		report.Recommendations = append(report.Recommendations,
+			fmt.Sprintf("Remove large blobs that only exist in history (about %d MiB, e.g. with git filter-repo)", historyBytes>>20))
+	} else if report.GitDirBytes >= largeGitDirBytes {
+		report.HistoryCleanup = true
+		report.Recommendations = append(report.Recommendations,
+			fmt.Sprintf(".git uses %d MiB, consider a history cleanup or a shallow clone", report.GitDirBytes>>20))
+	}
+	return report
+}
+
+// dirSize sums the sizes of all files below root, skipping the directory skip
+func dirSize(root, skip string) int64 {
+	var total int64
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if skip != "" && path == skip {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.Mode().IsRegular() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+// largestBlobs streams all objects of the history (git rev-list --objects --all) through
+// git cat-file --batch-check and keeps the top largest blobs
+func largestBlobs(repoPath string, top int) ([]LargeBlob, error) {
+	release := cmdlimit.AcquireClass(cmdlimit.ClassGit, "git rev-list --objects --all | git cat-file --batch-check")
+	defer release()
+
+	revList := exec.Command("git", "rev-list", "--objects", "--all")
+	revList.Dir = repoPath
+	catFile := exec.Command("git", "cat-file", "--batch-check=%(objecttype) %(objectname) %(objectsize) %(rest)")
+	catFile.Dir = repoPath
+
+	objects, err := revList.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	catFile.Stdin = objects
+	sizes, err := catFile.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := revList.Start(); err != nil {
+		return nil, fmt.Errorf("git rev-list failed: %v", err)
+	}
+	if err := catFile.Start(); err != nil {
+		revList.Process.Kill()
+		revList.Wait()
+		return nil, fmt.Errorf("git cat-file failed: %v", err)
+	}
+
+	blobs, parseErr := collectLargestBlobs(sizes, top)
+	io.Copy(io.Discard, sizes)
+	catErr := catFile.Wait()
+	revErr := revList.Wait()
+	switch {
+	case parseErr != nil:
+		return nil, parseErr
+	case revErr != nil:
+		return nil, fmt.Errorf("git rev-list failed: %v", revErr)
+	case catErr != nil:
+		return nil, fmt.Errorf("git cat-file failed: %v", catErr)
+	}
+	return blobs, nil
+}
+
+// collectLargestBlobs reads "<type> <hash> <size> <path>" lines and returns the top largest blobs, largest first
+func collectLargestBlobs(r io.Reader, top int) ([]LargeBlob, error) {
+	var blobs []LargeBlob
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), " ", 4)
+		if len(fields) < 3 || fields[0] != "blob" {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		if len(blobs) == top && size <= blobs[top-1].Size {
+			continue
+		}
+		blob := LargeBlob{Hash: fields[1], Size: size}
+		if len(fields) == 4 {
+			blob.Path = fields[3]
+		}
+		i := sort.Search(len(blobs), func(i int) bool { return blobs[i].Size < size })
+		blobs = append(blobs, LargeBlob{})
+		copy(blobs[i+1:], blobs[i:])
+		blobs[i] = blob
+		if len(blobs) > top {
+			blobs = blobs[:top]
+		}
+	}
+	return blobs, scanner.Err()
+}
+
+// headBlobs returns the blob hashes of the HEAD tree
+func headBlobs(repoPath string) map[string]bool {
+	result := make(map[string]bool)
+	out, err := gitOutput(repoPath, "ls-tree", "-r", "HEAD")
+	if err != nil {
+		return result
+	}
+	for _, line := range strings.Split(out, "\n") {
+		// "<mode> blob <hash>\t<path>"
+		meta, _, _ := strings.Cut(line, "\t")
+		if fields := strings.Fields(meta); len(fields) == 3 && fields[1] == "blob" {
+			result[fields[2]] = true
+		}
+	}
+	return result
+}
@@ -0,0 +1,162 @@
+package logic
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Operations a repository can be in the middle of, see RepoStatus.Operation
+const (
+	OperationMerge      = "merge"
+	OperationRebase     = "rebase"
+	OperationApply      = "am" // git am (rebase-apply/applying)
+	OperationCherryPick = "cherry-pick"
+	OperationRevert     = "revert"
+	OperationBisect     = "bisect"
+)
+
+// RepoStatus is the `git status` of a repository at a glance
+type RepoStatus struct {
+	Name       string   `json:"name"`
+	Path       string   `json:"path"`
+	Branch     string   `json:"branch"` // Empty with a detached HEAD
+	Detached   bool     `json:"detached,omitempty"`
+	Head       string   `json:"head,omitempty"` // Short SHA, empty before the first commit
+	Dirty      bool     `json:"dirty"`
+	Staged     int      `json:"staged"`     // Files with staged changes
+	Modified   int      `json:"modified"`   // Files with unstaged changes
+	Untracked  int      `json:"untracked"`  // Untracked files (not ignored)
+	Conflicted int      `json:"conflicted"` // Unmerged files
+	Upstream   string   `json:"upstream,omitempty"`
+	Remote     string   `json:"remote,omitempty"` // Primary remote, see PrimaryRemote
+	Remotes    []string `json:"remotes,omitempty"`
+	Ahead      int      `json:"ahead"`
+	Behind     int      `json:"behind"`
+	Stashes    int      `json:"stashes"`
+	Operation  string   `json:"operation,omitempty"` // Operation in progress: merge, rebase, am, cherry-pick, revert, bisect
+	Error      string   `json:"error,omitempty"`
+}
+
+// InspectRepoStatus returns the branch, working tree, upstream, stash and operation state of a repository
+func InspectRepoStatus(path string) RepoStatus {
+	status := RepoStatus{Name: filepath.Base(path), Path: path}
+	output, err := gitOutput(path, "status", "--porcelain=v2", "--branch")
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	parseStatusV2(output, &status)
+	status.Remote, status.Remotes = PrimaryRemote(path), ListRemotes(path)
+
+	if stashes, err := gitOutput(path, "stash", "list"); err == nil && stashes != "" {
+		status.Stashes = strings.Count(stashes, "\n") + 1
+	}
+	if gitDir, err := gitOutput(path, "rev-parse", "--absolute-git-dir"); err == nil {
+		status.Operation = operationInProgress(gitDir)
+	}
+	return status
+}
+
+// InspectRepoStatuses inspects the repositories in parallel (bounded by the git process limit),
+// in the order given
+func InspectRepoStatuses(repos []string) []RepoStatus {
+	statuses := make([]RepoStatus, len(repos))
+	forEachRepo(repos, func(i int, repo string) {
+		statuses[i] = InspectRepoStatus(repo)
+	})
+	return statuses
+}
+
+// forEachRepo calls fn for every repository in parallel; the git commands in fn are bounded by the
+// process limit
+func forEachRepo(repos []string, fn func(i int, repo string)) {
+	var wg sync.WaitGroup
+	for i, repo := range repos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn(i, repo)
+		}()
+	}
+	wg.Wait()
+}
+
+// parseStatusV2 reads the header and entry lines of `git status --porcelain=v2 --branch`
+func parseStatusV2(output string, status *RepoStatus) {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "#":
+			if len(fields) < 3 {
+				continue
+			}
+			switch fields[1] {
+			case "branch.oid":
+				if fields[2] != "(initial)" {
+					status.Head = shortSHA(fields[2])
+				}
+			case "branch.head":
+				if fields[2] == "(detached)" {
+					status.Detached = true
+				} else {
+					status.Branch = fields[2]
+				}
+			case "branch.upstream":
+				status.Upstream = fields[2]
+			case "branch.ab":
+				if len(fields) == 4 {
+					status.Ahead, _ = strconv.Atoi(strings.TrimPrefix(fields[2], "+"))
+					status.Behind, _ = strconv.Atoi(strings.TrimPrefix(fields[3], "-"))
+				}
+			}
+		case "1", "2":
+			// "1 XY ...": X is the index (staged), Y the working tree state, "." = unchanged
+			if len(fields) > 1 && len(fields[1]) == 2 {
+				if fields[1][0] != '.' {
+					status.Staged++
+				}
+				if fields[1][1] != '.' {
+					status.Modified++
+				}
+			}
+		case "u":
+			status.Conflicted++
+		case "?":
+			status.Untracked++
+		}
+	}
+	status.Dirty = status.Staged+status.Modified+status.Untracked+status.Conflicted > 0
+}
+
+// operationInProgress recognizes an interrupted merge, rebase, am, cherry-pick, revert or bisect by
+// the files git leaves in the git directory
+func operationInProgress(gitDir string) string {
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(gitDir, name))
+		return err == nil
+	}
+	switch {
+	case exists("rebase-merge"):
+		return OperationRebase
+	case exists("rebase-apply"):
+		if exists(filepath.Join("rebase-apply", "applying")) {
+			return OperationApply
+		}
+		return OperationRebase
+	case exists("MERGE_HEAD"):
+		return OperationMerge
+	case exists("CHERRY_PICK_HEAD"):
+		return OperationCherryPick
+	case exists("REVERT_HEAD"):
+		return OperationRevert
+	case exists("BISECT_LOG"):
+		return OperationBisect
+	}
+	return ""
+}
@@ -0,0 +1,240 @@
+package logic
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Kinds of RepoEvent
+const (
+	RepoAdded   = "repo-added"
+	RepoRemoved = "repo-removed"
+	RepoChanged = "repo-changed" // A build file (pom.xml, package.json) was written
+)
+
+// RepoEvent is a change below the roots of a RepoWatcher
+type RepoEvent struct {
+	Type string `json:"type"`
+	Repo string `json:"repo"`
+	File string `json:"file,omitempty"` // Changed build file, relative to the repo
+}
+
+// watchedBuildFiles are the build files whose changes are reported as RepoChanged
+var watchedBuildFiles = map[string]bool{"pom.xml": true, "package.json": true}
+
+// repoWatchDebounce collects the events of a clone, checkout or editor save into one batch
+const repoWatchDebounce = 500 * time.Millisecond
+
+// RepoWatcher reports repositories appearing and disappearing below its roots and changes to their
+// build files. Folders outside of repositories are watched for new and removed repositories; of a
+// repository only its root and the module folders with a build file are watched.
+type RepoWatcher struct {
+	roots    []string
+	excluded []string
+	watcher  *fsnotify.Watcher
+	events   chan []RepoEvent
+	done     chan struct{}
+	once     sync.Once
+
+	// Owned by the run goroutine after WatchRepos
+	repos      map[string]bool
+	containers map[string]bool // Folders outside of repositories
+	watched    map[string]bool
+}
+
+// WatchRepos starts watching the repositories below roots, searched like FindGitRepos
+func WatchRepos(roots []string, excluded []string) (*RepoWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w := &RepoWatcher{
+		excluded: excluded,
+		watcher:  watcher,
+		events:   make(chan []RepoEvent, 16),
+		done:     make(chan struct{}),
+		watched:  make(map[string]bool),
+	}
+	for _, root := range roots {
+		if strings.TrimSpace(root) != "" {
+			w.roots = append(w.roots, longPath(root))
+		}
+	}
+	w.repos, w.containers = w.scan()
+	w.syncWatches()
+	go w.run()
+	return w, nil
+}
+
+// Events delivers the changes in batches; it is closed by Close
+func (w *RepoWatcher) Events() <-chan []RepoEvent {
+	return w.events
+}
+
+// Close stops watching
+func (w *RepoWatcher) Close() error {
+	var err error
+	w.once.Do(func() {
+		close(w.done)
+		err = w.watcher.Close()
+	})
+	return err
+}
+
+// scan searches the roots for repositories and the folders outside of them
+func (w *RepoWatcher) scan() (repos, containers map[string]bool) {
+	discoveryMu.Lock()
+	depth := discoveryDepth
+	discoveryMu.Unlock()
+
+	repos, containers = make(map[string]bool), make(map[string]bool)
+	for _, root := range w.roots {
+		found, dirs, _ := walkForRepos(root, w.excluded, depth)
+		for _, repo := range found {
+			repos[repo] = true
+		}
+		for _, dir := range dirs {
+			containers[dir] = true
+		}
+	}
+	return repos, containers
+}
+
+// syncWatches watches the containers, the repositories and their module folders with a build file
+func (w *RepoWatcher) syncWatches() {
+	wanted := make(map[string]bool, len(w.containers)+len(w.repos))
+	for dir := range w.containers {
+		wanted[dir] = true
+	}
+	for repo := range w.repos {
+		wanted[repo] = true
+		entries, _ := os.ReadDir(repo)
+		for _, e := range entries {
+			if !e.IsDir() || e.Name() == ".git" || skippedDirs[e.Name()] {
+				continue
+			}
+			module := filepath.Join(repo, e.Name())
+			for file := range watchedBuildFiles {
+				if _, err := os.Stat(filepath.Join(module, file)); err == nil {
+					wanted[module] = true
+				}
+			}
+		}
+	}
+	for dir := range w.watched {
+		if !wanted[dir] {
+			w.watcher.Remove(dir)
+			delete(w.watched, dir)
+		}
+	}
+	for dir := range wanted {
+		if !w.watched[dir] && w.watcher.Add(dir) == nil {
+			w.watched[dir] = true
+		}
+	}
+}
+
+// repoOf returns the repository containing path, "" if there is none
+func (w *RepoWatcher) repoOf(path string) string {
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if w.repos[dir] {
+			return dir
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return ""
+		}
+	}
+}
+
+func (w *RepoWatcher) run() {
+	defer close(w.events)
+	timer := time.NewTimer(repoWatchDebounce)
+	timer.Stop()
+	rescan := false
+	changed := make(map[string]string) // Repo -> changed build file
+
+	for {
+		select {
+		case <-w.done:
+			timer.Stop()
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			dir := filepath.Dir(event.Name)
+			switch {
+			case w.containers[dir] || w.containers[event.Name]:
+				// A repository or folder appeared or disappeared
+				rescan = rescan || !event.Has(fsnotify.Write)
+			case watchedBuildFiles[filepath.Base(event.Name)]:
+				if repo := w.repoOf(dir); repo != "" {
+					rel, _ := filepath.Rel(repo, event.Name)
+					changed[repo] = filepath.ToSlash(rel)
+				}
+			case w.repos[dir] && event.Has(fsnotify.Create) && filepath.Base(event.Name) != ".git" && !skippedDirs[filepath.Base(event.Name)]:
+				// New module folder: watch it for its build file
+				rescan = true
+			default:
+				continue
+			}
+			timer.Reset(repoWatchDebounce)
+		case <-w.watcher.Errors:
+			// Events may have been lost: search again
+			rescan = true
+			timer.Reset(repoWatchDebounce)
+		case <-timer.C:
+			var batch []RepoEvent
+			if rescan {
+				repos, containers := w.scan()
+				for _, repo := range sortedKeys(repos) {
+					if !w.repos[repo] {
+						batch = append(batch, RepoEvent{Type: RepoAdded, Repo: repo})
+						delete(changed, repo)
+					}
+				}
+				for _, repo := range sortedKeys(w.repos) {
+					if !repos[repo] {
+						batch = append(batch, RepoEvent{Type: RepoRemoved, Repo: repo})
+						delete(changed, repo)
+					}
+				}
+				w.repos, w.containers = repos, containers
+				w.syncWatches()
+				rescan = false
+			}
+			for _, repo := range sortedKeys(changed) {
+				if w.repos[repo] {
+					batch = append(batch, RepoEvent{Type: RepoChanged, Repo: repo, File: changed[repo]})
+				}
+			}
+			changed = make(map[string]string)
+			if len(batch) == 0 {
+				continue
+			}
+			select {
+			case w.events <- batch:
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
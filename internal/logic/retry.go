@@ -0,0 +1,171 @@
+package logic
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Command classes with their own retry policy
+const (
+	RetryGit      = "git"      // Network git commands: fetch, pull, push
+	RetryMaven    = "maven"    // Maven builds and analyses
+	RetryScanners = "scanners" // Security scanners: npm/yarn/pnpm audit, trivy, govulncheck, pip-audit, composer audit
+)
+
+const retryFile = "retry.json"
+
+// maxRetryDelay caps the doubling delay between attempts
+const maxRetryDelay = time.Minute
+
+// RetryPolicy says how often a failed command is attempted and how long to wait in between
+type RetryPolicy struct {
+	Attempts int `json:"attempts"` // Total attempts, 1 = no retry
+	DelayMs  int `json:"delayMs"`  // Wait before the first retry, doubled for every further one (max. 1 minute)
+}
+
+// RetrySettings are the retry policies per command class, stored in retry.json in the data directory
+type RetrySettings struct {
+	Git      RetryPolicy `json:"git"`
+	Maven    RetryPolicy `json:"maven"`
+	Scanners RetryPolicy `json:"scanners"`
+}
+
+// DefaultRetrySettings retry network git commands twice and Maven and scanners once
+func DefaultRetrySettings() RetrySettings {
+	return RetrySettings{
+		Git:      RetryPolicy{Attempts: 3, DelayMs: 2000},
+		Maven:    RetryPolicy{Attempts: 2, DelayMs: 500},
+		Scanners: RetryPolicy{Attempts: 2, DelayMs: 2000},
+	}
+}
+
+func (s RetrySettings) policy(class string) RetryPolicy {
+	switch class {
+	case RetryGit:
+		return s.Git
+	case RetryMaven:
+		return s.Maven
+	case RetryScanners:
+		return s.Scanners
+	}
+	return RetryPolicy{Attempts: 1}
+}
+
+var (
+	retryMu sync.Mutex
+	// retrySettings are the policies in effect, set by ApplyRetrySettings
+	retrySettings = DefaultRetrySettings()
+)
+
+func retryPath() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, retryFile), nil
+}
+
+// LoadRetrySettings returns the stored retry policies, the defaults if none are stored
+func LoadRetrySettings() (RetrySettings, error) {
+	settings := DefaultRetrySettings()
+	path, err := retryPath()
+	if err != nil {
+		return settings, err
+	}
+	if err := readJSONFile(path, &settings); err != nil && !os.IsNotExist(err) {
+		return settings, err
+	}
+	return settings, nil
+}
+
+// SaveRetrySettings validates, stores and applies the retry policies
+func SaveRetrySettings(settings RetrySettings) (RetrySettings, error) {
+	for class, policy := range map[string]RetryPolicy{RetryGit: settings.Git, RetryMaven: settings.Maven, RetryScanners: settings.Scanners} {
+		if policy.Attempts < 1 || policy.Attempts > 10 {
+			return settings, fmt.Errorf("invalid %s attempts %d, expected 1 to 10", class, policy.Attempts)
+		}
+		if policy.DelayMs < 0 {
+			return settings, fmt.Errorf("invalid %s delay %d, expected milliseconds >= 0", class, policy.DelayMs)
+		}
+	}
+	path, err := retryPath()
+	if err != nil {
+		return settings, err
+	}
+	if err := writeJSONFile(path, settings); err != nil {
+		return settings, err
+	}
+	ApplyRetrySettings(settings)
+	return settings, nil
+}
+
+// ApplyRetrySettings makes the policies used by Retry
+func ApplyRetrySettings(settings RetrySettings) {
+	retryMu.Lock()
+	retrySettings = settings
+	retryMu.Unlock()
+}
+
+// permanentError is an error a retry cannot fix
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks an error that must not be retried, e.g. a compilation failure or a timeout
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err}
+}
+
+// Retry calls fn until it succeeds, returns a Permanent error or the attempts of the class are used up,
+// waiting with a doubling delay in between. log, if not nil, is told about every retry. Returns the last
+// error (unwrapped from Permanent).
+func Retry(class string, log func(string), fn func() error) error {
+	retryMu.Lock()
+	policy := retrySettings.policy(class)
+	retryMu.Unlock()
+
+	delay := time.Duration(policy.DelayMs) * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		var permanent permanentError
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
+		if err == nil || attempt >= policy.Attempts {
+			return err
+		}
+		if log != nil {
+			message, _, _ := strings.Cut(strings.TrimSpace(err.Error()), "\n")
+			log(fmt.Sprintf("  [WARNING] Attempt %d of %d failed, retrying in %s: %s", attempt, policy.Attempts, delay, message))
+		}
+		time.Sleep(delay)
+		delay = min(2*delay, maxRetryDelay)
+	}
+}
+
+// transientMavenPattern matches Maven failures caused by the network or the repository manager rather
+// than by the project, which a second attempt may get past
+var transientMavenPattern = regexp.MustCompile(`(?i)Could not transfer (?:artifact|metadata)|Connection (?:reset|refused|timed out)|Read timed out|` +
+	`Remote host terminated the handshake|Unknown host|status code: 5\d\d|5\d\d (?:Bad Gateway|Service Unavailable|Gateway Time-?out)`)
+
+// isTransientMavenFailure reports whether a failed Maven build looks worth retrying
+func isTransientMavenFailure(output string) bool {
+	return transientMavenPattern.MatchString(output)
+}
+
+// retryGitCommand runs a network git command with the git retry policy
+func retryGitCommand(dir string, log func(string), args ...string) error {
+	return Retry(RetryGit, log, func() error {
+		return runGitCommand(dir, args...)
+	})
+}
@@ -0,0 +1,450 @@
+package logic
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gorecode/updates/internal/cmdlimit"
+	"github.com/gorecode/updates/internal/gitops"
+)
+
+// RepoSnapshot records the git state of a repo before a housekeeping run touched it,
+// so the run can be rolled back later.
+type RepoSnapshot struct {
+	RepoPath          string `json:"repoPath"`
+	OriginalBranch    string `json:"originalBranch"`    // Branch checked out before the run
+	OriginalHead      string `json:"originalHead"`      // HEAD SHA before the run
+	WorkBranch        string `json:"workBranch"`        // Branch the housekeeping commits went to
+	WorkBranchCreated bool   `json:"workBranchCreated"` // true if the run created WorkBranch
+	BaseHead          string `json:"baseHead"`          // WorkBranch SHA before the first housekeeping commit
+	StashMessage      string `json:"stashMessage,omitempty"`
+	StashRestored     bool   `json:"stashRestored,omitempty"` // true if the run put the stashed changes back itself
+	CreatedTag        string `json:"createdTag,omitempty"`    // Tag created on the result of the run
+	TagPushed         bool   `json:"tagPushed,omitempty"`     // true if CreatedTag was pushed to origin
+	RolledBack        bool   `json:"rolledBack,omitempty"`
+}
+
+// Outcome of a repo in a run
+const (
+	RepoStatusSuccess = "success"
+	RepoStatusFailed  = "failed"
+	RepoStatusSkipped = "skipped"
+)
+
+// RepoResult records how a repo of a run finished
+type RepoResult struct {
+	RepoPath   string    `json:"repoPath"`
+	Status     string    `json:"status"` // RepoStatusSuccess, RepoStatusFailed or RepoStatusSkipped
+	FinishedAt time.Time `json:"finishedAt"`
+}
+
+// RunRecord is the persisted record of one housekeeping run
+type RunRecord struct {
+	ID          string         `json:"id"`
+	StartedAt   time.Time      `json:"startedAt"`
+	RootPath    string         `json:"rootPath"`
+	Label       string         `json:"label,omitempty"`       // Short name, e.g. "March fleet refresh"
+	Description string         `json:"description,omitempty"` // Free-text annotation
+	Repos       []RepoSnapshot `json:"repos"`
+	Results     []RepoResult   `json:"results,omitempty"` // Completion state of every processed repo
+	RerunOf     string         `json:"rerunOf,omitempty"` // ID of the run this one re-executes
+
+	// Time-boxed runs: repos not started before the budget ran out, and the request to continue them
+	Remaining   []string        `json:"remaining,omitempty"`
+	Request     json.RawMessage `json:"request,omitempty"`
+	ResumedFrom string          `json:"resumedFrom,omitempty"` // ID of the run this one continues
+	ResumedBy   string          `json:"resumedBy,omitempty"`   // ID of the run that continued this one
+}
+
+// Resumable reports whether the run stopped early and has not been continued yet
+func (r *RunRecord) Resumable() bool {
+	return len(r.Remaining) > 0 && r.ResumedBy == "" && len(r.Request) > 0
+}
+
+// RerunRepos returns the repos to execute again: every repo of the run, or with onlyFailed the
+// ones that failed or were skipped. Repos a time-boxed run did not reach are always included.
+func (r *RunRecord) RerunRepos(onlyFailed bool) []string {
+	var repos []string
+	for _, result := range r.Results {
+		if !onlyFailed || result.Status != RepoStatusSuccess {
+			repos = append(repos, result.RepoPath)
+		}
+	}
+	return append(repos, r.Remaining...)
+}
+
+// RunFilter selects run records from the history
+type RunFilter struct {
+	Label string // Exact label match, case-insensitive
+	Query string // Substring of label or description, case-insensitive
+}
+
+// Matches reports whether rec passes the filter (an empty filter matches everything)
+func (f RunFilter) Matches(rec *RunRecord) bool {
+	if f.Label != "" && !strings.EqualFold(rec.Label, f.Label) {
+		return false
+	}
+	if q := strings.ToLower(f.Query); q != "" {
+		if !strings.Contains(strings.ToLower(rec.Label), q) && !strings.Contains(strings.ToLower(rec.Description), q) {
+			return false
+		}
+	}
+	return true
+}
+
+var runIDPattern = regexp.MustCompile(`^[0-9]{8}-[0-9]{6}-[0-9a-f]{4}$`)
+
+// NewRunID creates a sortable, file-name safe ID for a run
+func NewRunID() string {
+	now := time.Now()
+	return fmt.Sprintf("%s-%04x", now.Format("20060102-150405"), now.Nanosecond()&0xffff)
+}
+
+// ValidRunID reports whether id looks like an ID created by NewRunID (guards against path traversal)
+func ValidRunID(id string) bool {
+	return runIDPattern.MatchString(id)
+}
+
+func runRecordPath(id string) (string, error) {
+	if !ValidRunID(id) {
+		return "", fmt.Errorf("invalid run ID '%s'", id)
+	}
+	dir, err := dataSubDir("runs")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, id+".json"), nil
+}
+
+// SaveRunRecord persists a run record in the data directory
+func SaveRunRecord(rec *RunRecord) error {
+	path, err := runRecordPath(rec.ID)
+	if err != nil {
+		return err
+	}
+	return writeJSONFile(path, rec)
+}
+
+// LoadRunRecord loads a previously saved run record
+func LoadRunRecord(id string) (*RunRecord, error) {
+	path, err := runRecordPath(id)
+	if err != nil {
+		return nil, err
+	}
+	var rec RunRecord
+	if err := readJSONFile(path, &rec); err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("run '%s' not found", id)
+		}
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// ListRunRecords returns the saved runs matching the filter, newest first
+func ListRunRecords(filter RunFilter) ([]RunRecord, error) {
+	dir, err := dataSubDir("runs")
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []RunRecord{}
+	// Run IDs start with the timestamp, so reverse name order is newest first
+	for i := len(entries) - 1; i >= 0; i-- {
+		id := strings.TrimSuffix(entries[i].Name(), ".json")
+		if entries[i].IsDir() || !ValidRunID(id) {
+			continue
+		}
+		rec, err := LoadRunRecord(id)
+		if err != nil {
+			continue
+		}
+		if filter.Matches(rec) {
+			result = append(result, *rec)
+		}
+	}
+	return result, nil
+}
+
+// AnnotateRun sets label and description of a saved run
+func AnnotateRun(id, label, description string) (*RunRecord, error) {
+	rec, err := LoadRunRecord(id)
+	if err != nil {
+		return nil, err
+	}
+	rec.Label = strings.TrimSpace(label)
+	rec.Description = strings.TrimSpace(description)
+	if err := SaveRunRecord(rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// gitOutput runs a git command and returns its trimmed stdout
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmdlimit.Output(cmd)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// What a housekeeping run does with a repo that has uncommitted changes
+const (
+	DirtyStash        = "stash"         // Stash the changes and keep them stashed, restored on rollback or with 'git stash pop'
+	DirtyStashRestore = "stash-restore" // Stash the changes and restore them on the original branch after the repo is processed (default)
+	DirtySkip         = "skip"          // Leave the repo alone
+	DirtyAbort        = "abort"         // Do not start the run at all, see FindDirtyRepos
+)
+
+// ValidDirtyMode reports whether mode is one of the Dirty* modes (empty = DirtyStashRestore)
+func ValidDirtyMode(mode string) bool {
+	switch mode {
+	case "", DirtyStash, DirtyStashRestore, DirtySkip, DirtyAbort:
+		return true
+	}
+	return false
+}
+
+// DirtyRepo is a repo with uncommitted changes, as found by the pre-flight check
+type DirtyRepo struct {
+	RepoPath string   `json:"repoPath"`
+	Changes  []string `json:"changes"` // 'git status --porcelain' lines
+}
+
+// uncommittedChanges returns the 'git status --porcelain' lines of the working tree (untracked files included)
+func uncommittedChanges(path string) ([]string, error) {
+	status, err := gitops.Open(path).Status()
+	if err != nil {
+		return nil, fmt.Errorf("git status failed: %v", err)
+	}
+	return status, nil
+}
+
+// FindDirtyRepos checks all repos of a run before it starts. Repos that opted out via
+// .githousekeeper.yaml are ignored, repos whose status cannot be read are reported too.
+func FindDirtyRepos(repos []string) []DirtyRepo {
+	var dirty []DirtyRepo
+	for _, repo := range repos {
+		if cfg, err := LoadRepoConfig(repo); err == nil && cfg.Skip {
+			continue
+		}
+		changes, err := uncommittedChanges(repo)
+		if err != nil {
+			changes = []string{err.Error()}
+		}
+		if len(changes) > 0 {
+			dirty = append(dirty, DirtyRepo{RepoPath: repo, Changes: changes})
+		}
+	}
+	return dirty
+}
+
+// restoreStashedChanges returns to the branch the developer was on and pops the run's stash
+func restoreStashedChanges(snap *RepoSnapshot, log func(string)) {
+	if snap.StashMessage == "" {
+		return
+	}
+	var err error
+	if snap.OriginalBranch != "" && snap.OriginalBranch != "HEAD" {
+		err = runGitCommand(snap.RepoPath, "checkout", snap.OriginalBranch)
+	} else {
+		err = runGitCommand(snap.RepoPath, "checkout", "--detach", snap.OriginalHead)
+	}
+	if err != nil {
+		log(fmt.Sprintf("  [WARNING] Could not return to %s, stashed changes kept in the stash: %v", snap.OriginalBranch, err))
+		return
+	}
+	ref := findStash(snap.RepoPath, snap.StashMessage)
+	if ref == "" {
+		log("  [WARNING] Stash of this run not found, nothing restored.")
+		return
+	}
+	if err := runGitCommand(snap.RepoPath, "stash", "pop", ref); err != nil {
+		log(fmt.Sprintf("  [WARNING] Restoring stashed changes failed, they are still in the stash: %v", err))
+		return
+	}
+	snap.StashMessage = ""
+	snap.StashRestored = true
+	log(fmt.Sprintf("  Switched back to %s and restored the uncommitted changes.", snap.OriginalBranch))
+}
+
+// captureOriginalState fills the pre-run part of the snapshot and stashes uncommitted changes
+func captureOriginalState(path, runID string, snap *RepoSnapshot, log func(string)) error {
+	snap.RepoPath = path
+	snap.OriginalBranch = currentBranch(path)
+	snap.OriginalHead, _ = gitops.Open(path).Head()
+
+	changes, err := uncommittedChanges(path)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	msg := "GitHousekeeper run " + runID
+	if err := runGitCommand(path, "stash", "push", "--include-untracked", "-m", msg); err != nil {
+		return fmt.Errorf("could not stash uncommitted changes: %v", err)
+	}
+	snap.StashMessage = msg
+	log("  [INFO] Uncommitted changes stashed.")
+	return nil
+}
+
+// findStash returns the stash ref (e.g. stash@{2}) whose message contains msg
+func findStash(path, msg string) string {
+	out, err := gitOutput(path, "stash", "list", "--format=%gd|%gs")
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(line, "|", 2)
+		if len(parts) == 2 && strings.HasSuffix(parts[1], msg) {
+			return parts[0]
+		}
+	}
+	return ""
+}
+
+// RollbackRepo reverts a repo to the state recorded in the snapshot:
+// a branch created by the run is deleted, an existing branch is reset to its pre-run SHA,
+// the original branch is checked out again and stashed changes are restored.
+// A tag created by the run is deleted, on origin too if the run pushed it.
+func RollbackRepo(snap RepoSnapshot, log func(string)) (err error) {
+	path := snap.RepoPath
+	if !IsGitRepo(path) {
+		return fmt.Errorf("%s is not a git repository", path)
+	}
+
+	// Never destroy work done after the run. If the run restored its stash, the changes are
+	// expected: they are stashed for the rollback and put back at the end.
+	if snap.StashRestored && snap.StashMessage == "" {
+		changes, err := uncommittedChanges(path)
+		if err != nil {
+			return err
+		}
+		if len(changes) > 0 {
+			msg := "GitHousekeeper rollback " + NewRunID()
+			if err := runGitCommand(path, "stash", "push", "--include-untracked", "-m", msg); err != nil {
+				return fmt.Errorf("could not stash uncommitted changes: %v", err)
+			}
+			snap.StashMessage = msg
+			log("  [INFO] Uncommitted changes stashed for the rollback.")
+			defer func() {
+				if err != nil {
+					log(fmt.Sprintf("  [WARNING] Uncommitted changes are kept in the stash '%s'.", msg))
+				}
+			}()
+		}
+	} else if err := requireCleanWorktree(path); err != nil {
+		return err
+	}
+
+	if snap.CreatedTag != "" {
+		if snap.TagPushed {
+			remote := primaryRemoteOrOrigin(path)
+			if err := retryGitCommand(path, log, "push", remote, "--delete", "refs/tags/"+snap.CreatedTag); err != nil {
+				log(fmt.Sprintf("  [WARNING] Deleting tag '%s' on %s failed: %v", snap.CreatedTag, remote, err))
+			} else {
+				log(fmt.Sprintf("  Tag '%s' deleted on %s.", snap.CreatedTag, remote))
+			}
+		}
+		if err := runGitCommand(path, "tag", "-d", snap.CreatedTag); err != nil {
+			log(fmt.Sprintf("  [WARNING] Deleting tag '%s' failed: %v", snap.CreatedTag, err))
+		} else {
+			log(fmt.Sprintf("  Tag '%s' deleted.", snap.CreatedTag))
+		}
+	}
+
+	if snap.WorkBranch != "" && snap.BaseHead != "" {
+		if snap.WorkBranchCreated {
+			if snap.OriginalBranch != "" && snap.OriginalBranch != "HEAD" && snap.OriginalBranch != snap.WorkBranch {
+				if err := runGitCommand(path, "checkout", snap.OriginalBranch); err != nil {
+					return fmt.Errorf("checkout %s failed: %v", snap.OriginalBranch, err)
+				}
+			} else if err := runGitCommand(path, "checkout", "--detach", snap.OriginalHead); err != nil {
+				return fmt.Errorf("checkout %s failed: %v", snap.OriginalHead, err)
+			}
+			if err := runGitCommand(path, "branch", "-D", snap.WorkBranch); err != nil {
+				return fmt.Errorf("deleting branch %s failed: %v", snap.WorkBranch, err)
+			}
+			log(fmt.Sprintf("  Branch '%s' deleted.", snap.WorkBranch))
+		} else {
+			if err := runGitCommand(path, "checkout", snap.WorkBranch); err != nil {
+				return fmt.Errorf("checkout %s failed: %v", snap.WorkBranch, err)
+			}
+			if err := runGitCommand(path, "reset", "--hard", snap.BaseHead); err != nil {
+				return fmt.Errorf("reset %s failed: %v", snap.WorkBranch, err)
+			}
+			log(fmt.Sprintf("  Branch '%s' reset to %s.", snap.WorkBranch, shortSHA(snap.BaseHead)))
+		}
+	}
+
+	// Return to where the developer was
+	if snap.OriginalBranch != "" && snap.OriginalBranch != "HEAD" {
+		if err := runGitCommand(path, "checkout", snap.OriginalBranch); err != nil {
+			return fmt.Errorf("checkout %s failed: %v", snap.OriginalBranch, err)
+		}
+	}
+
+	if snap.StashMessage != "" {
+		ref := findStash(path, snap.StashMessage)
+		if ref == "" {
+			log("  [WARNING] Stash of this run not found (already restored?).")
+		} else if err := runGitCommand(path, "stash", "pop", ref); err != nil {
+			return fmt.Errorf("restoring stashed changes failed: %v", err)
+		} else {
+			log("  Stashed changes restored.")
+		}
+	}
+
+	return nil
+}
+
+// RollbackRun rolls back all repos of a run and marks them in the persisted record
+func RollbackRun(id string, log func(string)) (*RunRecord, error) {
+	rec, err := LoadRunRecord(id)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rec.Repos {
+		snap := &rec.Repos[i]
+		log(fmt.Sprintf("REPO:%s", filepath.Base(snap.RepoPath)))
+		if snap.RolledBack {
+			log("  Already rolled back, skipping.")
+			continue
+		}
+		if err := RollbackRepo(*snap, log); err != nil {
+			log(fmt.Sprintf("  [ERROR] Rollback failed: %v", err))
+			continue
+		}
+		snap.RolledBack = true
+		log("  ✓ Rolled back.")
+	}
+
+	if err := SaveRunRecord(rec); err != nil {
+		return rec, err
+	}
+	return rec, nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
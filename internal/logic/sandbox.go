@@ -0,0 +1,112 @@
+package logic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// AllowedRootsEnv lists the directories the server may work in, separated by the OS path list
+// separator (":" on Unix, ";" on Windows). Unset means no restriction.
+const AllowedRootsEnv = "GITHOUSEKEEPER_ALLOWED_ROOTS"
+
+// PathSandbox restricts filesystem operations to a set of root directories
+type PathSandbox struct {
+	roots []string // Absolute, symlink-free
+}
+
+// NewPathSandbox resolves the given roots. An empty list creates a sandbox that allows everything.
+func NewPathSandbox(roots []string) (*PathSandbox, error) {
+	sandbox := &PathSandbox{}
+	for _, root := range roots {
+		root = strings.TrimSpace(root)
+		if root == "" {
+			continue
+		}
+		resolved, err := filepath.EvalSymlinks(root)
+		if err != nil {
+			return nil, fmt.Errorf("allowed root '%s': %v", root, err)
+		}
+		resolved, err = filepath.Abs(resolved)
+		if err != nil {
+			return nil, fmt.Errorf("allowed root '%s': %v", root, err)
+		}
+		sandbox.roots = append(sandbox.roots, resolved)
+	}
+	return sandbox, nil
+}
+
+// PathSandboxFromEnv creates the sandbox configured via GITHOUSEKEEPER_ALLOWED_ROOTS
+func PathSandboxFromEnv() (*PathSandbox, error) {
+	value := os.Getenv(AllowedRootsEnv)
+	if value == "" {
+		return &PathSandbox{}, nil
+	}
+	return NewPathSandbox(filepath.SplitList(value))
+}
+
+// Enabled reports whether any roots are configured
+func (s *PathSandbox) Enabled() bool {
+	return s != nil && len(s.roots) > 0
+}
+
+// Roots returns the resolved allowed roots
+func (s *PathSandbox) Roots() []string {
+	if s == nil {
+		return nil
+	}
+	return s.roots
+}
+
+// Check returns an error unless path (after resolving symlinks) lies inside one of the roots.
+// Paths that do not exist yet (e.g. a clone target) are checked via their nearest existing parent.
+func (s *PathSandbox) Check(path string) error {
+	if !s.Enabled() {
+		return nil
+	}
+	resolved, err := resolveExistingPrefix(path)
+	if err != nil {
+		return fmt.Errorf("invalid path '%s': %v", path, err)
+	}
+	for _, root := range s.roots {
+		if pathWithin(root, resolved) {
+			return nil
+		}
+	}
+	return fmt.Errorf("path '%s' is outside the allowed roots", path)
+}
+
+// resolveExistingPrefix makes path absolute and evaluates the symlinks of its longest existing prefix
+func resolveExistingPrefix(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	var missing []string
+	current := abs
+	for {
+		if resolved, err := filepath.EvalSymlinks(current); err == nil {
+			return filepath.Join(append([]string{resolved}, missing...)...), nil
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return abs, nil
+		}
+		missing = append([]string{filepath.Base(current)}, missing...)
+		current = parent
+	}
+}
+
+// pathWithin reports whether path equals root or lies below it
+func pathWithin(root, path string) bool {
+	if runtime.GOOS == "windows" {
+		root, path = strings.ToLower(root), strings.ToLower(path)
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
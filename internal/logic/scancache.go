@@ -0,0 +1,120 @@
+package logic
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/gorecode/updates/internal/logic/registry"
+)
+
+// scanCacheTTL bounds how long a cached scan result is served: the advisory databases change daily
+const scanCacheTTL = 24 * time.Hour
+
+// dependencyFiles are the manifests and lockfiles deciding the dependencies of a project type
+var dependencyFiles = map[string][]string{
+	"maven":  {"pom.xml"}, // Of every module, see DependencyFingerprint
+	"npm":    {"package.json", "package-lock.json", "npm-shrinkwrap.json"},
+	"yarn":   {"package.json", "yarn.lock"},
+	"pnpm":   {"package.json", "pnpm-lock.yaml"},
+	"go":     {"go.mod", "go.sum"},
+	"python": {"requirements.txt", "pyproject.toml", "poetry.lock", "uv.lock", "Pipfile", "Pipfile.lock", "setup.py"},
+	"php":    {"composer.json", "composer.lock"},
+	"rust":   {"Cargo.toml", "Cargo.lock"},
+	"ruby":   {"Gemfile", "Gemfile.lock"},
+	"dotnet": {"global.json", "Directory.Packages.props", "Directory.Build.props", "packages.lock.json"}, // And every project file
+}
+
+// DependencyFingerprint hashes the manifests and lockfiles of the project: every pom.xml of a Maven
+// build, every project file and lockfile of a .NET solution, the root files of the other project
+// types. "" when the project type has none. Repositories with the same dependencies get the same
+// fingerprint.
+func DependencyFingerprint(repoPath, projectType string) string {
+	names := dependencyFiles[projectType]
+	var files []string
+	if projectType == "maven" || projectType == "dotnet" {
+		filepath.WalkDir(repoPath, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if name := d.Name(); path != repoPath && (name == "target" || name == "bin" || name == "obj" || name == "node_modules" || name[0] == '.') {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if slices.Contains(names, d.Name()) || (projectType == "dotnet" && isDotnetProjectFile(d.Name())) {
+				files = append(files, path)
+			}
+			return nil
+		})
+	} else {
+		for _, name := range names {
+			if _, err := os.Stat(filepath.Join(repoPath, name)); err == nil {
+				files = append(files, filepath.Join(repoPath, name))
+			}
+		}
+	}
+	if len(files) == 0 {
+		return ""
+	}
+
+	hash := sha256.New()
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return ""
+		}
+		rel, _ := filepath.Rel(repoPath, file)
+		fmt.Fprintf(hash, "%s\x00%d\x00", filepath.ToSlash(rel), len(data))
+		hash.Write(data)
+	}
+	return projectType + "-" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// scanCacheEntry is a scanner result stored for a dependency fingerprint
+type scanCacheEntry struct {
+	Scanner     string          `json:"scanner"`
+	Fingerprint string          `json:"fingerprint"`
+	CachedAt    time.Time       `json:"cachedAt"`
+	Result      json.RawMessage `json:"result"`
+}
+
+// LoadCachedScan reads the result the scanner produced for the fingerprint into result and returns
+// when it was scanned. Results older than a day are not served, except in offline mode.
+func LoadCachedScan(scanner, fingerprint string, result interface{}) (time.Time, bool) {
+	path := cacheFilePath("scan-cache", scanner+"/"+fingerprint)
+	if path == "" {
+		return time.Time{}, false
+	}
+	var entry scanCacheEntry
+	if err := readJSONFile(path, &entry); err != nil || entry.Scanner != scanner || entry.Fingerprint != fingerprint {
+		return time.Time{}, false
+	}
+	if time.Since(entry.CachedAt) >= scanCacheTTL && !registry.Default.Offline {
+		return time.Time{}, false
+	}
+	if err := json.Unmarshal(entry.Result, result); err != nil {
+		return time.Time{}, false
+	}
+	return entry.CachedAt, true
+}
+
+// SaveCachedScan stores the result of the scanner for the fingerprint
+func SaveCachedScan(scanner, fingerprint string, result interface{}) error {
+	path := cacheFilePath("scan-cache", scanner+"/"+fingerprint)
+	if path == "" {
+		return fmt.Errorf("data directory not available")
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return writeJSONFile(path, scanCacheEntry{Scanner: scanner, Fingerprint: fingerprint, CachedAt: time.Now(), Result: data})
+}
@@ -0,0 +1,215 @@
+package logic
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// scanHistoryLimit bounds the scans kept per repo, branch and scanner (a year of weekly scans)
+const scanHistoryLimit = 52
+
+// ScanSnapshot is the finding set of one scan
+type ScanSnapshot struct {
+	ScannedAt time.Time         `json:"scannedAt"`
+	Findings  []SecurityFinding `json:"findings"`
+}
+
+// ScanHistory holds the scans of one repo branch by one scanner, oldest first
+type ScanHistory struct {
+	RepoName string         `json:"repoName"`
+	RepoPath string         `json:"repoPath"`
+	Branch   string         `json:"branch"`
+	Scanner  string         `json:"scanner"`
+	Scans    []ScanSnapshot `json:"scans"`
+}
+
+var scanHistoryMu sync.Mutex
+
+func scanHistoryPath(repoPath, branch, scanner string) string {
+	return cacheFilePath("scan-history", repoPath+"\x00"+branch+"\x00"+scanner)
+}
+
+// RecordScan appends the findings of a scan to the history of the repo branch and scanner and
+// returns the scan before it, nil for the first one
+func RecordScan(repoPath, branch, scanner string, findings []SecurityFinding) (*ScanSnapshot, error) {
+	scanHistoryMu.Lock()
+	defer scanHistoryMu.Unlock()
+
+	if _, err := dataSubDir("scan-history"); err != nil {
+		return nil, err
+	}
+	path := scanHistoryPath(repoPath, branch, scanner)
+	history := ScanHistory{RepoName: filepath.Base(repoPath), RepoPath: repoPath, Branch: branch, Scanner: scanner}
+	readJSONFile(path, &history)
+
+	var previous *ScanSnapshot
+	if n := len(history.Scans); n > 0 {
+		previous = &history.Scans[n-1]
+	}
+	if findings == nil {
+		findings = []SecurityFinding{}
+	}
+	history.Scans = append(history.Scans, ScanSnapshot{ScannedAt: time.Now(), Findings: findings})
+	if len(history.Scans) > scanHistoryLimit {
+		history.Scans = history.Scans[len(history.Scans)-scanHistoryLimit:]
+	}
+	if err := writeJSONFile(path, &history); err != nil {
+		return nil, err
+	}
+	if previous != nil {
+		snapshot := *previous
+		return &snapshot, nil
+	}
+	return nil, nil
+}
+
+// ListScanHistories returns the scan histories of all repos, sorted by repo, branch and scanner
+func ListScanHistories() ([]ScanHistory, error) {
+	dir, err := dataSubDir("scan-history")
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	scanHistoryMu.Lock()
+	defer scanHistoryMu.Unlock()
+
+	result := []ScanHistory{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		var history ScanHistory
+		if err := readJSONFile(filepath.Join(dir, e.Name()), &history); err == nil {
+			result = append(result, history)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.RepoPath != b.RepoPath {
+			return a.RepoPath < b.RepoPath
+		}
+		if a.Branch != b.Branch {
+			return a.Branch < b.Branch
+		}
+		return a.Scanner < b.Scanner
+	})
+	return result, nil
+}
+
+// Finding changes relative to the previous scan
+const (
+	FindingNew        = "new"
+	FindingPersisting = "persisting"
+	FindingFixed      = "fixed"
+)
+
+// FindingDelta classifies the findings of a scan against the previous one
+type FindingDelta struct {
+	New        []SecurityFinding `json:"new"`
+	Persisting []SecurityFinding `json:"persisting"`
+	Fixed      []SecurityFinding `json:"fixed"` // In the previous scan, not in this one
+}
+
+// CompareFindings classifies the current findings as new or persisting and lists the previous ones
+// that disappeared as fixed. The same vulnerability in the same package counts as persisting even
+// when the version changed.
+func CompareFindings(previous, current []SecurityFinding) FindingDelta {
+	delta := FindingDelta{New: []SecurityFinding{}, Persisting: []SecurityFinding{}, Fixed: []SecurityFinding{}}
+	before := make(map[string]bool, len(previous))
+	for _, f := range previous {
+		before[f.Key()] = true
+	}
+	now := make(map[string]bool, len(current))
+	for _, f := range current {
+		now[f.Key()] = true
+		if before[f.Key()] {
+			delta.Persisting = append(delta.Persisting, f)
+		} else {
+			delta.New = append(delta.New, f)
+		}
+	}
+	for _, f := range previous {
+		if !now[f.Key()] {
+			delta.Fixed = append(delta.Fixed, f)
+			now[f.Key()] = true // Listed once
+		}
+	}
+	return delta
+}
+
+// NewFindingsEntry lists the findings a repo branch gained in a period
+type NewFindingsEntry struct {
+	RepoName string            `json:"repoName"`
+	RepoPath string            `json:"repoPath"`
+	Branch   string            `json:"branch"`
+	Scanner  string            `json:"scanner"`
+	Baseline *time.Time        `json:"baseline,omitempty"` // Last scan before the period, nil = first scanned in it
+	New      []SecurityFinding `json:"new"`
+	Fixed    []SecurityFinding `json:"fixed"`
+}
+
+// NewFindingsReport sums up the findings of at least a severity that appeared since a point in
+// time, e.g. "net new CRITICALs this week"
+type NewFindingsReport struct {
+	Since    time.Time          `json:"since"`
+	Severity string             `json:"severity"`
+	New      int                `json:"new"`
+	Fixed    int                `json:"fixed"`
+	NetNew   int                `json:"netNew"` // New minus fixed
+	Repos    []NewFindingsEntry `json:"repos"`
+}
+
+// NewFindingsSince compares the latest scan of every repo branch and scanner with its last scan
+// before since. Histories without a scan in the period are skipped; ones first scanned in the period
+// count all their findings as new.
+func NewFindingsSince(histories []ScanHistory, since time.Time, severity string) NewFindingsReport {
+	report := NewFindingsReport{Since: since, Severity: severity, Repos: []NewFindingsEntry{}}
+	for _, history := range histories {
+		if len(history.Scans) == 0 {
+			continue
+		}
+		latest := history.Scans[len(history.Scans)-1]
+		if latest.ScannedAt.Before(since) {
+			continue
+		}
+		entry := NewFindingsEntry{RepoName: history.RepoName, RepoPath: history.RepoPath, Branch: history.Branch, Scanner: history.Scanner}
+		var baseline []SecurityFinding
+		for i := len(history.Scans) - 1; i >= 0; i-- {
+			if history.Scans[i].ScannedAt.Before(since) {
+				entry.Baseline = &history.Scans[i].ScannedAt
+				baseline = history.Scans[i].Findings
+				break
+			}
+		}
+		delta := CompareFindings(baseline, latest.Findings)
+		entry.New = filterSeverity(delta.New, severity)
+		entry.Fixed = filterSeverity(delta.Fixed, severity)
+		if len(entry.New) == 0 && len(entry.Fixed) == 0 {
+			continue
+		}
+		report.New += len(entry.New)
+		report.Fixed += len(entry.Fixed)
+		report.Repos = append(report.Repos, entry)
+	}
+	report.NetNew = report.New - report.Fixed
+	return report
+}
+
+// filterSeverity keeps the findings of at least the severity ("" keeps all)
+func filterSeverity(findings []SecurityFinding, severity string) []SecurityFinding {
+	result := []SecurityFinding{}
+	for _, f := range findings {
+		if severity == "" || SeverityAtLeast(f.Severity, severity) {
+			result = append(result, f)
+		}
+	}
+	return result
+}
@@ -0,0 +1,196 @@
+// Package score computes repository health scores from a configurable policy of weighted rules.
+package score
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Factors a rule can evaluate
+const (
+	FactorTodos            = "todos"            // TODO/FIXME comments
+	FactorJUnit4           = "junit4"           // 1 if JUnit 4 is a dependency
+	FactorSpringBootMajor  = "springBootMajor"  // Major version of Spring Boot (0 = none)
+	FactorLastCommitDays   = "lastCommitDays"   // Days since the last commit (-1 = unknown, never matches)
+	FactorOutdatedDeps     = "outdatedDeps"     // Outdated dependencies
+	FactorCVEs             = "cves"             // Known vulnerabilities of any severity (findings store)
+	FactorCriticalCVEs     = "criticalCves"     // Known CRITICAL and HIGH vulnerabilities
+	FactorMissingCI        = "missingCI"        // 1 if no CI configuration was found
+	FactorDeprecatedCI     = "deprecatedCI"     // Deprecated runner images and actions in the CI configuration
+	FactorBaseImageIssues  = "baseImageIssues"  // Dockerfile base images pinned to latest or end-of-life
+	FactorEOLRuntimes      = "eolRuntimes"      // Runtimes and frameworks out of support (endoflife.date)
+	FactorMissingTests     = "missingTests"     // 1 if the repo has no test files
+	FactorUncoveredPercent = "uncoveredPercent" // 100 - line coverage of the coverage report (-1 = no report, never matches)
+)
+
+var factors = map[string]bool{
+	FactorTodos: true, FactorJUnit4: true, FactorSpringBootMajor: true, FactorLastCommitDays: true,
+	FactorOutdatedDeps: true, FactorCVEs: true, FactorCriticalCVEs: true, FactorMissingCI: true,
+	FactorMissingTests: true, FactorUncoveredPercent: true, FactorDeprecatedCI: true, FactorBaseImageIssues: true,
+	FactorEOLRuntimes: true,
+}
+
+// Facts are the measured properties of a repo
+type Facts map[string]float64
+
+// Rule deducts points when its factor matches:
+//   - equals: the factor has exactly this value, deduct penalty once
+//   - per: deduct penalty for every full "per" units above "above" (e.g. 1 point per 5 TODOs)
+//   - otherwise: the factor is above "above" (default 0), deduct penalty once
+//
+// max caps the deduction of the rule (0 = no cap).
+type Rule struct {
+	Name    string   `yaml:"name" json:"name"`
+	Factor  string   `yaml:"factor" json:"factor"`
+	Penalty float64  `yaml:"penalty" json:"penalty"`
+	Equals  *float64 `yaml:"equals,omitempty" json:"equals,omitempty"`
+	Above   float64  `yaml:"above,omitempty" json:"above,omitempty"`
+	Per     float64  `yaml:"per,omitempty" json:"per,omitempty"`
+	Max     float64  `yaml:"max,omitempty" json:"max,omitempty"`
+}
+
+// Policy is the scoring model: every repo starts at Base and loses the points of the matching rules
+type Policy struct {
+	Base  float64 `yaml:"base" json:"base"`
+	Rules []Rule  `yaml:"rules" json:"rules"`
+}
+
+// Deduction is the contribution of one rule to a score
+type Deduction struct {
+	Rule   string `json:"rule"`
+	Points int    `json:"points"`
+}
+
+// Result is a score with its explanation
+type Result struct {
+	Score      int         `json:"score"`
+	Deductions []Deduction `json:"deductions,omitempty"`
+}
+
+func equals(v float64) *float64 { return &v }
+
+// DefaultPolicy reproduces the built-in penalties: 1 point per 5 TODOs (max 20),
+// 5 for JUnit 4, 20 for Spring Boot 2 and 40 for Spring Boot 1
+func DefaultPolicy() *Policy {
+	return &Policy{
+		Base: 100,
+		Rules: []Rule{
+			{Name: "TODO comments", Factor: FactorTodos, Penalty: 1, Per: 5, Max: 20},
+			{Name: "JUnit 4", Factor: FactorJUnit4, Penalty: 5},
+			{Name: "Spring Boot 2", Factor: FactorSpringBootMajor, Equals: equals(2), Penalty: 20},
+			{Name: "Spring Boot 1", Factor: FactorSpringBootMajor, Equals: equals(1), Penalty: 40},
+		},
+	}
+}
+
+// Parse reads a YAML policy; unknown keys and factors are an error
+func Parse(data []byte) (*Policy, error) {
+	policy := &Policy{Base: 100}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(policy); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Load reads the policy file at path; a missing file yields the default policy
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return DefaultPolicy(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	policy, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return policy, nil
+}
+
+// Validate checks the factors and numbers of all rules
+func (p *Policy) Validate() error {
+	if p.Base <= 0 {
+		return fmt.Errorf("base must be positive")
+	}
+	for i, rule := range p.Rules {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("rule %d", i+1)
+		}
+		if !factors[rule.Factor] {
+			return fmt.Errorf("%s: unknown factor '%s'", name, rule.Factor)
+		}
+		if rule.Penalty < 0 || rule.Per < 0 || rule.Max < 0 {
+			return fmt.Errorf("%s: penalty, per and max must not be negative", name)
+		}
+		if rule.Equals != nil && rule.Per > 0 {
+			return fmt.Errorf("%s: equals and per cannot be combined", name)
+		}
+	}
+	return nil
+}
+
+// Evaluate scores the facts of a repo; the result is between 0 and Base
+func (p *Policy) Evaluate(facts Facts) Result {
+	total := p.Base
+	result := Result{}
+	for _, rule := range p.Rules {
+		points := rule.deduction(facts[rule.Factor])
+		if points <= 0 {
+			continue
+		}
+		name := rule.Name
+		if name == "" {
+			name = rule.Factor
+		}
+		result.Deductions = append(result.Deductions, Deduction{Rule: name, Points: int(math.Round(points))})
+		total -= points
+	}
+	result.Score = int(math.Round(math.Max(0, total)))
+	return result
+}
+
+func (r Rule) deduction(value float64) float64 {
+	var points float64
+	switch {
+	case r.Equals != nil:
+		if value == *r.Equals {
+			points = r.Penalty
+		}
+	case r.Per > 0:
+		if value > r.Above {
+			points = math.Floor((value-r.Above)/r.Per) * r.Penalty
+		}
+	default:
+		if value > r.Above {
+			points = r.Penalty
+		}
+	}
+	if r.Max > 0 && points > r.Max {
+		points = r.Max
+	}
+	return points
+}
+
+// MajorVersion returns the major number of a version like "3.2.1" (0 if there is none)
+func MajorVersion(version string) float64 {
+	major, _, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(version), "v"), ".")
+	var n float64
+	if _, err := fmt.Sscanf(major, "%g", &n); err != nil {
+		return 0
+	}
+	return n
+}
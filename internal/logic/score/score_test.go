@@ -0,0 +1,114 @@
+package score
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ===========================================
+// Tests for the Default Policy
+// ===========================================
+
+func TestDefaultPolicy_MatchesBuiltInPenalties(t *testing.T) {
+	tests := []struct {
+		name     string
+		facts    Facts
+		expected int
+	}{
+		{"Clean repo", Facts{}, 100},
+		{"14 TODOs", Facts{FactorTodos: 14}, 98},
+		{"TODO penalty is capped", Facts{FactorTodos: 500}, 80},
+		{"JUnit 4", Facts{FactorJUnit4: 1}, 95},
+		{"Spring Boot 3", Facts{FactorSpringBootMajor: 3}, 100},
+		{"Spring Boot 2", Facts{FactorSpringBootMajor: 2}, 80},
+		{"Spring Boot 1 with everything", Facts{FactorSpringBootMajor: 1, FactorJUnit4: 1, FactorTodos: 500}, 35},
+		{"New factors are not scored by default", Facts{FactorMissingCI: 1, FactorCVEs: 12, FactorLastCommitDays: 900}, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultPolicy().Evaluate(tt.facts).Score; got != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+// ===========================================
+// Tests for Custom Policies
+// ===========================================
+
+func TestParse_CustomRules(t *testing.T) {
+	policy, err := Parse([]byte(`
+base: 100
+rules:
+  - name: Stale repository
+    factor: lastCommitDays
+    above: 365
+    penalty: 10
+  - name: Critical vulnerabilities
+    factor: criticalCves
+    per: 1
+    penalty: 15
+    max: 45
+  - name: No CI
+    factor: missingCI
+    penalty: 25
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	result := policy.Evaluate(Facts{FactorLastCommitDays: 400, FactorCriticalCVEs: 5, FactorMissingCI: 1})
+	if result.Score != 20 {
+		t.Errorf("Expected 100-10-45-25 = 20, got %d", result.Score)
+	}
+	if len(result.Deductions) != 3 || result.Deductions[1].Rule != "Critical vulnerabilities" || result.Deductions[1].Points != 45 {
+		t.Errorf("Unexpected deductions: %+v", result.Deductions)
+	}
+
+	if got := policy.Evaluate(Facts{FactorLastCommitDays: -1}).Score; got != 100 {
+		t.Errorf("Expected an unknown last commit not to count, got %d", got)
+	}
+	if got := policy.Evaluate(Facts{FactorCriticalCVEs: 50, FactorMissingCI: 1, FactorLastCommitDays: 999}).Score; got != 20 {
+		t.Errorf("Expected the cap to apply, got %d", got)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown factor": "rules:\n  - factor: stars\n    penalty: 5\n",
+		"unknown key":    "rules:\n  - factor: todos\n    weight: 5\n",
+		"negative":       "rules:\n  - factor: todos\n    penalty: -5\n",
+		"equals and per": "rules:\n  - factor: todos\n    equals: 1\n    per: 2\n",
+		"zero base":      "base: 0\n",
+	}
+	for name, yaml := range tests {
+		if _, err := Parse([]byte(yaml)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	policy, err := Load(filepath.Join(dir, "missing.yaml"))
+	if err != nil || len(policy.Rules) != len(DefaultPolicy().Rules) {
+		t.Errorf("Expected the default policy for a missing file, got %+v (%v)", policy, err)
+	}
+
+	path := filepath.Join(dir, "policy.yaml")
+	os.WriteFile(path, []byte("rules:\n  - factor: nope\n"), 0644)
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "policy.yaml") {
+		t.Errorf("Expected an error naming the file, got %v", err)
+	}
+}
+
+func TestMajorVersion(t *testing.T) {
+	for version, expected := range map[string]float64{"3.2.1": 3, "2.7.18": 2, "v1.5": 1, "": 0, "${boot.version}": 0} {
+		if got := MajorVersion(version); got != expected {
+			t.Errorf("MajorVersion(%q) = %v, expected %v", version, got, expected)
+		}
+	}
+}
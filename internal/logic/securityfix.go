@@ -0,0 +1,283 @@
+package logic
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gorecode/updates/internal/cmdlimit"
+	"github.com/gorecode/updates/internal/logic/registry"
+)
+
+// DependencyFix asks for a vulnerable dependency to be bumped to the version that fixes it
+type DependencyFix struct {
+	Package string `json:"package"` // As reported by the scanner (Maven "group:artifact" or jar name, npm name, Go module/package)
+	FixedIn string `json:"fixedIn"`
+	CVE     string `json:"cve,omitempty"`
+}
+
+// SecurityFixOptions configures FixVulnerableDependencies
+type SecurityFixOptions struct {
+	Branch  string // Branch for the fix commit (default "security-fix")
+	Push    bool   // Push the branch to origin after committing
+	RunID   string // ID of the run this repo belongs to (used to label stashes)
+	Maven   MavenSettings
+	Signing SigningSettings // Optional signing of the fix commit
+	Log     func(string)
+}
+
+// plannedFix is a fix resolved against the direct dependencies of the build file
+type plannedFix struct {
+	Name    string // Name as declared in the build file
+	Current string
+	Target  string
+	CVEs    []string
+}
+
+// jarVersionSuffix matches the version part of a jar file name ("jackson-databind-2.9.8.jar")
+var jarVersionSuffix = regexp.MustCompile(`-[0-9][0-9A-Za-z.\-_]*\.jar$`)
+
+// planDependencyFixes maps the requested fixes to direct dependencies and keeps the highest
+// fix version per dependency. Fixes that cannot be applied are returned as skip reasons.
+func planDependencyFixes(repoPath, projectType string, fixes []DependencyFix) ([]plannedFix, []string) {
+	direct := make(map[string]string)
+	for _, dep := range collectDirectDependencies(repoPath, projectType) {
+		direct[dep.Name] = dep.Version
+	}
+
+	// resolve finds the declared name of a reported package
+	resolve := func(pkg string) string {
+		if _, ok := direct[pkg]; ok {
+			return pkg
+		}
+		for name := range direct {
+			switch projectType {
+			case "maven":
+				// OWASP reports jar file names, Trivy "group:artifact"
+				artifact := name[strings.LastIndex(name, ":")+1:]
+				if pkg == artifact || jarVersionSuffix.ReplaceAllString(pkg, "") == artifact {
+					return name
+				}
+			case "go":
+				// govulncheck may report a package inside the module
+				if strings.HasPrefix(pkg, name+"/") {
+					return name
+				}
+			}
+		}
+		return ""
+	}
+
+	byName := make(map[string]*plannedFix)
+	var skipped []string
+	for _, fix := range fixes {
+		if fix.FixedIn == "" {
+			skipped = append(skipped, fmt.Sprintf("%s: no fix version known", fix.Package))
+			continue
+		}
+		name := resolve(fix.Package)
+		if name == "" {
+			skipped = append(skipped, fmt.Sprintf("%s: not a direct dependency with a pinned version (transitive dependencies are not bumped)", fix.Package))
+			continue
+		}
+
+		target := fix.FixedIn
+		if projectType == "go" && !strings.HasPrefix(target, "v") {
+			target = "v" + target
+		}
+
+		planned, ok := byName[name]
+		if !ok {
+			planned = &plannedFix{Name: name, Current: direct[name], Target: target}
+			byName[name] = planned
+		} else if registry.CompareVersions(target, planned.Target) > 0 {
+			planned.Target = target
+		}
+		if fix.CVE != "" {
+			planned.CVEs = append(planned.CVEs, fix.CVE)
+		}
+	}
+
+	var result []plannedFix
+	for _, planned := range byName {
+		if registry.CompareVersions(planned.Target, planned.Current) <= 0 {
+			skipped = append(skipped, fmt.Sprintf("%s: already at %s (fix %s)", planned.Name, planned.Current, planned.Target))
+			continue
+		}
+		result = append(result, *planned)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	sort.Strings(skipped)
+	return result, skipped
+}
+
+// applyDependencyFix bumps one dependency with the project's own tooling
+func applyDependencyFix(repoPath, projectType string, fix plannedFix, maven MavenSettings) (string, error) {
+	switch projectType {
+	case "maven":
+		cmd := maven.Command(repoPath, "versions:use-dep-version",
+			"-Dincludes="+fix.Name, "-DdepVersion="+fix.Target,
+			"-DforceVersion=true", "-DgenerateBackupPoms=false")
+		output, err := cmdlimit.CombinedOutput(cmd)
+		return string(output), err
+	case "npm":
+		return runTool(repoPath, "npm", "install", fix.Name+"@"+fix.Target)
+	case "yarn":
+		return runTool(repoPath, "yarn", "add", fix.Name+"@"+fix.Target)
+	case "pnpm":
+		return runTool(repoPath, "pnpm", "add", fix.Name+"@"+fix.Target)
+	case "go":
+		return runTool(repoPath, "go", "get", fix.Name+"@"+fix.Target)
+	}
+	return "", fmt.Errorf("automatic fixes are not supported for %s projects", projectType)
+}
+
+// verifyBuild builds the project after the bumps
+func verifyBuild(repoPath, projectType string, maven MavenSettings) (string, error) {
+	switch projectType {
+	case "maven":
+		output, err := cmdlimit.CombinedOutput(maven.Command(repoPath, "clean", "verify", "-DskipTests"))
+		return string(output), err
+	case "npm":
+		return runTool(repoPath, "npm", "run", "build", "--if-present")
+	case "yarn":
+		return runTool(repoPath, "yarn", "run", "--if-present", "build")
+	case "pnpm":
+		return runTool(repoPath, "pnpm", "run", "--if-present", "build")
+	case "go":
+		if output, err := runTool(repoPath, "go", "mod", "tidy"); err != nil {
+			return output, err
+		}
+		return runTool(repoPath, "go", "build", "./...")
+	}
+	return "", nil
+}
+
+// securityFixCommitMessage lists the bumps and the vulnerabilities they fix
+func securityFixCommitMessage(fixes []plannedFix) string {
+	var sb strings.Builder
+	if len(fixes) == 1 {
+		sb.WriteString(fmt.Sprintf("Bump %s to %s to fix vulnerabilities\n\n", fixes[0].Name, fixes[0].Target))
+	} else {
+		sb.WriteString(fmt.Sprintf("Bump %d vulnerable dependencies\n\n", len(fixes)))
+	}
+	for _, fix := range fixes {
+		sb.WriteString(fmt.Sprintf("- %s %s -> %s", fix.Name, fix.Current, fix.Target))
+		if len(fix.CVEs) > 0 {
+			sb.WriteString(" (" + strings.Join(fix.CVEs, ", ") + ")")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// FixVulnerableDependencies bumps vulnerable direct dependencies to their fix versions on a
+// dedicated branch, verifies the build and commits the result (optionally pushing it).
+// The entry's snapshot allows the change to be rolled back like a housekeeping run. Stashed
+// uncommitted changes are restored on the original branch afterwards.
+func FixVulnerableDependencies(repoPath string, fixes []DependencyFix, opts SecurityFixOptions) ReportEntry {
+	if opts.Log == nil {
+		opts.Log = func(msg string) {
+			slog.Info(strings.TrimSpace(msg))
+		}
+	}
+	entry := fixVulnerableDependencies(repoPath, fixes, opts)
+	restoreStashedChanges(&entry.Snapshot, func(msg string) {
+		entry.Messages = append(entry.Messages, msg)
+		opts.Log(msg)
+	})
+	return entry
+}
+
+func fixVulnerableDependencies(repoPath string, fixes []DependencyFix, opts SecurityFixOptions) ReportEntry {
+	entry := ReportEntry{RepoPath: repoPath, Success: true}
+	log := opts.Log
+	captureLog := func(msg string) {
+		entry.Messages = append(entry.Messages, msg)
+		log(msg)
+	}
+	fail := func(msg string) ReportEntry {
+		captureLog("  [ERROR] " + msg)
+		entry.Success = false
+		return entry
+	}
+
+	branch := strings.TrimSpace(opts.Branch)
+	if branch == "" {
+		branch = "security-fix"
+	}
+
+	projectType, _ := detectProjectTypeAndFramework(repoPath)
+	planned, skipped := planDependencyFixes(repoPath, projectType, fixes)
+	for _, reason := range skipped {
+		captureLog("  [SKIP] " + reason)
+	}
+	if len(planned) == 0 {
+		captureLog("  Nothing to fix automatically.")
+		return entry
+	}
+
+	if err := captureOriginalState(repoPath, opts.RunID, &entry.Snapshot, captureLog); err != nil {
+		return fail(err.Error())
+	}
+
+	defaultBranch := getDefaultBranch(repoPath)
+	if err := runGitCommand(repoPath, "checkout", defaultBranch); err != nil {
+		return fail(fmt.Sprintf("Checkout %s failed: %v", defaultBranch, err))
+	}
+	if err := runGitCommand(repoPath, "pull"); err != nil {
+		captureLog(fmt.Sprintf("  [WARNING] Pull %s failed, fixing the local state: %v", defaultBranch, err))
+	}
+
+	created := !branchExists(repoPath, branch)
+	if err := checkoutOrCreateBranch(repoPath, branch, captureLog); err != nil {
+		return fail(err.Error())
+	}
+	entry.Snapshot.WorkBranch = branch
+	entry.Snapshot.WorkBranchCreated = created
+	entry.Snapshot.BaseHead, _ = gitOutput(repoPath, "rev-parse", "HEAD")
+
+	// discard reverts the working tree of the fix branch after a failed bump or build
+	discard := func() {
+		runGitCommand(repoPath, "reset", "--hard", "HEAD")
+		runGitCommand(repoPath, "clean", "-fd")
+	}
+
+	for _, fix := range planned {
+		captureLog(fmt.Sprintf("  Bumping %s %s -> %s...", fix.Name, fix.Current, fix.Target))
+		if output, err := applyDependencyFix(repoPath, projectType, fix, opts.Maven); err != nil {
+			discard()
+			return fail(fmt.Sprintf("Bumping %s failed: %v\nOutput:\n%s", fix.Name, err, output))
+		}
+	}
+
+	captureLog("  Verifying the build...")
+	if output, err := verifyBuild(repoPath, projectType, opts.Maven); err != nil {
+		discard()
+		return fail(fmt.Sprintf("Build failed after the bumps, changes discarded: %v\nOutput:\n%s", err, output))
+	}
+	captureLog("  Build successful.")
+
+	if err := runGitCommand(repoPath, "add", "-A"); err != nil {
+		return fail(fmt.Sprintf("git add failed: %v", err))
+	}
+	verb, err := newCommitQueue(CommitPerFile, opts.Signing).commit(repoPath, securityFixCommitMessage(planned))
+	if err != nil {
+		return fail(fmt.Sprintf("git commit failed: %v", err))
+	}
+	captureLog(fmt.Sprintf("  %d dependency bump(s) %s on '%s'.", len(planned), verb, branch))
+
+	if opts.Push {
+		remote := primaryRemoteOrOrigin(repoPath)
+		if err := retryGitCommand(repoPath, captureLog, "push", "-u", remote, branch); err != nil {
+			return fail(fmt.Sprintf("Push failed: %v", err))
+		}
+		captureLog(fmt.Sprintf("  Branch '%s' pushed to %s.", branch, remote))
+	}
+
+	captureLog(fmt.Sprintf("  %s fixed.", filepath.Base(repoPath)))
+	return entry
+}
@@ -0,0 +1,265 @@
+package logic
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// managedStashPrefix starts the message of every stash GitHousekeeper pushes (runs, scans, named stashes)
+const managedStashPrefix = "GitHousekeeper "
+
+// namedStashPrefix starts the message of a named stash, followed by the name
+const namedStashPrefix = managedStashPrefix + "stash: "
+
+// StashEntry is one entry of a repository's `git stash list`
+type StashEntry struct {
+	Ref     string    `json:"ref"`    // stash@{n}, shifts when newer stashes are dropped
+	Commit  string    `json:"commit"` // Stable identity of the stash
+	Branch  string    `json:"branch"` // Branch the stash was pushed on
+	Message string    `json:"message"`
+	Name    string    `json:"name,omitempty"` // Name of a named stash
+	Managed bool      `json:"managed"`        // Pushed by GitHousekeeper and not restored yet
+	Created time.Time `json:"created"`
+}
+
+// RepoStashes are the stashes of a repository
+type RepoStashes struct {
+	Name    string       `json:"name"`
+	Path    string       `json:"path"`
+	Stashes []StashEntry `json:"stashes"`
+	Error   string       `json:"error,omitempty"`
+}
+
+// ListStashes returns the stashes of a repository, newest first
+func ListStashes(path string) ([]StashEntry, error) {
+	out, err := gitOutput(path, "stash", "list", "--format=%gd%x1f%H%x1f%ct%x1f%gs")
+	if err != nil {
+		return nil, err
+	}
+	stashes := []StashEntry{}
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(line, "\x1f", 4)
+		if len(parts) != 4 {
+			continue
+		}
+		entry := StashEntry{Ref: parts[0], Commit: parts[1]}
+		if ts, err := strconv.ParseInt(parts[2], 10, 64); err == nil {
+			entry.Created = time.Unix(ts, 0)
+		}
+		// "On main: message" for stashes with a message, "WIP on main: <sha> <subject>" without
+		subject := strings.TrimPrefix(strings.TrimPrefix(parts[3], "WIP "), "On ")
+		if branch, message, ok := strings.Cut(subject, ": "); ok {
+			entry.Branch, entry.Message = branch, message
+		} else {
+			entry.Message = parts[3]
+		}
+		entry.Managed = strings.HasPrefix(entry.Message, managedStashPrefix)
+		entry.Name = strings.TrimPrefix(entry.Message, namedStashPrefix)
+		if entry.Name == entry.Message {
+			entry.Name = ""
+		}
+		stashes = append(stashes, entry)
+	}
+	return stashes, nil
+}
+
+// ListRepoStashes lists the stashes of every repository in parallel, in the order given. With
+// managedOnly only stashes pushed by GitHousekeeper are returned, repositories without any are left out.
+func ListRepoStashes(repos []string, managedOnly bool) []RepoStashes {
+	all := make([]RepoStashes, len(repos))
+	forEachRepo(repos, func(i int, repo string) {
+		result := RepoStashes{Name: filepath.Base(repo), Path: repo, Stashes: []StashEntry{}}
+		stashes, err := ListStashes(repo)
+		if err != nil {
+			result.Error = err.Error()
+		}
+		for _, s := range stashes {
+			if !managedOnly || s.Managed {
+				result.Stashes = append(result.Stashes, s)
+			}
+		}
+		all[i] = result
+	})
+
+	var results []RepoStashes
+	for _, r := range all {
+		if !managedOnly || len(r.Stashes) > 0 || r.Error != "" {
+			results = append(results, r)
+		}
+	}
+	return results
+}
+
+// Stash is a stash pushed by GitHousekeeper. It is found by its commit, so it is restored or dropped
+// even after other stashes were pushed on top of it. Whoever pushes one must restore or drop it and
+// report when that fails: the changes are still in the stash then and must not be forgotten.
+type Stash struct {
+	RepoPath string `json:"repoPath"`
+	Commit   string `json:"commit"`
+	Message  string `json:"message"`
+}
+
+// PushStash stashes the uncommitted changes of a repository, untracked files included. Message is
+// prefixed with "GitHousekeeper ". Without changes nothing is stashed and nil is returned.
+func PushStash(path, message string) (*Stash, error) {
+	changes, err := uncommittedChanges(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	message = managedStashPrefix + message
+	if err := runGitCommand(path, "stash", "push", "--include-untracked", "-m", message); err != nil {
+		return nil, fmt.Errorf("could not stash uncommitted changes: %v", err)
+	}
+	commit, err := gitOutput(path, "rev-parse", "stash@{0}")
+	if err != nil {
+		return nil, fmt.Errorf("changes stashed as '%s', but the stash cannot be read: %v", message, err)
+	}
+	return &Stash{RepoPath: path, Commit: commit, Message: message}, nil
+}
+
+// PushNamedStash stashes the uncommitted changes under a name, to restore or drop them by that name
+// later. A repository can hold one stash per name. Without changes nil is returned.
+func PushNamedStash(path, name string) (*Stash, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("stash name must not be empty")
+	}
+	existing, err := FindNamedStash(path, name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("a stash named '%s' already exists", name)
+	}
+	return PushStash(path, "stash: "+name)
+}
+
+// FindNamedStash returns the stash with the given name, nil if there is none
+func FindNamedStash(path, name string) (*Stash, error) {
+	stashes, err := ListStashes(path)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	for _, s := range stashes {
+		if s.Name == name && s.Managed {
+			return &Stash{RepoPath: path, Commit: s.Commit, Message: s.Message}, nil
+		}
+	}
+	return nil, nil
+}
+
+// ref returns the current stash@{n} of the stash
+func (s *Stash) ref() (string, error) {
+	stashes, err := ListStashes(s.RepoPath)
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range stashes {
+		if entry.Commit == s.Commit {
+			return entry.Ref, nil
+		}
+	}
+	return "", fmt.Errorf("stash '%s' (%s) no longer exists", s.Message, shortSHA(s.Commit))
+}
+
+// Restore applies the stash to the working tree and drops it. If applying fails (e.g. a conflict)
+// the stash is kept and the error says so.
+func (s *Stash) Restore() error {
+	ref, err := s.ref()
+	if err != nil {
+		return err
+	}
+	if err := runGitCommand(s.RepoPath, "stash", "pop", ref); err != nil {
+		return fmt.Errorf("restoring stash '%s' failed, the changes are kept in %s: %v", s.Message, ref, err)
+	}
+	return nil
+}
+
+// Drop deletes the stash without applying it
+func (s *Stash) Drop() error {
+	ref, err := s.ref()
+	if err != nil {
+		return err
+	}
+	return runGitCommand(s.RepoPath, "stash", "drop", ref)
+}
+
+// Operations on a named stash across repositories
+const (
+	StashCreate  = "create"
+	StashRestore = "restore"
+	StashDrop    = "drop"
+)
+
+// Outcomes of a named stash operation in a repository
+const (
+	StashStashed  = "stashed"
+	StashClean    = "clean" // Nothing to stash
+	StashRestored = "restored"
+	StashDropped  = "dropped"
+	StashMissing  = "missing" // No stash with the name
+	StashFailed   = "failed"
+)
+
+// StashResult is the outcome of a named stash operation in one repository
+type StashResult struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// NamedStashOperation creates, restores or drops the stash with the given name in every repository,
+// in parallel. A failure in one repository does not stop the others; the results tell which
+// repositories hold the stash, so none is left behind unnoticed.
+func NamedStashOperation(repos []string, operation, name string) ([]StashResult, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("stash name must not be empty")
+	}
+	if operation != StashCreate && operation != StashRestore && operation != StashDrop {
+		return nil, fmt.Errorf("unknown stash operation '%s', expected create, restore or drop", operation)
+	}
+
+	results := make([]StashResult, len(repos))
+	forEachRepo(repos, func(i int, repo string) {
+		result := StashResult{Name: filepath.Base(repo), Path: repo}
+		var err error
+		if operation == StashCreate {
+			var stash *Stash
+			if stash, err = PushNamedStash(repo, name); stash != nil {
+				result.Status = StashStashed
+			} else if err == nil {
+				result.Status = StashClean
+			}
+		} else {
+			var stash *Stash
+			if stash, err = FindNamedStash(repo, name); err == nil {
+				switch {
+				case stash == nil:
+					result.Status = StashMissing
+				case operation == StashRestore:
+					if err = stash.Restore(); err == nil {
+						result.Status = StashRestored
+					}
+				default:
+					if err = stash.Drop(); err == nil {
+						result.Status = StashDropped
+					}
+				}
+			}
+		}
+		if err != nil {
+			result.Status, result.Error = StashFailed, err.Error()
+		}
+		results[i] = result
+	})
+	return results, nil
+}
@@ -0,0 +1,87 @@
+package logic
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gorecode/updates/internal/logic/registry"
+)
+
+func init() {
+	// Registry metadata is cached next to the other state
+	registry.Default.CacheDir = func() (string, error) { return dataSubDir("registry-cache") }
+}
+
+// DataDirEnv overrides the directory where GitHousekeeper keeps its state (run history, profiles, ...)
+const DataDirEnv = "GITHOUSEKEEPER_HOME"
+
+// DataDir returns (and creates) the directory used for persistent state.
+// Defaults to <user config dir>/GitHousekeeper.
+func DataDir() (string, error) {
+	dir := os.Getenv(DataDirEnv)
+	if dir == "" {
+		base, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(base, "GitHousekeeper")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// dataSubDir returns (and creates) a sub-directory of DataDir
+func dataSubDir(name string) (string, error) {
+	base, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(base, name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// cacheFilePath returns the file for a cache key in a data sub-directory, or "" if the data dir is unavailable
+func cacheFilePath(subDir, key string) string {
+	dir, err := dataSubDir(subDir)
+	if err != nil {
+		return ""
+	}
+	sum := sha1.Sum([]byte(key))
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// cacheFresh reports whether a cache entry can be used; in offline mode expired entries are still served
+func cacheFresh(fetchedAt time.Time, ttl time.Duration) bool {
+	return registry.Default.Offline || time.Since(fetchedAt) < ttl
+}
+
+// writeJSONFile writes v as indented JSON via a temp file, so a crash never leaves a half-written file
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// readJSONFile reads a JSON file into v
+func readJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,79 @@
+package logic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SuppressionsFile is the accepted-risk list, relative to the scanned root path
+const SuppressionsFile = ".githousekeeper/suppressions.yaml"
+
+// Suppression accepts the risk of a vulnerability so it is no longer reported as actionable
+type Suppression struct {
+	ID            string `yaml:"id" json:"id"`                               // CVE, GHSA, PYSEC, ... (case-insensitive)
+	Package       string `yaml:"package,omitempty" json:"package,omitempty"` // Empty = every package with this ID
+	Expires       string `yaml:"expires,omitempty" json:"expires,omitempty"` // YYYY-MM-DD, the finding is reported again afterwards
+	Justification string `yaml:"justification" json:"justification"`         // Why the risk is accepted
+}
+
+// SuppressionList is the content of the suppressions file
+type SuppressionList struct {
+	Suppressions []Suppression `yaml:"suppressions"`
+}
+
+// LoadSuppressions reads <rootPath>/.githousekeeper/suppressions.yaml.
+// A missing file is an empty list; entries without ID or with an invalid date are an error.
+func LoadSuppressions(rootPath string) (*SuppressionList, error) {
+	list := &SuppressionList{}
+	data, err := os.ReadFile(filepath.Join(rootPath, SuppressionsFile))
+	if os.IsNotExist(err) {
+		return list, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, list); err != nil {
+		return nil, fmt.Errorf("%s: %v", SuppressionsFile, err)
+	}
+	for i, s := range list.Suppressions {
+		if strings.TrimSpace(s.ID) == "" {
+			return nil, fmt.Errorf("%s: entry %d has no id", SuppressionsFile, i+1)
+		}
+		if s.Expires != "" {
+			if _, err := time.Parse("2006-01-02", s.Expires); err != nil {
+				return nil, fmt.Errorf("%s: entry %d (%s) has an invalid expiry '%s', expected YYYY-MM-DD", SuppressionsFile, i+1, s.ID, s.Expires)
+			}
+		}
+	}
+	return list, nil
+}
+
+// Match returns the active suppression for a finding, or nil.
+// A suppression expires at the end of its expiry day.
+func (l *SuppressionList) Match(id, pkg string, now time.Time) *Suppression {
+	if l == nil {
+		return nil
+	}
+	for i := range l.Suppressions {
+		s := &l.Suppressions[i]
+		if !strings.EqualFold(strings.TrimSpace(s.ID), id) {
+			continue
+		}
+		if s.Package != "" && !strings.EqualFold(s.Package, pkg) {
+			continue
+		}
+		if s.Expires != "" {
+			expires, _ := time.ParseInLocation("2006-01-02", s.Expires, now.Location())
+			if !now.Before(expires.AddDate(0, 0, 1)) {
+				continue
+			}
+		}
+		return s
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package logic
+
+import (
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorecode/updates/internal/cmdlimit"
+	"github.com/gorecode/updates/internal/gitops"
+	"github.com/gorecode/updates/internal/logic/registry"
+)
+
+// TagSettings controls which tags count as release tags for the version bump
+type TagSettings struct {
+	Pattern string `json:"pattern"` // Glob passed to 'git tag --list', e.g. "v*" or "payment-service/*" (empty = all tags)
+	Prefix  string `json:"prefix"`  // Stripped before comparing with the pom version, e.g. "payment-service/v"
+}
+
+// releaseVersionPattern matches plain release versions (1.2 or 1.2.3), no pre-release or build suffixes
+var releaseVersionPattern = regexp.MustCompile(`^[0-9]+\.[0-9]+(\.[0-9]+)?$`)
+
+// getLatestTag returns the newest release tag of the repo, or "No Tags".
+// Tags are filtered by the configured glob and must look like a release version once the
+// prefix is stripped, so tags like "build-2024" or other modules' monorepo tags are ignored.
+// If no tag qualifies, 'git describe' semantics (nearest reachable tag) are used as fallback.
+func getLatestTag(path string, settings TagSettings) string {
+	if tags, err := gitops.Open(path).Tags(); err == nil {
+		var names []string
+		for _, tag := range tags {
+			if matchTagPattern(settings.Pattern, tag.Name) {
+				names = append(names, tag.Name)
+			}
+		}
+		// Newest version first; tags that are no release version keep their name order at the end
+		sort.SliceStable(names, func(i, j int) bool {
+			vi, vj := TagVersion(names[i], settings.Prefix), TagVersion(names[j], settings.Prefix)
+			if !releaseVersionPattern.MatchString(vi) || !releaseVersionPattern.MatchString(vj) {
+				return releaseVersionPattern.MatchString(vi) && !releaseVersionPattern.MatchString(vj)
+			}
+			return registry.CompareVersions(vi, vj) > 0
+		})
+		if tag := selectReleaseTag(names, settings.Prefix); tag != "" {
+			return tag
+		}
+	}
+
+	// Fallback: nearest tag reachable from HEAD, if it is a release version
+	describeArgs := []string{"describe", "--tags", "--abbrev=0"}
+	if settings.Pattern != "" {
+		describeArgs = append(describeArgs, "--match", settings.Pattern)
+	}
+	cmd := exec.Command("git", describeArgs...)
+	cmd.Dir = path
+	output, err := cmdlimit.Output(cmd)
+	if err == nil {
+		if tag := selectReleaseTag([]string{string(output)}, settings.Prefix); tag != "" {
+			return tag
+		}
+	}
+
+	return "No Tags"
+}
+
+// matchTagPattern matches a tag against the glob of 'git tag --list', where * also matches "/"
+func matchTagPattern(pattern, tag string) bool {
+	if pattern == "" {
+		return true
+	}
+	// path.Match stops * at "/", so slashes are swapped for a byte tags cannot contain
+	matched, err := path.Match(strings.ReplaceAll(pattern, "/", "\x00"), strings.ReplaceAll(tag, "/", "\x00"))
+	return err == nil && matched
+}
+
+// selectReleaseTag returns the first tag (input is sorted newest first) that is a release version
+func selectReleaseTag(tags []string, prefix string) string {
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		if prefix != "" && !strings.HasPrefix(tag, prefix) {
+			continue
+		}
+		if releaseVersionPattern.MatchString(TagVersion(tag, prefix)) {
+			return tag
+		}
+	}
+	return ""
+}
+
+// TagVersion strips the configured prefix and a leading "v" from a tag ("svc/v1.2.3" -> "1.2.3")
+func TagVersion(tag, prefix string) string {
+	if tag == "No Tags" {
+		return tag
+	}
+	version := strings.TrimPrefix(tag, prefix)
+	return strings.TrimPrefix(version, "v")
+}
+
+// DefaultTagTemplate names the tag of a housekeeping run if no template is configured
+const DefaultTagTemplate = "housekeeping-{yyyy}-{mm}"
+
+// CreateTagSettings controls the annotated tag created on the result of a housekeeping run
+type CreateTagSettings struct {
+	Enabled  bool   `json:"enabled"`
+	Template string `json:"template"` // Placeholders: {yyyy} {mm} {dd} {repo} {branch} {run}
+	Message  string `json:"message"`  // Tag annotation, same placeholders (empty = "Housekeeping run {run}")
+	Push     bool   `json:"push"`     // Push the tag to origin
+}
+
+// expandTagTemplate fills the placeholders of a tag name or message
+func expandTagTemplate(template, repoPath, branch, runID string, now time.Time) string {
+	return strings.NewReplacer(
+		"{yyyy}", now.Format("2006"),
+		"{mm}", now.Format("01"),
+		"{dd}", now.Format("02"),
+		"{repo}", filepath.Base(repoPath),
+		"{branch}", strings.ReplaceAll(branch, "/", "-"),
+		"{run}", runID,
+	).Replace(template)
+}
+
+// ValidateTagTemplate checks that the template expands to a valid tag name
+func ValidateTagTemplate(template string) error {
+	if strings.TrimSpace(template) == "" {
+		template = DefaultTagTemplate
+	}
+	name := expandTagTemplate(template, "repo", "branch", "20060102-150405-abcd", time.Now())
+	if err := cmdlimit.Run(exec.Command("git", "check-ref-format", "refs/tags/"+name)); err != nil {
+		return fmt.Errorf("'%s' is not a valid tag name", name)
+	}
+	return nil
+}
+
+// createRunTag creates the annotated (and, with signing, signed) tag on HEAD and optionally pushes it.
+// Returns the tag name and whether it was pushed; an existing tag is left alone.
+func createRunTag(repoPath string, settings CreateTagSettings, signing SigningSettings, runID string, log func(string)) (string, bool) {
+	template := strings.TrimSpace(settings.Template)
+	if template == "" {
+		template = DefaultTagTemplate
+	}
+	message := settings.Message
+	if strings.TrimSpace(message) == "" {
+		message = "Housekeeping run {run}"
+	}
+	branch := currentBranch(repoPath)
+	now := time.Now()
+	name := expandTagTemplate(template, repoPath, branch, runID, now)
+	message = expandTagTemplate(message, repoPath, branch, runID, now)
+
+	if runGitCommand(repoPath, "show-ref", "--verify", "--quiet", "refs/tags/"+name) == nil {
+		log(fmt.Sprintf("  [WARNING] Tag '%s' already exists, not tagged again.", name))
+		return "", false
+	}
+
+	args := []string{"tag", "-a", name, "-m", message}
+	if signing.Enabled {
+		args = append(signing.configArgs(), "tag", "-s", name, "-m", message)
+	}
+	if err := runGitCommand(repoPath, args...); err != nil {
+		log(fmt.Sprintf("  [ERROR] Creating tag '%s' failed: %v", name, err))
+		return "", false
+	}
+	log(fmt.Sprintf("  Tag '%s' created on %s.", name, branch))
+
+	if !settings.Push {
+		return name, false
+	}
+	remote := primaryRemoteOrOrigin(repoPath)
+	if err := retryGitCommand(repoPath, log, "push", remote, "refs/tags/"+name); err != nil {
+		log(fmt.Sprintf("  [ERROR] Pushing tag '%s' failed: %v", name, err))
+		return name, false
+	}
+	log(fmt.Sprintf("  Tag '%s' pushed to %s.", name, remote))
+	return name, true
+}
+
+// RepoTagInfo shows the latest release tag of a repo and how far HEAD has moved since
+type RepoTagInfo struct {
+	RepoPath        string    `json:"repoPath"`
+	RepoName        string    `json:"repoName"`
+	Branch          string    `json:"branch"`
+	LatestTag       string    `json:"latestTag"` // "" if the repo has no matching tag
+	TagDate         time.Time `json:"tagDate,omitempty"`
+	CommitsSinceTag int       `json:"commitsSinceTag"` // Commits on HEAD not contained in the tag (drift)
+	RecentTags      []string  `json:"recentTags"`      // Newest tags matching the pattern, newest first
+	Error           string    `json:"error,omitempty"`
+}
+
+// ListRepoTags reports the latest tags of a repo and the tag-to-HEAD drift
+func ListRepoTags(repoPath string, settings TagSettings) RepoTagInfo {
+	info := RepoTagInfo{RepoPath: repoPath, RepoName: filepath.Base(repoPath), RecentTags: []string{}}
+	info.Branch = currentBranch(repoPath)
+
+	tags, err := gitops.Open(repoPath).Tags()
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	dates := make(map[string]time.Time)
+	var names []string
+	for _, tag := range tags {
+		dates[tag.Name] = tag.Date
+		if matchTagPattern(settings.Pattern, tag.Name) {
+			names = append(names, tag.Name)
+		}
+	}
+	sort.SliceStable(names, func(i, j int) bool { return dates[names[i]].After(dates[names[j]]) })
+	for _, name := range names {
+		if len(info.RecentTags) == 5 {
+			break
+		}
+		info.RecentTags = append(info.RecentTags, name)
+	}
+
+	latest := getLatestTag(repoPath, settings)
+	if latest == "No Tags" {
+		return info
+	}
+	info.LatestTag = latest
+	info.TagDate = dates[latest]
+	if count, err := gitOutput(repoPath, "rev-list", "--count", latest+"..HEAD"); err == nil {
+		info.CommitsSinceTag, _ = strconv.Atoi(count)
+	}
+	return info
+}
@@ -0,0 +1,274 @@
+package logic
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Pinning of a Terraform provider or module version
+const (
+	TerraformPinExact    = "exact"    // "= 5.31.0", "5.31.0", a module git tag or commit
+	TerraformPinRange    = "range"    // "~> 5.31", ">= 5.0, < 6.0": upgrades within the range
+	TerraformPinUnpinned = "unpinned" // No constraint, a lower bound only or a module from a branch
+)
+
+// TerraformPin is a provider requirement or module call and how strictly its version is pinned
+type TerraformPin struct {
+	Kind    string `json:"kind"` // "provider" or "module"
+	Name    string `json:"name"`
+	Source  string `json:"source,omitempty"`
+	Version string `json:"version,omitempty"` // Version constraint, for git modules the ref
+	File    string `json:"file"`              // Relative to the repo
+	Pinning string `json:"pinning"`           // TerraformPinExact, TerraformPinRange or TerraformPinUnpinned
+}
+
+// TerraformInventory lists the Terraform configurations of a repo with their providers and modules
+type TerraformInventory struct {
+	Dirs             []string       `json:"dirs"`                       // Directories with .tf files, relative to the repo
+	Unlocked         []string       `json:"unlocked,omitempty"`         // Directories requiring providers without .terraform.lock.hcl
+	RequiredVersions []string       `json:"requiredVersions,omitempty"` // required_version constraints of Terraform itself
+	Providers        []TerraformPin `json:"providers,omitempty"`
+	Modules          []TerraformPin `json:"modules,omitempty"`
+}
+
+// Unpinned counts the providers and modules without an upper version bound
+func (t TerraformInventory) Unpinned() int {
+	count := 0
+	for _, pins := range [][]TerraformPin{t.Providers, t.Modules} {
+		for _, pin := range pins {
+			if pin.Pinning == TerraformPinUnpinned {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+var (
+	terraformBlockPattern      = regexp.MustCompile(`(?m)^\s*(terraform|required_providers|module\s+"([^"]+)"|provider\s+"([^"]+)")\s*\{`)
+	terraformAttributePattern  = regexp.MustCompile(`(?m)^\s*([\w-]+)\s*=\s*"([^"]*)"`)
+	terraformProviderPattern   = regexp.MustCompile(`(?m)^\s*([\w-]+)\s*=\s*\{([^}]*)\}`)
+	terraformGitRefPattern     = regexp.MustCompile(`[?&]ref=([^&]+)`)
+	terraformVersionRefPattern = regexp.MustCompile(`^(v?\d+(\.\d+)*([-+.][\w.]+)?|[0-9a-f]{40})$`)
+	terraformRegistryPattern   = regexp.MustCompile(`^([\w.-]+/)?[\w-]+/[\w-]+/[\w-]+$`)
+)
+
+// FindTerraform parses the .tf files of a repo for provider requirements and module calls; nil when
+// the repo has none. Provider caches (.terraform) are skipped.
+func FindTerraform(repoPath string) *TerraformInventory {
+	var inventory TerraformInventory
+	dirs := make(map[string]bool)
+	requiring := make(map[string]bool) // Directories with providers, they need a lock file
+	filepath.Walk(repoPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if path != repoPath && (dockerfileSkipDirs[info.Name()] || info.Name() == ".terraform") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(info.Name()) != ".tf" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(repoPath, path)
+		rel = filepath.ToSlash(rel)
+		dir := filepath.ToSlash(filepath.Dir(rel))
+		if !dirs[dir] {
+			dirs[dir] = true
+			inventory.Dirs = append(inventory.Dirs, dir)
+		}
+		providers, modules, required := parseTerraformFile(string(data), rel)
+		inventory.Providers = append(inventory.Providers, providers...)
+		inventory.Modules = append(inventory.Modules, modules...)
+		inventory.RequiredVersions = append(inventory.RequiredVersions, required...)
+		if len(providers) > 0 {
+			requiring[dir] = true
+		}
+		return nil
+	})
+	if len(inventory.Dirs) == 0 {
+		return nil
+	}
+	for _, dir := range inventory.Dirs {
+		if _, err := os.Stat(filepath.Join(repoPath, filepath.FromSlash(dir), ".terraform.lock.hcl")); requiring[dir] && err != nil {
+			inventory.Unlocked = append(inventory.Unlocked, dir)
+		}
+	}
+	return &inventory
+}
+
+// parseTerraformFile returns the required providers, the module calls and the required_version
+// constraints of a .tf file. Providers configured without requirement are unpinned.
+func parseTerraformFile(content, file string) (providers, modules []TerraformPin, requiredVersions []string) {
+	content = stripTerraformComments(content)
+	required := make(map[string]bool)
+	var configured []string
+	for _, m := range terraformBlockPattern.FindAllStringSubmatchIndex(content, -1) {
+		header := content[m[2]:m[3]]
+		body := terraformBlockBody(content, m[1])
+		switch {
+		case header == "terraform":
+			if version := terraformAttribute(topLevelTerraform(body), "required_version"); version != "" {
+				requiredVersions = append(requiredVersions, version)
+			}
+		case header == "required_providers":
+			for _, p := range terraformProviderPattern.FindAllStringSubmatch(body, -1) {
+				required[p[1]] = true
+				version := terraformAttribute(p[2], "version")
+				providers = append(providers, TerraformPin{Kind: "provider", Name: p[1], Source: terraformAttribute(p[2], "source"),
+					Version: version, File: file, Pinning: terraformConstraintPinning(version)})
+			}
+			// Terraform 0.12 shorthand: aws = "~> 3.0"
+			for _, p := range terraformAttributePattern.FindAllStringSubmatch(topLevelTerraform(body), -1) {
+				if !required[p[1]] {
+					required[p[1]] = true
+					providers = append(providers, TerraformPin{Kind: "provider", Name: p[1], Version: p[2], File: file, Pinning: terraformConstraintPinning(p[2])})
+				}
+			}
+		case m[4] >= 0:
+			if pin, ok := terraformModulePin(content[m[4]:m[5]], topLevelTerraform(body), file); ok {
+				modules = append(modules, pin)
+			}
+		case m[6] >= 0:
+			configured = append(configured, content[m[6]:m[7]])
+		}
+	}
+	for _, name := range configured {
+		if !required[name] {
+			required[name] = true
+			providers = append(providers, TerraformPin{Kind: "provider", Name: name, File: file, Pinning: TerraformPinUnpinned})
+		}
+	}
+	return providers, modules, requiredVersions
+}
+
+// terraformModulePin classifies the version of a module call; local modules are not versioned
+func terraformModulePin(name, body, file string) (TerraformPin, bool) {
+	source := terraformAttribute(body, "source")
+	if source == "" || strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../") {
+		return TerraformPin{}, false
+	}
+	pin := TerraformPin{Kind: "module", Name: name, Source: source, File: file}
+	switch {
+	case strings.HasPrefix(source, "git::") || strings.HasPrefix(source, "github.com/") || strings.HasPrefix(source, "bitbucket.org/") || strings.Contains(source, ".git"):
+		pin.Pinning = TerraformPinUnpinned
+		if m := terraformGitRefPattern.FindStringSubmatch(source); m != nil {
+			pin.Version = m[1]
+			if terraformVersionRefPattern.MatchString(m[1]) {
+				pin.Pinning = TerraformPinExact
+			}
+		}
+	case terraformRegistryPattern.MatchString(source):
+		pin.Version = terraformAttribute(body, "version")
+		pin.Pinning = terraformConstraintPinning(pin.Version)
+	default:
+		return TerraformPin{}, false // Archives and buckets carry no version Terraform knows of
+	}
+	return pin, true
+}
+
+// terraformConstraintPinning classifies a version constraint: exact versions, ranges with an upper
+// bound (~>, <) and open ones that let terraform init pick any future major version
+func terraformConstraintPinning(constraint string) string {
+	if strings.TrimSpace(constraint) == "" {
+		return TerraformPinUnpinned
+	}
+	exact, bounded := true, false
+	for _, part := range strings.Split(constraint, ",") {
+		part = strings.TrimSpace(part)
+		switch {
+		case strings.HasPrefix(part, "~>") || strings.HasPrefix(part, "<"):
+			exact, bounded = false, true
+		case strings.HasPrefix(part, ">") || strings.HasPrefix(part, "!="):
+			exact = false
+		case strings.HasPrefix(part, "="):
+			bounded = true
+		default:
+			bounded = true // A bare version is exact
+		}
+	}
+	switch {
+	case exact:
+		return TerraformPinExact
+	case bounded:
+		return TerraformPinRange
+	}
+	return TerraformPinUnpinned
+}
+
+// stripTerraformComments removes line comments (#, //) and block comments; comment markers inside
+// strings are rare enough in the blocks read here to be ignored
+func stripTerraformComments(content string) string {
+	for {
+		start := strings.Index(content, "/*")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(content[start:], "*/")
+		if end < 0 {
+			content = content[:start]
+			break
+		}
+		content = content[:start] + content[start+end+2:]
+	}
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//") {
+			lines[i] = ""
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// terraformBlockBody returns the content of the block whose opening brace ends at start
+func terraformBlockBody(content string, start int) string {
+	depth := 1
+	for i := start; i < len(content); i++ {
+		switch content[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return content[start:i]
+			}
+		}
+	}
+	return content[start:]
+}
+
+// topLevelTerraform drops the nested blocks of a block body, so that their attributes (e.g. the
+// version of a required provider) are not taken for the block's own
+func topLevelTerraform(body string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range body {
+		switch {
+		case r == '{':
+			depth++
+		case r == '}':
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// terraformAttribute returns the string value of an attribute, "" if not set
+func terraformAttribute(body, name string) string {
+	for _, m := range terraformAttributePattern.FindAllStringSubmatch(body, -1) {
+		if m[1] == name {
+			return m[2]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,118 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Steps of a repo that can time out, as reported in ReportEntry.TimedOut
+const (
+	StepGit          = "git"          // git fetch and pull
+	StepMaven        = "maven"        // Maven build, build command of .githousekeeper.yaml or deprecation check
+	StepReplacements = "replacements" // Project-wide replacements
+	StepRepo         = "repo"         // Everything done for one repo
+)
+
+// StepTimeouts limits how long the steps of a run may take per repo, in minutes; 0 = no limit.
+// A repo exceeding a limit fails with a timeout and the run continues with the next repo.
+type StepTimeouts struct {
+	GitMinutes          int `json:"gitMinutes"`          // Each git fetch and pull
+	MavenMinutes        int `json:"mavenMinutes"`        // Each build
+	ReplacementsMinutes int `json:"replacementsMinutes"` // All project-wide replacements of a repo
+	RepoMinutes         int `json:"repoMinutes"`         // The whole repo, caps every step
+}
+
+// Validate rejects negative limits
+func (t StepTimeouts) Validate() error {
+	for step, minutes := range map[string]int{StepGit: t.GitMinutes, StepMaven: t.MavenMinutes, StepReplacements: t.ReplacementsMinutes, StepRepo: t.RepoMinutes} {
+		if minutes < 0 {
+			return fmt.Errorf("invalid %s timeout %d, expected minutes or 0 for no limit", step, minutes)
+		}
+	}
+	return nil
+}
+
+func (t StepTimeouts) limit(step string) time.Duration {
+	minutes := 0
+	switch step {
+	case StepGit:
+		minutes = t.GitMinutes
+	case StepMaven:
+		minutes = t.MavenMinutes
+	case StepReplacements:
+		minutes = t.ReplacementsMinutes
+	case StepRepo:
+		minutes = t.RepoMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// TimeoutError is returned by a step that was stopped because it exceeded its limit
+type TimeoutError struct {
+	Step  string
+	Limit time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Step == StepRepo {
+		return fmt.Sprintf("repository timeout of %s exceeded", e.Limit)
+	}
+	return fmt.Sprintf("%s timed out after %s", e.Step, e.Limit)
+}
+
+// stepTimer hands out the contexts of the steps of one repo
+type stepTimer struct {
+	timeouts StepTimeouts
+	deadline time.Time // Of the whole repo, zero without a limit
+	timedOut string    // First step that exceeded its limit
+}
+
+func newStepTimer(timeouts StepTimeouts) *stepTimer {
+	t := &stepTimer{timeouts: timeouts}
+	if limit := timeouts.limit(StepRepo); limit > 0 {
+		t.deadline = time.Now().Add(limit)
+	}
+	return t
+}
+
+// context limits a step to its own timeout and the time left for the repo
+func (t *stepTimer) context(step string) (context.Context, context.CancelFunc) {
+	deadline := t.deadline
+	if limit := t.timeouts.limit(step); limit > 0 {
+		if stepDeadline := time.Now().Add(limit); deadline.IsZero() || stepDeadline.Before(deadline) {
+			deadline = stepDeadline
+		}
+	}
+	if deadline.IsZero() {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithDeadline(context.Background(), deadline)
+}
+
+// check turns the error of a step whose context ran out into a *TimeoutError and records the timeout
+func (t *stepTimer) check(ctx context.Context, step string, err error) error {
+	if ctx.Err() != context.DeadlineExceeded {
+		return err
+	}
+	if t.expired() {
+		step = StepRepo
+	}
+	if t.timedOut == "" {
+		t.timedOut = step
+	}
+	return &TimeoutError{Step: step, Limit: t.timeouts.limit(step)}
+}
+
+// expired reports whether the repo has used up its time
+func (t *stepTimer) expired() bool {
+	return !t.deadline.IsZero() && !time.Now().Before(t.deadline)
+}
+
+// expire records a repo timeout noticed between steps and returns its error
+func (t *stepTimer) expire() error {
+	if t.timedOut == "" {
+		t.timedOut = StepRepo
+	}
+	return &TimeoutError{Step: StepRepo, Limit: t.timeouts.limit(StepRepo)}
+}
@@ -0,0 +1,137 @@
+package logic
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Environment variables enabling HTTPS
+const (
+	TLSCertEnv       = "GITHOUSEKEEPER_TLS_CERT"        // PEM certificate (chain) file
+	TLSKeyEnv        = "GITHOUSEKEEPER_TLS_KEY"         // PEM private key file
+	TLSSelfSignedEnv = "GITHOUSEKEEPER_TLS_SELF_SIGNED" // "true" = generate a self-signed certificate in the data dir
+)
+
+const selfSignedValidity = 365 * 24 * time.Hour
+
+// TLSConfigFromEnv returns the server TLS configuration, or nil to serve plain HTTP.
+// Certificate files take precedence over the self-signed certificate; hosts are added as
+// subject alternative names of a newly generated one (localhost and loopback are always included).
+func TLSConfigFromEnv(hosts []string) (*tls.Config, error) {
+	certFile, keyFile := os.Getenv(TLSCertEnv), os.Getenv(TLSKeyEnv)
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, fmt.Errorf("%s and %s must be set together", TLSCertEnv, TLSKeyEnv)
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading TLS certificate: %v", err)
+		}
+		return newTLSConfig(cert), nil
+	}
+
+	switch strings.ToLower(os.Getenv(TLSSelfSignedEnv)) {
+	case "", "false", "0":
+		return nil, nil
+	case "true", "1":
+	default:
+		return nil, fmt.Errorf("invalid %s '%s', expected true or false", TLSSelfSignedEnv, os.Getenv(TLSSelfSignedEnv))
+	}
+	dir, err := dataSubDir("tls")
+	if err != nil {
+		return nil, err
+	}
+	cert, err := LoadOrCreateSelfSigned(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"), hosts)
+	if err != nil {
+		return nil, err
+	}
+	return newTLSConfig(cert), nil
+}
+
+func newTLSConfig(cert tls.Certificate) *tls.Config {
+	return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+}
+
+// LoadOrCreateSelfSigned loads the certificate stored at certFile/keyFile and generates a new one
+// if it is missing, expires within 30 days or does not cover all hosts
+func LoadOrCreateSelfSigned(certFile, keyFile string, hosts []string) (tls.Certificate, error) {
+	names := []string{"localhost", "127.0.0.1", "::1"}
+	for _, host := range hosts {
+		// Wildcard listen addresses ("", 0.0.0.0, ::) are no names a client connects to
+		if ip := net.ParseIP(host); host != "" && (ip == nil || !ip.IsUnspecified()) {
+			names = append(names, host)
+		}
+	}
+	hosts = names
+	if cert, err := tls.LoadX509KeyPair(certFile, keyFile); err == nil && cert.Leaf != nil {
+		valid := time.Now().Add(30 * 24 * time.Hour).Before(cert.Leaf.NotAfter)
+		for _, host := range hosts {
+			if cert.Leaf.VerifyHostname(host) != nil {
+				valid = false
+			}
+		}
+		if valid {
+			return cert, nil
+		}
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "GitHousekeeper", Organization: []string{"GitHousekeeper (self-signed)"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	seen := make(map[string]bool)
+	for _, host := range hosts {
+		if seen[host] {
+			continue
+		}
+		seen[host] = true
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(certFile, certPEM, 0644); err != nil {
+		return tls.Certificate{}, err
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.X509KeyPair(certPEM, keyPEM)
+}
@@ -0,0 +1,359 @@
+package logic
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorecode/updates/internal/cmdlimit"
+)
+
+// InstallableTools are the scanners InstallTool can install
+var InstallableTools = []string{"govulncheck", "trivy", "pip-audit"}
+
+// toolInstallTimeout bounds one installation step, e.g. compiling govulncheck or a download
+const toolInstallTimeout = 10 * time.Minute
+
+// trivyReleaseURL is the GitHub API of trivy's latest release, replaced in tests
+var trivyReleaseURL = "https://api.github.com/repos/aquasecurity/trivy/releases/latest"
+
+// installMu runs one installation at a time, package managers do not like running twice
+var installMu sync.Mutex
+
+// ToolInstallResult tells how a tool was installed and whether it is usable now
+type ToolInstallResult struct {
+	Tool    string     `json:"tool"`
+	Method  string     `json:"method"` // "go install", "brew", "choco", "download" or "pip"
+	Log     []string   `json:"log"`    // Commands run and their outcome
+	Status  ToolStatus `json:"status"` // The tool after the installation
+	Success bool       `json:"success"`
+}
+
+// toolCacheBin returns (and creates) the folder programs installed by GitHousekeeper go to
+func toolCacheBin() (string, error) {
+	return dataSubDir(filepath.Join("tools", "bin"))
+}
+
+// AddToolCacheToPath appends the tool cache to the PATH of the server, so scanners installed by
+// GitHousekeeper are found. Tools installed on the system keep precedence.
+func AddToolCacheToPath() {
+	dir, err := toolCacheBin()
+	if err != nil {
+		return
+	}
+	if !slices.Contains(filepath.SplitList(os.Getenv("PATH")), dir) {
+		os.Setenv("PATH", os.Getenv("PATH")+string(os.PathListSeparator)+dir)
+	}
+}
+
+// InstallTool installs govulncheck (go install), trivy (brew or choco, otherwise the release binary
+// of GitHub, checksum verified) or pip-audit (in a virtual environment of its own) into the tool
+// cache of the data directory, nothing is installed system-wide without a package manager.
+func InstallTool(name string) (ToolInstallResult, error) {
+	if !slices.Contains(InstallableTools, name) {
+		return ToolInstallResult{}, fmt.Errorf("%s cannot be installed automatically, supported are %s", name, strings.Join(InstallableTools, ", "))
+	}
+	installMu.Lock()
+	defer installMu.Unlock()
+
+	bin, err := toolCacheBin()
+	if err != nil {
+		return ToolInstallResult{}, err
+	}
+	result := ToolInstallResult{Tool: name, Log: []string{}}
+	log := func(format string, args ...any) { result.Log = append(result.Log, fmt.Sprintf(format, args...)) }
+
+	switch name {
+	case "govulncheck":
+		result.Method = "go install"
+		err = installWithGo(bin, log)
+	case "trivy":
+		err = installTrivy(bin, &result.Method, log)
+	case "pip-audit":
+		result.Method = "pip"
+		err = installPipAudit(bin, log)
+	}
+	if err != nil {
+		log("Installation failed: %v", err)
+	}
+
+	AddToolCacheToPath()
+	result.Status = inspectTool(*findToolSpec(name))
+	result.Success = err == nil && result.Status.Available
+	if err == nil && !result.Status.Available {
+		log("%s was installed but is still not found on the PATH.", name)
+	}
+	return result, nil
+}
+
+// runInstallStep runs one command of an installation and logs it
+func runInstallStep(log func(string, ...any), env []string, name string, args ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), toolInstallTimeout)
+	defer cancel()
+	cmd := programCommand(ctx, name, args...)
+	cmd.WaitDelay = killWaitDelay
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	log("$ %s %s", name, strings.Join(args, " "))
+	output, err := cmdlimit.CombinedOutput(cmd)
+	if err != nil {
+		return fmt.Errorf("%s failed: %v: %s", name, err, truncateOutput(strings.TrimSpace(string(output)), 500))
+	}
+	return nil
+}
+
+// truncateOutput shortens command output for the installation log
+func truncateOutput(output string, max int) string {
+	if len(output) <= max {
+		return output
+	}
+	return "..." + output[len(output)-max:]
+}
+
+// installWithGo builds govulncheck with the installed Go into the tool cache
+func installWithGo(bin string, log func(string, ...any)) error {
+	if _, err := exec.LookPath("go"); err != nil {
+		return fmt.Errorf("govulncheck is built with Go, which is not installed. %s", InstallHint("go"))
+	}
+	return runInstallStep(log, []string{"GOBIN=" + bin}, "go", "install", "golang.org/x/vuln/cmd/govulncheck@latest")
+}
+
+// installTrivy uses the package manager of the system if there is one and downloads the release
+// binary otherwise, or when the package manager fails
+func installTrivy(bin string, method *string, log func(string, ...any)) error {
+	var packageManager []string
+	if _, err := exec.LookPath("brew"); err == nil {
+		packageManager = []string{"brew", "install", "trivy"}
+	} else if _, err := exec.LookPath("choco"); err == nil && isWindows() {
+		packageManager = []string{"choco", "install", "trivy", "-y", "--no-progress"}
+	}
+	if packageManager != nil {
+		*method = packageManager[0]
+		err := runInstallStep(log, nil, packageManager[0], packageManager[1:]...)
+		if err == nil {
+			return nil
+		}
+		log("%v, downloading the release instead", err)
+	}
+	*method = "download"
+	return downloadTrivy(bin, runtime.GOOS, runtime.GOARCH, log)
+}
+
+// trivyAssetName returns the archive of a trivy release for an OS and architecture, e.g.
+// trivy_0.50.1_Linux-64bit.tar.gz
+func trivyAssetName(version, goos, goarch string) (string, error) {
+	osNames := map[string]string{"linux": "Linux", "darwin": "macOS", "windows": "windows", "freebsd": "FreeBSD"}
+	archNames := map[string]string{"amd64": "64bit", "arm64": "ARM64", "386": "32bit", "arm": "ARM"}
+	osName, archName := osNames[goos], archNames[goarch]
+	if osName == "" || archName == "" {
+		return "", fmt.Errorf("trivy has no release for %s/%s", goos, goarch)
+	}
+	ext := ".tar.gz"
+	if goos == "windows" {
+		ext = ".zip"
+	}
+	return fmt.Sprintf("trivy_%s_%s-%s%s", version, osName, archName, ext), nil
+}
+
+// downloadTrivy downloads the latest trivy release, verifies it against the published checksums
+// and puts the binary into bin
+func downloadTrivy(bin, goos, goarch string, log func(string, ...any)) error {
+	client := &http.Client{Timeout: toolInstallTimeout}
+	var release struct {
+		TagName string `json:"tag_name"`
+		Assets  []struct {
+			Name string `json:"name"`
+			URL  string `json:"browser_download_url"`
+		} `json:"assets"`
+	}
+	data, err := httpGetBytes(client, trivyReleaseURL)
+	if err != nil {
+		return fmt.Errorf("could not read the latest trivy release: %v", err)
+	}
+	if err := json.Unmarshal(data, &release); err != nil {
+		return fmt.Errorf("could not read the latest trivy release: %v", err)
+	}
+	version := strings.TrimPrefix(release.TagName, "v")
+	archiveName, err := trivyAssetName(version, goos, goarch)
+	if err != nil {
+		return err
+	}
+	assetURL := func(name string) string {
+		for _, a := range release.Assets {
+			if a.Name == name {
+				return a.URL
+			}
+		}
+		return ""
+	}
+	archiveURL, checksumsURL := assetURL(archiveName), assetURL(fmt.Sprintf("trivy_%s_checksums.txt", version))
+	if archiveURL == "" || checksumsURL == "" {
+		return fmt.Errorf("release %s has no %s or no checksums", release.TagName, archiveName)
+	}
+
+	log("Downloading %s", archiveURL)
+	archive, err := httpGetBytes(client, archiveURL)
+	if err != nil {
+		return err
+	}
+	checksums, err := httpGetBytes(client, checksumsURL)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(archive)
+	if want := checksumOf(string(checksums), archiveName); want == "" || want != hex.EncodeToString(sum[:]) {
+		return fmt.Errorf("checksum of %s does not match the published one, the download is not used", archiveName)
+	}
+	log("Checksum verified (sha256 %s)", hex.EncodeToString(sum[:]))
+
+	binary := "trivy"
+	if goos == "windows" {
+		binary = "trivy.exe"
+	}
+	content, err := extractFile(archive, archiveName, binary)
+	if err != nil {
+		return err
+	}
+	if err := writeExecutable(filepath.Join(bin, binary), content); err != nil {
+		return err
+	}
+	log("Installed trivy %s to %s", version, filepath.Join(bin, binary))
+	return nil
+}
+
+// httpGetBytes downloads a URL, failing on other statuses than 200
+func httpGetBytes(client *http.Client, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// checksumOf returns the sha256 of a file from a sha256sum listing ("<hash>  <name>" per line)
+func checksumOf(listing, name string) string {
+	for _, line := range strings.Split(listing, "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0])
+		}
+	}
+	return ""
+}
+
+// extractFile returns the file with the given base name from a .tar.gz or .zip archive
+func extractFile(archive []byte, archiveName, name string) ([]byte, error) {
+	if strings.HasSuffix(archiveName, ".zip") {
+		zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range zr.File {
+			if path.Base(f.Name) == name {
+				rc, err := f.Open()
+				if err != nil {
+					return nil, err
+				}
+				defer rc.Close()
+				return io.ReadAll(rc)
+			}
+		}
+		return nil, fmt.Errorf("%s contains no %s", archiveName, name)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%s contains no %s", archiveName, name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag == tar.TypeReg && path.Base(header.Name) == name {
+			return io.ReadAll(tr)
+		}
+	}
+}
+
+// writeExecutable replaces path with an executable file, never leaving a half-written one behind
+func writeExecutable(path string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// installPipAudit installs pip-audit into a virtual environment of the tool cache and links it
+// into bin, so the system Python stays untouched
+func installPipAudit(bin string, log func(string, ...any)) error {
+	python := ""
+	for _, candidate := range []string{"python3", "python", "py"} {
+		if _, err := exec.LookPath(candidate); err == nil {
+			python = candidate
+			break
+		}
+	}
+	if python == "" {
+		return fmt.Errorf("pip-audit needs Python, which is not installed. Install Python 3 from https://www.python.org/downloads/")
+	}
+
+	venv := filepath.Join(filepath.Dir(bin), "pip-audit")
+	if err := runInstallStep(log, nil, python, "-m", "venv", venv); err != nil {
+		return err
+	}
+	scripts, exe := "bin", ""
+	if isWindows() {
+		scripts, exe = "Scripts", ".exe"
+	}
+	venvPython := filepath.Join(venv, scripts, "python"+exe)
+	if err := runInstallStep(log, nil, venvPython, "-m", "pip", "install", "--upgrade", "pip-audit"); err != nil {
+		return err
+	}
+
+	target := filepath.Join(venv, scripts, "pip-audit"+exe)
+	if isWindows() {
+		// A shim instead of a link, symbolic links need extra privileges on Windows
+		return writeExecutable(filepath.Join(bin, "pip-audit.cmd"), []byte("@\""+target+"\" %*\r\n"))
+	}
+	link := filepath.Join(bin, "pip-audit")
+	os.Remove(link)
+	return os.Symlink(target, link)
+}
@@ -0,0 +1,39 @@
+package logic
+
+import (
+	"context"
+	"os/exec"
+	"time"
+
+	"github.com/gorecode/updates/internal/cmdlimit"
+)
+
+// killWaitDelay bounds how long a killed command may keep its output open, e.g. through children of
+// "sh -c" or "cmd /C" that survive the shell
+const killWaitDelay = 10 * time.Second
+
+// runTool runs a build tool in dir and returns its combined output
+func runTool(dir, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	output, err := cmdlimit.CombinedOutput(cmd)
+	return string(output), err
+}
+
+// runShellCommand runs a user-configured command line (e.g. "pytest -q") in dir through the OS shell
+func runShellCommand(dir, command string) (string, error) {
+	return runShellCommandContext(context.Background(), dir, command)
+}
+
+// runShellCommandContext is like runShellCommand but the shell is killed when ctx is done
+func runShellCommandContext(ctx context.Context, dir, command string) (string, error) {
+	cmd := shellCommand(ctx, command)
+	cmd.Dir = dir
+	if ctx.Done() != nil {
+		// Only cancellable commands leave the server's process group (and with it Ctrl+C)
+		cmd.WaitDelay = killWaitDelay
+		killProcessGroup(cmd)
+	}
+	output, err := cmdlimit.CombinedOutput(cmd)
+	return string(output), err
+}
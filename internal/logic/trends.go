@@ -0,0 +1,132 @@
+package logic
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxDashboardSnapshots limits the history per scope (about a year of daily scans)
+const maxDashboardSnapshots = 400
+
+// DashboardSnapshot is the aggregated result of one complete dashboard scan
+type DashboardSnapshot struct {
+	Time           time.Time      `json:"time"`
+	Repos          int            `json:"repos"`
+	AvgHealthScore int            `json:"avgHealthScore"`
+	TotalTodos     int            `json:"totalTodos"`
+	OutdatedDeps   int            `json:"outdatedDeps"`
+	BuildErrors    int            `json:"buildErrors"`
+	SpringVersions map[string]int `json:"springVersions"`       // Spring Boot version -> repos
+	RepoScores     map[string]int `json:"repoScores,omitempty"` // Repo path -> health score
+}
+
+// DashboardHistory is the stored snapshot series of one scope (a root path or a group)
+type DashboardHistory struct {
+	Scope     string              `json:"scope"`
+	Snapshots []DashboardSnapshot `json:"snapshots"`
+}
+
+// DashboardTrend compares the first and the last snapshot of a period
+type DashboardTrend struct {
+	Scope          string              `json:"scope"`
+	Points         []DashboardSnapshot `json:"points"`
+	HealthChange   int                 `json:"healthChange"`   // Positive = healthier
+	TodosChange    int                 `json:"todosChange"`    // Negative = less debt
+	OutdatedChange int                 `json:"outdatedChange"` // Negative = fresher dependencies
+}
+
+var dashboardHistoryMu sync.Mutex
+
+// NewDashboardSnapshot aggregates the repo results of a dashboard scan
+func NewDashboardSnapshot(repos []RepoHealth, at time.Time) DashboardSnapshot {
+	snapshot := DashboardSnapshot{
+		Time:           at,
+		Repos:          len(repos),
+		SpringVersions: make(map[string]int),
+		RepoScores:     make(map[string]int),
+	}
+	totalHealth := 0
+	for _, repo := range repos {
+		totalHealth += repo.HealthScore
+		snapshot.TotalTodos += repo.TodoCount
+		snapshot.OutdatedDeps += repo.OutdatedDeps
+		if repo.HasBuildErrors {
+			snapshot.BuildErrors++
+		}
+		if repo.SpringBootVer != "" {
+			snapshot.SpringVersions[repo.SpringBootVer]++
+		}
+		snapshot.RepoScores[repo.Path] = repo.HealthScore
+	}
+	if len(repos) > 0 {
+		snapshot.AvgHealthScore = totalHealth / len(repos)
+	}
+	return snapshot
+}
+
+// SaveDashboardSnapshot appends a snapshot to the history of scope, dropping the oldest beyond the limit
+func SaveDashboardSnapshot(scope string, snapshot DashboardSnapshot) error {
+	dashboardHistoryMu.Lock()
+	defer dashboardHistoryMu.Unlock()
+
+	if _, err := dataSubDir("dashboard-history"); err != nil {
+		return err
+	}
+	path := cacheFilePath("dashboard-history", scope)
+	history := DashboardHistory{Scope: scope}
+	if err := readJSONFile(path, &history); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	history.Scope = scope
+	history.Snapshots = append(history.Snapshots, snapshot)
+	sort.Slice(history.Snapshots, func(i, j int) bool { return history.Snapshots[i].Time.Before(history.Snapshots[j].Time) })
+	if len(history.Snapshots) > maxDashboardSnapshots {
+		history.Snapshots = history.Snapshots[len(history.Snapshots)-maxDashboardSnapshots:]
+	}
+	return writeJSONFile(path, &history)
+}
+
+// LoadDashboardTrend returns the snapshots of scope taken since the given time (zero = all), oldest first
+func LoadDashboardTrend(scope string, since time.Time) (DashboardTrend, error) {
+	dashboardHistoryMu.Lock()
+	defer dashboardHistoryMu.Unlock()
+
+	trend := DashboardTrend{Scope: scope, Points: []DashboardSnapshot{}}
+	path := cacheFilePath("dashboard-history", scope)
+	if path == "" {
+		return trend, nil
+	}
+	var history DashboardHistory
+	if err := readJSONFile(path, &history); err != nil {
+		if os.IsNotExist(err) {
+			return trend, nil
+		}
+		return trend, err
+	}
+	for _, snapshot := range history.Snapshots {
+		if !snapshot.Time.Before(since) {
+			trend.Points = append(trend.Points, snapshot)
+		}
+	}
+	if n := len(trend.Points); n > 1 {
+		first, last := trend.Points[0], trend.Points[n-1]
+		trend.HealthChange = last.AvgHealthScore - first.AvgHealthScore
+		trend.TodosChange = last.TotalTodos - first.TotalTodos
+		trend.OutdatedChange = last.OutdatedDeps - first.OutdatedDeps
+	}
+	return trend, nil
+}
+
+// DashboardScope returns the history key of a dashboard scan of rootPath or a repository group
+func DashboardScope(rootPath, group string) string {
+	if group != "" {
+		return "group:" + group
+	}
+	if abs, err := filepath.Abs(rootPath); err == nil {
+		return abs
+	}
+	return filepath.Clean(rootPath)
+}
@@ -0,0 +1,175 @@
+package logic
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// How far the Maven build of a run verifies a repo
+const (
+	VerifyDefault = ""        // clean install -DskipTests
+	VerifyNone    = "none"    // No build, no deprecation check
+	VerifyCompile = "compile" // clean compile
+	VerifyTest    = "test"    // clean test, with the Surefire results in the report
+	VerifyVerify  = "verify"  // clean verify (integration tests and checks bound to verify)
+	VerifyCustom  = "custom"  // The goals of VerificationGoal
+)
+
+// maxTestFailures caps the failed tests listed per repo
+const maxTestFailures = 50
+
+// ValidVerificationLevel reports whether level is one of the Verify* levels and a custom level has goals
+func ValidVerificationLevel(level, goal string) error {
+	switch level {
+	case VerifyDefault, VerifyNone, VerifyCompile, VerifyTest, VerifyVerify:
+		return nil
+	case VerifyCustom:
+		if strings.TrimSpace(goal) == "" {
+			return fmt.Errorf("verification level custom needs a goal, e.g. 'clean verify -Pci'")
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown verification level '%s', expected none, compile, test, verify or custom", level)
+}
+
+// verificationArgs returns the Maven arguments of a verification level (nil for VerifyNone)
+func verificationArgs(level, goal string) []string {
+	var args []string
+	switch level {
+	case VerifyNone:
+		return nil
+	case VerifyCompile:
+		args = []string{"clean", "compile"}
+	case VerifyTest:
+		args = []string{"clean", "test"}
+	case VerifyVerify:
+		args = []string{"clean", "verify"}
+	case VerifyCustom:
+		args = strings.Fields(goal)
+	default:
+		args = []string{"clean", "install", "-DskipTests"}
+	}
+	// Deprecations are captured from the same build
+	return append(args, "-Dmaven.compiler.showDeprecation=true")
+}
+
+// verificationName describes a verification level for the run log
+func verificationName(level, goal string) string {
+	args := verificationArgs(level, goal)
+	if len(args) == 0 {
+		return "no build"
+	}
+	return "mvn " + strings.Join(args[:len(args)-1], " ")
+}
+
+// TestFailure is a failed or erroneous test case
+type TestFailure struct {
+	Class   string `json:"class"`
+	Name    string `json:"name"`
+	Message string `json:"message,omitempty"` // First line of the failure message
+	Error   bool   `json:"error,omitempty"`   // Unexpected exception instead of a failed assertion
+}
+
+// TestSummary are the Surefire and Failsafe results of a build
+type TestSummary struct {
+	Tests    int           `json:"tests"`
+	Failures int           `json:"failures"`
+	Errors   int           `json:"errors"`
+	Skipped  int           `json:"skipped"`
+	Failed   []TestFailure `json:"failed,omitempty"` // Max. 50
+}
+
+// String describes the summary for the run log, e.g. "120 run, 2 failed, 0 errors, 3 skipped"
+func (s TestSummary) String() string {
+	return fmt.Sprintf("%d run, %d failed, %d errors, %d skipped", s.Tests, s.Failures, s.Errors, s.Skipped)
+}
+
+// ParseTestReports collects the TEST-*.xml files of surefire-reports and failsafe-reports in all modules
+// of a repo, written at or after since (older files are left over from previous builds). Returns nil if
+// there is no report.
+func ParseTestReports(repoPath string, since time.Time) *TestSummary {
+	var files []string
+	filepath.WalkDir(repoPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case ".git", "node_modules", "src":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		dir := filepath.Base(filepath.Dir(path))
+		if (dir == "surefire-reports" || dir == "failsafe-reports") && strings.HasPrefix(d.Name(), "TEST-") && strings.HasSuffix(d.Name(), ".xml") {
+			if info, err := d.Info(); err == nil && !info.ModTime().Before(since) {
+				files = append(files, path)
+			}
+		}
+		return nil
+	})
+	if len(files) == 0 {
+		return nil
+	}
+	sort.Strings(files)
+
+	summary := &TestSummary{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var suite struct {
+			Name     string `xml:"name,attr"`
+			Tests    int    `xml:"tests,attr"`
+			Failures int    `xml:"failures,attr"`
+			Errors   int    `xml:"errors,attr"`
+			Skipped  int    `xml:"skipped,attr"`
+			Cases    []struct {
+				Class   string `xml:"classname,attr"`
+				Name    string `xml:"name,attr"`
+				Failure *struct {
+					Message string `xml:"message,attr"`
+					Type    string `xml:"type,attr"`
+				} `xml:"failure"`
+				Error *struct {
+					Message string `xml:"message,attr"`
+					Type    string `xml:"type,attr"`
+				} `xml:"error"`
+			} `xml:"testcase"`
+		}
+		if err := xml.Unmarshal(data, &suite); err != nil {
+			continue
+		}
+		summary.Tests += suite.Tests
+		summary.Failures += suite.Failures
+		summary.Errors += suite.Errors
+		summary.Skipped += suite.Skipped
+		for _, c := range suite.Cases {
+			if (c.Failure == nil && c.Error == nil) || len(summary.Failed) >= maxTestFailures {
+				continue
+			}
+			failure := TestFailure{Class: c.Class, Name: c.Name}
+			if failure.Class == "" {
+				failure.Class = suite.Name
+			}
+			message, kind := "", ""
+			if c.Failure != nil {
+				message, kind = c.Failure.Message, c.Failure.Type
+			} else {
+				message, kind, failure.Error = c.Error.Message, c.Error.Type, true
+			}
+			if message == "" {
+				message = kind
+			}
+			failure.Message, _, _ = strings.Cut(strings.TrimSpace(message), "\n")
+			summary.Failed = append(summary.Failed, failure)
+		}
+	}
+	return summary
+}
@@ -0,0 +1,215 @@
+package logic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// workspacesFile holds the workspaces, relative to DataDir
+const workspacesFile = "workspaces.json"
+
+// Hosting providers a workspace holds credentials for
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+// ProviderCredentials are the access tokens of a workspace for GitHub and GitLab
+type ProviderCredentials struct {
+	GitHubToken string `json:"githubToken,omitempty"`
+	GitLabURL   string `json:"gitlabUrl,omitempty"` // Empty = gitlab.com
+	GitLabToken string `json:"gitlabToken,omitempty"`
+}
+
+// Workspace is a named root path with its exclusions, provider credentials and request defaults,
+// so requests can send "workspace": "<id>" instead of repeating them
+type Workspace struct {
+	ID          string              `json:"id"` // Derived from the name like profile IDs, e.g. "java-services"
+	Name        string              `json:"name"`
+	RootPath    string              `json:"rootPath"`
+	Excluded    []string            `json:"excluded,omitempty"`
+	Credentials ProviderCredentials `json:"credentials"`
+	// Defaults are further request fields used when a request does not set them,
+	// e.g. {"settingsFile": "/home/me/.m2/work.xml", "verificationLevel": "test"}
+	Defaults  map[string]interface{} `json:"defaults,omitempty"`
+	UpdatedAt time.Time              `json:"updatedAt"`
+}
+
+// reservedWorkspaceDefaults are request fields set from the workspace itself, not from Defaults
+var reservedWorkspaceDefaults = map[string]bool{"workspace": true, "rootpath": true, "excluded": true, "token": true, "baseurl": true}
+
+// Redacted returns the workspace without tokens, for the UI
+func (w Workspace) Redacted() Workspace {
+	w.Credentials.GitHubToken = ""
+	w.Credentials.GitLabToken = ""
+	return w
+}
+
+// RequestFields returns the request fields the workspace provides: root path, exclusions, the token
+// (and GitLab URL) of provider if given, and the defaults
+func (w *Workspace) RequestFields(provider string) map[string]interface{} {
+	fields := make(map[string]interface{}, len(w.Defaults)+4)
+	for key, value := range w.Defaults {
+		fields[key] = value
+	}
+	fields["rootPath"] = w.RootPath
+	if len(w.Excluded) > 0 {
+		excluded := make([]interface{}, len(w.Excluded))
+		for i, e := range w.Excluded {
+			excluded[i] = e
+		}
+		fields["excluded"] = excluded
+	}
+	switch provider {
+	case ProviderGitHub:
+		if w.Credentials.GitHubToken != "" {
+			fields["token"] = w.Credentials.GitHubToken
+		}
+	case ProviderGitLab:
+		if w.Credentials.GitLabToken != "" {
+			fields["token"] = w.Credentials.GitLabToken
+		}
+		if w.Credentials.GitLabURL != "" {
+			fields["baseUrl"] = w.Credentials.GitLabURL
+		}
+	}
+	return fields
+}
+
+var workspacesMu sync.Mutex
+
+func workspacesPath() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, workspacesFile), nil
+}
+
+func readWorkspaces() ([]Workspace, error) {
+	path, err := workspacesPath()
+	if err != nil {
+		return nil, err
+	}
+	var workspaces []Workspace
+	if err := readJSONFile(path, &workspaces); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s: %v", workspacesFile, err)
+	}
+	return workspaces, nil
+}
+
+func writeWorkspaces(workspaces []Workspace) error {
+	path, err := workspacesPath()
+	if err != nil {
+		return err
+	}
+	sort.Slice(workspaces, func(i, j int) bool { return workspaces[i].ID < workspaces[j].ID })
+	return writeJSONFile(path, workspaces)
+}
+
+// ListWorkspaces returns all workspaces, sorted by ID
+func ListWorkspaces() ([]Workspace, error) {
+	workspacesMu.Lock()
+	defer workspacesMu.Unlock()
+	workspaces, err := readWorkspaces()
+	if workspaces == nil {
+		workspaces = []Workspace{}
+	}
+	return workspaces, err
+}
+
+// LoadWorkspace returns the workspace with the given ID or name
+func LoadWorkspace(idOrName string) (*Workspace, error) {
+	workspacesMu.Lock()
+	defer workspacesMu.Unlock()
+	workspaces, err := readWorkspaces()
+	if err != nil {
+		return nil, err
+	}
+	id := ProfileID(idOrName)
+	for i := range workspaces {
+		if workspaces[i].ID == id {
+			return &workspaces[i], nil
+		}
+	}
+	return nil, fmt.Errorf("workspace '%s' not found", idOrName)
+}
+
+// SaveWorkspace creates the workspace or replaces the one with the same name. The root path must be
+// absolute; empty tokens keep the stored ones.
+func SaveWorkspace(w Workspace) (*Workspace, error) {
+	w.Name = strings.TrimSpace(w.Name)
+	w.ID = ProfileID(w.Name)
+	if w.ID == "" {
+		return nil, fmt.Errorf("workspace name must contain letters or digits")
+	}
+	w.RootPath = strings.TrimSpace(w.RootPath)
+	if !filepath.IsAbs(w.RootPath) {
+		return nil, fmt.Errorf("root path '%s' is not absolute", w.RootPath)
+	}
+	w.RootPath = filepath.Clean(w.RootPath)
+
+	var excluded []string
+	for _, e := range w.Excluded {
+		if e = strings.TrimSpace(e); e != "" {
+			excluded = append(excluded, e)
+		}
+	}
+	w.Excluded = excluded
+	for key := range w.Defaults {
+		if reservedWorkspaceDefaults[strings.ToLower(key)] {
+			return nil, fmt.Errorf("'%s' cannot be a default, it is a workspace setting", key)
+		}
+	}
+	w.Credentials.GitLabURL = strings.TrimRight(strings.TrimSpace(w.Credentials.GitLabURL), "/")
+	w.UpdatedAt = time.Now()
+
+	workspacesMu.Lock()
+	defer workspacesMu.Unlock()
+	workspaces, err := readWorkspaces()
+	if err != nil {
+		return nil, err
+	}
+
+	replaced := false
+	for i := range workspaces {
+		if workspaces[i].ID == w.ID {
+			if w.Credentials.GitHubToken == "" {
+				w.Credentials.GitHubToken = workspaces[i].Credentials.GitHubToken
+			}
+			if w.Credentials.GitLabToken == "" {
+				w.Credentials.GitLabToken = workspaces[i].Credentials.GitLabToken
+			}
+			workspaces[i] = w
+			replaced = true
+		}
+	}
+	if !replaced {
+		workspaces = append(workspaces, w)
+	}
+	if err := writeWorkspaces(workspaces); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// DeleteWorkspace removes the workspace with the given ID; the repositories are not touched
+func DeleteWorkspace(id string) error {
+	workspacesMu.Lock()
+	defer workspacesMu.Unlock()
+	workspaces, err := readWorkspaces()
+	if err != nil {
+		return err
+	}
+	for i := range workspaces {
+		if workspaces[i].ID == id {
+			return writeWorkspaces(append(workspaces[:i], workspaces[i+1:]...))
+		}
+	}
+	return fmt.Errorf("workspace '%s' not found", id)
+}
@@ -0,0 +1,83 @@
+package logic
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Worktree is a temporary `git worktree` of a repository: another checkout next to the developer's,
+// sharing the object store, so a branch can be read without touching their working copy
+type Worktree struct {
+	RepoPath string // Repository the worktree belongs to
+	Path     string // Checkout of the worktree
+	tempDir  string
+}
+
+// AddWorktree checks out ref (a branch, tag or commit) detached in a new temporary worktree. The
+// checkout folder has the name of the repository, as tools use it as the project name.
+func AddWorktree(repoPath, ref string) (*Worktree, error) {
+	tempDir, err := os.MkdirTemp("", "githousekeeper-worktree-*")
+	if err != nil {
+		return nil, err
+	}
+	wt := &Worktree{RepoPath: repoPath, Path: filepath.Join(tempDir, filepath.Base(repoPath)), tempDir: tempDir}
+	if err := runGitCommand(repoPath, "worktree", "add", "--detach", wt.Path, ref); err != nil {
+		os.RemoveAll(tempDir)
+		runGitCommand(repoPath, "worktree", "prune")
+		return nil, fmt.Errorf("could not check out %s in a temporary worktree: %v", ref, err)
+	}
+	return wt, nil
+}
+
+// Remove deletes the worktree, including files created in it (build output), and unregisters it
+// from the repository
+func (wt *Worktree) Remove() error {
+	err := runGitCommand(wt.RepoPath, "worktree", "remove", "--force", wt.Path)
+	if removeErr := os.RemoveAll(wt.tempDir); removeErr != nil && err == nil {
+		err = removeErr
+	}
+	// Drops the registration even if 'worktree remove' failed and the folder was deleted above
+	runGitCommand(wt.RepoPath, "worktree", "prune")
+	if err != nil {
+		return fmt.Errorf("could not remove the temporary worktree %s: %v", wt.Path, err)
+	}
+	return nil
+}
+
+// ResolveBranch returns the ref to check out for branch: the local branch if there is one, otherwise
+// the branch of the primary remote, fetched first. remote is true for the latter; such a branch has no local
+// branch to switch to and is read in a worktree. If the fetch fails (offline), the last fetched
+// state of the branch is used when there is one.
+func ResolveBranch(repoPath, branch string) (ref string, remote bool, err error) {
+	if runGitCommand(repoPath, "show-ref", "--verify", "--quiet", "refs/heads/"+branch) == nil {
+		return branch, false, nil
+	}
+	remoteName := primaryRemoteOrOrigin(repoPath)
+	branch = strings.TrimPrefix(branch, remoteName+"/")
+	if branch == "" || strings.HasPrefix(branch, "-") {
+		return "", false, fmt.Errorf("invalid branch name '%s'", branch)
+	}
+
+	remoteRef := "refs/remotes/" + remoteName + "/" + branch
+	fetchErr := Retry(RetryGit, nil, func() error {
+		err := runGitCommand(repoPath, "fetch", "--no-tags", remoteName, "+refs/heads/"+branch+":"+remoteRef)
+		if err != nil && strings.Contains(err.Error(), "couldn't find remote ref") {
+			// The branch does not exist on the remote, another attempt will not change that
+			return Permanent(err)
+		}
+		return err
+	})
+	if runGitCommand(repoPath, "show-ref", "--verify", "--quiet", remoteRef) != nil {
+		if fetchErr != nil {
+			return "", false, fmt.Errorf("branch '%s' exists neither locally nor on %s: %v", branch, remoteName, fetchErr)
+		}
+		return "", false, fmt.Errorf("branch '%s' exists neither locally nor on %s", branch, remoteName)
+	}
+	if fetchErr != nil {
+		slog.Warn("Could not fetch branch, using the last fetched state", "repo", repoPath, "branch", branch, "error", fetchErr)
+	}
+	return remoteRef, true, nil
+}
@@ -19,22 +19,35 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 package main
 
 import (
+	"bytes"
+	"cmp"
+	"context"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/gorecode/updates/internal/cmdlimit"
+	"github.com/gorecode/updates/internal/gitops"
 	"github.com/gorecode/updates/internal/logic"
+	"github.com/gorecode/updates/internal/logic/patch"
+	"github.com/gorecode/updates/internal/logic/providers"
 )
 
 //go:embed assets
@@ -42,20 +55,72 @@ var assets embed.FS
 
 type RunRequest struct {
 	RootPath            string
+	RootPaths           []string // Further roots searched together with RootPath
 	Excluded            []string
+	Group               string // Optional repository group (ID or name), used instead of discovering repos under RootPath
 	ParentVersion       string
+	NodeVersion         string // Optional Node.js version (e.g. an LTS major) for .nvmrc, engines.node and CI
 	VersionBumpStrategy string // "major", "minor", "patch"
 	RunCleanInstall     bool
-	TargetBranch        string // "housekeeping", "custom-name", or ""
+	VerificationLevel   string                  // "" (install without tests), "none", "compile", "test", "verify" or "custom"
+	VerificationGoal    string                  // Maven goals of the custom level, e.g. "clean verify -Pci"
+	TargetBranch        string                  // "housekeeping", "custom-name", or ""
+	CommitStrategy      string                  // "per-file" (default), "per-rule" or "single"
+	Signing             logic.SigningSettings   // Optional GPG/SSH signing of the housekeeping commits
+	CreateTag           logic.CreateTagSettings // Optional annotated tag on the result, e.g. housekeeping-2025-06
+	DirtyTree           string                  // Uncommitted changes: "stash-restore" (default), "stash", "skip" or "abort"
+	CheckGitAccess      bool                    // Check access to every remote host first and abort if a repository cannot reach its remote
 	Replacements        []logic.Replacement
 	ReplacementScope    string // "all", "pom-only", "exclude-pom"
+	Tags                logic.TagSettings
+	RepoTags            map[string]logic.TagSettings // Per-repo overrides, keyed by repo folder name
+	RepoParentVersions  map[string]string            // Per-repo parent versions, keyed by repo folder name; override ParentVersion
+	Maven               logic.MavenSettings
+	Go                  logic.GoSettings     // Go module housekeeping (directives, dependency bumps)
+	Python              logic.PythonSettings // Python dependency bumps (poetry, pip-compile, requirements.txt pins)
+	Php                 logic.PhpSettings    // Composer dependency bumps
+	LFS                 logic.LFSSettings    // Files moved to Git LFS on the work branch
+	Format              logic.FormatSettings // Formatters (spotless, prettier, gofmt) applied on the work branch
+	Changelog           bool                 // CHANGELOG.md updated with the commits since the latest release tag
+	Label               string               // Optional run label shown in the history, e.g. "March fleet refresh"
+	Description         string               // Optional free-text annotation for the history
+	MaxDurationMinutes  int                  // Optional time budget; repos not started in time can be resumed later
+	Timeouts            logic.StepTimeouts   // Optional limits per repo and step; a repo exceeding one fails and the run continues
+	Repos               []string             // Optional explicit repo paths (used when resuming); empty = discover under RootPath
+	IncludeRepos        []string             // Optional selection by path or folder name, e.g. the repos that failed last time
+	ExcludeRepos        []string             // Repos (path or folder name) to leave out of this run
+	EmailReport         bool                 // Email the HTML report when the run finishes
+	EmailTo             []string             // Recipients of the report; empty = default list of the SMTP settings
 }
 
 func main() {
+	logFile, err := logic.SetupLogging()
+	if err != nil {
+		fmt.Printf("Error: invalid log configuration: %v\n", err)
+		os.Exit(1)
+	}
+	if logFile != "" {
+		slog.Info("Logging to file", "path", logFile)
+	}
+	if network, err := logic.LoadNetworkSettings(); err != nil {
+		slog.Warn("Could not load network settings", "error", err)
+	} else if err := logic.ApplyNetworkSettings(network); err != nil {
+		slog.Warn("Invalid network settings, using direct connections", "error", err)
+	} else if network.Offline {
+		slog.Info("Offline mode: no outbound requests, lookups use cached data only")
+	}
+	logic.ConfigureGitEnvironment()
+	logic.AddToolCacheToPath()
+	if retry, err := logic.LoadRetrySettings(); err != nil {
+		slog.Warn("Could not load retry settings, using the defaults", "error", err)
+	} else {
+		logic.ApplyRetrySettings(retry)
+	}
+
 	// Setup File Server
 	// Check if "assets" folder exists locally (Dev Mode)
 	if _, err := os.Stat("assets"); err == nil {
-		fmt.Println("Development Mode: Serving assets from local disk")
+		slog.Info("Development Mode: Serving assets from local disk")
 		http.Handle("/", http.FileServer(http.Dir("assets")))
 	} else {
 		// Production Mode: Use embedded assets
@@ -70,32 +135,170 @@ func main() {
 	// API
 	http.HandleFunc("/api/health", handleHealth)
 	http.HandleFunc("/api/run", handleRun)
+	http.HandleFunc("/api/validate-replacements", handleValidateReplacements)
+	http.HandleFunc("/api/preview-replacements", handlePreviewReplacements)
+	http.HandleFunc("/api/rollback/", handleRollback)
+	http.HandleFunc("/api/rerun/", handleRerun)
+	http.HandleFunc("/api/run-report/", handleRunReport)
+	http.HandleFunc("/api/deprecations-report", handleDeprecationsReport)
+	http.HandleFunc("/api/parent-suggestions", handleParentSuggestions)
+	http.HandleFunc("/api/smtp-settings", handleSMTPSettings)
+	http.HandleFunc("/api/smtp-test", handleSMTPTest)
+	http.HandleFunc("/api/network-settings", handleNetworkSettings)
+	http.HandleFunc("/api/retry-settings", handleRetrySettings)
+	http.HandleFunc("/api/logs/tail", handleLogsTail)
+	http.HandleFunc("/api/command-limits", handleCommandLimits)
+	http.HandleFunc("/api/session", handleSession)
+	http.HandleFunc("/api/runs", handleRuns)
+	http.HandleFunc("/api/runs/", handleRunDetail)
+	http.HandleFunc("/api/profiles", handleProfiles)
+	http.HandleFunc("/api/profiles/", handleProfileDetail)
+	http.HandleFunc("/api/groups", handleGroups)
+	http.HandleFunc("/api/groups/", handleGroupDetail)
+	http.HandleFunc("/api/workspaces", handleWorkspaces)
+	http.HandleFunc("/api/workspaces/", handleWorkspaceDetail)
 	http.HandleFunc("/api/spring-versions", handleSpringVersions)
+	http.HandleFunc("/api/node-lts", handleNodeLTS)
+	http.HandleFunc("/api/quarkus-streams", handleQuarkusStreams)
 	http.HandleFunc("/api/scan-spring", handleScanSpring)
 	http.HandleFunc("/api/analyze-spring", handleAnalyzeSpring)
+	http.HandleFunc("/api/analysis-patch", handleAnalysisPatch)
+	http.HandleFunc("/api/migration-history", handleMigrationHistory)
+	http.HandleFunc("/api/jakarta-readiness", handleJakartaReadiness)
 	http.HandleFunc("/api/pick-folder", handlePickFolder)
 	http.HandleFunc("/api/list-folders", handleListFolders)
 	http.HandleFunc("/api/openrewrite-versions", handleOpenRewriteVersions)
 	http.HandleFunc("/api/dashboard-stats", handleDashboardStats)
+	http.HandleFunc("/api/dashboard-watch", handleDashboardWatch)
+	http.HandleFunc("/api/dashboard-trends", handleDashboardTrends)
+	http.HandleFunc("/api/score-policy", handleScorePolicy)
 	http.HandleFunc("/api/list-branches", handleListBranches)
+	http.HandleFunc("/api/repo-status", handleRepoStatus)
+	http.HandleFunc("/api/repo-remote", handleRepoRemote)
+	http.HandleFunc("/api/stashes", handleStashes)
+	http.HandleFunc("/api/stashes/", handleStashOperation)
+	http.HandleFunc("/api/git-auth-check", handleGitAuthCheck)
 	http.HandleFunc("/api/sync-branches", handleSyncBranches)
+	http.HandleFunc("/api/remote-branches", handleRemoteBranches)
+	http.HandleFunc("/api/list-tags", handleListTags)
+	http.HandleFunc("/api/changelog", handleChangelog)
+	http.HandleFunc("/api/release/plan", handleReleasePlan)
+	http.HandleFunc("/api/release", handleRelease)
+	http.HandleFunc("/api/remote-branches/delete", handleDeleteRemoteBranches)
 	http.HandleFunc("/api/security-scan", handleSecurityScan)
+	http.HandleFunc("/api/environment", handleEnvironment)
+	http.HandleFunc("/api/install-tool", handleInstallTool)
+	http.HandleFunc("/api/owasp-settings", handleOwaspSettings)
+	http.HandleFunc("/api/owasp-db", handleOwaspDB)
+	http.HandleFunc("/api/owasp-db-update", handleOwaspDBUpdate)
 	http.HandleFunc("/api/check-trivy", handleCheckTrivy)
 	http.HandleFunc("/api/check-npm", handleCheckNpm)
 	http.HandleFunc("/api/check-go", handleCheckGo)
 	http.HandleFunc("/api/check-python", handleCheckPython)
 	http.HandleFunc("/api/check-php", handleCheckPhp)
+	http.HandleFunc("/api/check-rust", handleCheckRust)
+	http.HandleFunc("/api/check-dotnet", handleCheckDotnet)
+	http.HandleFunc("/api/check-ruby", handleCheckRuby)
+	http.HandleFunc("/api/check-signing", handleCheckSigning)
+	http.HandleFunc("/api/github/repos", handleGitHubRepos)
+	http.HandleFunc("/api/github/clone", handleGitHubClone)
+	http.HandleFunc("/api/gitlab/repos", handleGitLabRepos)
+	http.HandleFunc("/api/gitlab/clone", handleGitLabClone)
+	http.HandleFunc("/api/gitlab/merge-requests", handleGitLabMergeRequests)
+	http.HandleFunc("/api/gitignore-audit", handleGitignoreAudit)
+	http.HandleFunc("/api/gitignore-fix", handleGitignoreFix)
+	http.HandleFunc("/api/gitignore-standard", handleGitignoreStandard)
+	http.HandleFunc("/api/gitignore-normalize", handleGitignoreNormalize)
+	http.HandleFunc("/api/base-images/update", handleBaseImageUpdate)
+	http.HandleFunc("/api/ci-actions/update", handleCIActionUpdate)
+	http.HandleFunc("/api/dependency-bot-settings", handleDependencyBotSettings)
+	http.HandleFunc("/api/dependency-bots/seed", handleDependencyBotSeed)
+	http.HandleFunc("/api/hygiene-templates", handleHygieneTemplates)
+	http.HandleFunc("/api/hygiene/seed", handleHygieneSeed)
+	http.HandleFunc("/api/repo-size-report", handleRepoSizeReport)
+	http.HandleFunc("/api/lfs-audit", handleLFSAudit)
+	http.HandleFunc("/api/lfs-dry-run", handleLFSDryRun)
+	http.HandleFunc("/api/security-fix", handleSecurityFix)
+	http.HandleFunc("/api/outdated-maven", handleOutdatedMaven)
+	http.HandleFunc("/api/findings", handleFindings)
+	http.HandleFunc("/api/findings/import", handleFindingsImport)
+	http.HandleFunc("/api/findings/delta", handleFindingsDelta)
+
+	if err := cmdlimit.Configure(); err != nil {
+		slog.Error("Invalid process limits", "error", err)
+		os.Exit(1)
+	}
+	if err := logic.ConfigureDiscovery(); err != nil {
+		slog.Error("Invalid repository discovery depth", "error", err)
+		os.Exit(1)
+	}
+
+	sandbox, err := logic.PathSandboxFromEnv()
+	if err != nil {
+		slog.Error("Invalid "+logic.AllowedRootsEnv, "error", err)
+		os.Exit(1)
+	}
+	pathSandbox = sandbox
+	if sandbox.Enabled() {
+		slog.Info("Filesystem access restricted", "roots", strings.Join(sandbox.Roots(), ", "))
+	} else {
+		slog.Warn(logic.AllowedRootsEnv + " is not set, the API can access every directory of this user")
+	}
+
+	auth, err := logic.AuthenticatorFromEnv()
+	if err != nil {
+		slog.Error("Invalid authentication configuration", "error", err)
+		os.Exit(1)
+	}
+	serverAuth = auth
+
+	addr := os.Getenv(logic.ListenAddrEnv)
+	if addr == "" {
+		addr = "localhost:8080"
+	}
+	// Every endpoint can run git and build tools on this host, never expose them without credentials
+	if !logic.IsLoopbackAddr(addr) && !auth.Enabled() {
+		slog.Error("Listening on a non-local address requires authentication, set "+logic.AuthTokenEnv+" or "+logic.UsersFileEnv, "addr", addr)
+		os.Exit(1)
+	}
+	if auth.Enabled() {
+		slog.Info("Authentication enabled")
+	}
+
+	host, port, _ := net.SplitHostPort(addr)
+	certHosts := []string{host}
+	if hostname, err := os.Hostname(); err == nil {
+		certHosts = append(certHosts, hostname)
+	}
+	tlsConfig, err := logic.TLSConfigFromEnv(certHosts)
+	if err != nil {
+		slog.Error("Invalid TLS configuration", "error", err)
+		os.Exit(1)
+	}
 
-	port := "8080"
-	url := "http://localhost:" + port
+	scheme := "http"
+	if tlsConfig != nil {
+		scheme = "https"
+	}
+	url := scheme + "://localhost:" + port
 
-	fmt.Printf("Starting web interface at %s ...\n", url)
+	slog.Info("Starting web interface", "url", url, "addr", addr)
 
 	// Open Browser
 	go openBrowser(url)
 
-	if err := http.ListenAndServe(":"+port, nil); err != nil {
-		fmt.Printf("Error starting server: %v\n", err)
+	server := &http.Server{
+		Addr:      addr,
+		Handler:   withRequestLog(withAuth(auth, withWorkspace(withPathSandbox(sandbox, withSlashPaths(http.DefaultServeMux))))),
+		TLSConfig: tlsConfig,
+	}
+	if tlsConfig != nil {
+		err = server.ListenAndServeTLS("", "")
+	} else {
+		err = server.ListenAndServe()
+	}
+	if err != nil {
+		slog.Error("Error starting server", "error", err)
 	}
 }
 
@@ -117,6 +320,10 @@ func handleRun(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	if issues := logic.ValidateReplacements(req.Replacements); len(issues) > 0 {
+		http.Error(w, fmt.Sprintf("Invalid replacement '%s': %s", issues[0].Search, issues[0].Error), http.StatusBadRequest)
+		return
+	}
 
 	// Set headers for streaming
 	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
@@ -129,12 +336,151 @@ func handleRun(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	executeRun(w, flusher, req, newRunRecord(req))
+}
+
+// handleValidateReplacements compiles the replacement rules so errors show up before a run starts
+func handleValidateReplacements(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var replacements []logic.Replacement
+	if err := json.NewDecoder(r.Body).Decode(&replacements); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	issues := logic.ValidateReplacements(replacements)
+	if issues == nil {
+		issues = []logic.ReplacementIssue{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"valid":  len(issues) == 0,
+		"issues": issues,
+	})
+}
+
+// newRunRecord creates the history record for a run; every run gets an ID so it can be rolled back later
+func newRunRecord(req RunRequest) *logic.RunRecord {
+	run := &logic.RunRecord{
+		ID:          logic.NewRunID(),
+		StartedAt:   time.Now(),
+		RootPath:    req.RootPath,
+		Label:       strings.TrimSpace(req.Label),
+		Description: strings.TrimSpace(req.Description),
+	}
+	// Keep the request (without the repo selection) so the remainder can be resumed
+	req.Repos = nil
+	if data, err := json.Marshal(req); err == nil {
+		run.Request = data
+	}
+	return run
+}
+
+// executeRun processes the repos of req and streams the log.
+// With MaxDurationMinutes set, no new repo is started once the budget is used up;
+// the repos not reached are stored in run.Remaining and can be resumed later.
+func executeRun(w http.ResponseWriter, flusher http.Flusher, req RunRequest, run *logic.RunRecord) {
+	// Resumed runs replay a stored request, which the request sandbox never saw
+	for _, root := range append([]string{req.RootPath}, req.RootPaths...) {
+		if err := pathSandbox.Check(root); err != nil && root != "" {
+			fmt.Fprintf(w, "[ERROR] %v\n", err)
+			flusher.Flush()
+			return
+		}
+	}
+
+	if !logic.ValidDirtyMode(req.DirtyTree) {
+		fmt.Fprintf(w, "[ERROR] Unknown handling of uncommitted changes '%s', expected stash, stash-restore, skip or abort\n", req.DirtyTree)
+		flusher.Flush()
+		return
+	}
+
+	if err := logic.ValidVerificationLevel(req.VerificationLevel, req.VerificationGoal); err != nil {
+		fmt.Fprintf(w, "[ERROR] %v\n", err)
+		flusher.Flush()
+		return
+	}
+
+	if err := req.Timeouts.Validate(); err != nil {
+		fmt.Fprintf(w, "[ERROR] %v\n", err)
+		flusher.Flush()
+		return
+	}
+
+	if err := req.Format.Validate(); err != nil {
+		fmt.Fprintf(w, "[ERROR] %v\n", err)
+		flusher.Flush()
+		return
+	}
+
+	// Resumed and retried runs come from the run history, not through the request sandbox
+	for _, path := range []string{req.Maven.MavenHome, req.Maven.SettingsFile} {
+		if path == "" {
+			continue
+		}
+		if err := pathSandbox.Check(path); err != nil {
+			fmt.Fprintf(w, "[ERROR] %v\n", err)
+			flusher.Flush()
+			return
+		}
+	}
+
+	if req.CreateTag.Enabled {
+		if err := logic.ValidateTagTemplate(req.CreateTag.Template); err != nil {
+			fmt.Fprintf(w, "[ERROR] Invalid tag template: %v\n", err)
+			flusher.Flush()
+			return
+		}
+	}
+
+	// Fail before touching any repo if the signed commits would be rejected anyway
+	if req.Signing.Enabled {
+		if err := logic.VerifySigning(req.Signing); err != nil {
+			fmt.Fprintf(w, "[ERROR] Commit signing does not work: %v\n", err)
+			flusher.Flush()
+			return
+		}
+		fmt.Fprintf(w, "Commit signing verified.\n")
+	}
+
 	// Find Repos
 	var repos []string
-	if logic.IsGitRepo(req.RootPath) {
+	if len(req.Repos) > 0 {
+		for _, repo := range req.Repos {
+			if err := pathSandbox.Check(repo); err != nil {
+				fmt.Fprintf(w, "[ERROR] %v\n", err)
+				continue
+			}
+			if logic.IsGitRepo(repo) {
+				repos = append(repos, repo)
+			}
+		}
+	} else if req.Group != "" {
+		var err error
+		if repos, err = resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group); err != nil {
+			fmt.Fprintf(w, "[ERROR] %v\n", err)
+			flusher.Flush()
+			return
+		}
+		fmt.Fprintf(w, "Repository group '%s'\n", req.Group)
+	} else if len(req.RootPaths) == 0 && logic.IsGitRepo(req.RootPath) {
 		repos = []string{req.RootPath}
 	} else {
-		repos = logic.FindGitRepos(req.RootPath, req.Excluded)
+		repos = findRepos(req.RootPath, req.RootPaths, req.Excluded)
+	}
+
+	if len(req.IncludeRepos) > 0 || len(req.ExcludeRepos) > 0 {
+		found := len(repos)
+		var unmatched []string
+		repos, unmatched = logic.FilterRepos(repos, append([]string{req.RootPath}, req.RootPaths...), req.IncludeRepos, req.ExcludeRepos)
+		for _, entry := range unmatched {
+			fmt.Fprintf(w, "[WARNING] Included repository '%s' was not found.\n", entry)
+		}
+		fmt.Fprintf(w, "Repository selection: %d of %d projects\n", len(repos), found)
 	}
 
 	if len(repos) == 0 {
@@ -144,9 +490,79 @@ func handleRun(w http.ResponseWriter, r *http.Request) {
 	}
 
 	fmt.Fprintf(w, "Found: %d projects\n", len(repos))
+
+	// Abort mode: report every repo with local work and start nothing
+	if req.DirtyTree == logic.DirtyAbort {
+		if dirty := logic.FindDirtyRepos(repos); len(dirty) > 0 {
+			fmt.Fprintf(w, "[ERROR] Run aborted: %d repositories have uncommitted changes. Commit or stash them, or choose another handling.\n", len(dirty))
+			for _, d := range dirty {
+				fmt.Fprintf(w, "  %s:\n", filepath.Base(d.RepoPath))
+				for j, change := range d.Changes {
+					if j == 10 {
+						fmt.Fprintf(w, "    ... and %d more\n", len(d.Changes)-10)
+						break
+					}
+					fmt.Fprintf(w, "    %s\n", change)
+				}
+			}
+			fmt.Fprintf(w, "DIRTY_ABORT:%d\n", len(dirty))
+			flusher.Flush()
+			return
+		}
+	}
+
+	if missing := logic.RunToolNeeds(repos, req.Maven, req.VerificationLevel).Missing(req.Maven); len(missing) > 0 {
+		fmt.Fprintf(w, "[ERROR] Run aborted: %d required tools are not installed.\n", len(missing))
+		writeMissingTools(w, missing)
+		fmt.Fprintf(w, "TOOLS_ABORT:%d\n", len(missing))
+		flusher.Flush()
+		return
+	}
+
+	// Fail before touching any repo instead of many repos failing their pull one by one
+	if req.CheckGitAccess {
+		fmt.Fprintf(w, "Checking git access...\n")
+		flusher.Flush()
+		if access := logic.CheckGitAccess(repos); access.Failed > 0 {
+			fmt.Fprintf(w, "[ERROR] Run aborted: %d repositories cannot reach their remote.\n", access.Failed)
+			for _, h := range access.Hosts {
+				if h.Status == logic.AuthOK {
+					continue
+				}
+				fmt.Fprintf(w, "  %s (%s): %s, used by %s\n", h.URL, h.Protocol, h.Status, strings.Join(h.Repos, ", "))
+				fmt.Fprintf(w, "    %s\n", h.Hint)
+			}
+			fmt.Fprintf(w, "AUTH_ABORT:%d\n", access.Failed)
+			flusher.Flush()
+			return
+		}
+		fmt.Fprintf(w, "Git access verified.\n")
+	}
+
+	fmt.Fprintf(w, "RUN_ID:%s\n", run.ID)
 	flusher.Flush()
 
-	for _, repo := range repos {
+	report := &logic.RunReport{RunID: run.ID, Label: run.Label, RootPath: req.RootPath, StartedAt: run.StartedAt, Repos: []logic.RepoReport{}}
+
+	var deadline time.Time
+	if req.MaxDurationMinutes > 0 {
+		deadline = time.Now().Add(time.Duration(req.MaxDurationMinutes) * time.Minute)
+		fmt.Fprintf(w, "Time budget: %d minutes (until %s)\n", req.MaxDurationMinutes, deadline.Format("15:04"))
+		flusher.Flush()
+	}
+
+	for i, repo := range repos {
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			run.Remaining = repos[i:]
+			if err := logic.SaveRunRecord(run); err != nil {
+				fmt.Fprintf(w, "  [WARNING] Could not save run record (resume unavailable): %v\n", err)
+			}
+			fmt.Fprintf(w, "[WARNING] Time budget exhausted, %d repositories left for a later resume.\n", len(run.Remaining))
+			fmt.Fprintf(w, "TIME_BUDGET_EXHAUSTED:%d\n", len(run.Remaining))
+			flusher.Flush()
+			return
+		}
+
 		repoName := filepath.Base(repo)
 
 		// Special prefix for frontend highlighting
@@ -163,15 +579,58 @@ func handleRun(w http.ResponseWriter, r *http.Request) {
 			Replacements:        req.Replacements,
 			ReplacementScope:    req.ReplacementScope,
 			TargetParentVersion: req.ParentVersion,
+			TargetNodeVersion:   req.NodeVersion,
 			VersionBumpStrategy: req.VersionBumpStrategy,
 			RunCleanInstall:     req.RunCleanInstall,
+			VerificationLevel:   req.VerificationLevel,
+			VerificationGoal:    req.VerificationGoal,
 			ExcludedFolders:     req.Excluded,
 			TargetBranch:        req.TargetBranch,
+			CommitStrategy:      req.CommitStrategy,
+			Signing:             req.Signing,
+			CreateTag:           req.CreateTag,
+			DirtyTree:           req.DirtyTree,
+			RunID:               run.ID,
+			Tags:                req.Tags,
+			Maven:               req.Maven,
+			Go:                  req.Go,
+			Python:              req.Python,
+			Php:                 req.Php,
+			LFS:                 req.LFS,
+			Format:              req.Format,
+			Changelog:           req.Changelog,
+			Timeouts:            req.Timeouts,
 			Log:                 logCallback,
 		}
+		if tags, ok := req.RepoTags[repoName]; ok {
+			opts.Tags = tags
+		}
+		if version, ok := req.RepoParentVersions[repoName]; ok {
+			opts.TargetParentVersion = version
+		}
 
+		started := time.Now()
 		entry := logic.ProcessRepo(repo, opts)
 
+		if entry.Snapshot.RepoPath != "" {
+			run.Repos = append(run.Repos, entry.Snapshot)
+		}
+		result := logic.RepoResult{RepoPath: repo, Status: logic.RepoStatusFailed, FinishedAt: time.Now()}
+		if entry.Skipped {
+			result.Status = logic.RepoStatusSkipped
+		} else if entry.Success {
+			result.Status = logic.RepoStatusSuccess
+		}
+		run.Results = append(run.Results, result)
+		report.Add(logic.NewRepoReport(entry, result.Status, time.Since(started)))
+		report.FinishedAt = time.Now()
+		if err := logic.SaveRunReport(report); err != nil {
+			fmt.Fprintf(w, "  [WARNING] Could not save run report: %v\n", err)
+		}
+		if err := logic.SaveRunRecord(run); err != nil {
+			fmt.Fprintf(w, "  [WARNING] Could not save run record (rollback unavailable): %v\n", err)
+		}
+
 		// Deprecation output is handled separately in the UI, so we stream it with markers
 		if entry.DeprecationOutput != "" {
 			fmt.Fprintf(w, "DEPRECATION_START:%s\n", repoName)
@@ -180,2646 +639,7802 @@ func handleRun(w http.ResponseWriter, r *http.Request) {
 			flusher.Flush()
 		}
 
-		if entry.Success {
+		if entry.Skipped {
+			fmt.Fprintf(w, "– %s skipped.\n", repoName)
+		} else if entry.Success {
 			fmt.Fprintf(w, "✓ %s processed successfully.\n", repoName)
+		} else if entry.TimedOut != "" {
+			fmt.Fprintf(w, "✗ %s failed (timeout: %s).\n", repoName, entry.TimedOut)
 		} else {
 			fmt.Fprintf(w, "✗ %s failed.\n", repoName)
 		}
 		flusher.Flush()
 	}
-}
 
-// Cache for Spring versions to avoid repeated Maven Central calls
-var (
-	springVersionsCache     []logic.SpringVersionInfo
-	springVersionsCacheTime time.Time
-	springVersionsCacheTTL  = 5 * time.Minute
-)
+	fmt.Fprintf(w, "Summary: %d succeeded, %d failed, %d skipped, %d commits. Report: /api/run-report/%s\n",
+		report.Summary.Succeeded, report.Summary.Failed, report.Summary.Skipped, report.Summary.Commits, run.ID)
+	fmt.Fprintf(w, "RUN_REPORT:%s\n", run.ID)
+	flusher.Flush()
 
-func handleSpringVersions(w http.ResponseWriter, r *http.Request) {
-	// Check cache
-	if springVersionsCache != nil && time.Since(springVersionsCacheTime) < springVersionsCacheTTL {
-		w.Header().Set("Content-Type", "application/json")
-		w.Header().Set("X-Cache", "HIT")
-		json.NewEncoder(w).Encode(springVersionsCache)
+	if req.EmailReport {
+		subject := fmt.Sprintf("Housekeeping report %s: %d succeeded, %d failed", run.ID, report.Summary.Succeeded, report.Summary.Failed)
+		if run.Label != "" {
+			subject = fmt.Sprintf("Housekeeping report '%s': %d succeeded, %d failed", run.Label, report.Summary.Succeeded, report.Summary.Failed)
+		}
+		var html bytes.Buffer
+		logic.RenderRunReportHTML(report, &html)
+		data, _ := json.MarshalIndent(report, "", "  ")
+		attachment := logic.Attachment{Name: "housekeeping-report-" + run.ID + ".json", ContentType: "application/json", Data: data}
+		if recipients, err := emailReport(req.EmailTo, subject, html.String(), attachment); err != nil {
+			fmt.Fprintf(w, "[WARNING] Report not emailed: %v\n", err)
+		} else {
+			fmt.Fprintf(w, "Report emailed to %s.\n", strings.Join(recipients, ", "))
+		}
+		flusher.Flush()
+	}
+}
+
+// handleRunResume continues a time-boxed run with the repos it did not reach: POST /api/runs/{runID}/resume
+func handleRunResume(w http.ResponseWriter, r *http.Request, runID string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	versions, err := logic.GetSpringVersions()
+	prev, err := logic.LoadRunRecord(runID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
-
-	// Update cache
-	springVersionsCache = versions
-	springVersionsCacheTime = time.Now()
-
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("X-Cache", "MISS")
-	json.NewEncoder(w).Encode(versions)
-}
-
-// Current OpenRewrite versions used in this app
-// Moved to type definition area
-
-// Cache for OpenRewrite versions
-var (
-	openRewriteVersionsCache     []logic.OpenRewriteVersionInfo
-	openRewriteVersionsCacheTime time.Time
-	openRewriteVersionsCacheTTL  = 10 * time.Minute
-)
-
-func handleOpenRewriteVersions(w http.ResponseWriter, r *http.Request) {
-	// Check cache
-	if openRewriteVersionsCache != nil && time.Since(openRewriteVersionsCacheTime) < openRewriteVersionsCacheTTL {
-		w.Header().Set("Content-Type", "application/json")
-		w.Header().Set("X-Cache", "HIT")
-		json.NewEncoder(w).Encode(openRewriteVersionsCache)
+	if !prev.Resumable() {
+		http.Error(w, "Run has nothing left to resume", http.StatusConflict)
 		return
 	}
 
-	versions, err := logic.GetOpenRewriteVersions(openRewritePluginVersion, openRewriteRecipeVersion)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	var req RunRequest
+	if err := json.Unmarshal(prev.Request, &req); err != nil {
+		http.Error(w, "Stored run request is unreadable: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Update cache
-	openRewriteVersionsCache = versions
-	openRewriteVersionsCacheTime = time.Now()
-
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("X-Cache", "MISS")
-	json.NewEncoder(w).Encode(versions)
-}
+	// Optional override of the time budget for the continuation
+	var override struct {
+		MaxDurationMinutes *int `json:"maxDurationMinutes"`
+	}
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&override); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if override.MaxDurationMinutes != nil {
+		req.MaxDurationMinutes = *override.MaxDurationMinutes
+	}
 
-type ScanRequest struct {
-	RootPath string
-	Excluded []string
-}
+	run := newRunRecord(req)
+	run.ResumedFrom = prev.ID
+	req.Repos = prev.Remaining
 
-func handleScanSpring(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-	var req ScanRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	prev.ResumedBy = run.ID
+	if err := logic.SaveRunRecord(prev); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	results := logic.ScanProjectsForSpring(req.RootPath, req.Excluded)
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(results)
-}
+	// Set headers for streaming
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
 
-type ListFoldersRequest struct {
-	Path string
-}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
 
-type ListFoldersResponse struct {
-	IsRepo  bool
-	Folders []string
-	Error   string `json:",omitempty"`
+	fmt.Fprintf(w, "Resuming run %s with %d remaining repositories...\n", prev.ID, len(req.Repos))
+	executeRun(w, flusher, req, run)
 }
 
-func handleListFolders(w http.ResponseWriter, r *http.Request) {
+// handleRerun executes the repos of a past run again with its original parameters:
+// POST /api/rerun/{runID}?only=failed (failed and skipped repos only) or without "only" (all repos)
+func handleRerun(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	var req ListFoldersRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+
+	runID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/rerun/"), "/")
+	if !logic.ValidRunID(runID) {
+		http.Error(w, "Invalid run ID", http.StatusBadRequest)
+		return
+	}
+	only := r.URL.Query().Get("only")
+	if only != "" && only != "failed" {
+		http.Error(w, "Unknown selection '"+only+"', expected only=failed", http.StatusBadRequest)
 		return
 	}
 
-	resp := ListFoldersResponse{}
-
-	// Check if path exists
-	info, err := os.Stat(req.Path)
+	prev, err := logic.LoadRunRecord(runID)
 	if err != nil {
-		resp.Error = fmt.Sprintf("Path not found: %v", err)
-		json.NewEncoder(w).Encode(resp)
+		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
-	if !info.IsDir() {
-		resp.Error = "Path is not a directory"
-		json.NewEncoder(w).Encode(resp)
+	if len(prev.Request) == 0 || (len(prev.Results) == 0 && len(prev.Remaining) == 0) {
+		http.Error(w, "Run has no recorded parameters or repository results", http.StatusConflict)
 		return
 	}
-
-	// Check if it is a git repo
-	if logic.IsGitRepo(req.Path) {
-		resp.IsRepo = true
-		json.NewEncoder(w).Encode(resp)
+	repos := prev.RerunRepos(only == "failed")
+	if len(repos) == 0 {
+		http.Error(w, "Run has no failed or skipped repositories", http.StatusConflict)
 		return
 	}
 
-	// List subdirectories
-	entries, err := os.ReadDir(req.Path)
-	if err != nil {
-		resp.Error = fmt.Sprintf("Could not read directory: %v", err)
-		json.NewEncoder(w).Encode(resp)
+	var req RunRequest
+	if err := json.Unmarshal(prev.Request, &req); err != nil {
+		http.Error(w, "Stored run request is unreadable: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			resp.Folders = append(resp.Folders, entry.Name())
-		}
-	}
+	run := newRunRecord(req)
+	run.RerunOf = prev.ID
+	req.Repos = repos
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp)
+	// Set headers for streaming
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	fmt.Fprintf(w, "Re-running %d repositories of run %s...\n", len(repos), prev.ID)
+	executeRun(w, flusher, req, run)
 }
 
-func openBrowser(url string) {
-	var err error
-	switch runtime.GOOS {
-	case "linux":
-		err = exec.Command("xdg-open", url).Start()
-	case "windows":
-		err = exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
-	case "darwin":
-		err = exec.Command("open", url).Start()
-	default:
-		err = fmt.Errorf("unsupported platform")
+// handleRunReport returns the structured per-repo report of a run: GET /api/run-report/{runID}
+// (?format=html or ?format=pdf downloads it as a standalone document)
+func handleRunReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
-	if err != nil {
-		fmt.Printf("Could not open browser: %v\n", err)
+
+	runID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/run-report/"), "/")
+	if !logic.ValidRunID(runID) {
+		http.Error(w, "Invalid run ID", http.StatusBadRequest)
+		return
 	}
-}
 
-func handlePickFolder(w http.ResponseWriter, r *http.Request) {
-	path, err := openFolderDialog()
+	report, err := logic.LoadRunReport(runID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"path": path})
-}
-
-func openFolderDialog() (string, error) {
-	switch runtime.GOOS {
-	case "windows":
-		return openFolderDialogWindows()
-	case "darwin":
-		return openFolderDialogMac()
-	case "linux":
-		return openFolderDialogLinux()
+	filename := "housekeeping-report-" + runID
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	case "html":
+		var buf bytes.Buffer
+		if err := logic.RenderRunReportHTML(report, &buf); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.html"`)
+		w.Write(buf.Bytes())
+	case "pdf":
+		pdf, err := logic.RenderRunReportPDF(report)
+		if errors.Is(err, logic.ErrNoPDFConverter) {
+			http.Error(w, err.Error(), http.StatusNotImplemented)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.pdf"`)
+		w.Write(pdf)
 	default:
-		return "", fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+		http.Error(w, "Unknown format '"+format+"', expected json, html or pdf", http.StatusBadRequest)
 	}
 }
 
-func openFolderDialogWindows() (string, error) {
-	psScript := `
-		Add-Type -AssemblyName System.Windows.Forms
-		$f = New-Object System.Windows.Forms.FolderBrowserDialog
-		$f.ShowNewFolderButton = $true
-		if ($f.ShowDialog() -eq 'OK') {
-			Write-Host $f.SelectedPath
+// handleDeprecationsReport aggregates the deprecations of a run across its repos and diffs them against the
+// previous run of the same root path: GET /api/deprecations-report?run={runID} (default: latest run)
+func handleDeprecationsReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var report *logic.RunReport
+	var err error
+	if runID := r.URL.Query().Get("run"); runID != "" {
+		if !logic.ValidRunID(runID) {
+			http.Error(w, "Invalid run ID", http.StatusBadRequest)
+			return
 		}
-	`
-	cmd := exec.Command("powershell", "-NoProfile", "-Command", psScript)
-	output, err := cmd.Output()
+		report, err = logic.LoadRunReport(runID)
+	} else {
+		report, err = logic.LatestRunReport()
+	}
 	if err != nil {
-		return "", err
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
 	}
-	return strings.TrimSpace(string(output)), nil
+	previous, err := logic.PreviousRunReport(report)
+	if err != nil {
+		slog.Warn("Could not load the previous run report", "run", report.RunID, "error", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(logic.BuildDeprecationReport(report, previous))
 }
 
-func openFolderDialogMac() (string, error) {
-	script := `POSIX path of (choose folder)`
-	cmd := exec.Command("osascript", "-e", script)
-	output, err := cmd.Output()
+// handleRollback reverts all repos of a housekeeping run: POST /api/rollback/{runID}
+func handleRollback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	runID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/rollback/"), "/")
+	if !logic.ValidRunID(runID) {
+		http.Error(w, "Invalid run ID", http.StatusBadRequest)
+		return
+	}
+
+	// Set headers for streaming
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	fmt.Fprintf(w, "Rolling back run %s...\n", runID)
+	flusher.Flush()
+
+	_, err := logic.RollbackRun(runID, func(msg string) {
+		fmt.Fprintf(w, "%s\n", msg)
+		flusher.Flush()
+	})
 	if err != nil {
-		return "", err
+		fmt.Fprintf(w, "[ERROR] %v\n", err)
 	}
-	return strings.TrimSpace(string(output)), nil
+	fmt.Fprintf(w, "ROLLBACK_COMPLETE\n")
+	flusher.Flush()
 }
 
-func openFolderDialogLinux() (string, error) {
-	// Try zenity first (common on GNOME/GTK)
-	path, err := runCommandOutput("zenity", "--file-selection", "--directory")
-	if err == nil && path != "" {
-		return path, nil
+// handleRuns lists the run history: GET /api/runs?label=...&q=...
+func handleRuns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
 
-	// Try kdialog (common on KDE)
-	path, err = runCommandOutput("kdialog", "--getexistingdirectory")
-	if err == nil && path != "" {
-		return path, nil
+	runs, err := logic.ListRunRecords(logic.RunFilter{
+		Label: r.URL.Query().Get("label"),
+		Query: r.URL.Query().Get("q"),
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	return "", fmt.Errorf("no GUI dialog tool found (zenity or kdialog required)")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(runs)
 }
 
-func runCommandOutput(name string, args ...string) (string, error) {
-	cmd := exec.Command(name, args...)
-	output, err := cmd.Output()
+type RunAnnotationRequest struct {
+	Label       string `json:"label"`
+	Description string `json:"description"`
+}
+
+// handleRunDetail returns (GET) or annotates (POST) a single run: /api/runs/{runID}
+func handleRunDetail(w http.ResponseWriter, r *http.Request) {
+	runID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/runs/"), "/")
+	if id, ok := strings.CutSuffix(runID, "/resume"); ok {
+		if !logic.ValidRunID(id) {
+			http.Error(w, "Invalid run ID", http.StatusBadRequest)
+			return
+		}
+		handleRunResume(w, r, id)
+		return
+	}
+	if !logic.ValidRunID(runID) {
+		http.Error(w, "Invalid run ID", http.StatusBadRequest)
+		return
+	}
+
+	var rec *logic.RunRecord
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		rec, err = logic.LoadRunRecord(runID)
+	case http.MethodPost:
+		var req RunAnnotationRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		rec, err = logic.AnnotateRun(runID, req.Label, req.Description)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 	if err != nil {
-		return "", err
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
 	}
-	return strings.TrimSpace(string(output)), nil
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rec)
 }
 
-type AnalyzeSpringRequest struct {
-	RootPath      string   `json:"RootPath"`
-	Excluded      []string `json:"Excluded"`
-	TargetVersion string   `json:"TargetVersion"`
-	MigrationType string   `json:"MigrationType"` // "spring-boot", "java-version", "jakarta-ee", "quarkus"
+// ProfileRequest saves a run configuration under a name
+type ProfileRequest struct {
+	Name    string     `json:"name"`
+	Request RunRequest `json:"request"`
 }
 
-// AnalysisResult holds the result of analyzing a single repo
-type AnalysisResult struct {
-	Index    int
-	RepoName string
-	Output   string
-	Success  bool
-	Duration time.Duration
+// handleProfiles lists (GET) or saves (POST) run profiles: /api/profiles
+func handleProfiles(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		profiles, err := logic.ListProfiles()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(profiles)
+	case http.MethodPost:
+		var req ProfileRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if issues := logic.ValidateReplacements(req.Request.Replacements); len(issues) > 0 {
+			http.Error(w, fmt.Sprintf("Invalid replacement '%s': %s", issues[0].Search, issues[0].Error), http.StatusBadRequest)
+			return
+		}
+		// A profile is a reusable configuration, not a continuation of a specific run
+		req.Request.Repos = nil
+		data, err := json.Marshal(req.Request)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		profile, err := logic.SaveProfile(req.Name, data)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(profile)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
 }
 
-// Current OpenRewrite versions used in this app
-const (
-	openRewritePluginVersion      = "6.24.0"
-	openRewriteRecipeVersion      = "6.19.0"
-	openRewriteMigrateJavaVersion = "3.22.0"
-	openRewriteQuarkusVersion     = "2.28.1"
-)
+// handleProfileDetail returns (GET) or deletes (DELETE) a profile: /api/profiles/{id},
+// and executes it: POST /api/profiles/{id}/run
+func handleProfileDetail(w http.ResponseWriter, r *http.Request) {
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/profiles/"), "/")
+	id, run := strings.CutSuffix(id, "/run")
+	if !logic.ValidProfileID(id) {
+		http.Error(w, "Invalid profile ID", http.StatusBadRequest)
+		return
+	}
+	if run {
+		handleProfileRun(w, r, id)
+		return
+	}
 
-func handleAnalyzeSpring(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		profile, err := logic.LoadProfile(id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(profile)
+	case http.MethodDelete:
+		if err := logic.DeleteProfile(id); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleProfileRun executes the saved run request of a profile
+func handleProfileRun(w http.ResponseWriter, r *http.Request, id string) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	var req AnalyzeSpringRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	profile, err := logic.LoadProfile(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	var req RunRequest
+	if err := json.Unmarshal(profile.Request, &req); err != nil {
+		http.Error(w, "Stored profile request is unreadable: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if req.Label == "" {
+		req.Label = profile.Name
+	}
 
+	// Set headers for streaming
 	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
 		return
 	}
 
-	// 1. Find Repos
-	var repos []string
-	if logic.IsGitRepo(req.RootPath) {
-		repos = []string{req.RootPath}
-	} else {
-		repos = logic.FindGitRepos(req.RootPath, req.Excluded)
+	fmt.Fprintf(w, "Running profile '%s'...\n", profile.Name)
+	executeRun(w, flusher, req, newRunRecord(req))
+}
+
+// ==================== EMAIL DELIVERY ====================
+
+// emailReport sends a report to the given recipients or the default list of the SMTP settings
+func emailReport(to []string, subject, html string, attachments ...logic.Attachment) ([]string, error) {
+	settings, err := logic.LoadSMTPSettings()
+	if err != nil {
+		return nil, err
+	}
+	recipients, err := logic.ParseRecipients(to)
+	if err != nil {
+		return nil, err
 	}
+	if len(recipients) == 0 {
+		recipients = settings.Recipients
+	}
+	email := logic.Email{To: recipients, Subject: subject, HTML: html, Attachments: attachments}
+	return recipients, logic.SendEmail(settings, email)
+}
 
-	if len(repos) == 0 {
-		fmt.Fprintf(w, "No Git projects found under '%s'.\n", req.RootPath)
-		flusher.Flush()
+// handleSMTPSettings returns (GET, without password) or saves (POST) the SMTP settings: /api/smtp-settings
+func handleSMTPSettings(w http.ResponseWriter, r *http.Request) {
+	var settings logic.SMTPSettings
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		settings, err = logic.LoadSMTPSettings()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if settings, err = logic.SaveSMTPSettings(settings); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	fmt.Fprintf(w, "PROGRESS_INIT:%d\n", len(repos))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		logic.SMTPSettings
+		HasPassword bool `json:"hasPassword"`
+	}{settings.Redacted(), settings.Password != ""})
+}
 
-	migrationLabel := "Spring Boot"
-	switch req.MigrationType {
-	case "java-version":
-		migrationLabel = "Java"
-	case "jakarta-ee":
-		migrationLabel = "Jakarta EE"
-	case "quarkus":
-		migrationLabel = "Quarkus"
+// handleSMTPTest sends a test email: POST /api/smtp-test {"to": [...]}
+func handleSMTPTest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		To []string `json:"to"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	recipients, err := emailReport(req.To, "GitHousekeeper test email", "<p>The SMTP settings of GitHousekeeper work.</p>")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
 	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"sent": true, "recipients": recipients})
+}
 
-	fmt.Fprintf(w, "Found %d projects. Starting parallel analysis for %s %s...\n", len(repos), migrationLabel, req.TargetVersion)
-	fmt.Fprintf(w, "(Processing in background, results will appear as each project completes)\n\n")
-	flusher.Flush()
+// ==================== NETWORK ====================
 
-	overallStart := time.Now()
+// handleNetworkSettings returns (GET, without password) or saves and applies (POST) the proxy, CA bundle,
+// timeout, offline and Maven repository settings of the outbound requests: /api/network-settings
+func handleNetworkSettings(w http.ResponseWriter, r *http.Request) {
+	var settings logic.NetworkSettings
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		settings, err = logic.LoadNetworkSettings()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if settings, err = logic.SaveNetworkSettings(settings); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Info("Network settings updated", "offline", settings.Offline, "proxy", settings.HTTPProxy != "" || settings.HTTPSProxy != "", "mavenRepository", settings.MavenRepository)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 
-	// 2. Determine Recipe and Coordinates
-	var recipe string
-	var coordinates string
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		logic.NetworkSettings
+		HasMavenPassword bool `json:"hasMavenPassword"`
+	}{settings.Redacted(), settings.MavenPassword != ""})
+}
 
-	switch req.MigrationType {
-	case "java-version":
-		// TargetVersion e.g. "17", "21", "25"
-		// Correct recipe name is UpgradeToJava<Version>
-		recipe = fmt.Sprintf("org.openrewrite.java.migrate.UpgradeToJava%s", req.TargetVersion)
-		coordinates = fmt.Sprintf("org.openrewrite.recipe:rewrite-migrate-java:%s", openRewriteMigrateJavaVersion)
-	case "jakarta-ee":
-		recipe = "org.openrewrite.java.migrate.jakarta.JavaxMigrationToJakarta"
-		coordinates = fmt.Sprintf("org.openrewrite.recipe:rewrite-migrate-java:%s", openRewriteMigrateJavaVersion)
-	case "quarkus":
-		// Quarkus migration is complex and project-specific
-		// Return an informative message instead of running a recipe
-		fmt.Fprintf(w, "⚠️ Quarkus Migration Information\n\n")
-		fmt.Fprintf(w, "Quarkus migration requires a project-specific approach:\n\n")
-		fmt.Fprintf(w, "For Spring Boot → Quarkus:\n")
-		fmt.Fprintf(w, "- Use Quarkus CLI: 'quarkus create app' to generate new structure\n")
-		fmt.Fprintf(w, "- Migrate dependencies manually using Quarkus guides\n")
-		fmt.Fprintf(w, "- Replace Spring annotations with Quarkus equivalents\n\n")
-		fmt.Fprintf(w, "For Quarkus Version Upgrades:\n")
-		fmt.Fprintf(w, "- Use 'quarkus update' command directly in your project\n")
-		fmt.Fprintf(w, "- Or update versions in pom.xml and run tests\n\n")
-		fmt.Fprintf(w, "Resources:\n")
-		fmt.Fprintf(w, "- Migration Guide: https://quarkus.io/guides/migration-guide\n")
-		fmt.Fprintf(w, "- Spring to Quarkus: https://quarkus.io/blog/spring-boot-to-quarkus/\n\n")
-		flusher.Flush()
-		return
-	default: // "spring-boot" or empty
-
-		// OpenRewrite only has recipes for minor versions (e.g., 3.5), not patch versions (e.g., 3.5.8)
-		// Extract only major.minor from the target version
-		minorVersion := req.TargetVersion
-		versionParts := strings.Split(req.TargetVersion, ".")
-		if len(versionParts) >= 2 {
-			minorVersion = versionParts[0] + "." + versionParts[1]
+// handleRetrySettings returns (GET) or saves (POST) the retry policies of network git commands, Maven
+// and security scanners: /api/retry-settings
+func handleRetrySettings(w http.ResponseWriter, r *http.Request) {
+	var settings logic.RetrySettings
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		settings, err = logic.LoadRetrySettings()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
 		}
-		cleanVersion := strings.ReplaceAll(minorVersion, ".", "_")
-		if strings.HasPrefix(req.TargetVersion, "3.") {
-			recipe = fmt.Sprintf("org.openrewrite.java.spring.boot3.UpgradeSpringBoot_%s", cleanVersion)
-		} else if strings.HasPrefix(req.TargetVersion, "2.") {
-			recipe = fmt.Sprintf("org.openrewrite.java.spring.boot2.UpgradeSpringBoot_%s", cleanVersion)
-		} else {
-			// Fallback
-			recipe = fmt.Sprintf("org.openrewrite.java.spring.boot%c.UpgradeSpringBoot_%s", req.TargetVersion[0], cleanVersion)
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
-		coordinates = fmt.Sprintf("org.openrewrite.recipe:rewrite-spring:%s", openRewriteRecipeVersion)
+		if settings, err = logic.SaveRetrySettings(settings); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Info("Retry settings updated", "git", settings.Git.Attempts, "maven", settings.Maven.Attempts, "scanners", settings.Scanners.Attempts)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
 
-	// Use globally defined plugin versions
-	pluginVersion := openRewritePluginVersion
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
 
-	// 3. Send list of repos that will be analyzed (for live status display)
-	for _, repo := range repos {
-		fmt.Fprintf(w, "REPO_QUEUED:%s\n", filepath.Base(repo))
-	}
-	flusher.Flush()
+// ==================== REPOSITORY GROUPS ====================
 
-	// 4. Run Analysis in Parallel
-	resultChan := make(chan AnalysisResult, len(repos))
+// GroupRequest saves a repository group. Relative entries are resolved against RootPath,
+// so the UI can send the selected folder names.
+type GroupRequest struct {
+	Name     string   `json:"name"`
+	RootPath string   `json:"rootPath"`
+	Repos    []string `json:"repos"`
+}
 
-	for i, repo := range repos {
-		go func(index int, repoPath string) {
-			result := analyzeRepo(index, repoPath, recipe, pluginVersion, coordinates)
-			resultChan <- result
-		}(i, repo)
+// findRepos discovers the repos under the root path and the further root paths of a request
+func findRepos(rootPath string, rootPaths, excluded []string) []string {
+	var repos []string
+	if len(rootPaths) == 0 {
+		repos = logic.FindGitRepos(rootPath, excluded)
+	} else {
+		repos = logic.FindGitReposInRoots(append([]string{rootPath}, rootPaths...), excluded)
 	}
-
-	// 5. Collect and output results in order of completion
-	completed := 0
-	var totalDuration time.Duration
-	for completed < len(repos) {
-		result := <-resultChan
-		completed++
-		totalDuration += result.Duration
-
-		// Send repo completion status
-		statusMarker := "SUCCESS"
-		if !result.Success {
-			statusMarker = "FAILED"
-		}
-		fmt.Fprintf(w, "REPO_DONE:%s:%s:%.1f\n", result.RepoName, statusMarker, result.Duration.Seconds())
-
-		// Calculate average time per project and estimate remaining
-		avgDuration := totalDuration / time.Duration(completed)
-		remaining := len(repos) - completed
-		estimatedRemaining := avgDuration * time.Duration(remaining)
-
-		// Output progress update
-		fmt.Fprintf(w, "PROGRESS_UPDATE:%d:%d:%.1f\n", completed, len(repos), estimatedRemaining.Seconds())
-
-		// Output the complete result block for this repo
-		statusIcon := "✓"
-		if !result.Success {
-			statusIcon = "✗"
+	if !pathSandbox.Enabled() {
+		return repos
+	}
+	// A symlinked folder under an allowed root may lead out of it
+	allowed := repos[:0]
+	for _, repo := range repos {
+		if err := pathSandbox.Check(repo); err != nil {
+			slog.Warn("Repository outside the allowed roots skipped", "path", repo, "error", err)
+			continue
 		}
-		fmt.Fprintf(w, ">>> [%d/%d] %s %s (%.1fs)\n", completed, len(repos), statusIcon, result.RepoName, result.Duration.Seconds())
-		fmt.Fprintf(w, "%s", result.Output)
-		fmt.Fprintf(w, "\n")
-		flusher.Flush()
+		allowed = append(allowed, repo)
 	}
-
-	close(resultChan)
-
-	// Final summary
-	overallDuration := time.Since(overallStart)
-	fmt.Fprintf(w, "PROGRESS_DONE:%.1f\n", overallDuration.Seconds())
-	flusher.Flush()
+	return allowed
 }
 
-// analyzeRepo performs the OpenRewrite analysis on a single repository
-func analyzeRepo(index int, repoPath, recipe, pluginVersion, recipeArtifactCoordinates string) AnalysisResult {
-	startTime := time.Now()
-	repoName := filepath.Base(repoPath)
-	var output strings.Builder
-
-	// Check if it's a Maven project
-	if _, err := os.Stat(filepath.Join(repoPath, "pom.xml")); os.IsNotExist(err) {
-		output.WriteString("Skipping (no pom.xml)\n")
-		return AnalysisResult{Index: index, RepoName: repoName, Output: output.String(), Success: true, Duration: time.Since(startTime)}
+// resolveRepos returns the repos of the group, or the repos discovered under the root paths without a group
+func resolveRepos(rootPath string, rootPaths, excluded []string, group string) ([]string, error) {
+	if group == "" {
+		return findRepos(rootPath, rootPaths, excluded), nil
 	}
-
-	// Try up to 2 times (retry once on failure - helps with Maven cache issues)
-	maxRetries := 2
-	var lastError error
-	var cmdOutput []byte
-
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		// Construct Maven Command
-		cmd := exec.Command("mvn",
-			"-U",
-			"-B",
-			fmt.Sprintf("org.openrewrite.maven:rewrite-maven-plugin:%s:dryRun", pluginVersion),
-			fmt.Sprintf("-Drewrite.recipeArtifactCoordinates=%s", recipeArtifactCoordinates),
-			fmt.Sprintf("-Drewrite.activeRecipes=%s", recipe),
-		)
-		cmd.Dir = repoPath
-
-		cmdOutput, lastError = cmd.CombinedOutput()
-		if lastError == nil {
-			// Success - break out of retry loop
-			break
-		}
-
-		// If this was the first attempt and it failed, retry
-		if attempt < maxRetries {
-			// Brief pause before retry
-			time.Sleep(500 * time.Millisecond)
-			continue
+	g, err := logic.LoadGroup(group)
+	if err != nil {
+		return nil, err
+	}
+	repos, missing := g.Resolve(excluded)
+	if len(missing) > 0 {
+		slog.Warn("Repository group has missing entries", "group", g.Name, "missing", missing)
+	}
+	// Groups are stored server-side, so their paths never passed the request sandbox
+	for _, repo := range repos {
+		if err := pathSandbox.Check(repo); err != nil {
+			return nil, fmt.Errorf("group '%s': %v", g.Name, err)
 		}
 	}
+	return repos, nil
+}
 
-	// If still failed after retries
-	if lastError != nil {
-		output.WriteString(fmt.Sprintf("Error running OpenRewrite: %v\n", lastError))
-		lines := strings.Split(string(cmdOutput), "\n")
-		start := len(lines) - 10
-		if start < 0 {
-			start = 0
+// handleGroups lists (GET) or saves (POST) repository groups: /api/groups
+func handleGroups(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		groups, err := logic.ListGroups()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
 		}
-		for _, line := range lines[start:] {
-			output.WriteString(fmt.Sprintf("  %s\n", line))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(groups)
+	case http.MethodPost:
+		var req GroupRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
-		return AnalysisResult{Index: index, RepoName: repoName, Output: output.String(), Success: false, Duration: time.Since(startTime)}
-	}
-
-	// Check for patch file
-	patchFile := filepath.Join(repoPath, "target", "rewrite", "rewrite.patch")
-	if _, err := os.Stat(patchFile); err == nil {
-		content, err := os.ReadFile(patchFile)
-		if err == nil && len(content) > 0 {
-			// Parse and summarize the patch
-			summary := parsePatchToSummary(string(content))
-			output.WriteString(summary)
-		} else {
-			output.WriteString("✅ No changes required.\n")
+		repos := make([]string, 0, len(req.Repos))
+		for _, repo := range req.Repos {
+			if repo != "" && !filepath.IsAbs(repo) && req.RootPath != "" {
+				repo = filepath.Join(req.RootPath, repo)
+			}
+			if err := pathSandbox.Check(repo); err != nil {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			repos = append(repos, repo)
 		}
-	} else {
-		if strings.Contains(string(cmdOutput), "No changes") {
-			output.WriteString("✅ No changes required.\n")
-		} else {
-			output.WriteString("Analysis finished (no patch file generated).\n")
+		group, err := logic.SaveGroup(req.Name, repos)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(group)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
-
-	return AnalysisResult{Index: index, RepoName: repoName, Output: output.String(), Success: true, Duration: time.Since(startTime)}
 }
 
-// parsePatchToSummary converts a raw patch file into a readable summary
-func parsePatchToSummary(patch string) string {
-	var summary strings.Builder
-
-	// Track changes by category
-	categories := map[string][]string{
-		"🔄 Annotation Updates":       {},
-		"📦 Import Changes":           {},
-		"🛠️ Code Modernization":      {},
-		"⚙️ Configuration Changes":   {},
-		"🗑️ Deprecated Code Removal": {},
+// handleGroupDetail returns (GET) or deletes (DELETE) a repository group: /api/groups/{id}
+func handleGroupDetail(w http.ResponseWriter, r *http.Request) {
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/groups/"), "/")
+	if !logic.ValidProfileID(id) {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
 	}
 
-	// Track files changed
-	filesChanged := []string{}
-	currentFile := ""
-
-	lines := strings.Split(patch, "\n")
-	for i, line := range lines {
-		// Track file names
-		if strings.HasPrefix(line, "diff --git") {
-			parts := strings.Split(line, " ")
-			if len(parts) >= 4 {
-				file := strings.TrimPrefix(parts[2], "a/")
-				currentFile = file
-				filesChanged = append(filesChanged, file)
-			}
-			continue
+	switch r.Method {
+	case http.MethodGet:
+		group, err := logic.LoadGroup(id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
 		}
-
-		// Skip header lines
-		if strings.HasPrefix(line, "index ") || strings.HasPrefix(line, "---") || strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "@@") {
-			continue
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(group)
+	case http.MethodDelete:
+		if err := logic.DeleteGroup(id); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
 		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
 
-		// Analyze removed lines (-)
-		if strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---") {
-			removed := strings.TrimPrefix(line, "-")
-			removed = strings.TrimSpace(removed)
-
-			// Look for the corresponding added line
-			added := ""
-			for j := i + 1; j < len(lines) && j < i+5; j++ {
-				if strings.HasPrefix(lines[j], "+") && !strings.HasPrefix(lines[j], "+++") {
-					added = strings.TrimPrefix(lines[j], "+")
-					added = strings.TrimSpace(added)
-					break
-				}
-			}
-
-			// Categorize changes
-			shortFile := filepath.Base(currentFile)
-
-			// RequestMapping -> GetMapping/PostMapping/etc.
-			if strings.Contains(removed, "@RequestMapping") && strings.Contains(removed, "RequestMethod") {
-				if strings.Contains(added, "@GetMapping") {
-					categories["🔄 Annotation Updates"] = append(categories["🔄 Annotation Updates"],
-						fmt.Sprintf("%s: @RequestMapping(method=GET) → @GetMapping", shortFile))
-				} else if strings.Contains(added, "@PostMapping") {
-					categories["🔄 Annotation Updates"] = append(categories["🔄 Annotation Updates"],
-						fmt.Sprintf("%s: @RequestMapping(method=POST) → @PostMapping", shortFile))
-				} else if strings.Contains(added, "@PutMapping") {
-					categories["🔄 Annotation Updates"] = append(categories["🔄 Annotation Updates"],
-						fmt.Sprintf("%s: @RequestMapping(method=PUT) → @PutMapping", shortFile))
-				} else if strings.Contains(added, "@DeleteMapping") {
-					categories["🔄 Annotation Updates"] = append(categories["🔄 Annotation Updates"],
-						fmt.Sprintf("%s: @RequestMapping(method=DELETE) → @DeleteMapping", shortFile))
-				}
-			}
-
-			// Import changes
-			if strings.Contains(removed, "import ") && strings.Contains(added, "import ") {
-				oldImport := strings.TrimPrefix(removed, "import ")
-				oldImport = strings.TrimSuffix(oldImport, ";")
-				newImport := strings.TrimPrefix(added, "import ")
-				newImport = strings.TrimSuffix(newImport, ";")
-				if oldImport != newImport {
-					// Only show significant import changes
-					if strings.Contains(oldImport, "RequestMethod") {
-						// Skip, already covered by annotation changes
-					} else {
-						categories["📦 Import Changes"] = append(categories["📦 Import Changes"],
-							fmt.Sprintf("%s: %s", shortFile, filepath.Base(newImport)))
-					}
-				}
-			}
-
-			// HibernateProxy pattern matching
-			if strings.Contains(removed, "instanceof HibernateProxy") && strings.Contains(removed, "((HibernateProxy)") {
-				if strings.Contains(added, "instanceof HibernateProxy hp") {
-					categories["🛠️ Code Modernization"] = append(categories["🛠️ Code Modernization"],
-						fmt.Sprintf("%s: instanceof + cast → Pattern Matching (Java 16+)", shortFile))
-				}
-			}
-
-			// String.format -> formatted
-			if strings.Contains(removed, "String.format(") && strings.Contains(added, ".formatted(") {
-				categories["🛠️ Code Modernization"] = append(categories["🛠️ Code Modernization"],
-					fmt.Sprintf("%s: String.format() → String.formatted()", shortFile))
-			}
-
-			// @Autowired removal
-			if strings.Contains(removed, "@Autowired") && !strings.Contains(added, "@Autowired") {
-				categories["🗑️ Deprecated Code Removal"] = append(categories["🗑️ Deprecated Code Removal"],
-					fmt.Sprintf("%s: Removed unnecessary @Autowired (constructor injection)", shortFile))
-			}
-
-			// Configuration property changes
-			if strings.HasSuffix(currentFile, ".properties") || strings.HasSuffix(currentFile, ".yml") || strings.HasSuffix(currentFile, ".yaml") {
-				if strings.Contains(removed, "=") || strings.Contains(removed, ":") {
-					if strings.Contains(line, "deprecated") || strings.Contains(added, "#") {
-						propName := strings.Split(removed, "=")[0]
-						propName = strings.Split(propName, ":")[0]
-						propName = strings.TrimSpace(propName)
-						if propName != "" && !strings.HasPrefix(propName, "#") {
-							categories["⚙️ Configuration Changes"] = append(categories["⚙️ Configuration Changes"],
-								fmt.Sprintf("%s: Property '%s' deprecated/changed", shortFile, propName))
-						}
-					}
-				}
-			}
+// handleWorkspaces lists (GET) or saves (POST) workspaces: /api/workspaces. Tokens are never sent back.
+func handleWorkspaces(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		workspaces, err := logic.ListWorkspaces()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for i := range workspaces {
+			workspaces[i] = workspaces[i].Redacted()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(workspaces)
+	case http.MethodPost:
+		var req logic.Workspace
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := pathSandbox.Check(req.RootPath); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
 		}
+		workspace, err := logic.SaveWorkspace(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Info("Workspace saved", "workspace", workspace.ID, "rootPath", workspace.RootPath)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(workspace.Redacted())
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
+}
 
-	// Build HTML summary output for better readability
-	summary.WriteString(`<div class="migration-summary">`)
-	summary.WriteString(`<h2 style="margin:0 0 15px 0; color:#cdd6f4; border-bottom:2px solid #89b4fa; padding-bottom:10px;">📋 Migration Summary</h2>`)
-
-	// Files overview
-	summary.WriteString(fmt.Sprintf(`<div class="summary-section"><h3 style="color:#89b4fa; margin:15px 0 10px 0;">📁 Files affected: %d</h3>`, len(filesChanged)))
-	summary.WriteString(`<div style="display:flex; flex-wrap:wrap; gap:5px; margin-left:10px;">`)
-	for _, f := range filesChanged {
-		shortFile := filepath.Base(f)
-		summary.WriteString(fmt.Sprintf(`<span style="background:#313244; padding:3px 8px; border-radius:4px; font-size:0.85em;">%s</span>`, shortFile))
+// handleWorkspaceDetail returns (GET) or deletes (DELETE) a workspace: /api/workspaces/{id}
+func handleWorkspaceDetail(w http.ResponseWriter, r *http.Request) {
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/workspaces/"), "/")
+	if !logic.ValidProfileID(id) {
+		http.Error(w, "Invalid workspace ID", http.StatusBadRequest)
+		return
 	}
-	summary.WriteString(`</div></div>`)
 
-	// Changes by category - use ordered slice for consistent output
-	categoryOrder := []string{
-		"🔄 Annotation Updates",
-		"📦 Import Changes",
-		"🛠️ Code Modernization",
-		"⚙️ Configuration Changes",
-		"🗑️ Deprecated Code Removal",
+	switch r.Method {
+	case http.MethodGet:
+		workspace, err := logic.LoadWorkspace(id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(workspace.Redacted())
+	case http.MethodDelete:
+		if err := logic.DeleteWorkspace(id); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		slog.Info("Workspace deleted", "workspace", id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
+}
 
-	hasChanges := false
-	for _, category := range categoryOrder {
-		changes := categories[category]
-		if len(changes) > 0 {
-			hasChanges = true
-			// Deduplicate
-			unique := make(map[string]bool)
-			for _, c := range changes {
-				unique[c] = true
-			}
+// Cache for Spring versions to avoid repeated Maven Central calls
+var (
+	springVersionsCache     []logic.SpringVersionInfo
+	springVersionsCacheTime time.Time
+	springVersionsCacheTTL  = 5 * time.Minute
+)
 
-			summary.WriteString(fmt.Sprintf(`<div class="summary-section" style="margin-top:20px;"><h3 style="color:#a6e3a1; margin:0 0 10px 0;">%s <span style="background:#45475a; padding:2px 8px; border-radius:10px; font-size:0.8em;">%d</span></h3>`, category, len(unique)))
-			summary.WriteString(`<table style="width:100%; border-collapse:collapse; font-size:0.9em;">`)
-			for change := range unique {
-				// Split change into file and description
-				parts := strings.SplitN(change, ": ", 2)
-				file := parts[0]
-				desc := ""
-				if len(parts) > 1 {
-					desc = parts[1]
-				}
-				summary.WriteString(fmt.Sprintf(`<tr style="border-bottom:1px solid #313244;"><td style="padding:6px 10px; color:#f9e2af; white-space:nowrap; width:1%%;">%s</td><td style="padding:6px 10px; color:#cdd6f4;">%s</td></tr>`, file, desc))
-			}
-			summary.WriteString(`</table></div>`)
-		}
+func handleSpringVersions(w http.ResponseWriter, r *http.Request) {
+	// Check cache
+	if springVersionsCache != nil && time.Since(springVersionsCacheTime) < springVersionsCacheTTL {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Cache", "HIT")
+		json.NewEncoder(w).Encode(springVersionsCache)
+		return
 	}
 
-	if !hasChanges {
-		summary.WriteString(`<div class="summary-section" style="margin-top:20px; padding:15px; background:#313244; border-radius:8px;">`)
-		summary.WriteString(`<p style="margin:0; color:#f9e2af;">ℹ️ Changes detected but could not be automatically categorized.</p>`)
-		summary.WriteString(`<p style="margin:5px 0 0 0; color:#a6adc8;">Run with full patch output for details.</p>`)
-		summary.WriteString(`</div>`)
+	versions, err := logic.GetSpringVersions()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	summary.WriteString(`<div style="margin-top:20px; padding:12px; background:#1e1e2e; border-left:3px solid #89b4fa; border-radius:4px;">`)
-	summary.WriteString(`<p style="margin:0; color:#89b4fa;">💡 <strong>Tip:</strong> These are recommended changes for your Spring Boot upgrade. Review each change before applying.</p>`)
-	summary.WriteString(`</div>`)
-	summary.WriteString(`</div>`)
+	// Update cache
+	springVersionsCache = versions
+	springVersionsCacheTime = time.Now()
 
-	return summary.String()
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Cache", "MISS")
+	json.NewEncoder(w).Encode(versions)
 }
 
-func handleDashboardStats(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-	var req ScanRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+// handleQuarkusStreams lists the released Quarkus streams, newest first (cached by the logic package)
+func handleQuarkusStreams(w http.ResponseWriter, r *http.Request) {
+	streams, err := logic.QuarkusStreams()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
 		return
 	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(streams)
+}
 
-	// Set headers for streaming NDJSON
-	w.Header().Set("Content-Type", "application/x-ndjson")
-	w.Header().Set("Transfer-Encoding", "chunked")
-	w.Header().Set("X-Content-Type-Options", "nosniff")
-
-	flusher, ok := w.(http.Flusher)
-	if !ok {
-		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+// handleNodeLTS lists the supported Node.js LTS lines (endoflife.date, cached by the logic package)
+func handleNodeLTS(w http.ResponseWriter, r *http.Request) {
+	versions, err := logic.NodeLTSVersions(time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
 		return
 	}
-
-	// Use mutex to protect concurrent writes to ResponseWriter
-	var mu sync.Mutex
-	logic.StreamDashboardStats(req.RootPath, req.Excluded, func(result interface{}) {
-		mu.Lock()
-		defer mu.Unlock()
-		json.NewEncoder(w).Encode(result)
-		flusher.Flush()
-	})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(versions)
 }
 
-// BranchInfo represents a branch with its tracking status
-type BranchInfo struct {
-	Name       string `json:"name"`
-	IsTracking bool   `json:"isTracking"`
-	Remote     string `json:"remote"`
-	Ahead      int    `json:"ahead"`
-	Behind     int    `json:"behind"`
-}
+// Current OpenRewrite versions used in this app
+// Moved to type definition area
 
-// RepoWithBranches represents a repository and its branches
-type RepoWithBranches struct {
-	Name          string       `json:"name"`
-	Path          string       `json:"path"`
-	DefaultBranch string       `json:"defaultBranch"`
-	Branches      []BranchInfo `json:"branches"`
+// Cache for OpenRewrite versions
+var (
+	openRewriteVersionsCache     []logic.OpenRewriteVersionInfo
+	openRewriteVersionsCacheTime time.Time
+	openRewriteVersionsCacheTTL  = 10 * time.Minute
+)
+
+func handleOpenRewriteVersions(w http.ResponseWriter, r *http.Request) {
+	// Check cache
+	if openRewriteVersionsCache != nil && time.Since(openRewriteVersionsCacheTime) < openRewriteVersionsCacheTTL {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Cache", "HIT")
+		json.NewEncoder(w).Encode(openRewriteVersionsCache)
+		return
+	}
+
+	versions, err := logic.GetOpenRewriteVersions(openRewritePluginVersion, openRewriteRecipeVersion)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Update cache
+	openRewriteVersionsCache = versions
+	openRewriteVersionsCacheTime = time.Now()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Cache", "MISS")
+	json.NewEncoder(w).Encode(versions)
 }
 
-type ListBranchesRequest struct {
-	RootPath string   `json:"rootPath"`
-	Excluded []string `json:"excluded"`
+type ScanRequest struct {
+	RootPath    string
+	RootPaths   []string // Further roots searched together with RootPath
+	Excluded    []string
+	Group       string   // Optional repository group instead of RootPath
+	IgnorePaths []string // Path patterns skipped by TODO counting and health checks
+	Maven       logic.MavenSettings
 }
 
-func handleListBranches(w http.ResponseWriter, r *http.Request) {
+func handleScanSpring(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	var req ScanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	var req ListBranchesRequest
+	var results logic.SpringScanResult
+	if req.Group == "" && len(req.RootPaths) == 0 {
+		results = logic.ScanProjectsForSpring(req.RootPath, req.Excluded, req.Maven)
+	} else {
+		repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		results.Projects = []logic.ProjectSpringStatus{}
+		for _, repo := range repos {
+			repoResult := logic.ScanProjectsForSpring(repo, req.Excluded, req.Maven)
+			results.Projects = append(results.Projects, repoResult.Projects...)
+			results.DebugLog = append(results.DebugLog, repoResult.DebugLog...)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+type ListFoldersRequest struct {
+	Path string
+}
+
+type ListFoldersResponse struct {
+	IsRepo  bool
+	Folders []string
+	Error   string `json:",omitempty"`
+}
+
+func handleListFolders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req ListFoldersRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	repos := logic.FindGitRepos(req.RootPath, req.Excluded)
-	var result []RepoWithBranches
+	resp := ListFoldersResponse{}
 
-	for _, repoPath := range repos {
-		repoName := filepath.Base(repoPath)
+	// Check if path exists
+	info, err := os.Stat(req.Path)
+	if err != nil {
+		resp.Error = fmt.Sprintf("Path not found: %v", err)
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+	if !info.IsDir() {
+		resp.Error = "Path is not a directory"
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
 
-		// Get default branch
-		defaultBranch := getRepoDefaultBranch(repoPath)
+	// Check if it is a git repo
+	if logic.IsGitRepo(req.Path) {
+		resp.IsRepo = true
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
 
-		// Get all local branches with tracking info
-		branches := getRepoBranches(repoPath)
+	// List subdirectories
+	entries, err := os.ReadDir(req.Path)
+	if err != nil {
+		resp.Error = fmt.Sprintf("Could not read directory: %v", err)
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
 
-		result = append(result, RepoWithBranches{
-			Name:          repoName,
-			Path:          repoPath,
-			DefaultBranch: defaultBranch,
-			Branches:      branches,
-		})
+	for _, entry := range entries {
+		if entry.IsDir() {
+			resp.Folders = append(resp.Folders, entry.Name())
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(result)
+	json.NewEncoder(w).Encode(resp)
 }
 
-func getRepoDefaultBranch(repoPath string) string {
-	cmd := exec.Command("git", "symbolic-ref", "refs/remotes/origin/HEAD")
-	cmd.Dir = repoPath
-	output, err := cmd.Output()
-	if err == nil {
-		branch := strings.TrimPrefix(strings.TrimSpace(string(output)), "refs/remotes/origin/")
-		if branch != "" {
-			return branch
-		}
+func openBrowser(url string) {
+	var err error
+	switch runtime.GOOS {
+	case "linux":
+		err = exec.Command("xdg-open", url).Start()
+	case "windows":
+		err = exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+	case "darwin":
+		err = exec.Command("open", url).Start()
+	default:
+		err = fmt.Errorf("unsupported platform")
+	}
+	if err != nil {
+		slog.Warn("Could not open browser", "error", err)
 	}
+}
 
-	// Fallback: check if main exists
-	cmd = exec.Command("git", "show-ref", "--verify", "--quiet", "refs/heads/main")
-	cmd.Dir = repoPath
-	if cmd.Run() == nil {
-		return "main"
+func handlePickFolder(w http.ResponseWriter, r *http.Request) {
+	path, err := openFolderDialog()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	return "master"
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"path": path})
 }
 
-func getRepoBranches(repoPath string) []BranchInfo {
-	var branches []BranchInfo
+func openFolderDialog() (string, error) {
+	switch runtime.GOOS {
+	case "windows":
+		return openFolderDialogWindows()
+	case "darwin":
+		return openFolderDialogMac()
+	case "linux":
+		return openFolderDialogLinux()
+	default:
+		return "", fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+	}
+}
 
-	// Get local branches with their upstream tracking info
-	cmd := exec.Command("git", "for-each-ref", "--format=%(refname:short)|%(upstream:short)|%(upstream:track)", "refs/heads/")
-	cmd.Dir = repoPath
-	output, err := cmd.Output()
+func openFolderDialogWindows() (string, error) {
+	psScript := `
+		Add-Type -AssemblyName System.Windows.Forms
+		$f = New-Object System.Windows.Forms.FolderBrowserDialog
+		$f.ShowNewFolderButton = $true
+		if ($f.ShowDialog() -eq 'OK') {
+			Write-Host $f.SelectedPath
+		}
+	`
+	cmd := exec.Command("powershell", "-NoProfile", "-Command", psScript)
+	output, err := cmdlimit.Output(cmd)
 	if err != nil {
-		return branches
+		return "", err
 	}
+	return strings.TrimSpace(string(output)), nil
+}
 
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	for _, line := range lines {
-		if line == "" {
-			continue
-		}
-		parts := strings.Split(line, "|")
-		branchName := parts[0]
-		remote := ""
-		ahead := 0
-		behind := 0
-		isTracking := false
+func openFolderDialogMac() (string, error) {
+	script := `POSIX path of (choose folder)`
+	cmd := exec.Command("osascript", "-e", script)
+	output, err := cmdlimit.Output(cmd)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
 
-		if len(parts) > 1 && parts[1] != "" {
-			remote = parts[1]
-			isTracking = true
-		}
+func openFolderDialogLinux() (string, error) {
+	// Try zenity first (common on GNOME/GTK)
+	path, err := runCommandOutput("zenity", "--file-selection", "--directory")
+	if err == nil && path != "" {
+		return path, nil
+	}
 
-		if len(parts) > 2 && parts[2] != "" {
-			// Parse [ahead X, behind Y] or [ahead X] or [behind Y]
-			track := parts[2]
-			if strings.Contains(track, "ahead") {
-				fmt.Sscanf(track, "[ahead %d", &ahead)
-			}
-			if strings.Contains(track, "behind") {
-				if strings.Contains(track, "ahead") {
-					fmt.Sscanf(track, "[ahead %d, behind %d]", &ahead, &behind)
-				} else {
-					fmt.Sscanf(track, "[behind %d]", &behind)
-				}
-			}
-		}
+	// Try kdialog (common on KDE)
+	path, err = runCommandOutput("kdialog", "--getexistingdirectory")
+	if err == nil && path != "" {
+		return path, nil
+	}
 
-		branches = append(branches, BranchInfo{
-			Name:       branchName,
-			IsTracking: isTracking,
-			Remote:     remote,
-			Ahead:      ahead,
-			Behind:     behind,
-		})
+	return "", fmt.Errorf("no GUI dialog tool found (zenity or kdialog required)")
+}
+
+func runCommandOutput(name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	output, err := cmdlimit.Output(cmd)
+	if err != nil {
+		return "", err
 	}
+	return strings.TrimSpace(string(output)), nil
+}
 
-	return branches
+type AnalyzeSpringRequest struct {
+	RootPath      string              `json:"RootPath"`
+	RootPaths     []string            `json:"RootPaths"` // Further roots searched together with RootPath
+	Excluded      []string            `json:"Excluded"`
+	Group         string              `json:"Group"` // Optional repository group instead of RootPath
+	TargetVersion string              `json:"TargetVersion"`
+	MigrationType string              `json:"MigrationType"` // "spring-boot", "java-version", "jakarta-ee", "quarkus"
+	Maven         logic.MavenSettings `json:"Maven"`
 }
 
-type SyncBranchesRequest struct {
-	RootPath string   `json:"rootPath"`
-	Excluded []string `json:"excluded"`
+// AnalysisResult holds the result of analyzing a single repo
+type AnalysisResult struct {
+	Index    int
+	RepoName string
+	RepoPath string
+	Output   string
+	Success  bool
+	Duration time.Duration
+	Patch    *patch.Summary        // Parsed rewrite.patch, nil without changes
+	Delta    *logic.MigrationDelta // Comparison with the previous analysis of the same target, nil for the first
 }
 
-func handleSyncBranches(w http.ResponseWriter, r *http.Request) {
+// PatchSummaryEvent is streamed as "PATCH_SUMMARY:<json>" after the log block of a repo with changes
+type PatchSummaryEvent struct {
+	RepoName string                `json:"repoName"`
+	RepoPath string                `json:"repoPath"`
+	Summary  patch.Summary         `json:"summary"`
+	Delta    *logic.MigrationDelta `json:"delta,omitempty"`
+}
+
+// Current OpenRewrite versions used in this app
+const (
+	openRewritePluginVersion      = "6.24.0"
+	openRewriteRecipeVersion      = "6.19.0"
+	openRewriteMigrateJavaVersion = "3.22.0"
+	openRewriteQuarkusVersion     = "2.28.1"
+)
+
+func handleAnalyzeSpring(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	var req SyncBranchesRequest
+	var req AnalyzeSpringRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// Set headers for streaming
 	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 	w.Header().Set("Transfer-Encoding", "chunked")
-	w.Header().Set("X-Content-Type-Options", "nosniff")
-
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
 		return
 	}
 
-	repos := logic.FindGitRepos(req.RootPath, req.Excluded)
-	total := len(repos)
+	// 1. Find Repos
+	var repos []string
+	if req.Group == "" && len(req.RootPaths) == 0 && logic.IsGitRepo(req.RootPath) {
+		repos = []string{req.RootPath}
+	} else {
+		var err error
+		if repos, err = resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
 
-	fmt.Fprintf(w, "SYNC_INIT:%d\n", total)
+	if len(repos) == 0 {
+		fmt.Fprintf(w, "No Git projects found under '%s'.\n", req.RootPath)
+		flusher.Flush()
+		return
+	}
+
+	fmt.Fprintf(w, "PROGRESS_INIT:%d\n", len(repos))
+
+	migrationLabel := "Spring Boot"
+	switch req.MigrationType {
+	case "java-version":
+		migrationLabel = "Java"
+	case "jakarta-ee":
+		migrationLabel = "Jakarta EE"
+	case "quarkus":
+		migrationLabel = "Quarkus"
+	}
+
+	fmt.Fprintf(w, "Found %d projects. Starting parallel analysis for %s %s...\n", len(repos), migrationLabel, req.TargetVersion)
+	fmt.Fprintf(w, "(Processing in background, results will appear as each project completes)\n\n")
 	flusher.Flush()
 
-	for i, repoPath := range repos {
-		repoName := filepath.Base(repoPath)
-		fmt.Fprintf(w, "REPO_START:%s\n", repoName)
-		flusher.Flush()
+	overallStart := time.Now()
+
+	// 2. Determine the Maven goal: an OpenRewrite dryRun with recipe and coordinates, or the Quarkus update
+	var recipe string
+	var coordinates string
+	var goal []string
 
-		// Remember current branch
-		currentBranch := getCurrentBranch(repoPath)
+	switch req.MigrationType {
+	case "java-version":
+		// TargetVersion e.g. "17", "21", "25"
+		// Correct recipe name is UpgradeToJava<Version>
+		recipe = fmt.Sprintf("org.openrewrite.java.migrate.UpgradeToJava%s", req.TargetVersion)
+		coordinates = fmt.Sprintf("org.openrewrite.recipe:rewrite-migrate-java:%s", openRewriteMigrateJavaVersion)
+	case "jakarta-ee":
+		recipe = "org.openrewrite.java.migrate.jakarta.JavaxMigrationToJakarta"
+		coordinates = fmt.Sprintf("org.openrewrite.recipe:rewrite-migrate-java:%s", openRewriteMigrateJavaVersion)
+	case "quarkus":
+		// TargetVersion is a stream ("3.15") or empty for the latest one. The plugin has to be at least
+		// as new as the target to know its recipes; without Maven Central the project's plugin is used.
+		platformVersion, err := logic.QuarkusPlatformVersion(req.TargetVersion)
+		if err != nil {
+			fmt.Fprintf(w, "⚠️ Could not resolve the Quarkus platform version (%v), using the plugin version of each project.\n\n", err)
+		}
+		goal = logic.QuarkusUpdateArgs(platformVersion, req.TargetVersion)
+	default: // "spring-boot" or empty
 
-		// Fetch with prune
-		cmd := exec.Command("git", "fetch", "-p", "--all")
-		cmd.Dir = repoPath
-		if err := cmd.Run(); err != nil {
-			fmt.Fprintf(w, "  [WARNING] Fetch failed: %v\n", err)
+		// OpenRewrite only has recipes for minor versions (e.g., 3.5), not patch versions (e.g., 3.5.8)
+		// Extract only major.minor from the target version
+		minorVersion := req.TargetVersion
+		versionParts := strings.Split(req.TargetVersion, ".")
+		if len(versionParts) >= 2 {
+			minorVersion = versionParts[0] + "." + versionParts[1]
+		}
+		cleanVersion := strings.ReplaceAll(minorVersion, ".", "_")
+		if strings.HasPrefix(req.TargetVersion, "3.") {
+			recipe = fmt.Sprintf("org.openrewrite.java.spring.boot3.UpgradeSpringBoot_%s", cleanVersion)
+		} else if strings.HasPrefix(req.TargetVersion, "2.") {
+			recipe = fmt.Sprintf("org.openrewrite.java.spring.boot2.UpgradeSpringBoot_%s", cleanVersion)
 		} else {
-			fmt.Fprintf(w, "  Fetched all remotes\n")
+			// Fallback
+			recipe = fmt.Sprintf("org.openrewrite.java.spring.boot%c.UpgradeSpringBoot_%s", req.TargetVersion[0], cleanVersion)
 		}
-		flusher.Flush()
+		coordinates = fmt.Sprintf("org.openrewrite.recipe:rewrite-spring:%s", openRewriteRecipeVersion)
+	}
 
-		// Get all tracking branches and pull them
-		branches := getRepoBranches(repoPath)
-		for _, branch := range branches {
-			if !branch.IsTracking {
-				continue
-			}
+	if goal == nil {
+		goal = []string{
+			fmt.Sprintf("org.openrewrite.maven:rewrite-maven-plugin:%s:dryRun", openRewritePluginVersion),
+			fmt.Sprintf("-Drewrite.recipeArtifactCoordinates=%s", coordinates),
+			fmt.Sprintf("-Drewrite.activeRecipes=%s", recipe),
+		}
+	}
 
-			// Checkout branch
-			cmd = exec.Command("git", "checkout", branch.Name)
-			cmd.Dir = repoPath
-			if err := cmd.Run(); err != nil {
-				fmt.Fprintf(w, "  [WARNING] Could not checkout %s: %v\n", branch.Name, err)
-				continue
-			}
+	// 3. Send list of repos that will be analyzed (for live status display)
+	for _, repo := range repos {
+		fmt.Fprintf(w, "REPO_QUEUED:%s\n", filepath.Base(repo))
+	}
+	flusher.Flush()
 
-			// Pull
-			cmd = exec.Command("git", "pull", "--ff-only")
-			cmd.Dir = repoPath
-			if err := cmd.Run(); err != nil {
-				fmt.Fprintf(w, "  [WARNING] Pull %s failed (maybe conflicts): %v\n", branch.Name, err)
-			} else {
-				fmt.Fprintf(w, "  ✓ %s updated\n", branch.Name)
+	// 4. Run Analysis in Parallel
+	resultChan := make(chan AnalysisResult, len(repos))
+
+	for i, repo := range repos {
+		go func(index int, repoPath string) {
+			if req.MigrationType == "quarkus" && !logic.IsQuarkusProject(repoPath) {
+				resultChan <- AnalysisResult{Index: index, RepoName: filepath.Base(repoPath), RepoPath: repoPath, Output: "Skipping (no quarkus-bom in pom.xml)\n", Success: true}
+				return
+			}
+			result := analyzeRepo(index, repoPath, goal, req.Maven)
+			if result.Success {
+				recordMigrationAnalysis(&result, req.MigrationType, req.TargetVersion)
 			}
+			resultChan <- result
+		}(i, repo)
+	}
+
+	// 5. Collect and output results in order of completion
+	completed := 0
+	var totalDuration time.Duration
+	var progress logic.MigrationDelta
+	compared := 0
+	for completed < len(repos) {
+		result := <-resultChan
+		completed++
+		totalDuration += result.Duration
+		if result.Delta != nil {
+			compared++
+			progress.FilesFixed += result.Delta.FilesFixed
+			progress.FilesAdded += result.Delta.FilesAdded
+			progress.FindingsFixed += result.Delta.FindingsFixed
+			progress.FindingsNew += result.Delta.FindingsNew
 		}
 
-		// Switch back to original branch
-		if currentBranch != "" {
-			cmd = exec.Command("git", "checkout", currentBranch)
-			cmd.Dir = repoPath
-			cmd.Run()
+		// Send repo completion status
+		statusMarker := "SUCCESS"
+		if !result.Success {
+			statusMarker = "FAILED"
 		}
+		fmt.Fprintf(w, "REPO_DONE:%s:%s:%.1f\n", result.RepoName, statusMarker, result.Duration.Seconds())
+
+		// Calculate average time per project and estimate remaining
+		avgDuration := totalDuration / time.Duration(completed)
+		remaining := len(repos) - completed
+		estimatedRemaining := avgDuration * time.Duration(remaining)
+
+		// Output progress update
+		fmt.Fprintf(w, "PROGRESS_UPDATE:%d:%d:%.1f\n", completed, len(repos), estimatedRemaining.Seconds())
 
-		fmt.Fprintf(w, "REPO_DONE:%s\n", repoName)
-		fmt.Fprintf(w, "SYNC_PROGRESS:%d:%d\n", i+1, total)
+		// Output the complete result block for this repo
+		statusIcon := "✓"
+		if !result.Success {
+			statusIcon = "✗"
+		}
+		fmt.Fprintf(w, ">>> [%d/%d] %s %s (%.1fs)\n", completed, len(repos), statusIcon, result.RepoName, result.Duration.Seconds())
+		fmt.Fprintf(w, "%s", result.Output)
+		if result.Patch != nil {
+			if data, err := json.Marshal(PatchSummaryEvent{RepoName: result.RepoName, RepoPath: result.RepoPath, Summary: *result.Patch, Delta: result.Delta}); err == nil {
+				fmt.Fprintf(w, "PATCH_SUMMARY:%s\n", data)
+			}
+		}
+		fmt.Fprintf(w, "\n")
 		flusher.Flush()
 	}
 
-	fmt.Fprintf(w, "SYNC_COMPLETE\n")
-	flusher.Flush()
-}
+	close(resultChan)
 
-func getCurrentBranch(repoPath string) string {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	cmd.Dir = repoPath
-	output, err := cmd.Output()
-	if err != nil {
-		return ""
+	// Final summary
+	if compared > 0 {
+		fmt.Fprintf(w, "📈 Migration progress of %d repos since their previous analysis: %d files fixed, %d files newly affected, %d findings resolved, %d new findings\n",
+			compared, progress.FilesFixed, progress.FilesAdded, progress.FindingsFixed, progress.FindingsNew)
 	}
-	return strings.TrimSpace(string(output))
+	overallDuration := time.Since(overallStart)
+	fmt.Fprintf(w, "PROGRESS_DONE:%.1f\n", overallDuration.Seconds())
+	flusher.Flush()
 }
 
-// ==================== SECURITY SCAN ====================
-
-type SecurityScanRequest struct {
-	RootPath     string   `json:"rootPath"`
-	Excluded     []string `json:"excluded"`
-	Scanner      string   `json:"scanner"`      // "owasp", "trivy", "npm", or "auto"
-	TargetBranch string   `json:"targetBranch"` // Optional: branch to scan (empty = current branch)
-}
+// analyzeRepo runs the analysis goal (OpenRewrite dryRun or Quarkus update dry run) on a single repository
+func analyzeRepo(index int, repoPath string, goal []string, maven logic.MavenSettings) AnalysisResult {
+	startTime := time.Now()
+	repoName := filepath.Base(repoPath)
+	var output strings.Builder
 
-type CVEFinding struct {
-	CVE         string `json:"cve"`
-	Severity    string `json:"severity"` // CRITICAL, HIGH, MEDIUM, LOW
-	Package     string `json:"package"`
-	Version     string `json:"version"`
-	FixedIn     string `json:"fixedIn,omitempty"`
-	Description string `json:"description,omitempty"`
-}
+	// Check if it's a Maven project
+	if _, err := os.Stat(filepath.Join(repoPath, "pom.xml")); os.IsNotExist(err) {
+		output.WriteString("Skipping (no pom.xml)\n")
+		return AnalysisResult{Index: index, RepoName: repoName, RepoPath: repoPath, Output: output.String(), Success: true, Duration: time.Since(startTime)}
+	}
 
-type RepoSecurityResult struct {
-	RepoName      string       `json:"repoName"`
-	Findings      []CVEFinding `json:"findings"`
-	Error         string       `json:"error,omitempty"`
-	Duration      float64      `json:"duration"`
-	ProjectType   string       `json:"projectType,omitempty"`   // "maven", "npm", "yarn", "pnpm"
-	ScannedBranch string       `json:"scannedBranch,omitempty"` // The branch that was scanned
-}
+	// Every failure is retried, a second attempt often gets past Maven cache issues
+	var cmdOutput []byte
+	lastError := logic.Retry(logic.RetryMaven, nil, func() error {
+		cmd := maven.Command(repoPath, append([]string{"-U", "-B"}, goal...)...)
+		var err error
+		cmdOutput, err = cmdlimit.CombinedOutput(cmd)
+		return err
+	})
 
-// detectProjectType checks what kind of project this is
-func detectProjectType(repoPath string) string {
-	// Check for Maven
-	if _, err := os.Stat(filepath.Join(repoPath, "pom.xml")); err == nil {
-		return "maven"
-	}
-	// Check for Go
-	if _, err := os.Stat(filepath.Join(repoPath, "go.mod")); err == nil {
-		return "go"
-	}
-	// Check for PHP (composer.json)
-	if _, err := os.Stat(filepath.Join(repoPath, "composer.json")); err == nil {
-		return "php"
+	// If still failed after retries
+	if lastError != nil {
+		output.WriteString(fmt.Sprintf("Error running OpenRewrite: %v\n", lastError))
+		lines := strings.Split(string(cmdOutput), "\n")
+		start := len(lines) - 10
+		if start < 0 {
+			start = 0
+		}
+		for _, line := range lines[start:] {
+			output.WriteString(fmt.Sprintf("  %s\n", line))
+		}
+		return AnalysisResult{Index: index, RepoName: repoName, RepoPath: repoPath, Output: output.String(), Success: false, Duration: time.Since(startTime)}
 	}
-	// Check for Python (in priority order)
-	if _, err := os.Stat(filepath.Join(repoPath, "requirements.txt")); err == nil {
-		return "python"
+
+	// The Quarkus update lists the platform and extension changes before writing the patch
+	if changes := logic.ParseQuarkusUpdateOutput(string(cmdOutput)); len(changes) > 0 {
+		output.WriteString("Recommended Quarkus updates:\n")
+		for _, change := range changes {
+			output.WriteString(fmt.Sprintf("  • %s\n", change))
+		}
 	}
-	if _, err := os.Stat(filepath.Join(repoPath, "pyproject.toml")); err == nil {
-		return "python"
+
+	// Check for patch file
+	var summary *patch.Summary
+	patchFile := rewritePatchPath(repoPath)
+	if _, err := os.Stat(patchFile); err == nil {
+		content, err := os.ReadFile(patchFile)
+		if err == nil && len(content) > 0 {
+			// Parsed into the typed model, the client renders it from the PATCH_SUMMARY event
+			parsed := patch.Parse(string(content))
+			summary = &parsed
+			output.WriteString(fmt.Sprintf("Changes detected in %d files (+%d -%d).\n", len(parsed.Files), parsed.Additions, parsed.Deletions))
+		} else {
+			output.WriteString("✅ No changes required.\n")
+		}
+	} else {
+		if strings.Contains(string(cmdOutput), "No changes") || strings.Contains(string(cmdOutput), "up-to-date") {
+			output.WriteString("✅ No changes required.\n")
+		} else {
+			output.WriteString("Analysis finished (no patch file generated).\n")
+		}
 	}
-	if _, err := os.Stat(filepath.Join(repoPath, "setup.py")); err == nil {
-		return "python"
+
+	return AnalysisResult{Index: index, RepoName: repoName, RepoPath: repoPath, Output: output.String(), Success: true, Duration: time.Since(startTime), Patch: summary}
+}
+
+// recordMigrationAnalysis stores the analysis of a repo in the migration history and adds the
+// comparison with the previous analysis of the same target to the result
+func recordMigrationAnalysis(result *AnalysisResult, migrationType, targetVersion string) {
+	if migrationType == "" {
+		migrationType = "spring-boot"
 	}
-	if _, err := os.Stat(filepath.Join(repoPath, "Pipfile")); err == nil {
-		return "python"
+	delta, err := logic.SaveMigrationAnalysis(result.RepoPath, migrationType, targetVersion, logic.NewMigrationAnalysis(result.Patch, time.Now()))
+	if err != nil {
+		slog.Warn("Could not store the migration analysis", "repo", result.RepoName, "error", err)
+		return
 	}
-	// Check for pnpm
-	if _, err := os.Stat(filepath.Join(repoPath, "pnpm-lock.yaml")); err == nil {
-		return "pnpm"
+	if delta != nil {
+		result.Delta = delta
+		result.Output += fmt.Sprintf("📈 %s\n", delta)
 	}
-	// Check for Yarn
-	if _, err := os.Stat(filepath.Join(repoPath, "yarn.lock")); err == nil {
-		return "yarn"
+}
+
+// handleMigrationHistory returns the stored analyses of a repo for a migration target:
+// GET /api/migration-history?repoPath=...&migrationType=...&targetVersion=...
+func handleMigrationHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
-	// Check for npm
-	if _, err := os.Stat(filepath.Join(repoPath, "package-lock.json")); err == nil {
-		return "npm"
+	query := r.URL.Query()
+	if query.Get("repoPath") == "" {
+		http.Error(w, "repoPath is required", http.StatusBadRequest)
+		return
 	}
-	// Check for package.json without lockfile (default to npm)
-	if _, err := os.Stat(filepath.Join(repoPath, "package.json")); err == nil {
-		return "npm"
+	migrationType := query.Get("migrationType")
+	if migrationType == "" {
+		migrationType = "spring-boot"
 	}
-	// Check for .py files in root as a fallback for Python projects
-	if hasPythonFiles(repoPath) {
-		return "python-no-deps"
+	history, err := logic.LoadMigrationHistory(query.Get("repoPath"), migrationType, query.Get("targetVersion"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
-	return ""
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
 }
 
-// hasPythonFiles checks if there are .py files in the root directory
-func hasPythonFiles(repoPath string) bool {
-	entries, err := os.ReadDir(repoPath)
-	if err != nil {
-		return false
-	}
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".py") {
-			return true
-		}
-	}
-	return false
+// rewritePatchPath is where the OpenRewrite dryRun leaves the patch of a repo
+func rewritePatchPath(repoPath string) string {
+	return filepath.Join(repoPath, "target", "rewrite", "rewrite.patch")
 }
 
-// checkNpmAvailable checks if npm is available
-func checkNpmAvailable() bool {
-	cmd := exec.Command("npm", "--version")
-	return cmd.Run() == nil
+// handleAnalysisPatch downloads the raw patch of the last analysis of a repo: GET /api/analysis-patch?repoPath=...
+func handleAnalysisPatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	repoPath := r.URL.Query().Get("repoPath")
+	if repoPath == "" || !logic.IsGitRepo(repoPath) {
+		http.Error(w, "repoPath must be a git repository", http.StatusBadRequest)
+		return
+	}
+	content, err := os.ReadFile(rewritePatchPath(repoPath))
+	if err != nil {
+		http.Error(w, "No patch found, run an analysis first", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/x-diff; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-rewrite.patch"`, filepath.Base(repoPath)))
+	w.Write(content)
 }
 
-// checkYarnAvailable checks if yarn is available
-func checkYarnAvailable() bool {
-	cmd := exec.Command("yarn", "--version")
-	return cmd.Run() == nil
+// JakartaReadinessRequest selects the repos for the Jakarta EE readiness report
+type JakartaReadinessRequest struct {
+	RootPath  string              `json:"rootPath"`
+	RootPaths []string            `json:"rootPaths"` // Further roots searched together with RootPath
+	Excluded  []string            `json:"excluded"`
+	Group     string              `json:"group"` // Optional repository group instead of RootPath
+	Maven     logic.MavenSettings `json:"maven"`
 }
 
-// checkPnpmAvailable checks if pnpm is available
-func checkPnpmAvailable() bool {
-	cmd := exec.Command("pnpm", "--version")
-	return cmd.Run() == nil
-}
-
-// checkGovulncheckAvailable checks if govulncheck is available
-func checkGovulncheckAvailable() bool {
-	cmd := exec.Command("govulncheck", "-version")
-	return cmd.Run() == nil
-}
-
-// checkPipAuditAvailable checks if pip-audit is available
-func checkPipAuditAvailable() bool {
-	cmd := exec.Command("pip-audit", "--version")
-	return cmd.Run() == nil
-}
-
-// checkComposerAvailable checks if composer is available
-func checkComposerAvailable() bool {
-	cmd := exec.Command("composer", "--version")
-	return cmd.Run() == nil
-}
-
-func handleCheckTrivy(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
+// handleJakartaReadiness streams the javax dependencies and blockers of each Maven repo
+func handleJakartaReadiness(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 
-	// Check if trivy is available
-	cmd := exec.Command("which", "trivy")
-	if runtime.GOOS == "windows" {
-		cmd = exec.Command("where", "trivy")
+	var req JakartaReadinessRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	if err := cmd.Run(); err != nil {
-		json.NewEncoder(w).Encode(map[string]bool{"available": false})
+	// Set headers for streaming
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
 		return
 	}
 
-	// Get trivy version
-	cmd = exec.Command("trivy", "--version")
-	output, err := cmd.Output()
-	version := ""
-	if err == nil {
-		lines := strings.Split(string(output), "\n")
-		if len(lines) > 0 {
-			version = strings.TrimSpace(lines[0])
+	var repos []string
+	if req.Group == "" && len(req.RootPaths) == 0 && logic.IsGitRepo(req.RootPath) {
+		repos = []string{req.RootPath}
+	} else {
+		var err error
+		if repos, err = resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
 	}
 
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"available": true,
-		"version":   version,
-	})
-}
-
-func handleCheckNpm(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
+	count := 0
+	for _, repoPath := range repos {
+		if _, err := os.Stat(filepath.Join(repoPath, "pom.xml")); err != nil {
+			continue
+		}
+		repoName := filepath.Base(repoPath)
+		fmt.Fprintf(w, "REPO_START:%s\n", repoName)
+		flusher.Flush()
 
-	result := map[string]bool{
-		"npm":  checkNpmAvailable(),
-		"yarn": checkYarnAvailable(),
-		"pnpm": checkPnpmAvailable(),
+		report := logic.AnalyzeJakartaReadiness(repoPath, req.Maven)
+		data, _ := json.Marshal(report)
+		fmt.Fprintf(w, "REPO_RESULT:%s\n", data)
+		flusher.Flush()
+		count++
 	}
 
-	json.NewEncoder(w).Encode(result)
+	fmt.Fprintf(w, "JAKARTA_COMPLETE:%d\n", count)
+	flusher.Flush()
 }
 
-func handleCheckGo(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
-	available := checkGovulncheckAvailable()
-	version := ""
-
-	if available {
-		cmd := exec.Command("govulncheck", "-version")
-		output, err := cmd.Output()
-		if err == nil {
-			version = strings.TrimSpace(string(output))
-		}
+func handleDashboardStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req ScanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	policy, err := logic.LoadScorePolicy()
+	if err != nil {
+		http.Error(w, "Invalid health score policy: "+err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"available": available,
-		"version":   version,
-	})
-}
-
-func handleCheckPython(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
-	available := checkPipAuditAvailable()
-	version := ""
+	// Set headers for streaming NDJSON
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
 
-	if available {
-		cmd := exec.Command("pip-audit", "--version")
-		output, err := cmd.Output()
-		if err == nil {
-			version = strings.TrimSpace(string(output))
-		}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
 	}
 
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"available": available,
-		"version":   version,
+	// Use mutex to protect concurrent writes to ResponseWriter
+	var mu sync.Mutex
+	var results []logic.RepoHealth
+	logic.StreamRepoStats(repos, req.IgnorePaths, req.Maven, policy, func(result interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		if event, ok := result.(map[string]interface{}); ok {
+			if health, ok := event["data"].(logic.RepoHealth); ok {
+				results = append(results, health)
+			}
+		}
+		json.NewEncoder(w).Encode(result)
+		flusher.Flush()
 	})
-}
-
-func handleCheckPhp(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
-	available := checkComposerAvailable()
-	version := ""
 
-	if available {
-		cmd := exec.Command("composer", "--version")
-		output, err := cmd.Output()
-		if err == nil {
-			// Extract version from "Composer version 2.x.x ..."
-			version = strings.TrimSpace(string(output))
+	// Complete scans of the whole scope feed the trend history; a scan with exclusions or further roots is not comparable
+	if r.Context().Err() == nil && len(req.Excluded) == 0 && len(req.RootPaths) == 0 && len(results) > 0 {
+		snapshot := logic.NewDashboardSnapshot(results, time.Now())
+		if err := logic.SaveDashboardSnapshot(logic.DashboardScope(req.RootPath, req.Group), snapshot); err != nil {
+			slog.Warn("Could not store the dashboard snapshot", "error", err)
 		}
 	}
-
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"available": available,
-		"version":   version,
-	})
 }
 
-func handleSecurityScan(w http.ResponseWriter, r *http.Request) {
+// handleDashboardWatch keeps the dashboard of the root paths up to date: it watches the folders and streams
+// NDJSON like handleDashboardStats, a "repo" message for every new repository or changed pom.xml/package.json
+// (with "event" and "file") and a "removed" message for every deleted repository, until the client disconnects.
+func handleDashboardWatch(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-
-	var req SecurityScanRequest
+	var req ScanRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	// Default to OWASP
-	if req.Scanner == "" {
-		req.Scanner = "owasp"
+	if req.Group != "" {
+		http.Error(w, "Live updates need root paths, not a repository group", http.StatusBadRequest)
+		return
+	}
+	policy, err := logic.LoadScorePolicy()
+	if err != nil {
+		http.Error(w, "Invalid health score policy: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	watcher, err := logic.WatchRepos(append([]string{req.RootPath}, req.RootPaths...), req.Excluded)
+	if err != nil {
+		http.Error(w, "Cannot watch folders: "+err.Error(), http.StatusInternalServerError)
+		return
 	}
+	defer watcher.Close()
 
-	// Set headers for streaming
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Type", "application/x-ndjson")
 	w.Header().Set("Transfer-Encoding", "chunked")
 	w.Header().Set("X-Content-Type-Options", "nosniff")
-
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
 		return
 	}
-
-	// Debug: Log the request parameters
-	fmt.Printf("[SecurityScan] RootPath: %s, Excluded: %v, Scanner: %s\n", req.RootPath, req.Excluded, req.Scanner)
-
-	repos := logic.FindGitRepos(req.RootPath, req.Excluded)
-	total := len(repos)
-
-	// Debug: Log found repos
-	fmt.Printf("[SecurityScan] Found %d repos: %v\n", total, repos)
-
-	fmt.Fprintf(w, "SCAN_INIT:%d:%s\n", total, req.Scanner)
+	json.NewEncoder(w).Encode(map[string]interface{}{"type": "watching"})
 	flusher.Flush()
+	slog.Debug("Dashboard watch started", "rootPath", req.RootPath, "rootPaths", req.RootPaths)
+
+	for {
+		select {
+		case <-r.Context().Done():
+			slog.Debug("Dashboard watch stopped", "rootPath", req.RootPath)
+			return
+		case batch, ok := <-watcher.Events():
+			if !ok {
+				return
+			}
+			var updated []string
+			events := make(map[string]logic.RepoEvent)
+			for _, event := range batch {
+				if event.Type == logic.RepoRemoved {
+					json.NewEncoder(w).Encode(map[string]interface{}{"type": "removed", "path": event.Repo})
+					continue
+				}
+				updated = append(updated, event.Repo)
+				events[event.Repo] = event
+			}
+			logic.StreamRepoStats(updated, req.IgnorePaths, req.Maven, policy, func(result interface{}) {
+				message, ok := result.(map[string]interface{})
+				if !ok || message["type"] != "repo" {
+					return
+				}
+				if health, ok := message["data"].(logic.RepoHealth); ok {
+					message["event"] = events[health.Path].Type
+					message["file"] = events[health.Path].File
+				}
+				json.NewEncoder(w).Encode(message)
+			})
+			flusher.Flush()
+		}
+	}
+}
 
-	// Determine worker count (parallel scans)
-	workerCount := 4
-	if total < workerCount {
-		workerCount = total
+// handleScorePolicy returns the health score policy in effect and where it is read from: GET /api/score-policy
+func handleScorePolicy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
-	if workerCount < 1 {
-		workerCount = 1
+	path, err := logic.ScorePolicyPath()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
-
-	// Create channels for work distribution
-	type scanJob struct {
-		repoPath     string
-		repoName     string
-		index        int
-		targetBranch string
+	policy, err := logic.LoadScorePolicy()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
+	_, statErr := os.Stat(path)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"path":   path,
+		"custom": statErr == nil,
+		"policy": policy,
+	})
+}
 
-	type scanResult struct {
-		result RepoSecurityResult
-		index  int
+// handleDashboardTrends returns the stored dashboard snapshots of a root path or group as time series:
+// GET /api/dashboard-trends?rootPath=...&days=90 (or group=... instead of rootPath; days=0 = all)
+func handleDashboardTrends(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	rootPath, group := query.Get("rootPath"), query.Get("group")
+	if rootPath == "" && group == "" {
+		http.Error(w, "rootPath or group is required", http.StatusBadRequest)
+		return
 	}
 
-	jobs := make(chan scanJob, total)
-	results := make(chan scanResult, total)
-
-	// Start workers
-	var wg sync.WaitGroup
-	for w := 0; w < workerCount; w++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for job := range jobs {
-				start := time.Now()
-				var result RepoSecurityResult
-				result.RepoName = job.repoName
+	days := 90
+	if value := query.Get("days"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			http.Error(w, "days must be a number >= 0", http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+	var since time.Time
+	if days > 0 {
+		since = time.Now().AddDate(0, 0, -days)
+	}
 
-				// Handle branch switching if targetBranch is specified
-				var originalBranch string
-				branchSwitched := false
-				if job.targetBranch != "" {
-					// Get current branch
-					originalBranch = getCurrentBranch(job.repoPath)
+	trend, err := logic.LoadDashboardTrend(logic.DashboardScope(rootPath, group), since)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(trend)
+}
 
-					// Only switch if we're not already on the target branch
-					if originalBranch != job.targetBranch {
-						// Check if there are uncommitted changes
-						cmd := exec.Command("git", "status", "--porcelain")
-						cmd.Dir = job.repoPath
-						statusOutput, _ := cmd.Output()
-
-						if len(statusOutput) > 0 {
-							// Stash changes
-							stashCmd := exec.Command("git", "stash", "push", "-m", "GitHousekeeper security scan")
-							stashCmd.Dir = job.repoPath
-							stashCmd.Run()
-						}
+// BranchInfo represents a branch with its tracking status
+type BranchInfo struct {
+	Name       string `json:"name"`
+	IsTracking bool   `json:"isTracking"`
+	Remote     string `json:"remote"`
+	Ahead      int    `json:"ahead"`
+	Behind     int    `json:"behind"`
+}
 
-						// Switch to target branch
-						checkoutCmd := exec.Command("git", "checkout", job.targetBranch)
-						checkoutCmd.Dir = job.repoPath
-						if err := checkoutCmd.Run(); err != nil {
-							result.Error = fmt.Sprintf("Failed to checkout branch %s: %v", job.targetBranch, err)
-							result.Duration = time.Since(start).Seconds()
-							results <- scanResult{result: result, index: job.index}
-							continue
-						}
-						branchSwitched = true
-						result.ScannedBranch = job.targetBranch
-					} else {
-						result.ScannedBranch = originalBranch
-					}
-				} else {
-					result.ScannedBranch = getCurrentBranch(job.repoPath)
-				}
-
-				// Store scanned branch for result preservation
-				scannedBranch := result.ScannedBranch
-
-				// Detect project type
-				projectType := detectProjectType(job.repoPath)
-				result.ProjectType = projectType
-
-				// Determine which scanner to use
-				scannerToUse := req.Scanner
-				if req.Scanner == "auto" {
-					// Auto-detect based on project type
-					switch projectType {
-					case "maven":
-						scannerToUse = "owasp"
-					case "npm", "yarn", "pnpm":
-						scannerToUse = "npm"
-					case "go":
-						scannerToUse = "govulncheck"
-					case "python":
-						scannerToUse = "pip-audit"
-					case "php":
-						scannerToUse = "composer-audit"
-					case "python-no-deps":
-						result.Error = "Python project found but no requirements.txt, pyproject.toml, setup.py, or Pipfile. Cannot scan without dependency file."
-						result.ProjectType = "python"
-						result.Duration = time.Since(start).Seconds()
-						if branchSwitched {
-							exec.Command("git", "checkout", originalBranch).Run()
-							exec.Command("git", "stash", "pop").Run()
-						}
-						results <- scanResult{result: result, index: job.index}
-						continue
-					default:
-						result.Error = "No supported project type found (pom.xml, package.json, go.mod, requirements.txt, or composer.json)"
-						result.Duration = time.Since(start).Seconds()
-						// Switch back to original branch before returning error
-						if branchSwitched {
-							exec.Command("git", "checkout", originalBranch).Run()
-							exec.Command("git", "stash", "pop").Run()
-						}
-						results <- scanResult{result: result, index: job.index}
-						continue
-					}
-				}
-
-				// Run appropriate scanner
-				switch scannerToUse {
-				case "npm":
-					if projectType == "" || (projectType != "npm" && projectType != "yarn" && projectType != "pnpm") {
-						result.Error = "No package.json found"
-					} else {
-						result = runNpmAudit(job.repoPath, job.repoName, projectType)
-					}
-				case "trivy":
-					if projectType == "" {
-						result.Error = "No supported project files found"
-					} else {
-						result = runTrivyScan(job.repoPath, job.repoName)
-						result.ProjectType = projectType
-					}
-				case "owasp":
-					if projectType != "maven" {
-						result.Error = "No pom.xml found (OWASP requires Maven project)"
-					} else {
-						result = runOwaspScan(job.repoPath, job.repoName)
-						result.ProjectType = projectType
-					}
-				case "govulncheck":
-					if projectType != "go" {
-						result.Error = "No go.mod found (govulncheck requires Go project)"
-					} else {
-						result = runGovulncheck(job.repoPath, job.repoName)
-						result.ProjectType = projectType
-					}
-				case "pip-audit":
-					if projectType != "python" {
-						result.Error = "No Python project found (requires requirements.txt or pyproject.toml)"
-					} else {
-						result = runPipAudit(job.repoPath, job.repoName)
-						result.ProjectType = projectType
-					}
-				case "composer-audit":
-					if projectType != "php" {
-						result.Error = "No PHP project found (requires composer.json)"
-					} else {
-						result = runComposerAudit(job.repoPath, job.repoName)
-						result.ProjectType = projectType
-					}
-				default:
-					result.Error = "Unknown scanner type"
-				}
+// RepoWithBranches represents a repository and its branches
+type RepoWithBranches struct {
+	Name          string       `json:"name"`
+	Path          string       `json:"path"`
+	DefaultBranch string       `json:"defaultBranch"`
+	Branches      []BranchInfo `json:"branches"`
+}
 
-				// Restore the scanned branch info (may be lost in scanner functions)
-				result.ScannedBranch = scannedBranch
-				result.Duration = time.Since(start).Seconds()
+type ListBranchesRequest struct {
+	RootPath  string   `json:"rootPath"`
+	RootPaths []string `json:"rootPaths"` // Further roots searched together with RootPath
+	Excluded  []string `json:"excluded"`
+	Group     string   `json:"group"` // Optional repository group instead of RootPath
+}
 
-				// Switch back to original branch if we switched
-				if branchSwitched {
-					checkoutCmd := exec.Command("git", "checkout", originalBranch)
-					checkoutCmd.Dir = job.repoPath
-					checkoutCmd.Run()
+func handleListBranches(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 
-					// Try to restore stashed changes
-					stashPopCmd := exec.Command("git", "stash", "pop")
-					stashPopCmd.Dir = job.repoPath
-					stashPopCmd.Run()
-				}
+	var req ListBranchesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-				results <- scanResult{result: result, index: job.index}
-			}
-		}()
+	repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
+	var result []RepoWithBranches
 
-	// Send all repos that are being scanned
 	for _, repoPath := range repos {
 		repoName := filepath.Base(repoPath)
-		fmt.Fprintf(w, "REPO_START:%s\n", repoName)
-	}
-	flusher.Flush()
 
-	// Submit jobs
-	go func() {
-		for i, repoPath := range repos {
-			jobs <- scanJob{
-				repoPath:     repoPath,
-				repoName:     filepath.Base(repoPath),
-				index:        i,
-				targetBranch: req.TargetBranch,
-			}
-		}
-		close(jobs)
-	}()
+		// Get default branch
+		defaultBranch := getRepoDefaultBranch(repoPath)
 
-	// Collect results in a goroutine
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
+		// Get all local branches with tracking info
+		branches := getRepoBranches(repoPath)
 
-	// Process results as they come in
-	allResults := make([]RepoSecurityResult, total)
-	totalCritical, totalHigh, totalMedium, totalLow := 0, 0, 0, 0
-	completed := 0
-	scanStart := time.Now()
+		result = append(result, RepoWithBranches{
+			Name:          repoName,
+			Path:          repoPath,
+			DefaultBranch: defaultBranch,
+			Branches:      branches,
+		})
+	}
 
-	for res := range results {
-		completed++
-		allResults[res.index] = res.result
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
 
-		// Count severities
-		for _, f := range res.result.Findings {
-			switch f.Severity {
-			case "CRITICAL":
-				totalCritical++
-			case "HIGH":
-				totalHigh++
-			case "MEDIUM":
-				totalMedium++
-			case "LOW":
-				totalLow++
-			}
+func getRepoDefaultBranch(repoPath string) string {
+	remote := logic.PrimaryRemote(repoPath)
+	if remote == "" {
+		remote = "origin"
+	}
+	cmd := exec.Command("git", "symbolic-ref", "refs/remotes/"+remote+"/HEAD")
+	cmd.Dir = repoPath
+	output, err := cmdlimit.Output(cmd)
+	if err == nil {
+		branch := strings.TrimPrefix(strings.TrimSpace(string(output)), "refs/remotes/"+remote+"/")
+		if branch != "" {
+			return branch
 		}
+	}
 
-		// Stream result as JSON (even skipped repos)
-		resultJSON, _ := json.Marshal(res.result)
-		fmt.Fprintf(w, "REPO_RESULT:%s\n", string(resultJSON))
-
-		// Calculate ETA
-		elapsed := time.Since(scanStart).Seconds()
-		avgTimePerRepo := elapsed / float64(completed)
-		remainingRepos := total - completed
-		eta := avgTimePerRepo * float64(remainingRepos)
-
-		fmt.Fprintf(w, "REPO_DONE:%s:%.1f\n", res.result.RepoName, res.result.Duration)
-		fmt.Fprintf(w, "SCAN_PROGRESS:%d:%d:%.0f\n", completed, total, eta)
-		flusher.Flush()
+	// Fallback: check if main exists
+	cmd = exec.Command("git", "show-ref", "--verify", "--quiet", "refs/heads/main")
+	cmd.Dir = repoPath
+	if cmdlimit.Run(cmd) == nil {
+		return "main"
 	}
 
-	// Send summary
-	fmt.Fprintf(w, "SCAN_SUMMARY:%d:%d:%d:%d\n", totalCritical, totalHigh, totalMedium, totalLow)
-	fmt.Fprintf(w, "SCAN_COMPLETE\n")
-	flusher.Flush()
+	return "master"
 }
 
-func runTrivyScan(repoPath, repoName string) RepoSecurityResult {
-	result := RepoSecurityResult{RepoName: repoName}
-
-	// Run trivy fs with JSON output
-	cmd := exec.Command("trivy", "fs", "--scanners", "vuln", "--format", "json", "--quiet", ".")
-	cmd.Dir = repoPath
-	output, err := cmd.Output()
+func getRepoBranches(repoPath string) []BranchInfo {
+	var branches []BranchInfo
 
+	// Local branches with their upstream tracking info, read in-process where possible
+	list, err := gitops.Open(repoPath).Branches()
 	if err != nil {
-		// Trivy returns exit code 1 if vulnerabilities found, but still outputs JSON
-		if len(output) == 0 {
-			result.Error = fmt.Sprintf("Trivy scan failed: %v", err)
-			return result
-		}
+		return branches
+	}
+	for _, b := range list {
+		branches = append(branches, BranchInfo{
+			Name:       b.Name,
+			IsTracking: b.Upstream != "",
+			Remote:     b.Upstream,
+			Ahead:      b.Ahead,
+			Behind:     b.Behind,
+		})
 	}
 
-	// Parse Trivy JSON output
-	var trivyResult struct {
-		Results []struct {
-			Vulnerabilities []struct {
-				VulnerabilityID  string `json:"VulnerabilityID"`
-				PkgName          string `json:"PkgName"`
-				InstalledVersion string `json:"InstalledVersion"`
-				FixedVersion     string `json:"FixedVersion"`
-				Severity         string `json:"Severity"`
-				Description      string `json:"Description"`
-			} `json:"Vulnerabilities"`
-		} `json:"Results"`
+	return branches
+}
+
+type RepoStatusRequest struct {
+	RootPath  string   `json:"rootPath"`
+	RootPaths []string `json:"rootPaths"` // Further roots searched together with RootPath
+	Excluded  []string `json:"excluded"`
+	Group     string   `json:"group"` // Optional repository group instead of RootPath
+}
+
+// handleRepoStatus returns the `git status` of every repository: branch, dirty state, ahead/behind,
+// stashes and an interrupted merge or rebase
+func handleRepoStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
 
-	if err := json.Unmarshal(output, &trivyResult); err != nil {
-		result.Error = fmt.Sprintf("Failed to parse Trivy output: %v", err)
-		return result
+	var req RepoStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	for _, r := range trivyResult.Results {
-		for _, v := range r.Vulnerabilities {
-			result.Findings = append(result.Findings, CVEFinding{
-				CVE:         v.VulnerabilityID,
-				Severity:    strings.ToUpper(v.Severity),
-				Package:     v.PkgName,
-				Version:     v.InstalledVersion,
-				FixedIn:     v.FixedVersion,
-				Description: truncateString(v.Description, 200),
-			})
-		}
+	repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	return result
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(logic.InspectRepoStatuses(repos))
 }
 
-func runOwaspScan(repoPath, repoName string) RepoSecurityResult {
-	result := RepoSecurityResult{RepoName: repoName}
-
-	// Run OWASP dependency-check via Maven with JSON output
-	cmd := exec.Command("mvn",
-		"org.owasp:dependency-check-maven:12.1.0:check",
-		"-DfailBuildOnCVSS=11", // Never fail build
-		"-Dformat=JSON",
-		"-DprettyPrint=true",
-		"-DskipTestScope=true",
-		"-q", // Quiet mode
-	)
-	cmd.Dir = repoPath
-	cmd.Run() // Ignore exit code, we'll parse the output file
+type RepoRemoteRequest struct {
+	Path   string `json:"path"`
+	Remote string `json:"remote"` // Empty = choose automatically
+}
 
-	// Find and parse the JSON report
-	reportPath := filepath.Join(repoPath, "target", "dependency-check-report.json")
-	reportData, err := os.ReadFile(reportPath)
-	if err != nil {
-		result.Error = "OWASP scan completed but no report found. First scan may take 10+ minutes to download NVD database."
-		return result
+// handleRepoRemote sets the remote GitHousekeeper uses for a repository that has several remotes,
+// stored in the clone's git config
+func handleRepoRemote(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
 
-	// Parse OWASP JSON output
-	var owaspResult struct {
-		Dependencies []struct {
-			FileName        string `json:"fileName"`
-			Vulnerabilities []struct {
-				Name        string `json:"name"`
-				Severity    string `json:"severity"`
-				Description string `json:"description"`
-			} `json:"vulnerabilities"`
-		} `json:"dependencies"`
+	var req RepoRemoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
-
-	if err := json.Unmarshal(reportData, &owaspResult); err != nil {
-		result.Error = fmt.Sprintf("Failed to parse OWASP report: %v", err)
-		return result
+	if req.Path == "" || !logic.IsGitRepo(req.Path) {
+		http.Error(w, "path must be a git repository", http.StatusBadRequest)
+		return
+	}
+	if err := logic.SetPrimaryRemote(req.Path, req.Remote); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	for _, dep := range owaspResult.Dependencies {
-		for _, v := range dep.Vulnerabilities {
-			severity := strings.ToUpper(v.Severity)
-			// OWASP uses different severity names
-			switch severity {
-			case "CRITICAL", "HIGH", "MEDIUM", "LOW":
-				// Keep as is
-			case "MODERATE":
-				severity = "MEDIUM"
-			default:
-				severity = "LOW"
-			}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(logic.InspectRepoStatus(req.Path))
+}
 
-			result.Findings = append(result.Findings, CVEFinding{
-				CVE:         v.Name,
-				Severity:    severity,
-				Package:     dep.FileName,
-				Description: truncateString(v.Description, 200),
-			})
-		}
-	}
+type StashesRequest struct {
+	RootPath    string   `json:"rootPath"`
+	RootPaths   []string `json:"rootPaths"` // Further roots searched together with RootPath
+	Excluded    []string `json:"excluded"`
+	Group       string   `json:"group"`       // Optional repository group instead of RootPath
+	ManagedOnly bool     `json:"managedOnly"` // Only stashes pushed by GitHousekeeper
+	Name        string   `json:"name"`        // Stash name for create, restore and drop
+}
 
-	return result
+type GitAuthCheckRequest struct {
+	RootPath  string   `json:"rootPath"`
+	RootPaths []string `json:"rootPaths"` // Further roots searched together with RootPath
+	Excluded  []string `json:"excluded"`
+	Group     string   `json:"group"` // Optional repository group instead of RootPath
 }
 
-// detectYarnVersion detects Yarn version from package.json packageManager field or yarn --version
-func detectYarnVersion(repoPath string) (version string, useCorepack bool) {
-	// First check package.json for packageManager field
-	pkgPath := filepath.Join(repoPath, "package.json")
-	if data, err := os.ReadFile(pkgPath); err == nil {
-		var pkg struct {
-			PackageManager string `json:"packageManager"`
-		}
-		if json.Unmarshal(data, &pkg) == nil && pkg.PackageManager != "" {
-			// Format: "yarn@4.0.2" or "yarn@4.0.2+sha256.xxx"
-			if strings.HasPrefix(pkg.PackageManager, "yarn@") {
-				parts := strings.Split(pkg.PackageManager, "@")
-				if len(parts) >= 2 {
-					ver := strings.Split(parts[1], "+")[0] // Remove hash
-					return ver, true                       // Use corepack for packageManager-managed yarn
-				}
-			}
-		}
+// handleGitAuthCheck checks access to the remote host of every repository: POST /api/git-auth-check
+func handleGitAuthCheck(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
 
-	// Fallback to global yarn version
-	versionCmd := exec.Command("yarn", "--version")
-	versionCmd.Dir = repoPath
-	if versionOutput, err := versionCmd.Output(); err == nil {
-		return strings.TrimSpace(string(versionOutput)), false
+	var req GitAuthCheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if network, err := logic.LoadNetworkSettings(); err == nil && network.Offline {
+		http.Error(w, "Offline mode: remote hosts are not contacted", http.StatusConflict)
+		return
+	}
+	repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	return "1.0.0", false // Default to classic
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(logic.CheckGitAccess(repos))
 }
 
-// runNpmAudit runs npm/yarn/pnpm audit for Node.js projects
-func runNpmAudit(repoPath, repoName, packageManager string) RepoSecurityResult {
-	result := RepoSecurityResult{RepoName: repoName, ProjectType: packageManager}
-
-	var cmd *exec.Cmd
-	var isYarnBerry bool
-	var useCorepack bool
-
-	switch packageManager {
-	case "yarn":
-		var yarnVersion string
-		yarnVersion, useCorepack = detectYarnVersion(repoPath)
-
-		// Determine if Yarn Berry (v2+)
-		isYarnBerry = !strings.HasPrefix(yarnVersion, "1.")
-
-		if isYarnBerry {
-			// Yarn Modern (v2+/Berry) - try text output first (more reliable than JSON which often fails)
-			if useCorepack {
-				cmd = exec.Command("corepack", "yarn", "npm", "audit")
-			} else {
-				cmd = exec.Command("yarn", "npm", "audit")
-			}
-		} else {
-			// Yarn Classic (v1) - use "yarn audit --json"
-			cmd = exec.Command("yarn", "audit", "--json")
-		}
-	case "pnpm":
-		// pnpm audit with JSON output
-		cmd = exec.Command("pnpm", "audit", "--json")
-	default:
-		// npm audit with JSON output
-		cmd = exec.Command("npm", "audit", "--json")
+// handleStashes lists the stashes of every repository: POST /api/stashes
+func handleStashes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
-	cmd.Dir = repoPath
-
-	// Use CombinedOutput because npm/yarn/pnpm may write to stderr
-	// and return non-zero exit code when vulnerabilities are found
-	output, err := cmd.CombinedOutput()
 
-	// npm/yarn/pnpm audit returns non-zero exit code if vulnerabilities found
-	// but still outputs valid JSON, so we check if there's any output to parse
-	if len(output) == 0 {
-		if err != nil {
-			result.Error = fmt.Sprintf("%s audit failed: %v", packageManager, err)
-		} else {
-			result.Error = fmt.Sprintf("%s audit returned no output", packageManager)
-		}
-		return result
+	var req StashesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
-
-	// Parse based on package manager
-	if packageManager == "yarn" {
-		if isYarnBerry {
-			// Parse text output for Yarn Berry (more reliable)
-			result = parseYarnBerryTextOutput(output, repoName)
-		} else {
-			result = parseYarnClassicAuditOutput(output, repoName)
-		}
-	} else if packageManager == "pnpm" {
-		result = parsePnpmAuditOutput(output, repoName)
-	} else {
-		result = parseNpmAuditOutput(output, repoName)
+	repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
-	result.ProjectType = packageManager
 
-	return result
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(logic.ListRepoStashes(repos, req.ManagedOnly))
 }
 
-// parseNpmAuditOutput parses npm audit JSON output
-func parseNpmAuditOutput(output []byte, repoName string) RepoSecurityResult {
-	result := RepoSecurityResult{RepoName: repoName}
-
-	// npm audit JSON structure (v7+)
-	var npmResult struct {
-		Vulnerabilities map[string]struct {
-			Name         string        `json:"name"`
-			Severity     string        `json:"severity"`
-			Via          []interface{} `json:"via"`
-			Effects      []string      `json:"effects"`
-			Range        string        `json:"range"`
-			FixAvailable interface{}   `json:"fixAvailable"`
-		} `json:"vulnerabilities"`
-		Metadata struct {
-			Vulnerabilities struct {
-				Total    int `json:"total"`
-				Critical int `json:"critical"`
-				High     int `json:"high"`
-				Moderate int `json:"moderate"`
-				Low      int `json:"low"`
-			} `json:"vulnerabilities"`
-		} `json:"metadata"`
+// handleStashOperation creates, restores or drops a named stash in every repository:
+// POST /api/stashes/create, /api/stashes/restore, /api/stashes/drop
+func handleStashOperation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
 
-	if err := json.Unmarshal(output, &npmResult); err != nil {
-		// Try older npm audit format
-		result = parseNpmAuditOutputLegacy(output, repoName)
-		return result
+	var req StashesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	operation := strings.TrimPrefix(r.URL.Path, "/api/stashes/")
+	results, err := logic.NamedStashOperation(repos, operation, req.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	for pkgName, vuln := range npmResult.Vulnerabilities {
-		severity := normalizeSeverity(vuln.Severity)
-		cveID, description := extractCVEFromVia(vuln.Via, pkgName)
-		fixedIn := extractFixInfo(vuln.FixAvailable)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
 
-		result.Findings = append(result.Findings, CVEFinding{
-			CVE:         cveID,
-			Severity:    severity,
-			Package:     pkgName,
-			Version:     vuln.Range,
-			FixedIn:     fixedIn,
-			Description: truncateString(description, 200),
-		})
-	}
+// Repositories synced at the same time by /api/sync-branches, unless the request sets a concurrency
+const (
+	defaultSyncConcurrency = 4
+	maxSyncConcurrency     = 16
+)
 
-	return result
+type SyncBranchesRequest struct {
+	RootPath    string   `json:"rootPath"`
+	RootPaths   []string `json:"rootPaths"` // Further roots searched together with RootPath
+	Excluded    []string `json:"excluded"`
+	Group       string   `json:"group"`       // Optional repository group instead of RootPath
+	Concurrency int      `json:"concurrency"` // Repositories synced in parallel, 0 = default (4), at most 16
 }
 
-// parseNpmAuditOutputLegacy parses older npm audit JSON format
-func parseNpmAuditOutputLegacy(output []byte, repoName string) RepoSecurityResult {
-	result := RepoSecurityResult{RepoName: repoName}
+func handleSyncBranches(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 
-	var legacyResult struct {
-		Advisories map[string]struct {
-			ID                 int    `json:"id"`
-			ModuleName         string `json:"module_name"`
-			Severity           string `json:"severity"`
-			Title              string `json:"title"`
-			URL                string `json:"url"`
-			VulnerableVersions string `json:"vulnerable_versions"`
-			PatchedVersions    string `json:"patched_versions"`
-		} `json:"advisories"`
+	var req SyncBranchesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	if err := json.Unmarshal(output, &legacyResult); err != nil {
-		result.Error = "Failed to parse npm audit output"
-		return result
+	repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	for _, adv := range legacyResult.Advisories {
-		severity := normalizeSeverity(adv.Severity)
+	// Set headers for streaming
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
 
-		result.Findings = append(result.Findings, CVEFinding{
-			CVE:         fmt.Sprintf("npm:%d", adv.ID),
-			Severity:    severity,
-			Package:     adv.ModuleName,
-			Version:     adv.VulnerableVersions,
-			FixedIn:     adv.PatchedVersions,
-			Description: truncateString(adv.Title, 200),
-		})
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
 	}
 
-	return result
-}
+	total := len(repos)
+	workers := req.Concurrency
+	if workers <= 0 {
+		workers = defaultSyncConcurrency
+	}
+	workers = min(workers, maxSyncConcurrency, max(total, 1))
 
-// parseYarnBerryTextOutput parses Yarn Berry (v2+/v4) "yarn npm audit" text output
-// This is more reliable than JSON output which often fails with HTTP 500 errors
-// Format example:
-// ├─ next
-// │  ├─ ID: 1105949
-// │  ├─ Issue: Next.js has a Cache poisoning vulnerability...
-// │  ├─ URL: https://github.com/advisories/GHSA-r2fc-ccr8-96c4
-// │  ├─ Severity: low
-// │  ├─ Vulnerable Versions: >=15.3.0 <15.3.3
-// │  │
-// │  ├─ Tree Versions
-// │  │  └─ 15.3.1
-func parseYarnBerryTextOutput(output []byte, repoName string) RepoSecurityResult {
-	result := RepoSecurityResult{RepoName: repoName}
-
-	lines := strings.Split(string(output), "\n")
-	var currentPackage string
-	var currentID string
-	var currentIssue string
-	var currentURL string
-	var currentSeverity string
-	var currentVulnVersions string
-	var currentTreeVersion string
-
-	for _, line := range lines {
-		// Remove tree drawing characters
-		cleanLine := strings.TrimSpace(line)
-		cleanLine = strings.TrimPrefix(cleanLine, "├─ ")
-		cleanLine = strings.TrimPrefix(cleanLine, "│  ├─ ")
-		cleanLine = strings.TrimPrefix(cleanLine, "│  │  └─ ")
-		cleanLine = strings.TrimPrefix(cleanLine, "│  └─ ")
-		cleanLine = strings.TrimPrefix(cleanLine, "└─ ")
-		cleanLine = strings.TrimSpace(cleanLine)
-
-		if cleanLine == "" || cleanLine == "│" {
-			continue
-		}
-
-		// Check for new package entry (package name line - not prefixed with known fields)
-		if !strings.Contains(cleanLine, ":") && !strings.HasPrefix(cleanLine, "Tree") && !strings.HasPrefix(cleanLine, "Dependents") && len(cleanLine) > 0 {
-			// Save previous entry if we have one
-			if currentPackage != "" && currentSeverity != "" {
-				cveID := currentID
-				if currentURL != "" && strings.Contains(currentURL, "GHSA-") {
-					parts := strings.Split(currentURL, "/")
-					for _, p := range parts {
-						if strings.HasPrefix(p, "GHSA-") {
-							cveID = p
-							break
-						}
-					}
-				}
-
-				result.Findings = append(result.Findings, CVEFinding{
-					CVE:         cveID,
-					Severity:    normalizeSeverity(currentSeverity),
-					Package:     currentPackage,
-					Version:     currentTreeVersion,
-					FixedIn:     currentVulnVersions,
-					Description: truncateString(currentIssue, 200),
-				})
-			}
-
-			// Start new package
-			currentPackage = cleanLine
-			currentID = ""
-			currentIssue = ""
-			currentURL = ""
-			currentSeverity = ""
-			currentVulnVersions = ""
-			currentTreeVersion = ""
-			continue
-		}
+	fmt.Fprintf(w, "SYNC_INIT:%d\n", total)
+	flusher.Flush()
 
-		// Parse fields
-		if strings.HasPrefix(cleanLine, "ID: ") {
-			currentID = fmt.Sprintf("GHSA:%s", strings.TrimPrefix(cleanLine, "ID: "))
-		} else if strings.HasPrefix(cleanLine, "Issue: ") {
-			currentIssue = strings.TrimPrefix(cleanLine, "Issue: ")
-		} else if strings.HasPrefix(cleanLine, "URL: ") {
-			currentURL = strings.TrimPrefix(cleanLine, "URL: ")
-		} else if strings.HasPrefix(cleanLine, "Severity: ") {
-			currentSeverity = strings.TrimPrefix(cleanLine, "Severity: ")
-		} else if strings.HasPrefix(cleanLine, "Vulnerable Versions: ") {
-			currentVulnVersions = strings.TrimPrefix(cleanLine, "Vulnerable Versions: ")
-		} else if !strings.HasPrefix(cleanLine, "Tree") && !strings.HasPrefix(cleanLine, "Dependents") {
-			// This might be a version number under Tree Versions
-			if matched, _ := regexp.MatchString(`^\d+\.\d+`, cleanLine); matched {
-				currentTreeVersion = cleanLine
-			}
+	// Repos are synced in parallel; the log of each repo is written as one block when it is done,
+	// REPO_SYNCING tells which repos are in progress
+	var mu sync.Mutex
+	emit := func(lines ...string) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, line := range lines {
+			fmt.Fprintln(w, line)
 		}
+		flusher.Flush()
 	}
 
-	// Don't forget the last entry
-	if currentPackage != "" && currentSeverity != "" {
-		cveID := currentID
-		if currentURL != "" && strings.Contains(currentURL, "GHSA-") {
-			parts := strings.Split(currentURL, "/")
-			for _, p := range parts {
-				if strings.HasPrefix(p, "GHSA-") {
-					cveID = p
-					break
-				}
+	start := time.Now()
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	completed := 0
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for repoPath := range jobs {
+				repoName := filepath.Base(repoPath)
+				emit("REPO_SYNCING:" + repoName)
+				lines := []string{"REPO_START:" + repoName}
+				syncRepoBranches(repoPath, func(msg string) { lines = append(lines, msg) })
+
+				mu.Lock()
+				completed++
+				done := completed
+				mu.Unlock()
+				emit(append(lines, "REPO_DONE:"+repoName, fmt.Sprintf("SYNC_PROGRESS:%d:%d", done, total))...)
 			}
-		}
-
-		result.Findings = append(result.Findings, CVEFinding{
-			CVE:         cveID,
-			Severity:    normalizeSeverity(currentSeverity),
-			Package:     currentPackage,
-			Version:     currentTreeVersion,
-			FixedIn:     currentVulnVersions,
-			Description: truncateString(currentIssue, 200),
-		})
+		}()
+	}
+	for _, repoPath := range repos {
+		jobs <- repoPath
 	}
+	close(jobs)
+	wg.Wait()
 
-	return result
+	emit(fmt.Sprintf("Synced %d repositories in %s (%d in parallel)", total, time.Since(start).Round(100*time.Millisecond), workers), "SYNC_COMPLETE")
 }
 
-// parseYarnBerryAuditOutput parses Yarn Berry (v2+/v4) "yarn npm audit --json" NDJSON output
-// Format: {"value":"pkg","children":{"ID":123,"Issue":"...","Severity":"critical","Vulnerable Versions":"...","Tree Versions":[...]}}
-func parseYarnBerryAuditOutput(output []byte, repoName string) RepoSecurityResult {
-	result := RepoSecurityResult{RepoName: repoName}
+// syncRepoBranches fetches a repo and fast-forwards its tracking branches
+func syncRepoBranches(repoPath string, log func(string)) {
+	// Remember current branch, or the commit of a detached HEAD
+	currentBranch := getCurrentBranch(repoPath)
+	state := logic.InspectGitState(repoPath)
+	if state.Detached {
+		currentBranch = ""
+		log(fmt.Sprintf("  Detached HEAD at %.7s", state.Head))
+	}
 
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
+	if state.Shallow {
+		if err := logic.Unshallow(repoPath, log); err != nil {
+			log(fmt.Sprintf("  [WARNING] %v", err))
 		}
+	}
 
-		// Yarn Berry format
-		var entry struct {
-			Value    string `json:"value"`
-			Children struct {
-				ID                 int      `json:"ID"`
-				Issue              string   `json:"Issue"`
-				URL                string   `json:"URL"`
-				Severity           string   `json:"Severity"`
-				VulnerableVersions string   `json:"Vulnerable Versions"`
-				TreeVersions       []string `json:"Tree Versions"`
-				Dependents         []string `json:"Dependents"`
-			} `json:"children"`
-		}
+	// Fetch with prune
+	err := logic.Retry(logic.RetryGit, log, func() error {
+		cmd := exec.Command("git", "fetch", "-p", "--all")
+		cmd.Dir = repoPath
+		return cmdlimit.Run(cmd)
+	})
+	if err != nil {
+		log(fmt.Sprintf("  [WARNING] Fetch failed: %v", err))
+	} else {
+		log("  Fetched all remotes")
+	}
 
-		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+	// Fast-forward all tracking branches without touching the working tree
+	checkedOut := false
+	for _, branch := range getRepoBranches(repoPath) {
+		if !branch.IsTracking || branch.Behind == 0 {
 			continue
 		}
-
-		// Skip if no package name
-		if entry.Value == "" {
+		if branch.Ahead > 0 {
+			log(fmt.Sprintf("  [WARNING] %s has diverged from %s (%d ahead, %d behind), not updated", branch.Name, branch.Remote, branch.Ahead, branch.Behind))
 			continue
 		}
 
-		severity := normalizeSeverity(entry.Children.Severity)
-
-		// Extract version from TreeVersions
-		version := ""
-		if len(entry.Children.TreeVersions) > 0 {
-			version = entry.Children.TreeVersions[0]
+		var err error
+		if branch.Name == currentBranch {
+			// The checked-out branch can only move together with the working tree
+			cmd := exec.Command("git", "merge", "--ff-only", branch.Remote)
+			cmd.Dir = repoPath
+			err = cmdlimit.Run(cmd)
+		} else if err = fastForwardBranch(repoPath, branch); err != nil {
+			// Fallback: e.g. the branch is checked out in another worktree
+			checkedOut = true
+			err = checkoutAndPull(repoPath, branch.Name)
 		}
-
-		// Try to extract CVE from URL
-		cveID := fmt.Sprintf("GHSA:%d", entry.Children.ID)
-		if strings.Contains(entry.Children.URL, "GHSA-") {
-			parts := strings.Split(entry.Children.URL, "/")
-			for _, p := range parts {
-				if strings.HasPrefix(p, "GHSA-") {
-					cveID = p
-					break
-				}
-			}
+		if err != nil {
+			log(fmt.Sprintf("  [WARNING] Updating %s failed: %v", branch.Name, err))
+		} else {
+			log(fmt.Sprintf("  ✓ %s updated (%d commits)", branch.Name, branch.Behind))
 		}
-
-		result.Findings = append(result.Findings, CVEFinding{
-			CVE:         cveID,
-			Severity:    severity,
-			Package:     entry.Value,
-			Version:     version,
-			FixedIn:     entry.Children.VulnerableVersions, // Shows what's vulnerable, fix is upgrading out of range
-			Description: truncateString(entry.Children.Issue, 200),
-		})
 	}
 
-	return result
+	// Switch back to original branch (or commit) if the fallback had to check out
+	if checkedOut && currentBranch != "" {
+		cmd := exec.Command("git", "checkout", currentBranch)
+		cmd.Dir = repoPath
+		cmdlimit.Run(cmd)
+	} else if checkedOut && state.Detached && state.Head != "" {
+		cmd := exec.Command("git", "checkout", "--detach", state.Head)
+		cmd.Dir = repoPath
+		if err := cmdlimit.Run(cmd); err != nil {
+			log(fmt.Sprintf("  [WARNING] Could not return to detached HEAD %.7s: %v", state.Head, err))
+		}
+	}
 }
 
-// parseYarnClassicAuditOutput parses Yarn Classic (v1) NDJSON format
-func parseYarnClassicAuditOutput(output []byte, repoName string) RepoSecurityResult {
-	result := RepoSecurityResult{RepoName: repoName}
+// fastForwardBranch moves a local branch that is not checked out to its (already fetched) upstream.
+// 'git fetch . upstream:branch' only accepts fast-forwards and never touches the working tree.
+func fastForwardBranch(repoPath string, branch BranchInfo) error {
+	cmd := exec.Command("git", "fetch", ".", "refs/remotes/"+branch.Remote+":refs/heads/"+branch.Name)
+	cmd.Dir = repoPath
+	if output, err := cmdlimit.CombinedOutput(cmd); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
 
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
+// checkoutAndPull updates a branch the classic way, switching the working tree to it
+func checkoutAndPull(repoPath, branch string) error {
+	cmd := exec.Command("git", "checkout", branch)
+	cmd.Dir = repoPath
+	if err := cmdlimit.Run(cmd); err != nil {
+		return fmt.Errorf("checkout failed: %v", err)
+	}
+	err := logic.Retry(logic.RetryGit, nil, func() error {
+		cmd := exec.Command("git", "pull", "--ff-only")
+		cmd.Dir = repoPath
+		return cmdlimit.Run(cmd)
+	})
+	if err != nil {
+		return fmt.Errorf("pull failed (maybe conflicts): %v", err)
+	}
+	return nil
+}
 
-		var entry struct {
-			Type string `json:"type"`
-			Data struct {
-				Advisory struct {
-					ID                 int    `json:"id"`
-					ModuleName         string `json:"module_name"`
-					Severity           string `json:"severity"`
-					Title              string `json:"title"`
-					URL                string `json:"url"`
-					VulnerableVersions string `json:"vulnerable_versions"`
-					PatchedVersions    string `json:"patched_versions"`
-				} `json:"advisory"`
-			} `json:"data"`
-		}
+// getCurrentBranch returns the checked-out branch, "" for a detached HEAD
+func getCurrentBranch(repoPath string) string {
+	branch, _ := gitops.Open(repoPath).CurrentBranch()
+	return branch
+}
 
-		if err := json.Unmarshal([]byte(line), &entry); err != nil {
-			continue
-		}
+// ==================== SECURITY SCAN ====================
 
-		if entry.Type != "auditAdvisory" {
-			continue
-		}
+type SecurityScanRequest struct {
+	RootPath     string              `json:"rootPath"`
+	RootPaths    []string            `json:"rootPaths"` // Further roots searched together with RootPath
+	Excluded     []string            `json:"excluded"`
+	Group        string              `json:"group"`        // Optional repository group instead of RootPath
+	Scanner      string              `json:"scanner"`      // "owasp", "trivy", "npm", or "auto"
+	TargetBranch string              `json:"targetBranch"` // Optional: branch to scan (empty = current branch); may exist on the remote only
+	Worktree     bool                `json:"worktree"`     // Scan TargetBranch in a temporary git worktree, the developer's checkout stays untouched
+	Maven        logic.MavenSettings `json:"maven"`
+	// ContainerImages additionally scans Dockerfiles with 'trivy image':
+	// "" = off, "pull" = scan the referenced base images, "build" = build each Dockerfile and scan the result
+	ContainerImages string `json:"containerImages"`
+	// ConfigScan checks Kubernetes manifests, Helm charts and Terraform for misconfigurations: "" = off,
+	// "trivy" = 'trivy config', "kubesec" = kubesec (charts rendered with helm), "tfsec" = tfsec
+	ConfigScan string `json:"configScan"`
+	// MinSeverity drops findings below this severity ("" = report everything, UNKNOWN ratings are kept)
+	MinSeverity string        `json:"minSeverity"`
+	FailOn      *FailOnPolicy `json:"failOn,omitempty"`
+	EmailReport bool          `json:"emailReport"` // Email a summary when the scan finishes
+	EmailTo     []string      `json:"emailTo"`     // Recipients; empty = default list of the SMTP settings
+	Refresh     bool          `json:"refresh"`     // Scan again instead of serving cached results of unchanged dependencies
+	Compare     bool          `json:"compare"`     // Classify findings as new, persisting or fixed since the previous scan
+	OnlyNew     bool          `json:"onlyNew"`     // Compare and report only the new findings (delta scan)
+}
 
-		adv := entry.Data.Advisory
-		severity := normalizeSeverity(adv.Severity)
+// FailOnPolicy makes a scan fail (for CI) when more than MaxCount findings reach Severity
+type FailOnPolicy struct {
+	Severity string `json:"severity"` // CRITICAL, HIGH, MEDIUM or LOW (findings at this level or worse count)
+	MaxCount int    `json:"maxCount"` // Number of findings tolerated, 0 = fail on the first one
+}
 
-		result.Findings = append(result.Findings, CVEFinding{
-			CVE:         fmt.Sprintf("yarn:%d", adv.ID),
-			Severity:    severity,
-			Package:     adv.ModuleName,
-			Version:     adv.VulnerableVersions,
-			FixedIn:     adv.PatchedVersions,
-			Description: truncateString(adv.Title, 200),
-		})
-	}
+// SuppressedFinding is a finding matched by an entry of the suppressions file
+type SuppressedFinding struct {
+	CVEFinding
+	Justification string `json:"justification"`
+	Expires       string `json:"expires,omitempty"`
+}
 
-	return result
+type CVEFinding struct {
+	CVE         string  `json:"cve"`
+	Severity    string  `json:"severity"` // CRITICAL, HIGH, MEDIUM, LOW
+	CVSSScore   float64 `json:"cvssScore,omitempty"`
+	Package     string  `json:"package"`
+	Version     string  `json:"version"`
+	FixedIn     string  `json:"fixedIn,omitempty"`
+	Description string  `json:"description,omitempty"`
+	ScanTarget  string  `json:"scanTarget,omitempty"` // Empty for dependency findings, "image:<ref>" for container images, "config:<file>" for misconfigurations of Kubernetes or Terraform files
+	Change      string  `json:"change,omitempty"`     // With compare: "new" or "persisting" since the previous scan
+	SubPath     string  `json:"subPath,omitempty"`    // Sub-project the finding is in, empty for the repository root
 }
 
-// parseYarnAuditOutput is a legacy wrapper - now splits into Berry and Classic
-func parseYarnAuditOutput(output []byte, repoName string) RepoSecurityResult {
-	result := RepoSecurityResult{RepoName: repoName}
+type RepoSecurityResult struct {
+	RepoName      string              `json:"repoName"`
+	Findings      []CVEFinding        `json:"findings"`
+	Suppressed    []SuppressedFinding `json:"suppressed,omitempty"` // Accepted risks from the suppressions file
+	Error         string              `json:"error,omitempty"`
+	Duration      float64             `json:"duration"`
+	ProjectType   string              `json:"projectType,omitempty"`   // "maven", "npm", "yarn", "pnpm"
+	ScannedBranch string              `json:"scannedBranch,omitempty"` // The branch that was scanned
+	Warning       string              `json:"warning,omitempty"`       // E.g. stashed changes that could not be restored
+	CachedAt      *time.Time          `json:"cachedAt,omitempty"`      // Scan time of a result served from the scan cache
+	// With compare: the previous scan of the repo branch and its findings that are gone
+	PreviousScanAt *time.Time   `json:"previousScanAt,omitempty"`
+	Fixed          []CVEFinding `json:"fixed,omitempty"`
+	SubProjects    []SubProject `json:"subProjects,omitempty"` // Projects in subdirectories scanned on their own
+}
 
-	outputStr := string(output)
+// detectProjectType checks what kind of project this is
+func detectProjectType(repoPath string) string {
+	// Check for Maven
+	if _, err := os.Stat(filepath.Join(repoPath, "pom.xml")); err == nil {
+		return "maven"
+	}
+	// Check for Go
+	if _, err := os.Stat(filepath.Join(repoPath, "go.mod")); err == nil {
+		return "go"
+	}
+	// Check for PHP (composer.json)
+	if _, err := os.Stat(filepath.Join(repoPath, "composer.json")); err == nil {
+		return "php"
+	}
+	// Check for Rust (Cargo.toml)
+	if _, err := os.Stat(filepath.Join(repoPath, "Cargo.toml")); err == nil {
+		return "rust"
+	}
+	// Check for .NET (solution or project file)
+	if logic.DotnetProjectFile(repoPath) != "" {
+		return "dotnet"
+	}
+	// Check for Ruby (Bundler)
+	if _, err := os.Stat(filepath.Join(repoPath, "Gemfile")); err == nil {
+		return "ruby"
+	}
+	// Check for Python (in priority order)
+	if _, err := os.Stat(filepath.Join(repoPath, "requirements.txt")); err == nil {
+		return "python"
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, "pyproject.toml")); err == nil {
+		return "python"
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, "setup.py")); err == nil {
+		return "python"
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, "Pipfile")); err == nil {
+		return "python"
+	}
+	// Check for pnpm
+	if _, err := os.Stat(filepath.Join(repoPath, "pnpm-lock.yaml")); err == nil {
+		return "pnpm"
+	}
+	// Check for Yarn
+	if _, err := os.Stat(filepath.Join(repoPath, "yarn.lock")); err == nil {
+		return "yarn"
+	}
+	// Check for npm
+	if _, err := os.Stat(filepath.Join(repoPath, "package-lock.json")); err == nil {
+		return "npm"
+	}
+	// Check for package.json without lockfile (default to npm)
+	if _, err := os.Stat(filepath.Join(repoPath, "package.json")); err == nil {
+		return "npm"
+	}
+	// Check for .py files in root as a fallback for Python projects
+	if hasPythonFiles(repoPath) {
+		return "python-no-deps"
+	}
+	return ""
+}
 
-	// Try to detect format: Yarn Modern (v2+) outputs similar to npm
-	// Check if it starts with { (single JSON object) vs multiple lines (NDJSON)
-	trimmedOutput := strings.TrimSpace(outputStr)
+// subProjectDepth bounds how deep findSubProjects looks below the repository root
+const subProjectDepth = 3
+
+// SubProject is a project in a subdirectory of a repository, e.g. frontend/ next to a Maven build
+type SubProject struct {
+	Path        string     `json:"path"` // Relative to the repository, forward slashes
+	ProjectType string     `json:"projectType"`
+	Scanner     string     `json:"scanner,omitempty"`
+	Findings    int        `json:"findings"`
+	Error       string     `json:"error,omitempty"`
+	CachedAt    *time.Time `json:"cachedAt,omitempty"`
+}
 
-	// Yarn Modern (Berry) format - similar to npm audit
-	if strings.HasPrefix(trimmedOutput, "{") && !strings.Contains(trimmedOutput, "\n{") {
-		// Try npm-like format first (Yarn Modern)
-		var yarnModernResult struct {
-			Advisories map[string]struct {
-				ID                 int    `json:"id"`
-				ModuleName         string `json:"module_name"`
-				Severity           string `json:"severity"`
-				Title              string `json:"title"`
-				URL                string `json:"url"`
-				VulnerableVersions string `json:"vulnerable_versions"`
-				PatchedVersions    string `json:"patched_versions"`
-			} `json:"advisories"`
-			// Alternative structure for yarn npm audit
-			Vulnerabilities map[string]struct {
-				Name         string        `json:"name"`
-				Severity     string        `json:"severity"`
-				Via          []interface{} `json:"via"`
-				Range        string        `json:"range"`
-				FixAvailable interface{}   `json:"fixAvailable"`
-			} `json:"vulnerabilities"`
-		}
+// skippedProjectDirs hold dependencies, build output or virtual environments, never sub-projects
+var skippedProjectDirs = map[string]bool{"node_modules": true, "target": true, "vendor": true, "build": true, "dist": true, "venv": true, "__pycache__": true}
 
-		if err := json.Unmarshal(output, &yarnModernResult); err == nil {
-			// Check if we have vulnerabilities (npm v7+ format)
-			if len(yarnModernResult.Vulnerabilities) > 0 {
-				for pkgName, vuln := range yarnModernResult.Vulnerabilities {
-					severity := normalizeSeverity(vuln.Severity)
-					cveID, description := extractCVEFromVia(vuln.Via, pkgName)
-					fixedIn := extractFixInfo(vuln.FixAvailable)
+// projectEcosystem groups the project types whose build covers the projects below it: Maven modules,
+// npm/yarn/pnpm workspaces and the projects of a .NET solution. Go, Python, PHP, Rust and Ruby projects
+// in subdirectories stand on their own.
+func projectEcosystem(projectType string) string {
+	switch projectType {
+	case "maven", "dotnet":
+		return projectType
+	case "npm", "yarn", "pnpm":
+		return "node"
+	}
+	return ""
+}
 
-					result.Findings = append(result.Findings, CVEFinding{
-						CVE:         cveID,
-						Severity:    severity,
-						Package:     pkgName,
-						Version:     vuln.Range,
-						FixedIn:     fixedIn,
-						Description: truncateString(description, 200),
-					})
-				}
-				return result
-			}
+// findSubProjects returns the projects in subdirectories of the repository, up to subProjectDepth
+// levels deep, except Maven modules and Node.js packages below a project of the same ecosystem
+func findSubProjects(repoPath string) []SubProject {
+	type claim struct{ dir, ecosystem string }
+	var claims []claim
+	if ecosystem := projectEcosystem(detectProjectType(repoPath)); ecosystem != "" {
+		claims = append(claims, claim{repoPath, ecosystem})
+	}
 
-			// Check if we have advisories (older format)
-			if len(yarnModernResult.Advisories) > 0 {
-				for _, adv := range yarnModernResult.Advisories {
-					severity := normalizeSeverity(adv.Severity)
-					result.Findings = append(result.Findings, CVEFinding{
-						CVE:         fmt.Sprintf("yarn:%d", adv.ID),
-						Severity:    severity,
-						Package:     adv.ModuleName,
-						Version:     adv.VulnerableVersions,
-						FixedIn:     adv.PatchedVersions,
-						Description: truncateString(adv.Title, 200),
-					})
-				}
-				return result
+	var projects []SubProject
+	filepath.WalkDir(repoPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || path == repoPath {
+			return nil
+		}
+		rel, _ := filepath.Rel(repoPath, path)
+		if strings.HasPrefix(d.Name(), ".") || skippedProjectDirs[d.Name()] || strings.Count(rel, string(filepath.Separator)) >= subProjectDepth {
+			return filepath.SkipDir
+		}
+		projectType := detectProjectType(path)
+		if projectType == "" || projectType == "python-no-deps" {
+			return nil
+		}
+		ecosystem := projectEcosystem(projectType)
+		for _, c := range claims {
+			if c.ecosystem == ecosystem && strings.HasPrefix(path, c.dir+string(filepath.Separator)) {
+				return nil
 			}
 		}
+		if ecosystem != "" {
+			claims = append(claims, claim{path, ecosystem})
+		}
+		projects = append(projects, SubProject{Path: filepath.ToSlash(rel), ProjectType: projectType})
+		return nil
+	})
+	return projects
+}
+
+// scannerApplies tells whether the scanner can scan a project of the type
+func scannerApplies(scanner, projectType string) bool {
+	switch scanner {
+	case "owasp":
+		return projectType == "maven"
+	case "npm":
+		return projectType == "npm" || projectType == "yarn" || projectType == "pnpm"
+	case "govulncheck":
+		return projectType == "go"
+	case "pip-audit":
+		return projectType == "python"
+	case "composer-audit":
+		return projectType == "php"
+	case "cargo-audit":
+		return projectType == "rust"
+	case "dotnet":
+		return projectType == "dotnet"
+	case "bundler-audit":
+		return projectType == "ruby"
+	case "trivy":
+		return projectType != "" && projectType != "python-no-deps"
 	}
+	return false
+}
 
-	// Yarn Classic (v1) NDJSON format - each line is a separate JSON object
-	lines := strings.Split(outputStr, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
+// addSubProject adds the findings of a sub-project scan to the repository result, tagged with the
+// sub-path; a failed sub-project becomes a warning, the rest of the repository is still reported
+func addSubProject(result *RepoSecurityResult, sub SubProject, subResult RepoSecurityResult) {
+	sub.Findings = len(subResult.Findings)
+	sub.Error = subResult.Error
+	sub.CachedAt = subResult.CachedAt
+	for _, f := range subResult.Findings {
+		f.SubPath = sub.Path
+		result.Findings = append(result.Findings, f)
+	}
+	if sub.Error != "" {
+		warning := sub.Path + ": " + sub.Error
+		if result.Warning != "" {
+			warning = result.Warning + " | " + warning
 		}
+		result.Warning = warning
+	}
+	result.SubProjects = append(result.SubProjects, sub)
+}
 
-		var entry struct {
-			Type string `json:"type"`
-			Data struct {
-				Advisory struct {
-					ID                 int    `json:"id"`
-					ModuleName         string `json:"module_name"`
-					Severity           string `json:"severity"`
-					Title              string `json:"title"`
-					URL                string `json:"url"`
-					VulnerableVersions string `json:"vulnerable_versions"`
-					PatchedVersions    string `json:"patched_versions"`
-				} `json:"advisory"`
-			} `json:"data"`
+// hasPythonFiles checks if there are .py files in the root directory
+func hasPythonFiles(repoPath string) bool {
+	entries, err := os.ReadDir(repoPath)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".py") {
+			return true
 		}
+	}
+	return false
+}
 
-		if err := json.Unmarshal([]byte(line), &entry); err != nil {
-			continue
-		}
+// checkNpmAvailable checks if npm is available
+func checkNpmAvailable() bool {
+	cmd := exec.Command("npm", "--version")
+	return cmdlimit.Run(cmd) == nil
+}
 
-		if entry.Type != "auditAdvisory" {
-			continue
-		}
+// checkYarnAvailable checks if yarn is available
+func checkYarnAvailable() bool {
+	cmd := exec.Command("yarn", "--version")
+	return cmdlimit.Run(cmd) == nil
+}
 
-		adv := entry.Data.Advisory
-		severity := normalizeSeverity(adv.Severity)
+// checkPnpmAvailable checks if pnpm is available
+func checkPnpmAvailable() bool {
+	cmd := exec.Command("pnpm", "--version")
+	return cmdlimit.Run(cmd) == nil
+}
 
-		result.Findings = append(result.Findings, CVEFinding{
-			CVE:         fmt.Sprintf("yarn:%d", adv.ID),
-			Severity:    severity,
-			Package:     adv.ModuleName,
-			Version:     adv.VulnerableVersions,
-			FixedIn:     adv.PatchedVersions,
-			Description: truncateString(adv.Title, 200),
-		})
+// checkGovulncheckAvailable checks if govulncheck is available
+func checkGovulncheckAvailable() bool {
+	cmd := exec.Command("govulncheck", "-version")
+	return cmdlimit.Run(cmd) == nil
+}
+
+// checkPipAuditAvailable checks if pip-audit is available
+func checkPipAuditAvailable() bool {
+	cmd := exec.Command("pip-audit", "--version")
+	return cmdlimit.Run(cmd) == nil
+}
+
+// checkComposerAvailable checks if composer is available
+func checkComposerAvailable() bool {
+	cmd := exec.Command("composer", "--version")
+	return cmdlimit.Run(cmd) == nil
+}
+
+// checkDotnetAvailable checks if the .NET SDK is available
+func checkDotnetAvailable() bool {
+	cmd := exec.Command("dotnet", "--version")
+	return cmdlimit.Run(cmd) == nil
+}
+
+// checkBundlerAuditAvailable checks if bundler-audit is available
+func checkBundlerAuditAvailable() bool {
+	cmd := exec.Command("bundle-audit", "version")
+	return cmdlimit.Run(cmd) == nil
+}
+
+// checkCargoAuditAvailable checks if cargo audit is available
+func checkCargoAuditAvailable() bool {
+	cmd := exec.Command("cargo", "audit", "--version")
+	return cmdlimit.Run(cmd) == nil
+}
+
+// handleEnvironment reports which of the programs GitHousekeeper uses are installed, with their
+// versions and install hints for the missing ones
+func handleEnvironment(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(logic.InspectEnvironment())
+}
+
+type InstallToolRequest struct {
+	Tool string `json:"tool"` // govulncheck, trivy or pip-audit
+}
+
+// handleInstallTool installs a missing scanner on the user's request, into the tool cache of the
+// data directory or with the package manager of the system
+func handleInstallTool(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
 
-	return result
+	var req InstallToolRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if network, err := logic.LoadNetworkSettings(); err == nil && network.Offline {
+		http.Error(w, "Offline mode: tools cannot be downloaded", http.StatusConflict)
+		return
+	}
+
+	result, err := logic.InstallTool(req.Tool)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	slog.Info("Tool installation", "tool", result.Tool, "method", result.Method, "success", result.Success)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
 }
 
-// normalizeSeverity converts various severity names to standard format
-func normalizeSeverity(severity string) string {
-	switch strings.ToUpper(severity) {
-	case "CRITICAL", "HIGH", "MEDIUM", "LOW":
-		return strings.ToUpper(severity)
-	case "MODERATE":
-		return "MEDIUM"
+// handleOwaspSettings returns (GET, without API key) or saves (POST) the NVD API key and the shared
+// database directory of OWASP dependency-check: /api/owasp-settings
+func handleOwaspSettings(w http.ResponseWriter, r *http.Request) {
+	var settings logic.OwaspSettings
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		settings, err = logic.LoadOwaspSettings()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if settings, err = logic.SaveOwaspSettings(settings); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Info("OWASP settings updated", "dataDirectory", settings.DataDirectory, "nvdApiKey", settings.NVDApiKey != "")
 	default:
-		return "LOW"
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
-}
 
-// extractCVEFromVia extracts CVE ID and description from npm's via field
-func extractCVEFromVia(via []interface{}, pkgName string) (string, string) {
-	cveID := ""
-	description := ""
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		logic.OwaspSettings
+		HasNVDApiKey bool `json:"hasNvdApiKey"`
+	}{settings.Redacted(), settings.NVDApiKey != ""})
+}
 
-	for _, v := range via {
-		if viaMap, ok := v.(map[string]interface{}); ok {
-			if source, exists := viaMap["source"]; exists {
-				if sourceNum, ok := source.(float64); ok {
-					cveID = fmt.Sprintf("GHSA-%d", int(sourceNum))
-				}
+// handleOwaspDB reports the location, size and age of the shared NVD database: /api/owasp-db
+func handleOwaspDB(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	db, err := logic.InspectOwaspDatabase()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(db)
+}
+
+// OwaspDBUpdateRequest is the body of /api/owasp-db-update
+type OwaspDBUpdateRequest struct {
+	Maven logic.MavenSettings `json:"maven"`
+}
+
+// handleOwaspDBUpdate downloads or refreshes the shared NVD database, streaming Maven's output
+// line by line and ending with OWASP_DB_DONE:ok or OWASP_DB_DONE:failed:<error>
+func handleOwaspDBUpdate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req OwaspDBUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if network, err := logic.LoadNetworkSettings(); err == nil && network.Offline {
+		http.Error(w, "Offline mode: the NVD database cannot be downloaded", http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	start := time.Now()
+	err := logic.UpdateOwaspDatabase(r.Context(), req.Maven, func(line string) {
+		fmt.Fprintln(w, line)
+		flusher.Flush()
+	})
+	slog.Info("OWASP NVD database update", "duration", time.Since(start).Round(time.Second), "error", err)
+	if err != nil {
+		fmt.Fprintf(w, "OWASP_DB_DONE:failed:%v\n", err)
+	} else {
+		fmt.Fprintln(w, "OWASP_DB_DONE:ok")
+	}
+	flusher.Flush()
+}
+
+// prepareOwaspDatabase reports the age of the shared NVD database and updates it once before the
+// OWASP scans, which then only read it. Returns the plugin flags of the scans.
+func prepareOwaspDatabase(ctx context.Context, w io.Writer, flusher http.Flusher, maven logic.MavenSettings) []string {
+	settings, err := logic.LoadOwaspSettings()
+	if err != nil {
+		fmt.Fprintf(w, "SCAN_WARNING:OWASP settings not loaded: %v\n", err)
+		return nil
+	}
+	if db, err := logic.InspectOwaspDatabase(); err != nil {
+		fmt.Fprintf(w, "SCAN_WARNING:OWASP NVD database not readable: %v\n", err)
+	} else if db.Exists {
+		fmt.Fprintf(w, "SCAN_INFO:OWASP NVD database of %s, updated %s ago\n", db.UpdatedAt.Format("2006-01-02 15:04"), time.Duration(db.AgeHours*float64(time.Hour)).Round(time.Minute))
+	} else {
+		fmt.Fprintf(w, "SCAN_WARNING:OWASP NVD database not downloaded yet, the first download takes 10+ minutes (hours without an NVD API key)\n")
+	}
+	flusher.Flush()
+
+	if network, err := logic.LoadNetworkSettings(); err == nil && network.Offline {
+		fmt.Fprintf(w, "SCAN_INFO:Offline mode, OWASP scans use the NVD database as it is\n")
+	} else {
+		fmt.Fprintf(w, "SCAN_INFO:Updating the OWASP NVD database...\n")
+		flusher.Flush()
+		err := logic.UpdateOwaspDatabase(ctx, maven, func(line string) {
+			fmt.Fprintf(w, "SCAN_OWASP_DB:%s\n", line)
+			flusher.Flush()
+		})
+		if err != nil {
+			fmt.Fprintf(w, "SCAN_WARNING:%v\n", err)
+		}
+	}
+	flusher.Flush()
+
+	args, err := settings.ScanArgs()
+	if err != nil {
+		fmt.Fprintf(w, "SCAN_WARNING:OWASP data directory not usable: %v\n", err)
+		return nil
+	}
+	return args
+}
+
+func handleCheckTrivy(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	// Check if trivy is available
+	cmd := exec.Command("which", "trivy")
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("where", "trivy")
+	}
+
+	if err := cmdlimit.Run(cmd); err != nil {
+		json.NewEncoder(w).Encode(map[string]bool{"available": false})
+		return
+	}
+
+	// Get trivy version
+	cmd = exec.Command("trivy", "--version")
+	output, err := cmdlimit.Output(cmd)
+	version := ""
+	if err == nil {
+		lines := strings.Split(string(output), "\n")
+		if len(lines) > 0 {
+			version = strings.TrimSpace(lines[0])
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"available": true,
+		"version":   version,
+	})
+}
+
+func handleCheckNpm(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	result := map[string]bool{
+		"npm":  checkNpmAvailable(),
+		"yarn": checkYarnAvailable(),
+		"pnpm": checkPnpmAvailable(),
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleCheckSigning creates a signed test commit with the given settings
+func handleCheckSigning(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var settings logic.SigningSettings
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result := map[string]interface{}{"ok": true}
+	if err := logic.VerifySigning(settings); err != nil {
+		result = map[string]interface{}{"ok": false, "error": err.Error()}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func handleCheckGo(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	available := checkGovulncheckAvailable()
+	version := ""
+
+	if available {
+		cmd := exec.Command("govulncheck", "-version")
+		output, err := cmdlimit.Output(cmd)
+		if err == nil {
+			version = strings.TrimSpace(string(output))
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"available": available,
+		"version":   version,
+	})
+}
+
+func handleCheckPython(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	available := checkPipAuditAvailable()
+	version := ""
+
+	if available {
+		cmd := exec.Command("pip-audit", "--version")
+		output, err := cmdlimit.Output(cmd)
+		if err == nil {
+			version = strings.TrimSpace(string(output))
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"available": available,
+		"version":   version,
+	})
+}
+
+func handleCheckPhp(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	available := checkComposerAvailable()
+	version := ""
+
+	if available {
+		cmd := exec.Command("composer", "--version")
+		output, err := cmdlimit.Output(cmd)
+		if err == nil {
+			// Extract version from "Composer version 2.x.x ..."
+			version = strings.TrimSpace(string(output))
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"available": available,
+		"version":   version,
+	})
+}
+
+func handleCheckRust(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	available := checkCargoAuditAvailable()
+	version := ""
+
+	if available {
+		cmd := exec.Command("cargo", "audit", "--version")
+		output, err := cmdlimit.Output(cmd)
+		if err == nil {
+			version = strings.TrimSpace(string(output))
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"available": available,
+		"version":   version,
+	})
+}
+
+func handleCheckDotnet(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	available := checkDotnetAvailable()
+	version := ""
+
+	if available {
+		cmd := exec.Command("dotnet", "--version")
+		output, err := cmdlimit.Output(cmd)
+		if err == nil {
+			version = strings.TrimSpace(string(output))
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"available": available,
+		"version":   version,
+	})
+}
+
+func handleCheckRuby(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	available := checkBundlerAuditAvailable()
+	version := ""
+
+	if available {
+		cmd := exec.Command("bundle-audit", "version")
+		output, err := cmdlimit.Output(cmd)
+		if err == nil {
+			version = strings.TrimSpace(string(output))
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"available": available,
+		"version":   version,
+	})
+}
+
+// autoScanners is the scanner of the "auto" mode per project type
+var autoScanners = map[string]string{
+	"maven":  "owasp",
+	"npm":    "npm",
+	"yarn":   "npm",
+	"pnpm":   "npm",
+	"go":     "govulncheck",
+	"python": "pip-audit",
+	"php":    "composer-audit",
+	"rust":   "cargo-audit",
+	"dotnet": "dotnet",
+	"ruby":   "bundler-audit",
+}
+
+// scannerOf returns the scanner a repository of the project type gets
+func scannerOf(req SecurityScanRequest, projectType string) string {
+	if req.Scanner == "auto" {
+		return autoScanners[projectType]
+	}
+	return req.Scanner
+}
+
+// repoProjects returns the project at the repository root (path ".", possibly without type) and the
+// sub-projects
+func repoProjects(repo string) []SubProject {
+	return append([]SubProject{{Path: ".", ProjectType: detectProjectType(repo)}}, findSubProjects(repo)...)
+}
+
+// usesOwasp tells whether any of the repositories is scanned with OWASP dependency-check
+func usesOwasp(repos []string, req SecurityScanRequest) bool {
+	for _, repo := range repos {
+		for _, project := range repoProjects(repo) {
+			if project.ProjectType == "maven" && scannerOf(req, project.ProjectType) == "owasp" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scanToolNeeds returns the programs the scan needs, by the scanner each repository gets. The
+// checked-out state is inspected, a target branch of another project type is not foreseen.
+func scanToolNeeds(repos []string, req SecurityScanRequest) logic.ToolNeeds {
+	needs := logic.ToolNeeds{}
+	for _, repo := range repos {
+		for _, project := range repoProjects(repo) {
+			projectType := project.ProjectType
+			scanner := scannerOf(req, projectType)
+			switch {
+			case scanner == "owasp" && projectType == "maven":
+				needs.AddMaven(filepath.Join(repo, project.Path), req.Maven)
+			case scanner == "npm" && (projectType == "npm" || projectType == "yarn" || projectType == "pnpm"):
+				needs.Add(projectType, repo)
+			case scanner == "govulncheck" && projectType == "go":
+				needs.Add("govulncheck", repo)
+			case scanner == "pip-audit" && projectType == "python":
+				needs.Add("pip-audit", repo)
+			case scanner == "composer-audit" && projectType == "php":
+				needs.Add("composer", repo)
+			case scanner == "cargo-audit" && projectType == "rust":
+				needs.Add("cargo-audit", repo)
+			case scanner == "dotnet" && projectType == "dotnet":
+				needs.Add("dotnet", repo)
+			case scanner == "bundler-audit" && projectType == "ruby":
+				needs.Add("bundle-audit", repo)
+			case scanner == "trivy" && projectType != "":
+				needs.Add("trivy", repo)
+			}
+		}
+		if req.ContainerImages != "" && len(logic.FindDockerfiles(repo)) > 0 {
+			needs.Add("trivy", repo)
+			if req.ContainerImages == "build" {
+				needs.Add("docker", repo)
+			}
+		}
+		if req.ConfigScan != "" && configScanApplies(repo, req.ConfigScan) {
+			needs.Add(req.ConfigScan, repo)
+			if req.ConfigScan == "kubesec" && len(logic.FindKubernetes(repo).Charts) > 0 {
+				needs.Add("helm", repo)
+			}
+		}
+		if req.TargetBranch != "" {
+			needs.Add("git", repo)
+		}
+	}
+	return needs
+}
+
+// writeMissingTools lists the programs a job cannot run without, with the repositories needing
+// them and how to install them
+func writeMissingTools(w io.Writer, missing []logic.MissingTool) {
+	for _, m := range missing {
+		repos := m.Repos
+		if len(repos) > 5 {
+			repos = append(slices.Clip(repos[:5]), fmt.Sprintf("and %d more", len(m.Repos)-5))
+		}
+		fmt.Fprintf(w, "  %s, needed by %s\n", m.Name, strings.Join(repos, ", "))
+		fmt.Fprintf(w, "    %s\n", m.Hint)
+	}
+}
+
+func handleSecurityScan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req SecurityScanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Default to OWASP
+	if req.Scanner == "" {
+		req.Scanner = "owasp"
+	}
+	if req.OnlyNew {
+		req.Compare = true
+	}
+
+	if req.ConfigScan != "" && req.ConfigScan != "trivy" && req.ConfigScan != "kubesec" && req.ConfigScan != "tfsec" {
+		http.Error(w, "configScan must be trivy, kubesec or tfsec", http.StatusBadRequest)
+		return
+	}
+	req.MinSeverity = strings.ToUpper(req.MinSeverity)
+	if req.MinSeverity != "" && !logic.ValidSeverity(req.MinSeverity) {
+		http.Error(w, "minSeverity must be CRITICAL, HIGH, MEDIUM or LOW", http.StatusBadRequest)
+		return
+	}
+	if req.FailOn != nil {
+		req.FailOn.Severity = strings.ToUpper(req.FailOn.Severity)
+		if !logic.ValidSeverity(req.FailOn.Severity) || req.FailOn.MaxCount < 0 {
+			http.Error(w, "failOn needs a severity (CRITICAL, HIGH, MEDIUM or LOW) and a maxCount >= 0", http.StatusBadRequest)
+			return
+		}
+	}
+
+	repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Set headers for streaming
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	// The policy result is known only at the end, CI clients can read it from the trailer
+	w.Header().Set("Trailer", "X-Scan-Policy")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	total := len(repos)
+	slog.Debug("Security scan started", "rootPath", req.RootPath, "group", req.Group, "excluded", req.Excluded, "scanner", req.Scanner, "repos", repos)
+
+	fmt.Fprintf(w, "SCAN_INIT:%d:%s\n", total, req.Scanner)
+
+	// Fail before scanning instead of every repository of a project type failing on its own
+	if missing := scanToolNeeds(repos, req).Missing(req.Maven); len(missing) > 0 {
+		for _, m := range missing {
+			data, _ := json.Marshal(m)
+			fmt.Fprintf(w, "SCAN_MISSING_TOOL:%s\n", data)
+		}
+		fmt.Fprintf(w, "SCAN_ABORT:%d required tools are not installed\n", len(missing))
+		flusher.Flush()
+		return
+	}
+
+	suppressions, err := logic.LoadSuppressions(req.RootPath)
+	if err != nil {
+		fmt.Fprintf(w, "SCAN_WARNING:Suppressions not applied: %v\n", err)
+	} else if len(suppressions.Suppressions) > 0 {
+		fmt.Fprintf(w, "SCAN_INFO:%d suppressions loaded from %s\n", len(suppressions.Suppressions), logic.SuppressionsFile)
+	}
+	flusher.Flush()
+
+	// OWASP scans share one NVD database, updated once here instead of by up to 4 scans at once
+	var owaspArgs []string
+	if usesOwasp(repos, req) {
+		owaspArgs = prepareOwaspDatabase(r.Context(), w, flusher, req.Maven)
+	}
+
+	// Determine worker count (parallel scans)
+	workerCount := 4
+	if total < workerCount {
+		workerCount = total
+	}
+	if workerCount < 1 {
+		workerCount = 1
+	}
+
+	// Create channels for work distribution
+	type scanJob struct {
+		repoPath     string
+		repoName     string
+		index        int
+		targetBranch string
+	}
+
+	type scanResult struct {
+		result RepoSecurityResult
+		index  int
+	}
+
+	jobs := make(chan scanJob, total)
+	results := make(chan scanResult, total)
+
+	// Start workers
+	var wg sync.WaitGroup
+	for w := 0; w < workerCount; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				start := time.Now()
+				var result RepoSecurityResult
+				result.RepoName = job.repoName
+
+				// Handle branch switching if targetBranch is specified
+				var originalBranch string
+				var stash *logic.Stash
+				var worktree *logic.Worktree
+				branchSwitched := false
+				scanPath := job.repoPath // The temporary worktree in worktree mode
+				// switchBack returns to the original branch and restores stashed changes, or removes the
+				// worktree; what cannot be restored is reported, never left behind silently
+				switchBack := func() {
+					if worktree != nil {
+						if err := worktree.Remove(); err != nil {
+							result.Warning = err.Error()
+							slog.Warn("Security scan could not remove its worktree", "repo", job.repoName, "error", err)
+						}
+						return
+					}
+					if !branchSwitched {
+						return
+					}
+					checkoutCmd := exec.Command("git", "checkout", originalBranch)
+					checkoutCmd.Dir = job.repoPath
+					if err := cmdlimit.Run(checkoutCmd); err != nil {
+						result.Warning = fmt.Sprintf("Could not return to %s: %v", originalBranch, err)
+						if stash != nil {
+							result.Warning += fmt.Sprintf(". Uncommitted changes are kept in the stash '%s'", stash.Message)
+						}
+					} else if stash != nil {
+						if err := stash.Restore(); err != nil {
+							result.Warning = err.Error()
+						}
+					}
+					if result.Warning != "" {
+						slog.Warn("Security scan could not restore the repository", "repo", job.repoName, "warning", result.Warning)
+					}
+				}
+				// A branch that only exists on origin is fetched and always scanned in a worktree
+				targetRef, remoteOnly := job.targetBranch, false
+				if job.targetBranch != "" {
+					var err error
+					if targetRef, remoteOnly, err = logic.ResolveBranch(job.repoPath, job.targetBranch); err != nil {
+						result.Error = err.Error()
+						result.Duration = time.Since(start).Seconds()
+						results <- scanResult{result: result, index: job.index}
+						continue
+					}
+				}
+				if job.targetBranch != "" && (req.Worktree || remoteOnly) {
+					// Scan a separate checkout, the developer's working copy is not touched
+					var err error
+					if worktree, err = logic.AddWorktree(job.repoPath, targetRef); err != nil {
+						result.Error = err.Error()
+						result.Duration = time.Since(start).Seconds()
+						results <- scanResult{result: result, index: job.index}
+						continue
+					}
+					scanPath = worktree.Path
+					result.ScannedBranch = job.targetBranch
+				} else if job.targetBranch != "" {
+					// Get current branch
+					originalBranch = getCurrentBranch(job.repoPath)
+					if originalBranch == "" {
+						// Detached HEAD: return to the commit afterwards
+						originalBranch, _ = gitops.Open(job.repoPath).Head()
+					}
+
+					// Only switch if we're not already on the target branch
+					if originalBranch != job.targetBranch {
+						var err error
+						if stash, err = logic.PushStash(job.repoPath, "security scan"); err != nil {
+							result.Error = err.Error()
+							result.Duration = time.Since(start).Seconds()
+							results <- scanResult{result: result, index: job.index}
+							continue
+						}
+
+						// Switch to target branch
+						checkoutCmd := exec.Command("git", "checkout", job.targetBranch)
+						checkoutCmd.Dir = job.repoPath
+						if err := cmdlimit.Run(checkoutCmd); err != nil {
+							result.Error = fmt.Sprintf("Failed to checkout branch %s: %v", job.targetBranch, err)
+							if stash != nil {
+								if err := stash.Restore(); err != nil {
+									result.Warning = err.Error()
+								}
+							}
+							result.Duration = time.Since(start).Seconds()
+							results <- scanResult{result: result, index: job.index}
+							continue
+						}
+						branchSwitched = true
+						result.ScannedBranch = job.targetBranch
+					} else {
+						result.ScannedBranch = originalBranch
+					}
+				} else {
+					result.ScannedBranch = getCurrentBranch(job.repoPath)
+					if result.ScannedBranch == "" {
+						result.ScannedBranch = "HEAD"
+					}
+				}
+
+				// Store scanned branch for result preservation
+				scannedBranch := result.ScannedBranch
+
+				// Detect project type
+				projectType := detectProjectType(scanPath)
+				result.ProjectType = projectType
+				// Sub-projects in subdirectories (backend/pom.xml next to frontend/package.json) get
+				// the scanner of their own type, their findings are tagged with the sub-path
+				subProjects := findSubProjects(scanPath)
+
+				// Determine which scanner to use
+				scannerToUse := req.Scanner
+				if req.Scanner == "auto" {
+					// Auto-detect based on project type
+					switch projectType {
+					case "maven", "npm", "yarn", "pnpm", "go", "python", "php", "rust", "dotnet", "ruby":
+						scannerToUse = autoScanners[projectType]
+					case "python-no-deps":
+						if len(subProjects) > 0 {
+							scannerToUse = ""
+							break
+						}
+						result.Error = "Python project found but no requirements.txt, pyproject.toml, setup.py, or Pipfile. Cannot scan without dependency file."
+						result.ProjectType = "python"
+						result.Duration = time.Since(start).Seconds()
+						switchBack()
+						results <- scanResult{result: result, index: job.index}
+						continue
+					default:
+						// Monorepos may only have projects in subdirectories
+						if len(subProjects) > 0 {
+							scannerToUse = ""
+							break
+						}
+						// Infrastructure repos may only contain Dockerfiles, Kubernetes manifests or Terraform
+						if (req.ContainerImages != "" && len(logic.FindDockerfiles(scanPath)) > 0) ||
+							(req.ConfigScan != "" && configScanApplies(scanPath, req.ConfigScan)) {
+							scannerToUse = "none"
+							break
+						}
+						result.Error = "No supported project type found (pom.xml, package.json, go.mod, requirements.txt, composer.json, Cargo.toml, .csproj/.sln, or Gemfile)"
+						result.Duration = time.Since(start).Seconds()
+						// Switch back to original branch before returning error
+						switchBack()
+						results <- scanResult{result: result, index: job.index}
+						continue
+					}
+				}
+
+				if scannerToUse != "" && !scannerApplies(scannerToUse, projectType) && len(subProjects) > 0 {
+					scannerToUse = "" // Nothing to scan at the root
+				}
+				if scannerToUse != "" {
+					result = scanProject(scanPath, job.repoName, projectType, scannerToUse, req, owaspArgs)
+				}
+				for _, sub := range subProjects {
+					if sub.Scanner = scannerOf(req, sub.ProjectType); scannerApplies(sub.Scanner, sub.ProjectType) {
+						addSubProject(&result, sub, scanProject(filepath.Join(scanPath, sub.Path), job.repoName, sub.ProjectType, sub.Scanner, req, owaspArgs))
+					}
+				}
+				if scannerToUse == "" {
+					if len(result.SubProjects) == 0 {
+						result.Error = fmt.Sprintf("No sub-project can be scanned with %s", req.Scanner)
+					}
+					result.ProjectType = "monorepo"
+					scannerToUse = req.Scanner
+				}
+
+				// Container images: OS-level CVEs alongside the dependency CVEs
+				if req.ContainerImages != "" {
+					imageFindings, imageErrors := runTrivyImageScans(scanPath, job.repoName, req.ContainerImages)
+					result.Findings = append(result.Findings, imageFindings...)
+					if len(imageErrors) > 0 {
+						imageError := "Image scan: " + strings.Join(imageErrors, "; ")
+						if result.Error != "" {
+							result.Error += " | " + imageError
+						} else {
+							result.Error = imageError
+						}
+					}
+				}
+
+				// Kubernetes manifests and Helm charts: misconfigurations
+				if req.ConfigScan != "" {
+					configFindings, configErrors := runConfigScan(scanPath, req.ConfigScan)
+					result.Findings = append(result.Findings, configFindings...)
+					if len(configErrors) > 0 {
+						configError := "Config scan: " + strings.Join(configErrors, "; ")
+						if result.Error != "" {
+							result.Error += " | " + configError
+						} else {
+							result.Error = configError
+						}
+					}
+				}
+
+				// Restore the scanned branch info (may be lost in scanner functions)
+				result.ScannedBranch = scannedBranch
+				result.Duration = time.Since(start).Seconds()
+
+				// Keep the latest result per scanner in the findings store and every result in the scan
+				// history of the branch (failed scans don't count)
+				if result.Error == "" || len(result.Findings) > 0 {
+					source := scannerToUse
+					if source == "none" {
+						switch {
+						case req.ContainerImages != "":
+							source = "trivy-image"
+						case req.ConfigScan == "trivy":
+							source = "trivy-config"
+						default:
+							source = req.ConfigScan
+						}
+					}
+					findings := toSecurityFindings(result.Findings)
+					if err := logic.SaveFindings(job.repoPath, source, findings); err != nil {
+						slog.Warn("Could not store security findings", "repo", job.repoName, "error", err)
+					}
+					previous, err := logic.RecordScan(job.repoPath, result.ScannedBranch, source, findings)
+					if err != nil {
+						slog.Warn("Could not record the scan history", "repo", job.repoName, "error", err)
+					}
+					if req.Compare && previous != nil {
+						markFindingChanges(&result, logic.CompareFindings(previous.Findings, findings))
+						result.PreviousScanAt = &previous.ScannedAt
+					}
+				}
+
+				// The store keeps everything, the response only what passes the threshold
+				result.Findings = filterFindingsBySeverity(result.Findings, req.MinSeverity)
+				result.Fixed = filterFindingsBySeverity(result.Fixed, req.MinSeverity)
+				if req.OnlyNew && result.PreviousScanAt != nil {
+					result.Findings = slices.DeleteFunc(result.Findings, func(f CVEFinding) bool { return f.Change != logic.FindingNew })
+				}
+				result.Findings, result.Suppressed = applySuppressions(result.Findings, suppressions, time.Now())
+
+				// Switch back to original branch if we switched
+				switchBack()
+
+				results <- scanResult{result: result, index: job.index}
+			}
+		}()
+	}
+
+	// Send all repos that are being scanned
+	for _, repoPath := range repos {
+		repoName := filepath.Base(repoPath)
+		fmt.Fprintf(w, "REPO_START:%s\n", repoName)
+	}
+	flusher.Flush()
+
+	// Submit jobs
+	go func() {
+		for i, repoPath := range repos {
+			jobs <- scanJob{
+				repoPath:     repoPath,
+				repoName:     filepath.Base(repoPath),
+				index:        i,
+				targetBranch: req.TargetBranch,
+			}
+		}
+		close(jobs)
+	}()
+
+	// Collect results in a goroutine
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Process results as they come in
+	allResults := make([]RepoSecurityResult, total)
+	totalCritical, totalHigh, totalMedium, totalLow := 0, 0, 0, 0
+	completed := 0
+	scanStart := time.Now()
+
+	for res := range results {
+		completed++
+		allResults[res.index] = res.result
+
+		// Count severities
+		for _, f := range res.result.Findings {
+			switch f.Severity {
+			case "CRITICAL":
+				totalCritical++
+			case "HIGH":
+				totalHigh++
+			case "MEDIUM":
+				totalMedium++
+			case "LOW":
+				totalLow++
+			}
+		}
+
+		// Stream result as JSON (even skipped repos)
+		resultJSON, _ := json.Marshal(res.result)
+		fmt.Fprintf(w, "REPO_RESULT:%s\n", string(resultJSON))
+
+		// Calculate ETA
+		elapsed := time.Since(scanStart).Seconds()
+		avgTimePerRepo := elapsed / float64(completed)
+		remainingRepos := total - completed
+		eta := avgTimePerRepo * float64(remainingRepos)
+
+		fmt.Fprintf(w, "REPO_DONE:%s:%.1f\n", res.result.RepoName, res.result.Duration)
+		fmt.Fprintf(w, "SCAN_PROGRESS:%d:%d:%.0f\n", completed, total, eta)
+		flusher.Flush()
+	}
+
+	// Send summary
+	fmt.Fprintf(w, "SCAN_SUMMARY:%d:%d:%d:%d\n", totalCritical, totalHigh, totalMedium, totalLow)
+
+	// SCAN_POLICY:PASS or SCAN_POLICY:FAIL:<reason>
+	var policy string
+	if req.FailOn != nil {
+		policy = "PASS"
+		if violations := countFindingsAtLeast(allResults, req.FailOn.Severity); violations > req.FailOn.MaxCount {
+			policy = fmt.Sprintf("FAIL:%d findings with severity %s or higher (allowed: %d)", violations, req.FailOn.Severity, req.FailOn.MaxCount)
+		}
+		fmt.Fprintf(w, "SCAN_POLICY:%s\n", policy)
+		w.Header().Set("X-Scan-Policy", strings.SplitN(policy, ":", 2)[0])
+	}
+
+	if req.EmailReport {
+		report := &logic.ScanReport{RootPath: req.RootPath, ScannedAt: scanStart, Policy: policy}
+		if req.Group != "" {
+			report.RootPath = "Group " + req.Group
+		}
+		for _, result := range allResults {
+			repo := logic.ScanReportRepo{RepoName: result.RepoName, Branch: result.ScannedBranch, Type: result.ProjectType, Error: result.Error}
+			for _, f := range result.Findings {
+				switch f.Severity {
+				case "CRITICAL":
+					repo.Critical++
+				case "HIGH":
+					repo.High++
+				case "MEDIUM":
+					repo.Medium++
+				case "LOW":
+					repo.Low++
+				}
+			}
+			report.Repos = append(report.Repos, repo)
+		}
+		var html bytes.Buffer
+		logic.RenderScanReportHTML(report, &html)
+		subject := fmt.Sprintf("Security scan: %d critical, %d high, %d medium, %d low", totalCritical, totalHigh, totalMedium, totalLow)
+		if recipients, err := emailReport(req.EmailTo, subject, html.String()); err != nil {
+			fmt.Fprintf(w, "SCAN_WARNING:Report not emailed: %v\n", err)
+		} else {
+			fmt.Fprintf(w, "SCAN_INFO:Report emailed to %s\n", strings.Join(recipients, ", "))
+		}
+	}
+
+	fmt.Fprintf(w, "SCAN_COMPLETE\n")
+	flusher.Flush()
+}
+
+// filterFindingsBySeverity drops findings below min; unrated findings are kept ("" = no filter)
+func filterFindingsBySeverity(findings []CVEFinding, min string) []CVEFinding {
+	if min == "" {
+		return findings
+	}
+	var result []CVEFinding
+	for _, f := range findings {
+		if logic.SeverityAtLeast(f.Severity, min) || !logic.ValidSeverity(strings.ToUpper(f.Severity)) {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// applySuppressions moves the findings with an active suppression out of the actionable list
+func applySuppressions(findings []CVEFinding, suppressions *logic.SuppressionList, now time.Time) ([]CVEFinding, []SuppressedFinding) {
+	var actionable []CVEFinding
+	var suppressed []SuppressedFinding
+	for _, f := range findings {
+		if s := suppressions.Match(f.CVE, f.Package, now); s != nil {
+			suppressed = append(suppressed, SuppressedFinding{CVEFinding: f, Justification: s.Justification, Expires: s.Expires})
+			continue
+		}
+		actionable = append(actionable, f)
+	}
+	return actionable, suppressed
+}
+
+// countFindingsAtLeast counts the findings of all repos with the given severity or worse
+func countFindingsAtLeast(results []RepoSecurityResult, severity string) int {
+	count := 0
+	for _, r := range results {
+		for _, f := range r.Findings {
+			if logic.SeverityAtLeast(f.Severity, severity) {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// runScanner runs a security scanner with the scanner retry policy, stdout only or combined with stderr.
+// Scanners exit non-zero when they find vulnerabilities, so only a run without output is retried.
+func runScanner(cmd *exec.Cmd, combined bool) (output []byte, err error) {
+	attempt := cmd
+	logic.Retry(logic.RetryScanners, nil, func() error {
+		if attempt == nil {
+			// An exec.Cmd runs only once
+			attempt = exec.Command(cmd.Args[0], cmd.Args[1:]...)
+			attempt.Dir, attempt.Env = cmd.Dir, cmd.Env
+		}
+		if combined {
+			output, err = cmdlimit.CombinedOutput(attempt)
+		} else {
+			output, err = cmdlimit.Output(attempt)
+		}
+		attempt = nil
+		if errors.Is(err, exec.ErrNotFound) {
+			return logic.Permanent(err)
+		}
+		if err != nil && len(output) == 0 {
+			return err
+		}
+		return nil
+	})
+	return output, err
+}
+
+func runTrivyScan(repoPath, repoName string) RepoSecurityResult {
+	result := RepoSecurityResult{RepoName: repoName}
+
+	// Run trivy fs with JSON output
+	cmd := exec.Command("trivy", "fs", "--scanners", "vuln", "--format", "json", "--quiet", ".")
+	cmd.Dir = repoPath
+	output, err := runScanner(cmd, false)
+
+	if err != nil {
+		// Trivy returns exit code 1 if vulnerabilities found, but still outputs JSON
+		if len(output) == 0 {
+			result.Error = fmt.Sprintf("Trivy scan failed: %v", err)
+			return result
+		}
+	}
+
+	findings, err := parseTrivyOutput(output, "")
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Findings = findings
+	return result
+}
+
+// parseTrivyOutput converts the JSON report of 'trivy fs' or 'trivy image' into findings
+func parseTrivyOutput(output []byte, scanTarget string) ([]CVEFinding, error) {
+	var trivyResult struct {
+		Results []struct {
+			Vulnerabilities []struct {
+				VulnerabilityID  string `json:"VulnerabilityID"`
+				PkgName          string `json:"PkgName"`
+				InstalledVersion string `json:"InstalledVersion"`
+				FixedVersion     string `json:"FixedVersion"`
+				Severity         string `json:"Severity"`
+				Description      string `json:"Description"`
+			} `json:"Vulnerabilities"`
+		} `json:"Results"`
+	}
+
+	if err := json.Unmarshal(output, &trivyResult); err != nil {
+		return nil, fmt.Errorf("Failed to parse Trivy output: %v", err)
+	}
+
+	var findings []CVEFinding
+	for _, r := range trivyResult.Results {
+		for _, v := range r.Vulnerabilities {
+			findings = append(findings, CVEFinding{
+				CVE:         v.VulnerabilityID,
+				Severity:    strings.ToUpper(v.Severity),
+				Package:     v.PkgName,
+				Version:     v.InstalledVersion,
+				FixedIn:     v.FixedVersion,
+				Description: truncateString(v.Description, 200),
+				ScanTarget:  scanTarget,
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+// runTrivyImageScans scans the container images of all Dockerfiles in a repo.
+// mode "pull" scans the base images referenced by FROM (Trivy pulls them itself),
+// mode "build" builds each Dockerfile with docker and scans the resulting image.
+func runTrivyImageScans(repoPath, repoName, mode string) ([]CVEFinding, []string) {
+	var findings []CVEFinding
+	var failures []string
+
+	if _, err := exec.LookPath("trivy"); err != nil {
+		return nil, []string{"trivy is not installed"}
+	}
+	if mode == "build" {
+		if _, err := exec.LookPath("docker"); err != nil {
+			return nil, []string{"docker is required to build images"}
+		}
+	}
+
+	scanned := make(map[string]bool)
+	for i, dockerfile := range logic.FindDockerfiles(repoPath) {
+		var images []string
+		var target string
+		switch mode {
+		case "build":
+			tag := fmt.Sprintf("githousekeeper-scan/%s:%d", strings.ToLower(repoName), i)
+			cmd := exec.Command("docker", "build", "-q", "-f", dockerfile, "-t", tag, filepath.Dir(filepath.Join(repoPath, dockerfile)))
+			cmd.Dir = repoPath
+			if output, err := cmdlimit.CombinedOutput(cmd); err != nil {
+				failures = append(failures, fmt.Sprintf("build %s failed: %s", dockerfile, truncateString(strings.TrimSpace(string(output)), 200)))
+				continue
+			}
+			defer cmdlimit.Run(exec.Command("docker", "rmi", "-f", tag))
+			images = []string{tag}
+			target = "image:" + dockerfile
+		default:
+			var err error
+			images, err = logic.ParseBaseImages(filepath.Join(repoPath, dockerfile))
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("%s: %v", dockerfile, err))
+				continue
+			}
+		}
+
+		for _, image := range images {
+			if scanned[image] {
+				continue
+			}
+			scanned[image] = true
+
+			cmd := exec.Command("trivy", "image", "--scanners", "vuln", "--format", "json", "--quiet", image)
+			output, err := runScanner(cmd, false)
+			if err != nil && len(output) == 0 {
+				failures = append(failures, fmt.Sprintf("trivy image %s failed: %v", image, err))
+				continue
+			}
+
+			imageTarget := target
+			if imageTarget == "" {
+				imageTarget = "image:" + image
+			}
+			imageFindings, err := parseTrivyOutput(output, imageTarget)
+			if err != nil {
+				failures = append(failures, err.Error())
+				continue
+			}
+			findings = append(findings, imageFindings...)
+		}
+	}
+
+	return findings, failures
+}
+
+// configScanApplies tells whether the config scan tool has something to check in the repo: kubesec
+// Kubernetes manifests and Helm charts, tfsec Terraform, trivy both
+func configScanApplies(repoPath, tool string) bool {
+	switch tool {
+	case "kubesec":
+		return !logic.FindKubernetes(repoPath).Empty()
+	case "tfsec":
+		return logic.FindTerraform(repoPath) != nil
+	}
+	return !logic.FindKubernetes(repoPath).Empty() || logic.FindTerraform(repoPath) != nil
+}
+
+// runConfigScan checks the Kubernetes manifests, Helm charts and Terraform configurations of a repo
+// for misconfigurations. tool "trivy" runs 'trivy config' on the repo, "tfsec" runs tfsec on it,
+// "kubesec" scans every manifest and the rendered templates of every chart ('helm template').
+func runConfigScan(repoPath, tool string) ([]CVEFinding, []string) {
+	if !configScanApplies(repoPath, tool) {
+		return nil, nil
+	}
+	if _, err := exec.LookPath(tool); err != nil {
+		return nil, []string{tool + " is not installed"}
+	}
+
+	switch tool {
+	case "tfsec":
+		// --soft-fail: exit code 0 with findings
+		cmd := exec.Command("tfsec", ".", "--format", "json", "--soft-fail", "--no-color")
+		cmd.Dir = repoPath
+		output, err := runScanner(cmd, false)
+		if err != nil && len(output) == 0 {
+			return nil, []string{fmt.Sprintf("tfsec failed: %v", err)}
+		}
+		findings, err := parseTfsecOutput(output, repoPath)
+		if err != nil {
+			return nil, []string{err.Error()}
+		}
+		return findings, nil
+	case "trivy":
+		cmd := exec.Command("trivy", "config", "--misconfig-scanners", "kubernetes,helm,terraform", "--format", "json", "--quiet", ".")
+		cmd.Dir = repoPath
+		output, err := runScanner(cmd, false)
+		if err != nil && len(output) == 0 {
+			return nil, []string{fmt.Sprintf("trivy config failed: %v", err)}
+		}
+		findings, err := parseTrivyConfigOutput(output)
+		if err != nil {
+			return nil, []string{err.Error()}
+		}
+		return findings, nil
+	}
+
+	k8s := logic.FindKubernetes(repoPath)
+	var findings []CVEFinding
+	var failures []string
+	scan := func(file, target string) {
+		cmd := exec.Command("kubesec", "scan", file)
+		cmd.Dir = repoPath
+		// kubesec exits non-zero when an object fails a critical check
+		output, err := runScanner(cmd, false)
+		if err != nil && len(output) == 0 {
+			failures = append(failures, fmt.Sprintf("kubesec scan %s failed: %v", target, err))
+			return
+		}
+		fileFindings, err := parseKubesecOutput(output, target)
+		if err != nil {
+			failures = append(failures, err.Error())
+			return
+		}
+		findings = append(findings, fileFindings...)
+	}
+	for _, manifest := range k8s.Manifests {
+		scan(manifest, manifest)
+	}
+	if len(k8s.Charts) > 0 {
+		if _, err := exec.LookPath("helm"); err != nil {
+			return findings, append(failures, "helm is not installed, Helm charts not scanned")
+		}
+	}
+	for _, chart := range k8s.Charts {
+		cmd := exec.Command("helm", "template", chart)
+		cmd.Dir = repoPath
+		rendered, err := cmdlimit.Output(cmd)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("helm template %s failed: %v", chart, err))
+			continue
+		}
+		tmp, err := os.CreateTemp("", "githousekeeper-chart-*.yaml")
+		if err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+		tmp.Write(rendered)
+		tmp.Close()
+		scan(tmp.Name(), chart)
+		os.Remove(tmp.Name())
+	}
+	return findings, failures
+}
+
+// parseTrivyConfigOutput parses the failed checks of a 'trivy config' JSON report
+func parseTrivyConfigOutput(output []byte) ([]CVEFinding, error) {
+	var trivyResult struct {
+		Results []struct {
+			Target            string `json:"Target"`
+			Misconfigurations []struct {
+				ID       string `json:"ID"`
+				AVDID    string `json:"AVDID"`
+				Title    string `json:"Title"`
+				Message  string `json:"Message"`
+				Severity string `json:"Severity"`
+				Status   string `json:"Status"`
+			} `json:"Misconfigurations"`
+		} `json:"Results"`
+	}
+
+	if err := json.Unmarshal(output, &trivyResult); err != nil {
+		return nil, fmt.Errorf("Failed to parse Trivy config output: %v", err)
+	}
+
+	var findings []CVEFinding
+	for _, r := range trivyResult.Results {
+		for _, m := range r.Misconfigurations {
+			if m.Status != "" && m.Status != "FAIL" {
+				continue
+			}
+			id := m.ID
+			if id == "" {
+				id = m.AVDID
+			}
+			findings = append(findings, CVEFinding{
+				CVE:         id,
+				Severity:    strings.ToUpper(m.Severity),
+				Package:     m.Title,
+				Description: truncateString(m.Message, 200),
+				ScanTarget:  "config:" + r.Target,
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+// parseTfsecOutput parses a tfsec JSON report; its file names are absolute and made relative to the repo
+func parseTfsecOutput(output []byte, repoPath string) ([]CVEFinding, error) {
+	var report struct {
+		Results []struct {
+			RuleID      string `json:"rule_id"`
+			LongID      string `json:"long_id"`
+			Description string `json:"description"`
+			Resource    string `json:"resource"`
+			Severity    string `json:"severity"`
+			Location    struct {
+				Filename string `json:"filename"`
+			} `json:"location"`
+		} `json:"results"`
+	}
+
+	if err := json.Unmarshal(output, &report); err != nil {
+		return nil, fmt.Errorf("Failed to parse tfsec output: %v", err)
+	}
+
+	var findings []CVEFinding
+	for _, r := range report.Results {
+		file := r.Location.Filename
+		if rel, err := filepath.Rel(repoPath, file); err == nil && filepath.IsAbs(file) {
+			file = rel
+		}
+		id := r.RuleID
+		if id == "" {
+			id = r.LongID
+		}
+		findings = append(findings, CVEFinding{
+			CVE:         id,
+			Severity:    strings.ToUpper(r.Severity),
+			Package:     r.Resource,
+			Description: truncateString(r.Description, 200),
+			ScanTarget:  "config:" + filepath.ToSlash(file),
+		})
+	}
+
+	return findings, nil
+}
+
+// parseKubesecOutput parses a 'kubesec scan' JSON report. Only the critical checks are reported,
+// as HIGH: they are misconfigurations like privileged containers, not exploitable vulnerabilities.
+func parseKubesecOutput(output []byte, target string) ([]CVEFinding, error) {
+	var reports []struct {
+		Object  string `json:"object"`
+		Scoring struct {
+			Critical []struct {
+				ID     string `json:"id"`
+				Reason string `json:"reason"`
+			} `json:"critical"`
+		} `json:"scoring"`
+	}
+
+	if err := json.Unmarshal(output, &reports); err != nil {
+		return nil, fmt.Errorf("Failed to parse kubesec output: %v", err)
+	}
+
+	var findings []CVEFinding
+	for _, report := range reports {
+		for _, check := range report.Scoring.Critical {
+			findings = append(findings, CVEFinding{
+				CVE:         check.ID,
+				Severity:    "HIGH",
+				Package:     report.Object,
+				Description: truncateString(check.Reason, 200),
+				ScanTarget:  "config:" + target,
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+// scanProject runs the scanner on the project of the given type in dir, serving the cached result when
+// its dependencies did not change (also across repositories with the same lockfile)
+func scanProject(dir, repoName, projectType, scanner string, req SecurityScanRequest, owaspArgs []string) RepoSecurityResult {
+	result := RepoSecurityResult{RepoName: repoName, ProjectType: projectType}
+	fingerprint := ""
+	if scanner != "none" {
+		fingerprint = logic.DependencyFingerprint(dir, projectType)
+	}
+	if fingerprint != "" && !req.Refresh {
+		var cached RepoSecurityResult
+		if cachedAt, ok := logic.LoadCachedScan(scanner, fingerprint, &cached); ok {
+			cached.RepoName = repoName
+			cached.CachedAt = &cachedAt
+			return cached
+		}
+	}
+
+	switch scanner {
+	case "npm":
+		if projectType == "" || (projectType != "npm" && projectType != "yarn" && projectType != "pnpm") {
+			result.Error = "No package.json found"
+		} else {
+			result = runNpmAudit(dir, repoName, projectType)
+		}
+	case "trivy":
+		if projectType == "" {
+			result.Error = "No supported project files found"
+		} else {
+			result = runTrivyScan(dir, repoName)
+			result.ProjectType = projectType
+		}
+	case "owasp":
+		if projectType != "maven" {
+			result.Error = "No pom.xml found (OWASP requires Maven project)"
+		} else {
+			result = runOwaspScan(dir, repoName, req.Maven, owaspArgs)
+			result.ProjectType = projectType
+		}
+	case "govulncheck":
+		if projectType != "go" {
+			result.Error = "No go.mod found (govulncheck requires Go project)"
+		} else {
+			result = runGovulncheck(dir, repoName)
+			result.ProjectType = projectType
+		}
+	case "pip-audit":
+		if projectType != "python" {
+			result.Error = "No Python project found (requires requirements.txt or pyproject.toml)"
+		} else {
+			result = runPipAudit(dir, repoName)
+			result.ProjectType = projectType
+		}
+	case "composer-audit":
+		if projectType != "php" {
+			result.Error = "No PHP project found (requires composer.json)"
+		} else {
+			result = runComposerAudit(dir, repoName)
+			result.ProjectType = projectType
+		}
+	case "cargo-audit":
+		if projectType != "rust" {
+			result.Error = "No Cargo.toml found (cargo audit requires Rust project)"
+		} else {
+			result = runCargoAudit(dir, repoName)
+			result.ProjectType = projectType
+		}
+	case "dotnet":
+		if projectType != "dotnet" {
+			result.Error = "No .sln or .csproj found (dotnet list package requires .NET project)"
+		} else {
+			result = runDotnetAudit(dir, repoName)
+			result.ProjectType = projectType
+		}
+	case "bundler-audit":
+		if projectType != "ruby" {
+			result.Error = "No Gemfile found (bundler-audit requires Ruby project)"
+		} else {
+			result = runBundlerAudit(dir, repoName)
+			result.ProjectType = projectType
+		}
+	case "none":
+		result.ProjectType = "docker"
+		if len(logic.FindDockerfiles(dir)) == 0 {
+			result.ProjectType = "kubernetes"
+			if logic.FindKubernetes(dir).Empty() && logic.FindTerraform(dir) != nil {
+				result.ProjectType = "terraform"
+			}
+		}
+	default:
+		result.Error = "Unknown scanner type"
+	}
+	if fingerprint != "" && result.Error == "" {
+		if err := logic.SaveCachedScan(scanner, fingerprint, result); err != nil {
+			slog.Warn("Could not cache the scan result", "repo", repoName, "error", err)
+		}
+	}
+	return result
+}
+
+// runOwaspScan runs OWASP dependency-check on the Maven project; owaspArgs point it to the shared
+// NVD database (see prepareOwaspDatabase)
+func runOwaspScan(repoPath, repoName string, maven logic.MavenSettings, owaspArgs []string) RepoSecurityResult {
+	result := RepoSecurityResult{RepoName: repoName}
+
+	// Run OWASP dependency-check via Maven with JSON output
+	args := []string{
+		logic.OwaspPlugin + ":check",
+		"-DfailBuildOnCVSS=11", // Never fail build
+		"-Dformat=JSON",
+		"-DprettyPrint=true",
+		"-DskipTestScope=true",
+		"-q", // Quiet mode
+	}
+	cmd := maven.Command(repoPath, append(args, owaspArgs...)...)
+	cmdlimit.Run(cmd) // Ignore exit code, we'll parse the output file
+
+	// Find and parse the JSON report
+	reportPath := filepath.Join(repoPath, "target", "dependency-check-report.json")
+	reportData, err := os.ReadFile(reportPath)
+	if err != nil {
+		result.Error = "OWASP scan completed but no report found. Is the NVD database downloaded? See Settings → OWASP Dependency-Check."
+		return result
+	}
+
+	// Parse OWASP JSON output
+	var owaspResult struct {
+		Dependencies []struct {
+			FileName        string `json:"fileName"`
+			Vulnerabilities []struct {
+				Name        string `json:"name"`
+				Severity    string `json:"severity"`
+				Description string `json:"description"`
+			} `json:"vulnerabilities"`
+		} `json:"dependencies"`
+	}
+
+	if err := json.Unmarshal(reportData, &owaspResult); err != nil {
+		result.Error = fmt.Sprintf("Failed to parse OWASP report: %v", err)
+		return result
+	}
+
+	for _, dep := range owaspResult.Dependencies {
+		for _, v := range dep.Vulnerabilities {
+			severity := strings.ToUpper(v.Severity)
+			// OWASP uses different severity names
+			switch severity {
+			case "CRITICAL", "HIGH", "MEDIUM", "LOW":
+				// Keep as is
+			case "MODERATE":
+				severity = "MEDIUM"
+			default:
+				severity = "LOW"
+			}
+
+			result.Findings = append(result.Findings, CVEFinding{
+				CVE:         v.Name,
+				Severity:    severity,
+				Package:     dep.FileName,
+				Description: truncateString(v.Description, 200),
+			})
+		}
+	}
+
+	return result
+}
+
+// detectYarnVersion detects Yarn version from package.json packageManager field or yarn --version
+func detectYarnVersion(repoPath string) (version string, useCorepack bool) {
+	// First check package.json for packageManager field
+	pkgPath := filepath.Join(repoPath, "package.json")
+	if data, err := os.ReadFile(pkgPath); err == nil {
+		var pkg struct {
+			PackageManager string `json:"packageManager"`
+		}
+		if json.Unmarshal(data, &pkg) == nil && pkg.PackageManager != "" {
+			// Format: "yarn@4.0.2" or "yarn@4.0.2+sha256.xxx"
+			if strings.HasPrefix(pkg.PackageManager, "yarn@") {
+				parts := strings.Split(pkg.PackageManager, "@")
+				if len(parts) >= 2 {
+					ver := strings.Split(parts[1], "+")[0] // Remove hash
+					return ver, true                       // Use corepack for packageManager-managed yarn
+				}
+			}
+		}
+	}
+
+	// Fallback to global yarn version
+	versionCmd := exec.Command("yarn", "--version")
+	versionCmd.Dir = repoPath
+	if versionOutput, err := cmdlimit.Output(versionCmd); err == nil {
+		return strings.TrimSpace(string(versionOutput)), false
+	}
+
+	return "1.0.0", false // Default to classic
+}
+
+// runNpmAudit runs npm/yarn/pnpm audit for Node.js projects
+func runNpmAudit(repoPath, repoName, packageManager string) RepoSecurityResult {
+	result := RepoSecurityResult{RepoName: repoName, ProjectType: packageManager}
+
+	var cmd *exec.Cmd
+	var isYarnBerry bool
+	var useCorepack bool
+
+	switch packageManager {
+	case "yarn":
+		var yarnVersion string
+		yarnVersion, useCorepack = detectYarnVersion(repoPath)
+
+		// Determine if Yarn Berry (v2+)
+		isYarnBerry = !strings.HasPrefix(yarnVersion, "1.")
+
+		if isYarnBerry {
+			// Yarn Modern (v2+/Berry) - try text output first (more reliable than JSON which often fails)
+			if useCorepack {
+				cmd = exec.Command("corepack", "yarn", "npm", "audit")
+			} else {
+				cmd = exec.Command("yarn", "npm", "audit")
+			}
+		} else {
+			// Yarn Classic (v1) - use "yarn audit --json"
+			cmd = exec.Command("yarn", "audit", "--json")
+		}
+	case "pnpm":
+		// pnpm audit with JSON output
+		cmd = exec.Command("pnpm", "audit", "--json")
+	default:
+		// npm audit with JSON output
+		cmd = exec.Command("npm", "audit", "--json")
+	}
+	cmd.Dir = repoPath
+
+	// Use CombinedOutput because npm/yarn/pnpm may write to stderr
+	// and return non-zero exit code when vulnerabilities are found
+	output, err := runScanner(cmd, true)
+
+	// npm/yarn/pnpm audit returns non-zero exit code if vulnerabilities found
+	// but still outputs valid JSON, so we check if there's any output to parse
+	if len(output) == 0 {
+		if err != nil {
+			result.Error = fmt.Sprintf("%s audit failed: %v", packageManager, err)
+		} else {
+			result.Error = fmt.Sprintf("%s audit returned no output", packageManager)
+		}
+		return result
+	}
+
+	// Parse based on package manager
+	if packageManager == "yarn" {
+		if isYarnBerry {
+			// Parse text output for Yarn Berry (more reliable)
+			result = parseYarnBerryTextOutput(output, repoName)
+		} else {
+			result = parseYarnClassicAuditOutput(output, repoName)
+		}
+	} else if packageManager == "pnpm" {
+		result = parsePnpmAuditOutput(output, repoName)
+	} else {
+		result = parseNpmAuditOutput(output, repoName)
+	}
+	result.ProjectType = packageManager
+
+	return result
+}
+
+// parseNpmAuditOutput parses npm audit JSON output
+func parseNpmAuditOutput(output []byte, repoName string) RepoSecurityResult {
+	result := RepoSecurityResult{RepoName: repoName}
+
+	// npm audit JSON structure (v7+)
+	var npmResult struct {
+		Vulnerabilities map[string]struct {
+			Name         string        `json:"name"`
+			Severity     string        `json:"severity"`
+			Via          []interface{} `json:"via"`
+			Effects      []string      `json:"effects"`
+			Range        string        `json:"range"`
+			FixAvailable interface{}   `json:"fixAvailable"`
+		} `json:"vulnerabilities"`
+		Metadata struct {
+			Vulnerabilities struct {
+				Total    int `json:"total"`
+				Critical int `json:"critical"`
+				High     int `json:"high"`
+				Moderate int `json:"moderate"`
+				Low      int `json:"low"`
+			} `json:"vulnerabilities"`
+		} `json:"metadata"`
+	}
+
+	if err := json.Unmarshal(output, &npmResult); err != nil {
+		// Try older npm audit format
+		result = parseNpmAuditOutputLegacy(output, repoName)
+		return result
+	}
+
+	for pkgName, vuln := range npmResult.Vulnerabilities {
+		severity := normalizeSeverity(vuln.Severity)
+		cveID, description := extractCVEFromVia(vuln.Via, pkgName)
+		fixedIn := extractFixInfo(vuln.FixAvailable)
+
+		result.Findings = append(result.Findings, CVEFinding{
+			CVE:         cveID,
+			Severity:    severity,
+			Package:     pkgName,
+			Version:     vuln.Range,
+			FixedIn:     fixedIn,
+			Description: truncateString(description, 200),
+		})
+	}
+
+	return result
+}
+
+// parseNpmAuditOutputLegacy parses older npm audit JSON format
+func parseNpmAuditOutputLegacy(output []byte, repoName string) RepoSecurityResult {
+	result := RepoSecurityResult{RepoName: repoName}
+
+	var legacyResult struct {
+		Advisories map[string]struct {
+			ID                 int    `json:"id"`
+			ModuleName         string `json:"module_name"`
+			Severity           string `json:"severity"`
+			Title              string `json:"title"`
+			URL                string `json:"url"`
+			VulnerableVersions string `json:"vulnerable_versions"`
+			PatchedVersions    string `json:"patched_versions"`
+		} `json:"advisories"`
+	}
+
+	if err := json.Unmarshal(output, &legacyResult); err != nil {
+		result.Error = "Failed to parse npm audit output"
+		return result
+	}
+
+	for _, adv := range legacyResult.Advisories {
+		severity := normalizeSeverity(adv.Severity)
+
+		result.Findings = append(result.Findings, CVEFinding{
+			CVE:         fmt.Sprintf("npm:%d", adv.ID),
+			Severity:    severity,
+			Package:     adv.ModuleName,
+			Version:     adv.VulnerableVersions,
+			FixedIn:     adv.PatchedVersions,
+			Description: truncateString(adv.Title, 200),
+		})
+	}
+
+	return result
+}
+
+// parseYarnBerryTextOutput parses Yarn Berry (v2+/v4) "yarn npm audit" text output
+// This is more reliable than JSON output which often fails with HTTP 500 errors
+// Format example:
+// ├─ next
+// │  ├─ ID: 1105949
+// │  ├─ Issue: Next.js has a Cache poisoning vulnerability...
+// │  ├─ URL: https://github.com/advisories/GHSA-r2fc-ccr8-96c4
+// │  ├─ Severity: low
+// │  ├─ Vulnerable Versions: >=15.3.0 <15.3.3
+// │  │
+// │  ├─ Tree Versions
+// │  │  └─ 15.3.1
+func parseYarnBerryTextOutput(output []byte, repoName string) RepoSecurityResult {
+	result := RepoSecurityResult{RepoName: repoName}
+
+	lines := strings.Split(string(output), "\n")
+	var currentPackage string
+	var currentID string
+	var currentIssue string
+	var currentURL string
+	var currentSeverity string
+	var currentVulnVersions string
+	var currentTreeVersion string
+
+	for _, line := range lines {
+		// Remove tree drawing characters
+		cleanLine := strings.TrimSpace(line)
+		cleanLine = strings.TrimPrefix(cleanLine, "├─ ")
+		cleanLine = strings.TrimPrefix(cleanLine, "│  ├─ ")
+		cleanLine = strings.TrimPrefix(cleanLine, "│  │  └─ ")
+		cleanLine = strings.TrimPrefix(cleanLine, "│  └─ ")
+		cleanLine = strings.TrimPrefix(cleanLine, "└─ ")
+		cleanLine = strings.TrimSpace(cleanLine)
+
+		if cleanLine == "" || cleanLine == "│" {
+			continue
+		}
+
+		// Check for new package entry (package name line - not prefixed with known fields)
+		if !strings.Contains(cleanLine, ":") && !strings.HasPrefix(cleanLine, "Tree") && !strings.HasPrefix(cleanLine, "Dependents") && len(cleanLine) > 0 {
+			// Save previous entry if we have one
+			if currentPackage != "" && currentSeverity != "" {
+				cveID := currentID
+				if currentURL != "" && strings.Contains(currentURL, "GHSA-") {
+					parts := strings.Split(currentURL, "/")
+					for _, p := range parts {
+						if strings.HasPrefix(p, "GHSA-") {
+							cveID = p
+							break
+						}
+					}
+				}
+
+				result.Findings = append(result.Findings, CVEFinding{
+					CVE:         cveID,
+					Severity:    normalizeSeverity(currentSeverity),
+					Package:     currentPackage,
+					Version:     currentTreeVersion,
+					FixedIn:     currentVulnVersions,
+					Description: truncateString(currentIssue, 200),
+				})
+			}
+
+			// Start new package
+			currentPackage = cleanLine
+			currentID = ""
+			currentIssue = ""
+			currentURL = ""
+			currentSeverity = ""
+			currentVulnVersions = ""
+			currentTreeVersion = ""
+			continue
+		}
+
+		// Parse fields
+		if strings.HasPrefix(cleanLine, "ID: ") {
+			currentID = fmt.Sprintf("GHSA:%s", strings.TrimPrefix(cleanLine, "ID: "))
+		} else if strings.HasPrefix(cleanLine, "Issue: ") {
+			currentIssue = strings.TrimPrefix(cleanLine, "Issue: ")
+		} else if strings.HasPrefix(cleanLine, "URL: ") {
+			currentURL = strings.TrimPrefix(cleanLine, "URL: ")
+		} else if strings.HasPrefix(cleanLine, "Severity: ") {
+			currentSeverity = strings.TrimPrefix(cleanLine, "Severity: ")
+		} else if strings.HasPrefix(cleanLine, "Vulnerable Versions: ") {
+			currentVulnVersions = strings.TrimPrefix(cleanLine, "Vulnerable Versions: ")
+		} else if !strings.HasPrefix(cleanLine, "Tree") && !strings.HasPrefix(cleanLine, "Dependents") {
+			// This might be a version number under Tree Versions
+			if matched, _ := regexp.MatchString(`^\d+\.\d+`, cleanLine); matched {
+				currentTreeVersion = cleanLine
+			}
+		}
+	}
+
+	// Don't forget the last entry
+	if currentPackage != "" && currentSeverity != "" {
+		cveID := currentID
+		if currentURL != "" && strings.Contains(currentURL, "GHSA-") {
+			parts := strings.Split(currentURL, "/")
+			for _, p := range parts {
+				if strings.HasPrefix(p, "GHSA-") {
+					cveID = p
+					break
+				}
+			}
+		}
+
+		result.Findings = append(result.Findings, CVEFinding{
+			CVE:         cveID,
+			Severity:    normalizeSeverity(currentSeverity),
+			Package:     currentPackage,
+			Version:     currentTreeVersion,
+			FixedIn:     currentVulnVersions,
+			Description: truncateString(currentIssue, 200),
+		})
+	}
+
+	return result
+}
+
+// parseYarnBerryAuditOutput parses Yarn Berry (v2+/v4) "yarn npm audit --json" NDJSON output
+// Format: {"value":"pkg","children":{"ID":123,"Issue":"...","Severity":"critical","Vulnerable Versions":"...","Tree Versions":[...]}}
+func parseYarnBerryAuditOutput(output []byte, repoName string) RepoSecurityResult {
+	result := RepoSecurityResult{RepoName: repoName}
+
+	lines := strings.Split(string(output), "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		// Yarn Berry format
+		var entry struct {
+			Value    string `json:"value"`
+			Children struct {
+				ID                 int      `json:"ID"`
+				Issue              string   `json:"Issue"`
+				URL                string   `json:"URL"`
+				Severity           string   `json:"Severity"`
+				VulnerableVersions string   `json:"Vulnerable Versions"`
+				TreeVersions       []string `json:"Tree Versions"`
+				Dependents         []string `json:"Dependents"`
+			} `json:"children"`
+		}
+
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			continue
+		}
+
+		// Skip if no package name
+		if entry.Value == "" {
+			continue
+		}
+
+		severity := normalizeSeverity(entry.Children.Severity)
+
+		// Extract version from TreeVersions
+		version := ""
+		if len(entry.Children.TreeVersions) > 0 {
+			version = entry.Children.TreeVersions[0]
+		}
+
+		// Try to extract CVE from URL
+		cveID := fmt.Sprintf("GHSA:%d", entry.Children.ID)
+		if strings.Contains(entry.Children.URL, "GHSA-") {
+			parts := strings.Split(entry.Children.URL, "/")
+			for _, p := range parts {
+				if strings.HasPrefix(p, "GHSA-") {
+					cveID = p
+					break
+				}
+			}
+		}
+
+		result.Findings = append(result.Findings, CVEFinding{
+			CVE:         cveID,
+			Severity:    severity,
+			Package:     entry.Value,
+			Version:     version,
+			FixedIn:     entry.Children.VulnerableVersions, // Shows what's vulnerable, fix is upgrading out of range
+			Description: truncateString(entry.Children.Issue, 200),
+		})
+	}
+
+	return result
+}
+
+// parseYarnClassicAuditOutput parses Yarn Classic (v1) NDJSON format
+func parseYarnClassicAuditOutput(output []byte, repoName string) RepoSecurityResult {
+	result := RepoSecurityResult{RepoName: repoName}
+
+	lines := strings.Split(string(output), "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		var entry struct {
+			Type string `json:"type"`
+			Data struct {
+				Advisory struct {
+					ID                 int    `json:"id"`
+					ModuleName         string `json:"module_name"`
+					Severity           string `json:"severity"`
+					Title              string `json:"title"`
+					URL                string `json:"url"`
+					VulnerableVersions string `json:"vulnerable_versions"`
+					PatchedVersions    string `json:"patched_versions"`
+				} `json:"advisory"`
+			} `json:"data"`
+		}
+
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			continue
+		}
+
+		if entry.Type != "auditAdvisory" {
+			continue
+		}
+
+		adv := entry.Data.Advisory
+		severity := normalizeSeverity(adv.Severity)
+
+		result.Findings = append(result.Findings, CVEFinding{
+			CVE:         fmt.Sprintf("yarn:%d", adv.ID),
+			Severity:    severity,
+			Package:     adv.ModuleName,
+			Version:     adv.VulnerableVersions,
+			FixedIn:     adv.PatchedVersions,
+			Description: truncateString(adv.Title, 200),
+		})
+	}
+
+	return result
+}
+
+// parseYarnAuditOutput is a legacy wrapper - now splits into Berry and Classic
+func parseYarnAuditOutput(output []byte, repoName string) RepoSecurityResult {
+	result := RepoSecurityResult{RepoName: repoName}
+
+	outputStr := string(output)
+
+	// Try to detect format: Yarn Modern (v2+) outputs similar to npm
+	// Check if it starts with { (single JSON object) vs multiple lines (NDJSON)
+	trimmedOutput := strings.TrimSpace(outputStr)
+
+	// Yarn Modern (Berry) format - similar to npm audit
+	if strings.HasPrefix(trimmedOutput, "{") && !strings.Contains(trimmedOutput, "\n{") {
+		// Try npm-like format first (Yarn Modern)
+		var yarnModernResult struct {
+			Advisories map[string]struct {
+				ID                 int    `json:"id"`
+				ModuleName         string `json:"module_name"`
+				Severity           string `json:"severity"`
+				Title              string `json:"title"`
+				URL                string `json:"url"`
+				VulnerableVersions string `json:"vulnerable_versions"`
+				PatchedVersions    string `json:"patched_versions"`
+			} `json:"advisories"`
+			// Alternative structure for yarn npm audit
+			Vulnerabilities map[string]struct {
+				Name         string        `json:"name"`
+				Severity     string        `json:"severity"`
+				Via          []interface{} `json:"via"`
+				Range        string        `json:"range"`
+				FixAvailable interface{}   `json:"fixAvailable"`
+			} `json:"vulnerabilities"`
+		}
+
+		if err := json.Unmarshal(output, &yarnModernResult); err == nil {
+			// Check if we have vulnerabilities (npm v7+ format)
+			if len(yarnModernResult.Vulnerabilities) > 0 {
+				for pkgName, vuln := range yarnModernResult.Vulnerabilities {
+					severity := normalizeSeverity(vuln.Severity)
+					cveID, description := extractCVEFromVia(vuln.Via, pkgName)
+					fixedIn := extractFixInfo(vuln.FixAvailable)
+
+					result.Findings = append(result.Findings, CVEFinding{
+						CVE:         cveID,
+						Severity:    severity,
+						Package:     pkgName,
+						Version:     vuln.Range,
+						FixedIn:     fixedIn,
+						Description: truncateString(description, 200),
+					})
+				}
+				return result
+			}
+
+			// Check if we have advisories (older format)
+			if len(yarnModernResult.Advisories) > 0 {
+				for _, adv := range yarnModernResult.Advisories {
+					severity := normalizeSeverity(adv.Severity)
+					result.Findings = append(result.Findings, CVEFinding{
+						CVE:         fmt.Sprintf("yarn:%d", adv.ID),
+						Severity:    severity,
+						Package:     adv.ModuleName,
+						Version:     adv.VulnerableVersions,
+						FixedIn:     adv.PatchedVersions,
+						Description: truncateString(adv.Title, 200),
+					})
+				}
+				return result
+			}
+		}
+	}
+
+	// Yarn Classic (v1) NDJSON format - each line is a separate JSON object
+	lines := strings.Split(outputStr, "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		var entry struct {
+			Type string `json:"type"`
+			Data struct {
+				Advisory struct {
+					ID                 int    `json:"id"`
+					ModuleName         string `json:"module_name"`
+					Severity           string `json:"severity"`
+					Title              string `json:"title"`
+					URL                string `json:"url"`
+					VulnerableVersions string `json:"vulnerable_versions"`
+					PatchedVersions    string `json:"patched_versions"`
+				} `json:"advisory"`
+			} `json:"data"`
+		}
+
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			continue
+		}
+
+		if entry.Type != "auditAdvisory" {
+			continue
+		}
+
+		adv := entry.Data.Advisory
+		severity := normalizeSeverity(adv.Severity)
+
+		result.Findings = append(result.Findings, CVEFinding{
+			CVE:         fmt.Sprintf("yarn:%d", adv.ID),
+			Severity:    severity,
+			Package:     adv.ModuleName,
+			Version:     adv.VulnerableVersions,
+			FixedIn:     adv.PatchedVersions,
+			Description: truncateString(adv.Title, 200),
+		})
+	}
+
+	return result
+}
+
+// normalizeSeverity converts various severity names to standard format
+func normalizeSeverity(severity string) string {
+	switch strings.ToUpper(severity) {
+	case "CRITICAL", "HIGH", "MEDIUM", "LOW":
+		return strings.ToUpper(severity)
+	case "MODERATE":
+		return "MEDIUM"
+	default:
+		return "LOW"
+	}
+}
+
+// extractCVEFromVia extracts CVE ID and description from npm's via field
+func extractCVEFromVia(via []interface{}, pkgName string) (string, string) {
+	cveID := ""
+	description := ""
+
+	for _, v := range via {
+		if viaMap, ok := v.(map[string]interface{}); ok {
+			if source, exists := viaMap["source"]; exists {
+				if sourceNum, ok := source.(float64); ok {
+					cveID = fmt.Sprintf("GHSA-%d", int(sourceNum))
+				}
+			}
+			if url, exists := viaMap["url"]; exists {
+				if urlStr, ok := url.(string); ok {
+					// Extract CVE or GHSA from URL
+					if strings.Contains(urlStr, "CVE-") {
+						parts := strings.Split(urlStr, "/")
+						for _, p := range parts {
+							if strings.HasPrefix(p, "CVE-") {
+								cveID = p
+								break
+							}
+						}
+					} else if strings.Contains(urlStr, "GHSA-") {
+						parts := strings.Split(urlStr, "/")
+						for _, p := range parts {
+							if strings.HasPrefix(p, "GHSA-") {
+								cveID = p
+								break
+							}
+						}
+					}
+				}
+			}
+			if title, exists := viaMap["title"]; exists {
+				if titleStr, ok := title.(string); ok {
+					description = titleStr
+				}
+			}
+		}
+	}
+
+	if cveID == "" {
+		cveID = fmt.Sprintf("npm:%s", pkgName)
+	}
+
+	return cveID, description
+}
+
+// extractFixInfo extracts fix information from FixAvailable field
+func extractFixInfo(fixAvailable interface{}) string {
+	if fix, ok := fixAvailable.(map[string]interface{}); ok {
+		if version, exists := fix["version"]; exists {
+			return fmt.Sprintf("%v", version)
+		}
+	} else if fixAvailable == true {
+		return "Update available"
+	}
+	return ""
+}
+
+// parsePnpmAuditOutput parses pnpm audit JSON output
+func parsePnpmAuditOutput(output []byte, repoName string) RepoSecurityResult {
+	result := RepoSecurityResult{RepoName: repoName}
+
+	// pnpm audit JSON structure (similar to npm)
+	var pnpmResult struct {
+		Advisories map[string]struct {
+			ID                 int    `json:"id"`
+			ModuleName         string `json:"module_name"`
+			Severity           string `json:"severity"`
+			Title              string `json:"title"`
+			URL                string `json:"url"`
+			VulnerableVersions string `json:"vulnerable_versions"`
+			PatchedVersions    string `json:"patched_versions"`
+		} `json:"advisories"`
+	}
+
+	if err := json.Unmarshal(output, &pnpmResult); err != nil {
+		result.Error = "Failed to parse pnpm audit output"
+		return result
+	}
+
+	for _, adv := range pnpmResult.Advisories {
+		severity := normalizeSeverity(adv.Severity)
+
+		result.Findings = append(result.Findings, CVEFinding{
+			CVE:         fmt.Sprintf("pnpm:%d", adv.ID),
+			Severity:    severity,
+			Package:     adv.ModuleName,
+			Version:     adv.VulnerableVersions,
+			FixedIn:     adv.PatchedVersions,
+			Description: truncateString(adv.Title, 200),
+		})
+	}
+
+	return result
+}
+
+func truncateString(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	return s[:maxLen-3] + "..."
+}
+
+// runGovulncheck runs govulncheck for Go projects
+func runGovulncheck(repoPath, repoName string) RepoSecurityResult {
+	result := RepoSecurityResult{RepoName: repoName, ProjectType: "go"}
+
+	// Check if govulncheck is available
+	if !checkGovulncheckAvailable() {
+		result.Error = "govulncheck not installed. Install with: go install golang.org/x/vuln/cmd/govulncheck@latest"
+		return result
+	}
+
+	// Run govulncheck with JSON output
+	cmd := exec.Command("govulncheck", "-json", "./...")
+	cmd.Dir = repoPath
+	output, err := runScanner(cmd, false)
+
+	// govulncheck returns exit code 3 if vulnerabilities found
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			if exitErr.ExitCode() != 3 && len(output) == 0 {
+				result.Error = fmt.Sprintf("govulncheck failed: %v", err)
+				return result
+			}
+		} else if len(output) == 0 {
+			result.Error = fmt.Sprintf("govulncheck failed: %v", err)
+			return result
+		}
+	}
+
+	// Parse JSON output (NDJSON format - one JSON object per line)
+	lines := strings.Split(string(output), "\n")
+	vulnMap := make(map[string]CVEFinding) // Deduplicate by CVE
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		var entry struct {
+			Finding *struct {
+				OSV   string `json:"osv"`
+				Trace []struct {
+					Module  string `json:"module"`
+					Version string `json:"version"`
+					Package string `json:"package"`
+				} `json:"trace"`
+			} `json:"finding"`
+			OSV *struct {
+				ID       string `json:"id"`
+				Summary  string `json:"summary"`
+				Severity []struct {
+					Type  string `json:"type"`
+					Score string `json:"score"`
+				} `json:"severity"`
+				Affected []struct {
+					Package struct {
+						Name string `json:"name"`
+					} `json:"package"`
+					Ranges []struct {
+						Events []struct {
+							Fixed string `json:"fixed"`
+						} `json:"events"`
+					} `json:"ranges"`
+				} `json:"affected"`
+			} `json:"osv"`
+		}
+
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			continue
+		}
+
+		// Process OSV entries (vulnerability details)
+		if entry.OSV != nil {
+			osv := entry.OSV
+			severity := "MEDIUM" // Default
+
+			// Parse CVSS score if available (OSV stores the vector, not the number)
+			cvssScore := 0.0
+			for _, sev := range osv.Severity {
+				if sev.Type == "CVSS_V3" {
+					if score, err := logic.CVSS3BaseScore(sev.Score); err == nil {
+						cvssScore = score
+						severity = logic.SeverityFromCVSS(score)
+					}
+				}
+			}
+
+			// Get package name and fixed version
+			pkgName := ""
+			fixedIn := ""
+			if len(osv.Affected) > 0 {
+				pkgName = osv.Affected[0].Package.Name
+				for _, r := range osv.Affected[0].Ranges {
+					for _, ev := range r.Events {
+						if ev.Fixed != "" {
+							fixedIn = ev.Fixed
+						}
+					}
+				}
+			}
+
+			vulnMap[osv.ID] = CVEFinding{
+				CVE:         osv.ID,
+				Severity:    severity,
+				CVSSScore:   cvssScore,
+				Package:     pkgName,
+				FixedIn:     fixedIn,
+				Description: truncateString(osv.Summary, 200),
+			}
+		}
+
+		// Process finding entries (actual usage in code)
+		if entry.Finding != nil && len(entry.Finding.Trace) > 0 {
+			trace := entry.Finding.Trace[0]
+			if existing, ok := vulnMap[entry.Finding.OSV]; ok {
+				existing.Version = trace.Version
+				if existing.Package == "" {
+					existing.Package = trace.Module
+				}
+				vulnMap[entry.Finding.OSV] = existing
+			}
+		}
+	}
+
+	// Convert map to slice
+	for _, finding := range vulnMap {
+		result.Findings = append(result.Findings, finding)
+	}
+
+	// Entries without an embedded CVSS vector get the rating from OSV.dev
+	result.Findings = enrichFindingsWithOSV(result.Findings)
+
+	return result
+}
+
+// runPipAudit runs pip-audit for Python projects
+func runPipAudit(repoPath, repoName string) RepoSecurityResult {
+	result := RepoSecurityResult{RepoName: repoName, ProjectType: "python"}
+
+	// Check if pip-audit is available
+	if !checkPipAuditAvailable() {
+		result.Error = "pip-audit not installed. Install with: pip install pip-audit"
+		return result
+	}
+
+	// Determine which requirements file to use
+	var requirementsArg string
+	if _, err := os.Stat(filepath.Join(repoPath, "requirements.txt")); err == nil {
+		requirementsArg = "-r requirements.txt"
+	} else if _, err := os.Stat(filepath.Join(repoPath, "pyproject.toml")); err == nil {
+		// pip-audit can handle pyproject.toml directly in the directory
+		requirementsArg = ""
+	} else {
+		result.Error = "No requirements.txt or pyproject.toml found"
+		return result
+	}
+
+	// Run pip-audit with JSON output
+	var cmd *exec.Cmd
+	if requirementsArg != "" {
+		cmd = exec.Command("pip-audit", "-r", "requirements.txt", "--format", "json", "--progress-spinner=off")
+	} else {
+		cmd = exec.Command("pip-audit", "--format", "json", "--progress-spinner=off")
+	}
+	cmd.Dir = repoPath
+	output, err := runScanner(cmd, false)
+
+	// pip-audit returns exit code 1 if vulnerabilities found
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			if exitErr.ExitCode() != 1 && len(output) == 0 {
+				// Check stderr for more info
+				if len(exitErr.Stderr) > 0 {
+					result.Error = fmt.Sprintf("pip-audit failed: %s", string(exitErr.Stderr))
+				} else {
+					result.Error = fmt.Sprintf("pip-audit failed: %v", err)
+				}
+				return result
+			}
+		} else if len(output) == 0 {
+			result.Error = fmt.Sprintf("pip-audit failed: %v", err)
+			return result
+		}
+	}
+
+	// Parse pip-audit JSON output
+	var pipResult struct {
+		Dependencies []struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+			Vulns   []struct {
+				ID          string   `json:"id"`
+				FixVersions []string `json:"fix_versions"`
+				Description string   `json:"description"`
+			} `json:"vulns"`
+		} `json:"dependencies"`
+	}
+
+	// pip-audit outputs an array directly
+	var vulnArray []struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		Vulns   []struct {
+			ID          string   `json:"id"`
+			FixVersions []string `json:"fix_versions"`
+			Description string   `json:"description"`
+		} `json:"vulns"`
+	}
+
+	// Try parsing as array first (newer pip-audit format)
+	if err := json.Unmarshal(output, &vulnArray); err == nil {
+		for _, dep := range vulnArray {
+			for _, vuln := range dep.Vulns {
+				severity := determinePythonSeverity(vuln.ID)
+				fixedIn := ""
+				if len(vuln.FixVersions) > 0 {
+					fixedIn = vuln.FixVersions[len(vuln.FixVersions)-1] // Latest fix version
+				}
+
+				result.Findings = append(result.Findings, CVEFinding{
+					CVE:         vuln.ID,
+					Severity:    severity,
+					Package:     dep.Name,
+					Version:     dep.Version,
+					FixedIn:     fixedIn,
+					Description: truncateString(vuln.Description, 200),
+				})
+			}
+		}
+		result.Findings = enrichFindingsWithOSV(result.Findings)
+		return result
+	}
+
+	// Try parsing as object (older format)
+	if err := json.Unmarshal(output, &pipResult); err != nil {
+		result.Error = fmt.Sprintf("Failed to parse pip-audit output: %v", err)
+		return result
+	}
+
+	for _, dep := range pipResult.Dependencies {
+		for _, vuln := range dep.Vulns {
+			severity := determinePythonSeverity(vuln.ID)
+			fixedIn := ""
+			if len(vuln.FixVersions) > 0 {
+				fixedIn = vuln.FixVersions[len(vuln.FixVersions)-1]
+			}
+
+			result.Findings = append(result.Findings, CVEFinding{
+				CVE:         vuln.ID,
+				Severity:    severity,
+				Package:     dep.Name,
+				Version:     dep.Version,
+				FixedIn:     fixedIn,
+				Description: truncateString(vuln.Description, 200),
+			})
+		}
+	}
+
+	result.Findings = enrichFindingsWithOSV(result.Findings)
+	return result
+}
+
+// determinePythonSeverity determines severity from CVE/PYSEC ID.
+// pip-audit doesn't provide severity, this is only the fallback if the OSV lookup fails.
+func determinePythonSeverity(id string) string {
+	if strings.HasPrefix(id, "GHSA-") {
+		// GitHub Security Advisories are usually at least MEDIUM
+		return "MEDIUM"
+	}
+	if strings.HasPrefix(id, "CVE-") || strings.HasPrefix(id, "PYSEC-") {
+		return "MEDIUM"
+	}
+	return "LOW"
+}
+
+// osvLookupPrefixes are the advisory IDs OSV.dev can resolve
+var osvLookupPrefixes = []string{"PYSEC-", "GHSA-", "GO-", "RUSTSEC-", "CVE-"}
+
+// enrichFindingsWithOSV replaces guessed severities with the CVSS rating from OSV.dev and fills
+// missing fix versions. Findings that already carry a CVSS score are left alone; if OSV is not
+// reachable the scanner's own values are kept.
+func enrichFindingsWithOSV(findings []CVEFinding) []CVEFinding {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 4)
+
+	for i := range findings {
+		if findings[i].CVSSScore > 0 {
+			continue
+		}
+		lookupID := ""
+		for _, prefix := range osvLookupPrefixes {
+			if strings.HasPrefix(findings[i].CVE, prefix) {
+				lookupID = findings[i].CVE
+				break
+			}
+		}
+		if lookupID == "" {
+			continue
+		}
+
+		wg.Add(1)
+		go func(f *CVEFinding, id string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			vuln, err := logic.LookupOSV(id)
+			if err != nil {
+				return
+			}
+			if vuln.Severity != "" {
+				f.Severity = vuln.Severity
+				f.CVSSScore = vuln.CVSSScore
+			}
+			if f.FixedIn == "" {
+				f.FixedIn = vuln.FixVersionFor(f.Package, f.Version)
+			}
+			if f.Description == "" {
+				f.Description = truncateString(vuln.Summary, 200)
+			}
+		}(&findings[i], lookupID)
+	}
+	wg.Wait()
+	return findings
+}
+
+// readComposerLockVersions returns the installed package versions from composer.lock
+func readComposerLockVersions(repoPath string) map[string]string {
+	versions := make(map[string]string)
+	data, err := os.ReadFile(filepath.Join(repoPath, "composer.lock"))
+	if err != nil {
+		return versions
+	}
+	var lock struct {
+		Packages []struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"packages"`
+		PackagesDev []struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"packages-dev"`
+	}
+	if json.Unmarshal(data, &lock) != nil {
+		return versions
+	}
+	for _, p := range append(lock.Packages, lock.PackagesDev...) {
+		versions[p.Name] = strings.TrimPrefix(p.Version, "v")
+	}
+	return versions
+}
+
+// runComposerAudit runs composer audit for PHP projects
+func runComposerAudit(repoPath, repoName string) RepoSecurityResult {
+	result := RepoSecurityResult{RepoName: repoName, ProjectType: "php"}
+
+	// Check if composer is available
+	if !checkComposerAvailable() {
+		result.Error = "Composer not installed. Install from: https://getcomposer.org/download/"
+		return result
+	}
+
+	// Check if composer.json exists
+	composerPath := filepath.Join(repoPath, "composer.json")
+	if _, err := os.Stat(composerPath); os.IsNotExist(err) {
+		result.Error = "No composer.json found"
+		return result
+	}
+
+	// Run composer audit with JSON output
+	cmd := exec.Command("composer", "audit", "--format=json")
+	cmd.Dir = repoPath
+	output, err := runScanner(cmd, false)
+
+	// composer audit returns exit code 1 if vulnerabilities found
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			if exitErr.ExitCode() != 1 && len(output) == 0 {
+				// Check stderr for more info
+				if len(exitErr.Stderr) > 0 {
+					result.Error = fmt.Sprintf("composer audit failed: %s", string(exitErr.Stderr))
+				} else {
+					result.Error = fmt.Sprintf("composer audit failed: %v", err)
+				}
+				return result
+			}
+		} else if len(output) == 0 {
+			result.Error = fmt.Sprintf("composer audit failed: %v", err)
+			return result
+		}
+	}
+
+	// Parse composer audit JSON output
+	var auditResult struct {
+		Advisories map[string][]struct {
+			AdvisoryID       string `json:"advisoryId"`
+			PackageName      string `json:"packageName"`
+			AffectedVersions string `json:"affectedVersions"`
+			Title            string `json:"title"`
+			CVE              string `json:"cve"`
+			Link             string `json:"link"`
+			ReportedAt       string `json:"reportedAt"`
+			Severity         string `json:"severity"`
+		} `json:"advisories"`
+	}
+
+	if err := json.Unmarshal(output, &auditResult); err != nil {
+		// Check if it's empty (no vulnerabilities)
+		if len(output) == 0 || string(output) == "{}" || string(output) == "{\"advisories\":[]}" {
+			return result // No vulnerabilities
+		}
+		result.Error = fmt.Sprintf("Failed to parse composer audit output: %v", err)
+		return result
+	}
+
+	// Process advisories
+	installed := readComposerLockVersions(repoPath)
+	for packageName, advisories := range auditResult.Advisories {
+		for _, advisory := range advisories {
+			cveID := advisory.CVE
+			if cveID == "" {
+				cveID = advisory.AdvisoryID
+			}
+
+			severity := strings.ToUpper(advisory.Severity)
+			if severity == "" {
+				severity = "MEDIUM"
+			}
+
+			// Prefer the installed version, the affected range is only the fallback
+			version := installed[packageName]
+			if version == "" {
+				version = advisory.AffectedVersions
+			}
+
+			result.Findings = append(result.Findings, CVEFinding{
+				CVE:         cveID,
+				Severity:    severity,
+				Package:     packageName,
+				Version:     version,
+				Description: truncateString(advisory.Title, 200),
+			})
+		}
+	}
+
+	result.Findings = enrichFindingsWithOSV(result.Findings)
+	return result
+}
+
+// runCargoAudit runs cargo audit against the RustSec advisory database for Rust projects
+func runCargoAudit(repoPath, repoName string) RepoSecurityResult {
+	result := RepoSecurityResult{RepoName: repoName, ProjectType: "rust"}
+
+	// Check if cargo audit is available
+	if !checkCargoAuditAvailable() {
+		result.Error = "cargo audit not installed. Install with: cargo install cargo-audit --locked"
+		return result
+	}
+
+	// Run cargo audit with JSON output; without Cargo.lock it generates one first
+	args := []string{"audit", "--json"}
+	if network, err := logic.LoadNetworkSettings(); err == nil && network.Offline {
+		// Audit against the advisory database fetched before
+		args = append(args, "--no-fetch")
+	}
+	cmd := exec.Command("cargo", args...)
+	cmd.Dir = repoPath
+	output, err := runScanner(cmd, false)
+
+	// cargo audit returns exit code 1 if vulnerabilities found
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			if exitErr.ExitCode() != 1 || len(output) == 0 {
+				if len(exitErr.Stderr) > 0 {
+					result.Error = fmt.Sprintf("cargo audit failed: %s", strings.TrimSpace(string(exitErr.Stderr)))
+				} else {
+					result.Error = fmt.Sprintf("cargo audit failed: %v", err)
+				}
+				return result
+			}
+		} else if len(output) == 0 {
+			result.Error = fmt.Sprintf("cargo audit failed: %v", err)
+			return result
+		}
+	}
+
+	findings, err := parseCargoAuditOutput(output)
+	if err != nil {
+		result.Error = fmt.Sprintf("Failed to parse cargo audit output: %v", err)
+		return result
+	}
+	result.Findings = enrichFindingsWithOSV(findings)
+	return result
+}
+
+// parseCargoAuditOutput converts the vulnerabilities of cargo audit --json into findings, named by
+// their CVE alias if the RustSec advisory has one. Unmaintained and yanked crate warnings are no
+// vulnerabilities and skipped.
+func parseCargoAuditOutput(output []byte) ([]CVEFinding, error) {
+	var report struct {
+		Vulnerabilities struct {
+			List []struct {
+				Advisory struct {
+					ID      string   `json:"id"`
+					Title   string   `json:"title"`
+					Aliases []string `json:"aliases"`
+					CVSS    string   `json:"cvss"`
+				} `json:"advisory"`
+				Versions struct {
+					Patched []string `json:"patched"`
+				} `json:"versions"`
+				Package struct {
+					Name    string `json:"name"`
+					Version string `json:"version"`
+				} `json:"package"`
+			} `json:"list"`
+		} `json:"vulnerabilities"`
+	}
+	if err := json.Unmarshal(output, &report); err != nil {
+		return nil, err
+	}
+
+	var findings []CVEFinding
+	for _, vuln := range report.Vulnerabilities.List {
+		id := vuln.Advisory.ID
+		for _, alias := range vuln.Advisory.Aliases {
+			if strings.HasPrefix(alias, "CVE-") {
+				id = alias
+				break
+			}
+		}
+
+		// Advisories without a CVSS vector get the rating from OSV.dev
+		severity := "MEDIUM"
+		cvssScore := 0.0
+		if score, err := logic.CVSS3BaseScore(vuln.Advisory.CVSS); err == nil {
+			cvssScore = score
+			severity = logic.SeverityFromCVSS(score)
+		}
+
+		// Patched ranges look like ">=0.2.23" or "^0.1.45"
+		fixedIn := ""
+		if len(vuln.Versions.Patched) > 0 {
+			fixedIn = strings.TrimLeft(vuln.Versions.Patched[0], ">=^~ ")
+		}
+
+		findings = append(findings, CVEFinding{
+			CVE:         id,
+			Severity:    severity,
+			CVSSScore:   cvssScore,
+			Package:     vuln.Package.Name,
+			Version:     vuln.Package.Version,
+			FixedIn:     fixedIn,
+			Description: truncateString(vuln.Advisory.Title, 200),
+		})
+	}
+	return findings, nil
+}
+
+// runDotnetAudit lists the vulnerable NuGet packages of .NET projects, direct and transitive, with
+// dotnet list package. The packages are restored first, the listing reads the restore output.
+func runDotnetAudit(repoPath, repoName string) RepoSecurityResult {
+	result := RepoSecurityResult{RepoName: repoName, ProjectType: "dotnet"}
+
+	// Check if dotnet is available
+	if !checkDotnetAvailable() {
+		result.Error = ".NET SDK not installed. Install from: https://dotnet.microsoft.com/download"
+		return result
+	}
+
+	// A directory with several solutions or projects needs the one to list
+	target := logic.DotnetProjectFile(repoPath)
+	if target == "" {
+		result.Error = "No .sln or .csproj found"
+		return result
+	}
+
+	restore := exec.Command("dotnet", "restore", target)
+	restore.Dir = repoPath
+	if output, err := cmdlimit.CombinedOutput(restore); err != nil {
+		result.Error = fmt.Sprintf("dotnet restore failed: %v\n%s", err, truncateString(string(output), 500))
+		return result
+	}
+
+	// Requires .NET SDK 7.0.200+ for the JSON format
+	cmd := exec.Command("dotnet", "list", target, "package", "--vulnerable", "--include-transitive", "--format", "json")
+	cmd.Dir = repoPath
+	output, err := runScanner(cmd, false)
+	if err != nil && len(output) == 0 {
+		result.Error = fmt.Sprintf("dotnet list package failed: %v", err)
+		return result
+	}
+
+	findings, err := parseDotnetVulnerableOutput(output)
+	if err != nil {
+		result.Error = fmt.Sprintf("Failed to parse dotnet list package output: %v", err)
+		return result
+	}
+	result.Findings = enrichFindingsWithOSV(findings)
+	return result
+}
+
+// parseDotnetVulnerableOutput converts the vulnerable packages of dotnet list package --vulnerable
+// --format json into findings. The advisories are GitHub advisories, named by the GHSA ID of their
+// URL; a package in several projects or target frameworks is reported once.
+func parseDotnetVulnerableOutput(output []byte) ([]CVEFinding, error) {
+	type dotnetPackage struct {
+		ID              string `json:"id"`
+		ResolvedVersion string `json:"resolvedVersion"`
+		Vulnerabilities []struct {
+			Severity    string `json:"severity"`
+			AdvisoryURL string `json:"advisoryurl"`
+		} `json:"vulnerabilities"`
+	}
+	var report struct {
+		Problems []struct {
+			Text  string `json:"text"`
+			Level string `json:"level"`
+		} `json:"problems"`
+		Projects []struct {
+			Path       string `json:"path"`
+			Frameworks []struct {
+				TopLevelPackages   []dotnetPackage `json:"topLevelPackages"`
+				TransitivePackages []dotnetPackage `json:"transitivePackages"`
+			} `json:"frameworks"`
+		} `json:"projects"`
+	}
+	if err := json.Unmarshal(output, &report); err != nil {
+		return nil, err
+	}
+	if len(report.Projects) == 0 {
+		for _, problem := range report.Problems {
+			if strings.EqualFold(problem.Level, "error") {
+				return nil, fmt.Errorf("%s", problem.Text)
+			}
+		}
+	}
+
+	var findings []CVEFinding
+	seen := make(map[string]bool)
+	for _, project := range report.Projects {
+		for _, framework := range project.Frameworks {
+			for _, pkg := range append(framework.TopLevelPackages, framework.TransitivePackages...) {
+				for _, vuln := range pkg.Vulnerabilities {
+					id := vuln.AdvisoryURL[strings.LastIndex(vuln.AdvisoryURL, "/")+1:]
+					key := id + "|" + pkg.ID + "|" + pkg.ResolvedVersion
+					if seen[key] {
+						continue
+					}
+					seen[key] = true
+
+					// NuGet rates Critical, High, Moderate and Low
+					severity := strings.ToUpper(vuln.Severity)
+					if severity == "MODERATE" || severity == "" {
+						severity = "MEDIUM"
+					}
+
+					findings = append(findings, CVEFinding{
+						CVE:         id,
+						Severity:    severity,
+						Package:     pkg.ID,
+						Version:     pkg.ResolvedVersion,
+						Description: vuln.AdvisoryURL,
+					})
+				}
+			}
+		}
+	}
+	return findings, nil
+}
+
+// runBundlerAudit runs bundler-audit against the ruby-advisory-db for Ruby projects
+func runBundlerAudit(repoPath, repoName string) RepoSecurityResult {
+	result := RepoSecurityResult{RepoName: repoName, ProjectType: "ruby"}
+
+	// Check if bundler-audit is available
+	if !checkBundlerAuditAvailable() {
+		result.Error = "bundler-audit not installed. Install with: gem install bundler-audit"
+		return result
+	}
+
+	// bundler-audit checks the resolved versions, it does not resolve the Gemfile itself
+	if _, err := os.Stat(filepath.Join(repoPath, "Gemfile.lock")); os.IsNotExist(err) {
+		result.Error = "No Gemfile.lock found (run bundle lock)"
+		return result
+	}
+
+	// Run bundler-audit with JSON output (0.9+), updating the advisory database unless offline
+	args := []string{"check", "--format", "json"}
+	if network, err := logic.LoadNetworkSettings(); err != nil || !network.Offline {
+		args = append(args, "--update")
+	}
+	cmd := exec.Command("bundle-audit", args...)
+	cmd.Dir = repoPath
+	output, err := runScanner(cmd, false)
+
+	// bundler-audit returns exit code 1 if vulnerabilities found
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			if exitErr.ExitCode() != 1 || len(output) == 0 {
+				if len(exitErr.Stderr) > 0 {
+					result.Error = fmt.Sprintf("bundler-audit failed: %s", strings.TrimSpace(string(exitErr.Stderr)))
+				} else {
+					result.Error = fmt.Sprintf("bundler-audit failed: %v", err)
+				}
+				return result
+			}
+		} else if len(output) == 0 {
+			result.Error = fmt.Sprintf("bundler-audit failed: %v", err)
+			return result
+		}
+	}
+
+	findings, err := parseBundlerAuditOutput(output)
+	if err != nil {
+		result.Error = fmt.Sprintf("Failed to parse bundler-audit output: %v", err)
+		return result
+	}
+	result.Findings = enrichFindingsWithOSV(findings)
+	return result
+}
+
+// parseBundlerAuditOutput converts the unpatched gems of bundler-audit --format json into findings,
+// named by their CVE if the advisory has one. Insecure gem sources are no vulnerabilities and skipped.
+func parseBundlerAuditOutput(output []byte) ([]CVEFinding, error) {
+	// Some versions print the "Updating ruby-advisory-db" progress before the report
+	if start := bytes.IndexByte(output, '{'); start > 0 {
+		output = output[start:]
+	}
+	var report struct {
+		Results []struct {
+			Type string `json:"type"`
+			Gem  struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"gem"`
+			Advisory struct {
+				ID              string   `json:"id"`
+				Title           string   `json:"title"`
+				CVE             string   `json:"cve"`
+				CVSSv3          float64  `json:"cvss_v3"`
+				CVSSv2          float64  `json:"cvss_v2"`
+				Criticality     string   `json:"criticality"`
+				PatchedVersions []string `json:"patched_versions"`
+			} `json:"advisory"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(output, &report); err != nil {
+		return nil, err
+	}
+
+	var findings []CVEFinding
+	for _, r := range report.Results {
+		if r.Type != "unpatched_gem" {
+			continue
+		}
+		advisory := r.Advisory
+		id := advisory.ID
+		if advisory.CVE != "" {
+			id = "CVE-" + advisory.CVE
+		}
+
+		// Advisories without a score or criticality get the rating from OSV.dev
+		severity := "MEDIUM"
+		cvssScore := advisory.CVSSv3
+		if cvssScore == 0 {
+			cvssScore = advisory.CVSSv2
+		}
+		if cvssScore > 0 {
+			severity = logic.SeverityFromCVSS(cvssScore)
+		} else if c := strings.ToUpper(advisory.Criticality); c == "CRITICAL" || c == "HIGH" || c == "MEDIUM" || c == "LOW" {
+			severity = c
+		}
+
+		// Patched requirements look like "~> 6.1.7, >= 6.1.7.1" or ">= 7.0.4.1", one per release line;
+		// the lower bound of the line of the installed major version is the fix
+		fixedIn := ""
+		for _, requirement := range advisory.PatchedVersions {
+			parts := strings.Split(requirement, ",")
+			version := strings.TrimLeft(strings.TrimSpace(parts[len(parts)-1]), "~>= ")
+			if fixedIn == "" || strings.SplitN(version, ".", 2)[0] == strings.SplitN(r.Gem.Version, ".", 2)[0] {
+				fixedIn = version
+			}
+		}
+
+		findings = append(findings, CVEFinding{
+			CVE:         id,
+			Severity:    severity,
+			CVSSScore:   cvssScore,
+			Package:     r.Gem.Name,
+			Version:     r.Gem.Version,
+			FixedIn:     fixedIn,
+			Description: truncateString(advisory.Title, 200),
+		})
+	}
+	return findings, nil
+}
+
+// ==================== GITHUB INTEGRATION ====================
+
+type GitHubReposRequest struct {
+	Org      string               `json:"org"`
+	Token    string               `json:"token"`
+	RootPath string               `json:"rootPath"`
+	Filter   providers.RepoFilter `json:"filter"`
+	UseSSH   bool                 `json:"useSsh"`
+	Update   bool                 `json:"update"` // Also fast-forward already cloned repos
+	Repos    []string             `json:"repos"`  // Optional: only clone these repo names
+}
+
+// listGitHubRepos fetches, filters and marks the repos of the requested organization
+func listGitHubRepos(req GitHubReposRequest) ([]providers.RemoteRepo, error) {
+	client := providers.NewGitHubClient(req.Token)
+	repos, err := client.ListOrgRepos(req.Org)
+	if err != nil {
+		return nil, err
+	}
+	repos = providers.FilterRepos(repos, req.Filter)
+	if req.RootPath != "" {
+		providers.MarkCloned(repos, req.RootPath)
+	}
+	return repos, nil
+}
+
+func handleGitHubRepos(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req GitHubReposRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	repos, err := listGitHubRepos(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(repos)
+}
+
+func handleGitHubClone(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req GitHubReposRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.RootPath == "" {
+		http.Error(w, "rootPath is required", http.StatusBadRequest)
+		return
+	}
+
+	// Set headers for streaming
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	repos, err := listGitHubRepos(req)
+	if err != nil {
+		fmt.Fprintf(w, "[ERROR] %v\n", err)
+		flusher.Flush()
+		return
+	}
+
+	repos = selectRemoteRepos(repos, req.Repos)
+
+	fmt.Fprintf(w, "CLONE_INIT:%d\n", len(repos))
+	flusher.Flush()
+
+	cloned := providers.CloneMissing(repos, providers.CloneOptions{
+		RootPath: req.RootPath,
+		Username: "x-access-token",
+		Token:    req.Token,
+		UseSSH:   req.UseSSH,
+		Update:   req.Update,
+		Log: func(msg string) {
+			fmt.Fprintf(w, "%s\n", msg)
+			flusher.Flush()
+		},
+	})
+
+	fmt.Fprintf(w, "CLONE_COMPLETE:%d\n", cloned)
+	flusher.Flush()
+}
+
+// selectRemoteRepos restricts repos to the given names (the UI selection); no names keeps all
+func selectRemoteRepos(repos []providers.RemoteRepo, names []string) []providers.RemoteRepo {
+	if len(names) == 0 {
+		return repos
+	}
+	selected := make(map[string]bool)
+	for _, name := range names {
+		selected[name] = true
+	}
+	var filtered []providers.RemoteRepo
+	for _, repo := range repos {
+		if selected[repo.Name] {
+			filtered = append(filtered, repo)
+		}
+	}
+	return filtered
+}
+
+// ==================== GITLAB INTEGRATION ====================
+
+type GitLabRequest struct {
+	BaseURL      string               `json:"baseUrl"` // Empty = gitlab.com
+	Group        string               `json:"group"`   // Group path, e.g. "acme/backend"
+	Token        string               `json:"token"`
+	RootPath     string               `json:"rootPath"`
+	Filter       providers.RepoFilter `json:"filter"`
+	UseSSH       bool                 `json:"useSsh"`
+	Update       bool                 `json:"update"`       // Clone only: also fast-forward already cloned repos
+	Repos        []string             `json:"repos"`        // Clone only: only these repo names
+	SourceBranch string               `json:"sourceBranch"` // Merge requests only (default "housekeeping")
+}
+
+// listGitLabProjects fetches, filters and marks the projects of the requested group
+func listGitLabProjects(req GitLabRequest) ([]providers.RemoteRepo, error) {
+	client := providers.NewGitLabClient(req.BaseURL, req.Token)
+	repos, err := client.ListGroupProjects(req.Group)
+	if err != nil {
+		return nil, err
+	}
+	repos = providers.FilterRepos(repos, req.Filter)
+	if req.RootPath != "" {
+		providers.MarkCloned(repos, req.RootPath)
+	}
+	return repos, nil
+}
+
+func handleGitLabRepos(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req GitLabRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	repos, err := listGitLabProjects(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(repos)
+}
+
+func handleGitLabClone(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req GitLabRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.RootPath == "" {
+		http.Error(w, "rootPath is required", http.StatusBadRequest)
+		return
+	}
+
+	// Set headers for streaming
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	repos, err := listGitLabProjects(req)
+	if err != nil {
+		fmt.Fprintf(w, "[ERROR] %v\n", err)
+		flusher.Flush()
+		return
+	}
+	repos = selectRemoteRepos(repos, req.Repos)
+
+	fmt.Fprintf(w, "CLONE_INIT:%d\n", len(repos))
+	flusher.Flush()
+
+	cloned := providers.CloneMissing(repos, providers.CloneOptions{
+		RootPath: req.RootPath,
+		Username: "oauth2",
+		Token:    req.Token,
+		UseSSH:   req.UseSSH,
+		Update:   req.Update,
+		Log: func(msg string) {
+			fmt.Fprintf(w, "%s\n", msg)
+			flusher.Flush()
+		},
+	})
+
+	fmt.Fprintf(w, "CLONE_COMPLETE:%d\n", cloned)
+	flusher.Flush()
+}
+
+func handleGitLabMergeRequests(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req GitLabRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.SourceBranch == "" {
+		req.SourceBranch = "housekeeping"
+	}
+
+	client := providers.NewGitLabClient(req.BaseURL, req.Token)
+	mrs, err := client.ListGroupMergeRequests(req.Group, req.SourceBranch)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	if mrs == nil {
+		mrs = []providers.MergeRequest{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mrs)
+}
+
+// ==================== .GITIGNORE AUDIT ====================
+
+type GitignoreRequest struct {
+	RootPath  string                `json:"rootPath"`
+	RootPaths []string              `json:"rootPaths"` // Further roots searched together with RootPath
+	Excluded  []string              `json:"excluded"`
+	Group     string                `json:"group"`   // Optional repository group instead of RootPath
+	Repos     []string              `json:"repos"`   // Fix only: repo names to fix (empty = all with findings)
+	Branch    string                `json:"branch"`  // Fix only: branch for the commit (default "housekeeping")
+	Signing   logic.SigningSettings `json:"signing"` // Fix only
+}
+
+func handleGitignoreAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req GitignoreRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	result := []logic.GitignoreReport{}
+	for _, repoPath := range repos {
+		result = append(result, logic.AnalyzeGitignore(repoPath))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func handleGitignoreFix(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req GitignoreRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Branch == "" {
+		req.Branch = "housekeeping"
+	}
+
+	repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Set headers for streaming
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	selected := make(map[string]bool)
+	for _, name := range req.Repos {
+		selected[name] = true
+	}
+
+	log := func(msg string) {
+		fmt.Fprintln(w, msg)
+		flusher.Flush()
+	}
+
+	fixed := 0
+	for _, repoPath := range repos {
+		repoName := filepath.Base(repoPath)
+		if len(selected) > 0 && !selected[repoName] {
+			continue
+		}
+
+		// Re-analyze, the repo may have changed since the audit
+		report := logic.AnalyzeGitignore(repoPath)
+		if report.Error != "" || (len(report.MissingRules) == 0 && len(report.Offenders) == 0) {
+			continue
+		}
+
+		log(fmt.Sprintf("REPO_START:%s", repoName))
+		if err := logic.FixGitignore(report, req.Branch, req.Signing, log); err != nil {
+			log(fmt.Sprintf("  [ERROR] %v", err))
+		} else {
+			fixed++
+		}
+		log(fmt.Sprintf("REPO_DONE:%s", repoName))
+	}
+
+	log(fmt.Sprintf("GITIGNORE_COMPLETE:%d", fixed))
+}
+
+// handleGitignoreStandard returns (GET) or saves (POST) the org-standard .gitignore block:
+// /api/gitignore-standard
+func handleGitignoreStandard(w http.ResponseWriter, r *http.Request) {
+	var standard logic.GitignoreStandard
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		standard, err = logic.LoadGitignoreStandard()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&standard); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if standard, err = logic.SaveGitignoreStandard(standard); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Info("Standard .gitignore block updated", "rules", len(standard.Rules))
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(standard)
+}
+
+type GitignoreNormalizeRequest struct {
+	RootPath  string                `json:"rootPath"`
+	RootPaths []string              `json:"rootPaths"` // Further roots searched together with RootPath
+	Excluded  []string              `json:"excluded"`
+	Group     string                `json:"group"`  // Optional repository group instead of RootPath
+	Repos     []string              `json:"repos"`  // Repo names to normalize (empty = all)
+	Branch    string                `json:"branch"` // Branch for the commit (default "housekeeping")
+	Signing   logic.SigningSettings `json:"signing"`
+	DryRun    bool                  `json:"dryRun"` // Only report what would change
+}
+
+// handleGitignoreNormalize merges the org-standard block into the .gitignore of every selected repo
+// and reports the repos tracking files that should be ignored
+func handleGitignoreNormalize(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req GitignoreNormalizeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Branch == "" {
+		req.Branch = "housekeeping"
+	}
+	standard, err := logic.LoadGitignoreStandard()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Set headers for streaming
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	selected := make(map[string]bool)
+	for _, name := range req.Repos {
+		selected[name] = true
+	}
+
+	log := func(msg string) {
+		fmt.Fprintln(w, msg)
+		flusher.Flush()
+	}
+
+	changed := 0
+	var tracking []string
+	for _, repoPath := range repos {
+		repoName := filepath.Base(repoPath)
+		if len(selected) > 0 && !selected[repoName] {
+			continue
+		}
+
+		log(fmt.Sprintf("REPO_START:%s", repoName))
+		result, err := logic.NormalizeGitignore(repoPath, standard.Rules, req.Branch, req.Signing, req.DryRun, log)
+		if err != nil {
+			log(fmt.Sprintf("  [ERROR] %v", err))
+		} else if result.Changed {
+			changed++
+		}
+		if len(result.Tracked) > 0 {
+			tracking = append(tracking, repoName)
+		}
+		log(fmt.Sprintf("REPO_DONE:%s", repoName))
+	}
+
+	if len(tracking) > 0 {
+		log(fmt.Sprintf("Repositories tracking files that should be ignored: %s", strings.Join(tracking, ", ")))
+	}
+	log(fmt.Sprintf("GITIGNORE_NORMALIZE_COMPLETE:%d", changed))
+}
+
+// ==================== REPOSITORY SIZE ====================
+
+type RepoSizeRequest struct {
+	RootPath  string   `json:"rootPath"`
+	RootPaths []string `json:"rootPaths"` // Further roots searched together with RootPath
+	Excluded  []string `json:"excluded"`
+	Group     string   `json:"group"` // Optional repository group instead of RootPath
+	Top       int      `json:"top"`   // Largest blobs listed per repo (default 10)
+}
+
+// handleRepoSizeReport measures all repos and lists their largest blobs, biggest .git first
+func handleRepoSizeReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req RepoSizeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Top > 100 {
+		req.Top = 100
+	}
+	repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result := []logic.RepoSizeReport{}
+	for _, repoPath := range repos {
+		result = append(result, logic.AnalyzeRepoSize(repoPath, req.Top))
+	}
+	slices.SortStableFunc(result, func(a, b logic.RepoSizeReport) int { return cmp.Compare(b.GitDirBytes, a.GitDirBytes) })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// ==================== GIT LFS ====================
+
+type LFSRequest struct {
+	RootPath  string   `json:"rootPath"`
+	RootPaths []string `json:"rootPaths"` // Further roots searched together with RootPath
+	Excluded  []string `json:"excluded"`
+	Group     string   `json:"group"`    // Optional repository group instead of RootPath
+	Repos     []string `json:"repos"`    // Dry run only: repo paths (empty = all)
+	Patterns  []string `json:"patterns"` // Dry run only: .gitattributes patterns to migrate
+}
+
+// handleLFSAudit reports the LFS patterns and the large files stored without LFS per repo
+func handleLFSAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req LFSRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result := []logic.LFSReport{}
+	for _, repoPath := range repos {
+		result = append(result, logic.AnalyzeLFS(repoPath))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"lfsInstalled": logic.LFSAvailable(), "repos": result})
+}
+
+// handleLFSDryRun streams what a migration of the patterns would move, without changing the repos.
+// The migration itself runs as part of a housekeeping run (RunRequest.LFS) on the work branch.
+func handleLFSDryRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req LFSRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.Patterns) == 0 {
+		http.Error(w, "at least one pattern is required", http.StatusBadRequest)
+		return
+	}
+	repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Set headers for streaming
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	selected := make(map[string]bool)
+	for _, repo := range req.Repos {
+		selected[filepath.Clean(repo)] = true
+	}
+
+	log := func(msg string) {
+		fmt.Fprintln(w, msg)
+		flusher.Flush()
+	}
+
+	for _, repoPath := range repos {
+		if len(selected) > 0 && !selected[filepath.Clean(repoPath)] {
+			continue
+		}
+		log(fmt.Sprintf("REPO_START:%s", filepath.Base(repoPath)))
+		if err := logic.LFSDryRun(repoPath, req.Patterns, log); err != nil {
+			log(fmt.Sprintf("  [ERROR] %v", err))
+		}
+		log(fmt.Sprintf("REPO_DONE:%s", filepath.Base(repoPath)))
+	}
+	log("LFS_DRY_RUN_COMPLETE")
+}
+
+// ==================== BASE IMAGES ====================
+
+type BaseImageUpdateRequest struct {
+	RootPath  string                `json:"rootPath"`
+	RootPaths []string              `json:"rootPaths"` // Further roots searched together with RootPath
+	Excluded  []string              `json:"excluded"`
+	Group     string                `json:"group"`  // Optional repository group instead of RootPath
+	Repos     []string              `json:"repos"`  // Repo paths to update (empty = all using the image)
+	From      string                `json:"from"`   // Image reference to replace, e.g. "openjdk:8"
+	To        string                `json:"to"`     // New image reference, e.g. "eclipse-temurin:8"
+	Branch    string                `json:"branch"` // Branch for the commit (default "housekeeping")
+	Signing   logic.SigningSettings `json:"signing"`
+}
+
+// handleBaseImageUpdate replaces a base image in the Dockerfiles of all selected repos and commits it
+func handleBaseImageUpdate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req BaseImageUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.From, req.To = strings.TrimSpace(req.From), strings.TrimSpace(req.To)
+	if req.From == "" || req.To == "" || strings.ContainsAny(req.From+req.To, " \t\r\n") || req.From == req.To {
+		http.Error(w, "from and to must be two different image references", http.StatusBadRequest)
+		return
+	}
+	if req.Branch == "" {
+		req.Branch = "housekeeping"
+	}
+	repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Set headers for streaming
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	selected := make(map[string]bool)
+	for _, repo := range req.Repos {
+		selected[filepath.Clean(repo)] = true
+	}
+
+	log := func(msg string) {
+		fmt.Fprintln(w, msg)
+		flusher.Flush()
+	}
+
+	updated := 0
+	for _, repoPath := range repos {
+		if len(selected) > 0 && !selected[filepath.Clean(repoPath)] {
+			continue
+		}
+		log(fmt.Sprintf("REPO_START:%s", filepath.Base(repoPath)))
+		changed, err := logic.UpdateBaseImage(repoPath, req.From, req.To, req.Branch, req.Signing, log)
+		if err != nil {
+			log(fmt.Sprintf("  [ERROR] %v", err))
+		} else if changed {
+			updated++
+		}
+		log(fmt.Sprintf("REPO_DONE:%s", filepath.Base(repoPath)))
+	}
+
+	log(fmt.Sprintf("BASE_IMAGE_COMPLETE:%d", updated))
+}
+
+// ==================== CI ACTIONS ====================
+
+type CIActionUpdateRequest struct {
+	RootPath  string                `json:"rootPath"`
+	RootPaths []string              `json:"rootPaths"` // Further roots searched together with RootPath
+	Excluded  []string              `json:"excluded"`
+	Group     string                `json:"group"`  // Optional repository group instead of RootPath
+	Repos     []string              `json:"repos"`  // Repo paths to update (empty = all using the action)
+	From      string                `json:"from"`   // Reference to replace, e.g. "actions/checkout@v3"
+	To        string                `json:"to"`     // New reference, e.g. "actions/checkout@v4"
+	Branch    string                `json:"branch"` // Branch for the commit (default "housekeeping")
+	Signing   logic.SigningSettings `json:"signing"`
+}
+
+// handleCIActionUpdate replaces a GitHub action or GitLab component reference in the CI files of all
+// selected repos and commits it
+func handleCIActionUpdate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req CIActionUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.From, req.To = strings.TrimSpace(req.From), strings.TrimSpace(req.To)
+	if !strings.Contains(req.From, "@") || !strings.Contains(req.To, "@") || strings.ContainsAny(req.From+req.To, " \t\r\n") || req.From == req.To {
+		http.Error(w, "from and to must be two different references like actions/checkout@v4", http.StatusBadRequest)
+		return
+	}
+	if req.Branch == "" {
+		req.Branch = "housekeeping"
+	}
+	repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Set headers for streaming
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	selected := make(map[string]bool)
+	for _, repo := range req.Repos {
+		selected[filepath.Clean(repo)] = true
+	}
+
+	log := func(msg string) {
+		fmt.Fprintln(w, msg)
+		flusher.Flush()
+	}
+
+	updated := 0
+	for _, repoPath := range repos {
+		if len(selected) > 0 && !selected[filepath.Clean(repoPath)] {
+			continue
+		}
+		log(fmt.Sprintf("REPO_START:%s", filepath.Base(repoPath)))
+		changed, err := logic.UpdateCIAction(repoPath, req.From, req.To, req.Branch, req.Signing, log)
+		if err != nil {
+			log(fmt.Sprintf("  [ERROR] %v", err))
+		} else if changed {
+			updated++
+		}
+		log(fmt.Sprintf("REPO_DONE:%s", filepath.Base(repoPath)))
+	}
+
+	log(fmt.Sprintf("CI_ACTION_COMPLETE:%d", updated))
+}
+
+// ==================== DEPENDENCY BOTS ====================
+
+// handleDependencyBotSettings returns (GET) or saves (POST) the Renovate and Dependabot config
+// templates: /api/dependency-bot-settings
+func handleDependencyBotSettings(w http.ResponseWriter, r *http.Request) {
+	var settings logic.DependencyBotSettings
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		settings, err = logic.LoadDependencyBotSettings()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if settings, err = logic.SaveDependencyBotSettings(settings); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Info("Dependency bot templates updated")
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
+
+type DependencyBotSeedRequest struct {
+	RootPath  string                `json:"rootPath"`
+	RootPaths []string              `json:"rootPaths"` // Further roots searched together with RootPath
+	Excluded  []string              `json:"excluded"`
+	Group     string                `json:"group"`  // Optional repository group instead of RootPath
+	Repos     []string              `json:"repos"`  // Repo paths to seed (empty = all without bot config)
+	Tool      string                `json:"tool"`   // "renovate" or "dependabot"
+	Branch    string                `json:"branch"` // Branch for the commit (default "housekeeping")
+	Signing   logic.SigningSettings `json:"signing"`
+}
+
+// handleDependencyBotSeed commits the templated Renovate or Dependabot config to all selected repos
+// that have no dependency update bot configured
+func handleDependencyBotSeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req DependencyBotSeedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Tool != logic.BotRenovate && req.Tool != logic.BotDependabot {
+		http.Error(w, "tool must be renovate or dependabot", http.StatusBadRequest)
+		return
+	}
+	if req.Branch == "" {
+		req.Branch = "housekeeping"
+	}
+	settings, err := logic.LoadDependencyBotSettings()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Set headers for streaming
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	selected := make(map[string]bool)
+	for _, repo := range req.Repos {
+		selected[filepath.Clean(repo)] = true
+	}
+
+	log := func(msg string) {
+		fmt.Fprintln(w, msg)
+		flusher.Flush()
+	}
+
+	seeded := 0
+	for _, repoPath := range repos {
+		if len(selected) > 0 && !selected[filepath.Clean(repoPath)] {
+			continue
+		}
+		log(fmt.Sprintf("REPO_START:%s", filepath.Base(repoPath)))
+		changed, err := logic.SeedDependencyBotConfig(repoPath, req.Tool, settings, req.Branch, req.Signing, log)
+		if err != nil {
+			log(fmt.Sprintf("  [ERROR] %v", err))
+		} else if changed {
+			seeded++
+		}
+		log(fmt.Sprintf("REPO_DONE:%s", filepath.Base(repoPath)))
+	}
+
+	log(fmt.Sprintf("DEPENDENCY_BOT_COMPLETE:%d", seeded))
+}
+
+// ==================== REPO HYGIENE ====================
+
+// handleHygieneTemplates returns (GET) or saves (POST) the templates of the standard files:
+// /api/hygiene-templates
+func handleHygieneTemplates(w http.ResponseWriter, r *http.Request) {
+	var templates logic.HygieneTemplates
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		templates, err = logic.LoadHygieneTemplates()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&templates); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if templates, err = logic.SaveHygieneTemplates(templates); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Info("Repository file templates updated")
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(templates)
+}
+
+type HygieneSeedRequest struct {
+	RootPath  string                `json:"rootPath"`
+	RootPaths []string              `json:"rootPaths"` // Further roots searched together with RootPath
+	Excluded  []string              `json:"excluded"`
+	Group     string                `json:"group"`  // Optional repository group instead of RootPath
+	Repos     []string              `json:"repos"`  // Repo paths to seed (empty = all)
+	Files     []string              `json:"files"`  // Standard files to seed, e.g. "README" (empty = all)
+	Branch    string                `json:"branch"` // Branch for the commit (default "housekeeping")
+	Signing   logic.SigningSettings `json:"signing"`
+}
+
+// handleHygieneSeed adds the missing standard files of all selected repos from the templates and
+// commits them
+func handleHygieneSeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req HygieneSeedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, file := range req.Files {
+		if !slices.Contains(logic.HygieneFiles, file) {
+			http.Error(w, fmt.Sprintf("unknown file '%s', expected one of %s", file, strings.Join(logic.HygieneFiles, ", ")), http.StatusBadRequest)
+			return
+		}
+	}
+	if req.Branch == "" {
+		req.Branch = "housekeeping"
+	}
+	templates, err := logic.LoadHygieneTemplates()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Set headers for streaming
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	selected := make(map[string]bool)
+	for _, repo := range req.Repos {
+		selected[filepath.Clean(repo)] = true
+	}
+
+	log := func(msg string) {
+		fmt.Fprintln(w, msg)
+		flusher.Flush()
+	}
+
+	updated := 0
+	for _, repoPath := range repos {
+		if len(selected) > 0 && !selected[filepath.Clean(repoPath)] {
+			continue
+		}
+		log(fmt.Sprintf("REPO_START:%s", filepath.Base(repoPath)))
+		seeded, err := logic.SeedHygieneFiles(repoPath, req.Files, templates, req.Branch, req.Signing, log)
+		if err != nil {
+			log(fmt.Sprintf("  [ERROR] %v", err))
+		} else if len(seeded) > 0 {
+			updated++
+		}
+		log(fmt.Sprintf("REPO_DONE:%s", filepath.Base(repoPath)))
+	}
+
+	log(fmt.Sprintf("HYGIENE_COMPLETE:%d", updated))
+}
+
+// ==================== TAGS ====================
+
+// ListTagsRequest selects the repos and the release tags to report
+type ListTagsRequest struct {
+	RootPath  string                       `json:"rootPath"`
+	RootPaths []string                     `json:"rootPaths"` // Further roots searched together with RootPath
+	Excluded  []string                     `json:"excluded"`
+	Group     string                       `json:"group"` // Optional repository group instead of RootPath
+	Tags      logic.TagSettings            `json:"tags"`
+	RepoTags  map[string]logic.TagSettings `json:"repoTags"` // Per-repo overrides, keyed by repo folder name
+}
+
+// handleListTags reports the latest tag of every repo and the commits on HEAD since that tag
+func handleListTags(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ListTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result := []logic.RepoTagInfo{}
+	for _, repoPath := range repos {
+		settings := req.Tags
+		if tags, ok := req.RepoTags[filepath.Base(repoPath)]; ok {
+			settings = tags
+		}
+		result = append(result, logic.ListRepoTags(repoPath, settings))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// ChangelogRequest selects the repos whose CHANGELOG.md is updated
+type ChangelogRequest struct {
+	RootPath  string                       `json:"rootPath"`
+	RootPaths []string                     `json:"rootPaths"` // Further roots searched together with RootPath
+	Excluded  []string                     `json:"excluded"`
+	Group     string                       `json:"group"` // Optional repository group instead of RootPath
+	Repos     []string                     `json:"repos"` // Repo paths to update (empty = all)
+	Tags      logic.TagSettings            `json:"tags"`
+	RepoTags  map[string]logic.TagSettings `json:"repoTags"` // Per-repo overrides, keyed by repo folder name
+	Branch    string                       `json:"branch"`   // Branch for the commit (default "housekeeping")
+	Signing   logic.SigningSettings        `json:"signing"`
+	DryRun    bool                         `json:"dryRun"` // Only print the changelog section
+}
+
+// handleChangelog writes the commits since the latest release tag, grouped by conventional commit
+// type, to the Unreleased section of CHANGELOG.md in every selected repo and commits it
+func handleChangelog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ChangelogRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Branch == "" {
+		req.Branch = "housekeeping"
+	}
+	repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Set headers for streaming
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	selected := make(map[string]bool)
+	for _, repo := range req.Repos {
+		selected[filepath.Clean(repo)] = true
+	}
+
+	log := func(msg string) {
+		fmt.Fprintln(w, msg)
+		flusher.Flush()
+	}
+
+	updated := 0
+	for _, repoPath := range repos {
+		if len(selected) > 0 && !selected[filepath.Clean(repoPath)] {
+			continue
+		}
+		settings := req.Tags
+		if tags, ok := req.RepoTags[filepath.Base(repoPath)]; ok {
+			settings = tags
+		}
+		log(fmt.Sprintf("REPO_START:%s", filepath.Base(repoPath)))
+		if req.DryRun {
+			changelog := logic.BuildChangelog(repoPath, settings)
+			switch {
+			case changelog.Error != "":
+				log(fmt.Sprintf("  [ERROR] %s", changelog.Error))
+			case changelog.Commits == 0:
+				log("  No commits since the latest release tag.")
+			default:
+				log(fmt.Sprintf("  [DRY RUN] %d commit(s) since %s:", changelog.Commits, cmp.Or(changelog.SinceTag, "the first commit")))
+				for _, line := range strings.Split(strings.TrimRight(logic.RenderChangelogSection(changelog, "", time.Now()), "\n"), "\n") {
+					log("    " + line)
+				}
+				updated++
 			}
-			if url, exists := viaMap["url"]; exists {
-				if urlStr, ok := url.(string); ok {
-					// Extract CVE or GHSA from URL
-					if strings.Contains(urlStr, "CVE-") {
-						parts := strings.Split(urlStr, "/")
-						for _, p := range parts {
-							if strings.HasPrefix(p, "CVE-") {
-								cveID = p
-								break
-							}
-						}
-					} else if strings.Contains(urlStr, "GHSA-") {
-						parts := strings.Split(urlStr, "/")
-						for _, p := range parts {
-							if strings.HasPrefix(p, "GHSA-") {
-								cveID = p
-								break
-							}
-						}
+		} else if changed, err := logic.UpdateChangelog(repoPath, settings, logic.UnreleasedVersion, req.Branch, req.Signing, log); err != nil {
+			log(fmt.Sprintf("  [ERROR] %v", err))
+		} else if changed {
+			updated++
+		}
+		log(fmt.Sprintf("REPO_DONE:%s", filepath.Base(repoPath)))
+	}
+
+	log(fmt.Sprintf("CHANGELOG_COMPLETE:%d", updated))
+}
+
+// ReleaseRequest selects the repos to release and how
+type ReleaseRequest struct {
+	RootPath  string                       `json:"rootPath"`
+	RootPaths []string                     `json:"rootPaths"` // Further roots searched together with RootPath
+	Excluded  []string                     `json:"excluded"`
+	Group     string                       `json:"group"` // Optional repository group instead of RootPath
+	Repos     []string                     `json:"repos"` // Repo paths to release (empty = all)
+	Tags      logic.TagSettings            `json:"tags"`
+	RepoTags  map[string]logic.TagSettings `json:"repoTags"`  // Per-repo overrides, keyed by repo folder name
+	Strategy  string                       `json:"strategy"`  // "major", "minor" or "patch" (default)
+	Versions  map[string]string            `json:"versions"`  // Confirmed or edited versions, keyed by repo path
+	Changelog bool                         `json:"changelog"` // Move the Unreleased section of CHANGELOG.md to the version
+	Push      bool                         `json:"push"`
+	Signing   logic.SigningSettings        `json:"signing"`
+	DryRun    bool                         `json:"dryRun"` // Only print the plan
+}
+
+// releaseOptions returns the release options of a repo
+func (req ReleaseRequest) releaseOptions(repoPath string) logic.ReleaseOptions {
+	opts := logic.ReleaseOptions{
+		Tags:      req.Tags,
+		Strategy:  req.Strategy,
+		Version:   req.Versions[filepath.Clean(repoPath)],
+		Changelog: req.Changelog,
+		Push:      req.Push,
+		Signing:   req.Signing,
+	}
+	if tags, ok := req.RepoTags[filepath.Base(repoPath)]; ok {
+		opts.Tags = tags
+	}
+	return opts
+}
+
+// decodeReleaseRequest reads and validates a release request and resolves its repos
+func decodeReleaseRequest(w http.ResponseWriter, r *http.Request) (ReleaseRequest, []string, bool) {
+	var req ReleaseRequest
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return req, nil, false
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return req, nil, false
+	}
+	if req.Strategy != "" && req.Strategy != "major" && req.Strategy != "minor" && req.Strategy != "patch" {
+		http.Error(w, "strategy must be major, minor or patch", http.StatusBadRequest)
+		return req, nil, false
+	}
+	repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return req, nil, false
+	}
+	versions := make(map[string]string)
+	for repo, version := range req.Versions {
+		versions[filepath.Clean(repo)] = version
+	}
+	req.Versions = versions
+
+	selected := make(map[string]bool)
+	for _, repo := range req.Repos {
+		selected[filepath.Clean(repo)] = true
+	}
+	var result []string
+	for _, repoPath := range repos {
+		if len(selected) == 0 || selected[filepath.Clean(repoPath)] {
+			result = append(result, repoPath)
+		}
+	}
+	return req, result, true
+}
+
+// handleReleasePlan previews the next release of every selected repo: /api/release/plan
+func handleReleasePlan(w http.ResponseWriter, r *http.Request) {
+	req, repos, ok := decodeReleaseRequest(w, r)
+	if !ok {
+		return
+	}
+	plans := []logic.ReleasePlan{}
+	for _, repoPath := range repos {
+		plans = append(plans, logic.PlanRelease(repoPath, req.releaseOptions(repoPath)))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(plans)
+}
+
+// handleRelease sets the version, commits, tags and pushes every selected repo: /api/release
+func handleRelease(w http.ResponseWriter, r *http.Request) {
+	req, repos, ok := decodeReleaseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// Set headers for streaming
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	log := func(msg string) {
+		fmt.Fprintln(w, msg)
+		flusher.Flush()
+	}
+
+	released := 0
+	for _, repoPath := range repos {
+		log(fmt.Sprintf("REPO_START:%s", filepath.Base(repoPath)))
+		opts := req.releaseOptions(repoPath)
+		if req.DryRun {
+			plan := logic.PlanRelease(repoPath, opts)
+			if plan.Error != "" {
+				log(fmt.Sprintf("  [ERROR] %s", plan.Error))
+			} else {
+				log(fmt.Sprintf("  [DRY RUN] %s -> %s, tag '%s' on %s, %d commit(s) since %s", cmp.Or(plan.CurrentVersion, "-"), plan.Version, plan.Tag, plan.Branch, plan.Commits, cmp.Or(plan.LatestTag, "the first commit")))
+				for _, file := range plan.Files {
+					if file.Version != plan.Version {
+						log(fmt.Sprintf("    %s: %s -> %s", file.File, file.Version, plan.Version))
 					}
 				}
+				released++
 			}
-			if title, exists := viaMap["title"]; exists {
-				if titleStr, ok := title.(string); ok {
-					description = titleStr
-				}
+		} else if _, err := logic.Release(repoPath, opts, log); err != nil {
+			log(fmt.Sprintf("  [ERROR] %v", err))
+		} else {
+			released++
+		}
+		log(fmt.Sprintf("REPO_DONE:%s", filepath.Base(repoPath)))
+	}
+
+	log(fmt.Sprintf("RELEASE_COMPLETE:%d", released))
+}
+
+// ==================== REMOTE BRANCHES ====================
+
+// RemoteBranchesRequest selects the repos for the remote branch report
+type RemoteBranchesRequest struct {
+	RootPath  string   `json:"rootPath"`
+	RootPaths []string `json:"rootPaths"` // Further roots searched together with RootPath
+	Excluded  []string `json:"excluded"`
+	Group     string   `json:"group"` // Optional repository group instead of RootPath
+	Fetch     bool     `json:"fetch"` // Fetch and prune origin before the analysis
+}
+
+// RemoteBranchDeletion is one branch the user confirmed for deletion on origin
+type RemoteBranchDeletion struct {
+	Repo   string `json:"repo"` // Repo name as reported by the analysis
+	Branch string `json:"branch"`
+}
+
+type DeleteRemoteBranchesRequest struct {
+	RootPath  string                 `json:"rootPath"`
+	RootPaths []string               `json:"rootPaths"` // Further roots searched together with RootPath
+	Excluded  []string               `json:"excluded"`
+	Group     string                 `json:"group"` // Optional repository group instead of RootPath
+	Branches  []RemoteBranchDeletion `json:"branches"`
+}
+
+func handleRemoteBranches(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req RemoteBranchesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result := []logic.RemoteBranchReport{}
+	for _, repoPath := range repos {
+		result = append(result, logic.AnalyzeRemoteBranches(repoPath, req.Fetch))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleDeleteRemoteBranches deletes exactly the listed branches on origin, nothing is derived from filters
+func handleDeleteRemoteBranches(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req DeleteRemoteBranchesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.Branches) == 0 {
+		http.Error(w, "No branches selected", http.StatusBadRequest)
+		return
+	}
+
+	repoPaths, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Set headers for streaming
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	log := func(msg string) {
+		fmt.Fprintln(w, msg)
+		flusher.Flush()
+	}
+
+	repos := make(map[string]string)
+	for _, repoPath := range repoPaths {
+		repos[filepath.Base(repoPath)] = repoPath
+	}
+
+	deleted := 0
+	current := ""
+	for _, d := range req.Branches {
+		if d.Repo != current {
+			if current != "" {
+				log(fmt.Sprintf("REPO_DONE:%s", current))
+			}
+			current = d.Repo
+			log(fmt.Sprintf("REPO_START:%s", current))
+		}
+		repoPath, found := repos[d.Repo]
+		if !found {
+			log(fmt.Sprintf("  [ERROR] Repository '%s' not found below the root path", d.Repo))
+			continue
+		}
+		if err := logic.DeleteRemoteBranch(repoPath, d.Branch); err != nil {
+			log(fmt.Sprintf("  [ERROR] %s: %v", d.Branch, err))
+			continue
+		}
+		log(fmt.Sprintf("  ✓ %s/%s deleted", logic.PrimaryRemote(repoPath), d.Branch))
+		deleted++
+	}
+	if current != "" {
+		log(fmt.Sprintf("REPO_DONE:%s", current))
+	}
+
+	log(fmt.Sprintf("DELETE_COMPLETE:%d", deleted))
+}
+
+// ==================== PARENT VERSIONS ====================
+
+// ParentSuggestionsRequest selects the repos whose parent POMs are looked up
+type ParentSuggestionsRequest struct {
+	RootPath  string   `json:"rootPath"`
+	RootPaths []string `json:"rootPaths"` // Further roots searched together with RootPath
+	Excluded  []string `json:"excluded"`
+	Group     string   `json:"group"`
+}
+
+// handleParentSuggestions reports the parent POM of each Maven repo and its newest release:
+// POST /api/parent-suggestions
+func handleParentSuggestions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req ParentSuggestionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result := []*logic.ParentSuggestion{}
+	for _, repoPath := range repos {
+		if suggestion := logic.SuggestParentVersion(repoPath); suggestion != nil {
+			result = append(result, suggestion)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// ==================== MAVEN UPDATES ====================
+
+// OutdatedMavenRequest selects the repos for the Maven update analysis
+type OutdatedMavenRequest struct {
+	RootPath  string              `json:"rootPath"`
+	RootPaths []string            `json:"rootPaths"` // Further roots searched together with RootPath
+	Excluded  []string            `json:"excluded"`
+	Group     string              `json:"group"` // Optional repository group instead of RootPath
+	Maven     logic.MavenSettings `json:"maven"`
+}
+
+// handleOutdatedMaven streams the versions-maven-plugin update report of each Maven repo
+func handleOutdatedMaven(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req OutdatedMavenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Set headers for streaming
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	count := 0
+	for _, repoPath := range repos {
+		if _, err := os.Stat(filepath.Join(repoPath, "pom.xml")); err != nil {
+			continue
+		}
+		repoName := filepath.Base(repoPath)
+		fmt.Fprintf(w, "REPO_START:%s\n", repoName)
+		flusher.Flush()
+
+		report := logic.AnalyzeMavenUpdates(repoPath, req.Maven)
+		data, _ := json.Marshal(report)
+		fmt.Fprintf(w, "REPO_RESULT:%s\n", data)
+		flusher.Flush()
+		count++
+	}
+
+	fmt.Fprintf(w, "OUTDATED_COMPLETE:%d\n", count)
+	flusher.Flush()
+}
+
+// ==================== REPLACEMENT PREVIEW ====================
+
+// PreviewReplacementsRequest shows what the replacements would change without running them
+type PreviewReplacementsRequest struct {
+	RootPath         string              `json:"rootPath"`
+	RootPaths        []string            `json:"rootPaths"` // Further roots searched together with RootPath
+	Excluded         []string            `json:"excluded"`
+	Group            string              `json:"group"` // Optional repository group instead of RootPath
+	Replacements     []logic.Replacement `json:"replacements"`
+	ReplacementScope string              `json:"replacementScope"` // "all", "pom-only", "exclude-pom"
+}
+
+// handlePreviewReplacements streams the diffs the replacements would produce in each repo's working tree
+func handlePreviewReplacements(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req PreviewReplacementsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if issues := logic.ValidateReplacements(req.Replacements); len(issues) > 0 {
+		http.Error(w, fmt.Sprintf("Invalid replacement '%s': %s", issues[0].Search, issues[0].Error), http.StatusBadRequest)
+		return
+	}
+
+	repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Set headers for streaming
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	count := 0
+	for _, repoPath := range repos {
+		fmt.Fprintf(w, "REPO_START:%s\n", filepath.Base(repoPath))
+		flusher.Flush()
+
+		preview := logic.PreviewReplacements(repoPath, req.Replacements, req.ReplacementScope, req.Excluded)
+		data, _ := json.Marshal(preview)
+		fmt.Fprintf(w, "REPO_RESULT:%s\n", data)
+		flusher.Flush()
+		count++
+	}
+
+	fmt.Fprintf(w, "PREVIEW_COMPLETE:%d\n", count)
+	flusher.Flush()
+}
+
+// ==================== SECURITY AUTO-FIX ====================
+
+// SecurityFixRequest bumps vulnerable dependencies found by a security scan
+type SecurityFixRequest struct {
+	RootPath  string                           `json:"rootPath"`
+	RootPaths []string                         `json:"rootPaths"` // Further roots searched together with RootPath
+	Excluded  []string                         `json:"excluded"`
+	Group     string                           `json:"group"`  // Optional repository group instead of RootPath
+	Repos     map[string][]logic.DependencyFix `json:"repos"`  // Repo name -> fixes (from the scan findings with a fix version)
+	Branch    string                           `json:"branch"` // Branch for the fix commit (default "security-fix")
+	Push      bool                             `json:"push"`
+	Maven     logic.MavenSettings              `json:"maven"`
+	Signing   logic.SigningSettings            `json:"signing"`
+}
+
+func handleSecurityFix(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req SecurityFixRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.Repos) == 0 {
+		http.Error(w, "No fixes requested", http.StatusBadRequest)
+		return
+	}
+
+	repoPaths, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Set headers for streaming
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	log := func(msg string) {
+		fmt.Fprintln(w, msg)
+		flusher.Flush()
+	}
+
+	// Recorded like a housekeeping run, so the fix branches can be rolled back
+	run := &logic.RunRecord{
+		ID:        logic.NewRunID(),
+		StartedAt: time.Now(),
+		RootPath:  req.RootPath,
+		Label:     "Security auto-fix",
+	}
+	var repos []string
+	for _, repoPath := range repoPaths {
+		if len(req.Repos[filepath.Base(repoPath)]) > 0 {
+			repos = append(repos, repoPath)
+		}
+	}
+	if missing := logic.SecurityFixToolNeeds(repos, req.Maven).Missing(req.Maven); len(missing) > 0 {
+		log(fmt.Sprintf("[ERROR] Auto-fix aborted: %d required tools are not installed.", len(missing)))
+		writeMissingTools(w, missing)
+		log(fmt.Sprintf("TOOLS_ABORT:%d", len(missing)))
+		return
+	}
+
+	log(fmt.Sprintf("RUN_ID:%s", run.ID))
+
+	fixed := 0
+	for _, repoPath := range repos {
+		repoName := filepath.Base(repoPath)
+		fixes := req.Repos[repoName]
+
+		log(fmt.Sprintf("REPO_START:%s", repoName))
+		entry := logic.FixVulnerableDependencies(repoPath, fixes, logic.SecurityFixOptions{
+			Branch:  req.Branch,
+			Push:    req.Push,
+			RunID:   run.ID,
+			Maven:   req.Maven,
+			Signing: req.Signing,
+			Log:     log,
+		})
+		if entry.Snapshot.RepoPath != "" {
+			run.Repos = append(run.Repos, entry.Snapshot)
+			if err := logic.SaveRunRecord(run); err != nil {
+				log(fmt.Sprintf("  [WARNING] Could not save run record (rollback unavailable): %v", err))
 			}
 		}
+		if entry.Success && entry.Snapshot.WorkBranch != "" {
+			fixed++
+		}
+		log(fmt.Sprintf("REPO_DONE:%s", repoName))
 	}
 
-	if cveID == "" {
-		cveID = fmt.Sprintf("npm:%s", pkgName)
+	log(fmt.Sprintf("SECURITY_FIX_COMPLETE:%d", fixed))
+}
+
+// ==================== FINDINGS STORE ====================
+
+// toSecurityFindings converts scanner findings for the findings store
+func toSecurityFindings(findings []CVEFinding) []logic.SecurityFinding {
+	result := make([]logic.SecurityFinding, 0, len(findings))
+	for _, f := range findings {
+		result = append(result, logic.SecurityFinding{
+			CVE:         f.CVE,
+			Severity:    f.Severity,
+			CVSSScore:   f.CVSSScore,
+			Package:     f.Package,
+			Version:     f.Version,
+			FixedIn:     f.FixedIn,
+			Description: f.Description,
+			ScanTarget:  f.ScanTarget,
+			SubPath:     f.SubPath,
+		})
 	}
+	return result
+}
 
-	return cveID, description
+// fromSecurityFindings converts stored findings back to scan findings
+func fromSecurityFindings(findings []logic.SecurityFinding) []CVEFinding {
+	result := make([]CVEFinding, 0, len(findings))
+	for _, f := range findings {
+		result = append(result, CVEFinding{
+			CVE:         f.CVE,
+			Severity:    f.Severity,
+			CVSSScore:   f.CVSSScore,
+			Package:     f.Package,
+			Version:     f.Version,
+			FixedIn:     f.FixedIn,
+			Description: f.Description,
+			ScanTarget:  f.ScanTarget,
+			SubPath:     f.SubPath,
+		})
+	}
+	return result
 }
 
-// extractFixInfo extracts fix information from FixAvailable field
-func extractFixInfo(fixAvailable interface{}) string {
-	if fix, ok := fixAvailable.(map[string]interface{}); ok {
-		if version, exists := fix["version"]; exists {
-			return fmt.Sprintf("%v", version)
+// markFindingChanges labels the findings of the result as new or persisting and adds the fixed ones
+func markFindingChanges(result *RepoSecurityResult, delta logic.FindingDelta) {
+	isNew := make(map[string]bool, len(delta.New))
+	for _, f := range delta.New {
+		isNew[f.Key()] = true
+	}
+	for i, f := range toSecurityFindings(result.Findings) {
+		if isNew[f.Key()] {
+			result.Findings[i].Change = logic.FindingNew
+		} else {
+			result.Findings[i].Change = logic.FindingPersisting
 		}
-	} else if fixAvailable == true {
-		return "Update available"
 	}
-	return ""
+	result.Fixed = fromSecurityFindings(delta.Fixed)
 }
 
-// parsePnpmAuditOutput parses pnpm audit JSON output
-func parsePnpmAuditOutput(output []byte, repoName string) RepoSecurityResult {
-	result := RepoSecurityResult{RepoName: repoName}
+// RepoFindingsSummary is the consolidated vulnerability picture of one repo
+type RepoFindingsSummary struct {
+	RepoName string                  `json:"repoName"`
+	RepoPath string                  `json:"repoPath"`
+	Sources  map[string]time.Time    `json:"sources"` // Source -> last update
+	Findings []logic.SecurityFinding `json:"findings"`
+}
 
-	// pnpm audit JSON structure (similar to npm)
-	var pnpmResult struct {
-		Advisories map[string]struct {
-			ID                 int    `json:"id"`
-			ModuleName         string `json:"module_name"`
-			Severity           string `json:"severity"`
-			Title              string `json:"title"`
-			URL                string `json:"url"`
-			VulnerableVersions string `json:"vulnerable_versions"`
-			PatchedVersions    string `json:"patched_versions"`
-		} `json:"advisories"`
+// handleFindings returns the consolidated findings of all sources (scanners and platform imports).
+// GET /api/findings?rootPath=... limits the result to repos below the root path.
+func handleFindings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
 
-	if err := json.Unmarshal(output, &pnpmResult); err != nil {
-		result.Error = "Failed to parse pnpm audit output"
-		return result
+	stored, err := logic.ListFindings()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	for _, adv := range pnpmResult.Advisories {
-		severity := normalizeSeverity(adv.Severity)
-
-		result.Findings = append(result.Findings, CVEFinding{
-			CVE:         fmt.Sprintf("pnpm:%d", adv.ID),
-			Severity:    severity,
-			Package:     adv.ModuleName,
-			Version:     adv.VulnerableVersions,
-			FixedIn:     adv.PatchedVersions,
-			Description: truncateString(adv.Title, 200),
-		})
+	rootPath := filepath.Clean(r.URL.Query().Get("rootPath"))
+	result := []RepoFindingsSummary{}
+	for _, rf := range stored {
+		if r.URL.Query().Get("rootPath") != "" && rf.RepoPath != rootPath && !strings.HasPrefix(rf.RepoPath, rootPath+string(filepath.Separator)) {
+			continue
+		}
+		summary := RepoFindingsSummary{
+			RepoName: rf.RepoName,
+			RepoPath: rf.RepoPath,
+			Sources:  make(map[string]time.Time),
+			Findings: rf.Consolidated(),
+		}
+		for source, sf := range rf.Sources {
+			summary.Sources[source] = sf.UpdatedAt
+		}
+		result = append(result, summary)
 	}
 
-	return result
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
 }
 
-func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
+// handleFindingsDelta reports the findings that appeared and disappeared since a point in time, from
+// the scan history: GET /api/findings/delta?days=7&severity=CRITICAL&rootPath=... ("net new CRITICALs
+// this week"). days defaults to 7, severity to all findings.
+func handleFindingsDelta(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
-	return s[:maxLen-3] + "..."
+	days := 7
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "days must be a positive number", http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+	severity := strings.ToUpper(r.URL.Query().Get("severity"))
+	if severity != "" && !logic.ValidSeverity(severity) {
+		http.Error(w, "severity must be CRITICAL, HIGH, MEDIUM or LOW", http.StatusBadRequest)
+		return
+	}
+
+	histories, err := logic.ListScanHistories()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if r.URL.Query().Get("rootPath") != "" {
+		rootPath := filepath.Clean(r.URL.Query().Get("rootPath"))
+		histories = slices.DeleteFunc(histories, func(h logic.ScanHistory) bool {
+			return h.RepoPath != rootPath && !strings.HasPrefix(h.RepoPath, rootPath+string(filepath.Separator))
+		})
+	}
+
+	report := logic.NewFindingsSince(histories, time.Now().AddDate(0, 0, -days), severity)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
 }
 
-// runGovulncheck runs govulncheck for Go projects
-func runGovulncheck(repoPath, repoName string) RepoSecurityResult {
-	result := RepoSecurityResult{RepoName: repoName, ProjectType: "go"}
+// FindingsImportRequest imports the hosting platform's vulnerability alerts for the local repos
+type FindingsImportRequest struct {
+	RootPath  string   `json:"rootPath"`
+	RootPaths []string `json:"rootPaths"` // Further roots searched together with RootPath
+	Excluded  []string `json:"excluded"`
+	Group     string   `json:"group"`    // Optional repository group instead of RootPath
+	Provider  string   `json:"provider"` // "github" (Dependabot alerts) or "gitlab" (dependency scanning)
+	Token     string   `json:"token"`
+	BaseURL   string   `json:"baseUrl"` // GitLab instance, empty for gitlab.com
+}
 
-	// Check if govulncheck is available
-	if !checkGovulncheckAvailable() {
-		result.Error = "govulncheck not installed. Install with: go install golang.org/x/vuln/cmd/govulncheck@latest"
-		return result
+// handleFindingsImport pulls Dependabot alerts or GitLab dependency scanning findings for every
+// repo whose origin points to the provider and stores them next to the local scan results
+func handleFindingsImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
 
-	// Run govulncheck with JSON output
-	cmd := exec.Command("govulncheck", "-json", "./...")
-	cmd.Dir = repoPath
-	output, err := cmd.Output()
+	var req FindingsImportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	// govulncheck returns exit code 3 if vulnerabilities found
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			if exitErr.ExitCode() != 3 && len(output) == 0 {
-				result.Error = fmt.Sprintf("govulncheck failed: %v", err)
-				return result
+	var source, host string
+	var fetch func(path string) ([]providers.PlatformAlert, error)
+	switch req.Provider {
+	case "github":
+		source, host = "dependabot", "github.com"
+		client := providers.NewGitHubClient(req.Token)
+		fetch = client.ListDependabotAlerts
+	case "gitlab":
+		source, host = "gitlab", "gitlab.com"
+		if req.BaseURL != "" {
+			if u, err := url.Parse(req.BaseURL); err == nil && u.Host != "" {
+				host = strings.ToLower(u.Hostname())
 			}
-		} else if len(output) == 0 {
-			result.Error = fmt.Sprintf("govulncheck failed: %v", err)
-			return result
 		}
+		client := providers.NewGitLabClient(req.BaseURL, req.Token)
+		fetch = client.ListDependencyScanningFindings
+	default:
+		http.Error(w, "provider must be 'github' or 'gitlab'", http.StatusBadRequest)
+		return
 	}
 
-	// Parse JSON output (NDJSON format - one JSON object per line)
-	lines := strings.Split(string(output), "\n")
-	vulnMap := make(map[string]CVEFinding) // Deduplicate by CVE
+	repos, err := resolveRepos(req.RootPath, req.RootPaths, req.Excluded, req.Group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
+	// Set headers for streaming
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	log := func(msg string) {
+		fmt.Fprintln(w, msg)
+		flusher.Flush()
+	}
+
+	imported := 0
+	for _, repoPath := range repos {
+		repoName := filepath.Base(repoPath)
+
+		remote := logic.RemoteURL(repoPath)
+		if remote == "" {
 			continue
 		}
-
-		var entry struct {
-			Finding *struct {
-				OSV   string `json:"osv"`
-				Trace []struct {
-					Module  string `json:"module"`
-					Version string `json:"version"`
-					Package string `json:"package"`
-				} `json:"trace"`
-			} `json:"finding"`
-			OSV *struct {
-				ID       string `json:"id"`
-				Summary  string `json:"summary"`
-				Severity []struct {
-					Type  string `json:"type"`
-					Score string `json:"score"`
-				} `json:"severity"`
-				Affected []struct {
-					Package struct {
-						Name string `json:"name"`
-					} `json:"package"`
-					Ranges []struct {
-						Events []struct {
-							Fixed string `json:"fixed"`
-						} `json:"events"`
-					} `json:"ranges"`
-				} `json:"affected"`
-			} `json:"osv"`
+		remoteHost, remotePath, ok := providers.ParseRemoteURL(remote)
+		if !ok || remoteHost != host {
+			continue
 		}
 
-		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		log(fmt.Sprintf("REPO_START:%s", repoName))
+		alerts, err := fetch(remotePath)
+		if err != nil {
+			log(fmt.Sprintf("  [ERROR] %s: %v", remotePath, err))
+			log(fmt.Sprintf("REPO_DONE:%s", repoName))
 			continue
 		}
 
-		// Process OSV entries (vulnerability details)
-		if entry.OSV != nil {
-			osv := entry.OSV
-			severity := "MEDIUM" // Default
+		findings := make([]logic.SecurityFinding, 0, len(alerts))
+		for _, a := range alerts {
+			findings = append(findings, logic.SecurityFinding{
+				CVE:         a.ID,
+				Severity:    a.Severity,
+				CVSSScore:   a.CVSSScore,
+				Package:     a.Package,
+				Version:     a.Version,
+				FixedIn:     a.FixedIn,
+				Description: truncateString(a.Summary, 200),
+				URL:         a.URL,
+			})
+		}
+		if err := logic.SaveFindings(repoPath, source, findings); err != nil {
+			log(fmt.Sprintf("  [ERROR] Could not store findings: %v", err))
+		} else {
+			imported++
+			log(fmt.Sprintf("  Imported %d %s alerts.", len(findings), source))
+		}
+		log(fmt.Sprintf("REPO_DONE:%s", repoName))
+	}
 
-			// Parse CVSS score if available
-			for _, sev := range osv.Severity {
-				if sev.Type == "CVSS_V3" {
-					if score, err := strconv.ParseFloat(sev.Score, 64); err == nil {
-						if score >= 9.0 {
-							severity = "CRITICAL"
-						} else if score >= 7.0 {
-							severity = "HIGH"
-						} else if score >= 4.0 {
-							severity = "MEDIUM"
-						} else {
-							severity = "LOW"
-						}
-					}
-				}
-			}
+	log(fmt.Sprintf("IMPORT_COMPLETE:%d", imported))
+}
 
-			// Get package name and fixed version
-			pkgName := ""
-			fixedIn := ""
-			if len(osv.Affected) > 0 {
-				pkgName = osv.Affected[0].Package.Name
-				for _, r := range osv.Affected[0].Ranges {
-					for _, ev := range r.Events {
-						if ev.Fixed != "" {
-							fixedIn = ev.Fixed
-						}
-					}
-				}
-			}
+// ==================== SERVER LOG ====================
 
-			vulnMap[osv.ID] = CVEFinding{
-				CVE:         osv.ID,
-				Severity:    severity,
-				Package:     pkgName,
-				FixedIn:     fixedIn,
-				Description: truncateString(osv.Summary, 200),
-			}
+// withRequestLog logs every API request at debug level
+func withRequestLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") || r.URL.Path == "/api/health" || r.URL.Path == "/api/logs/tail" {
+			next.ServeHTTP(w, r)
+			return
 		}
+		start := time.Now()
+		next.ServeHTTP(w, r)
+		slog.Debug("HTTP request", "method", r.Method, "path", r.URL.Path, "duration", time.Since(start).Round(time.Millisecond))
+	})
+}
 
-		// Process finding entries (actual usage in code)
-		if entry.Finding != nil && len(entry.Finding.Trace) > 0 {
-			trace := entry.Finding.Trace[0]
-			if existing, ok := vulnMap[entry.Finding.OSV]; ok {
-				existing.Version = trace.Version
-				if existing.Package == "" {
-					existing.Package = trace.Module
-				}
-				vulnMap[entry.Finding.OSV] = existing
-			}
+// handleLogsTail returns the latest server log entries: GET /api/logs/tail?lines=200&level=warn
+func handleLogsTail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	lines := 200
+	if value := r.URL.Query().Get("lines"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			http.Error(w, "lines must be a positive number", http.StatusBadRequest)
+			return
 		}
+		lines = n
+	}
+	level, err := logic.ParseLogLevel(r.URL.Query().Get("level"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if r.URL.Query().Get("level") == "" {
+		level = slog.LevelDebug
 	}
 
-	// Convert map to slice
-	for _, finding := range vulnMap {
-		result.Findings = append(result.Findings, finding)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(logic.TailLogs(lines, level))
+}
+
+// handleCommandLimits returns the limit and usage of every external process class: GET /api/command-limits
+func handleCommandLimits(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cmdlimit.Usage())
+}
 
-	return result
+// ==================== AUTHENTICATION ====================
+
+// serverAuth is the authentication of the running server (disabled when nil or unconfigured)
+var serverAuth *logic.Authenticator
+
+// readOnlyEndpoints are the POST endpoints the read-only role may call: they analyze repositories
+// without changing them or the stored state. Everything else that is not GET or HEAD needs the
+// admin role, including analyses with side effects (dashboard-stats stores a snapshot,
+// outdated-maven runs Maven plugins).
+var readOnlyEndpoints = map[string]bool{
+	"/api/validate-replacements": true,
+	"/api/preview-replacements":  true,
+	"/api/scan-spring":           true,
+	"/api/list-folders":          true,
+	"/api/dashboard-watch":       true,
+	"/api/list-branches":         true,
+	"/api/repo-status":           true,
+	"/api/stashes":               true,
+	"/api/git-auth-check":        true,
+	"/api/remote-branches":       true,
+	"/api/list-tags":             true,
+	"/api/release/plan":          true,
+	"/api/gitignore-audit":       true,
+	"/api/github/repos":          true,
+	"/api/gitlab/repos":          true,
 }
 
-// runPipAudit runs pip-audit for Python projects
-func runPipAudit(repoPath, repoName string) RepoSecurityResult {
-	result := RepoSecurityResult{RepoName: repoName, ProjectType: "python"}
+type sessionKey struct{}
 
-	// Check if pip-audit is available
-	if !checkPipAuditAvailable() {
-		result.Error = "pip-audit not installed. Install with: pip install pip-audit"
-		return result
+// withAuth requires credentials for every request except the health check. Browser sessions
+// (basic auth) must send the session's CSRF token with state-changing requests; bearer token
+// clients carry no ambient credentials and are exempt.
+func withAuth(auth *logic.Authenticator, next http.Handler) http.Handler {
+	if !auth.Enabled() {
+		return next
 	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/health" {
+			next.ServeHTTP(w, r)
+			return
+		}
 
-	// Determine which requirements file to use
-	var requirementsArg string
-	if _, err := os.Stat(filepath.Join(repoPath, "requirements.txt")); err == nil {
-		requirementsArg = "-r requirements.txt"
-	} else if _, err := os.Stat(filepath.Join(repoPath, "pyproject.toml")); err == nil {
-		// pip-audit can handle pyproject.toml directly in the directory
-		requirementsArg = ""
-	} else {
-		result.Error = "No requirements.txt or pyproject.toml found"
-		return result
-	}
+		user, role, bearer, ok := auth.Authenticate(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="GitHousekeeper", charset="UTF-8"`)
+			http.Error(w, "Authentication required", http.StatusUnauthorized)
+			return
+		}
 
-	// Run pip-audit with JSON output
-	var cmd *exec.Cmd
-	if requirementsArg != "" {
-		cmd = exec.Command("pip-audit", "-r", "requirements.txt", "--format", "json", "--progress-spinner=off")
-	} else {
-		cmd = exec.Command("pip-audit", "--format", "json", "--progress-spinner=off")
-	}
-	cmd.Dir = repoPath
-	output, err := cmd.Output()
+		readOnly := r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions
+		if !readOnly && role != logic.RoleAdmin && !readOnlyEndpoints[r.URL.Path] {
+			http.Error(w, "Read-only access", http.StatusForbidden)
+			return
+		}
 
-	// pip-audit returns exit code 1 if vulnerabilities found
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			if exitErr.ExitCode() != 1 && len(output) == 0 {
-				// Check stderr for more info
-				if len(exitErr.Stderr) > 0 {
-					result.Error = fmt.Sprintf("pip-audit failed: %s", string(exitErr.Stderr))
-				} else {
-					result.Error = fmt.Sprintf("pip-audit failed: %v", err)
-				}
-				return result
+		if !bearer {
+			session := auth.Session(w, r, user, role)
+			if !readOnly && !session.ValidCSRF(r.Header.Get("X-CSRF-Token")) {
+				http.Error(w, "Missing or invalid CSRF token, reload the page", http.StatusForbidden)
+				return
 			}
-		} else if len(output) == 0 {
-			result.Error = fmt.Sprintf("pip-audit failed: %v", err)
-			return result
+			r = r.WithContext(context.WithValue(r.Context(), sessionKey{}, session))
 		}
-	}
+		next.ServeHTTP(w, r)
+	})
+}
 
-	// Parse pip-audit JSON output
-	var pipResult struct {
-		Dependencies []struct {
-			Name    string `json:"name"`
-			Version string `json:"version"`
-			Vulns   []struct {
-				ID          string   `json:"id"`
-				FixVersions []string `json:"fix_versions"`
-				Description string   `json:"description"`
-			} `json:"vulns"`
-		} `json:"dependencies"`
+// handleSession returns the user, role and CSRF token of the browser session: GET /api/session
+func handleSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
-
-	// pip-audit outputs an array directly
-	var vulnArray []struct {
-		Name    string `json:"name"`
-		Version string `json:"version"`
-		Vulns   []struct {
-			ID          string   `json:"id"`
-			FixVersions []string `json:"fix_versions"`
-			Description string   `json:"description"`
-		} `json:"vulns"`
+	response := struct {
+		AuthEnabled bool `json:"authEnabled"`
+		*logic.Session
+	}{AuthEnabled: serverAuth.Enabled()}
+	if session, ok := r.Context().Value(sessionKey{}).(*logic.Session); ok {
+		response.Session = session
+	} else {
+		response.Session = &logic.Session{User: "local", Role: logic.RoleAdmin}
 	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
 
-	// Try parsing as array first (newer pip-audit format)
-	if err := json.Unmarshal(output, &vulnArray); err == nil {
-		for _, dep := range vulnArray {
-			for _, vuln := range dep.Vulns {
-				severity := determinePythonSeverity(vuln.ID)
-				fixedIn := ""
-				if len(vuln.FixVersions) > 0 {
-					fixedIn = vuln.FixVersions[len(vuln.FixVersions)-1] // Latest fix version
-				}
+// ==================== PATH SANDBOX ====================
 
-				result.Findings = append(result.Findings, CVEFinding{
-					CVE:         vuln.ID,
-					Severity:    severity,
-					Package:     dep.Name,
-					Version:     dep.Version,
-					FixedIn:     fixedIn,
-					Description: truncateString(vuln.Description, 200),
-				})
-			}
-		}
-		return result
-	}
+// pathSandbox is the allowlist configured at startup (empty = unrestricted)
+var pathSandbox = &logic.PathSandbox{}
 
-	// Try parsing as object (older format)
-	if err := json.Unmarshal(output, &pipResult); err != nil {
-		result.Error = fmt.Sprintf("Failed to parse pip-audit output: %v", err)
-		return result
-	}
+// sandboxedPathKeys are the request fields (JSON body or query, case-insensitive) holding filesystem paths
+var sandboxedPathKeys = map[string]bool{"rootpath": true, "rootpaths": true, "path": true, "repopath": true, "settingsfile": true, "mavenhome": true, "datadirectory": true}
 
-	for _, dep := range pipResult.Dependencies {
-		for _, vuln := range dep.Vulns {
-			severity := determinePythonSeverity(vuln.ID)
-			fixedIn := ""
-			if len(vuln.FixVersions) > 0 {
-				fixedIn = vuln.FixVersions[len(vuln.FixVersions)-1]
-			}
+// maxSandboxedBody limits how much of a request body is buffered for the path check
+const maxSandboxedBody = 10 << 20
 
-			result.Findings = append(result.Findings, CVEFinding{
-				CVE:         vuln.ID,
-				Severity:    severity,
-				Package:     dep.Name,
-				Version:     dep.Version,
-				FixedIn:     fixedIn,
-				Description: truncateString(vuln.Description, 200),
-			})
-		}
+// withSlashPaths rewrites the Windows paths in JSON responses to forward slashes, so the UI and API clients
+// see the same separator on every platform. Forward slashes are accepted wherever a path is sent back.
+func withSlashPaths(next http.Handler) http.Handler {
+	if filepath.Separator != '\\' {
+		return next
 	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		sw := &slashPathsWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r)
+		sw.finish()
+	})
+}
 
-	return result
+// slashPathsWriter buffers JSON responses for withSlashPaths; streamed text responses pass through
+type slashPathsWriter struct {
+	http.ResponseWriter
+	decided bool
+	json    *bytes.Buffer // Buffered body of a JSON response
+	status  int
 }
 
-// determinePythonSeverity determines severity from CVE/PYSEC ID
-// Since pip-audit doesn't always include severity, we default to MEDIUM
-// but could be enhanced with OSV API lookup
-func determinePythonSeverity(id string) string {
-	// pip-audit doesn't provide severity directly
-	// Could be enhanced to lookup from OSV API
-	// For now, use heuristics based on ID prefix
-	if strings.HasPrefix(id, "GHSA-") {
-		// GitHub Security Advisories are usually at least MEDIUM
-		return "MEDIUM"
+func (w *slashPathsWriter) decide() {
+	if !w.decided {
+		w.decided = true
+		if strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+			w.json = &bytes.Buffer{}
+		}
 	}
-	if strings.HasPrefix(id, "CVE-") || strings.HasPrefix(id, "PYSEC-") {
-		return "MEDIUM"
+}
+
+func (w *slashPathsWriter) WriteHeader(status int) {
+	w.decide()
+	if w.json != nil {
+		w.status = status
+		return
 	}
-	return "LOW"
+	w.ResponseWriter.WriteHeader(status)
 }
 
-// runComposerAudit runs composer audit for PHP projects
-func runComposerAudit(repoPath, repoName string) RepoSecurityResult {
-	result := RepoSecurityResult{RepoName: repoName, ProjectType: "php"}
+func (w *slashPathsWriter) Write(p []byte) (int, error) {
+	w.decide()
+	if w.json != nil {
+		return w.json.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
 
-	// Check if composer is available
-	if !checkComposerAvailable() {
-		result.Error = "Composer not installed. Install from: https://getcomposer.org/download/"
-		return result
+func (w *slashPathsWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok && w.json == nil {
+		flusher.Flush()
 	}
+}
 
-	// Check if composer.json exists
-	composerPath := filepath.Join(repoPath, "composer.json")
-	if _, err := os.Stat(composerPath); os.IsNotExist(err) {
-		result.Error = "No composer.json found"
-		return result
+func (w *slashPathsWriter) finish() {
+	if w.json == nil {
+		return
 	}
+	w.Header().Del("Content-Length")
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	w.ResponseWriter.Write(logic.SlashPathsJSON(w.json.Bytes()))
+}
 
-	// Run composer audit with JSON output
-	cmd := exec.Command("composer", "audit", "--format=json")
-	cmd.Dir = repoPath
-	output, err := cmd.Output()
+// withWorkspace fills in the fields of API requests referring to a workspace ("workspace": "<id>" in a JSON
+// body or ?workspace=<id>): root path, exclusions, the provider token and the workspace defaults are used
+// wherever the request leaves them unset or empty. It runs before withPathSandbox, so workspace paths are checked too.
+func withWorkspace(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") || strings.HasPrefix(r.URL.Path, "/api/workspaces") {
+			next.ServeHTTP(w, r)
+			return
+		}
 
-	// composer audit returns exit code 1 if vulnerabilities found
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			if exitErr.ExitCode() != 1 && len(output) == 0 {
-				// Check stderr for more info
-				if len(exitErr.Stderr) > 0 {
-					result.Error = fmt.Sprintf("composer audit failed: %s", string(exitErr.Stderr))
-				} else {
-					result.Error = fmt.Sprintf("composer audit failed: %v", err)
+		if id := r.URL.Query().Get("workspace"); id != "" {
+			workspace, err := logic.LoadWorkspace(id)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			query := r.URL.Query()
+			for key, value := range workspace.RequestFields(requestProvider(r.URL.Path, nil)) {
+				if s, ok := value.(string); ok && !hasQueryKey(query, key) {
+					query.Set(key, s)
 				}
-				return result
 			}
-		} else if len(output) == 0 {
-			result.Error = fmt.Sprintf("composer audit failed: %v", err)
-			return result
+			r.URL.RawQuery = query.Encode()
+		}
+
+		if r.Body == nil || r.Method == http.MethodGet || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxSandboxedBody+1))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(body) > maxSandboxedBody {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		var payload map[string]interface{}
+		if decoder.Decode(&payload) != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		var id string
+		for key, value := range payload {
+			if strings.EqualFold(key, "workspace") {
+				id, _ = value.(string)
+			}
+		}
+		if id == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		workspace, err := logic.LoadWorkspace(id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		applyWorkspaceFields(payload, workspace.RequestFields(requestProvider(r.URL.Path, payload)))
+		body, err = json.Marshal(payload)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestProvider returns the hosting provider whose token an API request needs, "" for none
+func requestProvider(path string, payload map[string]interface{}) string {
+	switch {
+	case strings.HasPrefix(path, "/api/github/"):
+		return logic.ProviderGitHub
+	case strings.HasPrefix(path, "/api/gitlab/"):
+		return logic.ProviderGitLab
+	}
+	for key, value := range payload {
+		if provider, ok := value.(string); ok && strings.EqualFold(key, "provider") {
+			return provider
 		}
 	}
+	return ""
+}
 
-	// Parse composer audit JSON output
-	var auditResult struct {
-		Advisories map[string][]struct {
-			AdvisoryID       string `json:"advisoryId"`
-			PackageName      string `json:"packageName"`
-			AffectedVersions string `json:"affectedVersions"`
-			Title            string `json:"title"`
-			CVE              string `json:"cve"`
-			Link             string `json:"link"`
-			ReportedAt       string `json:"reportedAt"`
-			Severity         string `json:"severity"`
-		} `json:"advisories"`
+// applyWorkspaceFields sets the workspace fields a request payload does not set (keys are matched
+// case-insensitively like encoding/json does)
+func applyWorkspaceFields(payload map[string]interface{}, fields map[string]interface{}) {
+	present := make(map[string]string, len(payload))
+	for key := range payload {
+		present[strings.ToLower(key)] = key
+	}
+	for key, value := range fields {
+		original, ok := present[strings.ToLower(key)]
+		if !ok {
+			payload[key] = value
+			continue
+		}
+		if isEmptyJSONValue(payload[original]) {
+			payload[original] = value
+		}
 	}
+}
 
-	if err := json.Unmarshal(output, &auditResult); err != nil {
-		// Check if it's empty (no vulnerabilities)
-		if len(output) == 0 || string(output) == "{}" || string(output) == "{\"advisories\":[]}" {
-			return result // No vulnerabilities
+func isEmptyJSONValue(v interface{}) bool {
+	switch value := v.(type) {
+	case nil:
+		return true
+	case string:
+		return value == ""
+	case []interface{}:
+		return len(value) == 0
+	}
+	return false
+}
+
+func hasQueryKey(query url.Values, key string) bool {
+	for k, values := range query {
+		if strings.EqualFold(k, key) && len(values) > 0 && values[0] != "" {
+			return true
 		}
-		result.Error = fmt.Sprintf("Failed to parse composer audit output: %v", err)
-		return result
 	}
+	return false
+}
 
-	// Process advisories
-	for packageName, advisories := range auditResult.Advisories {
-		for _, advisory := range advisories {
-			cveID := advisory.CVE
-			if cveID == "" {
-				cveID = advisory.AdvisoryID
+// withPathSandbox rejects API requests referring to paths outside the allowed roots before they
+// reach a handler, so no handler can walk, modify or commit outside of them.
+func withPathSandbox(sandbox *logic.PathSandbox, next http.Handler) http.Handler {
+	if !sandbox.Enabled() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var paths []string
+		for key, values := range r.URL.Query() {
+			if sandboxedPathKeys[strings.ToLower(key)] {
+				paths = append(paths, values...)
 			}
+		}
 
-			severity := strings.ToUpper(advisory.Severity)
-			if severity == "" {
-				severity = "MEDIUM"
+		if r.Body != nil && r.Method != http.MethodGet && r.Method != http.MethodHead {
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxSandboxedBody+1))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if len(body) > maxSandboxedBody {
+				http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+				return
 			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
 
-			result.Findings = append(result.Findings, CVEFinding{
-				CVE:         cveID,
-				Severity:    severity,
-				Package:     packageName,
-				Version:     advisory.AffectedVersions,
-				Description: truncateString(advisory.Title, 200),
-			})
+			var payload interface{}
+			if json.Unmarshal(body, &payload) == nil {
+				paths = append(paths, collectRequestPaths(payload, "", "")...)
+			}
 		}
-	}
 
-	return result
+		for _, p := range paths {
+			if p == "" {
+				continue
+			}
+			if err := sandbox.Check(p); err != nil {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// collectRequestPaths returns the path values of a decoded JSON payload: all sandboxedPathKeys at
+// any depth, plus every entry of "repos" lists. Relative repos entries (folder or remote repo names)
+// are resolved against the rootPath next to them, as the handlers do, else against the working directory.
+func collectRequestPaths(v interface{}, key, rootPath string) []string {
+	var paths []string
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, child := range value {
+			if root, ok := child.(string); ok && strings.EqualFold(k, "rootPath") && root != "" {
+				rootPath = root
+			}
+		}
+		for k, child := range value {
+			paths = append(paths, collectRequestPaths(child, strings.ToLower(k), rootPath)...)
+		}
+	case []interface{}:
+		for _, child := range value {
+			paths = append(paths, collectRequestPaths(child, key, rootPath)...)
+		}
+	case string:
+		switch {
+		case sandboxedPathKeys[key]:
+			paths = append(paths, value)
+		case key == "repos" && value != "":
+			if !filepath.IsAbs(value) && rootPath != "" {
+				value = filepath.Join(rootPath, value)
+			}
+			if abs, err := filepath.Abs(value); err == nil {
+				value = abs
+			}
+			paths = append(paths, filepath.Clean(value))
+		}
+	}
+	return paths
 }
@@ -2,9 +2,16 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gorecode/updates/internal/logic"
 )
@@ -193,3 +200,877 @@ func containsNewline(s string) bool {
 	}
 	return false
 }
+
+// ===========================================
+// Tests for Time-Boxed Runs
+// ===========================================
+
+func TestHandleRunResume(t *testing.T) {
+	t.Setenv(logic.DataDirEnv, t.TempDir())
+
+	request, _ := json.Marshal(RunRequest{RootPath: "/nonexistent", MaxDurationMinutes: 30})
+	prev := &logic.RunRecord{
+		ID:        "20250301-020000-0001",
+		Remaining: []string{"/nonexistent/repo-b"},
+		Request:   request,
+	}
+	if err := logic.SaveRunRecord(prev); err != nil {
+		t.Fatalf("SaveRunRecord failed: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/runs/"+prev.ID+"/resume", nil)
+	rr := httptest.NewRecorder()
+	handleRunDetail(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if !strings.Contains(rr.Body.String(), "Resuming run "+prev.ID+" with 1 remaining") {
+		t.Errorf("Unexpected output: %s", rr.Body.String())
+	}
+
+	saved, err := logic.LoadRunRecord(prev.ID)
+	if err != nil {
+		t.Fatalf("LoadRunRecord failed: %v", err)
+	}
+	if saved.ResumedBy == "" || saved.Resumable() {
+		t.Error("Expected run to be marked as resumed")
+	}
+
+	// A second resume must be rejected
+	rr = httptest.NewRecorder()
+	handleRunDetail(rr, httptest.NewRequest(http.MethodPost, "/api/runs/"+prev.ID+"/resume", nil))
+	if rr.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for second resume, got %d", rr.Code)
+	}
+}
+
+func TestHandleRerun_OnlyFailed(t *testing.T) {
+	t.Setenv(logic.DataDirEnv, t.TempDir())
+
+	request, _ := json.Marshal(RunRequest{RootPath: "/nonexistent", TargetBranch: "housekeeping"})
+	prev := &logic.RunRecord{
+		ID: "20250301-020000-0002",
+		Results: []logic.RepoResult{
+			{RepoPath: "/nonexistent/repo-a", Status: logic.RepoStatusSuccess},
+			{RepoPath: "/nonexistent/repo-b", Status: logic.RepoStatusFailed},
+			{RepoPath: "/nonexistent/repo-c", Status: logic.RepoStatusSkipped},
+		},
+		Request: request,
+	}
+	if err := logic.SaveRunRecord(prev); err != nil {
+		t.Fatalf("SaveRunRecord failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	handleRerun(rr, httptest.NewRequest(http.MethodPost, "/api/rerun/"+prev.ID+"?only=failed", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if !strings.Contains(rr.Body.String(), "Re-running 2 repositories of run "+prev.ID) {
+		t.Errorf("Unexpected output: %s", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	handleRerun(rr, httptest.NewRequest(http.MethodPost, "/api/rerun/"+prev.ID, nil))
+	if !strings.Contains(rr.Body.String(), "Re-running 3 repositories") {
+		t.Errorf("Expected all repos without a selection, got: %s", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	handleRerun(rr, httptest.NewRequest(http.MethodPost, "/api/rerun/"+prev.ID+"?only=broken", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown selection, got %d", rr.Code)
+	}
+
+	// Nothing to do when every repo succeeded
+	prev.Results = prev.Results[:1]
+	logic.SaveRunRecord(prev)
+	rr = httptest.NewRecorder()
+	handleRerun(rr, httptest.NewRequest(http.MethodPost, "/api/rerun/"+prev.ID+"?only=failed", nil))
+	if rr.Code != http.StatusConflict {
+		t.Errorf("Expected 409 without failed repos, got %d", rr.Code)
+	}
+}
+
+// ===========================================
+// Tests for Run Profiles
+// ===========================================
+
+func TestHandleProfiles(t *testing.T) {
+	t.Setenv(logic.DataDirEnv, t.TempDir())
+
+	body := `{"name": "Monthly Housekeeping", "request": {"rootPath": "/nonexistent", "targetBranch": "housekeeping",
+		"runCleanInstall": true, "replacements": [{"Search": "a", "Replace": "b"}]}}`
+	rr := httptest.NewRecorder()
+	handleProfiles(rr, httptest.NewRequest(http.MethodPost, "/api/profiles", strings.NewReader(body)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	handleProfiles(rr, httptest.NewRequest(http.MethodGet, "/api/profiles", nil))
+	var profiles []logic.RunProfile
+	if err := json.Unmarshal(rr.Body.Bytes(), &profiles); err != nil || len(profiles) != 1 {
+		t.Fatalf("Expected one profile, got %s", rr.Body.String())
+	}
+	var stored RunRequest
+	json.Unmarshal(profiles[0].Request, &stored)
+	if profiles[0].ID != "monthly-housekeeping" || stored.TargetBranch != "housekeeping" || !stored.RunCleanInstall || len(stored.Replacements) != 1 {
+		t.Errorf("Unexpected profile: %+v / %+v", profiles[0], stored)
+	}
+
+	rr = httptest.NewRecorder()
+	handleProfileDetail(rr, httptest.NewRequest(http.MethodPost, "/api/profiles/monthly-housekeeping/run", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "Running profile 'Monthly Housekeeping'") {
+		t.Errorf("Unexpected run response %d: %s", rr.Code, rr.Body.String())
+	}
+
+	// Invalid regex rules are rejected when saving
+	rr = httptest.NewRecorder()
+	handleProfiles(rr, httptest.NewRequest(http.MethodPost, "/api/profiles", strings.NewReader(`{"name": "Broken", "request": {"replacements": [{"Search": "(", "Type": "regex"}]}}`)))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid regex, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	handleProfileDetail(rr, httptest.NewRequest(http.MethodDelete, "/api/profiles/monthly-housekeeping", nil))
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	handleProfileDetail(rr, httptest.NewRequest(http.MethodGet, "/api/profiles/monthly-housekeeping", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 after delete, got %d", rr.Code)
+	}
+}
+
+func TestHandleGroups(t *testing.T) {
+	t.Setenv(logic.DataDirEnv, t.TempDir())
+	root := t.TempDir()
+	for _, dir := range []string{"billing", "team/ledger", "team/payouts", "other"} {
+		os.MkdirAll(filepath.Join(root, dir), 0755)
+		runGit(t, filepath.Join(root, dir), "init", "-q")
+	}
+
+	// Relative entries are resolved against the root path, a folder entry covers all repos below it
+	body := `{"name": "Payments Team", "rootPath": "` + filepath.ToSlash(root) + `", "repos": ["billing", "team", "billing"]}`
+	rr := httptest.NewRecorder()
+	handleGroups(rr, httptest.NewRequest(http.MethodPost, "/api/groups", strings.NewReader(body)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var group logic.RepoGroup
+	json.Unmarshal(rr.Body.Bytes(), &group)
+	if group.ID != "payments-team" || len(group.Repos) != 2 {
+		t.Errorf("Unexpected group: %+v", group)
+	}
+
+	repos, err := resolveRepos(root, nil, []string{"payouts"}, "payments-team")
+	if err != nil {
+		t.Fatalf("resolveRepos failed: %v", err)
+	}
+	expected := []string{filepath.Join(root, "billing"), filepath.Join(root, "team", "ledger")}
+	if strings.Join(repos, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, repos)
+	}
+	if _, err := resolveRepos(root, nil, nil, "unknown"); err == nil {
+		t.Error("Expected an error for an unknown group")
+	}
+
+	rr = httptest.NewRecorder()
+	handleGroups(rr, httptest.NewRequest(http.MethodPost, "/api/groups", strings.NewReader(`{"name": "Empty", "repos": []}`)))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a group without repos, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	handleGroupDetail(rr, httptest.NewRequest(http.MethodDelete, "/api/groups/payments-team", nil))
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	handleGroupDetail(rr, httptest.NewRequest(http.MethodGet, "/api/groups/payments-team", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 after delete, got %d", rr.Code)
+	}
+}
+
+func TestHandleWorkspaces(t *testing.T) {
+	t.Setenv(logic.DataDirEnv, t.TempDir())
+	root := filepath.ToSlash(t.TempDir())
+
+	body := `{"name": "Java Services", "rootPath": "` + root + `", "excluded": ["legacy"], "credentials": {"githubToken": "ghp_secret"},
+		"defaults": {"settingsFile": "/home/me/work-settings.xml", "dryRun": true}}`
+	rr := httptest.NewRecorder()
+	handleWorkspaces(rr, httptest.NewRequest(http.MethodPost, "/api/workspaces", strings.NewReader(body)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if strings.Contains(rr.Body.String(), "ghp_secret") {
+		t.Error("Expected the token to be redacted in the response")
+	}
+
+	// Saving again without a token keeps the stored one
+	rr = httptest.NewRecorder()
+	handleWorkspaces(rr, httptest.NewRequest(http.MethodPost, "/api/workspaces", strings.NewReader(`{"name": "Java Services", "rootPath": "`+root+`", "excluded": ["legacy"],
+		"defaults": {"settingsFile": "/home/me/work-settings.xml", "dryRun": true}}`)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if ws, err := logic.LoadWorkspace("java-services"); err != nil || ws.Credentials.GitHubToken != "ghp_secret" {
+		t.Fatalf("Expected the stored token to be kept, got %+v, %v", ws, err)
+	}
+
+	rr = httptest.NewRecorder()
+	handleWorkspaces(rr, httptest.NewRequest(http.MethodPost, "/api/workspaces", strings.NewReader(`{"name": "Relative", "rootPath": "work"}`)))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a relative root path, got %d", rr.Code)
+	}
+
+	var received map[string]interface{}
+	var receivedQuery string
+	handler := withWorkspace(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = nil
+		receivedQuery = r.URL.Query().Get("rootPath")
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+
+	// Unset and empty fields come from the workspace, set ones are kept
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/run", strings.NewReader(`{"workspace": "java-services", "RootPath": "", "dryRun": false}`)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if received["RootPath"] != root || received["settingsFile"] != "/home/me/work-settings.xml" || received["dryRun"] != false {
+		t.Errorf("Unexpected request after applying the workspace: %v", received)
+	}
+	if excluded, _ := received["excluded"].([]interface{}); len(excluded) != 1 || received["token"] != nil {
+		t.Errorf("Expected the exclusions and no token, got %v", received)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/github/repos", strings.NewReader(`{"workspace": "java-services", "org": "acme"}`)))
+	if received["token"] != "ghp_secret" || received["org"] != "acme" {
+		t.Errorf("Expected the GitHub token of the workspace, got %v", received)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/findings?workspace=java-services", nil))
+	if receivedQuery != root {
+		t.Errorf("Expected the root path in the query, got %q", receivedQuery)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/run", strings.NewReader(`{"workspace": "unknown"}`)))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown workspace, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	handleWorkspaceDetail(rr, httptest.NewRequest(http.MethodDelete, "/api/workspaces/java-services", nil))
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	handleWorkspaceDetail(rr, httptest.NewRequest(http.MethodGet, "/api/workspaces/java-services", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 after delete, got %d", rr.Code)
+	}
+}
+
+// ===========================================
+// Tests for cargo audit Output Parsing
+// ===========================================
+
+func TestParseCargoAuditOutput(t *testing.T) {
+	output := []byte(`{"database":{"advisory-count":900},"lockfile":{"dependency-count":120},
+		"vulnerabilities":{"found":true,"count":2,"list":[
+		{"advisory":{"id":"RUSTSEC-2020-0071","package":"time","title":"Potential segfault in the time crate",
+			"aliases":["CVE-2020-26235","GHSA-wcg3-cvx6-7396"],"cvss":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"},
+		 "versions":{"patched":[">=0.2.23"],"unaffected":["=0.2.0"]},
+		 "package":{"name":"time","version":"0.1.45"}},
+		{"advisory":{"id":"RUSTSEC-2024-0019","package":"mio","title":"Tokens for named pipes may be delivered after deregistration","aliases":[],"cvss":null},
+		 "versions":{"patched":[]},
+		 "package":{"name":"mio","version":"0.8.10"}}
+	]},"warnings":{"unmaintained":[{"advisory":{"id":"RUSTSEC-2021-0139"},"package":{"name":"ansi_term"}}]}}`)
+
+	findings, err := parseCargoAuditOutput(output)
+	if err != nil {
+		t.Fatalf("parseCargoAuditOutput failed: %v", err)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("Expected 2 findings (warnings skipped), got %d", len(findings))
+	}
+	if f := findings[0]; f.CVE != "CVE-2020-26235" || f.Severity != "CRITICAL" || f.Package != "time" || f.Version != "0.1.45" || f.FixedIn != "0.2.23" {
+		t.Errorf("Unexpected finding: %+v", f)
+	}
+	if f := findings[1]; f.CVE != "RUSTSEC-2024-0019" || f.Severity != "MEDIUM" || f.FixedIn != "" {
+		t.Errorf("Unexpected finding without CVE alias: %+v", f)
+	}
+
+	if findings, err := parseCargoAuditOutput([]byte(`{"vulnerabilities":{"found":false,"count":0,"list":[]}}`)); err != nil || len(findings) != 0 {
+		t.Errorf("Expected no findings, got %v (%v)", findings, err)
+	}
+	if _, err := parseCargoAuditOutput([]byte("not json")); err == nil {
+		t.Error("Expected error for invalid output")
+	}
+}
+
+// ===========================================
+// Tests for dotnet list package Output Parsing
+// ===========================================
+
+func TestParseDotnetVulnerableOutput(t *testing.T) {
+	output := []byte(`{"version":1,"parameters":"--vulnerable --include-transitive","projects":[
+		{"path":"/src/Shop.Api/Shop.Api.csproj","frameworks":[{"framework":"net8.0",
+			"topLevelPackages":[{"id":"Newtonsoft.Json","requestedVersion":"12.0.1","resolvedVersion":"12.0.1",
+				"vulnerabilities":[{"severity":"High","advisoryurl":"https://github.com/advisories/GHSA-5crp-9r3c-p9vr"}]}],
+			"transitivePackages":[{"id":"System.Text.Encodings.Web","resolvedVersion":"4.5.0",
+				"vulnerabilities":[{"severity":"Moderate","advisoryurl":"https://github.com/advisories/GHSA-ghhp-997w-qr28"}]}]}]},
+		{"path":"/src/Shop.Core/Shop.Core.csproj","frameworks":[{"framework":"net8.0",
+			"topLevelPackages":[{"id":"Newtonsoft.Json","requestedVersion":"12.0.1","resolvedVersion":"12.0.1",
+				"vulnerabilities":[{"severity":"High","advisoryurl":"https://github.com/advisories/GHSA-5crp-9r3c-p9vr"}]}]}]}]}`)
+
+	findings, err := parseDotnetVulnerableOutput(output)
+	if err != nil {
+		t.Fatalf("parseDotnetVulnerableOutput failed: %v", err)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("Expected 2 findings (duplicate across projects removed), got %d", len(findings))
+	}
+	if f := findings[0]; f.CVE != "GHSA-5crp-9r3c-p9vr" || f.Severity != "HIGH" || f.Package != "Newtonsoft.Json" || f.Version != "12.0.1" {
+		t.Errorf("Unexpected finding: %+v", f)
+	}
+	if f := findings[1]; f.Severity != "MEDIUM" || f.Package != "System.Text.Encodings.Web" {
+		t.Errorf("Unexpected transitive finding: %+v", f)
+	}
+
+	if _, err := parseDotnetVulnerableOutput([]byte(`{"version":1,"problems":[{"text":"No assets file was found","level":"error"}]}`)); err == nil {
+		t.Error("Expected error for a report with only problems")
+	}
+	if _, err := parseDotnetVulnerableOutput([]byte("not json")); err == nil {
+		t.Error("Expected error for invalid output")
+	}
+}
+
+// ===========================================
+// Tests for bundler-audit Output Parsing
+// ===========================================
+
+func TestParseBundlerAuditOutput(t *testing.T) {
+	output := []byte(`Updating ruby-advisory-db ...
+{"version":"0.9.1","created_at":"2026-10-17 10:00:00 +0000","results":[
+		{"type":"unpatched_gem","gem":{"name":"actionpack","version":"7.0.4"},
+		 "advisory":{"id":"CVE-2023-22795","title":"ReDoS based DoS vulnerability in Action Dispatch","cve":"2023-22795",
+			"cvss_v3":7.5,"criticality":"high","patched_versions":["~> 6.1.7, >= 6.1.7.1", ">= 7.0.4.1"]}},
+		{"type":"unpatched_gem","gem":{"name":"nokogiri","version":"1.13.0"},
+		 "advisory":{"id":"GHSA-xxxx-yyyy-zzzz","title":"Unchecked return value","cve":null,"cvss_v3":null,"criticality":null,"patched_versions":[]}},
+		{"type":"insecure_source","source":"http://rubygems.org/"}]}`)
+
+	findings, err := parseBundlerAuditOutput(output)
+	if err != nil {
+		t.Fatalf("parseBundlerAuditOutput failed: %v", err)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("Expected 2 findings (insecure source skipped), got %d", len(findings))
+	}
+	if f := findings[0]; f.CVE != "CVE-2023-22795" || f.Severity != "HIGH" || f.Package != "actionpack" || f.Version != "7.0.4" || f.FixedIn != "7.0.4.1" {
+		t.Errorf("Unexpected finding: %+v", f)
+	}
+	if f := findings[1]; f.CVE != "GHSA-xxxx-yyyy-zzzz" || f.Severity != "MEDIUM" {
+		t.Errorf("Unexpected finding without CVE: %+v", f)
+	}
+
+	if _, err := parseBundlerAuditOutput([]byte("not json")); err == nil {
+		t.Error("Expected error for invalid output")
+	}
+}
+
+// ===========================================
+// Tests for Kubernetes Config Scan Parsing
+// ===========================================
+
+func TestParseTrivyConfigOutput(t *testing.T) {
+	output := []byte(`{"Results":[{"Target":"deploy/app.yaml","Class":"config","Type":"kubernetes","Misconfigurations":[
+		{"ID":"KSV001","AVDID":"AVD-KSV-0001","Title":"Can elevate its own privileges","Message":"Container 'web' of Deployment 'web' should set 'securityContext.allowPrivilegeEscalation' to false","Severity":"medium","Status":"FAIL"},
+		{"ID":"KSV003","Title":"Default capabilities not dropped","Severity":"LOW","Status":"PASS"}
+	]},{"Target":"charts/web/templates/deployment.yaml","Misconfigurations":[
+		{"AVDID":"AVD-KSV-0017","Title":"Privileged","Message":"Container 'app' should set 'securityContext.privileged' to false","Severity":"HIGH","Status":"FAIL"}
+	]}]}`)
+
+	findings, err := parseTrivyConfigOutput(output)
+	if err != nil {
+		t.Fatalf("parseTrivyConfigOutput failed: %v", err)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("Expected 2 findings (passed checks skipped), got %d", len(findings))
+	}
+	if f := findings[0]; f.CVE != "KSV001" || f.Severity != "MEDIUM" || f.Package != "Can elevate its own privileges" || f.ScanTarget != "config:deploy/app.yaml" {
+		t.Errorf("Unexpected finding: %+v", f)
+	}
+	if f := findings[1]; f.CVE != "AVD-KSV-0017" || f.ScanTarget != "config:charts/web/templates/deployment.yaml" {
+		t.Errorf("Unexpected finding without ID: %+v", f)
+	}
+
+	if _, err := parseTrivyConfigOutput([]byte("not json")); err == nil {
+		t.Error("Expected error for invalid output")
+	}
+}
+
+func TestParseTfsecOutput(t *testing.T) {
+	repo := "/repos/infra"
+	output := []byte(`{"results":[
+		{"rule_id":"AVD-AWS-0086","long_id":"aws-s3-block-public-acls","rule_description":"S3 Access block should block public ACL",
+		 "description":"No public access block so not blocking public acls","severity":"HIGH","resource":"aws_s3_bucket.logs",
+		 "location":{"filename":"/repos/infra/s3/main.tf","start_line":1,"end_line":4}},
+		{"long_id":"google-compute-no-public-ip","description":"Instance has a public IP","severity":"medium","resource":"google_compute_instance.vm",
+		 "location":{"filename":"compute.tf"}}
+	]}`)
+
+	findings, err := parseTfsecOutput(output, repo)
+	if err != nil {
+		t.Fatalf("parseTfsecOutput failed: %v", err)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("Expected 2 findings, got %d", len(findings))
+	}
+	if f := findings[0]; f.CVE != "AVD-AWS-0086" || f.Severity != "HIGH" || f.Package != "aws_s3_bucket.logs" || f.ScanTarget != "config:s3/main.tf" {
+		t.Errorf("Unexpected finding: %+v", f)
+	}
+	if f := findings[1]; f.CVE != "google-compute-no-public-ip" || f.Severity != "MEDIUM" || f.ScanTarget != "config:compute.tf" {
+		t.Errorf("Unexpected finding without rule ID: %+v", f)
+	}
+
+	// No results: null
+	if findings, err := parseTfsecOutput([]byte(`{"results":null}`), repo); err != nil || len(findings) != 0 {
+		t.Errorf("Expected no findings, got %v (%v)", findings, err)
+	}
+	if _, err := parseTfsecOutput([]byte("not json"), repo); err == nil {
+		t.Error("Expected error for invalid output")
+	}
+}
+
+func TestParseKubesecOutput(t *testing.T) {
+	output := []byte(`[{"object":"Deployment/web.default","valid":true,"fileName":"/tmp/chart.yaml","message":"Failed with a score of -30 points","score":-30,
+		"scoring":{"critical":[{"id":"Privileged","selector":"containers[] .securityContext .privileged == true","reason":"Privileged containers can allow almost completely unrestricted host access","points":-30}],
+		"advise":[{"id":"ReadOnlyRootFilesystem","reason":"An immutable root filesystem can prevent malicious binaries being added to PATH","points":1}]}},
+		{"object":"Service/web.default","valid":true,"message":"This resource kind is not supported by kubesec","score":0,"scoring":{}}]`)
+
+	findings, err := parseKubesecOutput(output, "charts/web")
+	if err != nil {
+		t.Fatalf("parseKubesecOutput failed: %v", err)
+	}
+	if len(findings) != 1 {
+		t.Fatalf("Expected 1 finding (advice skipped), got %d", len(findings))
+	}
+	if f := findings[0]; f.CVE != "Privileged" || f.Severity != "HIGH" || f.Package != "Deployment/web.default" || f.ScanTarget != "config:charts/web" {
+		t.Errorf("Unexpected finding: %+v", f)
+	}
+
+	if _, err := parseKubesecOutput([]byte("not json"), ""); err == nil {
+		t.Error("Expected error for invalid output")
+	}
+}
+
+// ===========================================
+// Tests for Trivy Output Parsing
+// ===========================================
+
+func TestParseTrivyOutput_ScanTarget(t *testing.T) {
+	output := []byte(`{"Results":[{"Vulnerabilities":[
+		{"VulnerabilityID":"CVE-2024-0001","PkgName":"openssl","InstalledVersion":"3.0.1","FixedVersion":"3.0.2","Severity":"high"}
+	]}]}`)
+
+	findings, err := parseTrivyOutput(output, "image:alpine:3.18")
+	if err != nil {
+		t.Fatalf("parseTrivyOutput failed: %v", err)
+	}
+	if len(findings) != 1 {
+		t.Fatalf("Expected 1 finding, got %d", len(findings))
+	}
+	if findings[0].Severity != "HIGH" || findings[0].ScanTarget != "image:alpine:3.18" {
+		t.Errorf("Unexpected finding: %+v", findings[0])
+	}
+
+	if _, err := parseTrivyOutput([]byte("not json"), ""); err == nil {
+		t.Error("Expected error for invalid output")
+	}
+}
+
+func TestSeverityThresholdAndFailOn(t *testing.T) {
+	findings := []CVEFinding{
+		{CVE: "CVE-1", Severity: "CRITICAL"},
+		{CVE: "CVE-2", Severity: "HIGH"},
+		{CVE: "CVE-3", Severity: "LOW"},
+		{CVE: "CVE-4", Severity: "UNKNOWN"},
+	}
+
+	filtered := filterFindingsBySeverity(findings, "HIGH")
+	if len(filtered) != 3 {
+		t.Errorf("Expected CRITICAL, HIGH and UNKNOWN to remain, got %+v", filtered)
+	}
+	if len(filterFindingsBySeverity(findings, "")) != 4 {
+		t.Error("Expected no filtering without threshold")
+	}
+
+	results := []RepoSecurityResult{{Findings: findings}, {Findings: []CVEFinding{{Severity: "CRITICAL"}}}}
+	if n := countFindingsAtLeast(results, "CRITICAL"); n != 2 {
+		t.Errorf("Expected 2 critical findings, got %d", n)
+	}
+	if n := countFindingsAtLeast(results, "HIGH"); n != 3 {
+		t.Errorf("Expected 3 findings HIGH or worse, got %d", n)
+	}
+}
+
+func TestHandleSecurityScan_InvalidSeverity(t *testing.T) {
+	body := `{"rootPath":"/tmp","scanner":"auto","failOn":{"severity":"SEVERE","maxCount":0}}`
+	req := httptest.NewRequest(http.MethodPost, "/api/security-scan", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	handleSecurityScan(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid failOn severity, got %d", w.Code)
+	}
+}
+
+func TestHandleSecurityScan_MissingToolAbortsBeforeScanning(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "service")
+	os.MkdirAll(filepath.Join(repo, ".git"), 0755)
+	os.WriteFile(filepath.Join(repo, "go.mod"), []byte("module example.com/service\n"), 0644)
+	t.Setenv("PATH", t.TempDir())
+
+	body := `{"rootPath":"` + root + `","scanner":"auto"}`
+	w := httptest.NewRecorder()
+	handleSecurityScan(w, httptest.NewRequest(http.MethodPost, "/api/security-scan", strings.NewReader(body)))
+
+	out := w.Body.String()
+	if !strings.Contains(out, `SCAN_MISSING_TOOL:{"name":"govulncheck"`) || !strings.Contains(out, "SCAN_ABORT:1 required tools") {
+		t.Fatalf("Expected govulncheck to be reported missing, got:\n%s", out)
+	}
+	if strings.Contains(out, "REPO_START:") || strings.Contains(out, "SCAN_COMPLETE") {
+		t.Errorf("Expected no repository to be scanned, got:\n%s", out)
+	}
+}
+
+func TestFindSubProjects(t *testing.T) {
+	repo := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(repo, filepath.FromSlash(rel))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+	write("pom.xml", "<project/>")
+	write("backend/pom.xml", "<project/>") // Module of the root build
+	write("frontend/package.json", "{}")   // Other ecosystem
+	write("frontend/package-lock.json", "{}")
+	write("frontend/packages/ui/package.json", "{}")      // Workspace of frontend
+	write("frontend/node_modules/x/package.json", "{}")   // Dependency
+	write("tools/cli/go.mod", "module example.com/cli")   // Separate Go module
+	write("a/b/c/deep/go.mod", "module example.com/deep") // Deeper than subProjectDepth
+	write(".github/scripts/requirements.txt", "requests")
+
+	got := findSubProjects(repo)
+	want := []SubProject{{Path: "frontend", ProjectType: "npm"}, {Path: "tools/cli", ProjectType: "go"}}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %+v, got %+v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected %+v, got %+v", want[i], got[i])
+		}
+	}
+
+	// The sub-projects decide the tools of the scan
+	needs := scanToolNeeds([]string{repo}, SecurityScanRequest{Scanner: "auto"})
+	for _, tool := range []string{"java", "npm", "govulncheck"} {
+		if len(needs[tool]) == 0 {
+			t.Errorf("Expected %s to be needed, got %v", tool, needs)
+		}
+	}
+}
+
+func TestAddSubProject(t *testing.T) {
+	result := RepoSecurityResult{RepoName: "shop", Findings: []CVEFinding{{CVE: "CVE-1", Package: "log4j"}}}
+	addSubProject(&result, SubProject{Path: "frontend", ProjectType: "npm", Scanner: "npm"}, RepoSecurityResult{Findings: []CVEFinding{{CVE: "CVE-2", Package: "lodash"}}})
+	addSubProject(&result, SubProject{Path: "tools/cli", ProjectType: "go", Scanner: "govulncheck"}, RepoSecurityResult{Error: "govulncheck failed"})
+
+	if len(result.Findings) != 2 || result.Findings[0].SubPath != "" || result.Findings[1].SubPath != "frontend" {
+		t.Errorf("Expected the sub-project finding to be tagged, got %+v", result.Findings)
+	}
+	if result.Error != "" || result.Warning != "tools/cli: govulncheck failed" {
+		t.Errorf("Expected a failed sub-project to be a warning, got error %q, warning %q", result.Error, result.Warning)
+	}
+	if len(result.SubProjects) != 2 || result.SubProjects[0].Findings != 1 || result.SubProjects[1].Error == "" {
+		t.Errorf("Unexpected sub-projects %+v", result.SubProjects)
+	}
+}
+
+func TestWithPathSandbox(t *testing.T) {
+	base := t.TempDir()
+	allowed := filepath.Join(base, "allowed")
+	os.MkdirAll(allowed, 0755)
+	sandbox, err := logic.NewPathSandbox([]string{allowed})
+	if err != nil {
+		t.Fatalf("NewPathSandbox failed: %v", err)
+	}
+
+	var received string
+	handler := withPathSandbox(sandbox, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+	}))
+
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		status int
+	}{
+		{"Allowed root", "/api/run", `{"rootPath":"` + allowed + `"}`, http.StatusOK},
+		{"Outside root", "/api/run", `{"rootPath":"` + base + `"}`, http.StatusForbidden},
+		{"Nested path", "/api/run", `{"RootPath":"` + allowed + `","repos":["/etc"]}`, http.StatusForbidden},
+		{"Repo names are no paths", "/api/github/clone", `{"rootPath":"` + allowed + `","repos":["api"]}`, http.StatusOK},
+		{"Query parameter", "/api/findings?rootPath=" + base, "", http.StatusForbidden},
+		{"Traversal", "/api/run", `{"rootPath":"` + allowed + `/../.."}`, http.StatusForbidden},
+		{"Maven settings file", "/api/run", `{"rootPath":"` + allowed + `","maven":{"settingsFile":"/etc/passwd"}}`, http.StatusForbidden},
+		{"Maven home", "/api/run", `{"rootPath":"` + allowed + `","maven":{"mavenHome":"` + base + `"}}`, http.StatusForbidden},
+		{"Relative repo traversal", "/api/ci-actions/update", `{"rootPath":"` + allowed + `","repos":["../outside"]}`, http.StatusForbidden},
+		{"Relative repo without root", "/api/run", `{"repos":["some-repo"]}`, http.StatusForbidden},
+		{"Relative repo under root", "/api/gitignore-normalize", `{"rootPath":"` + allowed + `","repos":["api"]}`, http.StatusOK},
+		{"Static assets unchecked", "/index.html?path=/etc", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = ""
+			method := http.MethodPost
+			if tt.body == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, tt.target, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d (%s)", tt.status, w.Code, w.Body.String())
+			}
+			if tt.status == http.StatusOK && received != tt.body {
+				t.Errorf("Expected body to be passed on, got %q", received)
+			}
+		})
+	}
+}
+
+func TestWithAuth(t *testing.T) {
+	t.Setenv(logic.AuthTokenEnv, "admin-secret")
+	t.Setenv(logic.ReadOnlyTokenEnv, "viewer-secret")
+	auth, err := logic.AuthenticatorFromEnv()
+	if err != nil {
+		t.Fatalf("AuthenticatorFromEnv failed: %v", err)
+	}
+	handler := withAuth(auth, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	send := func(method, target string, prepare func(r *http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		if prepare != nil {
+			prepare(req)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+	bearer := func(token string) func(r *http.Request) {
+		return func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) }
+	}
+
+	if w := send(http.MethodGet, "/api/health", nil); w.Code != http.StatusOK {
+		t.Errorf("Expected the health check to stay public, got %d", w.Code)
+	}
+	if w := send(http.MethodGet, "/api/profiles", nil); w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("Expected 401 with a basic auth challenge, got %d", w.Code)
+	}
+	if w := send(http.MethodPost, "/api/run", bearer("wrong")); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for an unknown token, got %d", w.Code)
+	}
+	if w := send(http.MethodPost, "/api/run", bearer("admin-secret")); w.Code != http.StatusOK {
+		t.Errorf("Expected bearer tokens to skip the CSRF check, got %d", w.Code)
+	}
+	if w := send(http.MethodPost, "/api/run", bearer("viewer-secret")); w.Code != http.StatusForbidden {
+		t.Errorf("Expected the read-only token to be blocked from runs, got %d", w.Code)
+	}
+	if w := send(http.MethodPost, "/api/list-branches", bearer("viewer-secret")); w.Code != http.StatusOK {
+		t.Errorf("Expected the read-only token to analyze, got %d", w.Code)
+	}
+	for _, path := range []string{"/api/dashboard-stats", "/api/outdated-maven"} {
+		if w := send(http.MethodPost, path, bearer("viewer-secret")); w.Code != http.StatusForbidden {
+			t.Errorf("Expected the read-only token to be blocked from %s, got %d", path, w.Code)
+		}
+	}
+
+	// Browser: basic auth creates a session whose CSRF token state-changing requests must send
+	basic := func(r *http.Request) { r.SetBasicAuth("alice", "admin-secret") }
+	w := send(http.MethodGet, "/", basic)
+	cookies := w.Result().Cookies()
+	if w.Code != http.StatusOK || len(cookies) != 1 {
+		t.Fatalf("Expected a session cookie, got %d and %v", w.Code, cookies)
+	}
+	session := auth.Session(httptest.NewRecorder(), func() *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.AddCookie(cookies[0])
+		return r
+	}(), "alice", logic.RoleAdmin)
+
+	withSession := func(csrf string) func(r *http.Request) {
+		return func(r *http.Request) {
+			basic(r)
+			r.AddCookie(cookies[0])
+			if csrf != "" {
+				r.Header.Set("X-CSRF-Token", csrf)
+			}
+		}
+	}
+	if w := send(http.MethodPost, "/api/run", withSession("")); w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 without CSRF token, got %d", w.Code)
+	}
+	if w := send(http.MethodPost, "/api/run", withSession("forged")); w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 with a wrong CSRF token, got %d", w.Code)
+	}
+	if w := send(http.MethodPost, "/api/run", withSession(session.CSRFToken)); w.Code != http.StatusOK {
+		t.Errorf("Expected the session's CSRF token to be accepted, got %d", w.Code)
+	}
+}
+
+func TestApplySuppressions(t *testing.T) {
+	list := &logic.SuppressionList{Suppressions: []logic.Suppression{
+		{ID: "CVE-1", Justification: "Not reachable"},
+	}}
+	findings := []CVEFinding{{CVE: "CVE-1", Package: "a"}, {CVE: "CVE-2", Package: "b"}}
+
+	actionable, suppressed := applySuppressions(findings, list, time.Now())
+	if len(actionable) != 1 || actionable[0].CVE != "CVE-2" {
+		t.Errorf("Expected only CVE-2 to be actionable, got %+v", actionable)
+	}
+	if len(suppressed) != 1 || suppressed[0].Justification != "Not reachable" {
+		t.Errorf("Expected CVE-1 to be suppressed, got %+v", suppressed)
+	}
+
+	if actionable, _ := applySuppressions(findings, nil, time.Now()); len(actionable) != 2 {
+		t.Error("Expected no suppression without list")
+	}
+}
+
+// ===========================================
+// Tests for Branch Sync
+// ===========================================
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@test.com", "GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@test.com")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func TestHandleSyncBranches_UpdatesWithoutCheckout(t *testing.T) {
+	base := t.TempDir()
+	origin := filepath.Join(base, "origin.git")
+	runGit(t, base, "init", "--bare", "-b", "main", origin)
+
+	// Publisher pushes main and feature
+	publisher := filepath.Join(base, "publisher")
+	runGit(t, base, "clone", origin, publisher)
+	os.WriteFile(filepath.Join(publisher, "file.txt"), []byte("v1"), 0644)
+	runGit(t, publisher, "add", "-A")
+	runGit(t, publisher, "commit", "-m", "Initial commit")
+	runGit(t, publisher, "push", "origin", "main", "main:feature")
+
+	root := filepath.Join(base, "repos")
+	local := filepath.Join(root, "local")
+	runGit(t, base, "clone", origin, local)
+	runGit(t, local, "branch", "feature", "origin/feature")
+
+	// New commits on both branches the local clone has not seen yet
+	os.WriteFile(filepath.Join(publisher, "file.txt"), []byte("v2"), 0644)
+	runGit(t, publisher, "commit", "-am", "Update main")
+	runGit(t, publisher, "push", "origin", "main", "main:feature")
+
+	// Local work on the checked-out branch must survive
+	os.WriteFile(filepath.Join(local, "notes.txt"), []byte("wip"), 0644)
+
+	body, _ := json.Marshal(SyncBranchesRequest{RootPath: root})
+	rr := httptest.NewRecorder()
+	handleSyncBranches(rr, httptest.NewRequest(http.MethodPost, "/api/sync-branches", strings.NewReader(string(body))))
+
+	output := rr.Body.String()
+	if !strings.Contains(output, "✓ feature updated") || !strings.Contains(output, "✓ main updated") {
+		t.Fatalf("Expected both branches to be updated: %s", output)
+	}
+	if head := runGit(t, local, "rev-parse", "--abbrev-ref", "HEAD"); head != "main" {
+		t.Errorf("Expected main to stay checked out, got %s", head)
+	}
+	if runGit(t, local, "rev-parse", "feature") != runGit(t, publisher, "rev-parse", "HEAD") {
+		t.Error("Expected feature to be fast-forwarded")
+	}
+	if _, err := os.Stat(filepath.Join(local, "notes.txt")); err != nil {
+		t.Error("Expected untracked local work to be untouched")
+	}
+}
+
+func TestHandleSyncBranches_ParallelKeepsRepoLogsTogether(t *testing.T) {
+	base := t.TempDir()
+	origin := filepath.Join(base, "origin.git")
+	runGit(t, base, "init", "--bare", "-b", "main", origin)
+	publisher := filepath.Join(base, "publisher")
+	runGit(t, base, "clone", origin, publisher)
+	os.WriteFile(filepath.Join(publisher, "file.txt"), []byte("v1"), 0644)
+	runGit(t, publisher, "add", "-A")
+	runGit(t, publisher, "commit", "-m", "Initial commit")
+	runGit(t, publisher, "push", "origin", "main")
+
+	root := filepath.Join(base, "repos")
+	names := []string{"a", "b", "c", "d", "e", "f"}
+	for _, name := range names {
+		runGit(t, base, "clone", origin, filepath.Join(root, name))
+	}
+	os.WriteFile(filepath.Join(publisher, "file.txt"), []byte("v2"), 0644)
+	runGit(t, publisher, "commit", "-am", "Update main")
+	runGit(t, publisher, "push", "origin", "main")
+
+	body, _ := json.Marshal(SyncBranchesRequest{RootPath: root, Concurrency: 3})
+	rr := httptest.NewRecorder()
+	handleSyncBranches(rr, httptest.NewRequest(http.MethodPost, "/api/sync-branches", strings.NewReader(string(body))))
+
+	// Every repo's lines sit between its REPO_START and REPO_DONE, progress counts up to the total
+	current, progress, synced := "", 0, map[string]bool{}
+	for _, line := range strings.Split(strings.TrimSpace(rr.Body.String()), "\n") {
+		switch {
+		case strings.HasPrefix(line, "REPO_START:"):
+			if current != "" {
+				t.Fatalf("REPO_START inside the block of %s", current)
+			}
+			current = strings.TrimPrefix(line, "REPO_START:")
+		case strings.HasPrefix(line, "REPO_DONE:"):
+			if name := strings.TrimPrefix(line, "REPO_DONE:"); name != current {
+				t.Fatalf("REPO_DONE:%s inside the block of %s", name, current)
+			}
+			current = ""
+		case strings.HasPrefix(line, "SYNC_PROGRESS:"):
+			progress++
+			if line != fmt.Sprintf("SYNC_PROGRESS:%d:%d", progress, len(names)) {
+				t.Errorf("unexpected %s", line)
+			}
+		case strings.HasPrefix(line, "  ✓ main updated"):
+			synced[current] = true
+		}
+	}
+	if len(synced) != len(names) || progress != len(names) {
+		t.Errorf("synced %v with %d progress events:\n%s", synced, progress, rr.Body.String())
+	}
+	if !strings.Contains(rr.Body.String(), "(3 in parallel)") || !strings.HasSuffix(rr.Body.String(), "SYNC_COMPLETE\n") {
+		t.Errorf("missing summary:\n%s", rr.Body.String())
+	}
+}